@@ -0,0 +1,91 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa/middleware/security/httpsig"
+)
+
+// HTTPSigSigner signs requests following the HTTP message signatures specification. It computes the
+// Content-Digest header from the request body and adds the Signature-Input and Signature headers.
+// Use the httpsig package New middleware to verify the signatures server side.
+type HTTPSigSigner struct {
+	// KeyID identifies the key used to sign the request, requests are not signed if empty.
+	KeyID string
+	// Algorithm computes the signature, e.g. httpsig.HMACSHA256(secret).
+	Algorithm httpsig.Algorithm
+	// Components lists the covered components, defaults to httpsig.DefaultComponents.
+	Components []string
+	// TTL is the optional signature validity duration used to set the "expires" parameter.
+	TTL time.Duration
+}
+
+// NewHTTPSigSigner creates a signer for the given algorithm name ("hmac-sha256" or "ed25519") and
+// base64 encoded key: the shared secret for HMAC or the 32 bytes private key seed for Ed25519.
+// The returned signer does not sign requests if keyID is empty.
+func NewHTTPSigSigner(keyID, alg, key string) (*HTTPSigSigner, error) {
+	if keyID == "" {
+		return &HTTPSigSigner{}, nil
+	}
+	k, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %s", err)
+	}
+	var a httpsig.Algorithm
+	switch alg {
+	case "", "hmac-sha256":
+		a = httpsig.HMACSHA256(k)
+	case "ed25519":
+		if len(k) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid ed25519 key seed length %d, expected %d", len(k), ed25519.SeedSize)
+		}
+		a = httpsig.Ed25519(ed25519.NewKeyFromSeed(k))
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+	return &HTTPSigSigner{KeyID: keyID, Algorithm: a}, nil
+}
+
+// Sign adds the Content-Digest, Signature-Input and Signature headers to the request.
+func (s *HTTPSigSigner) Sign(req *http.Request) error {
+	if s.KeyID == "" || s.Algorithm == nil {
+		return nil
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	req.Header.Set(httpsig.ContentDigestHeader, httpsig.ContentDigest(body))
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	components := s.Components
+	if components == nil {
+		components = httpsig.DefaultComponents
+	}
+	now := time.Now()
+	params := &httpsig.Params{
+		Components: components,
+		Created:    now,
+		KeyID:      s.KeyID,
+		Nonce:      base64.RawURLEncoding.EncodeToString(nonce),
+	}
+	if s.TTL > 0 {
+		params.Expires = now.Add(s.TTL)
+	}
+	return httpsig.SignRequest(req, s.Algorithm, params)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NextPageParam returns the value of the query string parameter name of the URL of the RFC 8288
+// Link header with the "next" relation type of resp. It returns false if resp does not link to a
+// next page or if the link URL does not set the parameter.
+func NextPageParam(resp *http.Response, name string) (string, bool) {
+	for _, header := range resp.Header["Link"] {
+		for _, link := range splitLinks(header) {
+			target, ok := nextLinkTarget(link)
+			if !ok {
+				continue
+			}
+			u, err := url.Parse(target)
+			if err != nil {
+				return "", false
+			}
+			values := u.Query()
+			if _, ok := values[name]; !ok {
+				return "", false
+			}
+			return values.Get(name), true
+		}
+	}
+	return "", false
+}
+
+// splitLinks splits the value of a Link header into its comma separated links, commas may
+// appear in the link target URIs.
+func splitLinks(header string) []string {
+	var (
+		links []string
+		start int
+		inURI bool
+	)
+	for i, c := range header {
+		switch c {
+		case '<':
+			inURI = true
+		case '>':
+			inURI = false
+		case ',':
+			if !inURI {
+				links = append(links, header[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(links, header[start:])
+}
+
+// nextLinkTarget returns the target URI of link if its relation types include "next".
+func nextLinkTarget(link string) (string, bool) {
+	link = strings.TrimSpace(link)
+	end := strings.Index(link, ">")
+	if !strings.HasPrefix(link, "<") || end < 0 {
+		return "", false
+	}
+	target := link[1:end]
+	for _, param := range strings.Split(link[end+1:], ";") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 || !strings.EqualFold(strings.TrimSpace(kv[0]), "rel") {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(kv[1]), `"`)) {
+			if strings.EqualFold(rel, "next") {
+				return target, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,45 @@
+package client_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NextPageParam", func() {
+	var resp *http.Response
+
+	BeforeEach(func() {
+		resp = &http.Response{Header: make(http.Header)}
+	})
+
+	It("returns the parameter of the next link", func() {
+		resp.Header.Add("Link", `</b?offset=0>; rel="first", </b?offset=20&x=a,b>; rel="next"`)
+		next, ok := client.NextPageParam(resp, "offset")
+		Expect(ok).To(BeTrue())
+		Expect(next).To(Equal("20"))
+	})
+
+	It("accepts links split across headers and multiple relation types", func() {
+		resp.Header.Add("Link", `</b?offset=0>; rel="first"`)
+		resp.Header.Add("Link", `</b?cursor=abc>; title="more"; rel="last next"`)
+		next, ok := client.NextPageParam(resp, "cursor")
+		Expect(ok).To(BeTrue())
+		Expect(next).To(Equal("abc"))
+	})
+
+	It("returns false without a next link", func() {
+		resp.Header.Add("Link", `</b?offset=0>; rel="first"`)
+		_, ok := client.NextPageParam(resp, "offset")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("returns false if the next link does not set the parameter", func() {
+		resp.Header.Add("Link", `</b?page=2>; rel="next"`)
+		_, ok := client.NextPageParam(resp, "cursor")
+		Expect(ok).To(BeFalse())
+	})
+})
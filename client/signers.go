@@ -3,6 +3,7 @@ package client
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type (
@@ -78,6 +79,17 @@ type (
 		Value string
 		// OAuth type, defaults to "Bearer".
 		Type string
+		// Expiry is the optional token expiration time, the zero value means the token never
+		// expires.
+		Expiry time.Time
+	}
+
+	// Refresher is implemented by signers whose credentials can be refreshed, for example after
+	// the service rejected them with a 401 response.
+	Refresher interface {
+		// Refresh discards the cached credentials so that the next request is signed with
+		// fresh ones. It returns false if the credentials cannot be refreshed.
+		Refresh() bool
 	}
 )
 
@@ -120,6 +132,40 @@ func (s *OAuth2Signer) Sign(req *http.Request) error {
 	return signFromSource(s.TokenSource, req)
 }
 
+// Refresh discards the cached token if the token source supports it, see RefreshingTokenSource.
+func (s *JWTSigner) Refresh() bool {
+	return invalidateSource(s.TokenSource)
+}
+
+// Refresh discards the cached token if the token source supports it, see RefreshingTokenSource.
+func (s *OAuth2Signer) Refresh() bool {
+	return invalidateSource(s.TokenSource)
+}
+
+// Refresh refreshes the credentials of the given signers that implement Refresher. It returns true
+// if the credentials of at least one signer were refreshed, in which case the request should be
+// signed and sent again.
+func Refresh(signers ...Signer) bool {
+	refreshed := false
+	for _, s := range signers {
+		if r, ok := s.(Refresher); ok && r.Refresh() {
+			refreshed = true
+		}
+	}
+	return refreshed
+}
+
+// invalidateSource discards the token cached by source if it implements Invalidate. It returns
+// true if a token was discarded.
+func invalidateSource(source TokenSource) bool {
+	if i, ok := source.(interface {
+		Invalidate() bool
+	}); ok {
+		return i.Invalidate()
+	}
+	return false
+}
+
 // signFromSource generates a token using the given source and uses it to sign the request.
 func signFromSource(source TokenSource, req *http.Request) error {
 	token, err := source.Token()
@@ -148,4 +194,6 @@ func (t *StaticToken) SetAuthHeader(r *http.Request) {
 }
 
 // Valid reports whether Token can be used to properly sign requests.
-func (t *StaticToken) Valid() bool { return true }
+func (t *StaticToken) Valid() bool {
+	return t.Expiry.IsZero() || time.Now().Before(t.Expiry)
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// NewMTLSConfig returns a TLS configuration that presents the client certificate stored in the
+// given PEM encoded files to servers requiring mutual TLS authentication. caFile is optional, if
+// not empty it must contain the PEM encoded certificates used to verify the server certificate
+// instead of the system pool.
+//
+// Use the result to configure the transport of the HTTP client given to New, e.g.:
+//
+//    tlsConfig, err := client.NewMTLSConfig("client.crt", "client.key", "")
+//    if err != nil {
+//        return err
+//    }
+//    httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+//    c := client.New(client.HTTPClientDoer(httpClient))
+//
+func NewMTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificate found in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
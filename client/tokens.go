@@ -0,0 +1,161 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshAhead is the default duration before the token expiry at which
+// RefreshingTokenSource fetches a new token.
+const DefaultRefreshAhead = time.Minute
+
+// minInvalidateInterval is the duration during which a freshly fetched token cannot be invalidated,
+// this prevents concurrent requests rejected with the previous token to refresh the token again.
+const minInvalidateInterval = time.Second
+
+type (
+	// TokenFetcher retrieves a new token, see CommandTokenFetcher and FileTokenFetcher.
+	TokenFetcher func() (*StaticToken, error)
+
+	// RefreshingTokenSource is a token source that caches the token returned by Fetch and
+	// fetches a new one shortly before it expires. Concurrent requests share the same refresh.
+	// The JWTSigner and OAuth2Signer Refresh methods invalidate the cached token so that
+	// generated clients retry requests rejected with a 401 response once with a new token.
+	//
+	// Example:
+	//
+	//    source := &client.RefreshingTokenSource{
+	//        Fetch: client.CommandTokenFetcher("vault read -field=token secret/api", "Bearer"),
+	//    }
+	//    c.SetJWTSigner(&client.JWTSigner{TokenSource: source})
+	//
+	RefreshingTokenSource struct {
+		// Fetch retrieves a new token.
+		Fetch TokenFetcher
+		// RefreshAhead is the duration before the token expiry at which a new token is
+		// fetched, defaults to DefaultRefreshAhead.
+		RefreshAhead time.Duration
+		// Now returns the current time, defaults to time.Now.
+		Now func() time.Time
+
+		mu        sync.Mutex
+		token     *StaticToken
+		fetchedAt time.Time
+	}
+)
+
+// Token returns the cached token or fetches a new one if there is none or if it expires within
+// RefreshAhead. The current token is returned if the refresh fails while it is still valid.
+func (s *RefreshingTokenSource) Token() (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ahead := s.RefreshAhead
+	if ahead == 0 {
+		ahead = DefaultRefreshAhead
+	}
+	now := s.now()
+	if s.token != nil && (s.token.Expiry.IsZero() || now.Add(ahead).Before(s.token.Expiry)) {
+		return s.token, nil
+	}
+	if s.Fetch == nil {
+		return nil, errors.New("no token fetch function configured")
+	}
+	token, err := s.Fetch()
+	if err != nil {
+		if s.token != nil && (s.token.Expiry.IsZero() || now.Before(s.token.Expiry)) {
+			return s.token, nil
+		}
+		return nil, err
+	}
+	s.token = token
+	s.fetchedAt = now
+	return token, nil
+}
+
+// Invalidate discards the cached token so that the next call to Token fetches a new one. Tokens
+// fetched less than a second ago are kept. Invalidate returns true if the token was discarded.
+func (s *RefreshingTokenSource) Invalidate() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil || s.now().Sub(s.fetchedAt) < minInvalidateInterval {
+		return false
+	}
+	s.token = nil
+	return true
+}
+
+// now returns the current time using the Now function if set.
+func (s *RefreshingTokenSource) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// NewStaticToken creates a token with the given value and type. The token expiry is initialized
+// from the "exp" claim if value is a JWT.
+func NewStaticToken(value, typ string) *StaticToken {
+	token := &StaticToken{Value: value, Type: typ}
+	if exp, err := JWTExpiry(value); err == nil {
+		token.Expiry = exp
+	}
+	return token
+}
+
+// JWTExpiry returns the expiration time contained in the "exp" claim of the given JWT. It returns
+// the zero time if the token has no "exp" claim. The token signature is not verified.
+func JWTExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid JWT payload: %s", err)
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("invalid JWT claims: %s", err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, nil
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid JWT exp claim: %s", err)
+	}
+	return time.Unix(int64(exp), 0), nil
+}
+
+// CommandTokenFetcher returns a fetcher that runs the given shell command and uses its standard
+// output, trimmed of surrounding white spaces, as token value.
+func CommandTokenFetcher(command, typ string) TokenFetcher {
+	return func() (*StaticToken, error) {
+		out, err := exec.Command("sh", "-c", command).Output()
+		if err != nil {
+			return nil, fmt.Errorf("token command failed: %s", err)
+		}
+		return NewStaticToken(strings.TrimSpace(string(out)), typ), nil
+	}
+}
+
+// FileTokenFetcher returns a fetcher that reads the token value from the given file. The file is
+// read again each time the token is refreshed so that it may be rotated by an external process.
+func FileTokenFetcher(path, typ string) TokenFetcher {
+	return func() (*StaticToken, error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return NewStaticToken(strings.TrimSpace(string(b)), typ), nil
+	}
+}
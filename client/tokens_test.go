@@ -0,0 +1,175 @@
+package client_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// jwtWithExpiry returns an unsigned JWT whose "exp" claim is set to exp.
+func jwtWithExpiry(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	payload := fmt.Sprintf(`{"sub":"test","exp":%d}`, exp.Unix())
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(payload)) + ".sig"
+}
+
+var _ = Describe("tokens", func() {
+	Context("JWTExpiry", func() {
+		It("parses the exp claim", func() {
+			exp := time.Now().Add(time.Hour).Truncate(time.Second)
+			parsed, err := client.JWTExpiry(jwtWithExpiry(exp))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.Equal(exp)).To(BeTrue())
+		})
+
+		It("rejects values that are not JWTs", func() {
+			_, err := client.JWTExpiry("opaque")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("NewStaticToken", func() {
+		It("is invalid once the JWT expired", func() {
+			token := client.NewStaticToken(jwtWithExpiry(time.Now().Add(-time.Minute)), "Bearer")
+			Expect(token.Valid()).To(BeFalse())
+		})
+
+		It("never expires with opaque values", func() {
+			token := client.NewStaticToken("opaque", "Bearer")
+			Expect(token.Valid()).To(BeTrue())
+		})
+	})
+
+	Context("RefreshingTokenSource", func() {
+		var fetches int
+		var mu sync.Mutex
+		var expiry time.Duration
+		var fetchErr error
+		var now time.Time
+		var source *client.RefreshingTokenSource
+
+		BeforeEach(func() {
+			fetches = 0
+			expiry = time.Hour
+			fetchErr = nil
+			now = time.Now()
+			source = &client.RefreshingTokenSource{
+				Fetch: func() (*client.StaticToken, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					if fetchErr != nil {
+						return nil, fetchErr
+					}
+					fetches++
+					return &client.StaticToken{
+						Value:  fmt.Sprintf("token-%d", fetches),
+						Expiry: now.Add(expiry),
+					}, nil
+				},
+				Now: func() time.Time { return now },
+			}
+		})
+
+		It("caches the token", func() {
+			t1, err := source.Token()
+			Expect(err).ToNot(HaveOccurred())
+			t2, err := source.Token()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t2).To(Equal(t1))
+			Expect(fetches).To(Equal(1))
+		})
+
+		It("shares the refresh across concurrent requests", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					source.Token()
+				}()
+			}
+			wg.Wait()
+			Expect(fetches).To(Equal(1))
+		})
+
+		Context("with a token about to expire", func() {
+			BeforeEach(func() {
+				expiry = 30 * time.Second
+			})
+
+			It("refreshes the token ahead of expiry", func() {
+				source.Token()
+				source.Token()
+				Expect(fetches).To(Equal(2))
+			})
+
+			It("keeps using the current token if the refresh fails", func() {
+				t1, _ := source.Token()
+				fetchErr = errors.New("boom")
+				t2, err := source.Token()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(t2).To(Equal(t1))
+			})
+		})
+
+		It("signs requests with a refreshed token after Refresh", func() {
+			signer := &client.JWTSigner{TokenSource: source}
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			Expect(signer.Sign(req)).ToNot(HaveOccurred())
+			Expect(req.Header.Get("Authorization")).To(Equal("Bearer token-1"))
+
+			now = now.Add(2 * time.Second)
+			Expect(client.Refresh(nil, signer)).To(BeTrue())
+			Expect(signer.Sign(req)).ToNot(HaveOccurred())
+			Expect(req.Header.Get("Authorization")).To(Equal("Bearer token-2"))
+		})
+
+		It("does not refresh a token that was just fetched", func() {
+			signer := &client.JWTSigner{TokenSource: source}
+			req, _ := http.NewRequest("GET", "http://example.com", nil)
+			Expect(signer.Sign(req)).ToNot(HaveOccurred())
+
+			Expect(client.Refresh(signer)).To(BeFalse())
+			Expect(signer.Sign(req)).ToNot(HaveOccurred())
+			Expect(req.Header.Get("Authorization")).To(Equal("Bearer token-1"))
+		})
+	})
+
+	Context("FileTokenFetcher", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "goa-token")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("reads the token from the file", func() {
+			path := filepath.Join(dir, "token")
+			Expect(ioutil.WriteFile(path, []byte("secret\n"), 0600)).To(Succeed())
+			token, err := client.FileTokenFetcher(path, "Bearer")()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token.Value).To(Equal("secret"))
+		})
+	})
+
+	It("does not refresh static token sources", func() {
+		signer := &client.JWTSigner{TokenSource: &client.StaticTokenSource{StaticToken: &client.StaticToken{Value: "static"}}}
+		Expect(client.Refresh(signer)).To(BeFalse())
+	})
+})
@@ -0,0 +1,127 @@
+package cors
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// Config lists the CORS policies applied by Middleware. The policies are evaluated in
+	// order, the first policy that matches the request origin applies.
+	Config struct {
+		// Policies lists the CORS policies.
+		Policies []*Policy
+	}
+
+	// Policy describes the CORS response headers sent to matching origins.
+	Policy struct {
+		// Origin is the origin specification, see MatchOrigin.
+		Origin string
+		// Regexp indicates whether Origin is a regular expression.
+		Regexp bool
+		// AllowOrigin is an optional predicate used instead of Origin to match origins, for
+		// example to check a dynamic allow-list.
+		AllowOrigin func(origin string) bool
+		// Methods lists the allowed methods, "*" authorizes all.
+		Methods []string
+		// Headers lists the allowed headers, "*" authorizes all.
+		Headers []string
+		// Exposed lists the headers exposed to clients.
+		Exposed []string
+		// MaxAge is how long in seconds preflight request responses may be cached.
+		MaxAge uint
+		// Credentials sets the Access-Control-Allow-Credentials header.
+		Credentials bool
+		// PrivateNetwork allows requests from public websites to services running on private
+		// networks, see https://wicg.github.io/private-network-access.
+		PrivateNetwork bool
+	}
+)
+
+// Middleware returns a middleware that applies the CORS response headers corresponding to the
+// request origin. Preflight requests are answered by the handler the middleware wraps, see
+// HandlePreflight. Middleware panics if a policy origin regular expression is invalid.
+//
+// The code generated by goagen for the CORS policies defined in the design uses this middleware,
+// it may also be used directly to load the policies from configuration:
+//
+//    policy := &cors.Policy{
+//        AllowOrigin: func(origin string) bool { return allowed[origin] },
+//        Methods:     []string{"GET", "POST"},
+//        Credentials: true,
+//    }
+//    service.Use(cors.Middleware(cors.Config{Policies: []*cors.Policy{policy}}))
+//
+func Middleware(config Config) goa.Middleware {
+	matchers := make([]func(string) bool, len(config.Policies))
+	for i, p := range config.Policies {
+		matchers[i] = p.matcher()
+	}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			origin := req.Header.Get("Origin")
+			if origin == "" {
+				// Not a CORS request
+				return h(ctx, rw, req)
+			}
+			for i, p := range config.Policies {
+				if !matchers[i](origin) {
+					continue
+				}
+				ctx = goa.WithLogContext(ctx, "origin", origin)
+				p.apply(rw, req, origin)
+				return h(ctx, rw, req)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// matcher returns the function used to match origins against the policy.
+func (p *Policy) matcher() func(string) bool {
+	if p.AllowOrigin != nil {
+		return p.AllowOrigin
+	}
+	spec, isRegexp := p.Origin, p.Regexp
+	if !isRegexp && len(spec) > 1 && strings.HasPrefix(spec, "/") && strings.HasSuffix(spec, "/") {
+		spec, isRegexp = strings.Trim(spec, "/"), true
+	}
+	if isRegexp {
+		r := regexp.MustCompile(spec)
+		return func(origin string) bool { return MatchOriginRegexp(origin, r) }
+	}
+	return func(origin string) bool { return MatchOrigin(origin, spec) }
+}
+
+// apply sets the CORS response headers for the given origin.
+func (p *Policy) apply(rw http.ResponseWriter, req *http.Request, origin string) {
+	h := rw.Header()
+	h.Set("Access-Control-Allow-Origin", origin)
+	if p.AllowOrigin != nil || p.Origin != "*" {
+		h.Set("Vary", "Origin")
+	}
+	if len(p.Exposed) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(p.Exposed, ", "))
+	}
+	if p.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.FormatUint(uint64(p.MaxAge), 10))
+	}
+	h.Set("Access-Control-Allow-Credentials", strconv.FormatBool(p.Credentials))
+	if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
+		// We are handling a preflight request
+		if len(p.Methods) > 0 {
+			h.Set("Access-Control-Allow-Methods", strings.Join(p.Methods, ", "))
+		}
+		if len(p.Headers) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(p.Headers, ", "))
+		}
+		if p.PrivateNetwork && req.Header.Get("Access-Control-Request-Private-Network") == "true" {
+			h.Set("Access-Control-Allow-Private-Network", "true")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cors_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goadesign/goa/cors"
+)
+
+func serveCORS(t *testing.T, config cors.Config, header http.Header) http.Header {
+	called := false
+	h := cors.Middleware(config)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	})
+	req, _ := http.NewRequest("OPTIONS", "http://example.com/bottles", nil)
+	req.Header = header
+	rw := httptest.NewRecorder()
+	if err := h(context.Background(), rw, req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !called {
+		t.Fatal("handler was not called")
+	}
+	return rw.Header()
+}
+
+func TestMiddleware(t *testing.T) {
+	allowed := map[string]bool{"http://dynamic.example.com": true}
+	config := cors.Config{
+		Policies: []*cors.Policy{
+			{
+				Origin:         "/^https://[a-z]+\\.goa\\.design$/",
+				Methods:        []string{"GET", "POST"},
+				Headers:        []string{"X-One"},
+				Exposed:        []string{"X-Two"},
+				MaxAge:         600,
+				Credentials:    true,
+				PrivateNetwork: true,
+			},
+			{
+				AllowOrigin: func(origin string) bool { return allowed[origin] },
+				Methods:     []string{"GET"},
+			},
+			{
+				Origin: "*",
+			},
+		},
+	}
+
+	data := []struct {
+		Name     string
+		Header   http.Header
+		Expected map[string]string
+	}{
+		{"not a CORS request", http.Header{}, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+		{"regexp origin", http.Header{"Origin": {"https://swagger.goa.design"}}, map[string]string{
+			"Access-Control-Allow-Origin":      "https://swagger.goa.design",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Expose-Headers":    "X-Two",
+			"Access-Control-Max-Age":           "600",
+			"Access-Control-Allow-Methods":     "",
+			"Vary":                             "Origin",
+		}},
+		{"regexp origin preflight with private network", http.Header{
+			"Origin":                                 {"https://swagger.goa.design"},
+			"Access-Control-Request-Method":          {"POST"},
+			"Access-Control-Request-Private-Network": {"true"},
+		}, map[string]string{
+			"Access-Control-Allow-Methods":         "GET, POST",
+			"Access-Control-Allow-Headers":         "X-One",
+			"Access-Control-Allow-Private-Network": "true",
+		}},
+		{"origin predicate", http.Header{
+			"Origin":                                 {"http://dynamic.example.com"},
+			"Access-Control-Request-Method":          {"GET"},
+			"Access-Control-Request-Private-Network": {"true"},
+		}, map[string]string{
+			"Access-Control-Allow-Origin":          "http://dynamic.example.com",
+			"Access-Control-Allow-Credentials":     "false",
+			"Access-Control-Allow-Methods":         "GET",
+			"Access-Control-Allow-Private-Network": "",
+			"Vary":                                 "Origin",
+		}},
+		{"wildcard origin", http.Header{"Origin": {"http://other.example.com"}}, map[string]string{
+			"Access-Control-Allow-Origin": "http://other.example.com",
+			"Vary":                        "",
+		}},
+	}
+
+	for _, test := range data {
+		header := serveCORS(t, config, test.Header)
+		for name, val := range test.Expected {
+			if actual := header.Get(name); actual != val {
+				t.Errorf("%s: got %s header %q, expected %q", test.Name, name, actual, val)
+			}
+		}
+	}
+}
@@ -63,6 +63,37 @@ const (
 	HTTPVersionNotSupported = "HTTPVersionNotSupported"
 )
 
+const (
+	// MergePatchContentType is the Content-Type of JSON merge patch (RFC 7396) request bodies.
+	MergePatchContentType = "application/merge-patch+json"
+
+	// JSONPatchContentType is the Content-Type of JSON patch (RFC 6902) request bodies.
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+const (
+	// Cursor pagination identifies each page with the opaque cursor returned together with the
+	// previous page in the "cursor" parameter.
+	Cursor PaginationStrategy = "cursor"
+
+	// Offset pagination identifies each page with the position of its first result in the
+	// collection in the "offset" parameter.
+	Offset PaginationStrategy = "offset"
+)
+
+const (
+	// PathVersioning prefixes the paths of the actions with the name of the API version, e.g.
+	// "/v2/bottles".
+	PathVersioning VersioningScheme = "path"
+
+	// HeaderVersioning selects the API version with a request header.
+	HeaderVersioning VersioningScheme = "header"
+
+	// MediaTypeVersioning selects the API version with a parameter of the media types listed
+	// in the Accept request header, e.g. "application/vnd.bottle+json; version=v2".
+	MediaTypeVersioning VersioningScheme = "mediatype"
+)
+
 var (
 	// Design being built by DSL.
 	Design *APIDefinition
@@ -98,6 +129,8 @@ var (
 		"application/x-cbor":    "github.com/goadesign/goa/encoding/cbor",
 		"application/msgpack":   "github.com/goadesign/goa/encoding/msgpack",
 		"application/x-msgpack": "github.com/goadesign/goa/encoding/msgpack",
+		MergePatchContentType:   "github.com/goadesign/goa",
+		JSONPatchContentType:    "github.com/goadesign/goa",
 	}
 
 	// KnownEncoderFunctions contains the list of encoding encoder and decoder functions known
@@ -113,6 +146,8 @@ var (
 		"application/x-cbor":    {"NewEncoder", "NewDecoder"},
 		"application/msgpack":   {"NewEncoder", "NewDecoder"},
 		"application/x-msgpack": {"NewEncoder", "NewDecoder"},
+		MergePatchContentType:   {"NewJSONEncoder", "NewJSONDecoder"},
+		JSONPatchContentType:    {"NewJSONEncoder", "NewJSONDecoder"},
 	}
 
 	// JSONContentTypes list the Content-Type header values that cause goa to encode or decode
@@ -127,6 +162,40 @@ var (
 	// Gob by default.
 	GobContentTypes = []string{"application/gob", "application/x-gob"}
 
+	// JSONPatchOperation is the built-in type of the elements of the JSON patch (RFC 6902)
+	// documents accepted by the actions defined with the JSONPatch DSL.
+	JSONPatchOperation = &UserTypeDefinition{
+		AttributeDefinition: &AttributeDefinition{
+			Type: Object{
+				"op": &AttributeDefinition{
+					Type:        String,
+					Description: "the operation to perform.",
+					Validation: &dslengine.ValidationDefinition{
+						Values: []interface{}{"add", "remove", "replace", "move", "copy", "test"},
+					},
+					Example: "replace",
+				},
+				"path": &AttributeDefinition{
+					Type:        String,
+					Description: "the JSON pointer to the location the operation applies to.",
+					Example:     "/name",
+				},
+				"from": &AttributeDefinition{
+					Type:        String,
+					Description: "the JSON pointer to the source location of move and copy operations.",
+				},
+				"value": &AttributeDefinition{
+					Type:        Any,
+					Description: "the value used by the add, replace and test operations.",
+					Nullable:    true,
+				},
+			},
+			Description: "JSON patch operation as described in RFC 6902",
+			Validation:  &dslengine.ValidationDefinition{Required: []string{"op", "path"}},
+		},
+		TypeName: "JSONPatchOperation",
+	}
+
 	// ErrorMediaIdentifier is the media type identifier used for error responses.
 	ErrorMediaIdentifier = "application/vnd.goa.error"
 
@@ -91,6 +91,9 @@ func Files(path, filename string, dsls ...func()) {
 //            Header("X-Account", Integer)
 //            Required("Authorization", "X-Account")
 //        })
+//        Cookies(func() {                     // Cookies describe relevant action cookies
+//            Cookie("session", String)
+//        })
 //        Payload(UpdatePayload)                // Payload describes the HTTP request body
 //        // OptionalPayload(UpdatePayload)     // OptionalPayload defines an HTTP request body which may be omitted
 //        Response(NoContent)                   // Each possible HTTP response is described via Response
@@ -108,6 +111,7 @@ func Action(name string, dsl func()) {
 				Name:     name,
 				Metadata: make(dslengine.MetadataDefinition),
 			}
+			dslengine.RecordLocation(action)
 		}
 		if !dslengine.Execute(dsl, action) {
 			return
@@ -316,6 +320,29 @@ func Headers(params ...interface{}) {
 	}
 }
 
+// Cookies can be used in: Action
+//
+// Cookies implements the DSL for describing the cookies sent with the action requests. The DSL
+// syntax is identical to the one of Attribute, cookies must be primitives. Here is an example:
+//
+//	Cookies(func() {
+//		Cookie("session", String)
+//		Cookie("visits", Integer, func() {
+//			Minimum(0)
+//		})
+//		Required("session")
+//	})
+func Cookies(dsl func()) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	cookies := newAttribute(a.Parent.MediaType)
+	if dslengine.Execute(dsl, cookies) {
+		a.Cookies = a.Cookies.Merge(cookies)
+	}
+}
+
 // Params can be used in: Action, Resource, API
 //
 // Params describe the action parameters, either path parameters identified via wildcards or query
@@ -424,6 +451,10 @@ func payload(isOptional bool, p interface{}, dsls ...func()) {
 		return
 	}
 	if a, ok := actionDefinition(); ok {
+		if a.Patch != nil {
+			dslengine.ReportError("action cannot define both a payload and a patch")
+			return
+		}
 		var att *design.AttributeDefinition
 		var dsl func()
 		switch actual := p.(type) {
@@ -489,6 +520,242 @@ func MultipartForm() {
 	}
 }
 
+// MergePatch can be used in: Action
+//
+// MergePatch declares that the action request body is a JSON merge patch (RFC 7396) describing a
+// partial update of a value of the given type or media type. The request Content-Type is
+// application/merge-patch+json. The action payload holds the patch document: it defines the
+// attributes of the target, none is required and all are nullable as null removes the member.
+// Attributes that cannot be nullable such as objects hold the merge patch of the member value.
+// The generated code provides an Apply method that applies the patch to a value of the target type
+// and validates the result against the design. The generated client provides a function that
+// builds the patch from the original and the updated values. Example:
+//
+//	Action("patch", func() {
+//		Routing(PATCH("/:bottleID"))
+//		MergePatch(BottleMedia)
+//		Response(OK, BottleMedia)
+//	})
+//
+func MergePatch(target interface{}) {
+	patch("MergePatch", design.MergePatchContentType, target)
+}
+
+// JSONPatch can be used in: Action
+//
+// JSONPatch declares that the action request body is a JSON patch (RFC 6902) describing a partial
+// update of a value of the given type or media type. The request Content-Type is
+// application/json-patch+json and the action payload is an array of JSONPatchOperation values.
+// The generated code provides the same Apply method and client function as MergePatch. Example:
+//
+//	Action("patch", func() {
+//		Routing(PATCH("/:bottleID"))
+//		JSONPatch(BottleMedia)
+//		Response(OK, BottleMedia)
+//	})
+//
+func JSONPatch(target interface{}) {
+	patch("JSONPatch", design.JSONPatchContentType, target)
+}
+
+func patch(name, format string, target interface{}) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	var (
+		typeName string
+		dt       design.DataType
+	)
+	switch actual := target.(type) {
+	case *design.UserTypeDefinition:
+		typeName, dt = actual.TypeName, actual
+	case *design.MediaTypeDefinition:
+		typeName, dt = actual.TypeName, actual
+	case string:
+		if t, ok := design.Design.Types[actual]; ok {
+			typeName, dt = t.TypeName, t
+		} else if mt := design.Design.MediaTypeWithIdentifier(actual); mt != nil {
+			typeName, dt = mt.TypeName, mt
+		} else {
+			dslengine.ReportError("unknown %s type %s", name, actual)
+			return
+		}
+	default:
+		dslengine.ReportError("invalid %s argument, must be a type or a media type", name)
+		return
+	}
+	if a.Payload != nil && a.Patch == nil {
+		dslengine.ReportError("action cannot define both a payload and a patch")
+		return
+	}
+	var typ design.DataType
+	if format == design.MergePatchContentType {
+		typ = mergePatchType(dt)
+	} else {
+		op := design.JSONPatchOperation
+		if t, ok := design.Design.Types[op.TypeName]; ok && t != op {
+			dslengine.ReportError("type %s conflicts with the built-in JSON patch operation type", op.TypeName)
+			return
+		}
+		if design.Design.Types == nil {
+			design.Design.Types = make(map[string]*design.UserTypeDefinition)
+		}
+		design.Design.Types[op.TypeName] = op
+		typ = &design.Array{ElemType: &design.AttributeDefinition{Type: op}}
+	}
+	a.Patch = &design.PatchDefinition{Format: format, Target: dt}
+	a.Payload = &design.UserTypeDefinition{
+		AttributeDefinition: &design.AttributeDefinition{
+			Type:        typ,
+			Description: fmt.Sprintf("%s of %s", format, typeName),
+		},
+		TypeName: fmt.Sprintf("%s%sPayload", camelize(a.Name), camelize(a.Parent.Name)),
+	}
+	a.PayloadOptional = false
+}
+
+// mergePatchType returns the type of the JSON merge patch documents that update values of the
+// given type. The patch defines the same attributes as the target, none is required and all are
+// nullable as null removes the member. The attributes that cannot be nullable such as objects hold
+// the merge patch of the member value instead, the result is validated once the patch is applied.
+func mergePatchType(target design.DataType) design.Object {
+	res := make(design.Object)
+	for n, att := range target.ToObject() {
+		p := design.DupAtt(att)
+		p.DefaultValue = nil
+		p.Nullable = true
+		if !design.CanBeNullable(p.Type) {
+			p.Type = design.Any
+			p.Validation = nil
+		}
+		res[n] = p
+	}
+	return res
+}
+
+// defaultPageSize is the default value of the "limit" parameter of paginated actions that do not
+// define DefaultPageSize.
+const defaultPageSize = 20
+
+// Paginated can be used in: Action
+//
+// Paginated declares that the action returns its results in pages. The strategy is either Cursor
+// or Offset. Paginated adds the "limit" parameter that sets the number of results in the page as
+// well as the "cursor" parameter with cursor pagination or the "offset" parameter with offset
+// pagination. The OK response of the action must use a collection media type.
+//
+// By default the response includes a RFC 8288 Link header that points to the next page of results
+// (and with offset pagination to the first, previous and last pages and a X-Total-Count header).
+// Envelope wraps the page of results in the response body together with the pagination fields
+// instead. The generated code provides response helpers that set the links or envelope fields and
+// the generated client provides iterators that fetch the successive pages. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Paginated(Cursor, func() {
+//			MaxPageSize(100)
+//			DefaultPageSize(25)
+//		})
+//		Response(OK, CollectionOf(BottleMedia))
+//	})
+//
+func Paginated(strategy design.PaginationStrategy, dsl ...func()) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if strategy != design.Cursor && strategy != design.Offset {
+		dslengine.ReportError("invalid pagination strategy %#v, must be Cursor or Offset", strategy)
+		return
+	}
+	p := &design.PaginationDefinition{Parent: a, Strategy: strategy}
+	if len(dsl) > 0 {
+		if !dslengine.Execute(dsl[0], p) {
+			return
+		}
+	}
+	if p.DefaultPageSize == 0 {
+		p.DefaultPageSize = defaultPageSize
+		if p.MaxPageSize > 0 && p.MaxPageSize < defaultPageSize {
+			p.DefaultPageSize = p.MaxPageSize
+		}
+	}
+	a.Pagination = p
+	a.Params = a.Params.Merge(paginationParams(p))
+}
+
+// MaxPageSize can be used in: Paginated
+//
+// MaxPageSize sets the maximum number of results in a page, requests that set the "limit"
+// parameter to a greater value are rejected.
+func MaxPageSize(size int) {
+	if p, ok := paginationDefinition(); ok {
+		if size <= 0 {
+			dslengine.ReportError("maximum page size must be greater than 0, got %d", size)
+			return
+		}
+		p.MaxPageSize = size
+	}
+}
+
+// DefaultPageSize can be used in: Paginated
+//
+// DefaultPageSize sets the number of results in a page when the request does not set the "limit"
+// parameter. The default page size is 20 or the maximum page size if lower.
+func DefaultPageSize(size int) {
+	if p, ok := paginationDefinition(); ok {
+		if size <= 0 {
+			dslengine.ReportError("default page size must be greater than 0, got %d", size)
+			return
+		}
+		p.DefaultPageSize = size
+	}
+}
+
+// Envelope can be used in: Paginated
+//
+// Envelope wraps the page of results in the response body together with the pagination fields
+// rather than linking the pages with Link headers. The envelope is an object with an "items" field
+// containing the page of results and a "next_cursor" field with cursor pagination or "offset",
+// "limit" and "total" fields with offset pagination.
+func Envelope() {
+	if p, ok := paginationDefinition(); ok {
+		p.Envelope = true
+	}
+}
+
+// paginationParams returns the parameters used to request pages of results.
+func paginationParams(p *design.PaginationDefinition) *design.AttributeDefinition {
+	min := 1.0
+	limit := &design.AttributeDefinition{
+		Type:         design.Integer,
+		Description:  "Maximum number of results in the page",
+		Validation:   &dslengine.ValidationDefinition{Minimum: &min},
+		DefaultValue: p.DefaultPageSize,
+	}
+	if p.MaxPageSize > 0 {
+		max := float64(p.MaxPageSize)
+		limit.Validation.Maximum = &max
+	}
+	params := design.Object{"limit": limit}
+	if p.Strategy == design.Cursor {
+		params["cursor"] = &design.AttributeDefinition{
+			Type:        design.String,
+			Description: "Cursor of the page, as returned with the previous page",
+		}
+	} else {
+		zero := 0.0
+		params["offset"] = &design.AttributeDefinition{
+			Type:         design.Integer,
+			Description:  "Position of the first result of the page in the collection",
+			Validation:   &dslengine.ValidationDefinition{Minimum: &zero},
+			DefaultValue: 0,
+		}
+	}
+	return &design.AttributeDefinition{Type: params}
+}
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
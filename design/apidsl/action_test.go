@@ -187,6 +187,48 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with cookies", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/:id"))
+				Cookies(func() {
+					Cookie("session")
+					Cookie("visits", Integer)
+					Required("session")
+				})
+			}
+		})
+
+		It("produces a valid action with the cookies", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.Cookies).ShouldNot(BeNil())
+			Ω(action.Cookies.Type.(Object)).Should(HaveLen(2))
+			Ω(action.Cookies.Type.(Object)).Should(HaveKey("session"))
+			Ω(action.Cookies.Type.(Object)["visits"].Type).Should(Equal(Integer))
+			Ω(action.Cookies.Validation.Required).Should(Equal([]string{"session"}))
+		})
+	})
+
+	Context("with a cookie that is not a primitive", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/:id"))
+				Cookies(func() {
+					Cookie("ids", ArrayOf(Integer))
+				})
+			}
+		})
+
+		It("produces an invalid action", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("action cookies must be primitives"))
+		})
+	})
+
 	Context("using a response with a media type modifier", func() {
 		const mtID = "application/vnd.app.foo+json"
 
@@ -364,3 +406,233 @@ var _ = Describe("Payload", func() {
 	})
 
 })
+
+var _ = Describe("MergePatch", func() {
+	var target *UserTypeDefinition
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		target = Type("Bottle", func() {
+			Attribute("name", String, func() {
+				MinLength(2)
+			})
+			Attribute("vintage", Integer, func() {
+				Default(2000)
+			})
+			Attribute("origin", func() {
+				Attribute("country", String)
+			})
+			Required("name")
+		})
+		dsl = func() {
+			MergePatch(target)
+		}
+	})
+
+	JustBeforeEach(func() {
+		Resource("bottle", func() {
+			Action("update", func() {
+				Routing(PATCH("/:id"))
+				dsl()
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the patch and payload of the action", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		a := Design.Resources["bottle"].Actions["update"]
+		Ω(a.Patch).ShouldNot(BeNil())
+		Ω(a.Patch.Format).Should(Equal(MergePatchContentType))
+		Ω(a.Patch.IsMergePatch()).Should(BeTrue())
+		Ω(a.Patch.Target).Should(Equal(target))
+		Ω(a.Payload).ShouldNot(BeNil())
+		Ω(a.Payload.TypeName).Should(Equal("UpdateBottlePayload"))
+		Ω(a.PayloadOptional).Should(BeFalse())
+	})
+
+	It("defines the target attributes as optional nullable payload attributes", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		p := Design.Resources["bottle"].Actions["update"].Payload
+		Ω(p.Type.IsObject()).Should(BeTrue())
+		o := p.Type.ToObject()
+		Ω(o).Should(HaveLen(3))
+		Ω(p.Validation).Should(BeNil())
+		Ω(o["name"].Type).Should(Equal(String))
+		Ω(o["name"].Nullable).Should(BeTrue())
+		Ω(o["name"].Validation.MinLength).ShouldNot(BeNil())
+		Ω(o["vintage"].Nullable).Should(BeTrue())
+		Ω(o["vintage"].DefaultValue).Should(BeNil())
+		Ω(o["origin"].Type).Should(Equal(Any))
+		Ω(o["origin"].Nullable).Should(BeTrue())
+		Ω(target.Type.ToObject()["vintage"].DefaultValue).Should(Equal(2000))
+	})
+
+	Context("with a payload", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Payload(target)
+				MergePatch(target)
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("both a payload and a patch"))
+		})
+	})
+})
+
+var _ = Describe("JSONPatch", func() {
+	var target *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		target = Type("Bottle", func() {
+			Attribute("name", String)
+		})
+		Resource("bottle", func() {
+			Action("edit", func() {
+				Routing(PATCH("/:id"))
+				JSONPatch("Bottle")
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		dslengine.Run()
+	})
+
+	It("sets the patch and payload of the action", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		a := Design.Resources["bottle"].Actions["edit"]
+		Ω(a.Patch).ShouldNot(BeNil())
+		Ω(a.Patch.Format).Should(Equal(JSONPatchContentType))
+		Ω(a.Patch.IsMergePatch()).Should(BeFalse())
+		Ω(a.Patch.Target).Should(Equal(target))
+		Ω(a.Payload.Type.IsArray()).Should(BeTrue())
+		Ω(a.Payload.Type.ToArray().ElemType.Type).Should(Equal(JSONPatchOperation))
+		Ω(Design.Types).Should(HaveKeyWithValue("JSONPatchOperation", JSONPatchOperation))
+	})
+})
+
+var _ = Describe("Paginated", func() {
+	var strategy PaginationStrategy
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		strategy = Cursor
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("name", String)
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				Params(func() {
+					Param("q", String)
+				})
+				if dsl == nil {
+					Paginated(strategy)
+				} else {
+					Paginated(strategy, dsl)
+				}
+				Response(OK, CollectionOf(bottle))
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("adds the cursor pagination params", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		a := Design.Resources["bottle"].Actions["list"]
+		Ω(a.Pagination).ShouldNot(BeNil())
+		Ω(a.Pagination.Strategy).Should(Equal(Cursor))
+		Ω(a.Pagination.DefaultPageSize).Should(Equal(20))
+		Ω(a.Pagination.MaxPageSize).Should(Equal(0))
+		params := a.Params.Type.ToObject()
+		Ω(params).Should(HaveKey("q"))
+		Ω(params).Should(HaveKey("cursor"))
+		Ω(params["cursor"].Type).Should(Equal(String))
+		Ω(params["limit"].Type).Should(Equal(Integer))
+		Ω(params["limit"].DefaultValue).Should(Equal(20))
+		Ω(params["limit"].Validation.Maximum).Should(BeNil())
+	})
+
+	It("documents the Link header of the OK response", func() {
+		headers := Design.Resources["bottle"].Actions["list"].Responses[OK].Headers
+		Ω(headers).ShouldNot(BeNil())
+		Ω(headers.Type.ToObject()).Should(HaveKey("Link"))
+		Ω(headers.Type.ToObject()).ShouldNot(HaveKey("X-Total-Count"))
+	})
+
+	Context("with offset pagination and page sizes", func() {
+		BeforeEach(func() {
+			strategy = Offset
+			dsl = func() {
+				MaxPageSize(10)
+			}
+		})
+
+		It("adds the offset pagination params", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["bottle"].Actions["list"]
+			Ω(a.Pagination.MaxPageSize).Should(Equal(10))
+			Ω(a.Pagination.DefaultPageSize).Should(Equal(10))
+			params := a.Params.Type.ToObject()
+			Ω(params).ShouldNot(HaveKey("cursor"))
+			Ω(params["offset"].Type).Should(Equal(Integer))
+			Ω(params["offset"].DefaultValue).Should(Equal(0))
+			Ω(*params["limit"].Validation.Maximum).Should(Equal(10.0))
+			Ω(a.Responses[OK].Headers.Type.ToObject()).Should(HaveKey("X-Total-Count"))
+		})
+	})
+
+	Context("with an envelope", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Envelope()
+			}
+		})
+
+		It("does not document the Link header", func() {
+			a := Design.Resources["bottle"].Actions["list"]
+			Ω(a.Pagination.Envelope).Should(BeTrue())
+			Ω(a.Responses[OK].Headers).Should(BeNil())
+		})
+	})
+
+	Context("with an invalid page size", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				DefaultPageSize(0)
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("default page size must be greater than 0"))
+		})
+	})
+
+	Context("with an invalid strategy", func() {
+		BeforeEach(func() {
+			strategy = "page"
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid pagination strategy"))
+		})
+	})
+})
@@ -87,25 +87,104 @@ func API(name string, dsl func()) *design.APIDefinition {
 	}
 	design.Design.Name = name
 	design.Design.DSLFunc = dsl
+	dslengine.RecordLocation(design.Design)
 	return design.Design
 }
 
+// defaultVersionHeader is the name of the request header that selects the API version with
+// HeaderVersioning unless specified otherwise.
+const defaultVersionHeader = "X-API-Version"
+
+// defaultVersionParam is the name of the media type parameter that selects the API version with
+// MediaTypeVersioning unless specified otherwise.
+const defaultVersionParam = "version"
+
 // Version can be used in: API
 //
-// Version specifies the API version. One design describes one version.
-func Version(ver string) {
-	if api, ok := apiDefinition(); ok {
-		api.Version = ver
+// Version declares a version of the API. A design that calls Version once describes that single
+// version. A design may also declare several coexisting versions, from the oldest to the latest.
+// The resources, actions, attributes and views of the design are then included in all versions
+// unless added, removed or deprecated in some version with AddedIn, RemovedIn and Deprecated.
+// VersionedBy defines how requests select the version, the generated code serves all versions and
+// the generated specs describe each version. The optional DSL describes the version. Example:
+//
+//	API("cellar", func() {
+//		Version("v1", func() {
+//			Description("Initial release")
+//			Deprecated(func() {
+//				Sunset("2027-06-30")
+//			})
+//		})
+//		Version("v2")
+//		VersionedBy(HeaderVersioning, "X-API-Version")
+//	})
+//
+func Version(ver string, dsl ...func()) {
+	api, ok := apiDefinition()
+	if !ok {
+		return
 	}
+	v := &design.VersionDefinition{Parent: api, Name: ver}
+	if len(dsl) > 0 {
+		if !dslengine.Execute(dsl[0], v) {
+			return
+		}
+	}
+	api.Versions = append(api.Versions, v)
+	api.Version = ver
 }
 
-// Description can be used in: API, Resource, Action, MediaType, Attribute, Response or ResponseTemplate
+// VersionedBy can be used in: API
+//
+// VersionedBy defines how requests select the API version when the design declares several
+// versions. The scheme is one of:
+//
+//	PathVersioning:      the paths of the actions are prefixed with the version, e.g. "/v2/bottles".
+//	HeaderVersioning:    the version is set in the request header with the given name,
+//	                     "X-API-Version" by default.
+//	MediaTypeVersioning: the version is set in the parameter with the given name of the media types
+//	                     listed in the Accept header, "version" by default.
+//
+// Requests that do not select a version with HeaderVersioning or MediaTypeVersioning are served by
+// the latest version. The default scheme is PathVersioning.
+func VersionedBy(scheme design.VersioningScheme, key ...string) {
+	api, ok := apiDefinition()
+	if !ok {
+		return
+	}
+	if len(key) > 1 {
+		dslengine.ReportError("too many arguments given to VersionedBy")
+		return
+	}
+	switch scheme {
+	case design.PathVersioning:
+		if len(key) > 0 {
+			dslengine.ReportError("path versioning does not use a header or parameter name")
+			return
+		}
+	case design.HeaderVersioning:
+		api.VersionKey = defaultVersionHeader
+	case design.MediaTypeVersioning:
+		api.VersionKey = defaultVersionParam
+	default:
+		dslengine.ReportError("invalid versioning scheme %#v, must be PathVersioning, HeaderVersioning or MediaTypeVersioning", scheme)
+		return
+	}
+	if len(key) > 0 {
+		api.VersionKey = key[0]
+	}
+	api.VersionScheme = scheme
+}
+
+// Description can be used in: API, Version, Resource, Action, MediaType, Attribute, Response or ResponseTemplate
 //
 // Description sets the definition description.
 func Description(d string) {
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition:
 		def.Description = d
+	case *design.VersionDefinition:
+		def.Description = d
 	case *design.ResourceDefinition:
 		def.Description = d
 	case *design.FileServerDefinition:
@@ -239,6 +318,17 @@ func Credentials() {
 	}
 }
 
+// PrivateNetwork can be used in: Origin
+//
+// PrivateNetwork authorizes requests made from public websites to the API when it runs on a private
+// network: preflight requests that include the Access-Control-Request-Private-Network header get a
+// response with the Access-Control-Allow-Private-Network header.
+func PrivateNetwork() {
+	if cors, ok := corsDefinition(); ok {
+		cors.PrivateNetwork = true
+	}
+}
+
 // TermsOfService can be used in: API
 //
 // TermsOfService describes the API terms of services or links to them.
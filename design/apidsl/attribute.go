@@ -128,6 +128,7 @@ func Attribute(name string, args ...interface{}) {
 			}
 		}
 		baseAttr.Reference = parent.Reference
+		dslengine.RecordLocation(baseAttr)
 		if dsl != nil {
 			dslengine.Execute(dsl, baseAttr)
 		}
@@ -261,6 +262,13 @@ func Header(name string, args ...interface{}) {
 	Attribute(name, args...)
 }
 
+// Cookie can be used in: Cookies
+//
+// Cookie is an alias of Attribute.
+func Cookie(name string, args ...interface{}) {
+	Attribute(name, args...)
+}
+
 // Member can be used in: Payload
 //
 // Member is an alias of Attribute.
@@ -296,6 +304,25 @@ func Default(def interface{}) {
 	}
 }
 
+// Nullable can be used in: Attribute
+//
+// Nullable makes it possible to explicitly set the attribute to null. The generated code
+// distinguishes an attribute that is absent from one that is set to null, for example to clear the
+// value of a field with a PATCH request:
+//
+//	Attribute("nickname", String, func() {
+//		Nullable()
+//	})
+//
+// The field generated for a nullable attribute of type T is a NullableT struct whose Set and Null
+// fields indicate whether the attribute is present and whether it is null. Nullable attributes must
+// be primitives or arrays or hashes of primitives and cannot have a default value.
+func Nullable() {
+	if a, ok := attributeDefinition(); ok {
+		a.Nullable = true
+	}
+}
+
 // Example can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // Example sets the example of an attribute to be used for the documentation:
@@ -412,13 +439,38 @@ var SupportedValidationFormats = []string{
 // "regexp": RE2 regular expression
 //
 // "rfc1123": RFC1123 date time
+//
+// Integer attributes accept the formats listed in design.IntegerFormats which set the bitness of
+// the generated Go type: "int32", "int64", "uint", "uint32" and "uint64". "int64" object fields are
+// encoded as JSON strings so that JavaScript clients do not lose precision.
+//
+// Number attributes accept the formats listed in design.NumberFormats: "float32" and "decimal".
+// Decimal values are encoded as JSON strings such as "12.50" and use the Go string type so that
+// money amounts are not rounded, for example:
+//
+//	Attribute("amount", Number, func() {
+//		Format("decimal")
+//		Minimum(0)
+//	})
 func Format(f string) {
 	if a, ok := attributeDefinition(); ok {
-		if a.Type != nil && a.Type.Kind() != design.StringKind {
-			incompatibleAttributeType("format", a.Type.Name(), "a string")
+		formats, compatible := SupportedValidationFormats, true
+		if a.Type != nil {
+			switch a.Type.Kind() {
+			case design.IntegerKind:
+				formats = design.IntegerFormats
+			case design.NumberKind:
+				formats = design.NumberFormats
+			case design.StringKind:
+			default:
+				compatible = false
+			}
+		}
+		if !compatible {
+			incompatibleAttributeType("format", a.Type.Name(), "a string, an integer or a number")
 		} else {
 			supported := false
-			for _, s := range SupportedValidationFormats {
+			for _, s := range formats {
 				if s == f {
 					supported = true
 					break
@@ -426,7 +478,7 @@ func Format(f string) {
 			}
 			if !supported {
 				dslengine.ReportError("unsupported format %#v, supported formats are: %s",
-					f, strings.Join(SupportedValidationFormats, ", "))
+					f, strings.Join(formats, ", "))
 			} else {
 				if a.Validation == nil {
 					a.Validation = &dslengine.ValidationDefinition{}
@@ -464,31 +516,7 @@ func Pattern(p string) {
 // Minimum adds a "minimum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor21.
 func Minimum(val interface{}) {
-	if a, ok := attributeDefinition(); ok {
-		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
-			incompatibleAttributeType("minimum", a.Type.Name(), "an integer or a number")
-		} else {
-			var f float64
-			switch v := val.(type) {
-			case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-				f = reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float()
-			case string:
-				var err error
-				f, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					dslengine.ReportError("invalid number value %#v", v)
-					return
-				}
-			default:
-				dslengine.ReportError("invalid number value %#v", v)
-				return
-			}
-			if a.Validation == nil {
-				a.Validation = &dslengine.ValidationDefinition{}
-			}
-			a.Validation.Minimum = &f
-		}
-	}
+	setBound("minimum", val, true, false)
 }
 
 // Maximum can be used in: Attribute, Header, Param, HashOf, ArrayOf
@@ -496,33 +524,103 @@ func Minimum(val interface{}) {
 // Maximum adds a "maximum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor17.
 func Maximum(val interface{}) {
+	setBound("maximum", val, false, false)
+}
+
+// ExclusiveMinimum can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// ExclusiveMinimum adds a "minimum" validation with "exclusiveMinimum" set to the attribute: the
+// value must be strictly greater than val.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor21.
+func ExclusiveMinimum(val interface{}) {
+	setBound("exclusive minimum", val, true, true)
+}
+
+// ExclusiveMaximum can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// ExclusiveMaximum adds a "maximum" validation with "exclusiveMaximum" set to the attribute: the
+// value must be strictly lower than val.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor17.
+func ExclusiveMaximum(val interface{}) {
+	setBound("exclusive maximum", val, false, true)
+}
+
+// MultipleOf can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// MultipleOf adds a "multipleOf" validation to the attribute: the value must be a multiple of val
+// which must be strictly greater than 0. The value of integer attributes must be an integer.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor14.
+//
+// Example:
+//
+//	Attribute("price", Number, func() {
+//		Format("decimal")
+//		MultipleOf(0.01)
+//	})
+func MultipleOf(val interface{}) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
+			incompatibleAttributeType("multiple of", a.Type.Name(), "an integer or a number")
+			return
+		}
+		f, ok := numberValue(val)
+		if !ok {
+			return
+		}
+		if f <= 0 {
+			dslengine.ReportError("invalid multiple of value %#v, value must be greater than 0", val)
+			return
+		}
+		if a.Validation == nil {
+			a.Validation = &dslengine.ValidationDefinition{}
+		}
+		a.Validation.MultipleOf = &f
+	}
+}
+
+// setBound sets the minimum or maximum validation of the current attribute.
+func setBound(name string, val interface{}, min, exclusive bool) {
 	if a, ok := attributeDefinition(); ok {
 		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
-			incompatibleAttributeType("maximum", a.Type.Name(), "an integer or a number")
+			incompatibleAttributeType(name, a.Type.Name(), "an integer or a number")
+			return
+		}
+		f, ok := numberValue(val)
+		if !ok {
+			return
+		}
+		if a.Validation == nil {
+			a.Validation = &dslengine.ValidationDefinition{}
+		}
+		if min {
+			a.Validation.Minimum = &f
+			a.Validation.ExclusiveMinimum = exclusive
 		} else {
-			var f float64
-			switch v := val.(type) {
-			case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-				f = reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float()
-			case string:
-				var err error
-				f, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					dslengine.ReportError("invalid number value %#v", v)
-					return
-				}
-			default:
-				dslengine.ReportError("invalid number value %#v", v)
-				return
-			}
-			if a.Validation == nil {
-				a.Validation = &dslengine.ValidationDefinition{}
-			}
 			a.Validation.Maximum = &f
+			a.Validation.ExclusiveMaximum = exclusive
 		}
 	}
 }
 
+// numberValue converts the given DSL value to a float64, it reports an error and returns false if
+// the value is not a number or a string representation of a number.
+func numberValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float(), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			dslengine.ReportError("invalid number value %#v", v)
+			return 0, false
+		}
+		return f, true
+	default:
+		dslengine.ReportError("invalid number value %#v", v)
+		return 0, false
+	}
+}
+
 // MinLength can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // MinLength adds a "minItems" validation to the attribute.
@@ -557,11 +655,158 @@ func MaxLength(val int) {
 	}
 }
 
-// Required can be used in: Attributes, Headers, Payload, Type, Params
+// UniqueItems can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// UniqueItems adds a "uniqueItems" validation to the attribute: the elements of the array must
+// all be different.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor49.
+func UniqueItems() {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.ArrayKind {
+			incompatibleAttributeType("unique items", a.Type.Name(), "an array")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.UniqueItems = true
+		}
+	}
+}
+
+// MinProperties can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// MinProperties adds a "minProperties" validation to the attribute: the hash must have at least
+// val keys.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor57.
+func MinProperties(val int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.HashKind {
+			incompatibleAttributeType("minimum properties", a.Type.Name(), "a hash")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.MinProperties = &val
+		}
+	}
+}
+
+// MaxProperties can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// MaxProperties adds a "maxProperties" validation to the attribute: the hash must have at most
+// val keys.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor54.
+func MaxProperties(val int) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.HashKind {
+			incompatibleAttributeType("maximum properties", a.Type.Name(), "a hash")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.MaxProperties = &val
+		}
+	}
+}
+
+// Required can be used in: Attributes, Headers, Cookies, Payload, Type, Params
 //
 // Required adds a "required" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor61.
 func Required(names ...string) {
+	if v, ok := objectValidation("required"); ok {
+		v.AddRequired(names)
+	}
+}
+
+// OneOfRequired can be used in: Attributes, Payload, Type
+//
+// OneOfRequired adds a validation that requires exactly one of the given attributes to be set.
+// Example:
+//
+//	var ContactPayload = Type("ContactPayload", func() {
+//		Attribute("email", String)
+//		Attribute("phone", String)
+//		OneOfRequired("email", "phone")
+//	})
+func OneOfRequired(names ...string) {
+	if len(names) < 2 {
+		dslengine.ReportError("OneOfRequired requires at least two attribute names")
+		return
+	}
+	if v, ok := objectValidation("one of required"); ok {
+		v.AddOneOfRequired(names)
+	}
+}
+
+// MutuallyExclusive can be used in: Attributes, Payload, Type
+//
+// MutuallyExclusive adds a validation that allows at most one of the given attributes to be set.
+func MutuallyExclusive(names ...string) {
+	if len(names) < 2 {
+		dslengine.ReportError("MutuallyExclusive requires at least two attribute names")
+		return
+	}
+	if v, ok := objectValidation("mutually exclusive"); ok {
+		v.AddMutuallyExclusive(names)
+	}
+}
+
+// DependentRequired can be used in: Attributes, Payload, Type
+//
+// DependentRequired adds a validation that requires the attributes listed in deps to be set
+// whenever the attribute name is set.
+// Example:
+//
+//	Attributes(func() {
+//		Attribute("country", String)
+//		Attribute("postal_code", String)
+//		DependentRequired("postal_code", "country")
+//	})
+func DependentRequired(name string, deps ...string) {
+	if len(deps) == 0 {
+		dslengine.ReportError("DependentRequired requires at least one dependent attribute name")
+		return
+	}
+	if v, ok := objectValidation("dependent required"); ok {
+		v.AddDependentRequired(name, deps)
+	}
+}
+
+// ValidateFunc can be used in: Attributes, Payload, Type
+//
+// ValidateFunc makes the generated Validate methods call the Go function fn defined in the
+// package with import path pkg. The function is given the values of the named attributes as
+// they appear in the generated struct (i.e. pointers for optional attributes) and must return
+// an error if the validation fails. Errors that are not goa errors are reported as invalid
+// request errors listing the attributes. Example:
+//
+//	var PeriodPayload = Type("PeriodPayload", func() {
+//		Attribute("start_date", DateTime)
+//		Attribute("end_date", DateTime)
+//		ValidateFunc("github.com/org/app/validators", "ValidatePeriod", "start_date", "end_date")
+//	})
+//
+// where the validators package defines:
+//
+//	func ValidatePeriod(start, end *time.Time) error
+func ValidateFunc(pkg, fn string, attributes ...string) {
+	if len(attributes) == 0 {
+		dslengine.ReportError("ValidateFunc requires at least one attribute name")
+		return
+	}
+	if v, ok := objectValidation("custom"); ok {
+		v.AddFunc(&dslengine.ValidationFuncDefinition{
+			PackagePath: pkg,
+			Name:        fn,
+			Attributes:  attributes,
+		})
+	}
+}
+
+// objectValidation returns the validation of the object attribute being defined, creating it
+// if needed. It reports an error if the current definition is not an object.
+func objectValidation(validation string) (*dslengine.ValidationDefinition, bool) {
 	var at *design.AttributeDefinition
 
 	switch def := dslengine.CurrentDefinition().(type) {
@@ -571,17 +816,17 @@ func Required(names ...string) {
 		at = def.AttributeDefinition
 	default:
 		dslengine.IncompatibleDSL()
-		return
+		return nil, false
 	}
 
 	if at.Type != nil && at.Type.Kind() != design.ObjectKind {
-		incompatibleAttributeType("required", at.Type.Name(), "an object")
-	} else {
-		if at.Validation == nil {
-			at.Validation = &dslengine.ValidationDefinition{}
-		}
-		at.Validation.AddRequired(names)
+		incompatibleAttributeType(validation, at.Type.Name(), "an object")
+		return nil, false
+	}
+	if at.Validation == nil {
+		at.Validation = &dslengine.ValidationDefinition{}
 	}
+	return at.Validation, true
 }
 
 // incompatibleAttributeType reports an error for validations defined on
@@ -253,6 +253,157 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name, type integer and a DSL defining a numeric format", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = Integer
+			dsl = func() { Format("int64") }
+		})
+
+		It("produces an attribute with the numeric format", func() {
+			o := parent.Type.(Object)
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(o[name].Validation.Format).Should(Equal("int64"))
+			Ω(o[name].NumericFormat()).Should(Equal("int64"))
+		})
+	})
+
+	Context("with a name, type string and a DSL defining a nullable attribute", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { Nullable() }
+		})
+
+		It("produces a nullable attribute", func() {
+			o := parent.Type.(Object)
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(o[name].Nullable).Should(BeTrue())
+			Ω(parent.IsPrimitivePointer(name)).Should(BeFalse())
+		})
+	})
+
+	Context("with a name, type number and a DSL defining a string format", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = Number
+			dsl = func() { Format("email") }
+		})
+
+		It("records an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("supported formats are: float32, decimal"))
+		})
+	})
+
+	Context("with a name, type number and a DSL defining extended validations", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = Number
+			dsl = func() {
+				ExclusiveMinimum(0)
+				Maximum(100)
+				MultipleOf(0.5)
+			}
+		})
+
+		It("produces an attribute with the validations", func() {
+			o := parent.Type.(Object)
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			v := o[name].Validation
+			Ω(*v.Minimum).Should(Equal(0.0))
+			Ω(v.ExclusiveMinimum).Should(BeTrue())
+			Ω(*v.Maximum).Should(Equal(100.0))
+			Ω(v.ExclusiveMaximum).Should(BeFalse())
+			Ω(*v.MultipleOf).Should(Equal(0.5))
+		})
+	})
+
+	Context("with a name, type array and a DSL defining a unique items validation", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = ArrayOf(String)
+			dsl = func() { UniqueItems() }
+		})
+
+		It("produces an attribute with the validation", func() {
+			o := parent.Type.(Object)
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(o[name].Validation.UniqueItems).Should(BeTrue())
+		})
+	})
+
+	Context("with a name, type string and a DSL defining a min properties validation", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { MinProperties(1) }
+		})
+
+		It("records an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("attribute must be a hash"))
+		})
+	})
+
+	Context("with a name and a DSL defining cross-field validations", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Attribute("email")
+				Attribute("phone")
+				Attribute("fax")
+				Attribute("country")
+				Attribute("postal_code")
+				OneOfRequired("email", "phone")
+				MutuallyExclusive("phone", "fax")
+				DependentRequired("postal_code", "country")
+				ValidateFunc("example.com/validators", "ValidateContact", "email", "country")
+			}
+		})
+
+		It("produces an attribute with the validations", func() {
+			o := parent.Type.(Object)
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			v := o[name].Validation
+			Ω(v.OneOfRequired).Should(Equal([][]string{{"email", "phone"}}))
+			Ω(v.MutuallyExclusive).Should(Equal([][]string{{"phone", "fax"}}))
+			Ω(v.DependentRequired).Should(Equal(map[string][]string{"postal_code": {"country"}}))
+			Ω(v.Funcs).Should(HaveLen(1))
+			Ω(v.Funcs[0].PackagePath).Should(Equal("example.com/validators"))
+			Ω(v.Funcs[0].Name).Should(Equal("ValidateContact"))
+			Ω(v.Funcs[0].Attributes).Should(Equal([]string{"email", "country"}))
+		})
+	})
+
+	Context("with a name and a DSL defining a one of required validation with a single name", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Attribute("email")
+				OneOfRequired("email")
+			}
+		})
+
+		It("records an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("at least two attribute names"))
+		})
+	})
+
+	Context("with a name, type string and a DSL defining a dependent required validation", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { DependentRequired("a", "b") }
+		})
+
+		It("records an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("attribute must be an object"))
+		})
+	})
+
 	Context("with a name, type integer, a description and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -122,6 +122,48 @@ func actionDefinition() (*design.ActionDefinition, bool) {
 	return a, ok
 }
 
+// lifecycleDefinition returns true and the lifecycle of the current context if it is a
+// ResourceDefinition, an ActionDefinition or an AttributeDefinition, nil and false otherwise. It
+// initializes the lifecycle if needed.
+func lifecycleDefinition() (*design.LifecycleDefinition, bool) {
+	var l **design.LifecycleDefinition
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		l = &def.Lifecycle
+	case *design.ActionDefinition:
+		l = &def.Lifecycle
+	case *design.AttributeDefinition:
+		l = &def.Lifecycle
+	default:
+		dslengine.IncompatibleDSL()
+		return nil, false
+	}
+	if *l == nil {
+		*l = new(design.LifecycleDefinition)
+	}
+	return *l, true
+}
+
+// deprecationDefinition returns true and current context if it is a DeprecationDefinition,
+// nil and false otherwise.
+func deprecationDefinition() (*design.DeprecationDefinition, bool) {
+	d, ok := dslengine.CurrentDefinition().(*design.DeprecationDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return d, ok
+}
+
+// paginationDefinition returns true and current context if it is a PaginationDefinition,
+// nil and false otherwise.
+func paginationDefinition() (*design.PaginationDefinition, bool) {
+	p, ok := dslengine.CurrentDefinition().(*design.PaginationDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return p, ok
+}
+
 // responseDefinition returns true and current context if it is a ResponseDefinition,
 // nil and false otherwise.
 func responseDefinition() (*design.ResponseDefinition, bool) {
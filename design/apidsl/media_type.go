@@ -111,6 +111,7 @@ func MediaType(identifier string, apidsl func()) *design.MediaTypeDefinition {
 	}
 	// Now save the type in the API media types map
 	mt := design.NewMediaTypeDefinition(typeName, identifier, apidsl)
+	dslengine.RecordLocation(mt)
 	design.Design.MediaTypes[canonicalID] = mt
 	return mt
 }
@@ -369,6 +370,68 @@ func Link(name string, view ...string) {
 	}
 }
 
+// Filterable can be used in: MediaType
+//
+// Filterable declares that collections of the media type can be filtered by the given attribute
+// using the given operators, Eq if none is given. Actions that return collections of the media type
+// accept a "filter[attribute][operator]" query string parameter per operator. The operators are
+// Eq, Ne, Lt, Lte, Gt, Gte and Prefix (string attributes only). Example:
+//
+//	MediaType("application/vnd.goa.example.bottle", func() {
+//		Attributes(func() {
+//			Attribute("id", Integer)
+//			Attribute("name", String)
+//			Attribute("vintage", Integer)
+//		})
+//		Filterable("name", Eq, Prefix)	// ?filter[name][prefix]=chateau
+//		Filterable("vintage", Gte, Lte)	// ?filter[vintage][gte]=2000&filter[vintage][lte]=2010
+//		Sortable("name", "vintage")	// ?sort=-vintage,name
+//		SparseFieldsets()		// ?fields=id,name
+//		View("default", func() {
+//			Attribute("id")
+//			Attribute("name")
+//			Attribute("vintage")
+//		})
+//	})
+func Filterable(name string, ops ...design.FilterOperator) {
+	if mt, ok := mediaTypeDefinition(); ok {
+		for _, f := range mt.Filters {
+			if f.Attribute == name {
+				dslengine.ReportError("duplicate filter definition for attribute %#v", name)
+				return
+			}
+		}
+		if len(ops) == 0 {
+			ops = []design.FilterOperator{design.Eq}
+		}
+		mt.Filters = append(mt.Filters, &design.FilterDefinition{Attribute: name, Operators: ops})
+	}
+}
+
+// Sortable can be used in: MediaType
+//
+// Sortable declares that collections of the media type can be sorted by the given attributes.
+// Actions that return collections of the media type accept a "sort" query string parameter
+// listing the attributes to sort by separated with commas, each optionally prefixed with "-" for
+// descending order. See Filterable for an example.
+func Sortable(names ...string) {
+	if mt, ok := mediaTypeDefinition(); ok {
+		mt.SortFields = names
+	}
+}
+
+// SparseFieldsets can be used in: MediaType
+//
+// SparseFieldsets lets clients select the attributes rendered in responses that use the media
+// type or a collection of it. The actions accept a "fields" query string parameter listing the
+// names of the attributes separated with commas, the response only includes these attributes.
+// See Filterable for an example.
+func SparseFieldsets() {
+	if mt, ok := mediaTypeDefinition(); ok {
+		mt.SparseFieldsets = true
+	}
+}
+
 // CollectionOf creates a collection media type from its element media type and an optional
 // identifier. A collection media type represents the content of responses that return a collection
 // of resources such as "list" actions. This function can be called from any place where a media
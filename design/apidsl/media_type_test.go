@@ -823,3 +823,95 @@ var _ = Describe("Example", func() {
 		})
 	})
 })
+
+var _ = Describe("Filterable", func() {
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = func() {
+			Filterable("name", Eq, Prefix)
+			Filterable("vintage")
+			Sortable("name", "vintage")
+			SparseFieldsets()
+		}
+	})
+
+	JustBeforeEach(func() {
+		bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("name", String)
+				Attribute("vintage", Integer)
+			})
+			dsl()
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				Response(OK, CollectionOf(bottle))
+			})
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(OK, bottle)
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the filters, sort fields and sparse fieldsets", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		mt := Design.MediaTypes["application/vnd.bottle"]
+		Ω(mt.Filters).Should(HaveLen(2))
+		Ω(mt.Filters[0].Operators).Should(Equal([]FilterOperator{Eq, Prefix}))
+		Ω(mt.Filters[1].Operators).Should(Equal([]FilterOperator{Eq}))
+		Ω(mt.SortFields).Should(Equal([]string{"name", "vintage"}))
+		Ω(mt.SparseFieldsets).Should(BeTrue())
+	})
+
+	It("adds the query string params to the collection actions", func() {
+		params := Design.Resources["bottle"].Actions["list"].Params.Type.ToObject()
+		Ω(params).Should(HaveKey("filter[name][eq]"))
+		Ω(params["filter[name][prefix]"].Type).Should(Equal(String))
+		Ω(params["filter[vintage][eq]"].Type).Should(Equal(Integer))
+		Ω(params["sort"].Validation.Pattern).Should(Equal("^-?(name|vintage)(,-?(name|vintage))*$"))
+		Ω(params["fields"].Validation.Pattern).Should(Equal("^(id|name)(,(id|name))*$"))
+	})
+
+	It("only adds the fields param to the other actions", func() {
+		params := Design.Resources["bottle"].Actions["show"].Params.Type.ToObject()
+		Ω(params).Should(HaveKey("fields"))
+		Ω(params).ShouldNot(HaveKey("sort"))
+		Ω(params).ShouldNot(HaveKey("filter[name][eq]"))
+	})
+
+	Context("with an invalid operator", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Filterable("vintage", Prefix)
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`filter operator "prefix" does not apply to attribute "vintage"`))
+		})
+	})
+
+	Context("with an unknown sort field", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Sortable("price")
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown attribute "price"`))
+		})
+	})
+})
@@ -62,6 +62,7 @@ func Resource(name string, dsl func()) *design.ResourceDefinition {
 		return nil
 	}
 	resource := design.NewResourceDefinition(name, dsl)
+	dslengine.RecordLocation(resource)
 	design.Design.Resources[name] = resource
 	return resource
 }
@@ -88,6 +88,7 @@ func Response(name string, paramsAndDSL ...interface{}) {
 				resp.ViewName = def.Parent.DefaultViewName
 			}
 			resp.Parent = def
+			dslengine.RecordLocation(resp)
 			def.Responses[name] = resp
 		}
 
@@ -105,6 +106,7 @@ func Response(name string, paramsAndDSL ...interface{}) {
 				resp.ViewName = def.DefaultViewName
 			}
 			resp.Parent = def
+			dslengine.RecordLocation(resp)
 			def.Responses[name] = resp
 		}
 
@@ -12,8 +12,9 @@ import (
 // API level, it will apply to all resources by default, following the same logic.
 //
 // The scheme refers to previous definitions of either OAuth2Security, BasicAuthSecurity,
-// APIKeySecurity or JWTSecurity.  It can be a string, corresponding to the first parameter of
-// those definitions, or a SecuritySchemeDefinition, returned by those same functions. Examples:
+// APIKeySecurity, JWTSecurity, MTLSSecurity or HTTPSigSecurity.  It can be a string, corresponding to the first
+// parameter of those definitions, or a SecuritySchemeDefinition, returned by those same functions.
+// Examples:
 //
 //    Security(BasicAuth)
 //
@@ -21,29 +22,42 @@ import (
 //        Scope("api:read")  // Requires "api:read" oauth2 scope
 //    })
 //
-func Security(scheme interface{}, dsl ...func()) {
-	var def *design.SecurityDefinition
-	switch val := scheme.(type) {
-	case string:
-		def = &design.SecurityDefinition{}
-		for _, scheme := range design.Design.SecuritySchemes {
-			if scheme.SchemeName == val {
-				def.Scheme = scheme
+// Security accepts additional schemes that must all be satisfied together with the first one.
+// Calling Security multiple times in the same definition declares alternative requirements:
+// requests are authorized if they satisfy any of them, the requirements are tried in the order in
+// which they are declared. Example:
+//
+//    Action("update", func() {
+//        Security("mtls", "jwt", func() { // Requires both a client certificate and a JWT
+//            Scope("api:write")
+//        })
+//        Security("api_key")              // or alternatively an API key
+//    })
+//
+func Security(scheme interface{}, args ...interface{}) {
+	def := &design.SecurityDefinition{Scheme: securityScheme(scheme)}
+	if def.Scheme == nil {
+		return
+	}
+	var dsl func()
+	for i, arg := range args {
+		if fn, ok := arg.(func()); ok {
+			if i != len(args)-1 {
+				dslengine.ReportError("DSL function must be last argument")
+				return
 			}
+			dsl = fn
+			continue
 		}
-		if def.Scheme == nil {
-			dslengine.ReportError("security scheme %q not found", val)
+		additional := securityScheme(arg)
+		if additional == nil {
 			return
 		}
-	case *design.SecuritySchemeDefinition:
-		def = &design.SecurityDefinition{Scheme: val}
-	default:
-		dslengine.ReportError("invalid value for 'scheme' parameter, specify a string or a *SecuritySchemeDefinition")
-		return
+		def.AdditionalSchemes = append(def.AdditionalSchemes, additional)
 	}
 
-	if len(dsl) != 0 {
-		if !dslengine.Execute(dsl[0], def) {
+	if dsl != nil {
+		if !dslengine.Execute(dsl, def) {
 			return
 		}
 	}
@@ -51,19 +65,48 @@ func Security(scheme interface{}, dsl ...func()) {
 	parentDef := dslengine.CurrentDefinition()
 	switch parent := parentDef.(type) {
 	case *design.ActionDefinition:
-		parent.Security = def
+		parent.Security = appendSecurity(parent.Security, def)
 	case *design.FileServerDefinition:
-		parent.Security = def
+		parent.Security = appendSecurity(parent.Security, def)
 	case *design.ResourceDefinition:
-		parent.Security = def
+		parent.Security = appendSecurity(parent.Security, def)
 	case *design.APIDefinition:
-		parent.Security = def
+		parent.Security = appendSecurity(parent.Security, def)
 	default:
 		dslengine.IncompatibleDSL()
 		return
 	}
 }
 
+// securityScheme returns the security scheme identified by the given Security argument, either a
+// scheme name or a *SecuritySchemeDefinition. It reports an error and returns nil if the scheme is
+// not found or the argument is invalid.
+func securityScheme(scheme interface{}) *design.SecuritySchemeDefinition {
+	switch val := scheme.(type) {
+	case string:
+		for _, scheme := range design.Design.SecuritySchemes {
+			if scheme.SchemeName == val {
+				return scheme
+			}
+		}
+		dslengine.ReportError("security scheme %q not found", val)
+	case *design.SecuritySchemeDefinition:
+		return val
+	default:
+		dslengine.ReportError("invalid value for 'scheme' parameter, specify a string or a *SecuritySchemeDefinition")
+	}
+	return nil
+}
+
+// appendSecurity adds def as an alternative to the existing security requirements if any.
+func appendSecurity(existing, def *design.SecurityDefinition) *design.SecurityDefinition {
+	if existing == nil || existing.Scheme.Kind == design.NoSecurityKind {
+		return def
+	}
+	existing.Alternatives = append(existing.Alternatives, def)
+	return existing
+}
+
 // NoSecurity can be used in: API, Action, Files, Resource
 //
 // NoSecurity resets the authentication schemes for an Action or a Resource. It also prevents
@@ -264,6 +307,48 @@ func JWTSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
 	return def
 }
 
+// MTLSSecurity is a top level DSL.
+// MTLSSecurity defines a mutual TLS security scheme: clients authenticate by presenting a
+// certificate which the server verifies during the TLS handshake. The subjects, SANs or SPIFFE IDs
+// that are allowed to access the API are configured at runtime when mounting the middleware, see
+// package github.com/goadesign/goa/middleware/security/mtls.
+//
+// Swagger 2.0 cannot express mutual TLS, the swagger generator documents the requirement in the
+// description of the operations instead.
+//
+// Example:
+//
+//    MTLSSecurity("mtls", func() {
+//        Description("Internal services authenticate with client certificates")
+//    })
+//
+func MTLSSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition, *dslengine.TopLevelDefinition:
+	default:
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+
+	if securitySchemeRedefined(name) {
+		return nil
+	}
+
+	def := &design.SecuritySchemeDefinition{
+		SchemeName: name,
+		Kind:       design.MTLSSecurityKind,
+		Type:       "mutualTLS",
+	}
+
+	if len(dsl) != 0 {
+		def.DSLFunc = dsl[0]
+	}
+
+	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+
+	return def
+}
+
 // Scope can be used in: Security, JWTSecurity, OAuth2Security
 //
 // Scope defines an authorization scope. Used within SecurityScheme, a description may be provided
@@ -401,3 +486,47 @@ func TokenURL(tokenURL string) {
 	}
 	dslengine.IncompatibleDSL()
 }
+
+// HTTPSigSecurity is a top level DSL.
+// HTTPSigSecurity defines a security scheme where clients sign requests following the HTTP message
+// signatures specification. The signature covers the request method, path, query string and body
+// digest by default and is sent in the Signature and Signature-Input headers. The keys, the covered
+// components and the replay protection are configured at runtime when mounting the middleware, see
+// package github.com/goadesign/goa/middleware/security/httpsig.
+//
+// The generated client signs requests automatically with the client package HTTPSigSigner.
+//
+// Example:
+//
+//    HTTPSigSecurity("sig", func() {
+//        Description("Partners sign requests with their shared secret")
+//    })
+//
+func HTTPSigSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition, *dslengine.TopLevelDefinition:
+	default:
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+
+	if securitySchemeRedefined(name) {
+		return nil
+	}
+
+	def := &design.SecuritySchemeDefinition{
+		SchemeName: name,
+		Kind:       design.HTTPSigSecurityKind,
+		Type:       "apiKey",
+		In:         "header",
+		Name:       "Signature",
+	}
+
+	if len(dsl) != 0 {
+		def.DSLFunc = dsl[0]
+	}
+
+	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+
+	return def
+}
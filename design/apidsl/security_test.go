@@ -175,6 +175,142 @@ var _ = Describe("Security", func() {
 
 	})
 
+	Context("with mutual TLS security", func() {
+		It("should pass with valid values when well defined", func() {
+			API("", func() {
+				MTLSSecurity("mtls", func() {
+					Description("Client certificates")
+				})
+			})
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/first"))
+					Security("mtls")
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes).Should(HaveLen(1))
+			scheme := Design.SecuritySchemes[0]
+			Ω(scheme.Kind).Should(Equal(MTLSSecurityKind))
+			Ω(scheme.Type).Should(Equal("mutualTLS"))
+			Ω(scheme.Description).Should(Equal("Client certificates"))
+			Ω(Design.Resources["one"].Actions["first"].Security.Scheme).Should(Equal(scheme))
+		})
+
+		It("should fail because of invalid declaration of Header", func() {
+			API("", func() {
+				MTLSSecurity("mtls", func() {
+					Header("invalid")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with HTTP message signature security", func() {
+		It("should pass with valid values when well defined", func() {
+			API("", func() {
+				HTTPSigSecurity("sig", func() {
+					Description("Signed requests")
+				})
+			})
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(POST("/first"))
+					Security("sig")
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes).Should(HaveLen(1))
+			scheme := Design.SecuritySchemes[0]
+			Ω(scheme.Kind).Should(Equal(HTTPSigSecurityKind))
+			Ω(scheme.Context()).Should(Equal("HTTPSigSecurity"))
+			Ω(scheme.Type).Should(Equal("apiKey"))
+			Ω(scheme.In).Should(Equal("header"))
+			Ω(scheme.Name).Should(Equal("Signature"))
+			Ω(Design.Resources["one"].Actions["first"].Security.Scheme).Should(Equal(scheme))
+		})
+
+		It("should fail because of invalid declaration of Header", func() {
+			API("", func() {
+				HTTPSigSecurity("sig", func() {
+					Header("X-Signature")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with composite security requirements", func() {
+		BeforeEach(func() {
+			API("", func() {
+				MTLSSecurity("mtls")
+				JWTSecurity("jwt", func() {
+					Header("Authorization")
+					Scope("read", "Read")
+				})
+				APIKeySecurity("key", func() {
+					Header("X-Key")
+				})
+			})
+		})
+
+		It("should define conjunctive and alternative requirements", func() {
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/first"))
+					Security("mtls", "jwt", func() {
+						Scope("read")
+					})
+					Security("key")
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			sec := Design.Resources["one"].Actions["first"].Security
+			Ω(sec.Scheme.SchemeName).Should(Equal("mtls"))
+			Ω(sec.AdditionalSchemes).Should(HaveLen(1))
+			Ω(sec.AdditionalSchemes[0].SchemeName).Should(Equal("jwt"))
+			Ω(sec.Scopes).Should(Equal([]string{"read"}))
+			Ω(sec.Alternatives).Should(HaveLen(1))
+			Ω(sec.Alternatives[0].Scheme.SchemeName).Should(Equal("key"))
+			Ω(sec.IsComposite()).Should(BeTrue())
+			Ω(sec.String()).Should(Equal("mtls+jwt|key"))
+		})
+
+		It("should fail with an unknown additional scheme", func() {
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/first"))
+					Security("mtls", "unknown")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+
+		It("should fail when the DSL is not the last argument", func() {
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/first"))
+					Security("mtls", func() {}, "jwt")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
 	Context("with resources and actions", func() {
 		It("should fallback properly to lower-level security", func() {
 			API("", func() {
@@ -34,8 +34,18 @@ import (
 //		Attribute("Country")
 //	})
 //
+// The optional primitive type given before the DSL defines a named primitive type. String and
+// Integer types that list their values with Enum are enum types: the generated code defines a Go
+// type with one constant per value. The constants are named after the type and the values, the
+// "enum:names" metadata overrides the value part of the names:
+//
+//	var BottleStatus = Type("BottleStatus", String, func() {
+//		Enum("open", "sealed")
+//		Metadata("enum:names", "Opened", "Sealed") // BottleStatusOpened and BottleStatusSealed
+//	})
+//
 // This function returns the newly defined type so the value can be used throughout the dsl.
-func Type(name string, dsl func()) *design.UserTypeDefinition {
+func Type(name string, args ...interface{}) *design.UserTypeDefinition {
 	if design.Design.Types == nil {
 		design.Design.Types = make(map[string]*design.UserTypeDefinition)
 	} else if _, ok := design.Design.Types[name]; ok {
@@ -48,15 +58,35 @@ func Type(name string, dsl func()) *design.UserTypeDefinition {
 		return nil
 	}
 
+	var (
+		base design.DataType
+		dsl  func()
+	)
+	for _, arg := range args {
+		switch actual := arg.(type) {
+		case design.Primitive:
+			base = actual
+		case func():
+			dsl = actual
+		case nil:
+		default:
+			dslengine.InvalidArgError("primitive type or DSL function", arg)
+			return nil
+		}
+	}
+
 	t := &design.UserTypeDefinition{
 		TypeName:            name,
 		AttributeDefinition: &design.AttributeDefinition{DSLFunc: dsl},
 	}
-	if dsl == nil {
+	if base != nil {
+		t.Type = base
+	} else if dsl == nil {
 		t.Type = design.String
 	} else {
 		t.Type = make(design.Object)
 	}
+	dslengine.RecordLocation(t)
 	design.Design.Types[name] = t
 	return t
 }
@@ -169,6 +199,71 @@ func HashOf(k, v interface{}, dsls ...func()) *design.Hash {
 	return &design.Hash{KeyType: &kat, ElemType: &vat}
 }
 
+// OneOf can be used in: Type
+//
+// OneOf defines the type as a union: its values are one of the object types listed by the DSL, the
+// variants. The DSL defines each variant with Attribute, the attribute name is the name of the
+// variant. The optional Discriminator argument sets the name of the attribute whose value is the
+// name of the variant, values of unions with no discriminator must match exactly one variant.
+// Example:
+//
+//	var PaymentMethod = Type("PaymentMethod", func() {
+//		Description("PaymentMethod describes how an order is paid")
+//		OneOf(func() {
+//			Attribute("card", Card)
+//			Attribute("bank_account", BankAccount)
+//			Attribute("wallet", func() {
+//				Attribute("provider", String)
+//			})
+//		}, Discriminator("kind"))
+//	})
+//
+// The JSON representation of a PaymentMethod paid with a card is the representation of the card
+// with the "kind" attribute set to "card".
+func OneOf(dsl func(), opts ...UnionOption) {
+	att, ok := dslengine.CurrentDefinition().(*design.AttributeDefinition)
+	if !ok || !isUserTypeAttribute(att) {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if o := att.Type.ToObject(); len(o) > 0 {
+		dslengine.ReportError("OneOf cannot be used in a type that defines attributes")
+		return
+	}
+	variants := &design.AttributeDefinition{Type: make(design.Object)}
+	if !dslengine.Execute(dsl, variants) {
+		return
+	}
+	u := &design.Union{Variants: variants.Type.ToObject()}
+	for _, opt := range opts {
+		opt(u)
+	}
+	att.Type = u
+}
+
+// UnionOption is the type of the optional arguments of OneOf.
+type UnionOption func(*design.Union)
+
+// Discriminator can be used in: OneOf
+//
+// Discriminator sets the name of the attribute of the union values whose value is the name of the
+// variant, see OneOf.
+func Discriminator(name string) UnionOption {
+	return func(u *design.Union) {
+		u.Discriminator = name
+	}
+}
+
+// isUserTypeAttribute returns true if att is the attribute of a user type defined with Type.
+func isUserTypeAttribute(att *design.AttributeDefinition) bool {
+	for _, ut := range design.Design.Types {
+		if ut.AttributeDefinition == att {
+			return true
+		}
+	}
+	return false
+}
+
 func resolveType(v interface{}) design.DataType {
 	if t, ok := v.(design.DataType); ok {
 		return t
@@ -10,6 +10,7 @@ import (
 
 var _ = Describe("Type", func() {
 	var name string
+	var base DataType
 	var dsl func()
 
 	var ut *UserTypeDefinition
@@ -17,11 +18,12 @@ var _ = Describe("Type", func() {
 	BeforeEach(func() {
 		dslengine.Reset()
 		name = ""
+		base = nil
 		dsl = nil
 	})
 
 	JustBeforeEach(func() {
-		Type(name, dsl)
+		Type(name, base, dsl)
 		dslengine.Run()
 		ut, _ = Design.Types[name]
 	})
@@ -106,6 +108,116 @@ var _ = Describe("Type", func() {
 			Ω(o[attName].Type).Should(Equal(DateTime))
 		})
 	})
+
+	Context("with a primitive type and an enum", func() {
+		BeforeEach(func() {
+			name = "status"
+			base = String
+			dsl = func() {
+				Enum("open", "sealed")
+				Metadata("enum:names", "Opened", "Sealed")
+			}
+		})
+
+		It("produces an enum type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut).ShouldNot(BeNil())
+			Ω(ut.Type).Should(Equal(String))
+			Ω(ut.Validation.Values).Should(Equal([]interface{}{"open", "sealed"}))
+			Ω(ut.Metadata["enum:names"]).Should(Equal([]string{"Opened", "Sealed"}))
+			Ω(ut.IsEnum()).Should(BeTrue())
+		})
+	})
+
+	Context("with an enum that lists too few names", func() {
+		BeforeEach(func() {
+			name = "status"
+			base = String
+			dsl = func() {
+				Enum("open", "sealed")
+				Metadata("enum:names", "Opened")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("lists 1 names for 2 values"))
+		})
+	})
+
+	Context("with enum values that produce the same constant name", func() {
+		BeforeEach(func() {
+			name = "status"
+			base = String
+			dsl = func() {
+				Enum("in-cellar", "in_cellar")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`enum values "in-cellar" and "in_cellar" produce the same constant name`))
+		})
+
+		Context("and enum:names metadata", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Enum("in-cellar", "in_cellar")
+					Metadata("enum:names", "Stored", "Legacy")
+				}
+			})
+
+			It("does not produce an error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with enum values that produce the same prefixed constant name", func() {
+		BeforeEach(func() {
+			name = "status"
+			base = String
+			dsl = func() {
+				Enum("1", "Value1")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`enum values "1" and "Value1" produce the same constant name`))
+		})
+	})
+
+	Context("with enum names that produce the same constant name", func() {
+		BeforeEach(func() {
+			name = "status"
+			base = String
+			dsl = func() {
+				Enum("open", "sealed")
+				Metadata("enum:names", "is open", "IsOpen")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`enum:names metadata names "is open" and "IsOpen" produce the same constant name`))
+		})
+	})
+
+	Context("with an enum of numbers", func() {
+		BeforeEach(func() {
+			name = "ratio"
+			base = Number
+			dsl = func() {
+				Enum(0.5, 1.5)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("enum types must be strings or integers"))
+		})
+	})
 })
 
 var _ = Describe("ArrayOf", func() {
@@ -289,3 +401,106 @@ var _ = Describe("HashOf", func() {
 		})
 	})
 })
+
+var _ = Describe("OneOf", func() {
+	var dsl func()
+
+	var ut *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		Type("card", func() {
+			Attribute("number", String)
+		})
+		Type("payment", dsl)
+		dslengine.Run()
+		ut = Design.Types["payment"]
+	})
+
+	Context("with variants", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf(func() {
+					Attribute("card", "card")
+					Attribute("wallet", func() {
+						Attribute("provider", String)
+					})
+				}, Discriminator("kind"))
+			}
+		})
+
+		It("produces a union type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut.IsUnion()).Should(BeTrue())
+			u := ut.ToUnion()
+			Ω(u.Discriminator).Should(Equal("kind"))
+			Ω(u.VariantNames()).Should(Equal([]string{"card", "wallet"}))
+			Ω(u.Variants["card"].Type).Should(Equal(Design.Types["card"]))
+			Ω(u.Variants["wallet"].Type.ToObject()).Should(HaveKey("provider"))
+		})
+	})
+
+	Context("with no discriminator", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf(func() {
+					Attribute("card", "card")
+				})
+			}
+		})
+
+		It("produces a union type with no discriminator", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut.ToUnion().Discriminator).Should(BeEmpty())
+		})
+	})
+
+	Context("in a type that defines attributes", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Attribute("id", Integer)
+				OneOf(func() {
+					Attribute("card", "card")
+				})
+			}
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("in an attribute", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Attribute("method", func() {
+					OneOf(func() {
+						Attribute("card", "card")
+					})
+				})
+			}
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a variant that is not an object", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				OneOf(func() {
+					Attribute("cash", Integer)
+				})
+			}
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,112 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// AddedIn can be used in: Resource, Action, Attribute, View
+//
+// AddedIn declares that the definition is included in the given version of the API and in the
+// versions that follow it, see Version. Example:
+//
+//	Resource("bottle", func() {
+//		Action("rate", func() {
+//			AddedIn("v2")
+//			Routing(PUT("/:id/rating"))
+//			Params(func() {
+//				Param("id", Integer)
+//				Param("stars", Integer, func() {
+//					AddedIn("v3")
+//				})
+//			})
+//		})
+//	})
+//
+// Attributes used in media type views follow the lifecycle of the media type attributes, AddedIn
+// used in a View applies to the whole view.
+func AddedIn(version string) {
+	if l, ok := lifecycleDefinition(); ok {
+		l.AddedIn = version
+	}
+}
+
+// RemovedIn can be used in: Resource, Action, Attribute, View
+//
+// RemovedIn declares that the definition is removed from the given version of the API and from
+// the versions that follow it, see Version.
+func RemovedIn(version string) {
+	if l, ok := lifecycleDefinition(); ok {
+		l.RemovedIn = version
+	}
+}
+
+// Deprecated can be used in: Version, Resource, Action, Attribute, View
+//
+// Deprecated declares that the definition is deprecated. The optional version argument is the
+// first version of the API that deprecates the definition, all versions do by default. The optional
+// DSL sets the date after which the definition is expected to be removed with Sunset.
+//
+// The generated code adds the Deprecation and, with a sunset date, the Sunset (RFC 8594) headers
+// to the responses of the deprecated actions, versions and resources. The generated specs flag the
+// deprecated operations. Examples:
+//
+//	Action("list", func() {
+//		Deprecated()
+//	})
+//
+//	Action("show", func() {
+//		Deprecated("v2", func() {
+//			Sunset("2027-06-30")
+//		})
+//	})
+//
+func Deprecated(args ...interface{}) {
+	var (
+		since string
+		dsl   func()
+	)
+	for _, arg := range args {
+		switch actual := arg.(type) {
+		case string:
+			since = actual
+		case func():
+			dsl = actual
+		default:
+			dslengine.ReportError("invalid Deprecated argument %#v, must be a version name or a DSL function", arg)
+			return
+		}
+	}
+	var parent dslengine.Definition
+	var deprecation **design.DeprecationDefinition
+	if v, ok := dslengine.CurrentDefinition().(*design.VersionDefinition); ok {
+		if since != "" {
+			dslengine.ReportError("cannot set the deprecation version of a version")
+			return
+		}
+		parent, deprecation = v, &v.Deprecation
+	} else {
+		l, ok := lifecycleDefinition()
+		if !ok {
+			return
+		}
+		parent, deprecation = dslengine.CurrentDefinition(), &l.Deprecation
+	}
+	d := &design.DeprecationDefinition{Parent: parent, Since: since}
+	if dsl != nil {
+		if !dslengine.Execute(dsl, d) {
+			return
+		}
+	}
+	*deprecation = d
+}
+
+// Sunset can be used in: Deprecated
+//
+// Sunset sets the date after which the deprecated definition is expected to be removed. The date
+// is formatted as "2006-01-02" or RFC 3339.
+func Sunset(date string) {
+	if d, ok := deprecationDefinition(); ok {
+		d.Sunset = date
+	}
+}
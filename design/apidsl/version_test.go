@@ -0,0 +1,239 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version", func() {
+	var scheme VersioningScheme
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		scheme = ""
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		API("cellar", func() {
+			BasePath("/api")
+			Version("v1", func() {
+				Description("first version")
+				Deprecated(func() {
+					Sunset("2027-06-30")
+				})
+			})
+			Version("v2")
+			if scheme != "" {
+				VersionedBy(scheme)
+			}
+		})
+		bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("name", String)
+				Attribute("vintage", Integer, func() {
+					RemovedIn("v2")
+				})
+				Attribute("color", String, func() {
+					AddedIn("v2")
+				})
+				Required("name", "vintage")
+			})
+			View("default", func() {
+				Attribute("name")
+				Attribute("vintage")
+				Attribute("color")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(OK, bottle)
+			})
+			Action("list", func() {
+				RemovedIn("v2")
+				Routing(GET(""))
+				Response(OK, CollectionOf(bottle))
+			})
+			Action("rate", func() {
+				AddedIn("v2")
+				Deprecated("v2")
+				Routing(PUT("/:id/rating"))
+				Response(NoContent)
+			})
+		})
+		if dsl != nil {
+			dsl()
+		}
+		dslengine.Run()
+	})
+
+	It("declares the versions", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(Design.Versions).Should(HaveLen(2))
+		Ω(Design.IsVersioned()).Should(BeTrue())
+		Ω(Design.Version).Should(Equal("v2"))
+		Ω(Design.VersionScheme).Should(Equal(PathVersioning))
+		v1 := Design.VersionDefinition("v1")
+		Ω(v1).ShouldNot(BeNil())
+		Ω(v1.Description).Should(Equal("first version"))
+		Ω(v1.Deprecation).ShouldNot(BeNil())
+		Ω(v1.Deprecation.SunsetHeader()).Should(Equal("Wed, 30 Jun 2027 00:00:00 GMT"))
+	})
+
+	It("records the lifecycles", func() {
+		list := Design.Resources["bottle"].Actions["list"]
+		Ω(list.Lifecycle).ShouldNot(BeNil())
+		Ω(list.Lifecycle.RemovedIn).Should(Equal("v2"))
+		rate := Design.Resources["bottle"].Actions["rate"]
+		Ω(rate.Lifecycle.AddedIn).Should(Equal("v2"))
+		Ω(rate.Lifecycle.Deprecation).ShouldNot(BeNil())
+		Ω(rate.Lifecycle.Deprecation.Since).Should(Equal("v2"))
+	})
+
+	It("projects the design on a version", func() {
+		v1 := Design.ForVersion("v1")
+		Ω(v1.BasePath).Should(Equal("/api/v1"))
+		Ω(v1.Resources["bottle"].Actions).Should(HaveKey("list"))
+		Ω(v1.Resources["bottle"].Actions).ShouldNot(HaveKey("rate"))
+		bottle := v1.MediaTypes["application/vnd.bottle"]
+		Ω(bottle.Type.ToObject()).Should(HaveKey("vintage"))
+		Ω(bottle.Type.ToObject()).ShouldNot(HaveKey("color"))
+		Ω(bottle.Views["default"].Type.ToObject()).ShouldNot(HaveKey("color"))
+
+		v2 := Design.ForVersion("v2")
+		Ω(v2.BasePath).Should(Equal("/api/v2"))
+		Ω(v2.Resources["bottle"].Actions).ShouldNot(HaveKey("list"))
+		Ω(v2.Resources["bottle"].Actions).Should(HaveKey("rate"))
+		bottle = v2.MediaTypes["application/vnd.bottle"]
+		Ω(bottle.Type.ToObject()).ShouldNot(HaveKey("vintage"))
+		Ω(bottle.Validation.Required).Should(Equal([]string{"name"}))
+		Ω(Design.MediaTypes["application/vnd.bottle"].Type.ToObject()).Should(HaveKey("vintage"))
+	})
+
+	It("computes the deprecations of the actions per version", func() {
+		err := Design.WithVersion("v1", func(api *APIDefinition) error {
+			Ω(api.Resources["bottle"].Actions["show"].Deprecation()).Should(Equal(api.Versions[0].Deprecation))
+			return nil
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		err = Design.WithVersion("v2", func(api *APIDefinition) error {
+			Ω(api.Resources["bottle"].Actions["show"].Deprecation()).Should(BeNil())
+			Ω(api.Resources["bottle"].Actions["rate"].Deprecation()).ShouldNot(BeNil())
+			return nil
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(Design.Version).Should(Equal("v2"))
+	})
+
+	Context("versioned by header", func() {
+		BeforeEach(func() {
+			scheme = HeaderVersioning
+		})
+
+		It("uses the default header and leaves the base path alone", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.VersionScheme).Should(Equal(HeaderVersioning))
+			Ω(Design.VersionKey).Should(Equal("X-API-Version"))
+			Ω(Design.ForVersion("v1").BasePath).Should(Equal("/api"))
+		})
+	})
+
+	Context("with an invalid scheme", func() {
+		BeforeEach(func() {
+			scheme = "query"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an unknown version", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Resource("account", func() {
+					AddedIn("v3")
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown version "v3"`))
+		})
+	})
+
+	Context("with an invalid sunset date", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Resource("account", func() {
+					Deprecated(func() {
+						Sunset("next year")
+					})
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("sunset"))
+		})
+	})
+	Context("with a versioned required attribute in a nested payload type", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				address := Type("address", func() {
+					Attribute("street", String)
+					Attribute("zip", String, func() {
+						AddedIn("v2")
+					})
+					Required("street", "zip")
+				})
+				Resource("account", func() {
+					Action("create", func() {
+						Routing(POST("/accounts"))
+						Payload(func() {
+							Attribute("address", address)
+						})
+						Response(NoContent)
+					})
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`required attribute "zip" of type address is added or removed in a version`))
+		})
+	})
+
+	Context("with a versioned required attribute at the top level of the payload", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Resource("account", func() {
+					Action("create", func() {
+						Routing(POST("/accounts"))
+						Payload(func() {
+							Attribute("name", String)
+							Attribute("zip", String, func() {
+								AddedIn("v2")
+							})
+							Required("name", "zip")
+						})
+						Response(NoContent)
+					})
+				})
+			}
+		})
+
+		It("does not produce an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+	})
+})
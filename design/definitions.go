@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -21,8 +23,18 @@ type (
 		Title string
 		// Description of API
 		Description string
-		// Version is the version of the API described by this design.
+		// Version is the version of the API described by this design, the latest version if
+		// the design declares several.
 		Version string
+		// Versions lists the versions declared with Version in order, the design describes
+		// multiple coexisting versions if there are several.
+		Versions []*VersionDefinition
+		// VersionScheme defines how requests select the API version if the design declares
+		// several.
+		VersionScheme VersioningScheme
+		// VersionKey is the name of the request header or media type parameter that selects
+		// the API version with HeaderVersioning or MediaTypeVersioning.
+		VersionKey string
 		// Host is the default API hostname
 		Host string
 		// Schemes is the supported API URL schemes
@@ -144,6 +156,50 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+		// Lifecycle records the API versions that include the resource if any.
+		Lifecycle *LifecycleDefinition
+	}
+
+	// VersioningScheme identifies how requests select the API version, one of PathVersioning,
+	// HeaderVersioning or MediaTypeVersioning.
+	VersioningScheme string
+
+	// VersionDefinition describes one of the versions of the API.
+	VersionDefinition struct {
+		// Parent API
+		Parent *APIDefinition
+		// Name of the version, e.g. "v2"
+		Name string
+		// Description of the changes introduced by the version
+		Description string
+		// Deprecation describes the deprecation of the whole version if any.
+		Deprecation *DeprecationDefinition
+	}
+
+	// LifecycleDefinition records the versions of the API that include a resource, an action
+	// or an attribute, including the attributes that define media type views.
+	LifecycleDefinition struct {
+		// AddedIn is the name of the first version that includes the definition, empty if
+		// all versions up to RemovedIn include it.
+		AddedIn string
+		// RemovedIn is the name of the first version that no longer includes the definition,
+		// empty if it is never removed.
+		RemovedIn string
+		// Deprecation describes the deprecation of the definition if any.
+		Deprecation *DeprecationDefinition
+	}
+
+	// DeprecationDefinition describes the deprecation of a version, a resource, an action or an
+	// attribute.
+	DeprecationDefinition struct {
+		// Parent is the deprecated definition.
+		Parent dslengine.Definition
+		// Since is the name of the first version that deprecates the definition, empty if it
+		// is deprecated in all versions.
+		Since string
+		// Sunset is the date after which the definition is expected to be removed formatted
+		// as "2006-01-02" or RFC 3339, empty if none.
+		Sunset string
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -164,6 +220,8 @@ type (
 		Credentials bool
 		// Sets Whether the Origin string is a regular expression
 		Regexp bool
+		// Allows preflight requests with Access-Control-Request-Private-Network
+		PrivateNetwork bool
 	}
 
 	// EncodingDefinition defines an encoder supported by the API.
@@ -247,12 +305,53 @@ type (
 		PayloadOptional bool
 		// PayloadOptional is true if the request payload is multipart, false otherwise.
 		PayloadMultipart bool
+		// Patch describes the partial update sent in the request body if the action is
+		// defined with MergePatch or JSONPatch. Payload describes the patch document.
+		Patch *PatchDefinition
+		// Pagination describes how the action splits its results into pages if the action is
+		// defined with Paginated.
+		Pagination *PaginationDefinition
 		// Request headers that need to be made available to action
 		Headers *AttributeDefinition
+		// Request cookies that need to be made available to action
+		Cookies *AttributeDefinition
 		// Metadata is a list of key/value pairs
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// Lifecycle records the API versions that include the action if any.
+		Lifecycle *LifecycleDefinition
+	}
+
+	// PatchDefinition describes the partial update of a user type or media type value sent in a
+	// request body.
+	PatchDefinition struct {
+		// Format is the Content-Type of the patch document, MergePatchContentType or
+		// JSONPatchContentType.
+		Format string
+		// Target is the type of the values the patch applies to, a *UserTypeDefinition or a
+		// *MediaTypeDefinition.
+		Target DataType
+	}
+
+	// PaginationStrategy identifies how the pages of results of a paginated action are
+	// requested, either Cursor or Offset.
+	PaginationStrategy string
+
+	// PaginationDefinition describes how a paginated action splits its results into pages.
+	PaginationDefinition struct {
+		// Parent action
+		Parent *ActionDefinition
+		// Strategy used to identify the pages.
+		Strategy PaginationStrategy
+		// MaxPageSize is the maximum value of the "limit" parameter, zero if there is none.
+		MaxPageSize int
+		// DefaultPageSize is the number of results in a page when the request does not set
+		// the "limit" parameter.
+		DefaultPageSize int
+		// Envelope is true if the response body wraps the page of results together with the
+		// pagination fields, false if the pages are linked with RFC 8288 Link headers.
+		Envelope bool
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -333,6 +432,10 @@ type (
 		// NonZeroAttributes lists the names of the child attributes that cannot have a
 		// zero value (and thus whose presence does not need to be validated).
 		NonZeroAttributes map[string]bool
+		// Nullable is true if the attribute may be explicitly set to null, see Nullable.
+		Nullable bool
+		// Lifecycle records the API versions that include the attribute if any.
+		Lifecycle *LifecycleDefinition
 		// DSLFunc contains the initialization DSL. This is used for user types.
 		DSLFunc func()
 	}
@@ -659,6 +762,10 @@ func (a *APIDefinition) DSL() func() {
 // Finalize sets the Consumes and Produces fields to the defaults if empty.
 // Also it records built-in media types that are used by the user design.
 func (a *APIDefinition) Finalize() {
+	if a.IsVersioned() && a.VersionScheme == "" {
+		a.VersionScheme = PathVersioning
+	}
+	inlineEnums(a.Params)
 	if len(a.Consumes) == 0 {
 		a.Consumes = DefaultDecoders
 	}
@@ -697,6 +804,68 @@ func (a *APIDefinition) Finalize() {
 	})
 }
 
+// Context returns the generic definition name used in error messages.
+func (p *PaginationDefinition) Context() string {
+	if p.Parent != nil {
+		return fmt.Sprintf("pagination of %s", p.Parent.Context())
+	}
+	return "unnamed pagination"
+}
+
+// ParamName returns the name of the query string parameter that sets the value of the filter for
+// the given operator.
+func (f *FilterDefinition) ParamName(op FilterOperator) string {
+	return fmt.Sprintf("filter[%s][%s]", f.Attribute, op)
+}
+
+// describe returns the comparison made by the operator in descriptions.
+func (op FilterOperator) describe() string {
+	switch op {
+	case Ne:
+		return "different from"
+	case Lt:
+		return "lower than"
+	case Lte:
+		return "lower than or equal to"
+	case Gt:
+		return "greater than"
+	case Gte:
+		return "greater than or equal to"
+	case Prefix:
+		return "prefixed with"
+	default:
+		return "equal to"
+	}
+}
+
+// IsMergePatch returns true if the patch is a JSON merge patch, false if it is a JSON patch.
+func (p *PatchDefinition) IsMergePatch() bool {
+	return p.Format == MergePatchContentType
+}
+
+// PatchEncodings returns the decoding definitions of the patch documents accepted by the API
+// actions whose Content-Type is not listed in Consumes. encoder selects encoding definitions
+// instead, e.g. for clients.
+func (a *APIDefinition) PatchEncodings(encoder bool) []*EncodingDefinition {
+	seen := make(map[string]bool)
+	for _, enc := range a.Consumes {
+		for _, m := range enc.MIMETypes {
+			seen[m] = true
+		}
+	}
+	var encs []*EncodingDefinition
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(action *ActionDefinition) error {
+			if p := action.Patch; p != nil && !seen[p.Format] {
+				seen[p.Format] = true
+				encs = append(encs, &EncodingDefinition{MIMETypes: []string{p.Format}, Encoder: encoder})
+			}
+			return nil
+		})
+	})
+	return encs
+}
+
 // NewResourceDefinition creates a resource definition but does not
 // execute the DSL.
 func NewResourceDefinition(name string, dsl func()) *ResourceDefinition {
@@ -894,6 +1063,8 @@ func (r *ResourceDefinition) DSL() func() {
 // parameters, initializes querystring parameters, sets path parameters as non zero attributes
 // and sets the fallbacks for security schemes.
 func (r *ResourceDefinition) Finalize() {
+	inlineEnums(r.Params)
+	inlineEnums(r.Headers)
 	meta := r.Metadata["swagger:generate"]
 	r.IterateFileServers(func(f *FileServerDefinition) error {
 		if meta != nil {
@@ -1054,6 +1225,10 @@ func (a *AttributeDefinition) IsPrimitivePointer(attName string) bool {
 	if att == nil {
 		return false
 	}
+	if att.Nullable {
+		// Nullable attributes are held in a value that records whether they are set.
+		return false
+	}
 	if att.Type.IsPrimitive() {
 		return (!a.IsRequired(attName) && !a.HasDefaultValue(attName) && !a.IsNonZero(attName) && !a.IsInterface(attName)) || a.IsFile(attName)
 	}
@@ -1086,6 +1261,44 @@ func (a *AttributeDefinition) IsFile(attName string) bool {
 	return att.Type.Kind() == FileKind
 }
 
+// NumericFormat returns the format of the attribute if it is an Integer or Number attribute
+// defined with one of IntegerFormats or NumberFormats, the empty string otherwise.
+func (a *AttributeDefinition) NumericFormat() string {
+	if a.Type == nil || a.Validation == nil || a.Validation.Format == "" {
+		return ""
+	}
+	var formats []string
+	switch a.Type.Kind() {
+	case IntegerKind:
+		formats = IntegerFormats
+	case NumberKind:
+		formats = NumberFormats
+	default:
+		return ""
+	}
+	for _, f := range formats {
+		if f == a.Validation.Format {
+			return f
+		}
+	}
+	return ""
+}
+
+// IsStringEncoded returns true if the values of the field generated for the given attribute are
+// encoded as JSON strings, that is if the attribute uses the "decimal" format or if it uses the
+// "int64" format. The target attribute must be an object.
+func (a *AttributeDefinition) IsStringEncoded(attName string) bool {
+	if !a.Type.IsObject() {
+		panic("checking string encoding on non-object") // bug
+	}
+	att := a.Type.ToObject()[attName]
+	if att == nil {
+		return false
+	}
+	f := att.NumericFormat()
+	return f == "decimal" || f == "int64"
+}
+
 // SetExample sets the custom example. SetExample also handles the case when the user doesn't
 // want any example or any auto-generated example.
 func (a *AttributeDefinition) SetExample(example interface{}) bool {
@@ -1152,10 +1365,14 @@ func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string)
 	ary := a.Type.ToArray()
 	ln := newExampleGenerator(a, rand).ExampleLength()
 	var res []interface{}
-	for i := 0; i < ln; i++ {
-		ex := ary.ElemType.GenerateExample(rand, seen)
-		if ex != nil {
-			res = append(res, ex)
+	if a.Validation != nil && a.Validation.UniqueItems {
+		res = ary.ElemType.uniqueExamples(ln, rand, seen)
+	} else {
+		for i := 0; i < ln; i++ {
+			ex := ary.ElemType.GenerateExample(rand, seen)
+			if ex != nil {
+				res = append(res, ex)
+			}
 		}
 	}
 	if len(res) == 0 {
@@ -1168,11 +1385,19 @@ func (a *AttributeDefinition) hashExample(rand *RandomGenerator, seen []string)
 	h := a.Type.ToHash()
 	ln := newExampleGenerator(a, rand).ExampleLength()
 	res := make(map[interface{}]interface{})
-	for i := 0; i < ln; i++ {
-		k := h.KeyType.GenerateExample(rand, seen)
-		v := h.ElemType.GenerateExample(rand, seen)
-		if k != nil && v != nil {
-			res[k] = v
+	if a.Validation != nil && (a.Validation.MinProperties != nil || a.Validation.MaxProperties != nil) {
+		for _, k := range h.KeyType.uniqueExamples(ln, rand, seen) {
+			if v := h.ElemType.GenerateExample(rand, seen); v != nil {
+				res[k] = v
+			}
+		}
+	} else {
+		for i := 0; i < ln; i++ {
+			k := h.KeyType.GenerateExample(rand, seen)
+			v := h.ElemType.GenerateExample(rand, seen)
+			if k != nil && v != nil {
+				res[k] = v
+			}
 		}
 	}
 	if len(res) == 0 {
@@ -1181,6 +1406,33 @@ func (a *AttributeDefinition) hashExample(rand *RandomGenerator, seen []string)
 	return h.MakeMap(res)
 }
 
+// uniqueExamples returns up to n distinct examples of the attribute. Examples of primitive
+// attributes that do not define an example are generated anew, other attributes produce a single
+// example.
+func (a *AttributeDefinition) uniqueExamples(n int, rand *RandomGenerator, seen []string) []interface{} {
+	if a.Example != nil || !a.Type.IsPrimitive() {
+		if ex := a.GenerateExample(rand, seen); ex != nil {
+			return []interface{}{ex}
+		}
+		return nil
+	}
+	var res []interface{}
+	for attempts := 0; len(res) < n && attempts < maxAttempts; attempts++ {
+		ex := newExampleGenerator(a, rand).Generate(seen)
+		dup := ex == nil
+		for _, e := range res {
+			if reflect.DeepEqual(e, ex) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			res = append(res, ex)
+		}
+	}
+	return res
+}
+
 func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string) interface{} {
 	// project media types
 	actual := a
@@ -1210,9 +1462,19 @@ func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string
 	for _, n := range keys {
 		att := aObj[n]
 		if ex := att.GenerateExample(rand, seen); ex != nil {
+			if actual.IsStringEncoded(n) {
+				ex = fmt.Sprint(ex)
+			}
 			res[n] = ex
 		}
 	}
+	val := actual.Validation
+	if ds, ok := actual.Type.(DataStructure); ok && ds.Definition().Validation != nil {
+		val = ds.Definition().Validation
+	}
+	if val != nil && val.HasCrossFieldRules() {
+		applyCrossFieldRules(res, val)
+	}
 	if len(res) > 0 {
 		a.Example = res
 	}
@@ -1220,6 +1482,44 @@ func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string
 	return a.Example
 }
 
+// applyCrossFieldRules removes attributes from the object example ex so that it satisfies the
+// OneOfRequired, MutuallyExclusive and DependentRequired validations: only the first attribute of
+// each group is kept and attributes whose dependencies were removed are removed as well.
+func applyCrossFieldRules(ex map[string]interface{}, val *dslengine.ValidationDefinition) {
+	keepFirst := func(names []string) {
+		found := false
+		for _, n := range names {
+			if _, ok := ex[n]; ok {
+				if found {
+					delete(ex, n)
+				}
+				found = true
+			}
+		}
+	}
+	for _, names := range val.OneOfRequired {
+		keepFirst(names)
+	}
+	for _, names := range val.MutuallyExclusive {
+		keepFirst(names)
+	}
+	for removed := true; removed; {
+		removed = false
+		for n, deps := range val.DependentRequired {
+			if _, ok := ex[n]; !ok {
+				continue
+			}
+			for _, d := range deps {
+				if _, ok := ex[d]; !ok {
+					delete(ex, n)
+					removed = true
+					break
+				}
+			}
+		}
+	}
+}
+
 // Merge merges the argument attributes into the target and returns the target overriding existing
 // attributes with identical names.
 // This only applies to attributes of type Object and Merge panics if the
@@ -1295,6 +1595,9 @@ func (a *AttributeDefinition) inheritRecursive(parent *AttributeDefinition, seen
 			if att.View == "" {
 				att.View = patt.View
 			}
+			if !att.Nullable {
+				att.Nullable = patt.Nullable
+			}
 			if att.Type == nil {
 				att.Type = patt.Type
 			} else if att.shouldInherit(patt) {
@@ -1575,9 +1878,14 @@ func (a *ActionDefinition) Finalize() {
 		a.Payload.Finalize()
 	}
 
+	inlineEnums(a.Params)
+	inlineEnums(a.Headers)
+	inlineEnums(a.Cookies)
 	a.mergeResponses()
 	a.initImplicitParams()
+	a.initShapingParams()
 	a.initQueryParams()
+	a.initPaginationHeaders()
 }
 
 // UserTypes returns all the user types used by the action payload and parameters.
@@ -1619,6 +1927,13 @@ func (a *ActionDefinition) IterateHeaders(it HeaderIterator) error {
 	return iterateHeaders(mergedHeaders, isRequired, it)
 }
 
+// IterateCookies calls the given iterator passing in each action cookie sorted in alphabetical
+// order. Iteration stops if an iterator returns an error and in this case IterateCookies returns
+// that error.
+func (a *ActionDefinition) IterateCookies(it HeaderIterator) error {
+	return iterateHeaders(a.Cookies, a.Cookies.IsRequired, it)
+}
+
 // IterateResponses calls the given iterator passing in each response sorted in alphabetical order.
 // Iteration stops if an iterator returns an error and in this case IterateResponses returns that
 // error.
@@ -1732,6 +2047,204 @@ func (a *ActionDefinition) initQueryParams() {
 	}
 }
 
+// inlineEnums replaces the enum user types used by the given params or headers with their base
+// type and validations. Params and headers are strings on the wire, the generated code represents
+// them with their base Go type.
+func inlineEnums(params *AttributeDefinition) {
+	if params == nil {
+		return
+	}
+	obj, ok := params.Type.(Object)
+	if !ok {
+		return
+	}
+	for _, att := range obj {
+		inlineEnum(att)
+		if a, ok := att.Type.(*Array); ok {
+			inlineEnum(a.ElemType)
+		}
+	}
+}
+
+// inlineEnum replaces the type of att with the base type of its enum user type if it has one.
+func inlineEnum(att *AttributeDefinition) {
+	ut, ok := att.Type.(*UserTypeDefinition)
+	if !ok || !ut.IsEnum() {
+		return
+	}
+	val := new(dslengine.ValidationDefinition)
+	if att.Validation != nil {
+		val = att.Validation.Dup()
+	}
+	val.Merge(ut.Validation)
+	att.Type = ut.Type
+	att.Validation = val
+	if att.Description == "" {
+		att.Description = ut.Description
+	}
+}
+
+// PageMediaType returns the collection media type of the OK response of a paginated action, nil
+// if the action is not paginated or if its OK response does not use a collection media type.
+func (a *ActionDefinition) PageMediaType() *MediaTypeDefinition {
+	if a.Pagination == nil {
+		return nil
+	}
+	mt, _ := a.okMediaType()
+	if mt == nil || !mt.IsArray() {
+		return nil
+	}
+	return mt
+}
+
+// ShapedMediaType returns the media type rendered by the OK response of the action or the element
+// media type if the response renders a collection. The boolean is true in the latter case. The
+// filters, sort fields and sparse fieldsets of the media type apply to the action.
+func (a *ActionDefinition) ShapedMediaType() (*MediaTypeDefinition, bool) {
+	mt, _ := a.okMediaType()
+	if mt == nil || !mt.IsArray() {
+		return mt, false
+	}
+	elem, ok := mt.ToArray().ElemType.Type.(*MediaTypeDefinition)
+	if !ok {
+		return nil, false
+	}
+	return elem, true
+}
+
+// okMediaType returns the media type and view of the OK response of the action if any.
+func (a *ActionDefinition) okMediaType() (*MediaTypeDefinition, string) {
+	resp, ok := a.Responses[OK]
+	if !ok {
+		return nil, ""
+	}
+	mt, ok := resp.Type.(*MediaTypeDefinition)
+	if !ok {
+		mt = Design.MediaTypeWithIdentifier(resp.MediaType)
+	}
+	view := resp.ViewName
+	if view == "" {
+		view = DefaultView
+	}
+	return mt, view
+}
+
+// initShapingParams adds the "filter[attribute][operator]" and "sort" parameters to actions that
+// return collections of media types that define filters or sort fields as well as the "fields"
+// parameter to actions that return media types that support sparse fieldsets. Parameters defined
+// explicitly by the action are left untouched.
+func (a *ActionDefinition) initShapingParams() {
+	mt, collection := a.ShapedMediaType()
+	if mt == nil {
+		return
+	}
+	params := make(Object)
+	if collection {
+		obj := mt.ToObject()
+		for _, f := range mt.Filters {
+			att, ok := obj[f.Attribute]
+			if !ok {
+				continue
+			}
+			for _, op := range f.Operators {
+				param := &AttributeDefinition{
+					Type:        att.Type,
+					Description: fmt.Sprintf("Filter the results whose %s is %s the value", f.Attribute, op.describe()),
+				}
+				if op == Prefix {
+					param.Type = String
+				}
+				// Filters are query string parameters, use the base type of enum types as
+				// for the other parameters.
+				inlineEnum(param)
+				params[f.ParamName(op)] = param
+			}
+		}
+		if len(mt.SortFields) > 0 {
+			params["sort"] = &AttributeDefinition{
+				Type: String,
+				Description: "Comma separated list of the attributes to sort the results by, prefixed " +
+					"with - for descending order: " + strings.Join(mt.SortFields, ", "),
+				Validation: &dslengine.ValidationDefinition{Pattern: listPattern("-?", mt.SortFields)},
+			}
+		}
+	}
+	if mt.SparseFieldsets {
+		_, view := a.okMediaType()
+		obj := mt.ToObject()
+		if v, ok := mt.Views[view]; ok {
+			obj = v.Type.ToObject()
+		}
+		names := make([]string, 0, len(obj))
+		for n := range obj {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		params["fields"] = &AttributeDefinition{
+			Type: String,
+			Description: "Comma separated list of the attributes to render in the response: " +
+				strings.Join(names, ", "),
+			Validation: &dslengine.ValidationDefinition{Pattern: listPattern("", names)},
+		}
+	}
+	if len(params) == 0 {
+		return
+	}
+	if a.Params == nil {
+		a.Params = &AttributeDefinition{Type: Object{}}
+	}
+	obj := a.Params.Type.ToObject()
+	for n, p := range params {
+		if _, ok := obj[n]; !ok {
+			obj[n] = p
+		}
+	}
+}
+
+// listPattern returns a regular expression that matches comma separated lists of the given names,
+// each name optionally preceded with prefix.
+func listPattern(prefix string, names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = regexp.QuoteMeta(n)
+	}
+	item := prefix + "(" + strings.Join(quoted, "|") + ")"
+	return "^" + item + "(," + item + ")*$"
+}
+
+// initPaginationHeaders documents the Link header of the OK response of actions whose pages are
+// linked with RFC 8288 Link headers, as well as the X-Total-Count header with offset pagination.
+func (a *ActionDefinition) initPaginationHeaders() {
+	p := a.Pagination
+	if p == nil || p.Envelope {
+		return
+	}
+	resp, ok := a.Responses[OK]
+	if !ok {
+		return
+	}
+	// Copy the headers, they may be shared with the response template.
+	headers := &AttributeDefinition{Type: Object{}}
+	if resp.Headers != nil {
+		headers = DupAtt(resp.Headers)
+		headers.Type = Dup(headers.Type)
+	}
+	obj := headers.Type.ToObject()
+	if _, ok := obj["Link"]; !ok {
+		obj["Link"] = &AttributeDefinition{
+			Type:        String,
+			Description: "RFC 8288 links to the neighboring pages of results",
+		}
+	}
+	if _, ok := obj["X-Total-Count"]; !ok && p.Strategy == Offset {
+		obj["X-Total-Count"] = &AttributeDefinition{
+			Type:        Integer,
+			Description: "Total number of results in the collection if known",
+		}
+	}
+	resp.Headers = headers
+}
+
 // Context returns the generic definition name used in error messages.
 func (f *FileServerDefinition) Context() string {
 	suffix := fmt.Sprintf("file server %s", f.FilePath)
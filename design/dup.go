@@ -47,6 +47,8 @@ func (d *dupper) DupAttribute(att *AttributeDefinition) *AttributeDefinition {
 		Metadata:          att.Metadata,
 		DefaultValue:      att.DefaultValue,
 		NonZeroAttributes: att.NonZeroAttributes,
+		Nullable:          att.Nullable,
+		Lifecycle:         att.Lifecycle,
 		View:              att.View,
 		DSLFunc:           att.DSLFunc,
 		Example:           att.Example,
@@ -72,6 +74,12 @@ func (d *dupper) DupType(t DataType) DataType {
 			KeyType:  d.DupAttribute(actual.KeyType),
 			ElemType: d.DupAttribute(actual.ElemType),
 		}
+	case *Union:
+		variants := make(Object, len(actual.Variants))
+		for n, att := range actual.Variants {
+			variants[n] = d.DupAttribute(att)
+		}
+		return &Union{Variants: variants, Discriminator: actual.Discriminator}
 	case *UserTypeDefinition:
 		if u, ok := d.dts[actual.TypeName]; ok {
 			return u
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"strconv"
 	"time"
 
 	regen "github.com/zach-klippenstein/goregen"
@@ -33,6 +34,10 @@ func (eg *exampleGenerator) Generate(seen []string) interface{} {
 	if eg.hasEnumValidation() {
 		return eg.generateValidatedEnumExample()
 	}
+	// Numeric formats and multiple of validations restrict the range of the generated values
+	if eg.a.NumericFormat() != "" || eg.hasMultipleOfValidation() {
+		return eg.generateNumericFormatExample(seen)
+	}
 	// loop until a satisified example is generated
 	hasFormat, hasPattern, hasMinMax := eg.hasFormatValidation(), eg.hasPatternValidation(), eg.hasMinMaxValidation()
 	attempts := 0
@@ -67,7 +72,7 @@ func (eg *exampleGenerator) Generate(seen []string) interface{} {
 }
 
 func (eg *exampleGenerator) ExampleLength() int {
-	if eg.hasLengthValidation() {
+	if eg.hasLengthValidation() || eg.hasPropertiesValidation() {
 		minlength, maxlength := math.Inf(1), math.Inf(-1)
 		if eg.a.Validation.MinLength != nil {
 			minlength = float64(*eg.a.Validation.MinLength)
@@ -75,6 +80,12 @@ func (eg *exampleGenerator) ExampleLength() int {
 		if eg.a.Validation.MaxLength != nil {
 			maxlength = float64(*eg.a.Validation.MaxLength)
 		}
+		if eg.a.Validation.MinProperties != nil {
+			minlength = float64(*eg.a.Validation.MinProperties)
+		}
+		if eg.a.Validation.MaxProperties != nil {
+			maxlength = float64(*eg.a.Validation.MaxProperties)
+		}
 		count := 0
 		if math.IsInf(minlength, 1) {
 			count = int(maxlength) - (eg.r.Int() % 3)
@@ -109,6 +120,13 @@ func (eg *exampleGenerator) hasLengthValidation() bool {
 	return eg.a.Validation.MinLength != nil || eg.a.Validation.MaxLength != nil
 }
 
+func (eg *exampleGenerator) hasPropertiesValidation() bool {
+	if eg.a.Validation == nil || !eg.a.Type.IsHash() {
+		return false
+	}
+	return eg.a.Validation.MinProperties != nil || eg.a.Validation.MaxProperties != nil
+}
+
 const maxExampleLength = 10
 
 // generateValidatedLengthExample generates a random size array of examples based on what's given.
@@ -173,6 +191,85 @@ func (eg *exampleGenerator) generateFormatExample() interface{} {
 	panic("Validation: unknown format '" + format + "'") // bug
 }
 
+// generateNumericFormatExample returns a random number that fits the numeric format and the
+// multiple of validation of the attribute. Decimal examples are strings.
+func (eg *exampleGenerator) generateNumericFormatExample(seen []string) interface{} {
+	var example interface{}
+	if eg.hasMultipleOfValidation() {
+		example = eg.generateValidatedMultipleOfExample()
+	} else if eg.hasMinMaxValidation() {
+		example = eg.generateValidatedMinMaxValueExample()
+	} else {
+		example = eg.a.Type.GenerateExample(eg.r, seen)
+	}
+	switch eg.a.NumericFormat() {
+	case "int32":
+		if v := example.(int); v > math.MaxInt32 || v < math.MinInt32 {
+			example = v % math.MaxInt32
+		}
+	case "uint", "uint32", "uint64":
+		v := example.(int)
+		if v < 0 {
+			v = -v
+		}
+		if eg.a.Validation.Format == "uint32" && int64(v) > math.MaxUint32 {
+			v = v % math.MaxUint32
+		}
+		example = v
+	case "float32":
+		example = float64(float32(example.(float64)))
+	case "decimal":
+		prec := 2
+		if eg.hasMultipleOfValidation() {
+			prec = -1
+		}
+		example = strconv.FormatFloat(example.(float64), 'f', prec, 64)
+	}
+	return example
+}
+
+func (eg *exampleGenerator) hasMultipleOfValidation() bool {
+	return eg.a.Validation != nil && eg.a.Validation.MultipleOf != nil
+}
+
+// generateValidatedMultipleOfExample returns a random multiple of the multiple of validation value
+// that satisfies the minimum and maximum validations if any.
+func (eg *exampleGenerator) generateValidatedMultipleOfExample() interface{} {
+	v := eg.a.Validation
+	m := *v.MultipleOf
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if v.Minimum != nil {
+		lo = math.Ceil(*v.Minimum / m)
+		if v.ExclusiveMinimum && lo*m <= *v.Minimum {
+			lo++
+		}
+	}
+	if v.Maximum != nil {
+		hi = math.Floor(*v.Maximum / m)
+		if v.ExclusiveMaximum && hi*m >= *v.Maximum {
+			hi--
+		}
+	}
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+		lo, hi = 1, 10
+	case math.IsInf(lo, -1):
+		lo = hi - 9
+	case math.IsInf(hi, 1) || hi-lo > 9:
+		hi = lo + 9
+	}
+	if hi < lo {
+		hi = lo
+	}
+	k := lo + float64(eg.r.Int()%int(hi-lo+1))
+	if eg.a.Type.Kind() == IntegerKind {
+		return int(k * m)
+	}
+	// Round to get rid of the floating point errors (e.g. 3 * 0.1 = 0.30000000000000004)
+	res, _ := strconv.ParseFloat(strconv.FormatFloat(k*m, 'g', 12, 64), 64)
+	return res
+}
+
 func (eg *exampleGenerator) hasPatternValidation() bool {
 	return eg.a.Validation != nil && eg.a.Validation.Pattern != ""
 }
@@ -217,21 +314,22 @@ func (eg *exampleGenerator) checkMinMaxValueValidation(example interface{}) bool
 	if !eg.hasMinMaxValidation() {
 		return true
 	}
-	valid := true
+	var f float64
+	switch v := example.(type) {
+	case int:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return true
+	}
 	if min := eg.a.Validation.Minimum; min != nil {
-		if v, ok := example.(int); ok && float64(v) < *min {
-			valid = false
-		} else if v, ok := example.(float64); ok && v < *min {
-			valid = false
+		if f < *min || (eg.a.Validation.ExclusiveMinimum && f == *min) {
+			return false
 		}
 	}
-	if !valid {
-		return false
-	}
 	if max := eg.a.Validation.Maximum; max != nil {
-		if v, ok := example.(int); ok && float64(v) > *max {
-			return false
-		} else if v, ok := example.(float64); ok && v > *max {
+		if f > *max || (eg.a.Validation.ExclusiveMaximum && f == *max) {
 			return false
 		}
 	}
@@ -249,6 +347,15 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 	if eg.a.Validation.Maximum != nil {
 		max = *eg.a.Validation.Maximum
 	}
+	if eg.a.Type.Kind() == IntegerKind {
+		// Exclusive bounds exclude the bound values
+		if eg.a.Validation.ExclusiveMinimum && !math.IsInf(min, 1) {
+			min++
+		}
+		if eg.a.Validation.ExclusiveMaximum && !math.IsInf(max, -1) {
+			max--
+		}
+	}
 	if math.IsInf(min, 1) {
 		if eg.a.Type.Kind() == IntegerKind {
 			if max == 0 {
@@ -256,7 +363,7 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 			}
 			return eg.r.Int() % int(max)
 		}
-		return eg.r.Float64() * max
+		return eg.excludeBounds(eg.r.Float64()*max, min, max)
 	} else if math.IsInf(max, -1) {
 		if eg.a.Type.Kind() == IntegerKind {
 			if min == 0 {
@@ -264,12 +371,12 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 			}
 			return int(min) + eg.r.Int()%int(min)
 		}
-		return min + eg.r.Float64()*min
+		return eg.excludeBounds(min+eg.r.Float64()*min, min, max)
 	} else if min < max {
 		if eg.a.Type.Kind() == IntegerKind {
 			return int(min) + eg.r.Int()%int(max-min)
 		}
-		return min + eg.r.Float64()*(max-min)
+		return eg.excludeBounds(min+eg.r.Float64()*(max-min), min, max)
 	} else if min == max {
 		if eg.a.Type.Kind() == IntegerKind {
 			return int(min)
@@ -278,3 +385,20 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 	}
 	panic("Validation: Min > Max")
 }
+
+// excludeBounds moves the number v away from min and max if they are exclusive bounds.
+func (eg *exampleGenerator) excludeBounds(v, min, max float64) float64 {
+	if eg.a.Validation.ExclusiveMinimum && v <= min {
+		if math.IsInf(max, -1) {
+			return min + 1
+		}
+		return min + (max-min)/2
+	}
+	if eg.a.Validation.ExclusiveMaximum && v >= max {
+		if math.IsInf(min, 1) {
+			return max - 1
+		}
+		return max - (max-min)/2
+	}
+	return v
+}
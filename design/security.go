@@ -3,6 +3,7 @@ package design
 import (
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/goadesign/goa/dslengine"
 )
@@ -22,20 +23,67 @@ const (
 	JWTSecurityKind
 	// NoSecurityKind means to have no security for this endpoint.
 	NoSecurityKind
+	// MTLSSecurityKind means "mutualTLS" security type, the client authenticates with a
+	// certificate verified during the TLS handshake.
+	MTLSSecurityKind
+	// HTTPSigSecurityKind means an "apiKey" security type where the "Signature" header contains
+	// an HTTP message signature of the request.
+	HTTPSigSecurityKind
 )
 
 // SecurityDefinition defines security requirements for an Action
 type SecurityDefinition struct {
 	// Scheme defines the Security Scheme used for this action.
 	Scheme *SecuritySchemeDefinition
+	// AdditionalSchemes lists the security schemes that must be satisfied together with
+	// Scheme.
+	AdditionalSchemes []*SecuritySchemeDefinition
 
 	// Scopes are scopes required for this action
 	Scopes []string `json:"scopes,omitempty"`
+
+	// Alternatives lists the security requirements that may be satisfied instead of this one,
+	// in order of preference.
+	Alternatives []*SecurityDefinition
 }
 
 // Context returns the generic definition name used in error messages.
 func (s *SecurityDefinition) Context() string { return "Security" }
 
+// AllSchemes returns the security schemes that must all be satisfied for the requirement: Scheme
+// followed by AdditionalSchemes.
+func (s *SecurityDefinition) AllSchemes() []*SecuritySchemeDefinition {
+	return append([]*SecuritySchemeDefinition{s.Scheme}, s.AdditionalSchemes...)
+}
+
+// Requirements returns the alternative security requirements in order of preference: s followed by
+// its Alternatives. A request is authorized if it satisfies any of them.
+func (s *SecurityDefinition) Requirements() []*SecurityDefinition {
+	return append([]*SecurityDefinition{s}, s.Alternatives...)
+}
+
+// IsComposite returns true if the security definition requires more than one scheme or lists
+// alternatives.
+func (s *SecurityDefinition) IsComposite() bool {
+	return len(s.AdditionalSchemes) > 0 || len(s.Alternatives) > 0
+}
+
+// String returns a textual representation of the security requirements, e.g. "mtls+jwt|api_key"
+// for an action that requires either both the "mtls" and "jwt" schemes or the "api_key" scheme.
+func (s *SecurityDefinition) String() string {
+	reqs := s.Requirements()
+	descs := make([]string, len(reqs))
+	for i, req := range reqs {
+		schemes := req.AllSchemes()
+		names := make([]string, len(schemes))
+		for j, scheme := range schemes {
+			names[j] = scheme.SchemeName
+		}
+		descs[i] = strings.Join(names, "+")
+	}
+	return strings.Join(descs, "|")
+}
+
 // SecuritySchemeDefinition defines a security scheme used to
 // authenticate against the API being designed. See
 // http://swagger.io/specification/#securityDefinitionsObject for more
@@ -51,7 +99,7 @@ type SecuritySchemeDefinition struct {
 	SchemeName string `json:"scheme"`
 
 	// Type is one of "apiKey", "oauth2" or "basic", according to the
-	// Swagger specs. We also support "jwt".
+	// Swagger specs. We also support "jwt" and "mutualTLS".
 	Type string `json:"type"`
 	// Description describes the security scheme. Ex: "Google OAuth2"
 	Description string `json:"description"`
@@ -90,6 +138,10 @@ func (s *SecuritySchemeDefinition) Context() string {
 		dslFunc = "APIKeySecurity"
 	case JWTSecurityKind:
 		dslFunc = "JWTSecurity"
+	case MTLSSecurityKind:
+		dslFunc = "MTLSSecurity"
+	case HTTPSigSecurityKind:
+		dslFunc = "HTTPSigSecurity"
 	}
 	return dslFunc
 }
@@ -49,6 +49,9 @@ type (
 		// IsHash returns true if the underlying type is a hash map, a user type which
 		// is a hash map or a media type whose type is a hash map.
 		IsHash() bool
+		// IsUnion returns true if the underlying type is a union or a user type which is a
+		// union.
+		IsUnion() bool
 		// ToObject returns the underlying object if any (i.e. if IsObject returns true),
 		// nil otherwise.
 		ToObject() Object
@@ -58,6 +61,9 @@ type (
 		// ToHash returns the underlying hash map if any (i.e. if IsHash returns true),
 		// nil otherwise.
 		ToHash() *Hash
+		// ToUnion returns the underlying union if any (i.e. if IsUnion returns true),
+		// nil otherwise.
+		ToUnion() *Union
 		// CanHaveDefault returns whether the data type can have a default value.
 		CanHaveDefault() bool
 		// IsCompatible checks whether val has a Go type that is
@@ -106,6 +112,16 @@ type (
 	// HashVal is the value of a hash used to specify the default value.
 	HashVal map[interface{}]interface{}
 
+	// Union is the type for a value that is one of several object types, the variants.
+	Union struct {
+		// Variants lists the variants indexed by name.
+		Variants Object
+		// Discriminator is the name of the attribute whose value is the name of the
+		// variant in the union values. The values of a union with no discriminator match
+		// exactly one of the variants.
+		Discriminator string
+	}
+
 	// UserTypeDefinition is the type for user defined types that are not media types
 	// (e.g. payload types).
 	UserTypeDefinition struct {
@@ -135,7 +151,43 @@ type (
 		Views map[string]*ViewDefinition
 		// Resource this media type is the canonical representation for if any
 		Resource *ResourceDefinition
+		// Filters lists the attributes collections of the media type can be filtered by.
+		Filters []*FilterDefinition
+		// SortFields lists the names of the attributes collections of the media type can be
+		// sorted by.
+		SortFields []string
+		// SparseFieldsets is true if clients may select the attributes rendered in responses
+		// with the "fields" query string parameter.
+		SparseFieldsets bool
 	}
+
+	// FilterOperator identifies how a filter compares the values of an attribute.
+	FilterOperator string
+
+	// FilterDefinition describes the filters that may be applied to an attribute of a media type.
+	FilterDefinition struct {
+		// Attribute is the name of the filtered attribute.
+		Attribute string
+		// Operators lists the supported comparisons.
+		Operators []FilterOperator
+	}
+)
+
+const (
+	// Eq filters the values equal to the parameter.
+	Eq FilterOperator = "eq"
+	// Ne filters the values different from the parameter.
+	Ne FilterOperator = "ne"
+	// Lt filters the values lower than the parameter.
+	Lt FilterOperator = "lt"
+	// Lte filters the values lower than or equal to the parameter.
+	Lte FilterOperator = "lte"
+	// Gt filters the values greater than the parameter.
+	Gt FilterOperator = "gt"
+	// Gte filters the values greater than or equal to the parameter.
+	Gte FilterOperator = "gte"
+	// Prefix filters the string values that start with the parameter.
+	Prefix FilterOperator = "prefix"
 )
 
 const (
@@ -165,6 +217,8 @@ const (
 	MediaTypeKind
 	// FileKind represents a file.
 	FileKind
+	// UnionKind represents a union of object types.
+	UnionKind
 )
 
 const (
@@ -195,6 +249,18 @@ const (
 	File = Primitive(FileKind)
 )
 
+var (
+	// IntegerFormats lists the formats that set the size and signedness of Integer attributes.
+	// "int64" values are encoded as JSON strings when used as object fields so that JavaScript
+	// clients do not lose precision.
+	IntegerFormats = []string{"int32", "int64", "uint", "uint32", "uint64"}
+
+	// NumberFormats lists the formats that may be used with Number attributes. "decimal"
+	// values are encoded as JSON strings and represented with Go strings so that money amounts
+	// are not rounded.
+	NumberFormats = []string{"float32", "decimal"}
+)
+
 // DataType implementation
 
 // Kind implements DataKind.
@@ -235,6 +301,9 @@ func (p Primitive) IsArray() bool { return false }
 // IsHash returns false.
 func (p Primitive) IsHash() bool { return false }
 
+// IsUnion returns false.
+func (p Primitive) IsUnion() bool { return false }
+
 // ToObject returns nil.
 func (p Primitive) ToObject() Object { return nil }
 
@@ -244,6 +313,9 @@ func (p Primitive) ToArray() *Array { return nil }
 // ToHash returns nil.
 func (p Primitive) ToHash() *Hash { return nil }
 
+// ToUnion returns nil.
+func (p Primitive) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns whether the primitive can have a default value.
 func (p Primitive) CanHaveDefault() (ok bool) {
 	switch p {
@@ -336,6 +408,9 @@ func (a *Array) IsArray() bool { return true }
 // IsHash returns false.
 func (a *Array) IsHash() bool { return false }
 
+// IsUnion returns false.
+func (a *Array) IsUnion() bool { return false }
+
 // ToObject returns nil.
 func (a *Array) ToObject() Object { return nil }
 
@@ -345,6 +420,9 @@ func (a *Array) ToArray() *Array { return a }
 // ToHash returns nil.
 func (a *Array) ToHash() *Hash { return nil }
 
+// ToUnion returns nil.
+func (a *Array) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns true if the array type can have a default value.
 // The array type can have a default value only if the element type can
 // have a default value.
@@ -409,6 +487,9 @@ func (o Object) IsArray() bool { return false }
 // IsHash returns false.
 func (o Object) IsHash() bool { return false }
 
+// IsUnion returns false.
+func (o Object) IsUnion() bool { return false }
+
 // ToObject returns the underlying object.
 func (o Object) ToObject() Object { return o }
 
@@ -418,6 +499,9 @@ func (o Object) ToArray() *Array { return nil }
 // ToHash returns nil.
 func (o Object) ToHash() *Hash { return nil }
 
+// ToUnion returns nil.
+func (o Object) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns false.
 func (o Object) CanHaveDefault() bool { return false }
 
@@ -475,6 +559,9 @@ func (h *Hash) IsArray() bool { return false }
 // IsHash returns true.
 func (h *Hash) IsHash() bool { return true }
 
+// IsUnion returns false.
+func (h *Hash) IsUnion() bool { return false }
+
 // ToObject returns nil.
 func (h *Hash) ToObject() Object { return nil }
 
@@ -484,6 +571,9 @@ func (h *Hash) ToArray() *Array { return nil }
 // ToHash returns the underlying hash map.
 func (h *Hash) ToHash() *Hash { return h }
 
+// ToUnion returns nil.
+func (h *Hash) ToUnion() *Union { return nil }
+
 // CanHaveDefault returns true if the hash type can have a default value.
 // The hash type can have a default value only if both the key type and
 // the element type can have a default value.
@@ -528,6 +618,87 @@ func (h *Hash) MakeMap(m map[interface{}]interface{}) interface{} {
 	return hash.Interface()
 }
 
+// Kind implements DataKind.
+func (u *Union) Kind() Kind { return UnionKind }
+
+// Name returns the type name.
+func (u *Union) Name() string { return "union" }
+
+// IsPrimitive returns false.
+func (u *Union) IsPrimitive() bool { return false }
+
+// HasAttributes returns true.
+func (u *Union) HasAttributes() bool { return true }
+
+// IsObject returns false.
+func (u *Union) IsObject() bool { return false }
+
+// IsArray returns false.
+func (u *Union) IsArray() bool { return false }
+
+// IsHash returns false.
+func (u *Union) IsHash() bool { return false }
+
+// IsUnion returns true.
+func (u *Union) IsUnion() bool { return true }
+
+// ToObject returns nil.
+func (u *Union) ToObject() Object { return nil }
+
+// ToArray returns nil.
+func (u *Union) ToArray() *Array { return nil }
+
+// ToHash returns nil.
+func (u *Union) ToHash() *Hash { return nil }
+
+// ToUnion returns the underlying union.
+func (u *Union) ToUnion() *Union { return u }
+
+// CanHaveDefault returns false.
+func (u *Union) CanHaveDefault() bool { return false }
+
+// IsCompatible returns true if val is compatible with one of the variants.
+func (u *Union) IsCompatible(val interface{}) bool {
+	for _, att := range u.Variants {
+		if att.Type.IsCompatible(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateExample returns a random value of one of the variants with the discriminator
+// attribute set to the name of the variant if any.
+func (u *Union) GenerateExample(r *RandomGenerator, seen []string) interface{} {
+	names := u.VariantNames()
+	if len(names) == 0 {
+		return nil
+	}
+	name := names[r.Int()%len(names)]
+	ex := u.Variants[name].GenerateExample(r, seen)
+	if m, ok := ex.(map[string]interface{}); ok && u.Discriminator != "" {
+		// Copy the example so that the discriminator does not leak into the example of
+		// the variant type.
+		res := make(map[string]interface{}, len(m)+1)
+		for k, v := range m {
+			res[k] = v
+		}
+		res[u.Discriminator] = name
+		return res
+	}
+	return ex
+}
+
+// VariantNames returns the names of the variants sorted in alphabetical order.
+func (u *Union) VariantNames() []string {
+	names := make([]string, 0, len(u.Variants))
+	for n := range u.Variants {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // AttributeIterator is the type of the function given to IterateAttributes.
 type AttributeIterator func(string, *AttributeDefinition) error
 
@@ -568,6 +739,15 @@ func UserTypes(dt DataType) map[string]*UserTypeDefinition {
 		return nil
 	case *Array:
 		return UserTypes(actual.ElemType.Type)
+	case *Union:
+		types := make(map[string]*UserTypeDefinition)
+		for _, att := range actual.Variants {
+			att.Walk(collect(types))
+		}
+		if len(types) == 0 {
+			return nil
+		}
+		return types
 	case *Hash:
 		ktypes := UserTypes(actual.KeyType.Type)
 		vtypes := UserTypes(actual.ElemType.Type)
@@ -625,12 +805,32 @@ func HasFile(dt DataType) bool {
 				return true
 			}
 		}
+	case dt.IsUnion():
+		for _, att := range dt.ToUnion().Variants {
+			if HasFile(att.Type) {
+				return true
+			}
+		}
 	default:
 		panic("unknown type")
 	}
 	return false
 }
 
+// CanBeNullable returns true if attributes of the given type may be nullable, that is if the type
+// is a primitive other than a file or an array or hash of such primitives.
+func CanBeNullable(dt DataType) bool {
+	switch actual := dt.(type) {
+	case Primitive:
+		return actual.Kind() != FileKind
+	case *Array:
+		return CanBeNullable(actual.ElemType.Type)
+	case *Hash:
+		return CanBeNullable(actual.KeyType.Type) && CanBeNullable(actual.ElemType.Type)
+	}
+	return false
+}
+
 // ToSlice converts an ArrayVal to a slice.
 func (a ArrayVal) ToSlice() []interface{} {
 	arr := make([]interface{}, len(a))
@@ -693,6 +893,19 @@ func (u *UserTypeDefinition) IsArray() bool { return u.Type != nil && u.Type.IsA
 // IsHash calls IsHash on the user type underlying data type.
 func (u *UserTypeDefinition) IsHash() bool { return u.Type != nil && u.Type.IsHash() }
 
+// IsUnion calls IsUnion on the user type underlying data type.
+func (u *UserTypeDefinition) IsUnion() bool { return u.Type != nil && u.Type.IsUnion() }
+
+// IsEnum returns true if the user type is a String or Integer type that lists its values with an
+// enum validation. Enum types are generated as named Go types with one constant per value.
+func (u *UserTypeDefinition) IsEnum() bool {
+	if u.Type == nil || u.Validation == nil || len(u.Validation.Values) == 0 {
+		return false
+	}
+	k := u.Type.Kind()
+	return k == StringKind || k == IntegerKind
+}
+
 // ToObject calls ToObject on the user type underlying data type.
 func (u *UserTypeDefinition) ToObject() Object { return u.Type.ToObject() }
 
@@ -702,6 +915,9 @@ func (u *UserTypeDefinition) ToArray() *Array { return u.Type.ToArray() }
 // ToHash calls ToHash on the user type underlying data type.
 func (u *UserTypeDefinition) ToHash() *Hash { return u.Type.ToHash() }
 
+// ToUnion calls ToUnion on the user type underlying data type.
+func (u *UserTypeDefinition) ToUnion() *Union { return u.Type.ToUnion() }
+
 // CanHaveDefault calls CanHaveDefault on the user type underlying data type.
 func (u *UserTypeDefinition) CanHaveDefault() bool { return u.Type.CanHaveDefault() }
 
@@ -832,6 +1048,7 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 		}
 		val = m.Validation.Dup()
 		val.Required = required
+		projectCrossFieldRules(val, viewObj)
 	}
 
 	// Compute description
@@ -919,6 +1136,54 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 	return
 }
 
+// projectCrossFieldRules removes the cross-field validations that reference attributes missing
+// from the view object. Mutually exclusive attributes and dependent attributes that are missing are
+// removed from their rule.
+func projectCrossFieldRules(val *dslengine.ValidationDefinition, viewObj Object) {
+	inView := func(names []string) []string {
+		var res []string
+		for _, n := range names {
+			if _, ok := viewObj[n]; ok {
+				res = append(res, n)
+			}
+		}
+		return res
+	}
+	var oneOf, exclusive [][]string
+	for _, names := range val.OneOfRequired {
+		if len(inView(names)) == len(names) {
+			oneOf = append(oneOf, names)
+		}
+	}
+	for _, names := range val.MutuallyExclusive {
+		if names = inView(names); len(names) > 1 {
+			exclusive = append(exclusive, names)
+		}
+	}
+	var dependent map[string][]string
+	for n, deps := range val.DependentRequired {
+		if _, ok := viewObj[n]; !ok {
+			continue
+		}
+		if deps = inView(deps); len(deps) > 0 {
+			if dependent == nil {
+				dependent = make(map[string][]string)
+			}
+			dependent[n] = deps
+		}
+	}
+	var funcs []*dslengine.ValidationFuncDefinition
+	for _, f := range val.Funcs {
+		if len(inView(f.Attributes)) == len(f.Attributes) {
+			funcs = append(funcs, f)
+		}
+	}
+	val.OneOfRequired = oneOf
+	val.MutuallyExclusive = exclusive
+	val.DependentRequired = dependent
+	val.Funcs = funcs
+}
+
 func (m *MediaTypeDefinition) projectCollection(view string) (*MediaTypeDefinition, *UserTypeDefinition, error) {
 	// Project the collection element media type
 	e := m.ToArray().ElemType.Type.(*MediaTypeDefinition) // validation checked this cast would work
@@ -1050,6 +1315,12 @@ func walk(at *AttributeDefinition, walker func(*AttributeDefinition) error, seen
 				return err
 			}
 		}
+	case *Union:
+		for _, cat := range actual.Variants {
+			if err := walk(cat, walker, seen); err != nil {
+				return err
+			}
+		}
 	case *UserTypeDefinition:
 		return walkUt(actual)
 	case *MediaTypeDefinition:
@@ -1073,7 +1344,12 @@ func toReflectType(dtype DataType) reflect.Type {
 		return reflect.TypeOf("")
 	case DateTimeKind:
 		return reflect.TypeOf(time.Time{})
-	case ObjectKind, UserTypeKind, MediaTypeKind:
+	case UserTypeKind:
+		if ut := dtype.(*UserTypeDefinition); ut.IsPrimitive() {
+			return toReflectType(ut.Type)
+		}
+		return reflect.TypeOf(map[string]interface{}{})
+	case ObjectKind, MediaTypeKind, UnionKind:
 		return reflect.TypeOf(map[string]interface{}{})
 	case ArrayKind:
 		return reflect.SliceOf(toReflectType(dtype.ToArray().ElemType.Type))
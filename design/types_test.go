@@ -2,6 +2,7 @@ package design_test
 
 import (
 	"errors"
+	"math"
 	"mime"
 	"sync"
 
@@ -602,4 +603,68 @@ var _ = Describe("GenerateExample", func() {
 			Ω(h.GenerateExample(rand, nil)).Should(BeAssignableToTypeOf(map[string]string{"foo": "bar"}))
 		})
 	})
+
+	Context("Given a number with an exclusive minimum and a multiple of validation", func() {
+		It("generates a valid example", func() {
+			min, multiple := 0.0, 0.25
+			att := &AttributeDefinition{Type: Number, Validation: &dslengine.ValidationDefinition{
+				Minimum:          &min,
+				ExclusiveMinimum: true,
+				MultipleOf:       &multiple,
+			}}
+			ex := att.GenerateExample(NewRandomGenerator("foo"), nil)
+			Ω(ex).Should(BeNumerically(">", 0))
+			Ω(math.Mod(ex.(float64), multiple)).Should(BeZero())
+		})
+	})
+
+	Context("Given an array with a unique items validation", func() {
+		It("generates distinct elements", func() {
+			min := 3
+			att := &AttributeDefinition{Type: &Array{ElemType: &AttributeDefinition{Type: Integer}},
+				Validation: &dslengine.ValidationDefinition{MinLength: &min, UniqueItems: true}}
+			ex := att.GenerateExample(NewRandomGenerator("foo"), nil)
+			Ω(ex).Should(HaveLen(3))
+			elems := ex.([]int)
+			Ω(elems[0]).ShouldNot(Equal(elems[1]))
+			Ω(elems[1]).ShouldNot(Equal(elems[2]))
+			Ω(elems[0]).ShouldNot(Equal(elems[2]))
+		})
+	})
+
+	Context("Given a hash with a min properties validation", func() {
+		It("generates enough keys", func() {
+			min := 4
+			att := &AttributeDefinition{Type: &Hash{
+				KeyType:  &AttributeDefinition{Type: String},
+				ElemType: &AttributeDefinition{Type: String},
+			}, Validation: &dslengine.ValidationDefinition{MinProperties: &min}}
+			ex := att.GenerateExample(NewRandomGenerator("foo"), nil)
+			Ω(len(ex.(map[string]string))).Should(BeNumerically(">=", 4))
+		})
+	})
+	Context("Given an object with cross-field validations", func() {
+		It("generates an example that satisfies them", func() {
+			att := &AttributeDefinition{
+				Type: Object{
+					"email":       &AttributeDefinition{Type: String},
+					"phone":       &AttributeDefinition{Type: String},
+					"fax":         &AttributeDefinition{Type: String},
+					"country":     &AttributeDefinition{Type: String},
+					"postal_code": &AttributeDefinition{Type: String},
+				},
+				Validation: &dslengine.ValidationDefinition{
+					OneOfRequired:     [][]string{{"email", "phone"}},
+					MutuallyExclusive: [][]string{{"country", "fax"}},
+					DependentRequired: map[string][]string{"postal_code": {"country"}},
+				},
+			}
+			ex := att.GenerateExample(NewRandomGenerator("foo"), nil).(map[string]interface{})
+			Ω(ex).Should(HaveKey("email"))
+			Ω(ex).ShouldNot(HaveKey("phone"))
+			Ω(ex).Should(HaveKey("country"))
+			Ω(ex).ShouldNot(HaveKey("fax"))
+			Ω(ex).Should(HaveKey("postal_code"))
+		})
+	})
 })
@@ -3,6 +3,8 @@ package design
 import (
 	"fmt"
 	"go/build"
+	"go/token"
+	"math"
 	"mime"
 	"net/url"
 	"os"
@@ -10,6 +12,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/goadesign/goa/dslengine"
 )
@@ -81,6 +84,7 @@ func (a *APIDefinition) Validate() error {
 		verr.Merge(a.Params.Validate("base parameters", a))
 	}
 
+	a.validateVersions(verr)
 	a.validateContact(verr)
 	a.validateLicense(verr)
 	a.validateDocs(verr)
@@ -215,6 +219,7 @@ func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Name == "" {
 		verr.Add(r, "Resource name cannot be empty")
 	}
+	verr.Merge(Design.validateLifecycle(r.Lifecycle, r))
 	r.validateActions(verr)
 	if r.ParentName != "" {
 		r.validateParent(verr)
@@ -329,6 +334,7 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if len(a.Routes) == 0 {
 		verr.Add(a, "No route defined for action")
 	}
+	verr.Merge(Design.validateLifecycle(a.Lifecycle, a))
 	for i, r := range a.Responses {
 		for j, r2 := range a.Responses {
 			if i != j && r.Status == r2.Status {
@@ -346,6 +352,26 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 		if HasFile(a.Payload.Type) && a.PayloadMultipart != true {
 			verr.Add(a, "Payload %s contains an invalid type, action payloads cannot contain a file", a.Payload.TypeName)
 		}
+		if Design.IsVersioned() {
+			verr.Merge(a.validateVersionedPayload())
+		}
+	}
+	if a.Patch != nil {
+		if !a.Patch.Target.IsObject() {
+			verr.Add(a, "patch target must be an object type or media type")
+		}
+		if a.PayloadMultipart {
+			verr.Add(a, "patch actions cannot use multipart forms")
+		}
+	}
+	if p := a.Pagination; p != nil {
+		if p.MaxPageSize > 0 && p.DefaultPageSize > p.MaxPageSize {
+			verr.Add(a, "default page size %d is greater than the maximum page size %d",
+				p.DefaultPageSize, p.MaxPageSize)
+		}
+		if a.PageMediaType() == nil {
+			verr.Add(a, "paginated actions must define an OK response with a collection media type")
+		}
 	}
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
@@ -369,6 +395,13 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 			verr.Add(a, "Param %s has an invalid type, action params must be primitives or arrays of primitives", n)
 		}
 	}
+	if a.Cookies != nil {
+		for n, c := range a.Cookies.Type.ToObject() {
+			if !c.Type.IsPrimitive() || HasFile(c.Type) {
+				verr.Add(a, "Cookie %s has an invalid type, action cookies must be primitives", n)
+			}
+		}
+	}
 
 	return verr.AsError()
 }
@@ -437,6 +470,9 @@ func (a *ActionDefinition) ValidateParams() *dslengine.ValidationErrors {
 		} else if p.Type.Kind() == HashKind {
 			verr.Add(a, `parameter %s cannot be a hash, only action payloads may be of type hash`, n)
 		}
+		if p.Nullable {
+			verr.Add(a, "parameter %s cannot be nullable, only payload and media type attributes may be", n)
+		}
 		ctx := fmt.Sprintf("parameter %s", n)
 		verr.Merge(p.Validate(ctx, a))
 	}
@@ -481,6 +517,21 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			verr.Add(parent, "%sdefault value %#v is not one of the accepted values: %#v", ctx, a.DefaultValue, a.Validation.Values)
 		}
 	}
+	verr.Merge(Design.validateLifecycle(a.Lifecycle, parent))
+	if a.NumericFormat() != "" {
+		verr.Merge(a.validateNumericFormat(ctx, parent))
+	}
+	if a.Nullable {
+		verr.Merge(a.validateNullable(ctx, parent))
+	}
+	if v := a.Validation; v != nil {
+		if v.MultipleOf != nil && a.Type.Kind() == IntegerKind && *v.MultipleOf != math.Trunc(*v.MultipleOf) {
+			verr.Add(parent, "%smultiple of value %v must be an integer", ctx, *v.MultipleOf)
+		}
+		if v.MinProperties != nil && v.MaxProperties != nil && *v.MinProperties > *v.MaxProperties {
+			verr.Add(parent, "%sminimum properties %d is greater than maximum properties %d", ctx, *v.MinProperties, *v.MaxProperties)
+		}
+	}
 	o := a.Type.ToObject()
 	if o != nil {
 		for _, n := range a.AllRequired() {
@@ -495,17 +546,171 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 				verr.Add(parent, `%srequired field "%s" does not exist`, ctx, n)
 			}
 		}
+		if a.Validation != nil {
+			verr.Merge(a.validateCrossFieldRules(ctx, parent))
+		}
 		for n, att := range o {
 			ctx = fmt.Sprintf("field %s", n)
+			if hasInlineNullable(att) {
+				verr.Add(parent, "%s - nullable attributes must be defined in a user type or media type, not in an inline object", ctx)
+			}
 			verr.Merge(att.Validate(ctx, parent))
 		}
+	} else if u, ok := a.Type.(*Union); ok {
+		if len(u.Variants) == 0 {
+			verr.Add(parent, "%sunion does not define any variant", ctx)
+		}
+		for _, n := range u.VariantNames() {
+			att := u.Variants[n]
+			if !att.Type.IsObject() {
+				verr.Add(parent, "%sunion variant %#v must be an object", ctx, n)
+				continue
+			}
+			if _, ok := att.Type.ToObject()[u.Discriminator]; ok {
+				verr.Add(parent, "%sunion variant %#v defines the discriminator attribute %#v", ctx, n, u.Discriminator)
+			}
+			verr.Merge(att.Validate(fmt.Sprintf("variant %s", n), parent))
+		}
 	} else {
 		if a.Type.IsArray() {
 			elemType := a.Type.ToArray().ElemType
+			if elemType.Nullable {
+				verr.Add(parent, "%sarray elements cannot be nullable", ctx)
+			}
 			verr.Merge(elemType.Validate(ctx, a))
+		} else if h := a.Type.ToHash(); h != nil {
+			if h.KeyType.Nullable || h.ElemType.Nullable {
+				verr.Add(parent, "%shash keys and elements cannot be nullable", ctx)
+			}
+		}
+	}
+
+	return verr.AsError()
+}
+
+// hasInlineNullable returns true if the given attribute is an inline object or an array or hash of
+// inline objects with nullable attributes. The generated code omits the nullable attributes that
+// are not set with a method of the struct type which anonymous structs cannot define.
+func hasInlineNullable(att *AttributeDefinition) bool {
+	switch actual := att.Type.(type) {
+	case Object:
+		for _, a := range actual {
+			if a.Nullable {
+				return true
+			}
+		}
+	case *Array:
+		return hasInlineNullable(actual.ElemType)
+	case *Hash:
+		return hasInlineNullable(actual.ElemType)
+	}
+	return false
+}
+
+// validateNullable makes sure the nullable attribute is a primitive other than a file or an array
+// or hash of such primitives and that it does not define a default value: a nullable attribute is
+// either absent, null or set.
+func (a *AttributeDefinition) validateNullable(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if !CanBeNullable(a.Type) {
+		verr.Add(parent, "%snullable attribute must be a primitive or an array or hash of primitives", ctx)
+	}
+	if a.DefaultValue != nil {
+		verr.Add(parent, "%snullable attribute cannot have a default value", ctx)
+	}
+	return verr
+}
+
+// validateCrossFieldRules makes sure the attributes referenced by the cross-field validations and
+// the validation functions of the object attribute exist. Attributes that must be set by
+// OneOfRequired or that are mutually exclusive may not be required or have a default value as
+// they would always be set.
+func (a *AttributeDefinition) validateCrossFieldRules(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	o := a.Type.ToObject()
+	check := func(rule, n string, optional bool) {
+		att, ok := o[n]
+		if !ok {
+			verr.Add(parent, `%s%s field "%s" does not exist`, ctx, rule, n)
+			return
+		}
+		if optional && (a.IsRequired(n) || att.DefaultValue != nil) {
+			verr.Add(parent, `%s%s field "%s" cannot be required or have a default value`, ctx, rule, n)
+		}
+	}
+	v := a.Validation
+	for _, names := range v.OneOfRequired {
+		for _, n := range names {
+			check("one of required", n, true)
+		}
+	}
+	for _, names := range v.MutuallyExclusive {
+		for _, n := range names {
+			check("mutually exclusive", n, true)
+		}
+	}
+	for n, deps := range v.DependentRequired {
+		check("dependent required", n, false)
+		for _, d := range deps {
+			check("dependent required", d, false)
+		}
+	}
+	for _, f := range v.Funcs {
+		if f.PackagePath == "" || !token.IsIdentifier(f.Name) || !token.IsExported(f.Name) {
+			verr.Add(parent, `%sinvalid validation function "%s" in package "%s"`, ctx, f.Name, f.PackagePath)
+		}
+		for _, n := range f.Attributes {
+			check("validation function", n, false)
 		}
 	}
+	return verr.AsError()
+}
 
+// validateNumericFormat checks that the bounds, default value and enum values of an attribute
+// that uses a numeric format fit the range of the format.
+func (a *AttributeDefinition) validateNumericFormat(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	f := a.NumericFormat()
+	if f == "decimal" && len(a.Validation.Values) > 0 {
+		verr.Add(parent, "%senum validations cannot be used with the decimal format", ctx)
+	}
+	var min, max float64
+	switch f {
+	case "int32":
+		min, max = math.MinInt32, math.MaxInt32
+	case "uint", "uint64":
+		min, max = 0, math.Inf(1)
+	case "uint32":
+		min, max = 0, math.MaxUint32
+	default:
+		return verr.AsError()
+	}
+	check := func(what string, val interface{}) {
+		var v float64
+		switch actual := val.(type) {
+		case int:
+			v = float64(actual)
+		case float64:
+			v = actual
+		default:
+			return
+		}
+		if v < min || v > max {
+			verr.Add(parent, "%s%s %v does not fit in the %s format", ctx, what, val, f)
+		}
+	}
+	if a.Validation.Minimum != nil {
+		check("minimum", *a.Validation.Minimum)
+	}
+	if a.Validation.Maximum != nil {
+		check("maximum", *a.Validation.Maximum)
+	}
+	if a.DefaultValue != nil {
+		check("default value", a.DefaultValue)
+	}
+	for _, v := range a.Validation.Values {
+		check("enum value", v)
+	}
 	return verr.AsError()
 }
 
@@ -539,9 +744,53 @@ func (u *UserTypeDefinition) Validate(ctx string, parent dslengine.Definition) *
 		verr.Add(parent, "%s - %s", ctx, "User type must have a name")
 	}
 	verr.Merge(u.AttributeDefinition.Validate(ctx, u))
+	if u.IsPrimitive() && u.Validation != nil && len(u.Validation.Values) > 0 && !u.IsEnum() {
+		verr.Add(parent, "type %#v: enum types must be strings or integers", u.TypeName)
+	}
+	if names, ok := u.Metadata["enum:names"]; ok {
+		if !u.IsEnum() {
+			verr.Add(parent, "type %#v: enum:names metadata can only be used with enum types", u.TypeName)
+		} else if len(names) != len(u.Validation.Values) {
+			verr.Add(parent, "type %#v: enum:names metadata lists %d names for %d values", u.TypeName, len(names), len(u.Validation.Values))
+		} else if n, o, ok := enumNamesCollide(names); ok {
+			verr.Add(parent, "type %#v: enum:names metadata names %#v and %#v produce the same constant name", u.TypeName, o, n)
+		}
+	} else if u.IsEnum() {
+		values := make([]string, len(u.Validation.Values))
+		for i, v := range u.Validation.Values {
+			values[i] = fmt.Sprint(v)
+		}
+		if n, o, ok := enumNamesCollide(values); ok {
+			verr.Add(parent, "type %#v: enum values %#v and %#v produce the same constant name, use the enum:names metadata to name the constants", u.TypeName, o, n)
+		}
+	}
 	return verr.AsError()
 }
 
+// enumNamesCollide returns the first name of names that produces the same generated constant name
+// as a previous name and that previous name. The generators make identifiers out of the names by
+// removing the characters that are not letters or digits, changing the case of letters and
+// prefixing the names that do not start with a letter with "Value".
+func enumNamesCollide(names []string) (string, string, bool) {
+	seen := make(map[string]string, len(names))
+	for _, n := range names {
+		key := strings.ToLower(strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, n))
+		if key == "" || !unicode.IsLetter([]rune(key)[0]) {
+			key = "value" + key
+		}
+		if o, ok := seen[key]; ok {
+			return n, o, true
+		}
+		seen[key] = n
+	}
+	return "", "", false
+}
+
 // Validate checks that the media type definition is consistent: its identifier is a valid media
 // type identifier.
 func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
@@ -596,9 +845,60 @@ func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
 	for _, l := range m.Links {
 		verr.Merge(l.Validate())
 	}
+	verr.Merge(m.validateShaping())
 	return verr.AsError()
 }
 
+// validateShaping checks that the filters and sort fields of the media type refer to primitive
+// attributes and that the filter operators apply to the attribute types.
+func (m *MediaTypeDefinition) validateShaping() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if m.IsArray() {
+		if len(m.Filters) > 0 || len(m.SortFields) > 0 || m.SparseFieldsets {
+			verr.Add(m, "filters, sort fields and sparse fieldsets must be defined on the element media type of collections")
+		}
+		return verr
+	}
+	obj := m.ToObject()
+	shapeable := func(n string) (*AttributeDefinition, bool) {
+		att, ok := obj[n]
+		if !ok {
+			verr.Add(m, "unknown attribute %#v", n)
+			return nil, false
+		}
+		if !att.Type.IsPrimitive() || att.Type.Kind() == FileKind || att.Type.Kind() == AnyKind {
+			verr.Add(m, "attribute %#v must be of a primitive type other than Any or File to be filtered or sorted", n)
+			return nil, false
+		}
+		return att, true
+	}
+	for _, f := range m.Filters {
+		att, ok := shapeable(f.Attribute)
+		if !ok {
+			continue
+		}
+		for _, op := range f.Operators {
+			switch op {
+			case Eq, Ne:
+			case Lt, Lte, Gt, Gte:
+				if k := att.Type.Kind(); k == BooleanKind || k == UUIDKind {
+					verr.Add(m, "filter operator %#v does not apply to attribute %#v of type %s", string(op), f.Attribute, att.Type.Name())
+				}
+			case Prefix:
+				if att.Type.Kind() != StringKind {
+					verr.Add(m, "filter operator %#v does not apply to attribute %#v of type %s", string(op), f.Attribute, att.Type.Name())
+				}
+			default:
+				verr.Add(m, "invalid filter operator %#v", string(op))
+			}
+		}
+	}
+	for _, n := range m.SortFields {
+		shapeable(n)
+	}
+	return verr
+}
+
 // Validate checks that the link definition is consistent: it has a media type or the name of an
 // attribute part of the parent media type.
 func (l *LinkDefinition) Validate() *dslengine.ValidationErrors {
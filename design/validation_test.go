@@ -157,6 +157,187 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("with a numeric format validation", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						Format("uint32")
+						Maximum(100)
+					})
+				}
+			})
+
+			It("records the validation", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(att.NumericFormat()).Should(Equal("uint32"))
+			})
+		})
+
+		Context("with a minimum that does not fit the numeric format", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						Format("uint")
+						Minimum(-1)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("minimum -1 does not fit in the uint format"))
+			})
+		})
+
+		Context("with an enum validation on a decimal", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Number, func() {
+						Format("decimal")
+						Enum(1.5, 2.5)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a non integer multiple of on an integer", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						MultipleOf(0.5)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("multiple of value 0.5 must be an integer"))
+			})
+		})
+
+		Context("with a min properties greater than the max properties", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, HashOf(String, String), func() {
+						MinProperties(3)
+						MaxProperties(2)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("minimum properties 3 is greater than maximum properties 2"))
+			})
+		})
+
+		Context("with a cross-field validation referencing an unknown attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						Attribute("email")
+						OneOfRequired("email", "phone")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`one of required field "phone" does not exist`))
+			})
+		})
+
+		Context("with a mutually exclusive validation on a required attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						Attribute("phone")
+						Attribute("fax")
+						Required("phone")
+						MutuallyExclusive("phone", "fax")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`mutually exclusive field "phone" cannot be required`))
+			})
+		})
+
+		Context("with an invalid validation function name", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						Attribute("start", DateTime)
+						ValidateFunc("example.com/validators", "validatePeriod", "start")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid validation function "validatePeriod"`))
+			})
+		})
+
+		Context("with a nullable object attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						Attribute("address", func() {
+							Attribute("street")
+							Nullable()
+						})
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("nullable attribute must be a primitive or an array or hash of primitives"))
+			})
+		})
+
+		Context("with a nullable attribute with a default value", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						Attribute("nickname", String, func() {
+							Nullable()
+							Default("joe")
+						})
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("nullable attribute cannot have a default value"))
+			})
+		})
+
+		Context("with a nullable attribute in an inline object", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						Attribute("nickname", String, func() {
+							Nullable()
+						})
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("nullable attributes must be defined in a user type or media type, not in an inline object"))
+			})
+		})
+
 		Context("with a valid min value validation", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -293,6 +474,47 @@ var _ = Describe("Validation", func() {
 		})
 	})
 
+	Context("with a union type", func() {
+		var dsl func()
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("bar", func() {
+				OneOf(dsl, Discriminator("kind"))
+			})
+			dslengine.Run()
+		})
+
+		Context("with object variants", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute("card", func() {
+						Attribute("number", String)
+					})
+				}
+			})
+
+			It("is valid", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with a variant that defines the discriminator attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute("card", func() {
+						Attribute("kind", String)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`defines the discriminator attribute "kind"`))
+			})
+		})
+	})
+
 	Context("actions with different http methods", func() {
 		It("should be valid because methods are different", func() {
 			dslengine.Reset()
@@ -392,6 +614,68 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("which patches a non object type", func() {
+			BeforeEach(func() {
+				tags := &UserTypeDefinition{
+					AttributeDefinition: &AttributeDefinition{Type: ArrayOf(String)},
+					TypeName:            "Tags",
+				}
+				dsl = func() {
+					MergePatch(tags)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": patch target must be an object type or media type`,
+				))
+			})
+		})
+
+		Context("which is paginated without collection response", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Paginated(Cursor)
+					Response(NoContent)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": paginated actions must define an OK response with a collection media type`,
+				))
+			})
+		})
+
+		Context("which is paginated with a default page size greater than the maximum", func() {
+			BeforeEach(func() {
+				dslengine.Reset()
+				var bottle = MediaType("application/vnd.goa.bottle", func() {
+					Attributes(func() {
+						Attribute("name", String)
+					})
+					View("default", func() {
+						Attribute("name")
+					})
+				})
+				var bottles = CollectionOf(bottle)
+				dslengine.Run()
+				dsl = func() {
+					Paginated(Offset, func() {
+						MaxPageSize(10)
+						DefaultPageSize(50)
+					})
+					Response(OK, bottles)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": default page size 50 is greater than the maximum page size 10`,
+				))
+			})
+		})
+
 		Context("which has a response contains a file", func() {
 			BeforeEach(func() {
 				dslengine.Reset()
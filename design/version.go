@@ -0,0 +1,449 @@
+package design
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+// sunsetFormats lists the accepted formats of deprecation sunset dates.
+var sunsetFormats = []string{"2006-01-02", time.RFC3339}
+
+// Context returns the generic definition name used in error messages.
+func (v *VersionDefinition) Context() string {
+	if v.Name != "" {
+		return fmt.Sprintf("version %#v", v.Name)
+	}
+	return "unnamed version"
+}
+
+// Context returns the generic definition name used in error messages.
+func (d *DeprecationDefinition) Context() string {
+	if d.Parent != nil {
+		return fmt.Sprintf("deprecation of %s", d.Parent.Context())
+	}
+	return "unnamed deprecation"
+}
+
+// SunsetTime returns the sunset date of the deprecation, false if there is none or if it cannot be
+// parsed.
+func (d *DeprecationDefinition) SunsetTime() (time.Time, bool) {
+	if d.Sunset == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range sunsetFormats {
+		if t, err := time.Parse(layout, d.Sunset); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// SunsetHeader returns the value of the RFC 8594 Sunset response header of the deprecated
+// definition, empty if the deprecation has no sunset date.
+func (d *DeprecationDefinition) SunsetHeader() string {
+	t, ok := d.SunsetTime()
+	if !ok {
+		return ""
+	}
+	return t.UTC().Format(http.TimeFormat)
+}
+
+// IsVersioned returns true if the design declares several coexisting versions of the API.
+func (a *APIDefinition) IsVersioned() bool {
+	return len(a.Versions) > 1
+}
+
+// VersionDefinition returns the version with the given name, nil if the design does not declare
+// it.
+func (a *APIDefinition) VersionDefinition(name string) *VersionDefinition {
+	for _, v := range a.Versions {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Includes returns true if the given version of the API includes the definition whose lifecycle
+// is l. Definitions with no lifecycle are included in all versions.
+func (a *APIDefinition) Includes(l *LifecycleDefinition, version string) bool {
+	if l == nil {
+		return true
+	}
+	i := a.versionIndex(version)
+	if l.AddedIn != "" && i < a.versionIndex(l.AddedIn) {
+		return false
+	}
+	return l.RemovedIn == "" || i < a.versionIndex(l.RemovedIn)
+}
+
+// Deprecation returns the deprecation of the action in the version of the API described by
+// Design: the deprecation of the action itself, of its resource or of the version, in this order.
+// It returns nil if the action is not deprecated.
+func (a *ActionDefinition) Deprecation() *DeprecationDefinition {
+	if a.Lifecycle != nil && Design.deprecates(a.Lifecycle.Deprecation) {
+		return a.Lifecycle.Deprecation
+	}
+	if r := a.Parent; r != nil && r.Lifecycle != nil && Design.deprecates(r.Lifecycle.Deprecation) {
+		return r.Lifecycle.Deprecation
+	}
+	if v := Design.VersionDefinition(Design.Version); v != nil {
+		return v.Deprecation
+	}
+	return nil
+}
+
+// IsDeprecated returns true if the attribute is deprecated in the version of the API described by
+// Design.
+func (a *AttributeDefinition) IsDeprecated() bool {
+	return a.Lifecycle != nil && Design.deprecates(a.Lifecycle.Deprecation)
+}
+
+// ForVersion returns a copy of the API definition that only includes the resources, actions,
+// attributes and views of the given version. The copy shares the definitions that do not depend
+// on the version with a. With PathVersioning the base path of the copy is prefixed with the name
+// of the version.
+func (a *APIDefinition) ForVersion(version string) *APIDefinition {
+	p := &versionProjector{
+		api:        a,
+		version:    version,
+		types:      make(map[string]*UserTypeDefinition),
+		mediaTypes: make(map[string]*MediaTypeDefinition),
+	}
+	res := *a
+	res.Version = version
+	if a.VersionScheme == PathVersioning {
+		res.BasePath = strings.TrimSuffix(a.BasePath, "/") + "/" + version
+	}
+	res.Params = p.attribute(a.Params)
+	res.Responses = p.responses(a.Responses)
+	if a.Types != nil {
+		res.Types = make(map[string]*UserTypeDefinition, len(a.Types))
+		for n, ut := range a.Types {
+			res.Types[n] = p.userType(ut)
+		}
+	}
+	if a.MediaTypes != nil {
+		res.MediaTypes = make(map[string]*MediaTypeDefinition, len(a.MediaTypes))
+		for id, mt := range a.MediaTypes {
+			res.MediaTypes[id] = p.mediaType(mt)
+		}
+	}
+	if a.Resources != nil {
+		res.Resources = make(map[string]*ResourceDefinition, len(a.Resources))
+		for n, r := range a.Resources {
+			if a.Includes(r.Lifecycle, version) {
+				res.Resources[n] = p.resource(r)
+			}
+		}
+	}
+	for _, mt := range p.mediaTypes {
+		if mt.Resource != nil {
+			mt.Resource = res.Resources[mt.Resource.Name]
+		}
+	}
+	return &res
+}
+
+// WithVersion calls fn with the projection of the API for the given version, see ForVersion.
+// Definitions compute their paths and look up media types from Design so WithVersion sets Design
+// to the projection for the duration of the call.
+func (a *APIDefinition) WithVersion(version string, fn func(*APIDefinition) error) error {
+	api, projected := Design, ProjectedMediaTypes
+	Design, ProjectedMediaTypes = a.ForVersion(version), make(MediaTypeRoot)
+	defer func() { Design, ProjectedMediaTypes = api, projected }()
+	return fn(Design)
+}
+
+// versionIndex returns the position of the version with the given name in the list of versions,
+// -1 if the design does not declare it.
+func (a *APIDefinition) versionIndex(name string) int {
+	for i, v := range a.Versions {
+		if v.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// deprecates returns true if d deprecates its definition in the version of the API described by
+// a.
+func (a *APIDefinition) deprecates(d *DeprecationDefinition) bool {
+	if d == nil {
+		return false
+	}
+	return d.Since == "" || a.versionIndex(a.Version) >= a.versionIndex(d.Since)
+}
+
+// validateVersions checks that the versions are uniquely named.
+func (a *APIDefinition) validateVersions(verr *dslengine.ValidationErrors) {
+	seen := make(map[string]bool, len(a.Versions))
+	for _, v := range a.Versions {
+		if v.Name == "" {
+			verr.Add(a, "version name cannot be empty")
+			continue
+		}
+		if seen[v.Name] {
+			verr.Add(a, "multiple definitions for version %#v", v.Name)
+		}
+		seen[v.Name] = true
+		verr.Merge(a.validateDeprecation(v.Deprecation))
+	}
+	switch a.VersionScheme {
+	case "", PathVersioning, HeaderVersioning, MediaTypeVersioning:
+	default:
+		verr.Add(a, "invalid versioning scheme %#v", a.VersionScheme)
+	}
+}
+
+// validateLifecycle checks that the versions that add, remove or deprecate the definition parent
+// are declared and ordered.
+func (a *APIDefinition) validateLifecycle(l *LifecycleDefinition, parent dslengine.Definition) *dslengine.ValidationErrors {
+	if l == nil {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	for _, name := range []string{l.AddedIn, l.RemovedIn} {
+		if name != "" && a.versionIndex(name) < 0 {
+			verr.Add(parent, "unknown version %#v", name)
+		}
+	}
+	if l.AddedIn != "" && l.RemovedIn != "" && a.versionIndex(l.AddedIn) >= a.versionIndex(l.RemovedIn) {
+		verr.Add(parent, "version %#v that removes the definition does not follow version %#v that adds it", l.RemovedIn, l.AddedIn)
+	}
+	verr.Merge(a.validateDeprecation(l.Deprecation))
+	return verr.AsError()
+}
+
+// validateVersionedPayload checks that the required attributes of the payload that are added or
+// removed in a version of the API are not defined in a nested user type or media type. The
+// generated code validates the top level attributes of the payload against each version but
+// validates the nested types the same way in all versions.
+func (a *ActionDefinition) validateVersionedPayload() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	seen := make(map[string]bool)
+	var check func(string, *AttributeDefinition)
+	check = func(typeName string, att *AttributeDefinition) {
+		switch actual := att.Type.(type) {
+		case Object:
+			for _, n := range att.AllRequired() {
+				if c, ok := actual[n]; ok && c.Lifecycle != nil && (c.Lifecycle.AddedIn != "" || c.Lifecycle.RemovedIn != "") {
+					verr.Add(a, "required attribute %#v of type %s is added or removed in a version, only the top level attributes of the payload may be", n, typeName)
+				}
+			}
+			for _, c := range actual {
+				check(typeName, c)
+			}
+		case *Array:
+			check(typeName, actual.ElemType)
+		case *Hash:
+			check(typeName, actual.KeyType)
+			check(typeName, actual.ElemType)
+		case *Union:
+			for _, v := range actual.Variants {
+				check(typeName, v)
+			}
+		}
+	}
+	a.Payload.Walk(func(att *AttributeDefinition) error {
+		ut, ok := att.Type.(*UserTypeDefinition)
+		if mt, isMT := att.Type.(*MediaTypeDefinition); isMT {
+			ut, ok = mt.UserTypeDefinition, true
+		}
+		if ok && !seen[ut.TypeName] {
+			seen[ut.TypeName] = true
+			check(ut.TypeName, ut.AttributeDefinition)
+		}
+		return nil
+	})
+	return verr
+}
+
+// validateDeprecation checks that the deprecation version is declared and that the sunset date is
+// valid.
+func (a *APIDefinition) validateDeprecation(d *DeprecationDefinition) *dslengine.ValidationErrors {
+	if d == nil {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	if d.Since != "" && a.versionIndex(d.Since) < 0 {
+		verr.Add(d, "unknown version %#v", d.Since)
+	}
+	if _, ok := d.SunsetTime(); d.Sunset != "" && !ok {
+		verr.Add(d, "invalid sunset date %#v, must be formatted as 2006-01-02 or RFC 3339", d.Sunset)
+	}
+	return verr.AsError()
+}
+
+// versionProjector copies definitions, leaving out the attributes and views that a given version
+// of the API does not include. It copies user types and media types once so that the copies may
+// be recursive.
+type versionProjector struct {
+	api        *APIDefinition
+	version    string
+	types      map[string]*UserTypeDefinition
+	mediaTypes map[string]*MediaTypeDefinition
+}
+
+// resource copies the resource and the actions the version includes.
+func (p *versionProjector) resource(r *ResourceDefinition) *ResourceDefinition {
+	res := *r
+	res.Params = p.attribute(r.Params)
+	res.Headers = p.attribute(r.Headers)
+	res.Responses = p.responses(r.Responses)
+	res.Actions = make(map[string]*ActionDefinition, len(r.Actions))
+	for n, a := range r.Actions {
+		if p.api.Includes(a.Lifecycle, p.version) {
+			res.Actions[n] = p.action(a, &res)
+		}
+	}
+	return &res
+}
+
+// action copies the action.
+func (p *versionProjector) action(a *ActionDefinition, parent *ResourceDefinition) *ActionDefinition {
+	res := *a
+	res.Parent = parent
+	res.Params = p.attribute(a.Params)
+	res.QueryParams = p.attribute(a.QueryParams)
+	res.Headers = p.attribute(a.Headers)
+	res.Cookies = p.attribute(a.Cookies)
+	res.Payload = p.userType(a.Payload)
+	res.Responses = p.responses(a.Responses)
+	res.Routes = make([]*RouteDefinition, len(a.Routes))
+	for i, r := range a.Routes {
+		route := *r
+		route.Parent = &res
+		res.Routes[i] = &route
+	}
+	if a.Patch != nil {
+		patch := *a.Patch
+		patch.Target = p.dataType(a.Patch.Target)
+		res.Patch = &patch
+	}
+	if a.Pagination != nil {
+		pagination := *a.Pagination
+		pagination.Parent = &res
+		res.Pagination = &pagination
+	}
+	return &res
+}
+
+// responses copies the given response definitions.
+func (p *versionProjector) responses(resps map[string]*ResponseDefinition) map[string]*ResponseDefinition {
+	if resps == nil {
+		return nil
+	}
+	res := make(map[string]*ResponseDefinition, len(resps))
+	for n, r := range resps {
+		resp := *r
+		resp.Type = p.dataType(r.Type)
+		resp.Headers = p.attribute(r.Headers)
+		res[n] = &resp
+	}
+	return res
+}
+
+// attribute copies the attribute, the required validation only lists the child attributes the
+// version includes.
+func (p *versionProjector) attribute(att *AttributeDefinition) *AttributeDefinition {
+	if att == nil {
+		return nil
+	}
+	res := *att
+	res.Type = p.dataType(att.Type)
+	if o, ok := res.Type.(Object); ok && att.Validation != nil && len(att.Validation.Required) > 0 {
+		res.Validation = att.Validation.Dup()
+		res.Validation.Required = nil
+		for _, n := range att.Validation.Required {
+			if _, ok := o[n]; ok {
+				res.Validation.Required = append(res.Validation.Required, n)
+			}
+		}
+	}
+	return &res
+}
+
+// dataType copies the data type.
+func (p *versionProjector) dataType(dt DataType) DataType {
+	switch actual := dt.(type) {
+	case Object:
+		res := make(Object, len(actual))
+		for n, att := range actual {
+			if p.api.Includes(att.Lifecycle, p.version) {
+				res[n] = p.attribute(att)
+			}
+		}
+		return res
+	case *Array:
+		return &Array{ElemType: p.attribute(actual.ElemType)}
+	case *Hash:
+		return &Hash{KeyType: p.attribute(actual.KeyType), ElemType: p.attribute(actual.ElemType)}
+	case *Union:
+		return &Union{Variants: p.dataType(actual.Variants).(Object), Discriminator: actual.Discriminator}
+	case *MediaTypeDefinition:
+		return p.mediaType(actual)
+	case *UserTypeDefinition:
+		return p.userType(actual)
+	}
+	return dt
+}
+
+// userType copies the user type.
+func (p *versionProjector) userType(ut *UserTypeDefinition) *UserTypeDefinition {
+	if ut == nil {
+		return nil
+	}
+	if res, ok := p.types[ut.TypeName]; ok {
+		return res
+	}
+	res := &UserTypeDefinition{TypeName: ut.TypeName}
+	p.types[ut.TypeName] = res
+	res.AttributeDefinition = p.attribute(ut.AttributeDefinition)
+	return res
+}
+
+// mediaType copies the media type together with the views and links the version includes.
+func (p *versionProjector) mediaType(mt *MediaTypeDefinition) *MediaTypeDefinition {
+	if res, ok := p.mediaTypes[mt.Identifier]; ok {
+		return res
+	}
+	res := *mt
+	p.mediaTypes[mt.Identifier] = &res
+	res.UserTypeDefinition = &UserTypeDefinition{TypeName: mt.TypeName}
+	res.AttributeDefinition = p.attribute(mt.AttributeDefinition)
+	obj := res.Type.ToObject()
+	if mt.Links != nil {
+		res.Links = make(map[string]*LinkDefinition, len(mt.Links))
+		for n, l := range mt.Links {
+			if obj != nil && obj[n] == nil {
+				continue
+			}
+			link := *l
+			link.Parent = &res
+			res.Links[n] = &link
+		}
+	}
+	if mt.Views != nil {
+		res.Views = make(map[string]*ViewDefinition, len(mt.Views))
+		for n, v := range mt.Views {
+			if !p.api.Includes(v.Lifecycle, p.version) {
+				continue
+			}
+			att := p.attribute(v.AttributeDefinition)
+			if vobj, ok := att.Type.(Object); ok && obj != nil {
+				for an := range vobj {
+					if obj[an] == nil && an != "links" {
+						delete(vobj, an)
+					}
+				}
+			}
+			res.Views[n] = &ViewDefinition{AttributeDefinition: att, Name: v.Name, Parent: &res}
+		}
+	}
+	return &res
+}
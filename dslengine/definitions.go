@@ -1,6 +1,9 @@
 package dslengine
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type (
 
@@ -94,15 +97,57 @@ type (
 		// Maximum represents a maximum value validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor17.
 		Maximum *float64
+		// ExclusiveMinimum makes the Minimum validation exclusive as described at
+		// http://json-schema.org/latest/json-schema-validation.html#anchor21.
+		ExclusiveMinimum bool
+		// ExclusiveMaximum makes the Maximum validation exclusive as described at
+		// http://json-schema.org/latest/json-schema-validation.html#anchor17.
+		ExclusiveMaximum bool
+		// MultipleOf represents a multiple of validation as described at
+		// http://json-schema.org/latest/json-schema-validation.html#anchor14.
+		MultipleOf *float64
 		// MinLength represents an minimum length validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor29.
 		MinLength *int
 		// MaxLength represents an maximum length validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor26.
 		MaxLength *int
+		// UniqueItems represents an unique items validation as described at
+		// http://json-schema.org/latest/json-schema-validation.html#anchor49.
+		UniqueItems bool
+		// MinProperties represents a minimum number of properties validation as described at
+		// http://json-schema.org/latest/json-schema-validation.html#anchor57.
+		MinProperties *int
+		// MaxProperties represents a maximum number of properties validation as described at
+		// http://json-schema.org/latest/json-schema-validation.html#anchor54.
+		MaxProperties *int
 		// Required list the required fields of object attributes as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor61.
 		Required []string
+		// OneOfRequired lists groups of fields of object attributes of which exactly one
+		// must be set.
+		OneOfRequired [][]string
+		// MutuallyExclusive lists groups of fields of object attributes of which at most
+		// one may be set.
+		MutuallyExclusive [][]string
+		// DependentRequired lists the fields of object attributes that must be set when
+		// the field used as key is set.
+		DependentRequired map[string][]string
+		// Funcs lists the user functions called by the generated Validate methods.
+		Funcs []*ValidationFuncDefinition
+	}
+
+	// ValidationFuncDefinition describes a user provided Go function called by the
+	// generated validation code. The function is given the values of the object fields
+	// listed in Attributes and must return an error if the validation fails.
+	ValidationFuncDefinition struct {
+		// PackagePath is the import path of the package that defines the function.
+		PackagePath string
+		// Name is the name of the function.
+		Name string
+		// Attributes lists the names of the fields whose values are given to the
+		// function.
+		Attributes []string
 	}
 )
 
@@ -137,9 +182,14 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 	}
 	if v.Minimum == nil || (other.Minimum != nil && *v.Minimum > *other.Minimum) {
 		v.Minimum = other.Minimum
+		v.ExclusiveMinimum = other.ExclusiveMinimum
 	}
 	if v.Maximum == nil || (other.Maximum != nil && *v.Maximum < *other.Maximum) {
 		v.Maximum = other.Maximum
+		v.ExclusiveMaximum = other.ExclusiveMaximum
+	}
+	if v.MultipleOf == nil {
+		v.MultipleOf = other.MultipleOf
 	}
 	if v.MinLength == nil || (other.MinLength != nil && *v.MinLength > *other.MinLength) {
 		v.MinLength = other.MinLength
@@ -147,23 +197,71 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 	if v.MaxLength == nil || (other.MaxLength != nil && *v.MaxLength < *other.MaxLength) {
 		v.MaxLength = other.MaxLength
 	}
+	if !v.UniqueItems {
+		v.UniqueItems = other.UniqueItems
+	}
+	if v.MinProperties == nil || (other.MinProperties != nil && *v.MinProperties > *other.MinProperties) {
+		v.MinProperties = other.MinProperties
+	}
+	if v.MaxProperties == nil || (other.MaxProperties != nil && *v.MaxProperties < *other.MaxProperties) {
+		v.MaxProperties = other.MaxProperties
+	}
 	v.AddRequired(other.Required)
+	for _, names := range other.OneOfRequired {
+		v.AddOneOfRequired(names)
+	}
+	for _, names := range other.MutuallyExclusive {
+		v.AddMutuallyExclusive(names)
+	}
+	for n, deps := range other.DependentRequired {
+		v.AddDependentRequired(n, deps)
+	}
+	for _, f := range other.Funcs {
+		v.AddFunc(f)
+	}
 }
 
 // AddRequired merges the required fields from other into v
 func (v *ValidationDefinition) AddRequired(required []string) {
-	for _, r := range required {
-		found := false
-		for _, rr := range v.Required {
-			if r == rr {
-				found = true
-				break
-			}
-		}
-		if !found {
-			v.Required = append(v.Required, r)
+	v.Required = appendMissing(v.Required, required)
+}
+
+// AddOneOfRequired adds a group of fields of which exactly one must be set unless v already
+// defines it.
+func (v *ValidationDefinition) AddOneOfRequired(names []string) {
+	v.OneOfRequired = appendGroup(v.OneOfRequired, names)
+}
+
+// AddMutuallyExclusive adds a group of fields of which at most one may be set unless v already
+// defines it.
+func (v *ValidationDefinition) AddMutuallyExclusive(names []string) {
+	v.MutuallyExclusive = appendGroup(v.MutuallyExclusive, names)
+}
+
+// AddFunc adds a validation function unless v already calls it with the same fields.
+func (v *ValidationDefinition) AddFunc(f *ValidationFuncDefinition) {
+	for _, vf := range v.Funcs {
+		if vf.PackagePath == f.PackagePath && vf.Name == f.Name &&
+			strings.Join(vf.Attributes, ",") == strings.Join(f.Attributes, ",") {
+			return
 		}
 	}
+	v.Funcs = append(v.Funcs, f)
+}
+
+// AddDependentRequired records that the fields deps must be set when the field name is set.
+func (v *ValidationDefinition) AddDependentRequired(name string, deps []string) {
+	if v.DependentRequired == nil {
+		v.DependentRequired = make(map[string][]string)
+	}
+	v.DependentRequired[name] = appendMissing(v.DependentRequired[name], deps)
+}
+
+// HasCrossFieldRules returns true if the validation defines rules that involve more than one
+// field of the object attribute or calls user functions.
+func (v *ValidationDefinition) HasCrossFieldRules() bool {
+	return len(v.OneOfRequired) > 0 || len(v.MutuallyExclusive) > 0 ||
+		len(v.DependentRequired) > 0 || len(v.Funcs) > 0
 }
 
 // HasRequiredOnly returns true if the validation only has the Required field with a non-zero value.
@@ -177,19 +275,62 @@ func (v *ValidationDefinition) HasRequiredOnly() bool {
 	if (v.Minimum != nil) || (v.Maximum != nil) || (v.MaxLength != nil) {
 		return false
 	}
+	if v.MultipleOf != nil || v.UniqueItems || v.MinProperties != nil || v.MaxProperties != nil {
+		return false
+	}
+	if v.HasCrossFieldRules() {
+		return false
+	}
 	return true
 }
 
 // Dup makes a shallow dup of the validation.
 func (v *ValidationDefinition) Dup() *ValidationDefinition {
 	return &ValidationDefinition{
-		Values:    v.Values,
-		Format:    v.Format,
-		Pattern:   v.Pattern,
-		Minimum:   v.Minimum,
-		Maximum:   v.Maximum,
-		MinLength: v.MinLength,
-		MaxLength: v.MaxLength,
-		Required:  v.Required,
+		Values:            v.Values,
+		Format:            v.Format,
+		Pattern:           v.Pattern,
+		Minimum:           v.Minimum,
+		Maximum:           v.Maximum,
+		ExclusiveMinimum:  v.ExclusiveMinimum,
+		ExclusiveMaximum:  v.ExclusiveMaximum,
+		MultipleOf:        v.MultipleOf,
+		MinLength:         v.MinLength,
+		MaxLength:         v.MaxLength,
+		UniqueItems:       v.UniqueItems,
+		MinProperties:     v.MinProperties,
+		MaxProperties:     v.MaxProperties,
+		Required:          v.Required,
+		OneOfRequired:     v.OneOfRequired,
+		MutuallyExclusive: v.MutuallyExclusive,
+		DependentRequired: v.DependentRequired,
+		Funcs:             v.Funcs,
+	}
+}
+
+// appendGroup appends the group of field names to groups unless it already contains it.
+func appendGroup(groups [][]string, names []string) [][]string {
+	for _, g := range groups {
+		if strings.Join(g, ",") == strings.Join(names, ",") {
+			return groups
+		}
+	}
+	return append(groups, names)
+}
+
+// appendMissing appends the elements of names that are not already in s to s.
+func appendMissing(s, names []string) []string {
+	for _, n := range names {
+		found := false
+		for _, e := range s {
+			if n == e {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s = append(s, n)
+		}
 	}
+	return s
 }
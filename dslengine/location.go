@@ -0,0 +1,26 @@
+package dslengine
+
+// Location is the location in the design source of the DSL function call that created a
+// definition.
+type Location struct {
+	// File is the path to the design file relative to the working directory.
+	File string
+	// Line is the line number in File.
+	Line int
+}
+
+// locations indexes the recorded definition locations.
+var locations = make(map[Definition]*Location)
+
+// RecordLocation records the location of the DSL function call that creates the given definition.
+// It must be called by the DSL function itself so that the location heuristic used to report
+// errors skips the DSL package frames.
+func RecordLocation(def Definition) {
+	file, line := computeErrorLocation()
+	locations[def] = &Location{File: file, Line: line}
+}
+
+// LocationOf returns the location recorded for the given definition, nil if none.
+func LocationOf(def Definition) *Location {
+	return locations[def]
+}
@@ -72,6 +72,7 @@ func Reset() {
 		r.Reset()
 	}
 	Errors = nil
+	locations = make(map[Definition]*Location)
 }
 
 // Run runs the given root definitions. It iterates over the definition sets
@@ -250,23 +251,29 @@ func (s contextStack) Current() Definition {
 // When successful it returns the file name and line number, empty string and
 // 0 otherwise.
 func computeErrorLocation() (file string, line int) {
-	skipFunc := func(file string) bool {
+	skipFunc := func(pc uintptr, file string) bool {
 		if strings.HasSuffix(file, "_test.go") { // Be nice with tests
 			return false
 		}
+		// The function name starts with the package import path which does not depend on
+		// where the source files live, e.g. outside of GOPATH.
+		var name string
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			name = fn.Name()
+		}
 		file = filepath.ToSlash(file)
 		for pkg := range dslPackages {
-			if strings.Contains(file, pkg) {
+			if strings.Contains(file, pkg) || strings.HasPrefix(name, pkg) {
 				return true
 			}
 		}
 		return false
 	}
 	depth := 2
-	_, file, line, _ = runtime.Caller(depth)
-	for skipFunc(file) {
+	pc, file, line, _ := runtime.Caller(depth)
+	for skipFunc(pc, file) {
 		depth++
-		_, file, line, _ = runtime.Caller(depth)
+		pc, file, line, _ = runtime.Caller(depth)
 	}
 	wd, err := os.Getwd()
 	if err != nil {
@@ -1,6 +1,8 @@
 package dslengine_test
 
 import (
+	"runtime"
+
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -67,12 +69,10 @@ var _ = Describe("DSL errors", func() {
 	Context("with one error", func() {
 		const errMsg = "err"
 
-		// See NOTE below.
-		const lineNumber = 76
+		var lineNumber int
 
 		BeforeEach(func() {
-			// NOTE: moving the line below requires updating the
-			// constant above to match its number.
+			lineNumber = callerLine() + 1
 			dslengine.ReportError(errMsg)
 		})
 
@@ -101,13 +101,11 @@ var _ = Describe("DSL errors", func() {
 	})
 
 	Context("with invalid DSL", func() {
-		// See NOTE below.
-		const lineNumber = 111
+		var lineNumber int
 
 		BeforeEach(func() {
 			API("foo", func() {
-				// NOTE: moving the line below requires updating the
-				// constant above to match its number.
+				lineNumber = callerLine() + 1
 				Attributes(func() {})
 			})
 			dslengine.Run()
@@ -123,13 +121,11 @@ var _ = Describe("DSL errors", func() {
 	})
 
 	Context("with DSL calling a function with an invalid argument type", func() {
-		// See NOTE below.
-		const lineNumber = 133
+		var lineNumber int
 
 		BeforeEach(func() {
 			Type("bar", func() {
-				// NOTE: moving the line below requires updating the
-				// constant above to match its number.
+				lineNumber = callerLine() + 1
 				Attribute("baz", 42)
 			})
 			dslengine.Run()
@@ -165,3 +161,28 @@ var _ = Describe("DSL errors", func() {
 		})
 	})
 })
+
+var _ = Describe("RecordLocation", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("records the location of the DSL", func() {
+		API("foo", func() {})
+		res, line := Resource("bar", func() {}), callerLine()
+		Ω(dslengine.LocationOf(res)).Should(Equal(&dslengine.Location{File: "runner_test.go", Line: line}))
+	})
+
+	It("is cleared by Reset", func() {
+		res := Resource("bar", func() {})
+		dslengine.Reset()
+		Ω(dslengine.LocationOf(res)).Should(BeNil())
+	})
+})
+
+// callerLine returns the line of the statement that calls it so that the specs do not depend on
+// the position of the DSL in the file.
+func callerLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
@@ -215,6 +215,17 @@ func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string)
 	return nil
 }
 
+// ContentType returns the content type of the encoder used by Encode given the value of the
+// Accept header, "*/*" if Encode uses the default encoder.
+func (encoder *HTTPEncoder) ContentType(accept string) string {
+	for _, t := range encoder.contentTypes {
+		if accept == t {
+			return t
+		}
+	}
+	return "*/*"
+}
+
 // Register sets a specific encoder to be used for the specified content types. If an encoder is
 // already registered, it is overwritten.
 func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
@@ -1,3 +1,14 @@
+/*
+Package gogoprotobuf provides a protocol buffers encoder and decoder. The values must implement
+proto.Message, for example the messages generated by protoc from the definitions produced by
+"goagen proto". The functions generated alongside the definitions convert the messages to and from
+the data structures generated by "goagen app" so that the same controllers may serve both JSON and
+protocol buffers requests:
+
+    service.Encoder.Register(gogoprotobuf.NewEncoder, "application/x-protobuf")
+    service.Decoder.Register(gogoprotobuf.NewDecoder, "application/x-protobuf")
+
+*/
 package gogoprotobuf
 
 import (
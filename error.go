@@ -195,6 +195,12 @@ func MissingHeaderError(name string) error {
 	return ErrInvalidRequest(msg, "name", name)
 }
 
+// MissingCookieError is the error produced when a request is missing a required cookie.
+func MissingCookieError(name string) error {
+	msg := fmt.Sprintf("missing required HTTP cookie %#v", name)
+	return ErrInvalidRequest(msg, "name", name)
+}
+
 // InvalidEnumValueError is the error produced when the value of a parameter or payload field does
 // not match one the values defined in the design Enum validation.
 func InvalidEnumValueError(ctx string, val interface{}, allowed []interface{}) error {
@@ -231,6 +237,44 @@ func InvalidRangeError(ctx string, target interface{}, value interface{}, min bo
 	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
 }
 
+// InvalidExclusiveRangeError is the error produced when the value of a parameter or payload field
+// does not match the exclusive range validation defined in the design. value may be a int or a
+// float64.
+func InvalidExclusiveRangeError(ctx string, target interface{}, value interface{}, min bool) error {
+	comp := "greater than"
+	if !min {
+		comp = "less than"
+	}
+	msg := fmt.Sprintf("%s must be %s %v but got value %#v", ctx, comp, value, target)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+}
+
+// InvalidMultipleOfError is the error produced when the value of a parameter or payload field is
+// not a multiple of the value defined in the design MultipleOf validation.
+func InvalidMultipleOfError(ctx string, target interface{}, value interface{}) error {
+	msg := fmt.Sprintf("%s must be a multiple of %v but got value %#v", ctx, value, target)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "multiple_of", value)
+}
+
+// InvalidUniqueItemsError is the error produced when the elements of an array parameter or
+// payload field are not unique as required by the design UniqueItems validation. dup is the first
+// duplicated element.
+func InvalidUniqueItemsError(ctx string, target interface{}, dup interface{}) error {
+	msg := fmt.Sprintf("elements of %s must be unique but got duplicate value %#v", ctx, dup)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "duplicate", dup)
+}
+
+// InvalidPropertiesCountError is the error produced when the number of keys of a hash parameter or
+// payload field does not match the MinProperties or MaxProperties validation defined in the design.
+func InvalidPropertiesCountError(ctx string, target interface{}, count, value int, min bool) error {
+	comp := "greater than or equal to"
+	if !min {
+		comp = "less than or equal to"
+	}
+	msg := fmt.Sprintf("number of properties of %s must be %s %d but got %d", ctx, comp, value, count)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "count", count, "comp", comp, "expected", value)
+}
+
 // InvalidLengthError is the error produced when the value of a parameter or payload field does
 // not match the length validation defined in the design.
 func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool) error {
@@ -242,6 +286,47 @@ func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool)
 	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
 }
 
+// InvalidOneOfRequiredError is the error produced when not exactly one of the attributes of a
+// OneOfRequired validation defined in the design is set. set lists the attributes that are set.
+func InvalidOneOfRequiredError(ctx string, names, set []string) error {
+	msg := fmt.Sprintf("exactly one of the attributes %s of %s must be set but got %d", quoteNames(names), ctx, len(set))
+	return ErrInvalidRequest(msg, "attributes", names, "parent", ctx, "set", set)
+}
+
+// MutuallyExclusiveAttributesError is the error produced when more than one of the attributes of
+// a MutuallyExclusive validation defined in the design is set. set lists the attributes that are
+// set.
+func MutuallyExclusiveAttributesError(ctx string, set []string) error {
+	msg := fmt.Sprintf("attributes %s of %s are mutually exclusive", quoteNames(set), ctx)
+	return ErrInvalidRequest(msg, "attributes", set, "parent", ctx)
+}
+
+// MissingDependentAttributeError is the error produced when an attribute is missing while the
+// attribute it depends on according to a DependentRequired validation defined in the design is
+// set.
+func MissingDependentAttributeError(ctx, name, dependency string) error {
+	msg := fmt.Sprintf("attribute %#v of %s is required when attribute %#v is set", name, ctx, dependency)
+	return ErrInvalidRequest(msg, "attribute", name, "parent", ctx, "dependency", dependency)
+}
+
+// InvalidCustomValidationError is the error produced when a validation function defined in the
+// design with ValidateFunc fails. Errors produced with goa error classes are returned as is,
+// other errors are wrapped into an invalid request error listing the validated attributes.
+func InvalidCustomValidationError(ctx, fn string, names []string, err error) error {
+	if _, ok := err.(ServiceError); ok {
+		return err
+	}
+	msg := fmt.Sprintf("validation %s of attributes %s of %s failed: %s", fn, quoteNames(names), ctx, err)
+	return ErrInvalidRequest(msg, "attributes", names, "parent", ctx, "validation", fn, "error", err.Error())
+}
+
+// InvalidPatchError is the error produced when the operation at the given index of a JSON patch
+// sent in a request body cannot be applied.
+func InvalidPatchError(index int, op, path, reason string) error {
+	msg := fmt.Sprintf("patch operation #%d (%s %#v) failed: %s", index, op, path, reason)
+	return ErrInvalidRequest(msg, "index", index, "op", op, "path", path)
+}
+
 // NoAuthMiddleware is the error produced when goa is unable to lookup a auth middleware for a
 // security scheme defined in the design.
 func NoAuthMiddleware(schemeName string) error {
@@ -249,6 +334,33 @@ func NoAuthMiddleware(schemeName string) error {
 	return ErrNoAuthMiddleware(msg, "scheme", schemeName)
 }
 
+// UnsatisfiedSecurityError is the error produced when a request satisfies none of the alternative
+// security requirements of an action. errs contains the errors returned by the auth middlewares of
+// each requirement. Internal errors (e.g. a missing auth middleware) are only returned, the first
+// one as is, if all the requirements failed with one.
+func UnsatisfiedSecurityError(errs ...error) error {
+	var internal error
+	details := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if se, ok := err.(ServiceError); ok && se.ResponseStatus() >= 500 {
+			if internal == nil {
+				internal = err
+			}
+			continue
+		}
+		if e, ok := err.(*ErrorResponse); ok {
+			details = append(details, e.Detail)
+		} else {
+			details = append(details, err.Error())
+		}
+	}
+	if internal != nil && len(details) == 0 {
+		return internal
+	}
+	msg := fmt.Sprintf("none of the security requirements are satisfied: %s", strings.Join(details, "; "))
+	return ErrUnauthorized(msg)
+}
+
 // MethodNotAllowedError is the error produced to requests that match the path of a registered
 // handler but not the HTTP method.
 func MethodNotAllowedError(method string, allowed []string) error {
@@ -122,6 +122,22 @@ var _ = Describe("MissingHeaderError", func() {
 	})
 })
 
+var _ = Describe("MissingCookieError", func() {
+	var valErr error
+	name := "session"
+
+	JustBeforeEach(func() {
+		valErr = MissingCookieError(name)
+	})
+
+	It("creates a http error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(ContainSubstring(name))
+	})
+})
+
 var _ = Describe("MethodNotAllowedError", func() {
 	var valErr error
 	method := "POST"
@@ -162,6 +178,51 @@ var _ = Describe("MethodNotAllowedError", func() {
 	})
 })
 
+var _ = Describe("UnsatisfiedSecurityError", func() {
+	var errs []error
+	var valErr error
+
+	JustBeforeEach(func() {
+		valErr = UnsatisfiedSecurityError(errs...)
+	})
+
+	BeforeEach(func() {
+		errs = []error{ErrUnauthorized("invalid JWT"), errors.New("missing API key")}
+	})
+
+	It("creates a http error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Status).Should(Equal(401))
+		Ω(err.Detail).Should(ContainSubstring("invalid JWT; missing API key"))
+	})
+
+	Context("with an internal error", func() {
+		BeforeEach(func() {
+			errs = append(errs, NoAuthMiddleware("jwt"))
+		})
+
+		It("returns the unauthorized error of the other requirements", func() {
+			Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+			err := valErr.(*ErrorResponse)
+			Ω(err.Status).Should(Equal(401))
+			Ω(err.Detail).Should(ContainSubstring("invalid JWT; missing API key"))
+			Ω(err.Detail).ShouldNot(ContainSubstring("not mounted"))
+		})
+	})
+
+	Context("with internal errors only", func() {
+		BeforeEach(func() {
+			errs = []error{NoAuthMiddleware("jwt"), NoAuthMiddleware("api_key")}
+		})
+
+		It("returns the first internal error", func() {
+			Ω(valErr).Should(Equal(errs[0]))
+		})
+	})
+})
+
 var _ = Describe("InvalidEnumValueError", func() {
 	var valErr error
 	ctx := "ctx"
@@ -318,6 +379,76 @@ var _ = Describe("InvalidLengthError", func() {
 	})
 })
 
+var _ = Describe("InvalidMultipleOfError", func() {
+	It("creates a http error", func() {
+		valErr := InvalidMultipleOfError("ctx", 7, 5)
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(Equal("ctx must be a multiple of 5 but got value 7"))
+	})
+})
+
+var _ = Describe("InvalidUniqueItemsError", func() {
+	It("creates a http error", func() {
+		valErr := InvalidUniqueItemsError("ctx", []string{"a", "a"}, "a")
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(Equal(`elements of ctx must be unique but got duplicate value "a"`))
+	})
+})
+
+var _ = Describe("InvalidPropertiesCountError", func() {
+	It("creates a http error", func() {
+		valErr := InvalidPropertiesCountError("ctx", map[string]int{}, 0, 1, true)
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(Equal("number of properties of ctx must be greater than or equal to 1 but got 0"))
+	})
+})
+
+var _ = Describe("InvalidOneOfRequiredError", func() {
+	It("creates a http error", func() {
+		valErr := InvalidOneOfRequiredError("ctx", []string{"email", "phone"}, nil)
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(Equal(`exactly one of the attributes "email", "phone" of ctx must be set but got 0`))
+	})
+})
+
+var _ = Describe("MissingDependentAttributeError", func() {
+	It("creates a http error", func() {
+		valErr := MissingDependentAttributeError("ctx", "country", "postal_code")
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(Equal(`attribute "country" of ctx is required when attribute "postal_code" is set`))
+	})
+})
+
+var _ = Describe("InvalidCustomValidationError", func() {
+	It("wraps plain errors", func() {
+		valErr := InvalidCustomValidationError("ctx", "ValidatePeriod", []string{"start", "end"}, errors.New("bad period"))
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(ContainSubstring("ValidatePeriod"))
+		Ω(err.Detail).Should(ContainSubstring("bad period"))
+	})
+
+	It("returns service errors unchanged", func() {
+		svcErr := ErrBadRequest("bad period")
+		Ω(InvalidCustomValidationError("ctx", "ValidatePeriod", []string{"start"}, svcErr)).Should(Equal(svcErr))
+	})
+})
+
+var _ = Describe("InvalidPatchError", func() {
+	It("creates a http error", func() {
+		valErr := InvalidPatchError(1, "remove", "/name", "path not found")
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Status).Should(Equal(400))
+		Ω(err.Detail).Should(Equal(`patch operation #1 (remove "/name") failed: path not found`))
+	})
+})
+
 // MergeableErrorResponse contains the details of a error response.
 // It implements ServiceMergeableError.
 type MergeableErrorResponse struct {
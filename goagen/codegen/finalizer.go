@@ -3,6 +3,7 @@ package codegen
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"text/template"
 
 	"github.com/goadesign/goa/design"
@@ -70,7 +71,7 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 					"catt":       catt,
 					"depth":      depth,
 					"isDatetime": catt.Type == design.DateTime,
-					"defaultVal": PrintVal(catt.Type, catt.DefaultValue),
+					"defaultVal": PrintValAtt(catt, catt.DefaultValue),
 				}
 				if !first {
 					buf.WriteByte('\n')
@@ -81,7 +82,7 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 			}
 			a := f.recurse(root, catt, fmt.Sprintf("%s.%s", target, Goify(n, true)), depth+1).String()
 			if a != "" {
-				if catt.Type.IsObject() {
+				if catt.Type.IsObject() || catt.Type.IsUnion() {
 					a = fmt.Sprintf("%sif %s.%s != nil {\n%s\n%s}",
 						Tabs(depth), target, Goify(n, true), a, Tabs(depth))
 				}
@@ -103,6 +104,14 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 		if as := RunTemplate(f.arrayAssignmentT, data); as != "" {
 			buf.WriteString(as)
 		}
+	} else if u := att.Type.ToUnion(); u != nil {
+		// Union types set the default values of the variant value in their Finalize method.
+		for _, n := range u.VariantNames() {
+			if f.Code(u.Variants[n], "v", 1) != "" {
+				buf.WriteString(fmt.Sprintf("%s%s.Finalize()", Tabs(depth), target))
+				break
+			}
+		}
 	}
 	return buf
 }
@@ -135,7 +144,7 @@ func PrintVal(t design.DataType, val interface{}) string {
 		var buffer bytes.Buffer
 		buffer.WriteString(fmt.Sprintf("%s{", GoTypeName(t, nil, 0, false)))
 		for k, v := range hval {
-			buffer.WriteString(fmt.Sprintf("%s: %s, ", PrintVal(h.KeyType.Type, k), PrintVal(h.ElemType.Type, v)))
+			buffer.WriteString(fmt.Sprintf("%s: %s, ", PrintValAtt(h.KeyType, k), PrintValAtt(h.ElemType, v)))
 		}
 		buffer.Truncate(buffer.Len() - 2) // remove ", "
 		buffer.WriteString("}")
@@ -150,7 +159,7 @@ func PrintVal(t design.DataType, val interface{}) string {
 		var buffer bytes.Buffer
 		buffer.WriteString(fmt.Sprintf("%s{", GoTypeName(t, nil, 0, false)))
 		for _, e := range aval {
-			buffer.WriteString(fmt.Sprintf("%s, ", PrintVal(a.ElemType.Type, e)))
+			buffer.WriteString(fmt.Sprintf("%s, ", PrintValAtt(a.ElemType, e)))
 		}
 		buffer.Truncate(buffer.Len() - 2) // remove ", "
 		buffer.WriteString("}")
@@ -161,6 +170,30 @@ func PrintVal(t design.DataType, val interface{}) string {
 	}
 }
 
+// PrintValAtt prints the given value corresponding to the given attribute. It differs from
+// PrintVal in that values of attributes that use a numeric format are converted to the Go type
+// of the attribute, decimal values are printed as strings.
+func PrintValAtt(att *design.AttributeDefinition, val interface{}) string {
+	switch f := att.NumericFormat(); f {
+	case "":
+		if ut, ok := att.Type.(*design.UserTypeDefinition); ok && ut.IsEnum() {
+			return fmt.Sprintf("%s(%s)", GoTypeName(ut, nil, 0, false), PrintVal(ut.Type, val))
+		}
+		return PrintVal(att.Type, val)
+	case "decimal":
+		v, ok := val.(float64)
+		if i, isInt := val.(int); isInt {
+			v, ok = float64(i), true
+		}
+		if !ok {
+			return fmt.Sprintf("%#v", val)
+		}
+		return strconv.Quote(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return fmt.Sprintf("%s(%s)", f, PrintVal(att.Type, val))
+	}
+}
+
 const (
 	assignmentTmpl = `{{ if .catt.Type.IsPrimitive }}{{ $defaultName := (print "default" (goify .field true)) }}{{/*
 */}}{{ tabs .depth }}var {{ $defaultName }}{{if .isDatetime}}, _{{end}} = {{ .defaultVal }}
@@ -2,6 +2,9 @@ package codegen
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
 
 	"github.com/goadesign/goa/design"
 )
@@ -12,6 +15,15 @@ type ImportSpec struct {
 	Path string
 }
 
+var (
+	// majorVersionRegex matches the major version element of module import paths.
+	majorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+	// gopkgVersionRegex matches the version suffix of gopkg.in import paths.
+	gopkgVersionRegex = regexp.MustCompile(`\.v[0-9]+$`)
+	// nonIdentifierRegex matches the characters that are not valid in Go identifiers.
+	nonIdentifierRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
 // NewImport creates an import spec.
 func NewImport(name, path string) *ImportSpec {
 	return &ImportSpec{Name: name, Path: path}
@@ -30,8 +42,27 @@ func (s *ImportSpec) Code() string {
 	return fmt.Sprintf(`"%s"`, s.Path)
 }
 
+// PackageAlias returns the name used by the generated code to import the package with the given
+// import path. The last element of an import path is not necessarily the package name, e.g.
+// "gopkg.in/yaml.v2" or "example.com/go-validators/v2", so the generated code imports the package
+// explicitly with this name: the last element stripped of its version suffix and of the
+// characters that are not valid in identifiers.
+func PackageAlias(pkgPath string) string {
+	elems := strings.Split(strings.Trim(pkgPath, "/"), "/")
+	name := elems[len(elems)-1]
+	if majorVersionRegex.MatchString(name) && len(elems) > 1 {
+		name = elems[len(elems)-2]
+	}
+	name = gopkgVersionRegex.ReplaceAllString(name, "")
+	name = strings.ToLower(nonIdentifierRegex.ReplaceAllString(name, ""))
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "pkg" + name
+	}
+	return name
+}
+
 // AttributeImports will construct a new ImportsSpec slice from an existing slice and add in imports specified in
-// struct:field:type Metadata tags.
+// struct:field:type Metadata tags and by validation functions.
 func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, seen []*design.AttributeDefinition) []*ImportSpec {
 
 	for _, a := range seen {
@@ -49,6 +80,12 @@ func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, se
 		}
 	}
 
+	if att.Validation != nil {
+		for _, f := range att.Validation.Funcs {
+			imports = appendImports(imports, []*ImportSpec{NewImport(PackageAlias(f.PackagePath), f.PackagePath)})
+		}
+	}
+
 	switch t := att.Type.(type) {
 	case *design.UserTypeDefinition:
 		return appendImports(imports, AttributeImports(t.AttributeDefinition, imports, seen))
@@ -65,6 +102,12 @@ func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, se
 	case *design.Hash:
 		imports = appendImports(imports, AttributeImports(t.KeyType, imports, seen))
 		return appendImports(imports, AttributeImports(t.ElemType, imports, seen))
+	case *design.Union:
+		t.Variants.IterateAttributes(func(n string, v *design.AttributeDefinition) error {
+			imports = appendImports(imports, AttributeImports(v, imports, seen))
+			return nil
+		})
+		return imports
 	}
 
 	return imports
@@ -75,7 +118,7 @@ func appendImports(i, a []*ImportSpec) []*ImportSpec {
 	for _, v := range a {
 		contains := false
 		for _, att := range i {
-			if att.Path == v.Path {
+			if att.Path == v.Path && att.Name == v.Name {
 				contains = true
 				break
 			}
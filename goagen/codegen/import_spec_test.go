@@ -183,5 +183,33 @@ var _ = Describe("AttributeImports", func() {
 				Ω(st).Should(Equal(imports[0].Path))
 			})
 		})
+
+		Context("of object with validation functions", func() {
+			It("imports the packages with an explicit name", func() {
+				att = &AttributeDefinition{
+					Type: Object{"foo": &AttributeDefinition{Type: String}},
+					Validation: &dslengine.ValidationDefinition{
+						Funcs: []*dslengine.ValidationFuncDefinition{{
+							PackagePath: "example.com/go-validators/v2",
+							Name:        "ValidateFoo",
+							Attributes:  []string{"foo"},
+						}},
+					},
+				}
+				imports := codegen.AttributeImports(att, nil, nil)
+
+				Ω(imports).Should(HaveLen(1))
+				Ω(imports[0].Code()).Should(Equal(`govalidators "example.com/go-validators/v2"`))
+			})
+		})
+	})
+})
+
+var _ = Describe("PackageAlias", func() {
+	It("returns a valid identifier derived from the import path", func() {
+		Ω(codegen.PackageAlias("example.com/validators")).Should(Equal("validators"))
+		Ω(codegen.PackageAlias("example.com/go-validators/v2")).Should(Equal("govalidators"))
+		Ω(codegen.PackageAlias("gopkg.in/check.v1")).Should(Equal("check"))
+		Ω(codegen.PackageAlias("example.com/2fa")).Should(Equal("pkg2fa"))
 	})
 })
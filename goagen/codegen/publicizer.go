@@ -53,6 +53,12 @@ func RecursivePublicizer(att *design.AttributeDefinition, source, target string,
 			att = ds.Definition()
 		}
 		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+			if catt.Nullable {
+				// The private and public data structures use the same nullable type.
+				publications = append(publications, fmt.Sprintf("%s%s.%s = %s.%s",
+					Tabs(depth), target, Goify(n, true), source, Goify(n, true)))
+				return nil
+			}
 			publication := Publicizer(
 				catt,
 				fmt.Sprintf("%s.%s", source, Goify(n, true)),
@@ -92,6 +98,8 @@ func Publicizer(att *design.AttributeDefinition, sourceField, targetField string
 		} else {
 			publication = RunTemplate(objectPublicizeT, data)
 		}
+	case att.Type.IsUnion():
+		publication = RunTemplate(recursivePublicizeT, data)
 	case att.Type.IsArray():
 		// If the array element is primitive type, we can simply copy the elements over (i.e) []string
 		if att.Type.HasAttributes() {
@@ -131,11 +139,11 @@ const (
 */}}{{ $k := printf "%s%d" "k" .depth }}{{ $v := printf "%s%d" "v" .depth }}
 {{ tabs .depth }}for {{ $k }}, {{ $v }} := range {{ .sourceField }} {
 {{ $pubk := printf "%s%s" "pub" $k }}{{ $pubv := printf "%s%s" "pub" $v }}{{/*
-*/}}{{ tabs (add .depth 1) }}{{ if .keyType.Type.IsObject }}var {{ $pubk }} {{ gotyperef .keyType.Type .AllRequired .depth false}}
+*/}}{{ tabs (add .depth 1) }}{{ if or .keyType.Type.IsObject .keyType.Type.IsUnion }}var {{ $pubk }} {{ gotyperef .keyType.Type .AllRequired .depth false}}
 {{ tabs (add .depth 1) }}if {{ $k }} != nil {
 {{ tabs (add .depth 1) }}{{ publicizer .keyType $k $pubk .dereference (add .depth 1) false }}
 {{ tabs (add .depth 1) }}}{{ else }}{{ publicizer .keyType $k $pubk .dereference (add .depth 1) true }}{{ end }}
-{{ tabs (add .depth 1) }}{{if .elemType.Type.IsObject }}var {{ $pubv }} {{ gotyperef .elemType.Type .AllRequired .depth false }}
+{{ tabs (add .depth 1) }}{{ if or .elemType.Type.IsObject .elemType.Type.IsUnion }}var {{ $pubv }} {{ gotyperef .elemType.Type .AllRequired .depth false }}
 {{ tabs (add .depth 1) }}if {{ $v }} != nil {
 {{ tabs (add .depth 1) }}{{ publicizer .elemType $v $pubv .dereference (add .depth 1) false }}
 {{ tabs (add .depth 1) }}}{{ else }}{{ publicizer .elemType $v $pubv .dereference (add .depth 1) true }}{{ end }}
@@ -16,6 +16,10 @@ import (
 // generating the code that transforms one data structure into another.
 const TransformMapKey = "transform:key"
 
+// EnumNamesKey is the name of the metadata used to specify the names of the constants generated
+// for the values of enum types.
+const EnumNamesKey = "enum:names"
+
 var (
 	// TempCount holds the value appended to variable names to make them unique.
 	TempCount int
@@ -25,6 +29,9 @@ var (
 	transformArrayT  *template.Template
 	transformHashT   *template.Template
 	transformObjectT *template.Template
+
+	// nullableMarshalerT is the template used by NullableMarshaler.
+	nullableMarshalerT *template.Template
 )
 
 // Initialize all templates
@@ -54,6 +61,9 @@ func init() {
 	if transformObjectT, err = template.New("transformObject").Funcs(fn).Parse(transformObjectTmpl); err != nil {
 		panic(err) // bug
 	}
+	if nullableMarshalerT, err = template.New("nullableMarshaler").Parse(nullableMarshalerTmpl); err != nil {
+		panic(err) // bug
+	}
 }
 
 // GoTypeDef returns the Go code that defines a Go type which matches the data structure
@@ -73,20 +83,20 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 	t := def.Type
 	switch actual := t.(type) {
 	case design.Primitive:
-		return GoTypeName(t, nil, tabs, private)
+		return GoNativeTypeAtt(def)
 	case *design.Array:
 		d := GoTypeDef(actual.ElemType, tabs, jsonTags, private)
-		if actual.ElemType.Type.IsObject() {
+		if actual.ElemType.Type.IsObject() || actual.ElemType.Type.IsUnion() {
 			d = "*" + d
 		}
 		return "[]" + d
 	case *design.Hash:
 		keyDef := GoTypeDef(actual.KeyType, tabs, jsonTags, private)
-		if actual.KeyType.Type.IsObject() {
+		if actual.KeyType.Type.IsObject() || actual.KeyType.Type.IsUnion() {
 			keyDef = "*" + keyDef
 		}
 		elemDef := GoTypeDef(actual.ElemType, tabs, jsonTags, private)
-		if actual.ElemType.Type.IsObject() {
+		if actual.ElemType.Type.IsObject() || actual.ElemType.Type.IsUnion() {
 			elemDef = "*" + elemDef
 		}
 		return fmt.Sprintf("map[%s]%s", keyDef, elemDef)
@@ -116,7 +126,9 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 		WriteTabs(&buffer, tabs+1)
 		field := obj[name]
 		typedef := GoTypeDef(field, tabs+1, jsonTags, private)
-		if (private && field.Type.IsPrimitive() && !def.IsInterface(name)) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
+		if field.Nullable {
+			typedef = NullableTypeName(field)
+		} else if (private && field.Type.IsPrimitive() && !def.IsInterface(name)) || field.Type.IsObject() || field.Type.IsUnion() || def.IsPrimitivePointer(name) {
 			typedef = "*" + typedef
 		}
 		fname := GoifyAtt(field, name, true)
@@ -158,11 +170,105 @@ func attributeTags(parent, att *design.AttributeDefinition, name string, private
 		return " `" + strings.Join(elems, " ") + "`"
 	}
 	// Default algorithm
-	var omit string
+	if att.Nullable {
+		// The nullable type encodes the value, the MarshalJSON method of the struct omits it
+		// when not set, see NullableMarshaler.
+		return fmt.Sprintf(" `form:\"%s,omitempty\" json:\"%s\" xml:\"%s,omitempty\"`", name, name, name)
+	}
+	var omit, enc string
 	if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
 		omit = ",omitempty"
 	}
-	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" xml:\"%s%s\"`", name, omit, name, omit, name, omit)
+	if att.NumericFormat() == "int64" {
+		enc = ",string"
+	}
+	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s%s\" xml:\"%s%s\"`", name, omit, name, omit, enc, name, omit)
+}
+
+// NullableTypeName returns the name of the Go type that holds the value of the given nullable
+// attribute, e.g. "NullableString" or "NullableInt64Array". The type records whether the attribute
+// is set and whether it is null, see the Nullable DSL.
+func NullableTypeName(att *design.AttributeDefinition) string {
+	return "Nullable" + nullableSuffix(att)
+}
+
+// nullableSuffix computes the part of the nullable type name that identifies the value type.
+func nullableSuffix(att *design.AttributeDefinition) string {
+	switch actual := att.Type.(type) {
+	case *design.Array:
+		return nullableSuffix(actual.ElemType) + "Array"
+	case *design.Hash:
+		return nullableSuffix(actual.KeyType) + nullableSuffix(actual.ElemType) + "Map"
+	}
+	switch t := GoNativeTypeAtt(att); t {
+	case "interface{}":
+		return "Any"
+	case "time.Time":
+		return "Time"
+	case "uuid.UUID":
+		return "UUID"
+	default:
+		return Goify(t, true)
+	}
+}
+
+// NullableMarshaler returns the code of the MarshalJSON method of the Go struct type with the given
+// name that defines the given object, the empty string if the object has no nullable attribute.
+// The nullable types encode null when the attribute is absent, the method omits the attributes
+// that are not set instead by shadowing the corresponding struct fields with pointers tagged with
+// omitempty.
+func NullableMarshaler(typeName string, ds design.DataStructure) string {
+	obj := ds.Definition().Type.ToObject()
+	var names []string
+	for n, att := range obj {
+		if att.Nullable {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	fields := make([]map[string]string, len(names))
+	for i, n := range names {
+		att := obj[n]
+		jsonName := n
+		if tag, ok := att.Metadata["struct:tag:json"]; ok && len(tag) > 0 {
+			jsonName = strings.Split(tag[0], ",")[0]
+		}
+		fields[i] = map[string]string{
+			"field": GoifyAtt(att, n, true),
+			"type":  NullableTypeName(att),
+			"tag":   fmt.Sprintf("`json:%q`", jsonName+",omitempty"),
+		}
+	}
+	return RunTemplate(nullableMarshalerT, map[string]interface{}{"typeName": typeName, "fields": fields})
+}
+
+// NullableTypes returns the nullable attributes contained in the given data structures, one for
+// each distinct type name returned by NullableTypeName. The attributes are sorted by type name.
+func NullableTypes(dss ...design.DataStructure) []*design.AttributeDefinition {
+	byName := make(map[string]*design.AttributeDefinition)
+	for _, ds := range dss {
+		ds.Walk(func(a *design.AttributeDefinition) error {
+			if a.Nullable {
+				if n := NullableTypeName(a); byName[n] == nil {
+					byName[n] = a
+				}
+			}
+			return nil
+		})
+	}
+	names := make([]string, 0, len(byName))
+	for n := range byName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	res := make([]*design.AttributeDefinition, len(names))
+	for i, n := range names {
+		res[i] = byName[n]
+	}
+	return res
 }
 
 // GoTypeRef returns the Go code that refers to the Go type which matches the given data type
@@ -179,7 +285,7 @@ func GoTypeRef(t design.DataType, required []string, tabs int, private bool) str
 			return "error"
 		}
 	}
-	if t.IsObject() {
+	if t.IsObject() || t.IsUnion() {
 		return "*" + tname
 	}
 	return tname
@@ -196,7 +302,7 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 	case design.Primitive:
 		return GoNativeType(t)
 	case *design.Array:
-		return "[]" + GoTypeRef(actual.ElemType.Type, actual.ElemType.AllRequired(), tabs+1, private)
+		return "[]" + goTypeRefAtt(actual.ElemType, tabs+1, private)
 	case design.Object:
 		att := &design.AttributeDefinition{Type: actual}
 		if len(required) > 0 {
@@ -207,10 +313,14 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 	case *design.Hash:
 		return fmt.Sprintf(
 			"map[%s]%s",
-			GoTypeRef(actual.KeyType.Type, actual.KeyType.AllRequired(), tabs+1, private),
-			GoTypeRef(actual.ElemType.Type, actual.ElemType.AllRequired(), tabs+1, private),
+			goTypeRefAtt(actual.KeyType, tabs+1, private),
+			goTypeRefAtt(actual.ElemType, tabs+1, private),
 		)
 	case *design.UserTypeDefinition:
+		if actual.IsEnum() {
+			// Enum types have no private counterpart.
+			return Goify(actual.TypeName, true)
+		}
 		return Goify(actual.TypeName, !private)
 	case *design.MediaTypeDefinition:
 		if actual.IsError() {
@@ -222,6 +332,38 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 	}
 }
 
+// goTypeRefAtt returns the Go code that refers to the Go type of the given attribute, taking into
+// account the numeric format of primitive attributes.
+func goTypeRefAtt(att *design.AttributeDefinition, tabs int, private bool) string {
+	if att.Type.IsPrimitive() {
+		return GoNativeTypeAtt(att)
+	}
+	return GoTypeRef(att.Type, att.AllRequired(), tabs, private)
+}
+
+// GoNativeTypeAtt returns the Go built-in type from which instances of the given attribute can be
+// initialized. It differs from GoNativeType in that it takes into account the numeric format of
+// Integer and Number attributes, for example "int64" or "decimal" (generated as string).
+func GoNativeTypeAtt(att *design.AttributeDefinition) string {
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		switch f := att.NumericFormat(); f {
+		case "":
+			return GoNativeType(actual)
+		case "decimal":
+			return "string"
+		default:
+			return f
+		}
+	case *design.Array:
+		return "[]" + GoNativeTypeAtt(actual.ElemType)
+	case *design.Hash:
+		return fmt.Sprintf("map[%s]%s", GoNativeTypeAtt(actual.KeyType), GoNativeTypeAtt(actual.ElemType))
+	default:
+		return GoNativeType(actual)
+	}
+}
+
 // GoNativeType returns the Go built-in type from which instances of t can be initialized.
 func GoNativeType(t design.DataType) string {
 	switch actual := t.(type) {
@@ -252,6 +394,8 @@ func GoNativeType(t design.DataType) string {
 		return "map[string]interface{}"
 	case *design.Hash:
 		return fmt.Sprintf("map[%s]%s", GoNativeType(actual.KeyType.Type), GoNativeType(actual.ElemType.Type))
+	case *design.Union:
+		return "interface{}"
 	case *design.MediaTypeDefinition:
 		return GoNativeType(actual.Type)
 	case *design.UserTypeDefinition:
@@ -261,6 +405,28 @@ func GoNativeType(t design.DataType) string {
 	}
 }
 
+// EnumValueNames returns the names of the values of the given enum type: the names listed in the
+// "enum:names" metadata of the type if any, the values otherwise. The names are Goified and the
+// names that do not start with a letter are prefixed with "Value". The generated constants are
+// named after the type followed by these names.
+func EnumValueNames(ut *design.UserTypeDefinition) []string {
+	values := ut.Validation.Values
+	given := ut.Metadata[EnumNamesKey]
+	names := make([]string, len(values))
+	for i, v := range values {
+		n := fmt.Sprint(v)
+		if i < len(given) {
+			n = given[i]
+		}
+		n = Goify(n, true)
+		if n == "" || !unicode.IsLetter([]rune(n)[0]) {
+			n = "Value" + n
+		}
+		names[i] = n
+	}
+	return names
+}
+
 // GoTypeDesc returns the description of a type.  If no description is defined
 // for the type, one will be generated.
 func GoTypeDesc(t design.DataType, upper bool) string {
@@ -750,3 +916,19 @@ const transformHashTmpl = `{{ tabs .Depth }}{{ .TargetCtx }} = make(map[{{ gotyp
 */}}{{ tabs .Depth }}	{{ .TargetCtx }}[tk] = tv
 {{ tabs .Depth }}}
 `
+
+const nullableMarshalerTmpl = `
+// MarshalJSON encodes the {{ .typeName }} instance, the nullable attributes that are not set are
+// omitted.
+func (v {{ .typeName }}) MarshalJSON() ([]byte, error) {
+	type alias {{ .typeName }}
+	set := struct {
+		alias
+{{ range .fields }}		{{ .field }} *{{ .type }} {{ .tag }}
+{{ end }}	}{alias: alias(v)}
+{{ range .fields }}	if v.{{ .field }}.Set {
+		set.{{ .field }} = &v.{{ .field }}
+	}
+{{ end }}	return json.Marshal(set)
+}
+`
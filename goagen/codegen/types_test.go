@@ -328,6 +328,51 @@ var _ = Describe("code generation", func() {
 				})
 			})
 
+			Context("of numeric types with formats", func() {
+				BeforeEach(func() {
+					format := func(f string) *dslengine.ValidationDefinition {
+						return &dslengine.ValidationDefinition{Format: f}
+					}
+					object = Object{
+						"id":     &AttributeDefinition{Type: Integer, Validation: format("int64")},
+						"amount": &AttributeDefinition{Type: Number, Validation: format("decimal")},
+						"counts": &AttributeDefinition{Type: &Array{ElemType: &AttributeDefinition{Type: Integer, Validation: format("uint32")}}},
+						"ratio":  &AttributeDefinition{Type: Number, Validation: format("float32")},
+					}
+					required = nil
+				})
+
+				It("produces the struct go code", func() {
+					expected := "struct {\n" +
+						"	Amount *string `form:\"amount,omitempty\" json:\"amount,omitempty\" xml:\"amount,omitempty\"`\n" +
+						"	Counts []uint32 `form:\"counts,omitempty\" json:\"counts,omitempty\" xml:\"counts,omitempty\"`\n" +
+						"	ID *int64 `form:\"id,omitempty\" json:\"id,omitempty,string\" xml:\"id,omitempty\"`\n" +
+						"	Ratio *float32 `form:\"ratio,omitempty\" json:\"ratio,omitempty\" xml:\"ratio,omitempty\"`\n" +
+						"}"
+					Ω(st).Should(Equal(expected))
+				})
+			})
+
+			Context("of nullable types", func() {
+				BeforeEach(func() {
+					object = Object{
+						"nick": &AttributeDefinition{Type: String, Nullable: true},
+						"tags": &AttributeDefinition{Type: &Array{ElemType: &AttributeDefinition{Type: String}}, Nullable: true},
+						"when": &AttributeDefinition{Type: DateTime, Nullable: true},
+					}
+					required = &dslengine.ValidationDefinition{Required: []string{"nick"}}
+				})
+
+				It("produces the struct go code", func() {
+					expected := "struct {\n" +
+						"	Nick NullableString `form:\"nick,omitempty\" json:\"nick\" xml:\"nick,omitempty\"`\n" +
+						"	Tags NullableStringArray `form:\"tags,omitempty\" json:\"tags\" xml:\"tags,omitempty\"`\n" +
+						"	When NullableTime `form:\"when,omitempty\" json:\"when\" xml:\"when,omitempty\"`\n" +
+						"}"
+					Ω(st).Should(Equal(expected))
+				})
+			})
+
 			Context("of hash of objects", func() {
 				BeforeEach(func() {
 					elem := Object{
@@ -775,3 +820,36 @@ var _ = Describe("GoTypeDesc", func() {
 		})
 	})
 })
+
+var _ = Describe("EnumValueNames", func() {
+	var ut *UserTypeDefinition
+	var names []string
+
+	BeforeEach(func() {
+		ut = &UserTypeDefinition{
+			TypeName: "Status",
+			AttributeDefinition: &AttributeDefinition{
+				Type:       String,
+				Validation: &dslengine.ValidationDefinition{Values: []interface{}{"open", "in-cellar", "2nd"}},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		names = codegen.EnumValueNames(ut)
+	})
+
+	It("names the constants after the values", func() {
+		Ω(names).Should(Equal([]string{"Open", "InCellar", "Value2nd"}))
+	})
+
+	Context("with enum:names metadata", func() {
+		BeforeEach(func() {
+			ut.Metadata = dslengine.MetadataDefinition{codegen.EnumNamesKey: {"opened", "InCellar", "second"}}
+		})
+
+		It("uses the metadata names", func() {
+			Ω(names).Should(Equal([]string{"Opened", "InCellar", "Second"}))
+		})
+	})
+})
@@ -5,19 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 )
 
 var (
-	enumValT     *template.Template
-	formatValT   *template.Template
-	patternValT  *template.Template
-	minMaxValT   *template.Template
-	lengthValT   *template.Template
-	requiredValT *template.Template
+	enumValT        *template.Template
+	formatValT      *template.Template
+	patternValT     *template.Template
+	minMaxValT      *template.Template
+	multipleOfValT  *template.Template
+	lengthValT      *template.Template
+	uniqueItemsValT *template.Template
+	propertiesValT  *template.Template
+	requiredValT    *template.Template
+	oneOfReqValT    *template.Template
+	exclusiveValT   *template.Template
+	dependentValT   *template.Template
+	validateFuncT   *template.Template
 )
 
 //  init instantiates the templates.
@@ -30,6 +40,7 @@ func init() {
 		"constant": constant,
 		"goifyAtt": GoifyAtt,
 		"add":      Add,
+		"join":     strings.Join,
 	}
 	if enumValT, err = template.New("enum").Funcs(fm).Parse(enumValTmpl); err != nil {
 		panic(err)
@@ -43,12 +54,33 @@ func init() {
 	if minMaxValT, err = template.New("minMax").Funcs(fm).Parse(minMaxValTmpl); err != nil {
 		panic(err)
 	}
+	if multipleOfValT, err = template.New("multipleOf").Funcs(fm).Parse(multipleOfValTmpl); err != nil {
+		panic(err)
+	}
 	if lengthValT, err = template.New("length").Funcs(fm).Parse(lengthValTmpl); err != nil {
 		panic(err)
 	}
+	if uniqueItemsValT, err = template.New("uniqueItems").Funcs(fm).Parse(uniqueItemsValTmpl); err != nil {
+		panic(err)
+	}
+	if propertiesValT, err = template.New("properties").Funcs(fm).Parse(propertiesValTmpl); err != nil {
+		panic(err)
+	}
 	if requiredValT, err = template.New("required").Funcs(fm).Parse(requiredValTmpl); err != nil {
 		panic(err)
 	}
+	if oneOfReqValT, err = template.New("oneOfRequired").Funcs(fm).Parse(oneOfRequiredValTmpl); err != nil {
+		panic(err)
+	}
+	if exclusiveValT, err = template.New("mutuallyExclusive").Funcs(fm).Parse(mutuallyExclusiveValTmpl); err != nil {
+		panic(err)
+	}
+	if dependentValT, err = template.New("dependentRequired").Funcs(fm).Parse(dependentRequiredValTmpl); err != nil {
+		panic(err)
+	}
+	if validateFuncT, err = template.New("validateFunc").Funcs(fm).Parse(validateFuncTmpl); err != nil {
+		panic(err)
+	}
 }
 
 // Validator is the code generator for the 'Validate' type methods.
@@ -114,9 +146,10 @@ func (v *Validator) arrayValCode(att *design.AttributeDefinition, nonzero, requi
 		buf.WriteString(validation)
 		first = false
 	}
+	elem := enumAttribute(a.ElemType)
 	val := v.Code(a.ElemType, true, false, false, "e", context+"[*]", depth+1, false)
 	if val != "" {
-		switch a.ElemType.Type.(type) {
+		switch elem.Type.(type) {
 		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
 			// For user and media types, call the Validate method
 			val = RunTemplate(v.userValT, map[string]interface{}{
@@ -159,9 +192,10 @@ func (v *Validator) hashValCode(att *design.AttributeDefinition, nonzero, requir
 		buf.WriteString(validation)
 		first = false
 	}
+	key := enumAttribute(h.KeyType)
 	keyVal := v.Code(h.KeyType, true, false, false, "k", context+"[*]", depth+1, false)
 	if keyVal != "" {
-		switch h.KeyType.Type.(type) {
+		switch key.Type.(type) {
 		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
 			// For user and media types, call the Validate method
 			keyVal = RunTemplate(v.userValT, map[string]interface{}{
@@ -171,9 +205,10 @@ func (v *Validator) hashValCode(att *design.AttributeDefinition, nonzero, requir
 			keyVal = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+1), keyVal, Tabs(depth+1))
 		}
 	}
+	elem := enumAttribute(h.ElemType)
 	elemVal := v.Code(h.ElemType, true, false, false, "e", context+"[*]", depth+1, false)
 	if elemVal != "" {
-		switch h.ElemType.Type.(type) {
+		switch elem.Type.(type) {
 		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
 			// For user and media types, call the Validate method
 			elemVal = RunTemplate(v.userValT, map[string]interface{}{
@@ -206,6 +241,11 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 		buf   = new(bytes.Buffer)
 		first = true
 	)
+	if isEnum(att.Type) {
+		// Enum types are primitive types, there is no recursion to break.
+		buf.WriteString(ValidationChecker(att, nonzero, required, hasDefault, target, context, depth, private))
+		return buf
+	}
 
 	// Break infinite recursions
 	switch dt := att.Type.(type) {
@@ -246,6 +286,12 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 		buf.Write(v.arrayValCode(att, nonzero, required, hasDefault, target, context, depth, private))
 	} else if h := att.Type.ToHash(); h != nil {
 		buf.Write(v.hashValCode(att, nonzero, required, hasDefault, target, context, depth, private))
+	} else if att.Type.IsUnion() {
+		// Union types validate the variant value in their Validate method.
+		buf.WriteString(RunTemplate(v.userValT, map[string]interface{}{
+			"depth":  depth,
+			"target": target,
+		}))
 	} else {
 		validation := ValidationChecker(att, nonzero, required, hasDefault, target, context, depth, private)
 		if validation != "" {
@@ -257,11 +303,21 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 
 func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, target, context string, depth int, private bool) string {
 	var validation string
-	if ds, ok := catt.Type.(design.DataStructure); ok {
+	if catt.Nullable {
+		// The value of a nullable attribute is only validated when set and not null.
+		field := fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true))
+		validation = v.recurse(catt, false, true, false, field+".Value", fmt.Sprintf("%s.%s", context, n), depth, false).String()
+		if validation != "" {
+			validation = fmt.Sprintf("%sif %s.Set && !%s.Null {\n%s\n%s}", Tabs(depth), field, field, validation, Tabs(depth))
+		}
+		return validation
+	}
+	if ds, ok := catt.Type.(design.DataStructure); ok && !isEnum(catt.Type) {
 		// We need to check empirically whether there are validations to be
 		// generated, we can't just generate and check whether something was
-		// generated to avoid infinite recursions.
-		hasValidations := false
+		// generated to avoid infinite recursions. Union types always
+		// validate the variant value.
+		hasValidations := catt.Type.IsUnion()
 		done := errors.New("done")
 		ds.Walk(func(a *design.AttributeDefinition) error {
 			if a.Validation != nil {
@@ -281,7 +337,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 				}
 				for _, name := range a.Validation.Required {
 					att := a.Type.ToObject()[name]
-					if att != nil && (!att.Type.IsPrimitive() || att.Type.Kind() == design.StringKind) {
+					if att != nil && (!att.Type.IsPrimitive() || att.Type.Kind() == design.StringKind || att.Nullable) {
 						hasValidations = true
 						return done
 					}
@@ -312,7 +368,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 		).String()
 	}
 	if validation != "" {
-		if catt.Type.IsObject() {
+		if catt.Type.IsObject() || catt.Type.IsUnion() {
 			validation = fmt.Sprintf("%sif %s.%s != nil {\n%s\n%s}",
 				Tabs(depth), target, GoifyAtt(catt, n, true), validation, Tabs(depth))
 		}
@@ -320,6 +376,30 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 	return validation
 }
 
+// enumAttribute returns a copy of att that uses the base type of its enum user type and the
+// validations of both the attribute and the enum type so that they are validated where the enum
+// type is used. It returns att if its type is not an enum type.
+func enumAttribute(att *design.AttributeDefinition) *design.AttributeDefinition {
+	ut, ok := att.Type.(*design.UserTypeDefinition)
+	if !ok || !ut.IsEnum() {
+		return att
+	}
+	res := *att
+	res.Type = ut.Type
+	res.Validation = new(dslengine.ValidationDefinition)
+	if att.Validation != nil {
+		res.Validation = att.Validation.Dup()
+	}
+	res.Validation.Merge(ut.Validation)
+	return &res
+}
+
+// isEnum returns true if dt is an enum user type.
+func isEnum(dt design.DataType) bool {
+	ut, ok := dt.(*design.UserTypeDefinition)
+	return ok && ut.IsEnum()
+}
+
 // ValidationChecker produces Go code that runs the validation defined in the given attribute
 // definition against the content of the variable named target recursively.
 // context is used to keep track of recursion to produce helpful error messages in case of type
@@ -328,6 +408,10 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 // error. It initializes that variable in case a validation fails.
 // Note: we do not want to recurse here, recursion is done by the marshaler/unmarshaler code.
 func ValidationChecker(att *design.AttributeDefinition, nonzero, required, hasDefault bool, target, context string, depth int, private bool) string {
+	enum := isEnum(att.Type)
+	if enum {
+		att = enumAttribute(att)
+	}
 	if att.Validation == nil {
 		return ""
 	}
@@ -336,18 +420,29 @@ func ValidationChecker(att *design.AttributeDefinition, nonzero, required, hasDe
 	if isPointer && att.Type.IsPrimitive() {
 		t = "*" + t
 	}
+	// The values of string enum types must be converted before being given to the functions
+	// that validate strings.
+	st, stv := target, t
+	if enum && att.Type.Kind() == design.StringKind {
+		st, stv = "string("+target+")", "string("+t+")"
+	}
 	data := map[string]interface{}{
-		"attribute": att,
-		"isPointer": private || isPointer,
-		"nonzero":   nonzero,
-		"context":   context,
-		"target":    target,
-		"targetVal": t,
-		"string":    att.Type.Kind() == design.StringKind,
-		"array":     att.Type.IsArray(),
-		"hash":      att.Type.IsHash(),
-		"depth":     depth,
-		"private":   private,
+		"attribute":       att,
+		"isPointer":       private || isPointer,
+		"nonzero":         nonzero,
+		"context":         context,
+		"target":          target,
+		"targetVal":       t,
+		"stringTarget":    st,
+		"stringTargetVal": stv,
+		"string":          att.Type.Kind() == design.StringKind,
+		"array":           att.Type.IsArray(),
+		"hash":            att.Type.IsHash(),
+		"decimal":         att.NumericFormat() == "decimal",
+		"float32":         att.NumericFormat() == "float32",
+		"integer":         att.Type.Kind() == design.IntegerKind,
+		"depth":           depth,
+		"private":         private,
 	}
 	res := validationsCode(att, data)
 	return strings.Join(res, "\n")
@@ -361,7 +456,7 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 			res = append(res, val)
 		}
 	}
-	if format := validation.Format; format != "" {
+	if format := validation.Format; format != "" && (att.NumericFormat() == "" || format == "decimal") {
 		data["format"] = format
 		if val := RunTemplate(formatValT, data); val != "" {
 			res = append(res, val)
@@ -380,6 +475,7 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 			data["min"] = fmt.Sprintf("%f", *min)
 		}
 		data["isMin"] = true
+		data["exclusive"] = validation.ExclusiveMinimum
 		delete(data, "max")
 		if val := RunTemplate(minMaxValT, data); val != "" {
 			res = append(res, val)
@@ -392,11 +488,22 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 			data["max"] = fmt.Sprintf("%f", *max)
 		}
 		data["isMin"] = false
+		data["exclusive"] = validation.ExclusiveMaximum
 		delete(data, "min")
 		if val := RunTemplate(minMaxValT, data); val != "" {
 			res = append(res, val)
 		}
 	}
+	if multipleOf := validation.MultipleOf; multipleOf != nil {
+		if att.Type == design.Integer {
+			data["multipleOf"] = renderInteger(*multipleOf)
+		} else {
+			data["multipleOf"] = strconv.FormatFloat(*multipleOf, 'g', -1, 64)
+		}
+		if val := RunTemplate(multipleOfValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
 	if minLength := validation.MinLength; minLength != nil {
 		data["minLength"] = minLength
 		data["isMinLength"] = true
@@ -413,6 +520,25 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 			res = append(res, val)
 		}
 	}
+	if validation.UniqueItems && att.Type.IsArray() {
+		if val := RunTemplate(uniqueItemsValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
+	if minProperties := validation.MinProperties; minProperties != nil {
+		data["properties"] = *minProperties
+		data["isMinProperties"] = true
+		if val := RunTemplate(propertiesValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
+	if maxProperties := validation.MaxProperties; maxProperties != nil {
+		data["properties"] = *maxProperties
+		data["isMinProperties"] = false
+		if val := RunTemplate(propertiesValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
 	if required := validation.Required; len(required) > 0 {
 		var val string
 		for i, r := range required {
@@ -424,9 +550,116 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 		}
 		res = append(res, val)
 	}
+	if validation.HasCrossFieldRules() && att.Type.IsObject() {
+		res = append(res, crossFieldValidationsCode(att, data)...)
+	}
+	return
+}
+
+// crossFieldValidationsCode produces the code that runs the validations involving multiple fields
+// of the object attribute att: OneOfRequired, MutuallyExclusive, DependentRequired and the
+// validation functions.
+func crossFieldValidationsCode(att *design.AttributeDefinition, data map[string]interface{}) (res []string) {
+	var (
+		validation = att.Validation
+		target     = data["target"].(string)
+		private    = data["private"].(bool)
+	)
+	isSet := func(names []string) []string {
+		set := make([]string, len(names))
+		for i, n := range names {
+			set[i] = "true"
+			if check, _ := presenceCheck(att, n, target, private); check != "" {
+				set[i] = check
+			}
+		}
+		return set
+	}
+	for _, names := range validation.OneOfRequired {
+		data["names"] = names
+		data["set"] = isSet(names)
+		res = append(res, RunTemplate(oneOfReqValT, data))
+	}
+	for _, names := range validation.MutuallyExclusive {
+		data["names"] = names
+		data["set"] = isSet(names)
+		res = append(res, RunTemplate(exclusiveValT, data))
+	}
+	keys := make([]string, 0, len(validation.DependentRequired))
+	for n := range validation.DependentRequired {
+		keys = append(keys, n)
+	}
+	sort.Strings(keys)
+	for _, n := range keys {
+		var missing []map[string]string
+		for _, dep := range validation.DependentRequired[n] {
+			if _, check := presenceCheck(att, dep, target, private); check != "" {
+				missing = append(missing, map[string]string{"name": dep, "check": check})
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		data["dependency"] = n
+		data["check"], _ = presenceCheck(att, n, target, private)
+		data["missing"] = missing
+		res = append(res, RunTemplate(dependentValT, data))
+	}
+	if private {
+		// Validation functions are given the values of the public data structures.
+		return
+	}
+	for _, f := range validation.Funcs {
+		args := make([]string, len(f.Attributes))
+		for i, n := range f.Attributes {
+			args[i] = fmt.Sprintf("%s.%s", target, GoifyAtt(att.Type.ToObject()[n], n, true))
+		}
+		data["func"] = fmt.Sprintf("%s.%s", PackageAlias(f.PackagePath), f.Name)
+		data["funcName"] = f.Name
+		data["names"] = f.Attributes
+		data["args"] = args
+		res = append(res, RunTemplate(validateFuncT, data))
+	}
 	return
 }
 
+// HasValidationFuncs returns true if the validation of the given attribute or of any attribute
+// it contains calls a validation function defined with ValidateFunc.
+func HasValidationFuncs(att *design.AttributeDefinition) bool {
+	found := errors.New("found")
+	check := func(a *design.AttributeDefinition) error {
+		if a.Validation != nil && len(a.Validation.Funcs) > 0 {
+			return found
+		}
+		return nil
+	}
+	if check(att) != nil {
+		return true
+	}
+	if ds, ok := att.Type.(design.DataStructure); ok {
+		return ds.Walk(check) != nil
+	}
+	return false
+}
+
+// presenceCheck returns the Go expressions that test whether the field n of the object att held in
+// target is set and whether it is not set. It returns empty strings if the field is always set.
+func presenceCheck(att *design.AttributeDefinition, n, target string, private bool) (set, unset string) {
+	catt := att.Type.ToObject()[n]
+	field := fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true))
+	if catt.Nullable {
+		return field + ".Set", "!" + field + ".Set"
+	}
+	if private || !catt.Type.IsPrimitive() ||
+		!(att.IsRequired(n) || att.HasDefaultValue(n) || att.IsNonZero(n)) {
+		return field + " != nil", field + " == nil"
+	}
+	if catt.Type.Kind() == design.StringKind {
+		return field + ` != ""`, field + ` == ""`
+	}
+	return "", ""
+}
+
 // renderInteger renders a max or min value properly, taking into account
 // overflows due to casting from a float value.
 func renderInteger(f float64) string {
@@ -474,6 +707,8 @@ func constant(formatName string) string {
 		return "goa.FormatRegexp"
 	case "rfc1123":
 		return "goa.FormatRFC1123"
+	case "decimal":
+		return "goa.FormatDecimal"
 	}
 	panic("unknown format") // bug
 }
@@ -502,37 +737,78 @@ const (
 
 	patternValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
-{{ end }}{{ tabs $depth }}if ok := goa.ValidatePattern(` + "`{{ .pattern }}`" + `, {{ .targetVal }}); !ok {
-{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, ` + "`{{ .pattern }}`" + `))
+{{ end }}{{ tabs $depth }}if ok := goa.ValidatePattern(` + "`{{ .pattern }}`" + `, {{ .stringTargetVal }}); !ok {
+{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `{{ .context }}` + "`" + `, {{ .stringTargetVal }}, ` + "`{{ .pattern }}`" + `))
 {{ tabs $depth }}}{{ if .isPointer }}
 {{ tabs .depth }}}{{ end }}`
 
 	formatValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
-{{ end }}{{ tabs $depth }}if err2 := goa.ValidateFormat({{ constant .format }}, {{ .targetVal }}); err2 != nil {
+{{ end }}{{ tabs $depth }}if err2 := goa.ValidateFormat({{ constant .format }}, {{ .stringTargetVal }}); err2 != nil {
 {{ tabs $depth }}		err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ constant .format }}, err2))
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
 	minMaxValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
+*/}}{{ $op := or (and .isMin (or (and .exclusive "<=") "<")) (or (and .exclusive ">=") ">") }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
-{{ end }}{{ tabs .depth }}	if {{ .targetVal }} {{ if .isMin }}<{{ else }}>{{ end }} {{ if .isMin }}{{ .min }}{{ else }}{{ .max }}{{ end }} {
-{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ if .isMin }}{{ .min }}, true{{ else }}{{ .max }}, false{{ end }}))
+{{ end }}{{ tabs .depth }}	if {{ if .decimal }}goa.CompareDecimal({{ .targetVal }}, {{ if .isMin }}{{ .min }}{{ else }}{{ .max }}{{ end }}) {{ $op }} 0{{ else }}{{ .targetVal }} {{ $op }} {{ if .isMin }}{{ .min }}{{ else }}{{ .max }}{{ end }}{{ end }} {
+{{ tabs $depth }}	err = goa.MergeErrors(err, goa.{{ if .exclusive }}InvalidExclusiveRangeError{{ else }}InvalidRangeError{{ end }}(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ if .isMin }}{{ .min }}, true{{ else }}{{ .max }}, false{{ end }}))
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
+	multipleOfValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
+{{ end }}{{ tabs $depth }}if {{ if .decimal }}!goa.ValidateDecimalMultipleOf({{ .targetVal }}, {{ .multipleOf }}){{/*
+*/}}{{ else if .integer }}{{ .targetVal }}%{{ .multipleOf }} != 0{{/*
+*/}}{{ else if .float32 }}!goa.ValidateMultipleOf(float64({{ .targetVal }}), {{ .multipleOf }}){{/*
+*/}}{{ else }}!goa.ValidateMultipleOf({{ .targetVal }}, {{ .multipleOf }}){{ end }} {
+{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidMultipleOfError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ .multipleOf }}))
+{{ tabs $depth }}}{{ if .isPointer }}
+{{ tabs .depth }}}{{ end }}`
+
 	lengthValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
-*/}}{{ $target := or (and (or (or .array .hash) .nonzero) .target) .targetVal }}{{/*
+*/}}{{ $target := or (and (or (or .array .hash) .nonzero) .stringTarget) .stringTargetVal }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
 {{ end }}{{ tabs .depth }}	if {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }} {{ if .isMinLength }}<{{ else }}>{{ end }} {{ if .isMinLength }}{{ .minLength }}{{ else }}{{ .maxLength }}{{ end }} {
 {{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `{{ .context }}` + "`" + `, {{ $target }}, {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }}, {{ if .isMinLength }}{{ .minLength }}, true{{ else }}{{ .maxLength }}, false{{ end }}))
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
+	uniqueItemsValTmpl = `{{ tabs .depth }}if dup, ok := goa.ValidateUniqueItems({{ .target }}); !ok {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.InvalidUniqueItemsError(` + "`" + `{{ .context }}` + "`" + `, {{ .target }}, dup))
+{{ tabs .depth }}}`
+
+	propertiesValTmpl = `{{ tabs .depth }}if len({{ .target }}) {{ if .isMinProperties }}<{{ else }}>{{ end }} {{ .properties }} {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.InvalidPropertiesCountError(` + "`" + `{{ .context }}` + "`" + `, {{ .target }}, len({{ .target }}), {{ .properties }}, {{ .isMinProperties }}))
+{{ tabs .depth }}}`
+
 	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{/*
-*/}}{{ if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
+*/}}{{ if $att.Nullable }}{{ tabs $.depth }}if !{{ $.target }}.{{ goifyAtt $att .required true }}.Set {
+{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"))
+{{ tabs $.depth }}}{{ else if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"))
 {{ tabs $.depth }}}{{ else if or $.private (not $att.Type.IsPrimitive) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == nil {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"))
 {{ tabs $.depth }}}{{ end }}`
+
+	oneOfRequiredValTmpl = `{{ tabs .depth }}if set := goa.SetAttributes({{ printf "%#v" .names }}, {{ join .set ", " }}); len(set) != 1 {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.InvalidOneOfRequiredError(` + "`" + `{{ .context }}` + "`" + `, {{ printf "%#v" .names }}, set))
+{{ tabs .depth }}}`
+
+	mutuallyExclusiveValTmpl = `{{ tabs .depth }}if set := goa.SetAttributes({{ printf "%#v" .names }}, {{ join .set ", " }}); len(set) > 1 {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.MutuallyExclusiveAttributesError(` + "`" + `{{ .context }}` + "`" + `, set))
+{{ tabs .depth }}}`
+
+	dependentRequiredValTmpl = `{{ $depth := or (and .check (add .depth 1)) .depth }}{{/*
+*/}}{{ if .check }}{{ tabs .depth }}if {{ .check }} {
+{{ end }}{{ range $i, $m := .missing }}{{ if $i }}
+{{ end }}{{ tabs $depth }}if {{ $m.check }} {
+{{ tabs $depth }}	err = goa.MergeErrors(err, goa.MissingDependentAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ $m.name }}", "{{ $.dependency }}"))
+{{ tabs $depth }}}{{ end }}{{ if .check }}
+{{ tabs .depth }}}{{ end }}`
+
+	validateFuncTmpl = `{{ tabs .depth }}if err2 := {{ .func }}({{ join .args ", " }}); err2 != nil {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.InvalidCustomValidationError(` + "`" + `{{ .context }}` + "`" + `, "{{ .funcName }}", {{ printf "%#v" .names }}, err2))
+{{ tabs .depth }}}`
 )
@@ -46,6 +46,47 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of enum user type", func() {
+				BeforeEach(func() {
+					attType = &design.UserTypeDefinition{
+						TypeName: "Rating",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Integer,
+							Validation: &dslengine.ValidationDefinition{
+								Values: []interface{}{1, 2, 3},
+							},
+						},
+					}
+					validation = nil
+				})
+
+				It("produces the enum validation go code", func() {
+					Ω(code).Should(Equal(enumValCode))
+				})
+			})
+
+			Context("of enum user type with other validations", func() {
+				BeforeEach(func() {
+					min := 4
+					attType = &design.UserTypeDefinition{
+						TypeName: "Status",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.String,
+							Validation: &dslengine.ValidationDefinition{
+								Values:    []interface{}{"open", "sealed"},
+								Pattern:   "^[a-z]+$",
+								MinLength: &min,
+							},
+						},
+					}
+					validation = nil
+				})
+
+				It("produces the validation go code of all the enum type validations", func() {
+					Ω(code).Should(Equal(enumOtherValCode))
+				})
+			})
+
 			Context("of pattern", func() {
 				BeforeEach(func() {
 					attType = design.String
@@ -101,6 +142,126 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of integer format int64", func() {
+				BeforeEach(func() {
+					attType = design.Integer
+					validation = &dslengine.ValidationDefinition{
+						Format: "int64",
+					}
+				})
+
+				It("does not validate the format", func() {
+					Ω(code).Should(BeEmpty())
+				})
+			})
+
+			Context("of min value 0.5 with the decimal format", func() {
+				BeforeEach(func() {
+					attType = design.Number
+					min := 0.5
+					validation = &dslengine.ValidationDefinition{
+						Format:  "decimal",
+						Minimum: &min,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(decimalMinValCode))
+				})
+			})
+
+			Context("of exclusive min value 0 and multiple of 5", func() {
+				BeforeEach(func() {
+					attType = design.Integer
+					min, multiple := 0.0, 5.0
+					validation = &dslengine.ValidationDefinition{
+						Minimum:          &min,
+						ExclusiveMinimum: true,
+						MultipleOf:       &multiple,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(exclusiveMinMultipleOfValCode))
+				})
+			})
+
+			Context("of array unique items", func() {
+				BeforeEach(func() {
+					attType = &design.Array{
+						ElemType: &design.AttributeDefinition{
+							Type: design.String,
+						},
+					}
+					validation = &dslengine.ValidationDefinition{
+						UniqueItems: true,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(arrayUniqueItemsValCode))
+				})
+			})
+
+			Context("of hash max properties 2", func() {
+				BeforeEach(func() {
+					attType = &design.Hash{
+						KeyType:  &design.AttributeDefinition{Type: design.String},
+						ElemType: &design.AttributeDefinition{Type: design.String},
+					}
+					max := 2
+					validation = &dslengine.ValidationDefinition{
+						MaxProperties: &max,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(hashMaxPropertiesValCode))
+				})
+			})
+
+			Context("of object cross-field rules", func() {
+				BeforeEach(func() {
+					attType = design.Object{
+						"email":       &design.AttributeDefinition{Type: design.String},
+						"phone":       &design.AttributeDefinition{Type: design.String},
+						"country":     &design.AttributeDefinition{Type: design.String},
+						"postal_code": &design.AttributeDefinition{Type: design.String},
+					}
+					validation = &dslengine.ValidationDefinition{
+						OneOfRequired:     [][]string{{"email", "phone"}},
+						DependentRequired: map[string][]string{"postal_code": {"country"}},
+						Funcs: []*dslengine.ValidationFuncDefinition{{
+							PackagePath: "example.com/validators",
+							Name:        "ValidateContact",
+							Attributes:  []string{"email", "country"},
+						}},
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(crossFieldValCode))
+				})
+			})
+
+			Context("of object nullable attributes", func() {
+				BeforeEach(func() {
+					min := 2
+					attType = design.Object{
+						"nick": &design.AttributeDefinition{
+							Type:       design.String,
+							Nullable:   true,
+							Validation: &dslengine.ValidationDefinition{MinLength: &min},
+						},
+					}
+					validation = &dslengine.ValidationDefinition{Required: []string{"nick"}}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(nullableValCode))
+				})
+			})
+
 			Context("of array min length 1", func() {
 				BeforeEach(func() {
 					attType = &design.Array{
@@ -360,6 +521,22 @@ const (
 		}
 	}`
 
+	enumOtherValCode = `	if val != nil {
+		if !(*val == "open" || *val == "sealed") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`context`" + `, *val, []interface{}{"open", "sealed"}))
+		}
+	}
+	if val != nil {
+		if ok := goa.ValidatePattern(` + "`^[a-z]+$`" + `, string(*val)); !ok {
+			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`context`" + `, string(*val), ` + "`^[a-z]+$`" + `))
+		}
+	}
+	if val != nil {
+		if utf8.RuneCountInString(string(*val)) < 4 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`context`" + `, string(*val), utf8.RuneCountInString(string(*val)), 4, true))
+		}
+	}`
+
 	patternValCode = `	if val != nil {
 		if ok := goa.ValidatePattern(` + "`.*`" + `, *val); !ok {
 			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`context`" + `, *val, ` + "`.*`" + `))
@@ -384,6 +561,57 @@ const (
 		}
 	}`
 
+	decimalMinValCode = `	if val != nil {
+		if err2 := goa.ValidateFormat(goa.FormatDecimal, *val); err2 != nil {
+				err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`context`" + `, *val, goa.FormatDecimal, err2))
+		}
+	}
+	if val != nil {
+		if goa.CompareDecimal(*val, 0.500000) < 0 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `context` + "`" + `, *val, 0.500000, true))
+		}
+	}`
+
+	exclusiveMinMultipleOfValCode = `	if val != nil {
+		if *val <= 0 {
+			err = goa.MergeErrors(err, goa.InvalidExclusiveRangeError(` + "`" + `context` + "`" + `, *val, 0, true))
+		}
+	}
+	if val != nil {
+		if *val%5 != 0 {
+			err = goa.MergeErrors(err, goa.InvalidMultipleOfError(` + "`" + `context` + "`" + `, *val, 5))
+		}
+	}`
+
+	arrayUniqueItemsValCode = `	if dup, ok := goa.ValidateUniqueItems(val); !ok {
+		err = goa.MergeErrors(err, goa.InvalidUniqueItemsError(` + "`" + `context` + "`" + `, val, dup))
+	}`
+
+	hashMaxPropertiesValCode = `	if len(val) > 2 {
+		err = goa.MergeErrors(err, goa.InvalidPropertiesCountError(` + "`" + `context` + "`" + `, val, len(val), 2, false))
+	}`
+
+	crossFieldValCode = `	if set := goa.SetAttributes([]string{"email", "phone"}, val.Email != nil, val.Phone != nil); len(set) != 1 {
+		err = goa.MergeErrors(err, goa.InvalidOneOfRequiredError(` + "`" + `context` + "`" + `, []string{"email", "phone"}, set))
+	}
+	if val.PostalCode != nil {
+		if val.Country == nil {
+			err = goa.MergeErrors(err, goa.MissingDependentAttributeError(` + "`" + `context` + "`" + `, "country", "postal_code"))
+		}
+	}
+	if err2 := validators.ValidateContact(val.Email, val.Country); err2 != nil {
+		err = goa.MergeErrors(err, goa.InvalidCustomValidationError(` + "`" + `context` + "`" + `, "ValidateContact", []string{"email", "country"}, err2))
+	}`
+
+	nullableValCode = `	if !val.Nick.Set {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `context` + "`" + `, "nick"))
+	}
+	if val.Nick.Set && !val.Nick.Null {
+		if utf8.RuneCountInString(val.Nick.Value) < 2 {
+		err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `context.nick` + "`" + `, val.Nick.Value, utf8.RuneCountInString(val.Nick.Value), 2, true))
+	}
+	}`
+
 	arrayMinLengthValCode = `	if val != nil {
 		if len(val) < 1 {
 			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `context` + "`" + `, val, len(val), 1, true))
@@ -52,7 +52,7 @@ type (
 
 var (
 	// Template used to render Go source file headers.
-	headerTmpl = template.Must(template.New("header").Funcs(DefaultFuncMap).Parse(headerT))
+	headerTmpl = template.Must(template.New("header").Funcs(DefaultFuncMap).Parse(HeaderT))
 
 	// DefaultFuncMap is the FuncMap used to initialize all source file templates.
 	DefaultFuncMap = template.FuncMap{
@@ -62,11 +62,13 @@ var (
 		"goify":               Goify,
 		"goifyatt":            GoifyAtt,
 		"gonative":            GoNativeType,
+		"gonativeatt":         GoNativeTypeAtt,
 		"gotypedef":           GoTypeDef,
 		"gotypename":          GoTypeName,
 		"gotypedesc":          GoTypeDesc,
 		"gotyperef":           GoTypeRef,
 		"join":                strings.Join,
+		"nullableMarshaler":   NullableMarshaler,
 		"recursivePublicizer": RecursivePublicizer,
 		"tabs":                Tabs,
 		"tempvar":             Tempvar,
@@ -369,7 +371,9 @@ func PackageName(path string) (string, error) {
 }
 
 const (
-	headerT = `{{if .Title}}// Code generated by goagen {{.ToolVersion}}, DO NOT EDIT.
+	// HeaderT is the template used to render the header of generated Go source files: the
+	// "Code generated" comment if Title is not empty, the package clause and the imports.
+	HeaderT = `{{if .Title}}// Code generated by goagen {{.ToolVersion}}, DO NOT EDIT.
 //
 // {{.Title}}
 //
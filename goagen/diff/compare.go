@@ -0,0 +1,607 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	// Change describes a difference between two versions of an API design.
+	Change struct {
+		// Path locates the changed element, e.g. `resources.bottle.actions.show.params.id`.
+		Path string `json:"path"`
+		// Kind is the kind of change.
+		Kind ChangeKind `json:"kind"`
+		// Breaking is true if clients of the old version may fail with the new version.
+		Breaking bool `json:"breaking"`
+		// Message describes the change.
+		Message string `json:"message"`
+	}
+
+	// ChangeKind enumerates the kinds of changes.
+	ChangeKind string
+
+	// direction records whether a type is sent by clients, returned by the API or both. It
+	// determines whether narrowing or widening a type breaks clients.
+	direction int
+
+	// comparer compares two API snapshots.
+	comparer struct {
+		old, new *API
+		changes  []*Change
+		// dirs indexes the directions of the user types and media types by name.
+		dirs map[string]direction
+	}
+)
+
+const (
+	// Added is the kind of changes that add an element.
+	Added ChangeKind = "added"
+	// Removed is the kind of changes that remove an element.
+	Removed ChangeKind = "removed"
+	// Changed is the kind of changes that modify an element.
+	Changed ChangeKind = "changed"
+)
+
+const (
+	// input is the direction of the types sent by the clients: params, headers and payloads.
+	input direction = 1 << iota
+	// output is the direction of the types returned by the API: response media types.
+	output
+)
+
+// identifierRegex matches the names that do not need to be quoted in paths.
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Compare returns the changes between the old and new snapshots sorted by path. Changes to
+// resources and actions are breaking if they remove or modify routes and responses, changes to
+// types are breaking if they narrow the values accepted by the API or widen the values returned
+// to the clients.
+func Compare(old, new *API) []*Change {
+	c := &comparer{old: old, new: new, dirs: make(map[string]direction)}
+	c.directions(old)
+	c.directions(new)
+	c.compareResources()
+	c.compareTypes()
+	c.compareMediaTypes()
+	sort.SliceStable(c.changes, func(i, j int) bool { return c.changes[i].Path < c.changes[j].Path })
+	return c.changes
+}
+
+// Breaking returns the breaking changes.
+func Breaking(changes []*Change) []*Change {
+	var res []*Change
+	for _, ch := range changes {
+		if ch.Breaking {
+			res = append(res, ch)
+		}
+	}
+	return res
+}
+
+// add records a change.
+func (c *comparer) add(path string, kind ChangeKind, breaking bool, format string, args ...interface{}) {
+	c.changes = append(c.changes, &Change{Path: path, Kind: kind, Breaking: breaking, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *comparer) compareResources() {
+	for _, n := range keys(c.old.Resources, c.new.Resources) {
+		path := join("resources", n)
+		o, nw := c.old.Resources[n], c.new.Resources[n]
+		switch {
+		case nw == nil:
+			c.add(path, Removed, true, "resource removed")
+		case o == nil:
+			c.add(path, Added, false, "resource added")
+		default:
+			for _, a := range keys(o.Actions, nw.Actions) {
+				c.compareAction(join(path, "actions", a), o.Actions[a], nw.Actions[a])
+			}
+		}
+	}
+}
+
+func (c *comparer) compareAction(path string, o, nw *Action) {
+	switch {
+	case nw == nil:
+		c.add(path, Removed, true, "action removed")
+		return
+	case o == nil:
+		c.add(path, Added, false, "action added")
+		return
+	}
+
+	removed, added := difference(o.Routes, nw.Routes), difference(nw.Routes, o.Routes)
+	if len(removed) == 1 && len(added) == 1 {
+		c.add(join(path, "routes"), Changed, true, "route changed from %s to %s", removed[0], added[0])
+	} else {
+		for _, r := range removed {
+			c.add(join(path, "routes"), Removed, true, "route %s removed", r)
+		}
+		for _, r := range added {
+			c.add(join(path, "routes"), Added, false, "route %s added", r)
+		}
+	}
+
+	c.compareObject(join(path, "params"), o.Params, nw.Params, input)
+	c.compareObject(join(path, "headers"), o.Headers, nw.Headers, input)
+	c.compareObject(join(path, "cookies"), o.Cookies, nw.Cookies, input)
+
+	ppath := join(path, "payload")
+	switch {
+	case o.Payload == nil && nw.Payload != nil:
+		if nw.PayloadOptional {
+			c.add(ppath, Added, false, "optional payload added")
+		} else {
+			c.add(ppath, Added, true, "required payload added")
+		}
+	case o.Payload != nil && nw.Payload == nil:
+		c.add(ppath, Removed, false, "payload removed")
+	case o.Patch != nw.Patch:
+		c.add(ppath, Changed, true, "payload changed from %s to %s", payloadKind(o.Patch), payloadKind(nw.Patch))
+	case o.Payload != nil:
+		if o.PayloadOptional && !nw.PayloadOptional {
+			c.add(ppath, Changed, true, "payload is now required")
+		} else if !o.PayloadOptional && nw.PayloadOptional {
+			c.add(ppath, Changed, false, "payload is now optional")
+		}
+		c.compareAttribute(ppath, o.Payload, nw.Payload, input)
+	}
+
+	pgpath := join(path, "pagination")
+	switch {
+	case o.Pagination == nw.Pagination:
+	case o.Pagination == "":
+		// Clients that expect all the results only get the first page.
+		c.add(pgpath, Added, true, "%s added", paginationKind(nw.Pagination))
+	case nw.Pagination == "":
+		c.add(pgpath, Removed, strings.HasSuffix(o.Pagination, "+envelope"), "%s removed", paginationKind(o.Pagination))
+	default:
+		c.add(pgpath, Changed, true, "pagination changed from %s to %s", paginationKind(o.Pagination), paginationKind(nw.Pagination))
+	}
+
+	if !o.Deprecated && nw.Deprecated {
+		c.add(path, Changed, false, "action deprecated")
+	} else if o.Deprecated && !nw.Deprecated {
+		c.add(path, Changed, false, "action no longer deprecated")
+	}
+
+	for _, n := range keys(o.Responses, nw.Responses) {
+		rpath := join(path, "responses", n)
+		or, nr := o.Responses[n], nw.Responses[n]
+		switch {
+		case nr == nil:
+			c.add(rpath, Removed, true, "response removed")
+		case or == nil:
+			c.add(rpath, Added, false, "response added")
+		default:
+			if or.Status != nr.Status {
+				c.add(rpath, Changed, true, "status changed from %d to %d", or.Status, nr.Status)
+			}
+			if or.MediaType != nr.MediaType {
+				c.add(rpath, Changed, true, "media type changed from %s to %s", quote(or.MediaType), quote(nr.MediaType))
+			} else if or.View != nr.View {
+				c.add(rpath, Changed, true, "view changed from %s to %s", quote(or.View), quote(nr.View))
+			}
+		}
+	}
+
+	spath := join(path, "security")
+	switch {
+	case len(o.Security) == 0 && len(nw.Security) > 0:
+		c.add(spath, Added, true, "security requirement %s added", strings.Join(nw.Security, " or "))
+	case len(o.Security) > 0 && len(nw.Security) == 0:
+		c.add(spath, Removed, false, "security requirement removed")
+	default:
+		for _, s := range difference(o.Security, nw.Security) {
+			c.add(spath, Removed, true, "security alternative %s removed", s)
+		}
+		for _, s := range difference(nw.Security, o.Security) {
+			c.add(spath, Added, false, "security alternative %s added", s)
+		}
+	}
+}
+
+func (c *comparer) compareTypes() {
+	for _, n := range keys(c.old.Types, c.new.Types) {
+		path := join("types", n)
+		o, nw := c.old.Types[n], c.new.Types[n]
+		switch {
+		case nw == nil:
+			// Removing a type used by an action is reported by the action.
+			c.add(path, Removed, false, "type removed")
+		case o == nil:
+			c.add(path, Added, false, "type added")
+		default:
+			c.compareAttribute(path, o, nw, c.dirs[n])
+		}
+	}
+}
+
+func (c *comparer) compareMediaTypes() {
+	for _, id := range keys(c.old.MediaTypes, c.new.MediaTypes) {
+		path := join("media_types", id)
+		o, nw := c.old.MediaTypes[id], c.new.MediaTypes[id]
+		switch {
+		case nw == nil:
+			c.add(path, Removed, false, "media type removed")
+			continue
+		case o == nil:
+			c.add(path, Added, false, "media type added")
+			continue
+		}
+		dir := c.dirs[id]
+		c.compareAttribute(path, o.Attribute, nw.Attribute, dir)
+		for _, v := range keys(o.Views, nw.Views) {
+			vpath := join(path, "views", v)
+			ov, oldOK := o.Views[v]
+			nv, newOK := nw.Views[v]
+			switch {
+			case !newOK:
+				c.add(vpath, Removed, dir&output != 0, "view removed")
+			case !oldOK:
+				c.add(vpath, Added, false, "view added")
+			default:
+				for _, a := range difference(ov, nv) {
+					c.add(vpath, Removed, dir&output != 0, "attribute %s removed from view", quote(a))
+				}
+				for _, a := range difference(nv, ov) {
+					c.add(vpath, Added, false, "attribute %s added to view", quote(a))
+				}
+			}
+		}
+	}
+}
+
+// compareObject compares optional object attributes.
+func (c *comparer) compareObject(path string, o, nw *Attribute, dir direction) {
+	if o == nil {
+		o = &Attribute{Type: "object"}
+	}
+	if nw == nil {
+		nw = &Attribute{Type: "object"}
+	}
+	c.compareAttribute(path, o, nw, dir)
+}
+
+// compareAttribute compares two versions of an attribute. Narrowing the values of an attribute
+// breaks the clients that send it while widening them breaks the clients that receive it.
+func (c *comparer) compareAttribute(path string, o, nw *Attribute, dir direction) {
+	breaks := func(narrowed bool) bool {
+		if narrowed {
+			return dir&input != 0
+		}
+		return dir&output != 0
+	}
+
+	if o.Type != nw.Type || o.Ref != nw.Ref {
+		c.add(path, Changed, true, "type changed from %s to %s", o.typeName(), nw.typeName())
+		return
+	}
+
+	// Enum
+	oldEnum, newEnum := literals(o.Enum), literals(nw.Enum)
+	switch {
+	case len(oldEnum) == 0 && len(newEnum) > 0:
+		c.add(path, Added, breaks(true), "enum validation added: %s", strings.Join(newEnum, ", "))
+	case len(oldEnum) > 0 && len(newEnum) == 0:
+		c.add(path, Removed, breaks(false), "enum validation removed")
+	default:
+		if removed := difference(oldEnum, newEnum); len(removed) > 0 {
+			c.add(path, Changed, breaks(true), "enum values removed: %s", strings.Join(removed, ", "))
+		}
+		if added := difference(newEnum, oldEnum); len(added) > 0 {
+			c.add(path, Changed, breaks(false), "enum values added: %s", strings.Join(added, ", "))
+		}
+	}
+
+	// Format and pattern
+	c.compareString(path, "format", o.Format, nw.Format, breaks)
+	c.compareString(path, "pattern", o.Pattern, nw.Pattern, breaks)
+
+	// Bounds
+	c.compareBound(path, "minimum", o.Minimum, nw.Minimum, true, breaks)
+	c.compareBound(path, "maximum", o.Maximum, nw.Maximum, false, breaks)
+	c.compareBound(path, "min length", float(o.MinLength), float(nw.MinLength), true, breaks)
+	c.compareBound(path, "max length", float(o.MaxLength), float(nw.MaxLength), false, breaks)
+	c.compareFlag(path, "exclusive minimum", o.ExclusiveMinimum, nw.ExclusiveMinimum, breaks)
+	c.compareFlag(path, "exclusive maximum", o.ExclusiveMaximum, nw.ExclusiveMaximum, breaks)
+	c.compareMultipleOf(path, o.MultipleOf, nw.MultipleOf, breaks)
+	c.compareFlag(path, "unique items", o.UniqueItems, nw.UniqueItems, breaks)
+	c.compareBound(path, "min properties", float(o.MinProperties), float(nw.MinProperties), true, breaks)
+	c.compareBound(path, "max properties", float(o.MaxProperties), float(nw.MaxProperties), false, breaks)
+
+	// Null values
+	switch {
+	case o.Nullable && !nw.Nullable:
+		c.add(path, Removed, breaks(true), "nullable removed")
+	case !o.Nullable && nw.Nullable:
+		c.add(path, Added, breaks(false), "nullable added")
+	}
+
+	// Cross-field validations
+	for _, r := range difference(nw.Rules, o.Rules) {
+		c.add(path, Added, breaks(true), "validation %s added", r)
+	}
+	for _, r := range difference(o.Rules, nw.Rules) {
+		c.add(path, Removed, breaks(false), "validation %s removed", r)
+	}
+
+	if od, nd := literal(o.Default), literal(nw.Default); od != nd {
+		c.add(path, Changed, false, "default value changed from %s to %s", od, nd)
+	}
+
+	if o.Key != nil && nw.Key != nil {
+		c.compareAttribute(path+"{key}", o.Key, nw.Key, dir)
+		c.compareAttribute(path+"{value}", o.Elem, nw.Elem, dir)
+	} else if o.Elem != nil && nw.Elem != nil {
+		c.compareAttribute(path+"[]", o.Elem, nw.Elem, dir)
+	}
+
+	// Object attributes
+	for _, n := range keys(o.Attributes, nw.Attributes) {
+		apath := join(path, n)
+		oa, na := o.Attributes[n], nw.Attributes[n]
+		oreq, nreq := contains(o.Required, n), contains(nw.Required, n)
+		switch {
+		case na == nil:
+			c.add(apath, Removed, breaks(false), "attribute removed")
+		case oa == nil:
+			if nreq {
+				c.add(apath, Added, breaks(true), "required attribute added")
+			} else {
+				c.add(apath, Added, false, "optional attribute added")
+			}
+		default:
+			if !oreq && nreq {
+				c.add(apath, Changed, breaks(true), "attribute is now required")
+			} else if oreq && !nreq {
+				c.add(apath, Changed, breaks(false), "attribute is now optional")
+			}
+			c.compareAttribute(apath, oa, na, dir)
+		}
+	}
+
+	// Union variants
+	if o.Discriminator != nw.Discriminator {
+		c.add(path, Changed, breaks(true) || breaks(false), "discriminator changed from %s to %s",
+			quote(o.Discriminator), quote(nw.Discriminator))
+	}
+	for _, n := range keys(o.Variants, nw.Variants) {
+		vpath := path + "<" + n + ">"
+		ov, nv := o.Variants[n], nw.Variants[n]
+		switch {
+		case nv == nil:
+			c.add(vpath, Removed, breaks(true), "variant removed")
+		case ov == nil:
+			c.add(vpath, Added, breaks(false), "variant added")
+		default:
+			c.compareAttribute(vpath, ov, nv, dir)
+		}
+	}
+}
+
+// compareString compares string validations such as format and pattern, adding or changing the
+// validation narrows the values, removing it widens them.
+func (c *comparer) compareString(path, name, o, nw string, breaks func(bool) bool) {
+	switch {
+	case o == nw:
+	case o == "":
+		c.add(path, Added, breaks(true), "%s validation %s added", name, quote(nw))
+	case nw == "":
+		c.add(path, Removed, breaks(false), "%s validation %s removed", name, quote(o))
+	default:
+		c.add(path, Changed, breaks(true) || breaks(false), "%s validation changed from %s to %s", name, quote(o), quote(nw))
+	}
+}
+
+// compareBound compares minimum or maximum validations. min is true if the bound is a lower
+// bound.
+func (c *comparer) compareBound(path, name string, o, nw *float64, min bool, breaks func(bool) bool) {
+	switch {
+	case o == nil && nw == nil:
+	case o == nil:
+		c.add(path, Added, breaks(true), "%s validation %v added", name, *nw)
+	case nw == nil:
+		c.add(path, Removed, breaks(false), "%s validation %v removed", name, *o)
+	case *o != *nw:
+		narrowed := *nw > *o
+		if !min {
+			narrowed = !narrowed
+		}
+		c.add(path, Changed, breaks(narrowed), "%s validation changed from %v to %v", name, *o, *nw)
+	}
+}
+
+// compareFlag compares boolean validations such as unique items, setting the flag narrows the
+// accepted values.
+func (c *comparer) compareFlag(path, name string, o, nw bool, breaks func(bool) bool) {
+	switch {
+	case o == nw:
+	case nw:
+		c.add(path, Added, breaks(true), "%s validation added", name)
+	default:
+		c.add(path, Removed, breaks(false), "%s validation removed", name)
+	}
+}
+
+// compareMultipleOf compares multiple of validations. The accepted values are widened if the new
+// value divides the old one and narrowed if it is a multiple of the old one.
+func (c *comparer) compareMultipleOf(path string, o, nw *float64, breaks func(bool) bool) {
+	switch {
+	case o == nil && nw == nil:
+	case o == nil:
+		c.add(path, Added, breaks(true), "multiple of validation %v added", *nw)
+	case nw == nil:
+		c.add(path, Removed, breaks(false), "multiple of validation %v removed", *o)
+	case *o != *nw:
+		widened, narrowed := isMultiple(*o, *nw), isMultiple(*nw, *o)
+		c.add(path, Changed, (!widened && breaks(true)) || (!narrowed && breaks(false)),
+			"multiple of validation changed from %v to %v", *o, *nw)
+	}
+}
+
+// isMultiple returns true if a is a multiple of b.
+func isMultiple(a, b float64) bool {
+	q := a / b
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
+// directions computes the directions of the user types and media types of the given API.
+func (c *comparer) directions(api *API) {
+	var mark func(a *Attribute, dir direction)
+	mark = func(a *Attribute, dir direction) {
+		if a == nil {
+			return
+		}
+		if a.Ref != "" {
+			if c.dirs[a.Ref]&dir == dir {
+				return
+			}
+			c.dirs[a.Ref] |= dir
+			if t, ok := api.Types[a.Ref]; ok {
+				mark(t, dir)
+			}
+			if mt, ok := api.MediaTypes[a.Ref]; ok {
+				mark(mt.Attribute, dir)
+			}
+		}
+		mark(a.Elem, dir)
+		mark(a.Key, dir)
+		for _, child := range a.Attributes {
+			mark(child, dir)
+		}
+		for _, variant := range a.Variants {
+			mark(variant, dir)
+		}
+	}
+	for _, r := range api.Resources {
+		for _, a := range r.Actions {
+			mark(a.Params, input)
+			mark(a.Headers, input)
+			mark(a.Cookies, input)
+			mark(a.Payload, input)
+			for _, resp := range a.Responses {
+				if resp.MediaType != "" {
+					mark(&Attribute{Ref: resp.MediaType}, output)
+				}
+			}
+		}
+	}
+}
+
+// typeName returns the name of the attribute type used in messages.
+func (a *Attribute) typeName() string {
+	if a.Ref != "" {
+		return a.Ref
+	}
+	return a.Type
+}
+
+// paginationKind describes a pagination given its snapshot in messages.
+func paginationKind(p string) string {
+	if strings.HasSuffix(p, "+envelope") {
+		return strings.TrimSuffix(p, "+envelope") + " pagination with envelope"
+	}
+	return p + " pagination"
+}
+
+// payloadKind describes a payload given its patch Content-Type in messages.
+func payloadKind(patch string) string {
+	if patch == "" {
+		return "plain payload"
+	}
+	return patch + " patch"
+}
+
+// join appends the given names to path, names that are not identifiers are quoted.
+func join(path string, names ...string) string {
+	for _, n := range names {
+		if identifierRegex.MatchString(n) {
+			if path != "" {
+				path += "."
+			}
+			path += n
+		} else {
+			path += "[" + quote(n) + "]"
+		}
+	}
+	return path
+}
+
+// keys returns the union of the keys of the given maps sorted alphabetically.
+func keys(maps ...interface{}) []string {
+	set := make(map[string]bool)
+	for _, m := range maps {
+		for _, k := range reflect.ValueOf(m).MapKeys() {
+			set[k.String()] = true
+		}
+	}
+	res := make([]string, 0, len(set))
+	for k := range set {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// difference returns the elements of a that are not in b.
+func difference(a, b []string) []string {
+	var res []string
+	for _, e := range a {
+		if !contains(b, e) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// contains returns true if elems contains e.
+func contains(elems []string, e string) bool {
+	for _, el := range elems {
+		if el == e {
+			return true
+		}
+	}
+	return false
+}
+
+// literals returns the JSON representations of the given values.
+func literals(vals []interface{}) []string {
+	res := make([]string, len(vals))
+	for i, v := range vals {
+		res[i] = literal(v)
+	}
+	return res
+}
+
+// literal returns the JSON representation of the given value.
+func literal(v interface{}) string {
+	if v == nil {
+		return "none"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// quote returns the given string quoted.
+func quote(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+// float converts the given length validation to a float.
+func float(i *int) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+	return &f
+}
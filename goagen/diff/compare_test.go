@@ -0,0 +1,390 @@
+package diff_test
+
+import (
+	"github.com/goadesign/goa/goagen/diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compare", func() {
+	var old, nw *diff.API
+	var changes []*diff.Change
+
+	// newAPI returns a snapshot with a "bottle" resource whose "create" action accepts a
+	// BottlePayload and whose "show" action returns a bottle media type.
+	newAPI := func() *diff.API {
+		min := 1900.0
+		return &diff.API{
+			Name: "test",
+			Resources: map[string]*diff.Resource{
+				"bottle": {Actions: map[string]*diff.Action{
+					"show": {
+						Routes: []string{"GET /bottles/:id"},
+						Params: &diff.Attribute{Type: "object", Attributes: map[string]*diff.Attribute{
+							"id": {Type: "integer"},
+						}},
+						Responses: map[string]*diff.Response{
+							"OK": {Status: 200, MediaType: "application/vnd.bottle"},
+						},
+					},
+					"create": {
+						Routes:    []string{"POST /bottles"},
+						Payload:   &diff.Attribute{Type: "object", Ref: "BottlePayload"},
+						Responses: map[string]*diff.Response{"Created": {Status: 201}},
+					},
+				}},
+			},
+			Types: map[string]*diff.Attribute{
+				"BottlePayload": {Type: "object", Required: []string{"name"}, Attributes: map[string]*diff.Attribute{
+					"name":    {Type: "string"},
+					"vintage": {Type: "integer", Minimum: &min},
+				}},
+			},
+			MediaTypes: map[string]*diff.MediaType{
+				"application/vnd.bottle": {
+					Attribute: &diff.Attribute{Type: "object", Attributes: map[string]*diff.Attribute{
+						"id":    {Type: "integer"},
+						"color": {Type: "string", Enum: []interface{}{"red", "white"}},
+					}},
+					Views: map[string][]string{"default": {"color", "id"}},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		old, nw = newAPI(), newAPI()
+	})
+
+	JustBeforeEach(func() {
+		changes = diff.Compare(old, nw)
+	})
+
+	Context("with identical snapshots", func() {
+		It("reports no change", func() {
+			Ω(changes).Should(BeEmpty())
+		})
+	})
+
+	Context("with a removed action", func() {
+		BeforeEach(func() {
+			delete(nw.Resources["bottle"].Actions, "show")
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0]).Should(Equal(&diff.Change{
+				Path:     "resources.bottle.actions.show",
+				Kind:     diff.Removed,
+				Breaking: true,
+				Message:  "action removed",
+			}))
+		})
+	})
+
+	Context("with a deprecated action", func() {
+		BeforeEach(func() {
+			nw.Resources["bottle"].Actions["show"].Deprecated = true
+		})
+
+		It("reports a non-breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0]).Should(Equal(&diff.Change{
+				Path:    "resources.bottle.actions.show",
+				Kind:    diff.Changed,
+				Message: "action deprecated",
+			}))
+		})
+	})
+
+	Context("with an added resource", func() {
+		BeforeEach(func() {
+			nw.Resources["account"] = &diff.Resource{}
+		})
+
+		It("reports a non-breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal("resources.account"))
+			Ω(changes[0].Kind).Should(Equal(diff.Added))
+			Ω(changes[0].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a changed route", func() {
+		BeforeEach(func() {
+			nw.Resources["bottle"].Actions["show"].Routes = []string{"GET /wines/:id"}
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal("resources.bottle.actions.show.routes"))
+			Ω(changes[0].Message).Should(Equal("route changed from GET /bottles/:id to GET /wines/:id"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a new required payload attribute", func() {
+		BeforeEach(func() {
+			t := nw.Types["BottlePayload"]
+			t.Attributes["color"] = &diff.Attribute{Type: "string"}
+			t.Required = []string{"color", "name"}
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal("types.BottlePayload.color"))
+			Ω(changes[0].Message).Should(Equal("required attribute added"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a new optional payload attribute", func() {
+		BeforeEach(func() {
+			nw.Types["BottlePayload"].Attributes["color"] = &diff.Attribute{Type: "string"}
+		})
+
+		It("reports a non-breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a stricter payload validation", func() {
+		BeforeEach(func() {
+			min := 1950.0
+			nw.Types["BottlePayload"].Attributes["vintage"].Minimum = &min
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal("types.BottlePayload.vintage"))
+			Ω(changes[0].Message).Should(Equal("minimum validation changed from 1900 to 1950"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a looser payload validation", func() {
+		BeforeEach(func() {
+			nw.Types["BottlePayload"].Attributes["vintage"].Minimum = nil
+		})
+
+		It("reports a non-breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(diff.Removed))
+			Ω(changes[0].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with an exclusive payload minimum", func() {
+		BeforeEach(func() {
+			nw.Types["BottlePayload"].Attributes["vintage"].ExclusiveMinimum = true
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Message).Should(Equal("exclusive minimum validation added"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a payload multiple of validation that divides the old one", func() {
+		BeforeEach(func() {
+			o, n := 10.0, 5.0
+			old.Types["BottlePayload"].Attributes["vintage"].MultipleOf = &o
+			nw.Types["BottlePayload"].Attributes["vintage"].MultipleOf = &n
+		})
+
+		It("reports a non-breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Message).Should(Equal("multiple of validation changed from 10 to 5"))
+			Ω(changes[0].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a cross-field payload validation", func() {
+		BeforeEach(func() {
+			nw.Types["BottlePayload"].Rules = []string{"vintage requires name"}
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(diff.Added))
+			Ω(changes[0].Message).Should(Equal("validation vintage requires name added"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a nullable payload attribute", func() {
+		BeforeEach(func() {
+			old.Types["BottlePayload"].Attributes["vintage"].Nullable = true
+		})
+
+		It("reports removing it as a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(diff.Removed))
+			Ω(changes[0].Message).Should(Equal("nullable removed"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a payload changed to a merge patch", func() {
+		BeforeEach(func() {
+			a := nw.Resources["bottle"].Actions["create"]
+			a.Payload = &diff.Attribute{Type: "hash"}
+			a.Patch = "application/merge-patch+json"
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal("resources.bottle.actions.create.payload"))
+			Ω(changes[0].Kind).Should(Equal(diff.Changed))
+			Ω(changes[0].Message).Should(Equal("payload changed from plain payload to application/merge-patch+json patch"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with pages wrapped in an envelope", func() {
+		BeforeEach(func() {
+			old.Resources["bottle"].Actions["show"].Pagination = "cursor"
+			nw.Resources["bottle"].Actions["show"].Pagination = "cursor+envelope"
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal("resources.bottle.actions.show.pagination"))
+			Ω(changes[0].Kind).Should(Equal(diff.Changed))
+			Ω(changes[0].Message).Should(Equal("pagination changed from cursor pagination to cursor pagination with envelope"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a removed link pagination", func() {
+		BeforeEach(func() {
+			old.Resources["bottle"].Actions["show"].Pagination = "offset"
+		})
+
+		It("reports a non breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(diff.Removed))
+			Ω(changes[0].Message).Should(Equal("offset pagination removed"))
+			Ω(changes[0].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a new response enum value", func() {
+		BeforeEach(func() {
+			color := nw.MediaTypes["application/vnd.bottle"].Attribute.Attributes["color"]
+			color.Enum = append(color.Enum, "rose")
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal(`media_types["application/vnd.bottle"].color`))
+			Ω(changes[0].Message).Should(Equal(`enum values added: "rose"`))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a removed response enum value", func() {
+		BeforeEach(func() {
+			nw.MediaTypes["application/vnd.bottle"].Attribute.Attributes["color"].Enum = []interface{}{"red"}
+		})
+
+		It("reports a non-breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a changed param type", func() {
+		BeforeEach(func() {
+			nw.Resources["bottle"].Actions["show"].Params.Attributes["id"].Type = "string"
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal("resources.bottle.actions.show.params.id"))
+			Ω(changes[0].Message).Should(Equal("type changed from integer to string"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a removed view attribute", func() {
+		BeforeEach(func() {
+			nw.MediaTypes["application/vnd.bottle"].Views["default"] = []string{"id"}
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Path).Should(Equal(`media_types["application/vnd.bottle"].views.default`))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a union payload", func() {
+		method := func() *diff.Attribute {
+			return &diff.Attribute{Type: "union", Discriminator: "kind", Variants: map[string]*diff.Attribute{
+				"card":   {Type: "object"},
+				"wallet": {Type: "object"},
+			}}
+		}
+
+		BeforeEach(func() {
+			old.Types["Method"], nw.Types["Method"] = method(), method()
+			old.Resources["bottle"].Actions["create"].Payload = &diff.Attribute{Type: "union", Ref: "Method"}
+			nw.Resources["bottle"].Actions["create"].Payload = &diff.Attribute{Type: "union", Ref: "Method"}
+		})
+
+		Context("with a removed variant", func() {
+			BeforeEach(func() {
+				delete(nw.Types["Method"].Variants, "wallet")
+			})
+
+			It("reports a breaking change", func() {
+				Ω(changes).Should(HaveLen(1))
+				Ω(changes[0].Path).Should(Equal("types.Method<wallet>"))
+				Ω(changes[0].Kind).Should(Equal(diff.Removed))
+				Ω(changes[0].Breaking).Should(BeTrue())
+			})
+		})
+
+		Context("with an added variant", func() {
+			BeforeEach(func() {
+				nw.Types["Method"].Variants["cash"] = &diff.Attribute{Type: "object"}
+			})
+
+			It("reports a non-breaking change", func() {
+				Ω(changes).Should(HaveLen(1))
+				Ω(changes[0].Path).Should(Equal("types.Method<cash>"))
+				Ω(changes[0].Kind).Should(Equal(diff.Added))
+				Ω(changes[0].Breaking).Should(BeFalse())
+			})
+		})
+
+		Context("with a changed discriminator", func() {
+			BeforeEach(func() {
+				nw.Types["Method"].Discriminator = "type"
+			})
+
+			It("reports a breaking change", func() {
+				Ω(changes).Should(HaveLen(1))
+				Ω(changes[0].Message).Should(Equal(`discriminator changed from "kind" to "type"`))
+				Ω(changes[0].Breaking).Should(BeTrue())
+			})
+		})
+	})
+
+	Context("with several changes", func() {
+		BeforeEach(func() {
+			delete(nw.Resources["bottle"].Actions, "show")
+			nw.Resources["account"] = &diff.Resource{}
+		})
+
+		It("sorts the changes by path", func() {
+			Ω(changes).Should(HaveLen(2))
+			Ω(changes[0].Path).Should(Equal("resources.account"))
+			Ω(changes[1].Path).Should(Equal("resources.bottle.actions.show"))
+			Ω(diff.Breaking(changes)).Should(Equal(changes[1:]))
+		})
+	})
+})
@@ -0,0 +1,20 @@
+/*
+Package diff implements the "goagen diff" command which reports the changes between two versions
+of a design package.
+
+Each version is evaluated by a meta generator which writes a JSON snapshot of the API contract:
+the resources, actions, routes, parameters, payloads, responses, user types, media types and
+validations. The versions are either directories containing the design package or git revisions
+of the repository that contains it, the working tree is used when no version is given.
+
+Compare walks the two snapshots and reports the elements that were added, removed or changed.
+A change is breaking when existing clients may fail against the new version: removing a resource,
+an action, a route or a response, changing a type, or narrowing the values accepted from the
+clients (new required attributes, new or stricter validations) as well as widening the values
+returned to them (new enum values, removed validations). Whether a type is sent by the clients,
+returned to them or both is computed from the actions that use it.
+
+The changes may be written as text, JSON or Markdown. The command exits with a non-zero status
+when some changes are breaking so that it may be used to gate merges.
+*/
+package diff
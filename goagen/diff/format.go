@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{"text", "json", "markdown"}
+
+// Write writes the given changes to w in the given format: "text", "json" or "markdown".
+func Write(w io.Writer, format string, changes []*Change) error {
+	switch format {
+	case "text":
+		return writeText(w, changes)
+	case "json":
+		return writeJSON(w, changes)
+	case "markdown":
+		return writeMarkdown(w, changes)
+	}
+	return fmt.Errorf("unknown format %q, supported formats are text, json and markdown", format)
+}
+
+// writeText writes one change per line followed by a summary.
+func writeText(w io.Writer, changes []*Change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "no change")
+		return err
+	}
+	for _, ch := range changes {
+		suffix := ""
+		if ch.Breaking {
+			suffix = " [breaking]"
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s%s\n", ch.Path, ch.Message, suffix); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d change(s), %d breaking\n", len(changes), len(Breaking(changes)))
+	return err
+}
+
+// writeJSON writes the changes and the number of breaking changes as a JSON object.
+func writeJSON(w io.Writer, changes []*Change) error {
+	if changes == nil {
+		changes = []*Change{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{
+		"changes":  changes,
+		"breaking": len(Breaking(changes)),
+	})
+}
+
+// writeMarkdown writes the breaking and non-breaking changes as two Markdown lists.
+func writeMarkdown(w io.Writer, changes []*Change) error {
+	if _, err := fmt.Fprintln(w, "## API changes"); err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "\nNo change.")
+		return err
+	}
+	var breaking, other []*Change
+	for _, ch := range changes {
+		if ch.Breaking {
+			breaking = append(breaking, ch)
+		} else {
+			other = append(other, ch)
+		}
+	}
+	for _, section := range []struct {
+		title   string
+		changes []*Change
+	}{{"Breaking changes", breaking}, {"Non-breaking changes", other}} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n### %s\n\n", section.title); err != nil {
+			return err
+		}
+		for _, ch := range section.changes {
+			if _, err := fmt.Fprintf(w, "- `%s`: %s\n", ch.Path, ch.Message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
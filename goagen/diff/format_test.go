@@ -0,0 +1,93 @@
+package diff_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/goadesign/goa/goagen/diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Write", func() {
+	var format string
+	var changes []*diff.Change
+	var out string
+	var err error
+
+	BeforeEach(func() {
+		changes = []*diff.Change{
+			{Path: "resources.account", Kind: diff.Added, Message: "resource added"},
+			{Path: "resources.bottle.actions.show", Kind: diff.Removed, Breaking: true, Message: "action removed"},
+		}
+	})
+
+	JustBeforeEach(func() {
+		var buf bytes.Buffer
+		err = diff.Write(&buf, format, changes)
+		out = buf.String()
+	})
+
+	Context("with the text format", func() {
+		BeforeEach(func() {
+			format = "text"
+		})
+
+		It("writes one change per line", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(out).Should(Equal("resources.account: resource added\n" +
+				"resources.bottle.actions.show: action removed [breaking]\n" +
+				"\n2 change(s), 1 breaking\n"))
+		})
+
+		Context("with no change", func() {
+			BeforeEach(func() {
+				changes = nil
+			})
+
+			It("says so", func() {
+				Ω(out).Should(Equal("no change\n"))
+			})
+		})
+	})
+
+	Context("with the JSON format", func() {
+		BeforeEach(func() {
+			format = "json"
+		})
+
+		It("writes the changes and the number of breaking changes", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			var res struct {
+				Changes  []*diff.Change
+				Breaking int
+			}
+			Ω(json.Unmarshal([]byte(out), &res)).ShouldNot(HaveOccurred())
+			Ω(res.Changes).Should(Equal(changes))
+			Ω(res.Breaking).Should(Equal(1))
+		})
+	})
+
+	Context("with the markdown format", func() {
+		BeforeEach(func() {
+			format = "markdown"
+		})
+
+		It("lists the breaking changes first", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(out).Should(Equal("## API changes\n" +
+				"\n### Breaking changes\n\n- `resources.bottle.actions.show`: action removed\n" +
+				"\n### Non-breaking changes\n\n- `resources.account`: resource added\n"))
+		})
+	})
+
+	Context("with an unknown format", func() {
+		BeforeEach(func() {
+			format = "yaml"
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
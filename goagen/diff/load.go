@@ -0,0 +1,190 @@
+package diff
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/meta"
+)
+
+// Load evaluates the design package with the given import path and returns its snapshot. source
+// selects the version of the design package: the empty string selects the working tree, the path
+// to a directory selects the design package contained in that directory and any other value is
+// interpreted as a git revision of the repository containing the design package.
+func Load(designPkg, source string) (*API, error) {
+	tmpDir, err := ioutil.TempDir("", "goagen-diff")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if source != "" {
+		gopath := filepath.Join(tmpDir, "gopath")
+		if info, err := os.Stat(source); err == nil && info.IsDir() {
+			err = copyDir(source, filepath.Join(gopath, "src", filepath.FromSlash(designPkg)))
+			if err != nil {
+				return nil, err
+			}
+		} else if err := extractRevision(designPkg, source, gopath); err != nil {
+			return nil, err
+		}
+		// Put the extracted version first in GOPATH so that it shadows the working tree.
+		orig := os.Getenv("GOPATH")
+		os.Setenv("GOPATH", gopath+string(os.PathListSeparator)+orig)
+		defer os.Setenv("GOPATH", orig)
+	}
+
+	out := filepath.Join(tmpDir, "out")
+	gen, err := meta.NewGenerator(
+		"diff.Generate",
+		[]*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa/goagen/diff")},
+		map[string]string{"design": designPkg, "out": out},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gen.Generate(); err != nil {
+		if source == "" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to evaluate design at %s: %s", source, err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(out, SnapshotFile))
+	if err != nil {
+		return nil, err
+	}
+	var api API
+	if err := json.Unmarshal(b, &api); err != nil {
+		return nil, err
+	}
+	return &api, nil
+}
+
+// extractRevision extracts the given revision of the git repository that contains the design
+// package to the src directory of gopath.
+func extractRevision(designPkg, rev, gopath string) error {
+	dir, err := codegen.PackageSourcePath(designPkg)
+	if err != nil {
+		return fmt.Errorf("%q is neither a directory nor a git revision: %s", rev, err)
+	}
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("%q is neither a directory nor a git revision: %s", rev, err)
+	}
+	root = strings.TrimSpace(root)
+	if _, err := git(root, "rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+		return fmt.Errorf("%q is neither a directory nor a git revision", rev)
+	}
+
+	// Compute the import path of the repository root from the design package import path.
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return err
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	rootPkg := designPkg
+	if rel != "." {
+		rootPkg = strings.TrimSuffix(designPkg, "/"+filepath.ToSlash(rel))
+	}
+
+	archive, err := git(root, "archive", "--format=tar", rev)
+	if err != nil {
+		return err
+	}
+	return untar(strings.NewReader(archive), filepath.Join(gopath, "src", filepath.FromSlash(rootPkg)))
+}
+
+// git runs git with the given arguments in dir and returns its standard output.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s\n%s", args[0], err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// untar extracts the regular files and directories of the tar archive read from r to dst.
+func untar(r io.Reader, dst string) error {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(h.Name)
+		if strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			return fmt.Errorf("invalid path %q in archive", h.Name)
+		}
+		target := filepath.Join(dst, filepath.FromSlash(name))
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(h.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// copyDir copies the regular files of src to dst recursively.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			if path != src && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, b, info.Mode().Perm())
+	})
+}
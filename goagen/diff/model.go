@@ -0,0 +1,322 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+type (
+	// API is a snapshot of the parts of an API design that make up its contract. Snapshots of
+	// two versions of a design are compared with Compare.
+	API struct {
+		// Name is the API name.
+		Name string `json:"name"`
+		// Resources indexes the resources by name.
+		Resources map[string]*Resource `json:"resources,omitempty"`
+		// Types indexes the user types by name.
+		Types map[string]*Attribute `json:"types,omitempty"`
+		// MediaTypes indexes the media types by identifier.
+		MediaTypes map[string]*MediaType `json:"media_types,omitempty"`
+	}
+
+	// Resource is a snapshot of a resource.
+	Resource struct {
+		// Actions indexes the resource actions by name.
+		Actions map[string]*Action `json:"actions,omitempty"`
+	}
+
+	// Action is a snapshot of an action.
+	Action struct {
+		// Routes lists the action routes as "VERB /full/path" sorted alphabetically.
+		Routes []string `json:"routes,omitempty"`
+		// Params describes the path and query string parameters.
+		Params *Attribute `json:"params,omitempty"`
+		// Headers describes the request headers.
+		Headers *Attribute `json:"headers,omitempty"`
+		// Cookies describes the request cookies.
+		Cookies *Attribute `json:"cookies,omitempty"`
+		// Payload describes the request payload.
+		Payload *Attribute `json:"payload,omitempty"`
+		// PayloadOptional is true if the payload may be omitted.
+		PayloadOptional bool `json:"payload_optional,omitempty"`
+		// Patch is the Content-Type of the payload if it is a patch document.
+		Patch string `json:"patch,omitempty"`
+		// Pagination is the pagination strategy if any, "cursor" or "offset", followed by
+		// "+envelope" if the pages are wrapped in an envelope.
+		Pagination string `json:"pagination,omitempty"`
+		// Deprecated is true if the action or its resource is deprecated in some version.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Responses indexes the responses by name.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// Security lists the alternative security requirements, each requirement lists the
+		// names of the schemes that must all be satisfied joined with "+".
+		Security []string `json:"security,omitempty"`
+	}
+
+	// Response is a snapshot of an action response.
+	Response struct {
+		// Status is the HTTP status code.
+		Status int `json:"status"`
+		// MediaType is the identifier of the response media type if any.
+		MediaType string `json:"media_type,omitempty"`
+		// View is the name of the view used to render the media type.
+		View string `json:"view,omitempty"`
+	}
+
+	// MediaType is a snapshot of a media type.
+	MediaType struct {
+		// Attribute describes the media type attributes.
+		Attribute *Attribute `json:"attribute"`
+		// Views lists the names of the attributes rendered by each view.
+		Views map[string][]string `json:"views,omitempty"`
+	}
+
+	// Attribute is a snapshot of an attribute type and validations.
+	Attribute struct {
+		// Type is the name of the attribute type kind: "string", "array", "object" etc.
+		Type string `json:"type"`
+		// Ref is the name of the user type or the identifier of the media type if the
+		// attribute type is one.
+		Ref string `json:"ref,omitempty"`
+		// Elem describes the elements of arrays and the values of hashes.
+		Elem *Attribute `json:"elem,omitempty"`
+		// Key describes the keys of hashes.
+		Key *Attribute `json:"key,omitempty"`
+		// Attributes describes the attributes of objects.
+		Attributes map[string]*Attribute `json:"attributes,omitempty"`
+		// Variants describes the variants of unions indexed by name.
+		Variants map[string]*Attribute `json:"variants,omitempty"`
+		// Discriminator is the name of the discriminator attribute of unions.
+		Discriminator string `json:"discriminator,omitempty"`
+		// Required lists the names of the required attributes of objects sorted
+		// alphabetically.
+		Required []string `json:"required,omitempty"`
+		// Nullable is true if the attribute may be set to null.
+		Nullable bool `json:"nullable,omitempty"`
+		// Default is the default value.
+		Default interface{} `json:"default,omitempty"`
+		// Enum lists the values allowed by an Enum validation.
+		Enum []interface{} `json:"enum,omitempty"`
+		// Format is the name of the format validation.
+		Format string `json:"format,omitempty"`
+		// Pattern is the regular expression of the pattern validation.
+		Pattern string `json:"pattern,omitempty"`
+		// Minimum is the minimum value validation.
+		Minimum *float64 `json:"minimum,omitempty"`
+		// Maximum is the maximum value validation.
+		Maximum *float64 `json:"maximum,omitempty"`
+		// MinLength is the minimum length validation.
+		MinLength *int `json:"min_length,omitempty"`
+		// MaxLength is the maximum length validation.
+		MaxLength *int `json:"max_length,omitempty"`
+		// ExclusiveMinimum is true if the minimum value is excluded.
+		ExclusiveMinimum bool `json:"exclusive_minimum,omitempty"`
+		// ExclusiveMaximum is true if the maximum value is excluded.
+		ExclusiveMaximum bool `json:"exclusive_maximum,omitempty"`
+		// MultipleOf is the multiple of validation.
+		MultipleOf *float64 `json:"multiple_of,omitempty"`
+		// UniqueItems is true if the array elements must be unique.
+		UniqueItems bool `json:"unique_items,omitempty"`
+		// MinProperties is the minimum number of hash keys validation.
+		MinProperties *int `json:"min_properties,omitempty"`
+		// MaxProperties is the maximum number of hash keys validation.
+		MaxProperties *int `json:"max_properties,omitempty"`
+		// Rules describes the cross-field validations and validation functions of
+		// objects sorted alphabetically.
+		Rules []string `json:"rules,omitempty"`
+	}
+)
+
+// New returns the snapshot of the given API design.
+func New(api *design.APIDefinition) *API {
+	res := &API{
+		Name:       api.Name,
+		Resources:  make(map[string]*Resource),
+		Types:      make(map[string]*Attribute),
+		MediaTypes: make(map[string]*MediaType),
+	}
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		res.Types[ut.TypeName] = newAttribute(ut.AttributeDefinition)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		m := &MediaType{Attribute: newAttribute(mt.AttributeDefinition), Views: make(map[string][]string)}
+		mt.IterateViews(func(v *design.ViewDefinition) error {
+			var names []string
+			if v.AttributeDefinition != nil {
+				for n := range v.Type.ToObject() {
+					names = append(names, n)
+				}
+			}
+			sort.Strings(names)
+			m.Views[v.Name] = names
+			return nil
+		})
+		res.MediaTypes[mt.Identifier] = m
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		resource := &Resource{Actions: make(map[string]*Action)}
+		r.IterateActions(func(a *design.ActionDefinition) error {
+			resource.Actions[a.Name] = newAction(api, a)
+			return nil
+		})
+		res.Resources[r.Name] = resource
+		return nil
+	})
+	return res
+}
+
+// newAction returns the snapshot of the given action.
+func newAction(api *design.APIDefinition, a *design.ActionDefinition) *Action {
+	action := &Action{Responses: make(map[string]*Response)}
+	for _, r := range a.Routes {
+		action.Routes = append(action.Routes, r.Verb+" "+r.FullPath())
+	}
+	sort.Strings(action.Routes)
+	if a.Params != nil {
+		action.Params = newAttribute(a.Params)
+	}
+	if a.Headers != nil {
+		action.Headers = newAttribute(a.Headers)
+	}
+	if a.Cookies != nil {
+		action.Cookies = newAttribute(a.Cookies)
+	}
+	if p := a.Payload; p != nil {
+		if _, ok := api.Types[p.TypeName]; ok {
+			action.Payload = newAttribute(&design.AttributeDefinition{Type: p})
+		} else {
+			// Payloads defined inline are not API user types.
+			action.Payload = newAttribute(p.AttributeDefinition)
+		}
+		action.PayloadOptional = a.PayloadOptional
+		if a.Patch != nil {
+			action.Patch = a.Patch.Format
+		}
+	}
+	for _, l := range []*design.LifecycleDefinition{a.Lifecycle, a.Parent.Lifecycle} {
+		if l != nil && l.Deprecation != nil {
+			action.Deprecated = true
+		}
+	}
+	if p := a.Pagination; p != nil {
+		action.Pagination = string(p.Strategy)
+		if p.Envelope {
+			action.Pagination += "+envelope"
+		}
+	}
+	for n, r := range a.Responses {
+		resp := &Response{Status: r.Status, View: r.ViewName}
+		if r.MediaType != "" {
+			resp.MediaType = design.CanonicalIdentifier(r.MediaType)
+			if mt := api.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+				resp.MediaType = mt.Identifier
+			}
+		}
+		action.Responses[n] = resp
+	}
+	if a.Security != nil {
+		for _, req := range a.Security.Requirements() {
+			var names []string
+			for _, s := range req.AllSchemes() {
+				names = append(names, s.SchemeName)
+			}
+			sort.Strings(names)
+			action.Security = append(action.Security, strings.Join(names, "+"))
+		}
+		sort.Strings(action.Security)
+	}
+	return action
+}
+
+// newAttribute returns the snapshot of the given attribute, user types and media types are
+// referenced by name.
+func newAttribute(att *design.AttributeDefinition) *Attribute {
+	res := &Attribute{Type: kind(att.Type), Default: att.DefaultValue, Nullable: att.Nullable}
+	switch t := att.Type.(type) {
+	case *design.MediaTypeDefinition:
+		res.Ref = t.Identifier
+	case *design.UserTypeDefinition:
+		res.Ref = t.TypeName
+	case *design.Array:
+		res.Elem = newAttribute(t.ElemType)
+	case *design.Hash:
+		res.Key, res.Elem = newAttribute(t.KeyType), newAttribute(t.ElemType)
+	case design.Object:
+		res.Attributes = make(map[string]*Attribute, len(t))
+		for n, child := range t {
+			res.Attributes[n] = newAttribute(child)
+		}
+	case *design.Union:
+		res.Variants = make(map[string]*Attribute, len(t.Variants))
+		for n, variant := range t.Variants {
+			res.Variants[n] = newAttribute(variant)
+		}
+		res.Discriminator = t.Discriminator
+	}
+	if v := att.Validation; v != nil {
+		res.Enum = v.Values
+		res.Format = v.Format
+		res.Pattern = v.Pattern
+		res.Minimum, res.Maximum = v.Minimum, v.Maximum
+		res.MinLength, res.MaxLength = v.MinLength, v.MaxLength
+		res.ExclusiveMinimum, res.ExclusiveMaximum = v.ExclusiveMinimum, v.ExclusiveMaximum
+		res.MultipleOf = v.MultipleOf
+		res.UniqueItems = v.UniqueItems
+		res.MinProperties, res.MaxProperties = v.MinProperties, v.MaxProperties
+		if len(v.Required) > 0 {
+			res.Required = append([]string(nil), v.Required...)
+			sort.Strings(res.Required)
+		}
+		res.Rules = crossFieldRules(v)
+	}
+	return res
+}
+
+// crossFieldRules returns the descriptions of the cross-field validations and validation
+// functions of the given validation sorted alphabetically.
+func crossFieldRules(v *dslengine.ValidationDefinition) []string {
+	var rules []string
+	sorted := func(names []string) string {
+		names = append([]string(nil), names...)
+		sort.Strings(names)
+		return strings.Join(names, ", ")
+	}
+	for _, names := range v.OneOfRequired {
+		rules = append(rules, "exactly one of "+sorted(names))
+	}
+	for _, names := range v.MutuallyExclusive {
+		rules = append(rules, "at most one of "+sorted(names))
+	}
+	for n, deps := range v.DependentRequired {
+		rules = append(rules, fmt.Sprintf("%s requires %s", n, sorted(deps)))
+	}
+	for _, f := range v.Funcs {
+		rules = append(rules, fmt.Sprintf("%s.%s(%s)", f.PackagePath, f.Name, strings.Join(f.Attributes, ", ")))
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+// kind returns the name of the kind of the given type, the kind of user types and media types is
+// the kind of their underlying type.
+func kind(t design.DataType) string {
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		return kind(actual.Type)
+	case *design.UserTypeDefinition:
+		return kind(actual.Type)
+	}
+	switch t.Kind() {
+	case design.DateTimeKind:
+		return "datetime"
+	case design.UUIDKind:
+		return "uuid"
+	}
+	return t.Name()
+}
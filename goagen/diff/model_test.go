@@ -0,0 +1,113 @@
+package diff_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var api *diff.API
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("test", func() {
+			BasePath("/api")
+		})
+		BottlePayload := Type("BottlePayload", func() {
+			Attribute("name", String, func() {
+				MinLength(2)
+			})
+			Attribute("vintage", Integer, func() {
+				Minimum(1900)
+			})
+			Required("name")
+		})
+		Bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("name", String)
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+			View("tiny", func() {
+				Attribute("id")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer)
+				})
+				Response(OK, Bottle)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(BottlePayload)
+				Response(Created)
+			})
+			Action("update", func() {
+				Routing(PATCH("/:id"))
+				Payload(func() {
+					Attribute("color", String, func() {
+						Enum("red", "white")
+					})
+				})
+				Response(NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		api = diff.New(Design)
+	})
+
+	It("snapshots the actions", func() {
+		Ω(api.Name).Should(Equal("test"))
+		Ω(api.Resources).Should(HaveKey("bottle"))
+		show := api.Resources["bottle"].Actions["show"]
+		Ω(show.Routes).Should(Equal([]string{"GET /api/bottles/:id"}))
+		Ω(show.Params.Attributes).Should(HaveKey("id"))
+		Ω(show.Params.Attributes["id"].Type).Should(Equal("integer"))
+		Ω(show.Responses).Should(HaveKey("OK"))
+		Ω(show.Responses["OK"].Status).Should(Equal(200))
+		Ω(show.Responses["OK"].MediaType).Should(Equal("application/vnd.bottle"))
+	})
+
+	It("references the user type payloads", func() {
+		p := api.Resources["bottle"].Actions["create"].Payload
+		Ω(p.Type).Should(Equal("object"))
+		Ω(p.Ref).Should(Equal("BottlePayload"))
+		Ω(p.Attributes).Should(BeEmpty())
+	})
+
+	It("expands the inline payloads", func() {
+		p := api.Resources["bottle"].Actions["update"].Payload
+		Ω(p.Ref).Should(BeEmpty())
+		Ω(p.Attributes).Should(HaveKey("color"))
+		Ω(p.Attributes["color"].Enum).Should(Equal([]interface{}{"red", "white"}))
+	})
+
+	It("snapshots the user types and their validations", func() {
+		Ω(api.Types).Should(HaveKey("BottlePayload"))
+		t := api.Types["BottlePayload"]
+		Ω(t.Required).Should(Equal([]string{"name"}))
+		Ω(*t.Attributes["name"].MinLength).Should(Equal(2))
+		Ω(*t.Attributes["vintage"].Minimum).Should(Equal(1900.0))
+	})
+
+	It("snapshots the media type views", func() {
+		Ω(api.MediaTypes).Should(HaveKey("application/vnd.bottle"))
+		mt := api.MediaTypes["application/vnd.bottle"]
+		Ω(mt.Views["default"]).Should(Equal([]string{"id", "name"}))
+		Ω(mt.Views["tiny"]).Should(Equal([]string{"id"}))
+	})
+})
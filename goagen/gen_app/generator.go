@@ -6,9 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/ir"
 	"github.com/goadesign/goa/goagen/utils"
 )
 
@@ -31,6 +33,7 @@ type Generator struct {
 	Target    string                // Name of generated package
 	NoTest    bool                  // Whether to skip test generation
 	genfiles  []string              // Generated files
+	files     []*ir.File            // Files to render
 	validator *codegen.Validator    // Validation code generator
 }
 
@@ -86,6 +89,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 		return nil, err
 	}
 	g.genfiles = []string{g.OutDir}
+	g.files = nil
 	if err := g.generateContexts(); err != nil {
 		return nil, err
 	}
@@ -95,7 +99,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateSecurity(); err != nil {
 		return nil, err
 	}
-	if err := g.generateHrefs(); err != nil {
+	if err := g.withLatestVersion(g.generateHrefs); err != nil {
 		return nil, err
 	}
 	if err := g.generateMediaTypes(); err != nil {
@@ -104,12 +108,21 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateUserTypes(); err != nil {
 		return nil, err
 	}
+	if err := g.generateNullableTypes(); err != nil {
+		return nil, err
+	}
 	if !g.NoTest {
-		if err := g.generateResourceTest(); err != nil {
+		if err := g.withLatestVersion(g.generateResourceTest); err != nil {
 			return nil, err
 		}
 	}
 
+	paths, err := ir.Render(ir.App, g.files)
+	g.genfiles = append(g.genfiles, paths...)
+	if err != nil {
+		return nil, err
+	}
+
 	return g.genfiles, nil
 }
 
@@ -125,25 +138,12 @@ func (g *Generator) Cleanup() {
 // generateContexts iterates through the API resources and actions and generates the action
 // contexts.
 func (g *Generator) generateContexts() (err error) {
-	var (
-		ctxFile string
-		ctxWr   *ContextsWriter
-	)
-	{
-		ctxFile = filepath.Join(g.OutDir, "contexts.go")
-		ctxWr, err = NewContextsWriter(ctxFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		ctxWr.Close()
-		if err == nil {
-			err = ctxWr.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filepath.Join(g.OutDir, "contexts.go"))
+	g.files = append(g.files, file)
+	ctxWr := newContextsWriter(file)
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("strconv"),
@@ -163,13 +163,22 @@ func (g *Generator) generateContexts() (err error) {
 		})
 	})
 
-	g.genfiles = append(g.genfiles, ctxFile)
 	if err = ctxWr.WriteHeader(title, g.Target, imports); err != nil {
 		return
 	}
-	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+	full := g.API
+	return g.forEachVersion(func(api *design.APIDefinition, suffix string) error {
+		return g.writeContexts(ctxWr, api, full, suffix)
+	})
+}
+
+// writeContexts generates the contexts of the actions of the given API. suffix is appended to the
+// resource names in the context type names. The payloads are the payloads of the actions of full
+// so that all versions share the same payload types.
+func (g *Generator) writeContexts(ctxWr *ContextsWriter, api, full *design.APIDefinition, suffix string) error {
+	return api.IterateResources(func(r *design.ResourceDefinition) error {
 		return r.IterateActions(func(a *design.ActionDefinition) error {
-			ctxName := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + "Context"
+			ctxName := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true) + suffix + "Context"
 			headers := &design.AttributeDefinition{
 				Type: design.Object{},
 			}
@@ -184,6 +193,10 @@ func (g *Generator) generateContexts() (err error) {
 			if headers != nil && len(headers.Type.ToObject()) == 0 {
 				headers = nil // So that {{if .Headers}} returns false in templates
 			}
+			cookies := a.Cookies
+			if cookies != nil && len(cookies.Type.ToObject()) == 0 {
+				cookies = nil // So that {{if .Cookies}} returns false in templates
+			}
 			params := a.AllParams()
 			if params != nil && len(params.Type.ToObject()) == 0 {
 				params = nil // So that {{if .Params}} returns false in templates
@@ -195,45 +208,44 @@ func (g *Generator) generateContexts() (err error) {
 					non101[k] = v
 				}
 			}
+			shared := full.Resources[r.Name].Actions[a.Name]
 			ctxData := ContextTemplateData{
 				Name:         ctxName,
 				ResourceName: r.Name,
 				ActionName:   a.Name,
-				Payload:      a.Payload,
+				Payload:      shared.Payload,
+				Patch:        shared.Patch,
+				Pagination:   a.Pagination,
 				Params:       params,
 				Headers:      headers,
+				Cookies:      cookies,
 				Routes:       a.Routes,
 				Responses:    non101,
-				API:          g.API,
+				API:          api,
 				DefaultPkg:   g.Target,
 				Security:     a.Security,
 			}
+			if mt, collection := a.ShapedMediaType(); mt != nil {
+				ctxData.Sortable = collection && len(mt.SortFields) > 0 && isStringPointer(params, "sort")
+				ctxData.SparseFieldsets = mt.SparseFieldsets && isStringPointer(params, "fields")
+				if len(full.Produces) > 0 {
+					ctxData.DefaultEncoding = full.Produces[0].MIMETypes[0]
+				}
+				if collection {
+					ctxData.Filters = contextFilters(mt, params, strings.TrimSuffix(ctxName, "Context"))
+				}
+			}
 			return ctxWr.Execute(&ctxData)
 		})
 	})
-	return
 }
 
 // generateControllers iterates through the API resources and generates the low level
 // controllers.
 func (g *Generator) generateControllers() (err error) {
-	var (
-		ctlFile string
-		ctlWr   *ControllersWriter
-	)
-	{
-		ctlFile = filepath.Join(g.OutDir, "controllers.go")
-		ctlWr, err = NewControllersWriter(ctlFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		ctlWr.Close()
-		if err == nil {
-			err = ctlWr.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filepath.Join(g.OutDir, "controllers.go"))
+	g.files = append(g.files, file)
+	ctlWr := newControllersWriter(file)
 	title := fmt.Sprintf("%s: Application Controllers", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("net/http"),
@@ -244,13 +256,26 @@ func (g *Generator) generateControllers() (err error) {
 		codegen.SimpleImport("regexp"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("time"),
+		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 	}
+	if g.API.IsVersioned() {
+		// The controllers of versioned APIs validate the payloads.
+		g.API.IterateResources(func(r *design.ResourceDefinition) error {
+			return r.IterateActions(func(a *design.ActionDefinition) error {
+				if a.Payload != nil {
+					imports = codegen.AttributeImports(a.Payload.AttributeDefinition, imports, nil)
+				}
+				return nil
+			})
+		})
+	}
 	encoders, err := BuildEncoders(g.API.Produces, true)
 	if err != nil {
 		return err
 	}
-	decoders, err := BuildEncoders(g.API.Consumes, false)
+	consumes := append(append([]*design.EncodingDefinition{}, g.API.Consumes...), g.API.PatchEncodings(false)...)
+	decoders, err := BuildEncoders(consumes, false)
 	if err != nil {
 		return err
 	}
@@ -277,10 +302,39 @@ func (g *Generator) generateControllers() (err error) {
 	if err = ctlWr.WriteInitService(encoders, decoders); err != nil {
 		return err
 	}
+	var selector bool
+	if g.API.IsVersioned() && g.API.VersionScheme != design.PathVersioning {
+		selector = true
+		if err = ctlWr.WriteVersionSelector(g.API); err != nil {
+			return err
+		}
+	}
 
-	g.genfiles = append(g.genfiles, ctlFile)
 	var controllersData []*ControllerTemplateData
-	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+	err = g.forEachVersion(func(api *design.APIDefinition, suffix string) error {
+		var version string
+		if selector {
+			version = api.Version
+		}
+		controllersData = append(controllersData, g.controllersData(api, suffix, version, encoders, decoders)...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = ctlWr.Execute(controllersData)
+	return
+}
+
+// controllersData returns the data used to generate the controllers of the resources of the given
+// API. suffix is appended to the resource names in the controller names. version is the API
+// version served by the controllers if the requests select the version with a header or a media
+// type parameter. The latest version serves the file servers and the CORS preflight requests of
+// paths shared by several versions.
+func (g *Generator) controllersData(api *design.APIDefinition, suffix, version string, encoders, decoders []*EncoderTemplateData) []*ControllerTemplateData {
+	latest := suffix == ""
+	var controllersData []*ControllerTemplateData
+	api.IterateResources(func(r *design.ResourceDefinition) error {
 		// Create file servers for all directory file servers that serve index.html.
 		fileServers := r.FileServers
 		for _, fs := range r.FileServers {
@@ -298,25 +352,49 @@ func (g *Generator) generateControllers() (err error) {
 				})
 			}
 		}
+		if !latest {
+			fileServers = nil
+		}
 		data := &ControllerTemplateData{
-			API:            g.API,
-			Resource:       codegen.Goify(r.Name, true),
+			API:            api,
+			Resource:       codegen.Goify(r.Name, true) + suffix,
 			PreflightPaths: r.PreflightPaths(),
 			FileServers:    fileServers,
+			Version:        version,
+		}
+		if version != "" && !latest {
+			data.PreflightPaths = nil
 		}
 		r.IterateActions(func(a *design.ActionDefinition) error {
-			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
-			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
+			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), data.Resource)
+			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), data.Resource)
+			// The payload types are shared by all versions, the controllers of versioned APIs
+			// validate the payload against the version with dedicated functions.
+			var validate string
+			if api.IsVersioned() && a.Payload != nil && a.Payload.IsObject() {
+				validate = fmt.Sprintf("validate%s%sPayload", codegen.Goify(a.Name, true), data.Resource)
+			}
+			// The full paths of the routes depend on the version, compute them while Design
+			// is set to the version projection.
+			routes := make([]*design.RouteDefinition, len(a.Routes))
+			for i, r := range a.Routes {
+				route := *r
+				route.Path = "/" + r.FullPath()
+				routes[i] = &route
+			}
 			action := map[string]interface{}{
 				"Name":             codegen.Goify(a.Name, true),
 				"DesignName":       a.Name,
-				"Routes":           a.Routes,
+				"Routes":           routes,
 				"Context":          context,
 				"Unmarshal":        unmarshal,
+				"Validate":         validate,
 				"Payload":          a.Payload,
 				"PayloadOptional":  a.PayloadOptional,
 				"PayloadMultipart": a.PayloadMultipart,
 				"Security":         a.Security,
+				"PreserveBody":     signsBody(a.Security),
+				"Deprecation":      a.Deprecation(),
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
@@ -329,8 +407,62 @@ func (g *Generator) generateControllers() (err error) {
 		}
 		return nil
 	})
-	err = ctlWr.Execute(controllersData)
-	return
+	return controllersData
+}
+
+// signsBody returns true if one of the schemes of the given security requirements verifies HTTP
+// message signatures. The signatures may cover the body digest so the body must remain readable
+// once the payload is decoded.
+func signsBody(security *design.SecurityDefinition) bool {
+	if security == nil {
+		return false
+	}
+	for _, r := range security.Requirements() {
+		for _, s := range r.AllSchemes() {
+			if s.Kind == design.HTTPSigSecurityKind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forEachVersion calls fn with each version of the API served by the generated code and the suffix
+// appended to the resource names in the names of the version contexts and controllers. If the
+// design declares several versions fn is called with the projection of each version, see
+// design.APIDefinition.WithVersion, and the latest version uses no suffix. Otherwise fn is called
+// once with the API definition and no suffix.
+func (g *Generator) forEachVersion(fn func(api *design.APIDefinition, suffix string) error) error {
+	if !g.API.IsVersioned() {
+		return fn(g.API, "")
+	}
+	for _, v := range g.API.Versions {
+		var suffix string
+		if v.Name != g.API.Version {
+			suffix = codegen.Goify(v.Name, true)
+		}
+		err := g.API.WithVersion(v.Name, func(api *design.APIDefinition) error {
+			return fn(api, suffix)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withLatestVersion calls fn with the generator API set to the projection of the latest version
+// if the design declares several versions, see forEachVersion.
+func (g *Generator) withLatestVersion(fn func() error) error {
+	if !g.API.IsVersioned() {
+		return fn()
+	}
+	full := g.API
+	defer func() { g.API = full }()
+	return full.WithVersion(full.Version, func(api *design.APIDefinition) error {
+		g.API = api
+		return fn()
+	})
 }
 
 // generateControllers iterates through the API resources and generates the low level
@@ -340,23 +472,9 @@ func (g *Generator) generateSecurity() (err error) {
 		return nil
 	}
 
-	var (
-		secFile string
-		secWr   *SecurityWriter
-	)
-	{
-		secFile = filepath.Join(g.OutDir, "security.go")
-		secWr, err = NewSecurityWriter(secFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		secWr.Close()
-		if err == nil {
-			err = secWr.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filepath.Join(g.OutDir, "security.go"))
+	g.files = append(g.files, file)
+	secWr := &SecurityWriter{Writer: file}
 	title := fmt.Sprintf("%s: Application Security", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("net/http"),
@@ -367,7 +485,6 @@ func (g *Generator) generateSecurity() (err error) {
 	if err = secWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, secFile)
 	err = secWr.Execute(design.Design.SecuritySchemes)
 
 	return
@@ -375,23 +492,9 @@ func (g *Generator) generateSecurity() (err error) {
 
 // generateHrefs iterates through the API resources and generates the href factory methods.
 func (g *Generator) generateHrefs() (err error) {
-	var (
-		hrefFile string
-		resWr    *ResourcesWriter
-	)
-	{
-		hrefFile = filepath.Join(g.OutDir, "hrefs.go")
-		resWr, err = NewResourcesWriter(hrefFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		resWr.Close()
-		if err == nil {
-			err = resWr.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filepath.Join(g.OutDir, "hrefs.go"))
+	g.files = append(g.files, file)
+	resWr := &ResourcesWriter{Writer: file}
 	title := fmt.Sprintf("%s: Application Resource Href Factories", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("fmt"),
@@ -400,7 +503,6 @@ func (g *Generator) generateHrefs() (err error) {
 	if err = resWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, hrefFile)
 	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		m := g.API.MediaTypeWithIdentifier(r.MediaType)
 		var identifier string
@@ -425,26 +527,13 @@ func (g *Generator) generateHrefs() (err error) {
 // generateMediaTypes iterates through the media types and generate the data structures and
 // marshaling code.
 func (g *Generator) generateMediaTypes() (err error) {
-	var (
-		mtFile string
-		mtWr   *MediaTypesWriter
-	)
-	{
-		mtFile = filepath.Join(g.OutDir, "media_types.go")
-		mtWr, err = NewMediaTypesWriter(mtFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		mtWr.Close()
-		if err == nil {
-			err = mtWr.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filepath.Join(g.OutDir, "media_types.go"))
+	g.files = append(g.files, file)
+	mtWr := newMediaTypesWriter(file)
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
@@ -456,7 +545,6 @@ func (g *Generator) generateMediaTypes() (err error) {
 	if err = mtWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, mtFile)
 	err = g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
 		if mt.IsError() {
 			return nil
@@ -472,27 +560,15 @@ func (g *Generator) generateMediaTypes() (err error) {
 // generateUserTypes iterates through the user types and generates the data structures and
 // marshaling code.
 func (g *Generator) generateUserTypes() (err error) {
-	var (
-		utFile string
-		utWr   *UserTypesWriter
-	)
-	{
-		utFile = filepath.Join(g.OutDir, "user_types.go")
-		utWr, err = NewUserTypesWriter(utFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		utWr.Close()
-		if err == nil {
-			err = utWr.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filepath.Join(g.OutDir, "user_types.go"))
+	g.files = append(g.files, file)
+	utWr := newUserTypesWriter(file)
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("mime/multipart"),
+		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
@@ -504,9 +580,98 @@ func (g *Generator) generateUserTypes() (err error) {
 	if err = utWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, utFile)
 	err = g.API.IterateUserTypes(func(t *design.UserTypeDefinition) error {
 		return utWr.Execute(t)
 	})
 	return
 }
+
+// generateNullableTypes generates the types holding the values of the nullable attributes if
+// there are any.
+func (g *Generator) generateNullableTypes() error {
+	atts := NullableTypes(g.API)
+	if len(atts) == 0 {
+		return nil
+	}
+	file := ir.NewFile(filepath.Join(g.OutDir, "nullable_types.go"))
+	g.files = append(g.files, file)
+	ntWr := newNullableTypesWriter(file)
+	title := fmt.Sprintf("%s: Application Nullable Types", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+	}
+	if err := ntWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	return ntWr.Execute(atts)
+}
+
+// NullableTypes returns the nullable attributes defined in the API user types, media types and
+// action payloads, one for each type that must be generated to hold their values.
+func NullableTypes(api *design.APIDefinition) []*design.AttributeDefinition {
+	var dss []design.DataStructure
+	api.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		dss = append(dss, t)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		dss = append(dss, mt)
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				dss = append(dss, a.Payload)
+			}
+			return nil
+		})
+	})
+	return codegen.NullableTypes(dss...)
+}
+
+// isStringPointer returns true if params defines an optional string parameter with the given name
+// held in a pointer field of the context.
+func isStringPointer(params *design.AttributeDefinition, name string) bool {
+	if params == nil {
+		return false
+	}
+	att, ok := params.Type.ToObject()[name]
+	return ok && att.Type.Kind() == design.StringKind && params.IsPrimitivePointer(name)
+}
+
+// contextFilters returns the filters of the collections of mt exposed by the context whose
+// parameters are params. prefix is the prefix of the filter struct names.
+func contextFilters(mt *design.MediaTypeDefinition, params *design.AttributeDefinition, prefix string) []*ContextFilter {
+	if params == nil {
+		return nil
+	}
+	obj := params.Type.ToObject()
+	var filters []*ContextFilter
+	for _, f := range mt.Filters {
+		name := codegen.Goify(f.Attribute, true)
+		filter := &ContextFilter{Name: name, TypeName: prefix + name + "Filter", Attribute: f.Attribute}
+		for _, op := range f.Operators {
+			n := f.ParamName(op)
+			att, ok := obj[n]
+			if !ok || !att.Type.IsPrimitive() {
+				continue
+			}
+			typ := codegen.GoNativeTypeAtt(att)
+			if params.IsPrimitivePointer(n) {
+				typ = "*" + typ
+			}
+			filter.Operators = append(filter.Operators, &ContextFilterOperator{
+				Name:  codegen.Goify(string(op), true),
+				Type:  typ,
+				Field: codegen.GoifyAtt(att, n, true),
+			})
+		}
+		if len(filter.Operators) > 0 {
+			filters = append(filters, filter)
+		}
+	}
+	return filters
+}
@@ -4,19 +4,25 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_app"
+	"github.com/goadesign/goa/goagen/ir"
 	"github.com/goadesign/goa/version"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+// registeredDesign is the API definition registered as root with the DSL engine.
+var registeredDesign = design.Design
+
 var _ = Describe("Generate", func() {
 	var workspace *codegen.Workspace
 	var outDir string
@@ -68,6 +74,153 @@ var _ = Describe("Generate", func() {
 			isEmptySource("hrefs.go")
 			isEmptySource("media_types.go")
 		})
+
+		Context("with a registered hook", func() {
+			BeforeEach(func() {
+				ir.RegisterHook(ir.App, func(files []*ir.File) ([]*ir.File, error) {
+					for _, f := range files {
+						if filepath.Base(f.Path) != "controllers.go" {
+							continue
+						}
+						for _, s := range f.Section("service") {
+							f.InsertAfter(s, &ir.Section{Name: "audit", Source: "// Audited by {{ . }}\n", Data: "hook"})
+						}
+					}
+					extra := ir.NewFile(filepath.Join(outDir, "app", "extra.go"))
+					extra.WriteHeader("", "app", nil)
+					return append(files, extra), nil
+				})
+			})
+
+			AfterEach(func() {
+				ir.ResetHooks()
+			})
+
+			It("renders the files modified by the hook", func() {
+				Ω(genErr).Should(BeNil())
+				Ω(files).Should(HaveLen(7))
+				Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "extra.go")))
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("// Audited by hook\n"))
+			})
+		})
+	})
+
+	Context("with a versioned API", func() {
+		BeforeEach(func() {
+			// Other specs replace the design, restore the one registered with the DSL engine.
+			design.Design = registeredDesign
+			dslengine.Reset()
+			apidsl.API("test", func() {
+				apidsl.Version("v1")
+				apidsl.Version("v2")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST("/bottles"))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", design.String)
+						apidsl.Attribute("color", design.String, func() {
+							apidsl.AddedIn("v2")
+						})
+						apidsl.Required("name", "color")
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).Should(Succeed())
+		})
+
+		It("validates the payloads of each version route against the version", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring(`service.Mux.Handle("POST", "/v1/bottles", ctrl.MuxHandler("create", h, unmarshalCreateBottleV1Payload))`))
+			Ω(code).Should(ContainSubstring(`service.Mux.Handle("POST", "/v2/bottles", ctrl.MuxHandler("create", h, unmarshalCreateBottlePayload))`))
+			Ω(code).Should(ContainSubstring(versionedPayloadValidation))
+			Ω(code).Should(ContainSubstring(latestPayloadValidation))
+		})
+	})
+
+	Context("with a versioned API validating the payload lengths and with functions", func() {
+		BeforeEach(func() {
+			pkg, err := workspace.NewPackage("example.com/validators")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ioutil.WriteFile(filepath.Join(pkg.Abs(), "validators.go"), []byte(validatorsCode), 0644)).Should(Succeed())
+			design.Design = registeredDesign
+			dslengine.Reset()
+			apidsl.API("test", func() {
+				apidsl.Version("v1")
+				apidsl.Version("v2")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST("/bottles"))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", design.String, func() {
+							apidsl.MinLength(2)
+						})
+						apidsl.Attribute("color", design.String, func() {
+							apidsl.AddedIn("v2")
+						})
+						apidsl.Required("name", "color")
+						apidsl.ValidateFunc("example.com/validators", "CheckName", "name")
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).Should(Succeed())
+		})
+
+		It("generates controllers that compile", func() {
+			if _, err := exec.LookPath("go"); err != nil {
+				Skip("go not installed")
+			}
+			Ω(genErr).Should(BeNil())
+			cmd := exec.Command("go", "build", ".")
+			cmd.Dir = filepath.Join(outDir, "app")
+			cmd.Env = append(os.Environ(), "GO111MODULE=off", "GOFLAGS=")
+			out, err := cmd.CombinedOutput()
+			Ω(err).ShouldNot(HaveOccurred(), string(out))
+		})
+	})
+
+	Context("with a filter on an enum attribute", func() {
+		BeforeEach(func() {
+			design.Design = registeredDesign
+			dslengine.Reset()
+			apidsl.API("test", nil)
+			status := apidsl.Type("status", design.String, func() {
+				apidsl.Enum("open", "sealed")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("status", status)
+					apidsl.Filterable("status", design.Eq)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("status")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET("/bottles"))
+					apidsl.Response(design.OK, apidsl.CollectionOf(bottle))
+				})
+			})
+			Ω(dslengine.Run()).Should(Succeed())
+		})
+
+		It("coerces the filter parameter to the base type of the enum", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("FilterStatusEq *string"))
+			Ω(code).Should(ContainSubstring(enumFilterCode))
+		})
 	})
 
 	Context("with a simple API", func() {
@@ -583,3 +736,54 @@ func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *h
 	return nil
 }
 `
+
+const versionedPayloadValidation = `	if err := validateCreateBottleV1Payload(payload); err != nil {
+		// Initialize payload with private data structure so it can be logged
+		goa.ContextRequest(ctx).Payload = payload
+		return err
+	}
+	goa.ContextRequest(ctx).Payload = payload.Publicize()
+	return nil
+}
+
+// validateCreateBottleV1Payload validates the payload against the version of the API served by the
+// controller.
+func validateCreateBottleV1Payload(payload *createBottlePayload) (err error) {
+	if payload.Name == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`raw`" + `, "name"))
+	}
+	return
+}
+`
+
+const latestPayloadValidation = `func validateCreateBottlePayload(payload *createBottlePayload) (err error) {
+	if payload.Name == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`raw`" + `, "name"))
+	}
+	if payload.Color == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`raw`" + `, "color"))
+	}
+	return
+}
+`
+
+const enumFilterCode = `		rawFilterStatusEq := paramFilterStatusEq[0]
+		rctx.FilterStatusEq = &rawFilterStatusEq
+		if rctx.FilterStatusEq != nil {
+			if !(*rctx.FilterStatusEq == "open" || *rctx.FilterStatusEq == "sealed") {
+				err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`filter[status][eq]`" + `, *rctx.FilterStatusEq, []interface{}{"open", "sealed"}))
+			}
+		}`
+
+const validatorsCode = `package validators
+
+import "errors"
+
+// CheckName rejects the reserved names.
+func CheckName(name string) error {
+	if name == "admin" {
+		return errors.New("reserved name")
+	}
+	return nil
+}
+`
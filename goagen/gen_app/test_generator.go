@@ -12,6 +12,7 @@ import (
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/ir"
 )
 
 func makeTestDir(g *Generator, apiName string) (outDir string, err error) {
@@ -43,6 +44,7 @@ type TestMethod struct {
 	Params            []*ObjectType
 	QueryParams       []*ObjectType
 	Headers           []*ObjectType
+	Cookies           []*ObjectType
 	Payload           *ObjectType
 	reservedNames     map[string]bool
 }
@@ -72,7 +74,6 @@ func (g *Generator) generateResourceTest() error {
 	funcs := template.FuncMap{
 		"isSlice": isSlice,
 	}
-	testTmpl := template.Must(template.New("test").Funcs(funcs).Parse(testTmpl))
 	outDir, err := makeTestDir(g, g.API.Name)
 	if err != nil {
 		return err
@@ -100,18 +101,7 @@ func (g *Generator) generateResourceTest() error {
 	}
 
 	return g.API.IterateResources(func(res *design.ResourceDefinition) (err error) {
-		filename := filepath.Join(outDir, codegen.SnakeCase(res.Name)+"_testing.go")
-		var file *codegen.SourceFile
-		file, err = codegen.SourceFileFor(filename)
-		if err != nil {
-			return err
-		}
-		defer func() {
-			file.Close()
-			if err == nil {
-				err = file.FormatCode()
-			}
-		}()
+		file := ir.NewFile(filepath.Join(outDir, codegen.SnakeCase(res.Name)+"_testing.go"))
 		title := fmt.Sprintf("%s: %s TestHelpers", g.API.Context(), res.Name)
 		if err = file.WriteHeader(title, "test", imports); err != nil {
 			return err
@@ -145,9 +135,8 @@ func (g *Generator) generateResourceTest() error {
 		}); err != nil {
 			return err
 		}
-		g.genfiles = append(g.genfiles, filename)
-		err = testTmpl.Execute(file, methods)
-		return
+		g.files = append(g.files, file)
+		return file.ExecuteTemplate("test", testTmpl, funcs, methods)
 	})
 }
 
@@ -162,6 +151,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		path                                         []*ObjectType
 		query                                        []*ObjectType
 		header                                       []*ObjectType
+		cookie                                       []*ObjectType
 		returnType                                   *ObjectType
 		payload                                      *ObjectType
 	)
@@ -207,6 +197,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 	path = pathParams(action, route)
 	query = queryParams(action)
 	header = headers(action, resource.Headers)
+	cookie = cookies(action)
 
 	if action.Payload != nil {
 		payload = &ObjectType{}
@@ -230,6 +221,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		Params:            path,
 		QueryParams:       query,
 		Headers:           header,
+		Cookies:           cookie,
 		Payload:           payload,
 		ReturnType:        returnType,
 		ReturnsErrorMedia: mediaType == design.ErrorMedia,
@@ -239,7 +231,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		RouteVerb:         route.Verb,
 		Status:            response.Status,
 		FullPath:          goPathFormat(route.FullPath()),
-		reservedNames:     reservedNames(path, query, header, payload, returnType),
+		reservedNames:     reservedNames(path, query, header, cookie, payload, returnType),
 	}
 }
 
@@ -279,6 +271,16 @@ func headers(action *design.ActionDefinition, headers *design.AttributeDefinitio
 	return objs
 }
 
+// cookies returns the cookies for the given action.
+func cookies(action *design.ActionDefinition) []*ObjectType {
+	var objs []*ObjectType
+	action.IterateCookies(func(name string, _ bool, att *design.AttributeDefinition) error {
+		objs = append(objs, attToObject(name, action.Cookies, att))
+		return nil
+	})
+	return objs
+}
+
 // queryParams returns the query string params for the given action.
 func queryParams(action *design.ActionDefinition) []*ObjectType {
 	var qparams []string
@@ -299,7 +301,7 @@ func paramFromNames(action *design.ActionDefinition, names []string) (params []*
 	return
 }
 
-func reservedNames(params, queryParams, headers []*ObjectType, payload, returnType *ObjectType) map[string]bool {
+func reservedNames(params, queryParams, headers, cookies []*ObjectType, payload, returnType *ObjectType) map[string]bool {
 	var names = make(map[string]bool)
 	for _, param := range params {
 		names[param.Name] = true
@@ -310,6 +312,9 @@ func reservedNames(params, queryParams, headers []*ObjectType, payload, returnTy
 	for _, header := range headers {
 		names[header.Name] = true
 	}
+	for _, cookie := range cookies {
+		names[cookie.Name] = true
+	}
 	if payload != nil {
 		names[payload.Name] = true
 	}
@@ -364,6 +369,7 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 */}}{{ range $param := $test.Params }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $param := $test.QueryParams }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $header := $test.Headers }}, {{ $header.Name }} {{ $header.Pointer }}{{ $header.Type }}{{ end }}{{/*
+*/}}{{ range $cookie := $test.Cookies }}, {{ $cookie.Name }} {{ $cookie.Pointer }}{{ $cookie.Type }}{{ end }}{{/*
 */}}{{ if $test.Payload }}, {{ $test.Payload.Name }} {{ $test.Payload.Pointer }}{{ $test.Payload.Type }}{{ end }}){{/*
 */}} (http.ResponseWriter{{ if $test.ReturnType }}, {{ $test.ReturnType.Pointer }}{{ $test.ReturnType.Type }}{{ end }}) {
 	// Setup service
@@ -413,6 +419,10 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 {{ template "convertParam" $header }}
 		{{ $req }}.Header[{{ printf "%q" $header.Label }}] = sliceVal
 	}
+{{ end }}{{ range $cookie := $test.Cookies }}{{ if $cookie.Pointer }}	if {{ $cookie.Name }} != nil {{ end }}{
+{{ template "convertParam" $cookie }}
+		{{ $req }}.AddCookie(&http.Cookie{Name: {{ printf "%q" $cookie.Label }}, Value: sliceVal[0]})
+	}
 {{ end }} {{ $prms := $test.Escape "prms" }}{{ $prms }} := url.Values{}
 {{ range $param := $test.Params }}	{{ $prms }}["{{ $param.Label }}"] = []string{fmt.Sprintf("%v",{{ $param.Name}})}
 {{ end }}{{ range $param := $test.QueryParams }}{{ if $param.Pointer }} if {{ $param.Name }} != nil {{ end }} {
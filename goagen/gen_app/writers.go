@@ -11,6 +11,7 @@ import (
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/ir"
 )
 
 // WildcardRegex is the regex used to capture path parameters.
@@ -19,20 +20,22 @@ var WildcardRegex = regexp.MustCompile("(?:[^/]*/:([^/]+))+")
 type (
 	// ContextsWriter generate codes for a goa application contexts.
 	ContextsWriter struct {
-		*codegen.SourceFile
+		ir.Writer
 		CtxTmpl     *template.Template
 		CtxNewTmpl  *template.Template
 		CtxRespTmpl *template.Template
 		PayloadTmpl *template.Template
 		Finalizer   *codegen.Finalizer
 		Validator   *codegen.Validator
+
+		payloads map[string]bool // Names of the payload types already generated
 	}
 
 	// ControllersWriter generate code for a goa application handlers.
 	// Handlers receive a HTTP request, create the action context, call the action code and send the
 	// resulting HTTP response.
 	ControllersWriter struct {
-		*codegen.SourceFile
+		ir.Writer
 		CtrlTmpl    *template.Template
 		MountTmpl   *template.Template
 		handleCORST *template.Template
@@ -42,7 +45,7 @@ type (
 
 	// SecurityWriter generate code for action-level security handlers.
 	SecurityWriter struct {
-		*codegen.SourceFile
+		ir.Writer
 		SecurityTmpl *template.Template
 	}
 
@@ -50,14 +53,14 @@ type (
 	// Resources are data structures initialized by the application handlers and passed to controller
 	// actions.
 	ResourcesWriter struct {
-		*codegen.SourceFile
+		ir.Writer
 		ResourceTmpl *template.Template
 	}
 
 	// MediaTypesWriter generate code for a goa application media types.
 	// Media types are data structures used to render the response bodies.
 	MediaTypesWriter struct {
-		*codegen.SourceFile
+		ir.Writer
 		MediaTypeTmpl *template.Template
 		Validator     *codegen.Validator
 	}
@@ -65,12 +68,18 @@ type (
 	// UserTypesWriter generate code for a goa application user types.
 	// User types are data structures defined in the DSL with "Type".
 	UserTypesWriter struct {
-		*codegen.SourceFile
+		ir.Writer
 		UserTypeTmpl *template.Template
 		Finalizer    *codegen.Finalizer
 		Validator    *codegen.Validator
 	}
 
+	// NullableTypesWriter generate code for the types that hold the values of nullable
+	// attributes.
+	NullableTypesWriter struct {
+		ir.Writer
+	}
+
 	// ContextTemplateData contains all the information used by the template to render the context
 	// code for an action.
 	ContextTemplateData struct {
@@ -79,24 +88,38 @@ type (
 		ActionName   string // e.g. "list"
 		Params       *design.AttributeDefinition
 		Payload      *design.UserTypeDefinition
+		Patch        *design.PatchDefinition
+		Pagination   *design.PaginationDefinition
 		Headers      *design.AttributeDefinition
-		Routes       []*design.RouteDefinition
-		Responses    map[string]*design.ResponseDefinition
-		API          *design.APIDefinition
-		DefaultPkg   string
-		Security     *design.SecurityDefinition
+		Cookies      *design.AttributeDefinition
+		// Sortable is true if the context parses the "sort" parameter into sort keys.
+		Sortable bool
+		// SparseFieldsets is true if the response helpers only render the attributes listed
+		// in the "fields" parameter.
+		SparseFieldsets bool
+		// DefaultEncoding is the content type of the default response encoder, sparse
+		// fieldsets only apply to responses encoded in JSON.
+		DefaultEncoding string
+		// Filters lists the filters exposed by the context in a typed struct.
+		Filters    []*ContextFilter
+		Routes     []*design.RouteDefinition
+		Responses  map[string]*design.ResponseDefinition
+		API        *design.APIDefinition
+		DefaultPkg string
+		Security   *design.SecurityDefinition
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
 	ControllerTemplateData struct {
 		API            *design.APIDefinition          // API definition
 		Resource       string                         // Lower case plural resource name, e.g. "bottles"
-		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Context" and "Unmarshal"
+		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Context", "Unmarshal" and "PreserveBody"
 		FileServers    []*design.FileServerDefinition // File servers
 		Encoders       []*EncoderTemplateData         // Encoder data
 		Decoders       []*EncoderTemplateData         // Decoder data
 		Origins        []*design.CORSDefinition       // CORS policies
 		PreflightPaths []string
+		Version        string // API version served if selected with a header or media type parameter
 	}
 
 	// ResourceData contains the information required to generate the resource GoGenerator
@@ -123,8 +146,37 @@ type (
 		// Default is true if this encoder/decoder should be set as the default.
 		Default bool
 	}
+
+	// ContextFilter describes the filters of an attribute of the collection rendered by an
+	// action.
+	ContextFilter struct {
+		// Name is the name of the field of the filters struct, e.g. "Vintage".
+		Name string
+		// TypeName is the name of the filter struct, e.g. "ListBottleVintageFilter".
+		TypeName string
+		// Attribute is the name of the filtered attribute, e.g. "vintage".
+		Attribute string
+		// Operators lists the operators of the filter.
+		Operators []*ContextFilterOperator
+	}
+
+	// ContextFilterOperator describes a field of a filter struct.
+	ContextFilterOperator struct {
+		// Name is the name of the field, e.g. "Gte".
+		Name string
+		// Type is the Go type of the field, e.g. "*int".
+		Type string
+		// Field is the name of the context field that holds the value of the filter
+		// parameter, e.g. "FilterVintageGte".
+		Field string
+	}
 )
 
+// FiltersName returns the name of the struct that holds the filters of the context.
+func (c *ContextTemplateData) FiltersName() string {
+	return strings.TrimSuffix(c.Name, "Context") + "Filters"
+}
+
 // IsPathParam returns true if the given parameter name corresponds to a path parameter for all
 // the context action routes. Such parameter is required but does not need to be validated as
 // httptreemux takes care of that.
@@ -197,11 +249,16 @@ func NewContextsWriter(filename string) (*ContextsWriter, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newContextsWriter(file), nil
+}
+
+// newContextsWriter returns a contexts code writer that writes to w.
+func newContextsWriter(w ir.Writer) *ContextsWriter {
 	return &ContextsWriter{
-		SourceFile: file,
-		Finalizer:  codegen.NewFinalizer(),
-		Validator:  codegen.NewValidator(),
-	}, nil
+		Writer:    w,
+		Finalizer: codegen.NewFinalizer(),
+		Validator: codegen.NewValidator(),
+	}
 }
 
 // Execute writes the code for the context types to the writer.
@@ -212,13 +269,23 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 	fn := template.FuncMap{
 		"newCoerceData":      newCoerceData,
 		"arrayAttribute":     arrayAttribute,
-		"printVal":           codegen.PrintVal,
+		"printVal":           codegen.PrintValAtt,
 		"canonicalHeaderKey": http.CanonicalHeaderKey,
 		"isPathParam":        data.IsPathParam,
 	}
 	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
 		return err
 	}
+	if data.Sortable {
+		if err := w.ExecuteTemplate("sort", ctxSortT, nil, data); err != nil {
+			return err
+		}
+	}
+	if len(data.Filters) > 0 {
+		if err := w.ExecuteTemplate("filters", ctxFiltersT, nil, data); err != nil {
+			return err
+		}
+	}
 	if data.Payload != nil {
 		found := false
 		for _, t := range design.Design.Types {
@@ -227,7 +294,11 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 				break
 			}
 		}
-		if !found {
+		if !found && !w.payloads[data.Payload.TypeName] {
+			if w.payloads == nil {
+				w.payloads = make(map[string]bool)
+			}
+			w.payloads[data.Payload.TypeName] = true
 			fn := template.FuncMap{
 				"finalizeCode":   w.Finalizer.Code,
 				"validationCode": w.Validator.Code,
@@ -235,6 +306,11 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
 				return err
 			}
+			if data.Patch != nil {
+				if err := w.ExecuteTemplate("patch", patchT, fn, data); err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
@@ -271,19 +347,32 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 				if err != nil {
 					return err
 				}
-				respData["Projected"] = projected
-				respData["ViewName"] = view
-				respData["MediaType"] = mt
-				respData["ContentType"] = mt.ContentType
+				// The writer may render the template later, use a new map for each view.
+				viewData := map[string]interface{}{
+					"Context":     data,
+					"Response":    resp,
+					"Projected":   projected,
+					"ViewName":    view,
+					"MediaType":   mt,
+					"ContentType": mt.ContentType,
+				}
 				if view == "default" {
-					respData["RespName"] = codegen.Goify(resp.Name, true)
+					viewData["RespName"] = codegen.Goify(resp.Name, true)
 				} else {
 					base := fmt.Sprintf("%s%s", resp.Name, strings.Title(view))
-					respData["RespName"] = codegen.Goify(base, true)
+					viewData["RespName"] = codegen.Goify(base, true)
 				}
-				if err := w.ExecuteTemplate("response", ctxMTRespT, fn, respData); err != nil {
+				if err := w.ExecuteTemplate("response", ctxMTRespT, fn, viewData); err != nil {
 					return err
 				}
+				if data.Pagination != nil && resp.Name == design.OK && projected.Type.IsArray() {
+					suffix := strings.TrimPrefix(viewData["RespName"].(string), codegen.Goify(resp.Name, true))
+					viewData["PageName"] = strings.TrimSuffix(data.Name, "Context") + suffix + "Page"
+					viewData["Pagination"] = data.Pagination
+					if err := w.ExecuteTemplate("page", pageT, fn, viewData); err != nil {
+						return err
+					}
+				}
 			}
 			return nil
 		}
@@ -298,11 +387,16 @@ func NewControllersWriter(filename string) (*ControllersWriter, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newControllersWriter(file), nil
+}
+
+// newControllersWriter returns a handlers code writer that writes to w.
+func newControllersWriter(w ir.Writer) *ControllersWriter {
 	return &ControllersWriter{
-		SourceFile: file,
-		Finalizer:  codegen.NewFinalizer(),
-		Validator:  codegen.NewValidator(),
-	}, nil
+		Writer:    w,
+		Finalizer: codegen.NewFinalizer(),
+		Validator: codegen.NewValidator(),
+	}
 }
 
 // WriteInitService writes the initService function
@@ -315,6 +409,12 @@ func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTempla
 	return w.ExecuteTemplate("service", serviceT, nil, ctx)
 }
 
+// WriteVersionSelector writes the selector that dispatches the requests to the controllers of the
+// API version they select.
+func (w *ControllersWriter) WriteVersionSelector(api *design.APIDefinition) error {
+	return w.ExecuteTemplate("versionSelector", versionSelectorT, nil, api)
+}
+
 // Execute writes the handlers GoGenerator
 func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 	if len(data) == 0 {
@@ -333,9 +433,10 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 			}
 		}
 		fn := template.FuncMap{
-			"newCoerceData":  newCoerceData,
-			"finalizeCode":   w.Finalizer.Code,
-			"validationCode": w.Validator.Code,
+			"newCoerceData":      newCoerceData,
+			"finalizeCode":       w.Finalizer.Code,
+			"validationCode":     w.Validator.Code,
+			"hasValidationFuncs": codegen.HasValidationFuncs,
 		}
 		if err := w.ExecuteTemplate("unmarshal", unmarshalT, fn, d); err != nil {
 			return err
@@ -351,7 +452,7 @@ func NewSecurityWriter(filename string) (*SecurityWriter, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &SecurityWriter{SourceFile: file}, nil
+	return &SecurityWriter{Writer: file}, nil
 }
 
 // Execute adds the different security schemes and middleware supporting functions.
@@ -366,7 +467,7 @@ func NewResourcesWriter(filename string) (*ResourcesWriter, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ResourcesWriter{SourceFile: file}, nil
+	return &ResourcesWriter{Writer: file}, nil
 }
 
 // Execute writes the code for the context types to the writer.
@@ -381,7 +482,12 @@ func NewMediaTypesWriter(filename string) (*MediaTypesWriter, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &MediaTypesWriter{SourceFile: file, Validator: codegen.NewValidator()}, nil
+	return newMediaTypesWriter(file), nil
+}
+
+// newMediaTypesWriter returns a media types code writer that writes to w.
+func newMediaTypesWriter(w ir.Writer) *MediaTypesWriter {
+	return &MediaTypesWriter{Writer: w, Validator: codegen.NewValidator()}
 }
 
 // Execute writes the code for the context types to the writer.
@@ -418,11 +524,16 @@ func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newUserTypesWriter(file), nil
+}
+
+// newUserTypesWriter returns a user types code writer that writes to w.
+func newUserTypesWriter(w ir.Writer) *UserTypesWriter {
 	return &UserTypesWriter{
-		SourceFile: file,
-		Finalizer:  codegen.NewFinalizer(),
-		Validator:  codegen.NewValidator(),
-	}, nil
+		Writer:    w,
+		Finalizer: codegen.NewFinalizer(),
+		Validator: codegen.NewValidator(),
+	}
 }
 
 // Execute writes the code for the context types to the writer.
@@ -431,26 +542,118 @@ func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
 	}
+	if t.IsUnion() {
+		fn["unionVariants"] = unionVariants
+		return w.ExecuteTemplate("types", unionT, fn, t)
+	}
+	if t.IsEnum() {
+		fn["enumValueNames"] = codegen.EnumValueNames
+		fn["isString"] = func() bool { return t.Type.Kind() == design.StringKind }
+		return w.ExecuteTemplate("types", enumT, fn, t)
+	}
 	return w.ExecuteTemplate("types", userTypeT, fn, t)
 }
 
+// NewNullableTypesWriter returns a nullable types code writer.
+// Nullable types hold the values of the attributes defined with "Nullable".
+func NewNullableTypesWriter(filename string) (*NullableTypesWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return newNullableTypesWriter(file), nil
+}
+
+// newNullableTypesWriter returns a nullable types code writer that writes to w.
+func newNullableTypesWriter(w ir.Writer) *NullableTypesWriter {
+	return &NullableTypesWriter{Writer: w}
+}
+
+// Execute writes the code for the nullable types of the given attributes to the writer, see
+// codegen.NullableTypes.
+func (w *NullableTypesWriter) Execute(atts []*design.AttributeDefinition) error {
+	fn := template.FuncMap{
+		"nullableTypeName": codegen.NullableTypeName,
+		"stringEncoded":    func(att *design.AttributeDefinition) bool { return att.NumericFormat() == "int64" },
+	}
+	return w.ExecuteTemplate("nullable", nullableT, fn, atts)
+}
+
 // newCoerceData is a helper function that creates a map that can be given to the "Coerce" template.
 func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, pkg string, depth int) map[string]interface{} {
+	varName := codegen.Goify(name, false)
+	parse, conv := numericCoercion(att, "raw"+codegen.Goify(name, true))
+	value := varName
+	if conv != "" {
+		value = fmt.Sprintf("%s(%s)", conv, varName)
+	}
 	return map[string]interface{}{
 		"Name":      name,
-		"VarName":   codegen.Goify(name, false),
+		"VarName":   varName,
 		"Pointer":   pointer,
 		"Attribute": att,
 		"Pkg":       pkg,
 		"Depth":     depth,
+		"Parse":     parse,
+		"Value":     value,
 	}
 }
 
+// numericCoercion returns the strconv function call that parses the raw value of an Integer or
+// Number attribute and the conversion needed to produce a value of the attribute Go type if any.
+func numericCoercion(att *design.AttributeDefinition, raw string) (parse, conv string) {
+	switch att.Type.Kind() {
+	case design.IntegerKind:
+		switch f := att.NumericFormat(); f {
+		case "int32":
+			return fmt.Sprintf("strconv.ParseInt(%s, 10, 32)", raw), f
+		case "int64":
+			return fmt.Sprintf("strconv.ParseInt(%s, 10, 64)", raw), ""
+		case "uint":
+			return fmt.Sprintf("strconv.ParseUint(%s, 10, 0)", raw), f
+		case "uint32":
+			return fmt.Sprintf("strconv.ParseUint(%s, 10, 32)", raw), f
+		case "uint64":
+			return fmt.Sprintf("strconv.ParseUint(%s, 10, 64)", raw), ""
+		default:
+			return fmt.Sprintf("strconv.Atoi(%s)", raw), ""
+		}
+	case design.NumberKind:
+		if att.NumericFormat() == "float32" {
+			return fmt.Sprintf("strconv.ParseFloat(%s, 32)", raw), "float32"
+		}
+		return fmt.Sprintf("strconv.ParseFloat(%s, 64)", raw), ""
+	}
+	return "", ""
+}
+
 // arrayAttribute returns the array element attribute definition.
 func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
 	return a.Type.(*design.Array).ElemType
 }
 
+// unionVariant is a union variant as rendered by the "types" template.
+type unionVariant struct {
+	// Name is the variant name.
+	Name string
+	// Attribute is the variant object definition.
+	Attribute *design.AttributeDefinition
+}
+
+// unionVariants returns the variants of the given union user type sorted by name.
+func unionVariants(t *design.UserTypeDefinition) []*unionVariant {
+	u := t.ToUnion()
+	variants := make([]*unionVariant, len(u.Variants))
+	for i, n := range u.VariantNames() {
+		att := u.Variants[n]
+		if ds, ok := att.Type.(design.DataStructure); ok {
+			att = ds.Definition()
+		}
+		variants[i] = &unionVariant{Name: n, Attribute: att}
+	}
+	return variants
+}
+
 const (
 	// ctxT generates the code for the context data type.
 	// template input: *ContextTemplateData
@@ -460,9 +663,11 @@ type {{ .Name }} struct {
 	*goa.ResponseData
 	*goa.RequestData
 {{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ if not ($.HasParamAndHeader $name) }}{{/*
-*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
-{{ end }}{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
-*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
+*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ gonativeatt $att }}
+{{ end }}{{ end }}{{ end }}{{ if .Cookies }}{{ range $name, $att := .Cookies.Type.ToObject }}{{/*
+*/}}	{{ goifyatt $att $name true }} {{ if $.Cookies.IsPrimitivePointer $name }}*{{ end }}{{ gonativeatt $att }}
+{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
+*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gonativeatt $att }}
 {{ end }}{{ end }}{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
 {{ end }}}
 `
@@ -483,25 +688,27 @@ type {{ .Name }} struct {
 
 */}}{{/* IntegerType */}}{{/*
 */}}{{ $tmp := tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.Atoi(raw{{ goify .Name true }}); err2 == nil {
-{{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ .VarName }}
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := {{ .Parse }}; err2 == nil {
+{{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ .Value }}
 {{ tabs .Depth }}	{{ $tmp }} := &{{ $tmp2 }}
 {{ tabs .Depth }}	{{ .Pkg }} = {{ $tmp }}
-{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ .VarName }}
+{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ .Value }}
 {{ end }}{{ tabs .Depth }}} else {
 {{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "integer"))
 {{ tabs .Depth }}}
 {{ end }}{{ if eq .Attribute.Type.Kind 3 }}{{/*
 
 */}}{{/* NumberType */}}{{/*
-*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseFloat(raw{{ goify .Name true }}, 64); err2 == nil {
-{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+*/}}{{ if eq .Attribute.NumericFormat "decimal" }}{{ tabs .Depth }}{{ .Pkg }} = {{ if .Pointer }}&{{ end }}raw{{ goify .Name true }}
+{{ else }}{{ $varName := or (and (not .Pointer) .Value) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := {{ .Parse }}; err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ if eq .Value .VarName }}{{ $varName }} := &{{ .VarName }}{{ else }}{{ $tmp := tempvar }}{{ $tmp }} := {{ .Value }}
+{{ tabs .Depth }}	{{ $varName }} := &{{ $tmp }}{{ end }}
 {{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
 {{ tabs .Depth }}} else {
 {{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "number"))
 {{ tabs .Depth }}}
-{{ end }}{{ if eq .Attribute.Type.Kind 4 }}{{/*
+{{ end }}{{ end }}{{ if eq .Attribute.Type.Kind 4 }}{{/*
 
 */}}{{/* StringType */}}{{/*
 */}}{{ tabs .Depth }}{{ .Pkg }} = {{ if .Pointer }}&{{ end }}raw{{ goify .Name true }}
@@ -574,14 +781,26 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 {{ end }}	}
 {{ end }}{{ end }}{{/* if .Headers }}{{/*
 
+*/}}{{ if .Cookies }}{{ range $name, $att := .Cookies.Type.ToObject }}	cookie{{ goify $name true }}, _ := req.Cookie("{{ $name }}")
+{{ if $.Cookies.IsRequired $name }}	if cookie{{ goify $name true }} == nil {
+		err = goa.MergeErrors(err, goa.MissingCookieError("{{ $name }}"))
+	} else {
+{{ else }}	if cookie{{ goify $name true }} != nil {
+{{ end }}		raw{{ goify $name true }} := cookie{{ goify $name true }}.Value
+{{ template "Coerce" (newCoerceData $name $att ($.Cookies.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{/*
+*/}}{{ $validation := validationChecker $att ($.Cookies.IsNonZero $name) ($.Cookies.IsRequired $name) ($.Cookies.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
+*/}}{{ if $validation }}{{ $validation }}
+{{ end }}	}
+{{ end }}{{ end }}{{/* if .Cookies */}}{{/*
+
 */}}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
 */}}	param{{ goify $name true }} := req.Params["{{ $name }}"]
 {{ $mustValidate := $.MustValidate $name }}{{ if $mustValidate }}	if len(param{{ goify $name true }}) == 0 {
-		{{ if $.Params.HasDefaultValue $name }}{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}{{else}}{{/*
+		{{ if $.Params.HasDefaultValue $name }}{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att $att.DefaultValue }}{{else}}{{/*
 */}}err = goa.MergeErrors(err, goa.MissingParamError("{{ $name }}")){{end}}
 	} else {
 {{ else }}{{ if $.Params.HasDefaultValue $name }}	if len(param{{ goify $name true }}) == 0 {
-		{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}
+		{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att $att.DefaultValue }}
 	} else {
 {{ else }}	if len(param{{ goify $name true }}) > 0 {
 {{ end }}{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}{{ if eq (arrayAttribute $att).Type.Kind 4 }}		params := param{{ goify $name true }}
@@ -609,8 +828,104 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 {{ if .Projected.Type.IsArray }}	if r == nil {
 		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
 	}
+{{ end }}{{ if and .Context.SparseFieldsets (eq .Response.Name "OK") }}	if ctx.Fields != nil && ctx.ResponseData.Service.EncodesJSON(ctx.Request, "{{ .Context.DefaultEncoding }}") {
+		return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, goa.SelectFields(r, goa.ParseFields(*ctx.Fields)))
+	}
 {{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
 }
+`
+
+	// ctxSortT generates the method that parses the sort parameter.
+	// template input: *ContextTemplateData
+	ctxSortT = `// SortKeys returns the attributes to sort the results by as listed in the "sort" parameter.
+func (ctx *{{ .Name }}) SortKeys() []goa.SortKey {
+	if ctx.Sort == nil {
+		return nil
+	}
+	return goa.ParseSort(*ctx.Sort)
+}
+`
+
+	// ctxFiltersT generates the filters struct and the method that initializes it.
+	// template input: *ContextTemplateData
+	ctxFiltersT = `// {{ .FiltersName }} holds the values of the "filter[attribute][operator]" parameters of the
+// {{ .ResourceName }} {{ .ActionName }} action.
+type {{ .FiltersName }} struct {
+{{ range .Filters }}	// {{ .Name }} holds the filters of the {{ .Attribute }} attribute.
+	{{ .Name }} {{ .TypeName }}
+{{ end }}}
+{{ range .Filters }}
+// {{ .TypeName }} holds the values of the filters of the {{ .Attribute }} attribute, the values of
+// the filters that are not set are nil.
+type {{ .TypeName }} struct {
+{{ range .Operators }}	{{ .Name }} {{ .Type }}
+{{ end }}}
+{{ end }}
+// Filters returns the filters listed in the "filter[attribute][operator]" parameters.
+func (ctx *{{ .Name }}) Filters() *{{ .FiltersName }} {
+	var f {{ .FiltersName }}
+{{ range $f := .Filters }}{{ range .Operators }}	f.{{ $f.Name }}.{{ .Name }} = ctx.{{ .Field }}
+{{ end }}{{ end }}	return &f
+}
+`
+
+	// pageT generates the response helpers of paginated actions.
+	// template input: map[string]interface{}
+	pageT = `{{ $page := gotyperef .Projected .Projected.AllRequired 0 false }}{{ $cursor := eq .Pagination.Strategy "cursor" }}{{/*
+*/}}{{ if .Pagination.Envelope }}// {{ .PageName }} is the envelope of a page of results sent by {{ .RespName }}Page.
+type {{ .PageName }} struct {
+	// Page of results
+	Items {{ $page }} ` + "`" + `form:"items" json:"items" xml:"items"` + "`" + `
+{{ if $cursor }}	// Cursor of the next page, absent on the last page
+	NextCursor *string ` + "`" + `form:"next_cursor,omitempty" json:"next_cursor,omitempty" xml:"next_cursor,omitempty"` + "`" + `
+{{ else }}	// Position of the first result of the page in the collection
+	Offset int ` + "`" + `form:"offset" json:"offset" xml:"offset"` + "`" + `
+	// Maximum number of results in the page
+	Limit int ` + "`" + `form:"limit" json:"limit" xml:"limit"` + "`" + `
+	// Total number of results in the collection, absent if unknown
+	Total *int ` + "`" + `form:"total,omitempty" json:"total,omitempty" xml:"total,omitempty"` + "`" + `
+{{ end }}}
+
+{{ end }}{{ if $cursor }}{{ if .Pagination.Envelope }}{{/*
+*/}}// {{ .RespName }}Page sends a HTTP response with status code {{ .Response.Status }} containing a page of results wrapped together
+// with the cursor of the next page. An empty cursor indicates the last page.
+{{ else }}{{/*
+*/}}// {{ .RespName }}Page sends a HTTP response with status code {{ .Response.Status }} containing a page of results and a Link
+// header pointing to the page identified by the cursor next. An empty cursor indicates the last page.
+{{ end }}func (ctx *{{ .Context.Name }}) {{ .RespName }}Page(r {{ $page }}, next string) error {
+{{ else }}{{ if .Pagination.Envelope }}{{/*
+*/}}// {{ .RespName }}Page sends a HTTP response with status code {{ .Response.Status }} containing a page of results wrapped together
+// with its position in the collection. total is the number of results in the collection, negative
+// if unknown.
+{{ else }}{{/*
+*/}}// {{ .RespName }}Page sends a HTTP response with status code {{ .Response.Status }} containing a page of results and Link headers
+// pointing to the neighboring pages. total is the number of results in the collection, negative if
+// unknown.
+{{ end }}func (ctx *{{ .Context.Name }}) {{ .RespName }}Page(r {{ $page }}, total int) error {
+{{ end }}{{ if .Pagination.Envelope }}	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
+	}
+	if r == nil {
+		r = {{ $page }}{}
+	}
+{{ if $cursor }}	page := &{{ .PageName }}{Items: r}
+	if next != "" {
+		page.NextCursor = &next
+	}
+{{ else }}	page := &{{ .PageName }}{Items: r, Offset: ctx.Offset, Limit: ctx.Limit}
+	if total >= 0 {
+		page.Total = &total
+	}
+{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, page)
+{{ else }}{{ if $cursor }}	if next != "" {
+		ctx.ResponseData.Header().Set("Link", goa.CursorPageLink(ctx.Request.URL, next))
+	}
+{{ else }}	ctx.ResponseData.Header().Set("Link", goa.OffsetPageLinks(ctx.Request.URL, ctx.Offset, ctx.Limit, len(r), total))
+	if total >= 0 {
+		ctx.ResponseData.Header().Set("X-Total-Count", strconv.Itoa(total))
+	}
+{{ end }}	return ctx.{{ .RespName }}(r)
+{{ end }}}
 `
 
 	// ctxTRespT generates the response helpers for responses with overridden types.
@@ -665,13 +980,27 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Publicize()
 
 // {{ gotypename .Payload nil 0 false }} is the {{ .ResourceName }} {{ .ActionName }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
-
+{{ if .Payload.IsObject }}{{ nullableMarshaler (gotypename .Payload nil 1 false) .Payload }}{{ end }}
 {{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
 `
+	// patchT generates the method that applies a patch action payload.
+	// template input: *ContextTemplateData
+	patchT = `{{ $target := gotyperef .Patch.Target nil 0 false }}
+// Apply applies the patch to v and validates the result against the design.
+func (payload {{ gotypename .Payload nil 0 false }}) Apply(v {{ $target }}) error {
+	if err := goa.Apply{{ if .Patch.IsMergePatch }}MergePatch{{ else }}JSONPatch{{ end }}(payload, v); err != nil {
+		return err
+	}
+{{ if validationCode .Patch.Target.AttributeDefinition false false false "v" "response" 1 false }}{{/*
+*/}}	return v.Validate()
+{{ else }}	return nil
+{{ end }}}
+`
+
 	// ctrlT generates the controller interface for a given resource.
 	// template input: *ControllerTemplateData
 	ctrlT = `// {{ .Resource }}Controller is the controller interface for the {{ .Resource }} actions.
@@ -702,15 +1031,27 @@ func initService(service *goa.Service) {
 {{ end }}{{ end }}}
 `
 
+	// versionSelectorT generates the selector of the API version requested by a request.
+	// template input: *design.APIDefinition
+	versionSelectorT = `{{ if eq .VersionScheme "header" }}
+// apiVersion selects the API version requested with the {{ printf "%q" .VersionKey }} header, {{ printf "%q" .Version }} by default.
+var apiVersion = goa.HeaderVersion({{ printf "%q" .VersionKey }}, {{ printf "%q" .Version }})
+{{ else }}
+// apiVersion selects the API version requested with the {{ printf "%q" .VersionKey }} parameter of the
+// media types listed in the Accept header, {{ printf "%q" .Version }} by default.
+var apiVersion = goa.MediaTypeVersion({{ printf "%q" .VersionKey }}, {{ printf "%q" .Version }})
+{{ end }}`
+
 	// mountT generates the code for a resource "Mount" function.
 	// template input: *ControllerTemplateData
-	mountT = `
+	mountT = `{{ define "HandleSecurity" }}` + handleSecurityT + `{{ end }}` + `
 // Mount{{ .Resource }}Controller "mounts" a {{ .Resource }} resource controller on the given service.
 func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Controller) {
 	initService(service)
 	var h goa.Handler
-{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
-*/}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
+{{ $res := .Resource }}{{ if .Origins }}	handleOrigin := cors.Middleware({{ $res }}CORS)
+{{ range .PreflightPaths }}{{/*
+*/}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handleOrigin(cors.HandlePreflight()), nil))
 {{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
@@ -730,48 +1071,48 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}		}
 {{ end }}		return ctrl.{{ .Name }}(rctx)
 	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
-{{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
-	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
+{{ if .Security }}{{ template "HandleSecurity" .Security }}{{ end }}{{ if $.Origins }}	h = handleOrigin(h)
+{{ end }}{{ with .Deprecation }}	h = goa.DeprecatedHandler(h, {{ printf "%q" .SunsetHeader }})
+{{ end }}{{ range .Routes }}	{{ if $.Version }}service.HandleVersion(apiVersion, {{ printf "%q" $.Version }}, {{ else }}service.Mux.Handle({{ end }}"{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ if $action.PreserveBody }}goa.PreserveBody({{ $action.Unmarshal }}){{ else }}{{ $action.Unmarshal }}{{ end }}{{ else }}nil{{ end }}))
+	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $.Version }}, "version", {{ printf "%q" . }}{{ end }}{{ with $action.Security }}, "security", {{ printf "%q" .String }}{{ end }})
 {{ end }}{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
-{{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
+{{ if .Security }}{{ template "HandleSecurity" .Security }}{{ end }}{{ if $.Origins }}	h = handleOrigin(h)
 {{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
-	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
+	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .String }}{{ end }})
 {{ end }}}
 `
 
+	// handleSecurityT generates the code that wraps the handler "h" with the auth middlewares.
+	// template input: *design.SecurityDefinition
+	handleSecurityT = `{{ if .IsComposite }}	h = handleSecurityAlternatives(h,
+{{ range .Requirements }}		securityRequirement{
+			schemes: []string{ {{- range $i, $scheme := .AllSchemes }}{{ if $i }}, {{ end }}{{ printf "%q" $scheme.SchemeName }}{{ end -}} },
+{{ if .Scopes }}			scopes:  []string{ {{- range $i, $scope := .Scopes }}{{ if $i }}, {{ end }}{{ printf "%q" $scope }}{{ end -}} },
+{{ end }}		},
+{{ end }}	)
+{{ else }}	h = handleSecurity({{ printf "%q" .Scheme.SchemeName }}, h{{ range .Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}`
+
 	// handleCORST generates the code that checks whether a CORS request is authorized
 	// template input: *ControllerTemplateData
-	handleCORST = `// handle{{ .Resource }}Origin applies the CORS response headers corresponding to the origin.
-func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
-{{ range $i, $policy := .Origins }}{{ if $policy.Regexp }}	spec{{$i}} := regexp.MustCompile({{ printf "%q" $policy.Origin }})
-{{ end }}{{ end }}
-	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		origin := req.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			return h(ctx, rw, req)
-		}
-{{ range $i, $policy := .Origins }}		{{ if $policy.Regexp }}if cors.MatchOriginRegexp(origin, spec{{$i}}){{else}}if cors.MatchOrigin(origin, {{ printf "%q" $policy.Origin }}){{end}} {
-			ctx = goa.WithLogContext(ctx, "origin", origin)
-			rw.Header().Set("Access-Control-Allow-Origin", origin)
-{{ if not (eq $policy.Origin "*") }}			rw.Header().Set("Vary", "Origin")
-{{ end }}{{ if $policy.Exposed }}			rw.Header().Set("Access-Control-Expose-Headers", "{{ join $policy.Exposed ", " }}")
-{{ end }}{{ if gt $policy.MaxAge 0 }}			rw.Header().Set("Access-Control-Max-Age", "{{ $policy.MaxAge }}")
-{{ end }}			rw.Header().Set("Access-Control-Allow-Credentials", "{{ $policy.Credentials }}")
-			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-{{ if $policy.Methods }}				rw.Header().Set("Access-Control-Allow-Methods", "{{ join $policy.Methods ", " }}")
-{{ end }}{{ if $policy.Headers }}				rw.Header().Set("Access-Control-Allow-Headers", "{{ join $policy.Headers ", " }}")
-{{ end }}			}
-			return h(ctx, rw, req)
-		}
-{{ end }}
-		return h(ctx, rw, req)
-	}
+	handleCORST = `// {{ .Resource }}CORS is the CORS configuration of the {{ .Resource }} controller as defined in the
+// design. It may be modified before the controller is mounted, e.g. to load the allowed origins from
+// the service configuration. Mount{{ .Resource }}Controller builds the CORS middleware used by all the
+// actions from it.
+var {{ .Resource }}CORS = cors.Config{
+	Policies: []*cors.Policy{
+{{ range .Origins }}		{
+			Origin: {{ printf "%q" .Origin }},
+{{ if .Regexp }}			Regexp: true,
+{{ end }}{{ if .Methods }}			Methods: []string{ {{- range $i, $m := .Methods }}{{ if $i }}, {{ end }}{{ printf "%q" $m }}{{ end -}} },
+{{ end }}{{ if .Headers }}			Headers: []string{ {{- range $i, $h := .Headers }}{{ if $i }}, {{ end }}{{ printf "%q" $h }}{{ end -}} },
+{{ end }}{{ if .Exposed }}			Exposed: []string{ {{- range $i, $h := .Exposed }}{{ if $i }}, {{ end }}{{ printf "%q" $h }}{{ end -}} },
+{{ end }}{{ if gt .MaxAge 0 }}			MaxAge: {{ .MaxAge }},
+{{ end }}{{ if .Credentials }}			Credentials: true,
+{{ end }}{{ if .PrivateNetwork }}			PrivateNetwork: true,
+{{ end }}		},
+{{ end }}	},
 }
 `
 
@@ -788,7 +1129,7 @@ func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Reque
 {{ template "Coerce" (newCoerceData $name $att true (printf "payload.%s" (goifyatt $att $name true)) 1) }}{{ end }}{{/*
 */}}	if err != nil {
 		return err
-	}{{ else if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
+	}{{ else if or .Payload.IsObject .Payload.IsUnion }}payload := &{{ gotypename .Payload nil 1 true }}{}
 	if err := service.DecodeRequest(req, payload); err != nil {
 		return err
 	}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
@@ -796,15 +1137,73 @@ func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Reque
 	if err := service.DecodeRequest(req, &payload); err != nil {
 		return err
 	}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 true }}{{ if $validation }}
-	if err := payload.Validate(); err != nil {
+	if err := {{ if .Validate }}{{ .Validate }}(payload){{ else }}payload.Validate(){{ end }}; err != nil {
 		// Initialize payload with private data structure so it can be logged
 		goa.ContextRequest(ctx).Payload = payload
 		return err
 	}{{ end }}
-	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}
+{{ if and .Payload.IsObject (hasValidationFuncs .Payload.AttributeDefinition) }}	pub := payload.Publicize()
+	goa.ContextRequest(ctx).Payload = pub
+	return {{ if .Validate }}{{ .Validate }}Funcs(pub){{ else }}pub.Validate(){{ end }}
+{{ else }}	goa.ContextRequest(ctx).Payload = payload{{ if or .Payload.IsObject .Payload.IsUnion }}.Publicize(){{ end }}
 	return nil
+{{ end }}}
+{{ if .Validate }}{{ if $validation }}
+// {{ .Validate }} validates the payload against the version of the API served by the
+// controller.
+func {{ .Validate }}(payload {{ gotyperef .Payload nil 1 true }}) (err error) {
+{{ $validation }}
+	return
 }
-{{ end }}
+{{ end }}{{ if hasValidationFuncs .Payload.AttributeDefinition }}
+// {{ .Validate }}Funcs validates the public payload against the version of the API served by the
+// controller.
+func {{ .Validate }}Funcs(payload {{ gotyperef .Payload nil 1 false }}) (err error) {
+{{ validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}
+	return
+}
+{{ end }}{{ end }}{{ end }}
+{{ end }}`
+
+	// nullableT generates the code for the types holding the values of nullable attributes.
+	// template input: []*design.AttributeDefinition
+	nullableT = `{{ range . }}{{ $name := nullableTypeName . }}{{ $value := gotypedef . 0 false false }}
+// {{ $name }} holds the value of a nullable attribute of type {{ $value }}. Set is false if the
+// attribute is absent and Null is true if it is explicitly set to null.
+type {{ $name }} struct {
+	Value {{ $value }}
+	Set   bool
+	Null  bool
+}
+
+// UnmarshalJSON records that the attribute is set and whether it is null.
+func (n *{{ $name }}) UnmarshalJSON(b []byte) error {
+	n.Set = true
+	if string(b) == "null" {
+		n.Null = true
+		return nil
+	}
+{{ if stringEncoded . }}	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	n.Value = v
+	return nil
+{{ else }}	return json.Unmarshal(b, &n.Value)
+{{ end }}}
+
+// MarshalJSON encodes null unless the attribute is set to a value.
+func (n {{ $name }}) MarshalJSON() ([]byte, error) {
+	if !n.Set || n.Null {
+		return []byte("null"), nil
+	}
+{{ if stringEncoded . }}	return json.Marshal(strconv.FormatInt(n.Value, 10))
+{{ else }}	return json.Marshal(n.Value)
+{{ end }}}
 {{ end }}`
 
 	// resourceT generates the code for a resource.
@@ -823,7 +1222,7 @@ func {{ .Name }}Href({{ if .CanonicalParams }}{{ join .CanonicalParams ", " }} i
 //
 // Identifier: {{ .Identifier }}{{ $typeName := gotypename . .AllRequired 0 false }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-
+{{ if .IsObject }}{{ nullableMarshaler $typeName . }}{{ end }}
 {{ $validation := validationCode .AttributeDefinition false false false "mt" "response" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} media type instance.
 func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
@@ -836,7 +1235,7 @@ func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 	// template input: MediaTypeLinkTemplateData
 	mediaTypeLinkT = `// {{ gotypedesc . true }}{{ $typeName := gotypename . .AllRequired 0 false }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "response" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
+{{ if .IsObject }}{{ nullableMarshaler $typeName . }}{{ end }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "response" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
@@ -866,11 +1265,201 @@ func (ut {{ gotyperef . .AllRequired 0 true }}) Publicize() {{ gotyperef . .AllR
 
 // {{ gotypedesc . true }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
+{{ if .IsObject }}{{ nullableMarshaler $typeName . }}{{ end }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
+`
+
+	// enumT generates the code for an enum user type: a named type with one constant per value.
+	// template input: *design.UserTypeDefinition
+	enumT = `{{ $typeName := gotypename . nil 0 false }}{{ $names := enumValueNames . }}// {{ gotypedesc . true }}
+type {{ $typeName }} {{ gotypedef . 0 true false }}
+
+// {{ $typeName }} values.
+const (
+{{ range $i, $v := .Validation.Values }}	// {{ $typeName }}{{ index $names $i }} is the {{ printf "%#v" $v }} {{ $typeName }} value.
+	{{ $typeName }}{{ index $names $i }} {{ $typeName }} = {{ printf "%#v" $v }}
+{{ end }})
+
+// Valid returns true if v is one of the {{ $typeName }} values.
+func (v {{ $typeName }}) Valid() bool {
+	switch v {
+	case {{ range $i, $n := $names }}{{ if $i }}, {{ end }}{{ $typeName }}{{ $n }}{{ end }}:
+		return true
+	}
+	return false
+}
+
+// String returns the text representation of v.
+func (v {{ $typeName }}) String() string {
+	return {{ if isString }}string(v){{ else }}strconv.Itoa(int(v)){{ end }}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v {{ $typeName }}) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts values that are not one of the
+// {{ $typeName }} values so that clients can decode values added later to the API, use Valid or
+// the Validate methods to check them.
+func (v *{{ $typeName }}) UnmarshalText(text []byte) error {
+{{ if isString }}	*v = {{ $typeName }}(text)
+{{ else }}	i, err := strconv.Atoi(string(text))
+	if err != nil {
+		return goa.InvalidAttributeTypeError(` + "`" + `{{ $typeName }}` + "`" + `, string(text), "integer")
+	}
+	*v = {{ $typeName }}(i)
+{{ end }}	return nil
+}
+{{ if not isString }}
+// MarshalJSON implements json.Marshaler, the values are encoded as JSON numbers.
+func (v {{ $typeName }}) MarshalJSON() ([]byte, error) {
+	return v.MarshalText()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *{{ $typeName }}) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	return v.UnmarshalText(data)
+}
+{{ end }}`
+
+	// unionT generates the code for a union user type: a struct wrapping the variant value, the
+	// interface implemented by the variants and a struct per variant.
+	// template input: *design.UserTypeDefinition
+	unionT = `{{ $privateTypeName := gotypename . nil 0 true }}{{ $typeName := gotypename . nil 0 false }}{{/*
+*/}}{{ $discriminator := .ToUnion.Discriminator }}{{ $variants := unionVariants . }}// {{ gotypedesc . false }}
+type {{ $privateTypeName }} struct {
+	// Value is the union variant value.
+	Value {{ $privateTypeName }}Variant
+}
+
+// {{ $privateTypeName }}Variant is implemented by the {{ $privateTypeName }} variants.
+type {{ $privateTypeName }}Variant interface {
+	Validate() error
+	Publicize() {{ $typeName }}Variant
+}
+{{ range $variants }}{{ $variantName := printf "%s%s" $privateTypeName (goify .Name true) }}
+// {{ $variantName }} is the {{ printf "%q" .Name }} variant of {{ $privateTypeName }}.
+type {{ $variantName }} {{ gotypedef .Attribute 0 true true }}
+{{ $assignment := finalizeCode .Attribute "ut" 1 }}{{ if $assignment }}
+// Finalize sets the default values for {{ $variantName }} type instance.
+func (ut *{{ $variantName }}) Finalize() {
+{{ $assignment }}
+}
+{{ end }}
+// Validate validates the {{ $variantName }} type instance.
+func (ut *{{ $variantName }}) Validate() (err error) {
+{{ with validationCode .Attribute false false false "ut" "request" 1 true }}{{ . }}
+{{ end }}	return
+}
+
+// Publicize creates {{ $typeName }}{{ goify .Name true }} from {{ $variantName }}
+func (ut *{{ $variantName }}) Publicize() {{ $typeName }}Variant {
+	var pub {{ $typeName }}{{ goify .Name true }}
+	{{ recursivePublicizer .Attribute "ut" "pub" 1 }}
+	return &pub
+}
+{{ end }}{{ if finalizeCode .AttributeDefinition "ut" 1 }}
+// Finalize sets the default values of the {{ $privateTypeName }} variant value.
+func (ut *{{ $privateTypeName }}) Finalize() {
+	if v, ok := ut.Value.(interface {
+		Finalize()
+	}); ok {
+		v.Finalize()
+	}
+}
+{{ end }}
+// Validate validates the {{ $privateTypeName }} variant value.
+func (ut *{{ $privateTypeName }}) Validate() error {
+	if ut == nil || ut.Value == nil {
+		return nil
+	}
+	return ut.Value.Validate()
+}
+
+// UnmarshalJSON decodes the {{ $privateTypeName }} variant value{{ if $discriminator }} selected by the {{ printf "%q" $discriminator }} attribute{{ end }}.
+func (ut *{{ $privateTypeName }}) UnmarshalJSON(data []byte) error {
+	variants := map[string]interface{}{
+{{ range $variants }}		{{ printf "%q" .Name }}: &{{ $privateTypeName }}{{ goify .Name true }}{},
+{{ end }}	}
+	name, err := goa.UnmarshalUnion(data, {{ printf "%q" $discriminator }}, variants)
+	if err != nil {
+		return err
+	}
+	ut.Value = variants[name].({{ $privateTypeName }}Variant)
+	return nil
+}
+
+// Publicize creates {{ $typeName }} from {{ $privateTypeName }}
+func (ut *{{ $privateTypeName }}) Publicize() *{{ $typeName }} {
+	var pub {{ $typeName }}
+	if ut.Value != nil {
+		pub.Value = ut.Value.Publicize()
+	}
+	return &pub
+}
+
+// {{ gotypedesc . true }}
+type {{ $typeName }} struct {
+	// Value is the union variant value.
+	Value {{ $typeName }}Variant
+}
+
+// {{ $typeName }}Variant is implemented by the {{ $typeName }} variants.
+type {{ $typeName }}Variant interface {
+	// Variant returns the name of the variant.
+	Variant() string
+	Validate() error
+}
+{{ range $variants }}{{ $variantName := printf "%s%s" $typeName (goify .Name true) }}
+// {{ $variantName }} is the {{ printf "%q" .Name }} variant of {{ $typeName }}.
+type {{ $variantName }} {{ gotypedef .Attribute 0 true false }}
+{{ nullableMarshaler $variantName .Attribute }}
+// Variant returns the name of the {{ $variantName }} variant.
+func (ut *{{ $variantName }}) Variant() string {
+	return {{ printf "%q" .Name }}
+}
+
+// Validate validates the {{ $variantName }} type instance.
+func (ut *{{ $variantName }}) Validate() (err error) {
+{{ with validationCode .Attribute false false false "ut" "type" 1 false }}{{ . }}
+{{ end }}	return
+}
+{{ end }}
+// Validate validates the {{ $typeName }} variant value.
+func (ut *{{ $typeName }}) Validate() error {
+	if ut == nil || ut.Value == nil {
+		return nil
+	}
+	return ut.Value.Validate()
+}
+
+// MarshalJSON encodes the {{ $typeName }} variant value{{ if $discriminator }} together with the {{ printf "%q" $discriminator }} attribute{{ end }}.
+func (ut {{ $typeName }}) MarshalJSON() ([]byte, error) {
+	if ut.Value == nil {
+		return []byte("null"), nil
+	}
+	return goa.MarshalUnion(ut.Value, {{ printf "%q" $discriminator }}, ut.Value.Variant())
+}
+
+// UnmarshalJSON decodes the {{ $typeName }} variant value{{ if $discriminator }} selected by the {{ printf "%q" $discriminator }} attribute{{ end }}.
+func (ut *{{ $typeName }}) UnmarshalJSON(data []byte) error {
+	variants := map[string]interface{}{
+{{ range $variants }}		{{ printf "%q" .Name }}: &{{ $typeName }}{{ goify .Name true }}{},
+{{ end }}	}
+	name, err := goa.UnmarshalUnion(data, {{ printf "%q" $discriminator }}, variants)
+	if err != nil {
+		return err
+	}
+	ut.Value = variants[name].({{ $typeName }}Variant)
+	return nil
+}
 `
 
 	// securitySchemesT generates the code for the security module.
@@ -879,6 +1468,13 @@ func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 type (
 	// Private type used to store auth handler info in request context
 	authMiddlewareKey string
+
+	// securityRequirement lists the security schemes that must all be satisfied to authorize
+	// a request together with the scopes they require.
+	securityRequirement struct {
+		schemes []string
+		scopes  []string
+	}
 )
 
 {{ range . }}
@@ -928,5 +1524,38 @@ func handleSecurity(schemeName string, h goa.Handler, scopes ...string) goa.Hand
 		return am(h)(ctx, rw, req)
 	}
 }
+
+// handleSecurityAlternatives creates a handler that tries the given security requirements in
+// order and runs h with the first one satisfied. A requirement is satisfied if the auth middlewares
+// of all its schemes succeed. The errors of all the requirements are aggregated if none is.
+func handleSecurityAlternatives(h goa.Handler, requirements ...securityRequirement) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		var errs []error
+	requirements:
+		for _, r := range requirements {
+			reached := false
+			var handler goa.Handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				reached = true
+				return h(ctx, rw, req)
+			}
+			for i := len(r.schemes) - 1; i >= 0; i-- {
+				am, ok := ctx.Value(authMiddlewareKey(r.schemes[i])).(goa.Middleware)
+				if !ok {
+					// A scheme with no mounted middleware only rules out this alternative.
+					errs = append(errs, goa.NoAuthMiddleware(r.schemes[i]))
+					continue requirements
+				}
+				handler = am(handler)
+			}
+			err := handler(goa.WithRequiredScopes(ctx, r.scopes), rw, req)
+			if err == nil || reached {
+				// Errors returned by h must not trigger the next alternative.
+				return err
+			}
+			errs = append(errs, err)
+		}
+		return goa.UnsatisfiedSecurityError(errs...)
+	}
+}
 `
 )
@@ -3,6 +3,8 @@ package genapp_test
 import (
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/design/apidsl"
@@ -50,7 +52,7 @@ var _ = Describe("ContextsWriter", func() {
 		})
 
 		Context("with data", func() {
-			var params, headers *design.AttributeDefinition
+			var params, headers, cookies *design.AttributeDefinition
 			var payload *design.UserTypeDefinition
 			var responses map[string]*design.ResponseDefinition
 			var routes []*design.RouteDefinition
@@ -60,6 +62,7 @@ var _ = Describe("ContextsWriter", func() {
 			BeforeEach(func() {
 				params = nil
 				headers = nil
+				cookies = nil
 				payload = nil
 				responses = nil
 				routes = nil
@@ -74,6 +77,7 @@ var _ = Describe("ContextsWriter", func() {
 					Params:       params,
 					Payload:      payload,
 					Headers:      headers,
+					Cookies:      cookies,
 					Responses:    responses,
 					Routes:       routes,
 					API:          design.Design,
@@ -178,6 +182,67 @@ var _ = Describe("ContextsWriter", func() {
 	}
 	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
 				})
+
+				Context("and sort fields and sparse fieldsets", func() {
+					It("writes the sort keys method and selects the fields", func() {
+						data.Sortable = true
+						data.SparseFieldsets = true
+						data.DefaultEncoding = "application/json"
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(sortKeys))
+						Ω(written).Should(ContainSubstring(`	if ctx.Fields != nil && ctx.ResponseData.Service.EncodesJSON(ctx.Request, "application/json") {
+		return ctx.ResponseData.Service.Send(ctx.Context, 200, goa.SelectFields(r, goa.ParseFields(*ctx.Fields)))
+	}`))
+					})
+				})
+
+				Context("and filters", func() {
+					It("writes the filters struct and method", func() {
+						data.Filters = []*genapp.ContextFilter{{
+							Name:      "Vintage",
+							TypeName:  "ListBottleVintageFilter",
+							Attribute: "vintage",
+							Operators: []*genapp.ContextFilterOperator{
+								{Name: "Gte", Type: "*int", Field: "FilterVintageGte"},
+								{Name: "Lt", Type: "*int", Field: "FilterVintageLt"},
+							},
+						}}
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(filters))
+					})
+				})
+
+				Context("and cursor pagination", func() {
+					It("writes the page response helper", func() {
+						data.Pagination = &design.PaginationDefinition{Strategy: design.Cursor, DefaultPageSize: 20}
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(cursorPage))
+					})
+				})
+
+				Context("and offset pagination with an envelope", func() {
+					It("writes the page envelope and response helper", func() {
+						data.Pagination = &design.PaginationDefinition{Strategy: design.Offset, DefaultPageSize: 20, Envelope: true}
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(offsetEnvelopePage))
+					})
+				})
 			})
 
 			Context("with an integer param", func() {
@@ -227,6 +292,23 @@ var _ = Describe("ContextsWriter", func() {
 					})
 				})
 
+				Context("with a numeric format", func() {
+					BeforeEach(func() {
+						intParam.Validation = &dslengine.ValidationDefinition{Format: "uint32"}
+					})
+
+					It("writes the integer contexts code", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(BeEmpty())
+						Ω(written).Should(ContainSubstring("	Param *uint32\n"))
+						Ω(written).Should(ContainSubstring(uint32ContextFactory))
+					})
+				})
+
 				Context("with required attribute", func() {
 					BeforeEach(func() {
 						validation.Required = []string{"param"}
@@ -790,6 +872,29 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with cookies", func() {
+				BeforeEach(func() {
+					cookies = &design.AttributeDefinition{
+						Type: design.Object{
+							"session": &design.AttributeDefinition{Type: design.String},
+							"visits":  &design.AttributeDefinition{Type: design.Integer},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"session"}},
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(cookiesContext))
+					Ω(written).Should(ContainSubstring(cookiesContextFactory))
+				})
+			})
+
 			Context("with a string header and param with the same name", func() {
 				BeforeEach(func() {
 					str := &design.AttributeDefinition{Type: design.String}
@@ -837,6 +942,37 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a merge patch payload", func() {
+				var target *design.UserTypeDefinition
+
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					target = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type:       design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+							Validation: &dslengine.ValidationDefinition{Required: []string{"name"}},
+						},
+						TypeName: "Bottle",
+					}
+					payload = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{"name": &design.AttributeDefinition{Type: design.String, Nullable: true}},
+						},
+						TypeName: "ListBottlePayload",
+					}
+				})
+
+				It("writes the patch apply method", func() {
+					data.Patch = &design.PatchDefinition{Format: design.MergePatchContentType, Target: target}
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(patchApply))
+				})
+			})
+
 			Context("with a object payload", func() {
 				BeforeEach(func() {
 					design.Design = new(design.APIDefinition)
@@ -994,16 +1130,23 @@ var _ = Describe("ControllersWriter", func() {
 		})
 
 		Context("with data", func() {
-			var multipart bool
+			var multipart, preserveBody bool
 			var actions, verbs, paths, contexts, unmarshals []string
 			var payloads []*design.UserTypeDefinition
 			var encoders, decoders []*genapp.EncoderTemplateData
 			var origins []*design.CORSDefinition
+			var security *design.SecurityDefinition
+			var deprecation *design.DeprecationDefinition
+			var version string
 
 			var data []*genapp.ControllerTemplateData
 
 			BeforeEach(func() {
+				security = nil
+				deprecation = nil
+				version = ""
 				multipart = false
+				preserveBody = false
 				actions = nil
 				verbs = nil
 				paths = nil
@@ -1021,6 +1164,7 @@ var _ = Describe("ControllersWriter", func() {
 				d := &genapp.ControllerTemplateData{
 					Resource: "Bottles",
 					Origins:  origins,
+					Version:  version,
 				}
 				as := make([]map[string]interface{}, len(actions))
 				for i, a := range actions {
@@ -1044,6 +1188,9 @@ var _ = Describe("ControllersWriter", func() {
 						"Unmarshal":        unmarshal,
 						"Payload":          payload,
 						"PayloadMultipart": multipart,
+						"Security":         security,
+						"PreserveBody":     preserveBody,
+						"Deprecation":      deprecation,
 					}
 				}
 				if len(as) > 0 {
@@ -1088,6 +1235,105 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with a deprecated action of a version", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					deprecation = &design.DeprecationDefinition{Sunset: "2027-06-30"}
+					version = "v1"
+				})
+
+				It("adds the deprecation headers and routes by version", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(versionMount))
+				})
+			})
+
+			Context("with a single security scheme", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					security = &design.SecurityDefinition{
+						Scheme: &design.SecuritySchemeDefinition{SchemeName: "jwt"},
+						Scopes: []string{"api:read"},
+					}
+				})
+
+				It("writes the security handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`h = handleSecurity("jwt", h, "api:read")`))
+					Ω(written).Should(ContainSubstring(`"security", "jwt")`))
+				})
+			})
+
+			Context("with composite security requirements", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					security = &design.SecurityDefinition{
+						Scheme:            &design.SecuritySchemeDefinition{SchemeName: "mtls"},
+						AdditionalSchemes: []*design.SecuritySchemeDefinition{{SchemeName: "jwt"}},
+						Scopes:            []string{"api:read"},
+						Alternatives: []*design.SecurityDefinition{
+							{Scheme: &design.SecuritySchemeDefinition{SchemeName: "api_key"}},
+						},
+					}
+				})
+
+				It("writes the security alternatives handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(compositeSecurityMount))
+					Ω(written).Should(ContainSubstring(`"security", "mtls+jwt|api_key")`))
+				})
+			})
+
+			Context("with an action signed with HTTP message signatures", func() {
+				BeforeEach(func() {
+					actions = []string{"create"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"CreateBottleContext"}
+					unmarshals = []string{"unmarshalCreateBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName:            "CreateBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{Type: design.Object{}},
+						},
+					}
+					security = &design.SecurityDefinition{
+						Scheme: &design.SecuritySchemeDefinition{SchemeName: "sig", Kind: design.HTTPSigSecurityKind},
+					}
+					preserveBody = true
+				})
+
+				It("preserves the request body for the signature verification", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`ctrl.MuxHandler("create", h, goa.PreserveBody(unmarshalCreateBottlePayload))`))
+				})
+			})
+
 			Context("with actions that take a payload", func() {
 				BeforeEach(func() {
 					actions = []string{"list"}
@@ -1276,6 +1522,7 @@ var _ = Describe("ControllersWriter", func() {
 					written := string(b)
 					Ω(written).ShouldNot(BeEmpty())
 					Ω(written).Should(ContainSubstring(originsIntegration))
+					Ω(written).Should(ContainSubstring(originsMiddleware))
 					Ω(written).Should(ContainSubstring(originsHandler))
 				})
 			})
@@ -1563,6 +1810,57 @@ var _ = Describe("UserTypesWriter", func() {
 					Ω(written).Should(ContainSubstring(userTypeIncludingHash))
 				})
 			})
+
+			Context("with a union user type", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: &design.Union{
+							Variants: design.Object{
+								"card": &design.AttributeDefinition{
+									Type: design.Object{
+										"number": &design.AttributeDefinition{
+											Type: design.String,
+										},
+									},
+								},
+							},
+							Discriminator: "kind",
+						},
+					}
+					typeName = "PaymentMethod"
+				})
+				It("writes the union user type code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(unionUserType))
+				})
+			})
+
+			Context("with an enum user type", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: design.String,
+						Validation: &dslengine.ValidationDefinition{
+							Values: []interface{}{"open", "sealed"},
+						},
+						Metadata: dslengine.MetadataDefinition{"enum:names": {"Opened", "Sealed"}},
+					}
+					typeName = "BottleStatus"
+				})
+				It("writes the enum type code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(enumUserType))
+				})
+			})
 		})
 	})
 })
@@ -1620,6 +1918,20 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 }
 `
 
+	uint32ContextFactory = `
+	paramParam := req.Params["param"]
+	if len(paramParam) > 0 {
+		rawParam := paramParam[0]
+		if param, err2 := strconv.ParseUint(rawParam, 10, 32); err2 == nil {
+			tmp2 := uint32(param)
+			tmp1 := &tmp2
+			rctx.Param = tmp1
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("param", rawParam, "integer"))
+		}
+	}
+`
+
 	intDefaultContext = `
 type ListBottleContext struct {
 	context.Context
@@ -2299,6 +2611,74 @@ type ListBottleContext struct {
 	*goa.RequestData
 	Payload *ListBottlePayload
 }
+`
+
+	patchApply = `
+// Apply applies the patch to v and validates the result against the design.
+func (payload ListBottlePayload) Apply(v *Bottle) error {
+	if err := goa.ApplyMergePatch(payload, v); err != nil {
+		return err
+	}
+	return v.Validate()
+}
+`
+
+	filters = `// ListBottleFilters holds the values of the "filter[attribute][operator]" parameters of the
+// bottles list action.
+type ListBottleFilters struct {
+	// Vintage holds the filters of the vintage attribute.
+	Vintage ListBottleVintageFilter
+}
+
+// ListBottleVintageFilter holds the values of the filters of the vintage attribute, the values of
+// the filters that are not set are nil.
+type ListBottleVintageFilter struct {
+	Gte *int
+	Lt *int
+}
+
+// Filters returns the filters listed in the "filter[attribute][operator]" parameters.
+func (ctx *ListBottleContext) Filters() *ListBottleFilters {
+	var f ListBottleFilters
+	f.Vintage.Gte = ctx.FilterVintageGte
+	f.Vintage.Lt = ctx.FilterVintageLt
+	return &f
+}
+`
+
+	sortKeys = `
+// SortKeys returns the attributes to sort the results by as listed in the "sort" parameter.
+func (ctx *ListBottleContext) SortKeys() []goa.SortKey {
+	if ctx.Sort == nil {
+		return nil
+	}
+	return goa.ParseSort(*ctx.Sort)
+}
+`
+
+	cursorPage = `
+// OKPage sends a HTTP response with status code 200 containing a page of results and a Link
+// header pointing to the page identified by the cursor next. An empty cursor indicates the last page.
+func (ctx *ListBottleContext) OKPage(r Collection, next string) error {
+	if next != "" {
+		ctx.ResponseData.Header().Set("Link", goa.CursorPageLink(ctx.Request.URL, next))
+	}
+	return ctx.OK(r)
+}
+`
+
+	offsetEnvelopePage = `
+// ListBottlePage is the envelope of a page of results sent by OKPage.
+type ListBottlePage struct {
+	// Page of results
+	Items Collection ` + "`" + `form:"items" json:"items" xml:"items"` + "`" + `
+	// Position of the first result of the page in the collection
+	Offset int ` + "`" + `form:"offset" json:"offset" xml:"offset"` + "`" + `
+	// Maximum number of results in the page
+	Limit int ` + "`" + `form:"limit" json:"limit" xml:"limit"` + "`" + `
+	// Total number of results in the collection, absent if unknown
+	Total *int ` + "`" + `form:"total,omitempty" json:"total,omitempty" xml:"total,omitempty"` + "`" + `
+}
 `
 
 	payloadObjUnmarshal = `
@@ -2360,7 +2740,7 @@ type PublicController interface {
 }
 `
 
-	fileServerOptionsHandler = `service.Mux.Handle("OPTIONS", "/public/star\\*star/*filepath", ctrl.MuxHandler("preflight", handlePublicOrigin(cors.HandlePreflight()), nil))`
+	fileServerOptionsHandler = `service.Mux.Handle("OPTIONS", "/public/star\\*star/*filepath", ctrl.MuxHandler("preflight", handleOrigin(cors.HandlePreflight()), nil))`
 
 	simpleController = `// BottlesController is the controller interface for the Bottles actions.
 type BottlesController interface {
@@ -2370,87 +2750,51 @@ type BottlesController interface {
 `
 
 	originsIntegration = `}
-	h = handleBottlesOrigin(h)
+	h = handleOrigin(h)
 	service.Mux.Handle`
 
-	originsHandler = `// handleBottlesOrigin applies the CORS response headers corresponding to the origin.
-func handleBottlesOrigin(h goa.Handler) goa.Handler {
-
-	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		origin := req.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			return h(ctx, rw, req)
-		}
-		if cors.MatchOrigin(origin, "here.example.com") {
-			ctx = goa.WithLogContext(ctx, "origin", origin)
-			rw.Header().Set("Access-Control-Allow-Origin", origin)
-			rw.Header().Set("Vary", "Origin")
-			rw.Header().Set("Access-Control-Expose-Headers", "X-Three")
-			rw.Header().Set("Access-Control-Allow-Credentials", "true")
-			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-				rw.Header().Set("Access-Control-Allow-Methods", "GET, POST")
-				rw.Header().Set("Access-Control-Allow-Headers", "X-One, X-Two")
-			}
-			return h(ctx, rw, req)
-		}
-		if cors.MatchOrigin(origin, "there.example.com") {
-			ctx = goa.WithLogContext(ctx, "origin", origin)
-			rw.Header().Set("Access-Control-Allow-Origin", origin)
-			rw.Header().Set("Vary", "Origin")
-			rw.Header().Set("Access-Control-Allow-Credentials", "false")
-			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-				rw.Header().Set("Access-Control-Allow-Methods", "*")
-				rw.Header().Set("Access-Control-Allow-Headers", "*")
-			}
-			return h(ctx, rw, req)
-		}
+	originsMiddleware = `	var h goa.Handler
+	handleOrigin := cors.Middleware(BottlesCORS)
+`
 
-		return h(ctx, rw, req)
-	}
+	originsHandler = `// BottlesCORS is the CORS configuration of the Bottles controller as defined in the
+// design. It may be modified before the controller is mounted, e.g. to load the allowed origins from
+// the service configuration. MountBottlesController builds the CORS middleware used by all the
+// actions from it.
+var BottlesCORS = cors.Config{
+	Policies: []*cors.Policy{
+		{
+			Origin: "here.example.com",
+			Methods: []string{"GET", "POST"},
+			Headers: []string{"X-One", "X-Two"},
+			Exposed: []string{"X-Three"},
+			Credentials: true,
+		},
+		{
+			Origin: "there.example.com",
+			Methods: []string{"*"},
+			Headers: []string{"*"},
+		},
+	},
 }
 `
 
-	regexpOriginsHandler = `// handleBottlesOrigin applies the CORS response headers corresponding to the origin.
-func handleBottlesOrigin(h goa.Handler) goa.Handler {
-	spec0 := regexp.MustCompile("[here|there].example.com")
-
-	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		origin := req.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			return h(ctx, rw, req)
-		}
-		if cors.MatchOriginRegexp(origin, spec0) {
-			ctx = goa.WithLogContext(ctx, "origin", origin)
-			rw.Header().Set("Access-Control-Allow-Origin", origin)
-			rw.Header().Set("Vary", "Origin")
-			rw.Header().Set("Access-Control-Expose-Headers", "X-Three")
-			rw.Header().Set("Access-Control-Allow-Credentials", "true")
-			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-				rw.Header().Set("Access-Control-Allow-Methods", "GET, POST")
-				rw.Header().Set("Access-Control-Allow-Headers", "X-One, X-Two")
-			}
-			return h(ctx, rw, req)
-		}
-		if cors.MatchOrigin(origin, "there.example.com") {
-			ctx = goa.WithLogContext(ctx, "origin", origin)
-			rw.Header().Set("Access-Control-Allow-Origin", origin)
-			rw.Header().Set("Vary", "Origin")
-			rw.Header().Set("Access-Control-Allow-Credentials", "false")
-			if acrm := req.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-				rw.Header().Set("Access-Control-Allow-Methods", "*")
-				rw.Header().Set("Access-Control-Allow-Headers", "*")
-			}
-			return h(ctx, rw, req)
-		}
-
-		return h(ctx, rw, req)
-	}
+	regexpOriginsHandler = `var BottlesCORS = cors.Config{
+	Policies: []*cors.Policy{
+		{
+			Origin: "[here|there].example.com",
+			Regexp: true,
+			Methods: []string{"GET", "POST"},
+			Headers: []string{"X-One", "X-Two"},
+			Exposed: []string{"X-Three"},
+			Credentials: true,
+		},
+		{
+			Origin: "there.example.com",
+			Methods: []string{"*"},
+			Headers: []string{"*"},
+		},
+	},
 }
 `
 
@@ -2498,6 +2842,28 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 }
 `
 
+	versionMount = `		return ctrl.List(rctx)
+	}
+	h = goa.DeprecatedHandler(h, "Wed, 30 Jun 2027 00:00:00 GMT")
+	service.HandleVersion(apiVersion, "v1", "GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles", "version", "v1")
+}
+`
+
+	compositeSecurityMount = `		return ctrl.List(rctx)
+	}
+	h = handleSecurityAlternatives(h,
+		securityRequirement{
+			schemes: []string{"mtls", "jwt"},
+			scopes:  []string{"api:read"},
+		},
+		securityRequirement{
+			schemes: []string{"api_key"},
+		},
+	)
+	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+`
+
 	multiController = `// BottlesController is the controller interface for the Bottles actions.
 type BottlesController interface {
 	goa.Muxer
@@ -2607,5 +2973,174 @@ type ComplexPayload struct {
 	Misc map[int]*MiscPayload ` + "`" + `form:"misc,omitempty" json:"misc,omitempty" xml:"misc,omitempty"` + "`" + `
 	Name *string ` + "`" + `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"` + "`" + `
 }
+`
+
+	enumUserType = `// BottleStatus user type.
+type BottleStatus string
+
+// BottleStatus values.
+const (
+	// BottleStatusOpened is the "open" BottleStatus value.
+	BottleStatusOpened BottleStatus = "open"
+	// BottleStatusSealed is the "sealed" BottleStatus value.
+	BottleStatusSealed BottleStatus = "sealed"
+)
+
+// Valid returns true if v is one of the BottleStatus values.
+func (v BottleStatus) Valid() bool {
+	switch v {
+	case BottleStatusOpened, BottleStatusSealed:
+		return true
+	}
+	return false
+}
+
+// String returns the text representation of v.
+func (v BottleStatus) String() string {
+	return string(v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v BottleStatus) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts values that are not one of the
+// BottleStatus values so that clients can decode values added later to the API, use Valid or
+// the Validate methods to check them.
+func (v *BottleStatus) UnmarshalText(text []byte) error {
+	*v = BottleStatus(text)
+	return nil
+}
+`
+
+	unionUserType = `// PaymentMethod user type.
+type PaymentMethod struct {
+	// Value is the union variant value.
+	Value PaymentMethodVariant
+}
+
+// PaymentMethodVariant is implemented by the PaymentMethod variants.
+type PaymentMethodVariant interface {
+	// Variant returns the name of the variant.
+	Variant() string
+	Validate() error
+}
+
+// PaymentMethodCard is the "card" variant of PaymentMethod.
+type PaymentMethodCard struct {
+	Number *string ` + "`" + `form:"number,omitempty" json:"number,omitempty" xml:"number,omitempty"` + "`" + `
+}
+
+// Variant returns the name of the PaymentMethodCard variant.
+func (ut *PaymentMethodCard) Variant() string {
+	return "card"
+}
+`
+
+	cookiesContext = `
+type ListBottleContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Session string
+	Visits *int
+}
+`
+
+	cookiesContextFactory = `
+	cookieSession, _ := req.Cookie("session")
+	if cookieSession == nil {
+		err = goa.MergeErrors(err, goa.MissingCookieError("session"))
+	} else {
+		rawSession := cookieSession.Value
+		rctx.Session = rawSession
+	}
+	cookieVisits, _ := req.Cookie("visits")
+	if cookieVisits != nil {
+		rawVisits := cookieVisits.Value
+		if visits, err2 := strconv.Atoi(rawVisits); err2 == nil {
+			tmp2 := visits
+			tmp1 := &tmp2
+			rctx.Visits = tmp1
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("visits", rawVisits, "integer"))
+		}
+	}
+	return &rctx, err
+}
 `
 )
+
+var _ = Describe("NullableTypesWriter", func() {
+	var workspace *codegen.Workspace
+	var pkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err = workspace.NewPackage("nullable")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("omits the nullable attributes that are not set when encoding", func() {
+		if _, err := exec.LookPath("go"); err != nil {
+			Skip("go not installed")
+		}
+		nick := &design.AttributeDefinition{Type: design.String, Nullable: true}
+		ut := &design.UserTypeDefinition{
+			AttributeDefinition: &design.AttributeDefinition{Type: design.Object{"nick": nick}},
+			TypeName:            "Profile",
+		}
+		imports := []*codegen.ImportSpec{codegen.SimpleImport("encoding/json")}
+
+		src, err := pkg.CreateSourceFile("nullable_types.go")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(src.WriteHeader("Nullable Types", "main", imports)).Should(Succeed())
+		Ω((&genapp.NullableTypesWriter{Writer: src}).Execute([]*design.AttributeDefinition{nick})).Should(Succeed())
+		src.Close()
+		src, err = pkg.CreateSourceFile("user_types.go")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(src.WriteHeader("User Types", "main", imports)).Should(Succeed())
+		wr, err := genapp.NewUserTypesWriter(src.Abs())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(wr.Execute(ut)).Should(Succeed())
+		src.Close()
+		Ω(ioutil.WriteFile(filepath.Join(pkg.Abs(), "main.go"), []byte(nullableMain), 0644)).Should(Succeed())
+
+		cmd := exec.Command("go", "run", "main.go", "nullable_types.go", "user_types.go")
+		cmd.Dir = pkg.Abs()
+		cmd.Env = append(os.Environ(), "GO111MODULE=off", "GOFLAGS=")
+		out, err := cmd.CombinedOutput()
+		Ω(err).ShouldNot(HaveOccurred(), string(out))
+		Ω(string(out)).Should(Equal(`{}` + "\n" + `{"nick":null}` + "\n" + `{"nick":"bob"}` + "\n" + "true true\n"))
+	})
+})
+
+const nullableMain = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func main() {
+	for _, p := range []Profile{{}, {Nick: NullableString{Set: true, Null: true}}, {Nick: NullableString{Set: true, Value: "bob"}}} {
+		b, err := json.Marshal(&p)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(b))
+	}
+	var p Profile
+	if err := json.Unmarshal([]byte(` + "`" + `{"nick":null}` + "`" + `), &p); err != nil {
+		panic(err)
+	}
+	fmt.Println(p.Nick.Set, p.Nick.Null)
+}
+`
@@ -12,20 +12,12 @@ import (
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/ir"
 )
 
 func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, funcs template.FuncMap) (err error) {
-	var file *codegen.SourceFile
-	file, err = codegen.SourceFileFor(mainFile)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		file.Close()
-		if err == nil {
-			err = file.FormatCode()
-		}
-	}()
+	file := ir.NewFile(mainFile)
+	g.files = append(g.files, file)
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
@@ -48,8 +40,9 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 	funcs["joinNames"] = joinNames
 	funcs["signerSignature"] = signerSignature
 	funcs["signerArgs"] = signerArgs
+	funcs["isHTTPSig"] = isHTTPSig
+	funcs["isJWT"] = isJWT
 
-	g.genfiles = append(g.genfiles, mainFile)
 	version := design.Design.Version
 	if version == "" {
 		version = "0"
@@ -59,15 +52,28 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 	hasBasicAuthSigners := false
 	hasAPIKeySigners := false
 	hasTokenSigners := false
+	hasHTTPSigSigners := false
+	hasMTLS := false
 	for _, s := range g.API.SecuritySchemes {
+		if s.Kind == design.MTLSSecurityKind {
+			hasMTLS = true
+		}
 		if signerType(s) != "" {
 			hasSigners = true
+			if isHTTPSig(s) {
+				hasHTTPSigSigners = true
+				continue
+			}
+			if isJWT(s) {
+				hasTokenSigners = true
+				continue
+			}
 			switch s.Type {
 			case "basic":
 				hasBasicAuthSigners = true
 			case "apiKey":
 				hasAPIKeySigners = true
-			case "jwt", "oauth2":
+			case "oauth2":
 				hasTokenSigners = true
 			}
 		}
@@ -81,6 +87,8 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 		HasBasicAuthSigners bool
 		HasAPIKeySigners    bool
 		HasTokenSigners     bool
+		HasHTTPSigSigners   bool
+		HasMTLS             bool
 	}{
 		API:                 g.API,
 		Version:             version,
@@ -89,23 +97,16 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 		HasBasicAuthSigners: hasBasicAuthSigners,
 		HasAPIKeySigners:    hasAPIKeySigners,
 		HasTokenSigners:     hasTokenSigners,
+		HasHTTPSigSigners:   hasHTTPSigSigners,
+		HasMTLS:             hasMTLS,
 	}
-	err = file.ExecuteTemplate("main", mainTmpl, funcs, data)
+	err = file.ExecuteTemplate("main", mainTmpl, snapshot(funcs), data)
 	return
 }
 
 func (g *Generator) generateCommands(commandsFile string, clientPkg string, funcs template.FuncMap) (err error) {
-	var file *codegen.SourceFile
-	file, err = codegen.SourceFileFor(commandsFile)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		file.Close()
-		if err == nil {
-			err = file.FormatCode()
-		}
-	}()
+	file := ir.NewFile(commandsFile)
+	g.files = append(g.files, file)
 
 	funcs["defaultRouteParams"] = defaultRouteParams
 	funcs["defaultRouteTemplate"] = defaultRouteTemplate
@@ -113,16 +114,14 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	funcs["joinRouteParams"] = joinRouteParams
 	funcs["routes"] = routes
 	funcs["flagType"] = flagType
+	funcs["flagDefault"] = flagDefault
+	funcs["parseNumber"] = parseNumber
 	funcs["cmdFieldType"] = cmdFieldTypeString
 	funcs["formatExample"] = formatExample
 	funcs["shouldAddExample"] = shouldAddExample
 	funcs["kebabCase"] = codegen.KebabCase
 
-	commandTypesTmpl := template.Must(template.New("commandTypes").Funcs(funcs).Parse(commandTypesTmpl))
-	commandsTmpl := template.Must(template.New("commands").Funcs(funcs).Parse(commandsTmpl))
-	commandsTmplWS := template.Must(template.New("commandsWS").Funcs(funcs).Parse(commandsTmplWS))
-	downloadCommandTmpl := template.Must(template.New("download").Funcs(funcs).Parse(downloadCommandTmpl))
-	registerTmpl := template.Must(template.New("register").Funcs(funcs).Parse(registerTmpl))
+	funcs = snapshot(funcs)
 
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("encoding/json"),
@@ -149,14 +148,13 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	if err = file.WriteHeader(title, "cli", imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, commandsFile)
 
 	file.Write([]byte("type (\n"))
 	var fs []*design.FileServerDefinition
 	if err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
 		fs = append(fs, res.FileServers...)
 		return res.IterateActions(func(action *design.ActionDefinition) error {
-			return commandTypesTmpl.Execute(file, action)
+			return file.ExecuteTemplate("commandTypes", commandTypesTmpl, funcs, action)
 		})
 	}); err != nil {
 		return err
@@ -168,11 +166,13 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 
 	actions := make(map[string][]*design.ActionDefinition)
 	hasDownloads := false
+	var params []*design.AttributeDefinition
 	g.API.IterateResources(func(res *design.ResourceDefinition) error {
 		if len(res.FileServers) > 0 {
 			hasDownloads = true
 		}
 		return res.IterateActions(func(action *design.ActionDefinition) error {
+			params = append(params, action.QueryParams, action.Headers, action.Cookies)
 			name := codegen.Goify(action.Name, false)
 			if as, ok := actions[name]; ok {
 				actions[name] = append(as, action)
@@ -183,13 +183,15 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 		})
 	})
 	data := struct {
-		Actions      map[string][]*design.ActionDefinition
-		Package      string
-		HasDownloads bool
+		Actions        map[string][]*design.ActionDefinition
+		Package        string
+		HasDownloads   bool
+		NumericFormats []string
 	}{
-		Actions:      actions,
-		Package:      g.Target,
-		HasDownloads: hasDownloads,
+		Actions:        actions,
+		Package:        g.Target,
+		HasDownloads:   hasDownloads,
+		NumericFormats: numericFormats(params...),
 	}
 	if err = file.ExecuteTemplate("registerCmds", registerCmdsT, funcs, data); err != nil {
 		return err
@@ -228,7 +230,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 			Package:     g.Target,
 			FileServers: fsdata,
 		}
-		if err = downloadCommandTmpl.Execute(file, data); err != nil {
+		if err = file.ExecuteTemplate("download", downloadCommandTmpl, funcs, data); err != nil {
 			return err
 		}
 	}
@@ -238,20 +240,18 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 				"Action":          action,
 				"Resource":        action.Parent,
 				"Package":         g.Target,
-				"HasMultiContent": len(g.API.Consumes) > 1,
+				"HasMultiContent": len(g.API.Consumes) > 1 && action.Patch == nil,
 			}
 			var err error
 			if action.WebSocket() {
-				err = commandsTmplWS.Execute(file, data)
+				err = file.ExecuteTemplate("commandsWS", commandsTmplWS, funcs, data)
 			} else {
-				err = commandsTmpl.Execute(file, data)
-
+				err = file.ExecuteTemplate("commands", commandsTmpl, funcs, data)
 			}
 			if err != nil {
 				return err
 			}
-			err = registerTmpl.Execute(file, data)
-			return err
+			return file.ExecuteTemplate("register", registerTmpl, funcs, data)
 		})
 	})
 	return
@@ -346,6 +346,11 @@ func joinNames(useNil bool, atts ...*design.AttributeDefinition) string {
 // resolve non required, non array Param/QueryParam for access via CII flags.
 // Some types need convertion from string to 'Type' before calling rich client Commands.
 func flagTypeVal(a *design.AttributeDefinition, key string, field string) string {
+	if f := a.NumericFormat(); f == "decimal" {
+		return `stringFlagVal("` + key + `", ` + field + ")"
+	} else if f != "" {
+		return "%s"
+	}
 	switch a.Type {
 	case design.Integer:
 		return `intFlagVal("` + key + `", ` + field + ")"
@@ -363,6 +368,11 @@ func flagTypeVal(a *design.AttributeDefinition, key string, field string) string
 // Special types like Number/UUID need to be converted from String
 // %s maps to specialTypeResult.Temps
 func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
+	if f := a.NumericFormat(); f == "decimal" {
+		return field
+	} else if f != "" {
+		return "*%s"
+	}
 	switch a.Type {
 	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
 		return "*%s"
@@ -375,7 +385,13 @@ func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
 // Special types like Number/UUID need to be converted from String
 // %s maps to specialTypeResult.Temps
 func flagTypeArrayVal(a *design.AttributeDefinition, field string) string {
-	switch a.Type.ToArray().ElemType.Type {
+	elem := a.Type.ToArray().ElemType
+	if f := elem.NumericFormat(); f == "decimal" {
+		return field
+	} else if f != "" {
+		return "%s"
+	}
+	switch elem.Type {
 	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
 		return "%s"
 	}
@@ -427,9 +443,19 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 		for _, n := range keys {
 			a := obj[n]
 			field := fmt.Sprintf("cmd.%s", codegen.Goify(n, true))
-			typ := cmdFieldType(a.Type, true)
+			typ := cmdFieldType(a, true)
 			var typeHandler, nilVal string
-			if !a.Type.IsArray() {
+			if f := a.NumericFormat(); f != "" {
+				nilVal = `""`
+				if f != "decimal" {
+					typeHandler = f + "Val"
+				}
+			} else if f := arrayNumericFormat(a); f != "" {
+				nilVal = "nil"
+				if f != "decimal" {
+					typeHandler = f + "Array"
+				}
+			} else if !a.Type.IsArray() {
 				nilVal = `""`
 				switch a.Type {
 				case design.Number:
@@ -522,13 +548,17 @@ func routes(action *design.ActionDefinition) string {
 // signerSignature returns the callee signature for the signer factory function for the given security
 // scheme.
 func signerSignature(sec *design.SecuritySchemeDefinition) string {
+	if isHTTPSig(sec) {
+		return "keyID, alg, key string"
+	}
+	if isJWT(sec) {
+		return "source goaclient.TokenSource"
+	}
 	switch sec.Type {
 	case "basic":
 		return "user, pass string"
 	case "apiKey":
 		return "key, format string"
-	case "jwt":
-		return "source goaclient.TokenSource"
 	case "oauth2":
 		return "source goaclient.TokenSource"
 	default:
@@ -539,13 +569,17 @@ func signerSignature(sec *design.SecuritySchemeDefinition) string {
 // signerArgs returns the caller signature for the signer factory function for the given security
 // scheme.
 func signerArgs(sec *design.SecuritySchemeDefinition) string {
+	if isHTTPSig(sec) {
+		return "sigKeyID, sigAlg, sigKey"
+	}
+	if isJWT(sec) {
+		return "source"
+	}
 	switch sec.Type {
 	case "basic":
 		return "user, pass"
 	case "apiKey":
 		return "key, format"
-	case "jwt":
-		return "source"
 	case "oauth2":
 		return "source"
 	default:
@@ -553,10 +587,24 @@ func signerArgs(sec *design.SecuritySchemeDefinition) string {
 	}
 }
 
+// isHTTPSig returns true if the given security scheme is a HTTP message signatures scheme.
+func isHTTPSig(sec *design.SecuritySchemeDefinition) bool {
+	return sec.Kind == design.HTTPSigSecurityKind
+}
+
+// isJWT returns true if the given security scheme is a JWT scheme. JWT schemes are described as
+// "apiKey" schemes in the design but the CLI signs their requests with tokens.
+func isJWT(sec *design.SecuritySchemeDefinition) bool {
+	return sec.Kind == design.JWTSecurityKind || sec.Type == "jwt"
+}
+
 // flagType returns the flag type for the given (basic type) attribute definition.
 func flagType(att *design.AttributeDefinition) string {
 	switch att.Type.Kind() {
 	case design.IntegerKind:
+		if att.NumericFormat() != "" {
+			return "String"
+		}
 		return "Int"
 	case design.NumberKind:
 		return "String"
@@ -572,6 +620,11 @@ func flagType(att *design.AttributeDefinition) string {
 		return "String"
 	case design.ArrayKind:
 		switch att.Type.ToArray().ElemType.Type.Kind() {
+		case design.IntegerKind:
+			if arrayNumericFormat(att) != "" {
+				return "StringSlice"
+			}
+			return "IntSlice"
 		case design.NumberKind:
 			return "StringSlice"
 		case design.BooleanKind:
@@ -588,6 +641,71 @@ func flagType(att *design.AttributeDefinition) string {
 	}
 }
 
+// flagDefault returns the Go literal used as default value of the flag for the given attribute.
+// Numeric values are quoted when the flag is a string flag.
+func flagDefault(att *design.AttributeDefinition) string {
+	if _, ok := att.DefaultValue.(string); !ok && flagType(att) == "String" {
+		return fmt.Sprintf("%q", fmt.Sprint(att.DefaultValue))
+	}
+	return fmt.Sprintf("%#v", att.DefaultValue)
+}
+
+// arrayNumericFormat returns the numeric format of the elements of the given array attribute if
+// any, the empty string otherwise.
+func arrayNumericFormat(att *design.AttributeDefinition) string {
+	if !att.Type.IsArray() {
+		return ""
+	}
+	return att.Type.ToArray().ElemType.NumericFormat()
+}
+
+// numericFormats returns the sorted list of numeric formats used by the fields of the given
+// objects or their array elements. "decimal" is omitted as decimal flags are strings.
+func numericFormats(atts ...*design.AttributeDefinition) []string {
+	seen := make(map[string]bool)
+	for _, att := range atts {
+		if att == nil {
+			continue
+		}
+		for _, a := range att.Type.ToObject() {
+			f := a.NumericFormat()
+			if f == "" {
+				f = arrayNumericFormat(a)
+			}
+			if f != "" && f != "decimal" {
+				seen[f] = true
+			}
+		}
+	}
+	var formats []string
+	for f := range seen {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// parseNumber returns the Go code that parses the string held by the val variable into a value
+// of the given numeric format.
+func parseNumber(format string) string {
+	switch format {
+	case "int32":
+		return "strconv.ParseInt(val, 10, 32)"
+	case "int64":
+		return "strconv.ParseInt(val, 10, 64)"
+	case "uint":
+		return "strconv.ParseUint(val, 10, 0)"
+	case "uint32":
+		return "strconv.ParseUint(val, 10, 32)"
+	case "uint64":
+		return "strconv.ParseUint(val, 10, 64)"
+	case "float32":
+		return "strconv.ParseFloat(val, 32)"
+	default:
+		panic("unknown numeric format " + format) // bug
+	}
+}
+
 func shouldAddExample(ut *design.UserTypeDefinition) bool {
 	if ut == nil {
 		return false
@@ -621,21 +739,44 @@ func main() {
 	app.PersistentFlags().DurationVarP(&httpClient.Timeout, "timeout", "t", time.Duration(20) * time.Second, "Set the request timeout")
 	app.PersistentFlags().BoolVar(&c.Dump, "dump", false, "Dump HTTP request and response.")
 
-{{ if .HasSigners }}	// Register signer flags
+{{ if or .HasSigners .HasMTLS }}	// Register signer flags
 {{ if .HasBasicAuthSigners }} var user, pass string
 	app.PersistentFlags().StringVar(&user, "user", "", "Username used for authentication")
 	app.PersistentFlags().StringVar(&pass, "pass", "", "Password used for authentication")
 {{ end }}{{ if .HasAPIKeySigners }} var key, format string
 	app.PersistentFlags().StringVar(&key, "key", "", "API key used for authentication")
 	app.PersistentFlags().StringVar(&format, "format", "Bearer %s", "Format used to create auth header or query from key")
-{{ end }}{{ if .HasTokenSigners }} var token, typ string
+{{ end }}{{ if .HasTokenSigners }} var token, tokenCmd, tokenFile, typ string
 	app.PersistentFlags().StringVar(&token, "token", "", "Token used for authentication")
+	app.PersistentFlags().StringVar(&tokenCmd, "token-cmd", "", "Shell command that prints the token used for authentication, run again when the token expires")
+	app.PersistentFlags().StringVar(&tokenFile, "token-file", "", "File containing the token used for authentication, read again when the token expires")
 	app.PersistentFlags().StringVar(&typ, "token-type", "Bearer", "Token type used for authentication")
+{{ end }}{{ if .HasHTTPSigSigners }} var sigKeyID, sigAlg, sigKey string
+	app.PersistentFlags().StringVar(&sigKeyID, "sig-key-id", "", "Identifier of the key used to sign requests")
+	app.PersistentFlags().StringVar(&sigAlg, "sig-alg", "hmac-sha256", "Request signature algorithm (hmac-sha256 or ed25519)")
+	app.PersistentFlags().StringVar(&sigKey, "sig-key", "", "Base64 encoded request signing key (HMAC secret or ed25519 seed)")
+{{ end }}{{ if .HasMTLS }} var certFile, certKeyFile, caFile string
+	app.PersistentFlags().StringVar(&certFile, "cert", "", "Client certificate file (PEM) used for mutual TLS authentication")
+	app.PersistentFlags().StringVar(&certKeyFile, "cert-key", "", "Client certificate private key file (PEM)")
+	app.PersistentFlags().StringVar(&caFile, "ca-cert", "", "CA certificates file (PEM) used to verify the server, defaults to the system pool")
 {{ end }}
 	// Parse flags and setup signers
 	app.ParseFlags(os.Args)
-{{ if .HasTokenSigners }}	source := &goaclient.StaticTokenSource{
-		StaticToken: &goaclient.StaticToken{Type: typ, Value: token},
+{{ if .HasTokenSigners }}	var source goaclient.TokenSource = &goaclient.StaticTokenSource{
+		StaticToken: goaclient.NewStaticToken(token, typ),
+	}
+	if tokenCmd != "" {
+		source = &goaclient.RefreshingTokenSource{Fetch: goaclient.CommandTokenFetcher(tokenCmd, typ)}
+	} else if tokenFile != "" {
+		source = &goaclient.RefreshingTokenSource{Fetch: goaclient.FileTokenFetcher(tokenFile, typ)}
+	}
+{{ end }}{{ if .HasMTLS }}	if certFile != "" {
+		tlsConfig, err := goaclient.NewMTLSConfig(certFile, certKeyFile, caFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load client certificate: %s\n", err)
+			os.Exit(-1)
+		}
+		httpClient.Transport = &http.Transport{TLSClientConfig: tlsConfig}
 	}
 {{ end }}{{ end }}{{ range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
 */}}	{{ goify $security.SchemeName false }}Signer := new{{ goify $security.SchemeName true }}Signer({{ signerArgs $security }}){{ end }}
@@ -667,7 +808,16 @@ func newHTTPClient() *http.Client {
 // new{{ goify $security.SchemeName true }}Signer returns the request signer used for authenticating
 // against the {{ $security.SchemeName }} security scheme.
 func new{{ goify $security.SchemeName true }}Signer({{ signerSignature $security }}) goaclient.Signer {
-{{ if eq .Type "basic" }}	return &goaclient.BasicSigner{
+{{ if isHTTPSig $security }}	signer, err := goaclient.NewHTTPSigSigner(keyID, alg, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid request signing configuration: %s\n", err)
+		os.Exit(-1)
+	}
+	return signer
+{{ else if isJWT $security }}	return &goaclient.JWTSigner{
+		TokenSource: source,
+	}
+{{ else if eq .Type "basic" }}	return &goaclient.BasicSigner{
 		Username: user,
 		Password: pass,
 	}
@@ -677,9 +827,6 @@ func new{{ goify $security.SchemeName true }}Signer({{ signerSignature $security
 		KeyValue: key,
 		Format: {{ if eq $security.In "query" }}"%s"{{ else }}format{{ end }},
 	}
-{{ else if eq .Type "jwt" }}	return &goaclient.JWTSigner{
-		TokenSource: source,
-	}
 {{ else if eq .Type "oauth2" }}	return &goaclient.OAuth2Signer{
 		TokenSource: source,
 	}
@@ -693,11 +840,13 @@ const commandTypesTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Name (titl
 {{ if .Payload }}		Payload string
 		ContentType string
 {{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
-{{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false }}
+{{ end }}		{{ goify $name true }} {{ cmdFieldType $att false }}
 {{ end }}{{ end }}{{ $params := .QueryParams }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
-{{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
+{{ end }}		{{ goify $name true }} {{ cmdFieldType $att false }}
 {{ end }}{{ end }}{{ $headers := .Headers }}{{ if $headers }}{{ range $name, $att := $headers.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
-{{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
+{{ end }}		{{ goify $name true }} {{ cmdFieldType $att false }}
+{{ end }}{{ end }}{{ $cookies := .Cookies }}{{ if $cookies }}{{ range $name, $att := $cookies.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
+{{ end }}		{{ goify $name true }} {{ cmdFieldType $att false }}
 {{ end }}{{ end }}		PrettyPrint bool
 	}
 
@@ -722,9 +871,9 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ else }}{{ $pparams := defaultRouteParams .Action }}	path = fmt.Sprintf({{ printf "%q" (defaultRouteTemplate .Action)}}, {{ joinRouteParams .Action $pparams }})
 {{ end }}	}
 	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
-	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
+	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers .Action.Cookies }}{{ $specialTypeResult.Output }}
 	ws, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{/*
-	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }})
+	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers .Action.Cookies }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }})
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
@@ -790,16 +939,20 @@ func (cmd *{{ $cmdName }}) RegisterFlags(cc *cobra.Command, c *{{ .Package }}.Cl
 {{ if .Action.Payload }}	cc.Flags().StringVar(&cmd.Payload, "payload", "", "Request body encoded in JSON")
 	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request content type override, e.g. 'application/x-www-form-urlencoded'")
 {{ end }}{{ $pparams := defaultRouteParams .Action }}{{ if $pparams }}{{ range $pname, $pparam := $pparams.Type.ToObject }}{{ $tmp := goify $pname false }}{{/*
-*/}}{{ if not $pparam.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $pparam.Type false }}
+*/}}{{ if not $pparam.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $pparam false }}
 {{ end }}	cc.Flags().{{ flagType $pparam }}Var(&cmd.{{ goify $pname true }}, "{{ $pname }}", {{/*
-*/}}{{ if $pparam.DefaultValue }}{{ printf "%#v" $pparam.DefaultValue }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $pparam.Description }}` + "`" + `)
+*/}}{{ if $pparam.DefaultValue }}{{ flagDefault $pparam }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $pparam.Description }}` + "`" + `)
 {{ end }}{{ end }}{{ $params := .Action.QueryParams }}{{ if $params }}{{ range $name, $param := $params.Type.ToObject }}{{ $tmp := goify $name false }}{{/*
-*/}}{{ if not $param.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $param.Type false }}
+*/}}{{ if not $param.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $param false }}
 {{ end }}	cc.Flags().{{ flagType $param }}Var(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
-*/}}{{ if $param.DefaultValue }}{{ printf "%#v" $param.DefaultValue }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $param.Description }}` + "`" + `)
+*/}}{{ if $param.DefaultValue }}{{ flagDefault $param }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $param.Description }}` + "`" + `)
 {{ end }}{{ end }}{{ $headers := .Action.Headers }}{{ if $headers }}{{ range $name, $header := $headers.Type.ToObject }}{{/*
 */}} cc.Flags().StringVar(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
 */}}{{ if $header.DefaultValue }}{{ printf "%q" $header.DefaultValue }}{{ else }}""{{ end }}, ` + "`" + `{{ escapeBackticks $header.Description }}` + "`" + `)
+{{ end }}{{ end }}{{ $cookies := .Action.Cookies }}{{ if $cookies }}{{ range $name, $cookie := $cookies.Type.ToObject }}{{ $tmp := goify $name false }}{{/*
+*/}}{{ if not $cookie.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $cookie false }}
+{{ end }}	cc.Flags().{{ flagType $cookie }}Var(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
+*/}}{{ if $cookie.DefaultValue }}{{ flagDefault $cookie }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $cookie.Description }}` + "`" + `)
 {{ end }}{{ end }}}`
 
 const commandsTmpl = `
@@ -821,10 +974,10 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ end }}		}
 	}
 {{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
-	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
+	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers .Action.Cookies }}{{ $specialTypeResult.Output }}
 	resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
 	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
-	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
+	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers .Action.Cookies }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
 	*/}}{{ if and .Action.Payload .HasMultiContent }}, cmd.ContentType{{ end }})
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
@@ -1024,4 +1177,28 @@ func boolArray(ins []string) ([]bool, error) {
 		vals = append(vals, *val)
 	}
 	return vals, nil
-}`
+}{{ range .NumericFormats }}
+
+func {{ . }}Val(val string) (*{{ . }}, error) {
+	v, err := {{ parseNumber . }}
+	if err != nil {
+		return nil, err
+	}
+	t := {{ . }}(v)
+	return &t, nil
+}
+
+func {{ . }}Array(ins []string) ([]{{ . }}, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals []{{ . }}
+	for _, id := range ins {
+		val, err := {{ . }}Val(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
+}{{ end }}`
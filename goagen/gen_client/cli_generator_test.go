@@ -218,6 +218,8 @@ var _ = Describe("Generate", func() {
 										"anyArray":    &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Any}}},
 										"boolArray":   &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Boolean}}},
 										"numberArray": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Number}}},
+										"count":       &design.AttributeDefinition{Type: design.Integer, Validation: &dslengine.ValidationDefinition{Format: "int64"}},
+										"ids":         &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer, Validation: &dslengine.ValidationDefinition{Format: "uint32"}}}},
 									},
 									Validation: &dslengine.ValidationDefinition{Required: []string{"boolReq"}},
 								},
@@ -256,6 +258,25 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring(", tmp"))
 			Ω(content).Should(ContainSubstring("cc.Flags().StringSliceVar(&cmd.TimeArray, "))
 		})
+		It("generate the correct handling for numbers with a format", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(", err = int64Val(cmd.Count)"))
+			Ω(content).Should(ContainSubstring(", err = uint32Array(cmd.Ids)"))
+			Ω(content).Should(ContainSubstring("cc.Flags().StringVar(&cmd.Count, "))
+			Ω(content).Should(ContainSubstring("cc.Flags().StringSliceVar(&cmd.Ids, "))
+			Ω(content).Should(ContainSubstring("func int64Val(val string) (*int64, error) {\n\tv, err := strconv.ParseInt(val, 10, 64)"))
+			Ω(content).Should(ContainSubstring("func uint32Array(ins []string) ([]uint32, error) {"))
+			Ω(content).ShouldNot(ContainSubstring("func int32Val("))
+			c, err = ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content = string(c)
+			Ω(content).Should(ContainSubstring("count *int64"))
+			Ω(content).Should(ContainSubstring("strconv.FormatInt(int64(*count), 10)"))
+			Ω(content).Should(ContainSubstring("strconv.FormatUint(uint64(p), 10)"))
+		})
 		It("generate the correct handling for special type Number", func() {
 			Ω(genErr).Should(BeNil())
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
@@ -487,7 +508,8 @@ var _ = Describe("Generate", func() {
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
 			content := string(c)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(content).Should(ContainSubstring("jwt1Signer := newJWT1Signer()"))
+			Ω(content).Should(ContainSubstring("jwt1Signer := newJWT1Signer(source)"))
+			Ω(content).Should(ContainSubstring("return &goaclient.JWTSigner{"))
 			Ω(content).Should(ContainSubstring("c.SetJWT1Signer(jwt1Signer)"))
 		})
 	})
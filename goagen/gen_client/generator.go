@@ -14,6 +14,7 @@ import (
 	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_app"
+	"github.com/goadesign/goa/goagen/ir"
 	"github.com/goadesign/goa/goagen/utils"
 )
 
@@ -40,6 +41,7 @@ type Generator struct {
 	Tool           string                // Name of CLI tool
 	NoTool         bool                  // Whether to skip tool generation
 	genfiles       []string
+	files          []*ir.File
 	encoders       []*genapp.EncoderTemplateData
 	decoders       []*genapp.EncoderTemplateData
 	encoderImports []string
@@ -78,11 +80,28 @@ func Generate() (files []string, err error) {
 	return g.Generate()
 }
 
-// Generate generats the client package and CLI.
+// Generate generats the client package and CLI. The client of an API whose design declares
+// several versions targets the latest version.
 func (g *Generator) Generate() (_ []string, err error) {
 	if g.API == nil {
 		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
 	}
+	if g.API.IsVersioned() {
+		var files []string
+		full := g.API
+		defer func() { g.API = full }()
+		err = full.WithVersion(full.Version, func(api *design.APIDefinition) error {
+			g.API = api
+			files, err = g.generate()
+			return err
+		})
+		return files, err
+	}
+	return g.generate()
+}
+
+// generate generates the client package and CLI of the API definition.
+func (g *Generator) generate() (_ []string, err error) {
 
 	go utils.Catch(nil, func() { g.Cleanup() })
 
@@ -91,6 +110,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			g.Cleanup()
 		}
 	}()
+	g.files = nil
 
 	firstNonEmpty := func(args ...string) string {
 		for _, value := range args {
@@ -205,9 +225,25 @@ func (g *Generator) Generate() (_ []string, err error) {
 		return
 	}
 
+	paths, err := ir.Render(ir.Client, g.files)
+	g.genfiles = append(g.genfiles, paths...)
+	if err != nil {
+		return
+	}
+
 	return g.genfiles, nil
 }
 
+// snapshot returns a copy of the given template functions. The files are rendered once all have
+// been built so each template must keep the functions defined when it was added.
+func snapshot(funcs template.FuncMap) template.FuncMap {
+	res := make(template.FuncMap, len(funcs))
+	for n, f := range funcs {
+		res[n] = f
+	}
+	return res
+}
+
 func defaultToolName(api *design.APIDefinition) string {
 	if api == nil {
 		return ""
@@ -224,23 +260,12 @@ func (g *Generator) Cleanup() {
 }
 
 func (g *Generator) generateClient(clientFile string, clientPkg string, funcs template.FuncMap) (err error) {
-	var file *codegen.SourceFile
-	{
-		file, err = codegen.SourceFileFor(clientFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		file.Close()
-		if err == nil {
-			err = file.FormatCode()
-		}
-	}()
-	clientTmpl := template.Must(template.New("client").Funcs(funcs).Parse(clientTmpl))
+	file := ir.NewFile(clientFile)
+	g.files = append(g.files, file)
 
 	// Compute list of encoders and decoders
-	encoders, err := genapp.BuildEncoders(g.API.Produces, true)
+	produces := append(append([]*design.EncodingDefinition{}, g.API.Produces...), g.API.PatchEncodings(true)...)
+	encoders, err := genapp.BuildEncoders(produces, true)
 	if err != nil {
 		return err
 	}
@@ -277,7 +302,6 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 	if err = file.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, clientFile)
 
 	// Generate
 	data := struct {
@@ -289,7 +313,7 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 		Encoders: encoders,
 		Decoders: decoders,
 	}
-	err = clientTmpl.Execute(file, data)
+	err = file.ExecuteTemplate("client", clientTmpl, snapshot(funcs), data)
 	return
 }
 
@@ -303,13 +327,15 @@ func (g *Generator) generateClientResources(pkgDir, clientPkg string, funcs temp
 	if err := g.generateUserTypes(pkgDir); err != nil {
 		return err
 	}
+	if err := g.generateNullableTypes(pkgDir); err != nil {
+		return err
+	}
 
 	return g.generateMediaTypes(pkgDir, funcs)
 }
 
 func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDefinition, funcs template.FuncMap) (err error) {
-	payloadTmpl := template.Must(template.New("payload").Funcs(funcs).Parse(payloadTmpl))
-	pathTmpl := template.Must(template.New("pathTemplate").Funcs(funcs).Parse(pathTmpl))
+	funcs = snapshot(funcs)
 
 	resFilename := codegen.SnakeCase(res.Name)
 	if resFilename == typesFileName {
@@ -318,17 +344,8 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 	}
 	filename := filepath.Join(pkgDir, resFilename+".go")
 
-	var file *codegen.SourceFile
-	file, err = codegen.SourceFileFor(filename)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		file.Close()
-		if err == nil {
-			err = file.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filename)
+	g.files = append(g.files, file)
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("bytes"),
 		codegen.SimpleImport("encoding/json"),
@@ -346,13 +363,14 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
 	if err = file.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, filename)
 
 	err = res.IterateFileServers(func(fs *design.FileServerDefinition) error {
 		return g.generateFileServer(file, fs, funcs)
@@ -372,9 +390,14 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 				}
 			}
 			if !found {
-				if err := payloadTmpl.Execute(file, action); err != nil {
+				if err := file.ExecuteTemplate("payload", payloadTmpl, funcs, action); err != nil {
 					return err
 				}
+				if action.Patch != nil {
+					if err := file.ExecuteTemplate("patch", patchTmpl, funcs, action); err != nil {
+						return err
+					}
+				}
 			}
 		}
 		for i, r := range action.Routes {
@@ -402,7 +425,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 				Index:  i,
 				Params: pd,
 			}
-			if err := pathTmpl.Execute(file, data); err != nil {
+			if err := file.ExecuteTemplate("pathTemplate", pathTmpl, funcs, data); err != nil {
 				return err
 			}
 		}
@@ -411,11 +434,10 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 	return
 }
 
-func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.FileServerDefinition, funcs template.FuncMap) error {
+func (g *Generator) generateFileServer(file *ir.File, fs *design.FileServerDefinition, funcs template.FuncMap) error {
 	var (
 		dir string
 
-		fsTmpl = template.Must(template.New("fileserver").Funcs(funcs).Parse(fsTmpl))
 		name   = g.fileServerMethod(fs)
 		wcs    = design.ExtractWildcards(fs.RequestPath)
 		scheme = "http"
@@ -450,19 +472,18 @@ func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.File
 		RequestDir:      requestDir,
 		CanonicalScheme: scheme,
 	}
-	return fsTmpl.Execute(file, data)
+	return file.ExecuteTemplate("fileserver", fsTmpl, funcs, data)
 }
 
-func (g *Generator) generateActionClient(action *design.ActionDefinition, file *codegen.SourceFile, funcs template.FuncMap) error {
+func (g *Generator) generateActionClient(action *design.ActionDefinition, file *ir.File, funcs template.FuncMap) error {
 	var (
-		params        []string
-		names         []string
-		queryParams   []*paramData
-		headers       []*paramData
-		signer        string
-		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
-		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
-		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		params      []string
+		names       []string
+		queryParams []*paramData
+		headers     []*paramData
+		cookies     []*paramData
+		signers     []string
+		refreshers  []string
 	)
 	if action.Payload != nil {
 		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
@@ -478,19 +499,32 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		// Update closure
 		for _, p := range reqData {
 			names = append(names, p.VarName)
-			params = append(params, p.VarName+" "+cmdFieldType(p.Attribute.Type, false))
+			params = append(params, p.VarName+" "+cmdFieldType(p.Attribute, false))
 		}
 		for _, p := range optData {
 			names = append(names, p.VarName)
-			params = append(params, p.VarName+" "+cmdFieldType(p.Attribute.Type, p.Attribute.Type.IsPrimitive()))
+			params = append(params, p.VarName+" "+cmdFieldType(p.Attribute, p.Attribute.Type.IsPrimitive()))
 		}
 		return append(reqData, optData...)
 	}
 	queryParams = initParamsScoped(action.QueryParams)
 	headers = initParamsScoped(action.Headers)
+	cookies = initParamsScoped(action.Cookies)
 
 	if action.Security != nil {
-		signer = codegen.Goify(action.Security.Scheme.SchemeName, true)
+		seen := make(map[string]bool)
+		for _, req := range action.Security.Requirements() {
+			for _, scheme := range req.AllSchemes() {
+				if signerType(scheme) == "" || seen[scheme.SchemeName] {
+					continue
+				}
+				seen[scheme.SchemeName] = true
+				signers = append(signers, codegen.Goify(scheme.SchemeName, true))
+				if scheme.Kind == design.JWTSecurityKind || scheme.Kind == design.OAuth2SecurityKind {
+					refreshers = append(refreshers, codegen.Goify(scheme.SchemeName, true))
+				}
+			}
+		}
 	}
 	data := struct {
 		Name               string
@@ -502,12 +536,18 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		HasPayload         bool
 		HasMultiContent    bool
 		DefaultContentType string
+		PatchContentType   string
 		Params             string
 		ParamNames         string
 		CanonicalScheme    string
-		Signer             string
+		Signers            []string
+		Refreshers         []string
 		QueryParams        []*paramData
 		Headers            []*paramData
+		Cookies            []*paramData
+		Pagination         *design.PaginationDefinition
+		PageType           string
+		PageTypeName       string
 	}{
 		Name:               action.Name,
 		ResourceName:       action.Parent.Name,
@@ -516,22 +556,45 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Payload:            action.Payload,
 		PayloadMultipart:   action.PayloadMultipart,
 		HasPayload:         action.Payload != nil,
-		HasMultiContent:    len(design.Design.Consumes) > 1,
+		HasMultiContent:    len(design.Design.Consumes) > 1 && action.Patch == nil,
 		DefaultContentType: design.Design.Consumes[0].MIMETypes[0],
 		Params:             strings.Join(params, ", "),
 		ParamNames:         strings.Join(names, ", "),
 		CanonicalScheme:    action.CanonicalScheme(),
-		Signer:             signer,
+		Signers:            signers,
+		Refreshers:         refreshers,
 		QueryParams:        queryParams,
 		Headers:            headers,
+		Cookies:            cookies,
+	}
+	if action.Patch != nil {
+		data.DefaultContentType = action.Patch.Format
+		data.PatchContentType = action.Patch.Format
 	}
 	if action.WebSocket() {
-		return clientsWSTmpl.Execute(file, data)
+		return file.ExecuteTemplate("clientsws", clientsWSTmpl, funcs, data)
+	}
+	if err := file.ExecuteTemplate("clients", clientsTmpl, funcs, data); err != nil {
+		return err
 	}
-	if err := clientsTmpl.Execute(file, data); err != nil {
+	if err := file.ExecuteTemplate("requests", requestsTmpl, funcs, data); err != nil {
 		return err
 	}
-	return requestsTmpl.Execute(file, data)
+	if mt := action.PageMediaType(); mt != nil {
+		view := action.Responses[design.OK].ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		projected, _, err := mt.Project(view)
+		if err != nil {
+			return err
+		}
+		data.Pagination = action.Pagination
+		data.PageType = codegen.GoTypeRef(projected, projected.AllRequired(), 1, false)
+		data.PageTypeName = typeName(projected)
+		return file.ExecuteTemplate("page", pageTmpl, funcs, data)
+	}
+	return nil
 }
 
 // fileServerMethod returns the name of the client method for downloading assets served by the given
@@ -569,27 +632,14 @@ func (g *Generator) fileServerMethod(fs *design.FileServerDefinition) string {
 func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (err error) {
 	funcs["decodegotyperef"] = decodeGoTypeRef
 	funcs["decodegotypename"] = decodeGoTypeName
-	typeDecodeTmpl := template.Must(template.New("typeDecode").Funcs(funcs).Parse(typeDecodeTmpl))
-	var (
-		mtFile string
-		mtWr   *genapp.MediaTypesWriter
-	)
-	{
-		mtFile = filepath.Join(pkgDir, "media_types.go")
-		mtWr, err = genapp.NewMediaTypesWriter(mtFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		mtWr.Close()
-		if err == nil {
-			err = mtWr.FormatCode()
-		}
-	}()
+	funcs = snapshot(funcs)
+	file := ir.NewFile(filepath.Join(pkgDir, "media_types.go"))
+	g.files = append(g.files, file)
+	mtWr := &genapp.MediaTypesWriter{Writer: file, Validator: codegen.NewValidator()}
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("time"),
@@ -602,7 +652,6 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 	if err = mtWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, mtFile)
 	err = g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
 		if (mt.Type.IsObject() || mt.Type.IsArray()) && !mt.IsError() {
 			if err := mtWr.Execute(mt); err != nil {
@@ -614,7 +663,7 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 			if err != nil {
 				return err
 			}
-			return typeDecodeTmpl.Execute(mtWr.SourceFile, p)
+			return file.ExecuteTemplate("typeDecode", typeDecodeTmpl, funcs, p)
 		})
 		return err
 	})
@@ -624,27 +673,15 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 // generateUserTypes iterates through the user types and generates the data structures and
 // marshaling code.
 func (g *Generator) generateUserTypes(pkgDir string) (err error) {
-	var (
-		utFile string
-		utWr   *genapp.UserTypesWriter
-	)
-	{
-		utFile = filepath.Join(pkgDir, "user_types.go")
-		utWr, err = genapp.NewUserTypesWriter(utFile)
-		if err != nil {
-			return
-		}
-	}
-	defer func() {
-		utWr.Close()
-		if err == nil {
-			err = utWr.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filepath.Join(pkgDir, "user_types.go"))
+	g.files = append(g.files, file)
+	utWr := &genapp.UserTypesWriter{Writer: file, Finalizer: codegen.NewFinalizer(), Validator: codegen.NewValidator()}
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
@@ -655,8 +692,14 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 	if err = utWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
-	g.genfiles = append(g.genfiles, utFile)
 	err = g.API.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		// Rendering is deferred, work on a copy so the other files still see the file
+		// attributes.
+		t = &design.UserTypeDefinition{
+			AttributeDefinition: design.DupAtt(t.AttributeDefinition),
+			TypeName:            t.TypeName,
+		}
+		t.Type = design.Dup(t.Type)
 		o := t.Type.ToObject()
 		for _, att := range o {
 			if att.Type.Kind() == design.FileKind {
@@ -668,6 +711,29 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 	return
 }
 
+// generateNullableTypes generates the types holding the values of the nullable attributes if
+// there are any.
+func (g *Generator) generateNullableTypes(pkgDir string) error {
+	atts := genapp.NullableTypes(g.API)
+	if len(atts) == 0 {
+		return nil
+	}
+	file := ir.NewFile(filepath.Join(pkgDir, "nullable_types.go"))
+	g.files = append(g.files, file)
+	ntWr := &genapp.NullableTypesWriter{Writer: file}
+	title := fmt.Sprintf("%s: Application Nullable Types", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
+	}
+	if err := ntWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	return ntWr.Execute(atts)
+}
+
 // join is a code generation helper function that generates a function signature built from
 // concatenating the properties (name type) of the given attribute type (assuming it's an object).
 // join accepts an optional slice of strings which indicates the order in which the parameters
@@ -697,7 +763,7 @@ func join(att *design.AttributeDefinition, usePointers bool, pos ...[]string) st
 	for i, n := range keys {
 		a := obj[n]
 		elems[i] = fmt.Sprintf("%s %s", codegen.Goify(n, false),
-			cmdFieldType(a.Type, usePointers && !a.IsRequired(n)))
+			cmdFieldType(a, usePointers && !a.IsRequired(n)))
 	}
 	return strings.Join(elems, ", ")
 }
@@ -744,25 +810,26 @@ func decodeGoTypeName(t design.DataType, required []string, tabs int, private bo
 	return codegen.GoTypeName(t, required, tabs, private)
 }
 
-// cmdFieldType computes the Go type name used to store command flags of the given design type.
-func cmdFieldType(t design.DataType, point bool) string {
+// cmdFieldType computes the Go type name used to store command flags of the given design attribute.
+func cmdFieldType(att *design.AttributeDefinition, point bool) string {
 	var pointer, suffix string
-	if point && !t.IsArray() {
+	if point && !att.Type.IsArray() {
 		pointer = "*"
 	}
-	suffix = codegen.GoNativeType(t)
+	suffix = codegen.GoNativeTypeAtt(att)
 	return pointer + suffix
 }
 
-// cmdFieldTypeString computes the Go type name used to store command flags of the given design type. Complex types are String
-func cmdFieldTypeString(t design.DataType, point bool) string {
+// cmdFieldTypeString computes the Go type name used to store command flags of the given design attribute. Complex types and numbers with a format are String
+func cmdFieldTypeString(att *design.AttributeDefinition, point bool) string {
 	var pointer, suffix string
+	t := att.Type
 	if point && !t.IsArray() {
 		pointer = "*"
 	}
-	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind {
+	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind || att.NumericFormat() != "" {
 		suffix = "string"
-	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind) {
+	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind) || arrayNumericFormat(att) != "" {
 		suffix = "[]string"
 	} else {
 		suffix = codegen.GoNativeType(t)
@@ -791,6 +858,16 @@ var arrayToStringTmpl *template.Template
 func toString(name, target string, att *design.AttributeDefinition) string {
 	switch actual := att.Type.(type) {
 	case design.Primitive:
+		switch f := att.NumericFormat(); f {
+		case "int32", "int64":
+			return fmt.Sprintf("%s := strconv.FormatInt(int64(%s), 10)", target, name)
+		case "uint", "uint32", "uint64":
+			return fmt.Sprintf("%s := strconv.FormatUint(uint64(%s), 10)", target, name)
+		case "float32":
+			return fmt.Sprintf("%s := strconv.FormatFloat(float64(%s), 'f', -1, 32)", target, name)
+		case "decimal":
+			return fmt.Sprintf("%s := %s", target, name)
+		}
 		switch actual.Kind() {
 		case design.IntegerKind:
 			return fmt.Sprintf("%s := strconv.Itoa(%s)", target, name)
@@ -846,6 +923,8 @@ func signerType(scheme *design.SecuritySchemeDefinition) string {
 		return "goaclient.APIKeySigner"
 	case design.BasicAuthSecurityKind:
 		return "goaclient.BasicSigner"
+	case design.HTTPSigSecurityKind:
+		return "goaclient.HTTPSigSigner"
 	}
 	return ""
 }
@@ -946,6 +1025,114 @@ const (
 
 	payloadTmpl = `// {{ gotypename .Payload nil 0 false }} is the {{ .Parent.Name }} {{ .Name }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
+{{ if .Payload.IsObject }}{{ nullableMarshaler (gotypename .Payload nil 1 false) .Payload }}{{ end }}`
+
+	patchTmpl = `{{ $name := gotypename .Payload nil 0 false }}{{ $target := gotyperef .Patch.Target nil 0 false }}
+// New{{ $name }} builds the {{ .Parent.Name }} {{ .Name }} action patch that turns orig into updated.
+func New{{ $name }}(orig, updated {{ $target }}) ({{ $name }}, error) {
+	var payload {{ $name }}
+	err := goa.Diff{{ if .Patch.IsMergePatch }}MergePatch{{ else }}JSONPatch{{ end }}(orig, updated, &payload)
+	return payload, err
+}
+`
+
+	pageTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}{{ $iter := printf "%sIterator" $funcName }}{{ $cursor := eq (printf "%s" .Pagination.Strategy) "cursor" }}{{ if .Pagination.Envelope }}
+// {{ $funcName }}Page is the envelope of a page of results of the {{ .ResourceName }} {{ .Name }} action.
+type {{ $funcName }}Page struct {
+	Items {{ .PageType }} ` + "`" + `form:"items" json:"items" xml:"items"` + "`" + `
+{{ if $cursor }}	NextCursor *string ` + "`" + `form:"next_cursor,omitempty" json:"next_cursor,omitempty" xml:"next_cursor,omitempty"` + "`" + `
+{{ else }}	Offset int ` + "`" + `form:"offset" json:"offset" xml:"offset"` + "`" + `
+	Limit int ` + "`" + `form:"limit" json:"limit" xml:"limit"` + "`" + `
+	Total *int ` + "`" + `form:"total,omitempty" json:"total,omitempty" xml:"total,omitempty"` + "`" + `
+{{ end }}}
+{{ end }}
+// {{ $iter }} iterates over the pages of results of the {{ .ResourceName }} {{ .Name }} action.
+type {{ $iter }} struct {
+	c     *Client
+	fetch func() (*http.Response, error)
+	seek  func({{ if $cursor }}string{{ else }}int{{ end }})
+	page  {{ .PageType }}
+	done  bool
+	err   error
+}
+
+// New{{ $iter }} returns an iterator over the pages of results of the {{ .ResourceName }} {{ .Name }}
+// action starting with the page requested with the given parameters.
+func (c *Client) New{{ $iter }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) *{{ $iter }} {
+	it := &{{ $iter }}{c: c}
+	it.fetch = func() (*http.Response, error) {
+		return c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+	}
+	it.seek = func(next {{ if $cursor }}string{{ else }}int{{ end }}) {
+		{{ if $cursor }}cursor{{ else }}offset{{ end }} = &next
+	}
+	return it
+}
+
+// Next fetches the next page of results. It returns false once the last page has been fetched or
+// if fetching the page failed in which case Err returns the error.
+func (it *{{ $iter }}) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	resp, err := it.fetch()
+	if err != nil {
+		it.err = err
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		it.err = fmt.Errorf("unexpected response status %s", resp.Status)
+		return false
+	}
+{{ if .Pagination.Envelope }}	var page {{ $funcName }}Page
+	if err := it.c.Decoder.Decode(&page, resp.Body, resp.Header.Get("Content-Type")); err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page.Items
+{{ if $cursor }}	if page.NextCursor == nil || *page.NextCursor == "" {
+		it.done = true
+	} else {
+		it.seek(*page.NextCursor)
+	}
+{{ else }}	next := page.Offset + len(page.Items)
+	if len(page.Items) == 0 || len(page.Items) < page.Limit || page.Total != nil && next >= *page.Total {
+		it.done = true
+	} else {
+		it.seek(next)
+	}
+{{ end }}{{ else }}	page, err := it.c.Decode{{ .PageTypeName }}(resp)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page
+	next, ok := goaclient.NextPageParam(resp, "{{ if $cursor }}cursor{{ else }}offset{{ end }}")
+	if !ok {
+		it.done = true
+		return true
+	}
+{{ if $cursor }}	it.seek(next)
+{{ else }}	offset, err := strconv.Atoi(next)
+	if err != nil {
+		it.err = fmt.Errorf("invalid next page offset %q: %s", next, err)
+		return true
+	}
+	it.seek(offset)
+{{ end }}{{ end }}	return true
+}
+
+// Page returns the page of results fetched by the last call to Next.
+func (it *{{ $iter }}) Page() {{ .PageType }} {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration if any.
+func (it *{{ $iter }}) Err() error {
+	return it.err
+}
 `
 
 	typeDecodeTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
@@ -974,8 +1161,19 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	if err != nil {
 		return nil, err
 	}
-	return c.Client.Do(ctx, req)
-}
+{{ if .Refreshers }}	resp, err := c.Client.Do(ctx, req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && goaclient.Refresh({{ range $i, $r := .Refreshers }}{{ if $i }}, {{ end }}c.{{ $r }}Signer{{ end }}) {
+		// Retry once with refreshed credentials
+		resp.Body.Close()
+		req, err = c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
+		if err != nil {
+			return nil, err
+		}
+		resp, err = c.Client.Do(ctx, req)
+	}
+	return resp, err
+{{ else }}	return c.Client.Do(ctx, req)
+{{ end }}}
 `
 
 	clientsWSTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $desc := .Description }}{{/*
@@ -1013,7 +1211,11 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 {{ range $header := .Headers }}{{ $tmp := tempvar }}	{{ toString $header.VarName $tmp $header.Attribute }}
 	cfg.Header["{{ $header.Name }}"] = []string{ {{ $tmp }} }
-{{ end }}	return websocket.DialConfig(cfg)
+{{ end }}{{ range .Cookies }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
+{{ end }}{{ $tmp := tempvar }}	{{ toString .ValueName $tmp .Attribute }}
+	cfg.Header.Add("Cookie", (&http.Cookie{Name: "{{ .Name }}", Value: {{ $tmp }}}).String())
+{{ if .CheckNil }}	}
+{{ end }}{{ end }}	return websocket.DialConfig(cfg)
 }
 `
 
@@ -1090,7 +1292,7 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 {{ else }}{{ if .HasMultiContent }}	if contentType == "" {
 		contentType = "*/*" // Use default encoder
 	}
-{{ end }}	err := c.Encoder.Encode(payload, &body, {{ if .HasMultiContent }}contentType{{ else }}"*/*"{{ end }})
+{{ end }}	err := c.Encoder.Encode(payload, &body, {{ if .HasMultiContent }}contentType{{ else }}"{{ or .PatchContentType "*/*" }}"{{ end }})
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode body: %s", err)
 	}
@@ -1140,8 +1342,12 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	header.Set("{{ .Name }}", {{ $tmp }}){{ else }}
 	header.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}{{ end }}
-{{ end }}{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
-		if err := c.{{ .Signer }}Signer.Sign(req); err != nil {
+{{ end }}{{ end }}{{ range .Cookies }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
+{{ end }}{{ $tmp := tempvar }}	{{ toString .ValueName $tmp .Attribute }}
+	req.AddCookie(&http.Cookie{Name: "{{ .Name }}", Value: {{ $tmp }}})
+{{ if .CheckNil }}	}
+{{ end }}{{ end }}{{ range .Signers }}	if c.{{ . }}Signer != nil {
+		if err := c.{{ . }}Signer.Sign(req); err != nil {
 			return nil, err
 		}
 	}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_client"
@@ -17,6 +18,9 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// registeredDesign is the API definition registered as root with the DSL engine.
+var registeredDesign = design.Design
+
 var _ = Describe("Generate", func() {
 	const testgenPackagePath = "github.com/goadesign/goa/goagen/gen_client/test_"
 
@@ -174,6 +178,51 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with cookies", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			o := design.Object{
+				"session": &design.AttributeDefinition{Type: design.String},
+				"visits":  &design.AttributeDefinition{Type: design.Integer},
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{Verb: "GET", Path: ""}},
+								Cookies: &design.AttributeDefinition{
+									Type: o,
+									Validation: &dslengine.ValidationDefinition{
+										Required: []string{"session"},
+									},
+								}}},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates code that adds the cookies to the request", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("ShowFoo(ctx context.Context, path string, session string, visits *int)"))
+			Ω(content).Should(ContainSubstring(`req.AddCookie(&http.Cookie{Name: "session", Value: tmp`))
+			Ω(content).Should(ContainSubstring(`if visits != nil {`))
+			Ω(content).Should(ContainSubstring(`req.AddCookie(&http.Cookie{Name: "visits", Value: tmp`))
+		})
+	})
+
 	Context("with querystring params in path", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -510,6 +559,243 @@ var _ = Describe("Generate", func() {
 			return nil, err
 		}`))
 		})
+
+		It("retries unauthorized requests once with refreshed credentials", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("resp.StatusCode == http.StatusUnauthorized && goaclient.Refresh(c.JWT1Signer)"))
+		})
+
+		It("generates the token command and file flags", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`app.PersistentFlags().StringVar(&tokenCmd, "token-cmd", "",`))
+			Ω(content).Should(ContainSubstring("goaclient.FileTokenFetcher(tokenFile, typ)"))
+		})
+	})
+
+	Context("with an action with mutual TLS security configured", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			securitySchemeDef := &design.SecuritySchemeDefinition{
+				SchemeName: "mtls",
+				Kind:       design.MTLSSecurityKind,
+				Type:       "mutualTLS",
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				SecuritySchemes: []*design.SecuritySchemeDefinition{
+					securitySchemeDef,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Security: &design.SecurityDefinition{
+									Scheme: securitySchemeDef,
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("does not generate a signer", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).ShouldNot(ContainSubstring("MtlsSigner"))
+		})
+
+		It("generates the client certificate flags", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`app.PersistentFlags().StringVar(&certFile, "cert", "",`))
+			Ω(content).Should(ContainSubstring("goaclient.NewMTLSConfig(certFile, certKeyFile, caFile)"))
+		})
+	})
+
+	Context("with an action with HTTP message signature security configured", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			securitySchemeDef := &design.SecuritySchemeDefinition{
+				SchemeName: "sig",
+				Kind:       design.HTTPSigSecurityKind,
+				Type:       "apiKey",
+				In:         "header",
+				Name:       "Signature",
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				SecuritySchemes: []*design.SecuritySchemeDefinition{
+					securitySchemeDef,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Security: &design.SecurityDefinition{
+									Scheme: securitySchemeDef,
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("signs the requests", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("SigSigner goaclient.Signer"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("c.SigSigner.Sign(req)"))
+		})
+
+		It("generates the signing key flags", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`app.PersistentFlags().StringVar(&sigKeyID, "sig-key-id", "",`))
+			Ω(content).Should(ContainSubstring("goaclient.NewHTTPSigSigner(keyID, alg, key)"))
+			Ω(content).ShouldNot(ContainSubstring("goaclient.APIKeySigner"))
+		})
+	})
+
+	Context("with a paginated action", func() {
+		BeforeEach(func() {
+			bottle := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"name": {Type: design.String}},
+					},
+					TypeName: "Bottle",
+				},
+				Identifier: "application/vnd.bottle",
+			}
+			bottle.Views = map[string]*design.ViewDefinition{
+				"default": {AttributeDefinition: bottle.AttributeDefinition, Name: "default", Parent: bottle},
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					design.CanonicalIdentifier(bottle.Identifier): bottle,
+				},
+			}
+			design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+			bottles := apidsl.CollectionOf(bottle)
+			dslengine.Execute(bottles.DSL(), bottles)
+			params := &design.AttributeDefinition{
+				Type: design.Object{
+					"cursor": {Type: design.String},
+					"limit":  {Type: design.Integer},
+				},
+			}
+			design.Design.Resources = map[string]*design.ResourceDefinition{
+				"foo": {
+					Name: "foo",
+					Actions: map[string]*design.ActionDefinition{
+						"list": {
+							Name:        "list",
+							Routes:      []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+							Params:      params,
+							QueryParams: params,
+							Pagination:  &design.PaginationDefinition{Strategy: design.Cursor},
+							Responses: map[string]*design.ResponseDefinition{
+								"OK": {Name: "OK", Status: 200, Type: bottles, MediaType: bottles.Identifier},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+			listAct.Pagination.Parent = listAct
+		})
+
+		It("generates a page iterator", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func (c *Client) NewListFooIterator(ctx context.Context, path string, cursor *string, limit *int) *ListFooIterator {"))
+			Ω(content).Should(ContainSubstring(`next, ok := goaclient.NextPageParam(resp, "cursor")`))
+			Ω(content).Should(ContainSubstring("func (it *ListFooIterator) Page() BottleCollection {"))
+		})
+	})
+
+	Context("with a filter on an enum attribute", func() {
+		BeforeEach(func() {
+			// Other specs replace the design, restore the one registered with the DSL engine.
+			design.Design = registeredDesign
+			dslengine.Reset()
+			apidsl.API("test", nil)
+			status := apidsl.Type("status", design.String, func() {
+				apidsl.Enum("open", "sealed")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("status", status)
+					apidsl.Filterable("status", design.Eq)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("status")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET("/bottles"))
+					apidsl.Response(design.OK, apidsl.CollectionOf(bottle))
+				})
+			})
+			Ω(dslengine.Run()).Should(Succeed())
+		})
+
+		It("sends the filter as a string query parameter", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "bottle.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func (c *Client) ListBottle(ctx context.Context, path string, filterStatusEq *string) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring(`values.Set("filter[status][eq]", *filterStatusEq)`))
+		})
 	})
 
 	Context("with an action with a user type payload", func() {
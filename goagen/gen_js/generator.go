@@ -1,11 +1,13 @@
 package genjs
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"text/template"
@@ -151,6 +153,20 @@ func (g *Generator) generateJS(jsFile string) (_ *design.ActionDefinition, err e
 		return
 	}
 
+	var enums []*design.UserTypeDefinition
+	g.API.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if ut.IsEnum() {
+			enums = append(enums, ut)
+		}
+		return nil
+	})
+	if len(enums) > 0 {
+		funcs := template.FuncMap{"enumValueNames": codegen.EnumValueNames, "jsValue": jsValue}
+		if err = file.ExecuteTemplate("enums", enumsT, funcs, enums); err != nil {
+			return
+		}
+	}
+
 	actions := make(map[string][]*design.ActionDefinition)
 	g.API.IterateResources(func(res *design.ResourceDefinition) error {
 		return res.IterateActions(func(action *design.ActionDefinition) error {
@@ -175,7 +191,7 @@ func (g *Generator) generateJS(jsFile string) (_ *design.ActionDefinition, err e
 				exampleAction = a
 			}
 			data := map[string]interface{}{"Action": a}
-			funcs := template.FuncMap{"params": params}
+			funcs := template.FuncMap{"params": params, "paramVars": paramVars}
 			if err = file.ExecuteTemplate("jsFuncs", jsFuncsT, funcs, data); err != nil {
 				return
 			}
@@ -199,8 +215,8 @@ func (g *Generator) generateIndexHTML(htmlFile string, exampleAction *design.Act
 	if len(argNames) > 0 {
 		query := exampleAction.QueryParams.Type.ToObject()
 		argValues := make([]string, len(argNames))
-		for i, n := range argNames {
-			ex := query[n].GenerateExample(g.API.RandomGenerator(), nil)
+		for i, p := range argNames {
+			ex := query[p.Name].GenerateExample(g.API.RandomGenerator(), nil)
 			argValues[i] = fmt.Sprintf("%v", ex)
 		}
 		args = strings.Join(argValues, ", ")
@@ -279,20 +295,55 @@ func (g *Generator) Cleanup() {
 	g.genfiles = nil
 }
 
-func params(action *design.ActionDefinition) []string {
+// jsParam is a query string parameter of a JavaScript client function.
+type jsParam struct {
+	// Name is the name of the parameter in the query string.
+	Name string
+	// Var is the name of the function argument that holds the value of the parameter.
+	Var string
+}
+
+// jsIdentifierRegex matches the names that are valid JavaScript identifiers.
+var jsIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// params returns the query string parameters of the action sorted by name. The names that are not
+// valid JavaScript identifiers such as "filter[name][eq]" are camel cased to name the function
+// arguments.
+func params(action *design.ActionDefinition) []*jsParam {
 	if action.QueryParams == nil {
 		return nil
 	}
-	params := make([]string, len(action.QueryParams.Type.ToObject()))
-	i := 0
+	names := make([]string, 0, len(action.QueryParams.Type.ToObject()))
 	for n := range action.QueryParams.Type.ToObject() {
-		params[i] = n
-		i++
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	params := make([]*jsParam, len(names))
+	for i, n := range names {
+		v := n
+		if !jsIdentifierRegex.MatchString(n) {
+			v = codegen.Goify(n, false)
+		}
+		params[i] = &jsParam{Name: n, Var: v}
 	}
-	sort.Strings(params)
 	return params
 }
 
+// paramVars returns the names of the function arguments that hold the values of params.
+func paramVars(params []*jsParam) string {
+	vars := make([]string, len(params))
+	for i, p := range params {
+		vars[i] = p.Var
+	}
+	return strings.Join(vars, ", ")
+}
+
+// jsValue returns the JavaScript literal of the given enum value.
+func jsValue(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
 const moduleT = `// This module exports functions that give access to the {{.API.Name}} API hosted at {{.API.Host}}.
 // It uses the axios javascript library for making the actual HTTP requests.
 define(['axios'] , function (axios) {
@@ -315,6 +366,14 @@ define(['axios'] , function (axios) {
     var urlPrefix = scheme + '://' + host;
 `
 
+const enumsT = `{{ range . }}
+  // {{ goify .TypeName true }} lists the values of the {{ .TypeName }} enum type.
+  client.{{ goify .TypeName true }} = Object.freeze({
+{{ $names := enumValueNames . }}{{ range $i, $v := .Validation.Values }}{{ if $i }},
+{{ end }}    {{ index $names $i }}: {{ jsValue $v }}{{ end }}
+  });
+{{ end }}`
+
 const moduleTend = `  return client;
   };
 });
@@ -324,18 +383,18 @@ const jsFuncsT = `{{$params := params .Action}}
   {{$name := printf "%s%s" .Action.Name (title .Action.Parent.Name)}}// {{if .Action.Description}}{{.Action.Description}}{{else}}{{$name}} calls the {{.Action.Name}} action of the {{.Action.Parent.Name}} resource.{{end}}
   // path is the request path, the format is "{{(index .Action.Routes 0).FullPath}}"
   {{if .Action.Payload}}// data contains the action payload (request body)
-  {{end}}{{if $params}}// {{join $params ", "}} {{if gt (len $params) 1}}are{{else}}is{{end}} used to build the request query string.
+  {{end}}{{if $params}}// {{paramVars $params}} {{if gt (len $params) 1}}are{{else}}is{{end}} used to build the request query string.
   {{end}}// config is an optional object to be merged into the config built by the function prior to making the request.
   // The content of the config object is described here: https://github.com/mzabriskie/axios#request-api
   // This function returns a promise which raises an error if the HTTP response is a 4xx or 5xx.
-  client.{{$name}} = function (path{{if .Action.Payload}}, data{{end}}{{if $params}}, {{join $params ", "}}{{end}}, config) {
+  client.{{$name}} = function (path{{if .Action.Payload}}, data{{end}}{{if $params}}, {{paramVars $params}}{{end}}, config) {
     var cfg = {
       timeout: timeout,
       url: urlPrefix + path,
       method: '{{toLower (index .Action.Routes 0).Verb}}',
 {{if $params}}      params: {
 {{range $index, $param := $params}}{{if $index}},
-{{end}}        {{$param}}: {{$param}}{{end}}
+{{end}}        {{if eq $param.Name $param.Var}}{{$param.Name}}{{else}}'{{$param.Name}}'{{end}}: {{$param.Var}}{{end}}
       },
 {{end}}{{if .Action.Payload}}    data: data,
 {{end}}      responseType: 'json'
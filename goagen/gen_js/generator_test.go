@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/gen_js"
 	"github.com/goadesign/goa/version"
 	. "github.com/onsi/ginkgo"
@@ -56,6 +57,34 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an enum type", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:  "testapi",
+				Title: "dummy API with an enum type",
+				Types: map[string]*design.UserTypeDefinition{
+					"WineStatus": {
+						TypeName: "WineStatus",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.String,
+							Validation: &dslengine.ValidationDefinition{
+								Values: []interface{}{"open", "in-cellar"},
+							},
+							Metadata: dslengine.MetadataDefinition{"enum:names": {"Opened", "Cellared"}},
+						},
+					},
+				},
+			}
+		})
+
+		It("generates the enum values", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "js", "client.js"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("client.WineStatus = Object.freeze({\n    Opened: \"open\",\n    Cellared: \"in-cellar\"\n  });"))
+		})
+	})
+
 	Context("with an example action with query parameters", func() {
 		BeforeEach(func() {
 			action := &design.ActionDefinition{
@@ -98,6 +127,26 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(len(strings.Split(string(content), "\n"))).Should(BeNumerically(">=", 13))
 		})
+
+		Context("whose names are not valid identifiers", func() {
+			BeforeEach(func() {
+				query := design.Object{
+					"query":              {Type: design.String},
+					"filter[status][eq]": {Type: design.String},
+				}
+				action := design.Design.Resources["bottle"].Actions["show"]
+				action.Params.Type = query
+				action.QueryParams.Type = query
+			})
+
+			It("names the function arguments with identifiers", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "js", "client.js"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("client.showBottle = function (path, filterStatusEq, query, config) {"))
+				Ω(string(content)).Should(ContainSubstring("        'filter[status][eq]': filterStatusEq,\n        query: query\n"))
+			})
+		})
 	})
 })
 
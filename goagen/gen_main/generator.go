@@ -17,6 +17,7 @@ import (
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/ir"
 	"github.com/goadesign/goa/goagen/utils"
 )
 
@@ -141,17 +142,7 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		return "", err
 	}
 
-	var file *codegen.SourceFile
-	file, err = codegen.SourceFileFor(filename)
-	if err != nil {
-		return "", err
-	}
-	defer func() {
-		file.Close()
-		if err == nil {
-			err = file.FormatCode()
-		}
-	}()
+	file := ir.NewFile(filename)
 
 	elems := strings.Split(appPkg, "/")
 	pkgName := elems[len(elems)-1]
@@ -201,6 +192,9 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 	if err != nil {
 		return "", err
 	}
+	if _, err = ir.Render(ir.Controller, []*ir.File{file}); err != nil {
+		return "", err
+	}
 	return
 }
 
@@ -234,7 +228,13 @@ func (g *Generator) Generate() (_ []string, err error) {
 		if err = os.MkdirAll(g.OutDir, 0755); err != nil {
 			return nil, err
 		}
-		if err = g.createMainFile(mainFile, funcMap(g.Target, nil)); err != nil {
+		var file *ir.File
+		if file, err = g.createMainFile(mainFile, funcMap(g.Target, nil)); err != nil {
+			return nil, err
+		}
+		paths, err := ir.Render(ir.Main, []*ir.File{file})
+		g.genfiles = append(g.genfiles, paths...)
+		if err != nil {
 			return nil, err
 		}
 	}
@@ -263,19 +263,8 @@ func (g *Generator) Cleanup() {
 	g.genfiles = nil
 }
 
-func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err error) {
-	var file *codegen.SourceFile
-	file, err = codegen.SourceFileFor(mainFile)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		file.Close()
-		if err == nil {
-			err = file.FormatCode()
-		}
-	}()
-	g.genfiles = append(g.genfiles, mainFile)
+func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (*ir.File, error) {
+	file := ir.NewFile(mainFile)
 	funcs["getPort"] = func(hostport string) string {
 		_, port, err := net.SplitHostPort(hostport)
 		if err != nil {
@@ -285,7 +274,7 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 	}
 	outPkg, err := codegen.PackagePath(g.OutDir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	appPkg := path.Join(outPkg, "app")
 	imports := []*codegen.ImportSpec{
@@ -296,7 +285,7 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 	}
 	file.Write([]byte("//go:generate goagen bootstrap -d " + g.DesignPkg + "\n\n"))
 	if err = file.WriteHeader("", "main", imports); err != nil {
-		return err
+		return nil, err
 	}
 	tls := false
 	for _, scheme := range g.API.Schemes {
@@ -309,8 +298,10 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 		"API":  g.API,
 		"TLS":  tls,
 	}
-	err = file.ExecuteTemplate("main", mainT, funcs, data)
-	return
+	if err = file.ExecuteTemplate("main", mainT, funcs, data); err != nil {
+		return nil, err
+	}
+	return file, nil
 }
 
 // tempCount is the counter used to create unique temporary variable names.
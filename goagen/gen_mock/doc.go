@@ -0,0 +1,17 @@
+/*
+Package genmock generates a mock server that implements every action of the API with example
+responses. The mock server mounts the controllers of the package generated by "goagen app" so
+that requests are validated against the design: the parameters are coerced and validated and the
+payloads decoded and validated before the mock actions run.
+
+Each action answers with an example of one of its responses. The examples are produced by the
+design example generator, they use the values given with the Example DSL and are deterministic
+for a given seed. Requests select the response with the X-Mock-Response header whose value is
+either the response name as defined in the design, e.g. "NotFound", or its status code, e.g.
+"404". Actions send their first 2xx response when the header is missing.
+
+The generator creates the "mock" directory under the output directory and writes a main.go file,
+a mock.go file containing the helpers shared by the controllers and one file per resource. The
+directory is deleted and re-created each time the generator runs.
+*/
+package genmock
@@ -0,0 +1,407 @@
+package genmock
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/ir"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a Mock Server Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+type (
+	// Generator is the mock server generator.
+	Generator struct {
+		API       *design.APIDefinition // The API definition
+		OutDir    string                // Path to output directory
+		DesignPkg string                // Path to design package, only used to mark generated files.
+		AppPkg    string                // Import path of generated "app" package, may be relative to OutDir
+		Seed      string                // Seed of the example generator, defaults to the API name
+		genfiles  []string              // Generated files
+	}
+
+	// actionData is the data used to render a mock action.
+	actionData struct {
+		// Action is the action definition.
+		Action *design.ActionDefinition
+		// Var is the name of the variable that holds the action example responses.
+		Var string
+		// Responses lists the example responses sorted by status.
+		Responses []*mockResponse
+	}
+
+	// mockResponse is an example response.
+	mockResponse struct {
+		// Name is the response name.
+		Name string
+		// Status is the response HTTP status code.
+		Status int
+		// ContentType is the value of the Content-Type header if any.
+		ContentType string
+		// Headers indexes the example values of the response headers by name.
+		Headers map[string]string
+		// Body is the JSON encoded example body, empty if the response has no body.
+		Body string
+	}
+)
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, designPkg, appPkg, seed, ver string
+
+	set := flag.NewFlagSet("mock", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&designPkg, "design", "", "")
+	set.StringVar(&appPkg, "app-pkg", "app", "")
+	set.StringVar(&seed, "seed", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("pkg", "", "")
+	set.String("tooldir", "", "")
+	set.String("tool", "", "")
+	set.Bool("notool", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, DesignPkg: designPkg, AppPkg: appPkg, Seed: seed, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the mock server.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.AppPkg == "" {
+		g.AppPkg = "app"
+	}
+	if g.Seed == "" {
+		g.Seed = g.API.Name
+	}
+	elems := strings.Split(g.AppPkg, "/")
+	pkgName := elems[len(elems)-1]
+	codegen.Reserved[pkgName] = true
+
+	appImport, err := g.appImport()
+	if err != nil {
+		return nil, err
+	}
+	g.OutDir = filepath.Join(g.OutDir, "mock")
+	if err = os.RemoveAll(g.OutDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+	funcs := template.FuncMap{"targetPkg": func() string { return pkgName }}
+
+	main := ir.NewFile(filepath.Join(g.OutDir, "main.go"))
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
+		codegen.SimpleImport(appImport),
+	}
+	main.WriteHeader(fmt.Sprintf("%s: Mock Server", g.API.Context()), "main", imports)
+	port := "8080"
+	if _, p, err := net.SplitHostPort(g.API.Host); err == nil {
+		port = p
+	}
+	data := map[string]interface{}{"API": g.API, "Port": port}
+	main.ExecuteTemplate("main", mainT, funcs, data)
+
+	helpers := ir.NewFile(filepath.Join(g.OutDir, "mock.go"))
+	imports = []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("strings"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	helpers.WriteHeader(fmt.Sprintf("%s: Mock Server Helpers", g.API.Context()), "main", imports)
+	helpers.ExecuteTemplate("helpers", helpersT, nil, nil)
+
+	files := []*ir.File{main, helpers}
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		file := ir.NewFile(filepath.Join(g.OutDir, codegen.SnakeCase(r.Name)+".go"))
+		imports := []*codegen.ImportSpec{
+			codegen.SimpleImport("github.com/goadesign/goa"),
+			codegen.SimpleImport(appImport),
+		}
+		title := fmt.Sprintf("%s: %s Mock Controller", g.API.Context(), r.Name)
+		file.WriteHeader(title, "main", imports)
+		file.ExecuteTemplate("controller", ctrlT, funcs, r)
+		err := r.IterateActions(func(a *design.ActionDefinition) error {
+			responses, err := g.responses(a)
+			if err != nil {
+				return err
+			}
+			data := &actionData{
+				Action:    a,
+				Var:       codegen.Goify(r.Name, false) + codegen.Goify(a.Name, true) + "Responses",
+				Responses: responses,
+			}
+			return file.ExecuteTemplate("action", actionT, funcs, data)
+		})
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := ir.Render(ir.Mock, files)
+	g.genfiles = append(g.genfiles, paths...)
+	if err != nil {
+		return nil, err
+	}
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// appImport returns the import path of the generated app package.
+func (g *Generator) appImport() (string, error) {
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err == nil {
+		return g.AppPkg, nil
+	}
+	imp, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(filepath.ToSlash(imp), g.AppPkg), nil
+}
+
+// responses returns the example responses of the given action sorted by status. The example
+// generator is seeded with the seed, resource, action and response names so that the examples
+// of a response do not depend on the other actions.
+func (g *Generator) responses(a *design.ActionDefinition) ([]*mockResponse, error) {
+	var res []*mockResponse
+	err := a.IterateResponses(func(r *design.ResponseDefinition) error {
+		rand := design.NewRandomGenerator(strings.Join([]string{g.Seed, a.Parent.Name, a.Name, r.Name}, "/"))
+		resp := &mockResponse{Name: r.Name, Status: r.Status, ContentType: r.MediaType}
+		if r.Headers != nil {
+			headers := r.Headers.Type.ToObject()
+			names := make([]string, 0, len(headers))
+			for n := range headers {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			for _, n := range names {
+				if ex := headers[n].GenerateExample(rand, nil); ex != nil {
+					if resp.Headers == nil {
+						resp.Headers = make(map[string]string)
+					}
+					resp.Headers[n] = fmt.Sprint(ex)
+				}
+			}
+		}
+
+		var example interface{}
+		mt, ok := r.Type.(*design.MediaTypeDefinition)
+		switch {
+		case r.Type != nil && !ok:
+			example = (&design.AttributeDefinition{Type: r.Type}).GenerateExample(rand, nil)
+		case !ok:
+			mt = g.API.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt != nil {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			projected, _, err := mt.Project(view)
+			if err != nil {
+				return err
+			}
+			example = projected.GenerateExample(rand, nil)
+			resp.ContentType = mt.ContentType
+		}
+		if example != nil {
+			b, err := json.Marshal(example)
+			if err != nil {
+				return fmt.Errorf("failed to encode example of %s: %s", r.Context(), err)
+			}
+			resp.Body = string(b)
+		}
+		res = append(res, resp)
+		return nil
+	})
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Status < res[j].Status })
+	return res, err
+}
+
+const mainT = `
+func main() {
+	addr := flag.String("addr", ":{{ .Port }}", "address the mock server listens on")
+	flag.Parse()
+
+	// Create service
+	service := goa.New({{ printf "%q" .API.Name }})
+
+	// Mount middleware
+	service.Use(middleware.RequestID())
+	service.Use(middleware.LogRequest(true))
+	service.Use(middleware.ErrorHandler(service, true))
+	service.Use(middleware.Recover())
+{{ range $name, $scheme := .API.SecuritySchemes }}
+	// The mock server does not authenticate requests.
+	{{ targetPkg }}.Use{{ goify $scheme.SchemeName true }}Middleware(service, allowAll)
+{{ end }}
+{{ range $name, $res := .API.Resources }}{{ $name := goify $res.Name true }}	// Mount "{{ $res.Name }}" controller
+	{{ targetPkg }}.Mount{{ $name }}Controller(service, New{{ $name }}Controller(service))
+{{ end }}
+	// Start service
+	service.LogInfo("mock", "addr", *addr, "scenario header", ScenarioHeader)
+	if err := service.ListenAndServe(*addr); err != nil {
+		service.LogError("startup", "err", err)
+	}
+}
+`
+
+const helpersT = `
+// ScenarioHeader is the name of the request header that selects the response sent by the mock
+// actions. Its value is the name of a response, e.g. "NotFound", or its status code, e.g. "404".
+const ScenarioHeader = "X-Mock-Response"
+
+// mockResponse is an example response.
+type mockResponse struct {
+	// Name is the response name.
+	Name string
+	// Status is the response HTTP status code.
+	Status int
+	// ContentType is the value of the Content-Type header if any.
+	ContentType string
+	// Headers indexes the example values of the response headers by name.
+	Headers map[string]string
+	// Body is the JSON encoded example body, empty if the response has no body.
+	Body string
+}
+
+// respond sends the response selected by the request scenario header.
+func respond(ctx context.Context, rd *goa.ResponseData, req *goa.RequestData, responses []*mockResponse) error {
+	resp, err := selectResponse(req.Header.Get(ScenarioHeader), responses)
+	if err != nil {
+		return err
+	}
+	for name, value := range resp.Headers {
+		rd.Header().Set(name, value)
+	}
+	if resp.ContentType != "" && rd.Header().Get("Content-Type") == "" {
+		rd.Header().Set("Content-Type", resp.ContentType)
+	}
+	if resp.Body == "" {
+		rd.WriteHeader(resp.Status)
+		return nil
+	}
+	var body interface{}
+	if err := json.Unmarshal([]byte(resp.Body), &body); err != nil {
+		return err
+	}
+	return rd.Service.Send(ctx, resp.Status, body)
+}
+
+// selectResponse returns the response with the given name or status, the first 2xx response if
+// scenario is empty.
+func selectResponse(scenario string, responses []*mockResponse) (*mockResponse, error) {
+	if len(responses) == 0 {
+		return &mockResponse{Status: 204}, nil
+	}
+	if scenario == "" {
+		for _, resp := range responses {
+			if resp.Status >= 200 && resp.Status < 300 {
+				return resp, nil
+			}
+		}
+		return responses[0], nil
+	}
+	names := make([]string, len(responses))
+	for i, resp := range responses {
+		if resp.Name == scenario || strconv.Itoa(resp.Status) == scenario {
+			return resp, nil
+		}
+		names[i] = resp.Name
+	}
+	return nil, goa.ErrBadRequest("unknown "+ScenarioHeader+" value", "value", scenario, "expected", strings.Join(names, ", "))
+}
+
+// allowAll is the auth middleware used for all the security schemes.
+func allowAll(h goa.Handler) goa.Handler {
+	return h
+}
+`
+
+const ctrlT = `// {{ $ctrlName := printf "%s%s" (goify .Name true) "Controller" }}{{ $ctrlName }} implements the {{ .Name }} resource with example responses.
+type {{ $ctrlName }} struct {
+	*goa.Controller
+}
+
+// New{{ $ctrlName }} creates a {{ .Name }} mock controller.
+func New{{ $ctrlName }}(service *goa.Service) *{{ $ctrlName }} {
+	return &{{ $ctrlName }}{Controller: service.NewController("{{ $ctrlName }}")}
+}
+`
+
+const actionT = `{{ $a := .Action }}
+{{- $ctrlName := printf "%s%s" (goify $a.Parent.Name true) "Controller" }}
+// {{ goify $a.Name true }} sends an example response of the {{ $a.Name }} action.
+func (c *{{ $ctrlName }}) {{ goify $a.Name true }}(ctx *{{ targetPkg }}.{{ goify $a.Name true }}{{ goify $a.Parent.Name true }}Context) error {
+	return respond(ctx.Context, ctx.ResponseData, ctx.RequestData, {{ .Var }})
+}
+
+// {{ .Var }} lists the example responses of the {{ $a.Name }} action.
+var {{ .Var }} = []*mockResponse{
+{{ range .Responses }}	{
+		Name:   {{ printf "%q" .Name }},
+		Status: {{ .Status }},
+{{ if .ContentType }}		ContentType: {{ printf "%q" .ContentType }},
+{{ end }}{{ if .Headers }}		Headers: map[string]string{
+{{ range $name, $value := .Headers }}			{{ printf "%q" $name }}: {{ printf "%q" $value }},
+{{ end }}		},
+{{ end }}{{ if .Body }}		Body: {{ printf "%q" .Body }},
+{{ end }}	},
+{{ end }}}
+`
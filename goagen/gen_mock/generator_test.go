@@ -0,0 +1,127 @@
+package genmock_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_mock"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(workspace.Path, "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--app-pkg=github.com/goadesign/goa", "--version=" + version.String()}
+		dslengine.Reset()
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		files, genErr = genmock.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	read := func(name string) string {
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "mock", name))
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(content)
+	}
+
+	Context("with a design", func() {
+		BeforeEach(func() {
+			API("cellar", func() {
+				Host("localhost:8081")
+			})
+			BottleMedia := MediaType("application/vnd.goa.example.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer, func() {
+						Example(42)
+					})
+					Attribute("name", String, func() {
+						Example("Number 8")
+					})
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottles", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Response(OK, func() {
+						Media(BottleMedia, "tiny")
+						Headers(func() {
+							Header("X-Request-Count", Integer, func() {
+								Example(3)
+							})
+						})
+					})
+					Response(NotFound)
+				})
+				Action("delete", func() {
+					Routing(DELETE("/:id"))
+				})
+			})
+		})
+
+		It("generates the main, helpers and controller files", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(ConsistOf(
+				filepath.Join(outDir, "mock"),
+				filepath.Join(outDir, "mock", "main.go"),
+				filepath.Join(outDir, "mock", "mock.go"),
+				filepath.Join(outDir, "mock", "bottles.go"),
+			))
+		})
+
+		It("listens on the API host port", func() {
+			Ω(read("main.go")).Should(ContainSubstring(`flag.String("addr", ":8081"`))
+			Ω(read("main.go")).Should(ContainSubstring("goa.MountBottlesController(service, NewBottlesController(service))"))
+		})
+
+		It("defines the scenario header", func() {
+			Ω(read("mock.go")).Should(ContainSubstring(`const ScenarioHeader = "X-Mock-Response"`))
+		})
+
+		It("generates the example responses", func() {
+			content := read("bottles.go")
+			Ω(content).Should(ContainSubstring("func (c *BottlesController) Show(ctx *goa.ShowBottlesContext) error"))
+			Ω(content).Should(ContainSubstring(`Body: "{\"id\":42}"`))
+			Ω(content).Should(ContainSubstring(`ContentType: "application/vnd.goa.example.bottle"`))
+			Ω(content).Should(ContainSubstring(`"X-Request-Count": "3"`))
+			Ω(content).Should(ContainSubstring(`Name:   "NotFound"`))
+			Ω(content).Should(ContainSubstring("Status: 404"))
+			Ω(content).Should(ContainSubstring("var bottlesDeleteResponses = []*mockResponse{}"))
+		})
+
+		It("generates the same examples for the same seed", func() {
+			first := read("bottles.go")
+			_, err := genmock.Generate()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(read("bottles.go")).Should(Equal(first))
+		})
+	})
+})
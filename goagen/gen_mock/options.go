@@ -0,0 +1,41 @@
+package genmock
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//DesignPkg Path to design package, only used to mark generated files.
+func DesignPkg(designPkg string) Option {
+	return func(g *Generator) {
+		g.DesignPkg = designPkg
+	}
+}
+
+//AppPkg Import path of generated "app" package, may be relative to the output directory
+func AppPkg(pkg string) Option {
+	return func(g *Generator) {
+		g.AppPkg = pkg
+	}
+}
+
+//Seed Seed of the example generator
+func Seed(seed string) Option {
+	return func(g *Generator) {
+		g.Seed = seed
+	}
+}
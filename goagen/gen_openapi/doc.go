@@ -0,0 +1,7 @@
+/*
+Package genopenapi provides a generator for the OpenAPI 3.1 specification of the API.
+The generator produces both a JSON and a YAML version of the specification. It is built from the
+same API definition as the Swagger 2.0 specification produced by the genswagger package and honors
+the same "swagger:*" metadata. See https://spec.openapis.org/oas/v3.1.0 for more information.
+*/
+package genopenapi
@@ -0,0 +1,161 @@
+package genopenapi
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of an OpenAPI Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the OpenAPI specification generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, toolDir, target, ver string
+		notool, regen                bool
+	)
+
+	set := flag.NewFlagSet("openapi", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.StringVar(&toolDir, "tooldir", "tool", "")
+	set.BoolVar(&notool, "notool", false, "")
+	set.StringVar(&target, "pkg", "app", "")
+	set.BoolVar(&regen, "regen", false, "")
+	set.Bool("force", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the OpenAPI specification files.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	openapiDir := filepath.Join(g.OutDir, "openapi")
+	os.RemoveAll(openapiDir)
+	if err = os.MkdirAll(openapiDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, openapiDir)
+
+	if !g.API.IsVersioned() {
+		if err = g.generateSpec(g.API, openapiDir); err != nil {
+			return nil, err
+		}
+		return g.genfiles, nil
+	}
+
+	// The top level specification describes the latest version, each version is also described
+	// in the directory named after it.
+	for _, v := range g.API.Versions {
+		err = g.API.WithVersion(v.Name, func(api *design.APIDefinition) error {
+			if v.Name == g.API.Version {
+				if err := g.generateSpec(api, openapiDir); err != nil {
+					return err
+				}
+			}
+			dir := filepath.Join(openapiDir, v.Name)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			g.genfiles = append(g.genfiles, dir)
+			return g.generateSpec(api, dir)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return g.genfiles, nil
+}
+
+// generateSpec writes the JSON and YAML OpenAPI specifications of the given API definition to dir.
+func (g *Generator) generateSpec(api *design.APIDefinition, dir string) error {
+	genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	s, err := New(api)
+	if err != nil {
+		return err
+	}
+
+	// JSON
+	rawJSON, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	openapiFile := filepath.Join(dir, "openapi.json")
+	if err := ioutil.WriteFile(openapiFile, rawJSON, 0644); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, openapiFile)
+
+	// YAML
+	var yamlSource interface{}
+	if err = json.Unmarshal(rawJSON, &yamlSource); err != nil {
+		return err
+	}
+
+	rawYAML, err := yaml.Marshal(yamlSource)
+	if err != nil {
+		return err
+	}
+	openapiFile = filepath.Join(dir, "openapi.yaml")
+	if err := ioutil.WriteFile(openapiFile, rawYAML, 0644); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, openapiFile)
+
+	return nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
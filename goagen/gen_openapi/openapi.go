@@ -0,0 +1,974 @@
+package genopenapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_schema"
+)
+
+// Version is the version of the OpenAPI specification produced by the generator.
+const Version = "3.1.0"
+
+type (
+	// OpenAPI represents an instance of an OpenAPI 3.1 document.
+	// See https://spec.openapis.org/oas/v3.1.0
+	OpenAPI struct {
+		OpenAPI      string                 `json:"openapi"`
+		Info         *Info                  `json:"info"`
+		Servers      []*Server              `json:"servers,omitempty"`
+		Paths        map[string]*PathItem   `json:"paths"`
+		Components   *Components            `json:"components,omitempty"`
+		Tags         []*Tag                 `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty"`
+		Extensions   map[string]interface{} `json:"-"`
+	}
+
+	// Info provides metadata about the API.
+	Info struct {
+		Title          string                    `json:"title"`
+		Description    string                    `json:"description,omitempty"`
+		TermsOfService string                    `json:"termsOfService,omitempty"`
+		Contact        *design.ContactDefinition `json:"contact,omitempty"`
+		License        *design.LicenseDefinition `json:"license,omitempty"`
+		Version        string                    `json:"version"`
+		Extensions     map[string]interface{}    `json:"-"`
+	}
+
+	// Server represents a server hosting the API.
+	Server struct {
+		// URL of the server.
+		URL string `json:"url"`
+		// Description of the server.
+		Description string `json:"description,omitempty"`
+	}
+
+	// PathItem describes the operations available on a single path.
+	PathItem struct {
+		// Get defines a GET operation on this path.
+		Get *Operation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *Operation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *Operation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *Operation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *Operation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *Operation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *Operation `json:"patch,omitempty"`
+		// Trace defines a TRACE operation on this path.
+		Trace *Operation `json:"trace,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Operation describes a single API operation on a path.
+	Operation struct {
+		// Tags is a list of tags for API documentation control.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is a list of parameters that are applicable for this operation.
+		Parameters []*Parameter `json:"parameters,omitempty"`
+		// RequestBody describes the request body.
+		RequestBody *RequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses.
+		Responses map[string]*Response `json:"responses"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security lists the alternative security requirements of the operation.
+		Security []map[string][]string `json:"security,omitempty"`
+		// Servers overrides the API servers for this operation.
+		Servers []*Server `json:"servers,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Parameter describes a single operation parameter.
+	Parameter struct {
+		// Name of the parameter.
+		Name string `json:"name"`
+		// In is the location of the parameter: "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required,omitempty"`
+		// Deprecated declares this parameter to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Schema defines the type used for the parameter.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// RequestBody describes a request body.
+	RequestBody struct {
+		// Description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content maps the request body media types to their schemas.
+		Content map[string]*MediaType `json:"content"`
+		// Required determines whether the body is mandatory.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// MediaType describes the content of a request or response body for a given media type.
+	MediaType struct {
+		// Schema defines the body content.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Response describes an operation response.
+	Response struct {
+		// Description of the response.
+		Description string `json:"description"`
+		// Headers is a list of headers that are sent with the response.
+		Headers map[string]*Header `json:"headers,omitempty"`
+		// Content maps the response body media types to their schemas.
+		Content map[string]*MediaType `json:"content,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// Header describes a response header.
+	Header struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Required determines whether the header is always sent.
+		Required bool `json:"required,omitempty"`
+		// Schema defines the type used for the header.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Components holds the reusable objects referenced by the document.
+	Components struct {
+		// Schemas contains the user types and media types views schemas.
+		Schemas map[string]*genschema.JSONSchema `json:"schemas,omitempty"`
+		// Responses contains the API level responses.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// SecuritySchemes contains the API security schemes.
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme defines a security scheme that can be used by the operations.
+	SecurityScheme struct {
+		// Type of the security scheme: "apiKey", "http", "mutualTLS", "oauth2" or
+		// "openIdConnect".
+		Type string `json:"type"`
+		// Description for security scheme.
+		Description string `json:"description,omitempty"`
+		// Name of the header or query parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		In string `json:"in,omitempty"`
+		// Scheme is the HTTP authorization scheme when type is "http", e.g. "basic".
+		Scheme string `json:"scheme,omitempty"`
+		// BearerFormat is a hint to the client of the bearer token format.
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		// Flows contains the OAuth2 flows when type is "oauth2".
+		Flows *OAuthFlows `json:"flows,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OAuthFlows lists the supported OAuth2 flows.
+	OAuthFlows struct {
+		Implicit          *OAuthFlow `json:"implicit,omitempty"`
+		Password          *OAuthFlow `json:"password,omitempty"`
+		ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+		AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	}
+
+	// OAuthFlow describes an OAuth2 flow.
+	OAuthFlow struct {
+		AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+		TokenURL         string            `json:"tokenUrl,omitempty"`
+		Scopes           map[string]string `json:"scopes"`
+	}
+
+	// ExternalDocs allows referencing an external resource for extended documentation.
+	ExternalDocs struct {
+		// Description is a short description of the target documentation.
+		Description string `json:"description,omitempty"`
+		// URL for the target documentation.
+		URL string `json:"url"`
+	}
+
+	// Tag adds metadata to a tag used by the operations.
+	Tag struct {
+		// Name of the tag.
+		Name string `json:"name"`
+		// Description is a short description of the tag.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs is additional external documentation for this tag.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// These types are used in marshalJSON() to avoid recursive call of json.Marshal().
+	_OpenAPI        OpenAPI
+	_Info           Info
+	_PathItem       PathItem
+	_Operation      Operation
+	_Parameter      Parameter
+	_Response       Response
+	_SecurityScheme SecurityScheme
+	_Tag            Tag
+)
+
+func marshalJSON(v interface{}, extensions map[string]interface{}) ([]byte, error) {
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(extensions) == 0 {
+		return marshaled, nil
+	}
+	var unmarshaled map[string]interface{}
+	if err := json.Unmarshal(marshaled, &unmarshaled); err != nil {
+		return nil, err
+	}
+	for k, v := range extensions {
+		unmarshaled[k] = v
+	}
+	return json.Marshal(unmarshaled)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o OpenAPI) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPI(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of i.
+func (i Info) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Info(i), i.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p PathItem) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_PathItem(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Operation(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Parameter(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r Response) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Response(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of s.
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_SecurityScheme(s), s.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of t.
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_Tag(t), t.Extensions)
+}
+
+// New creates an OpenAPI 3.1 document from an API definition.
+func New(api *design.APIDefinition) (*OpenAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	o := &OpenAPI{
+		OpenAPI: Version,
+		Info: &Info{
+			Title:          api.Title,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Version:        api.Version,
+			Extensions:     extensionsFromDefinition(api.Metadata),
+		},
+		Servers:      serversFromDefinition(api),
+		Paths:        make(map[string]*PathItem),
+		Tags:         tagsFromDefinition(api.Metadata),
+		ExternalDocs: docsFromDefinition(api.Docs),
+		Components:   &Components{SecuritySchemes: securitySchemesFromDefinition(api.SecuritySchemes)},
+	}
+
+	err := api.IterateResponses(func(r *design.ResponseDefinition) error {
+		res, err := responseFromDefinition(api, r)
+		if err != nil {
+			return err
+		}
+		if o.Components.Responses == nil {
+			o.Components.Responses = make(map[string]*Response)
+		}
+		o.Components.Responses[r.Name] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			if !mustGenerate(fs.Metadata) {
+				return nil
+			}
+			return buildPathFromFileServer(o, api, fs)
+		})
+		if err != nil {
+			return err
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			for _, route := range a.Routes {
+				if err := buildPathFromDefinition(o, api, route); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(genschema.Definitions) > 0 {
+		o.Components.Schemas = make(map[string]*genschema.JSONSchema, len(genschema.Definitions))
+		for n, d := range genschema.Definitions {
+			o.Components.Schemas[n] = toOpenAPISchema(d)
+		}
+	}
+	if o.Components.Schemas == nil && o.Components.Responses == nil && o.Components.SecuritySchemes == nil {
+		o.Components = nil
+	}
+	return o, nil
+}
+
+// toOpenAPISchema returns a copy of the given JSON schema where the references point to the
+// document components and where the hyper-schema, Swagger specific and unsupported fields are
+// removed.
+func toOpenAPISchema(s *genschema.JSONSchema) *genschema.JSONSchema {
+	if s == nil {
+		return nil
+	}
+	res := *s
+	res.Schema = ""
+	res.Media = nil
+	res.Links = nil
+	res.Definitions = nil
+	if strings.HasPrefix(res.Ref, "#/definitions/") {
+		res.Ref = "#/components/schemas/" + strings.TrimPrefix(res.Ref, "#/definitions/")
+	}
+	if res.Type == genschema.JSONFile {
+		res.Type = genschema.JSONString
+		res.Format = "binary"
+	}
+	res.Items = toOpenAPISchema(s.Items)
+	if len(s.Properties) > 0 {
+		res.Properties = make(map[string]*genschema.JSONSchema, len(s.Properties))
+		for n, p := range s.Properties {
+			res.Properties[n] = toOpenAPISchema(p)
+		}
+	} else {
+		res.Properties = nil
+	}
+	res.AnyOf = toOpenAPISchemas(s.AnyOf)
+	res.OneOf = toOpenAPISchemas(s.OneOf)
+	res.AllOf = toOpenAPISchemas(s.AllOf)
+	res.Not = toOpenAPISchema(s.Not)
+	// JSON Schema 2020-12 used by OpenAPI 3.1 replaces "dependencies" with "dependentRequired".
+	res.DependentRequired, res.Dependencies = s.Dependencies, nil
+	// It also replaces the boolean exclusive bounds with the excluded values.
+	if res.ExclusiveMinimum == true && res.Minimum != nil {
+		res.ExclusiveMinimum, res.Minimum = *res.Minimum, nil
+	}
+	if res.ExclusiveMaximum == true && res.Maximum != nil {
+		res.ExclusiveMaximum, res.Maximum = *res.Maximum, nil
+	}
+	return &res
+}
+
+// toOpenAPISchemas applies toOpenAPISchema to each schema of the given list.
+func toOpenAPISchemas(schemas []*genschema.JSONSchema) []*genschema.JSONSchema {
+	if len(schemas) == 0 {
+		return nil
+	}
+	res := make([]*genschema.JSONSchema, len(schemas))
+	for i, s := range schemas {
+		res[i] = toOpenAPISchema(s)
+	}
+	return res
+}
+
+// attributeSchema returns the OpenAPI schema of the given attribute.
+func attributeSchema(api *design.APIDefinition, at *design.AttributeDefinition) *genschema.JSONSchema {
+	return toOpenAPISchema(genschema.AttributeSchema(api, at))
+}
+
+// typeSchema returns the OpenAPI schema of the given type.
+func typeSchema(api *design.APIDefinition, t design.DataType) *genschema.JSONSchema {
+	return toOpenAPISchema(genschema.TypeSchema(api, t))
+}
+
+// mustGenerate returns true if the metadata indicates that an OpenAPI specification should be
+// generated, false otherwise. The generator honors the "swagger:generate" metadata.
+func mustGenerate(meta dslengine.MetadataDefinition) bool {
+	if m, ok := meta["swagger:generate"]; ok {
+		if len(m) > 0 && m[0] == "false" {
+			return false
+		}
+	}
+	return true
+}
+
+// serversFromDefinition returns the API servers computed from the API host and schemes. The API
+// base path is part of the paths.
+func serversFromDefinition(api *design.APIDefinition) []*Server {
+	if api.Host == "" {
+		return nil
+	}
+	schemes := api.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	servers := make([]*Server, len(schemes))
+	for i, s := range schemes {
+		servers[i] = &Server{URL: fmt.Sprintf("%s://%s", s, api.Host)}
+	}
+	return servers
+}
+
+// securitySchemesFromDefinition maps the design security schemes to OpenAPI security schemes.
+func securitySchemesFromDefinition(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+	res := make(map[string]*SecurityScheme, len(schemes))
+	for _, scheme := range schemes {
+		s := &SecurityScheme{
+			Description: scheme.Description,
+			Extensions:  extensionsFromDefinition(scheme.Metadata),
+		}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			s.Type = "http"
+			s.Scheme = "basic"
+		case design.APIKeySecurityKind:
+			s.Type = "apiKey"
+			s.In = scheme.In
+			s.Name = scheme.Name
+		case design.JWTSecurityKind:
+			if scheme.In == "header" && strings.EqualFold(scheme.Name, "Authorization") {
+				s.Type = "http"
+				s.Scheme = "bearer"
+				s.BearerFormat = "JWT"
+			} else {
+				s.Type = "apiKey"
+				s.In = scheme.In
+				s.Name = scheme.Name
+			}
+			if scheme.TokenURL != "" {
+				s.Description += fmt.Sprintf("\n\n**Token URL**: %s", scheme.TokenURL)
+			}
+			if len(scheme.Scopes) != 0 {
+				s.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(scheme.Scopes))
+			}
+			s.Description = strings.TrimPrefix(s.Description, "\n\n")
+		case design.OAuth2SecurityKind:
+			s.Type = "oauth2"
+			scopes := scheme.Scopes
+			if scopes == nil {
+				scopes = make(map[string]string)
+			}
+			flow := &OAuthFlow{AuthorizationURL: scheme.AuthorizationURL, TokenURL: scheme.TokenURL, Scopes: scopes}
+			s.Flows = &OAuthFlows{}
+			switch scheme.Flow {
+			case "implicit":
+				flow.TokenURL = ""
+				s.Flows.Implicit = flow
+			case "password":
+				flow.AuthorizationURL = ""
+				s.Flows.Password = flow
+			case "application":
+				flow.AuthorizationURL = ""
+				s.Flows.ClientCredentials = flow
+			default:
+				s.Flows.AuthorizationCode = flow
+			}
+		case design.MTLSSecurityKind:
+			s.Type = "mutualTLS"
+		case design.HTTPSigSecurityKind:
+			s.Type = "apiKey"
+			s.In = "header"
+			s.Name = "Signature"
+			if s.Description != "" {
+				s.Description += "\n\n"
+			}
+			s.Description += "**HTTP message signature**: the Signature and Signature-Input headers " +
+				"sign the request method, path, query string and Content-Digest header."
+		default:
+			continue
+		}
+		res[scheme.SchemeName] = s
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+func scopesMapList(scopes map[string]string) string {
+	names := []string{}
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  * `%s`: %s", name, scopes[name]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
+	var keys []string
+	for k := range mdata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if chunks[0] != "swagger" || chunks[1] != "tag" {
+			continue
+		}
+
+		tag := &Tag{Name: chunks[2]}
+		if d := mdata[key+":desc"]; len(d) != 0 {
+			tag.Description = d[0]
+		}
+		var docs *ExternalDocs
+		if u := mdata[key+":url"]; len(u) != 0 {
+			docs = &ExternalDocs{URL: u[0]}
+		}
+		if d := mdata[key+":url:desc"]; len(d) != 0 {
+			if docs == nil {
+				docs = &ExternalDocs{}
+			}
+			docs.Description = d[0]
+		}
+		tag.ExternalDocs = docs
+		tags = append(tags, tag)
+	}
+	return
+}
+
+func tagNamesFromDefinitions(mdatas ...dslengine.MetadataDefinition) (tagNames []string) {
+	for _, mdata := range mdatas {
+		for _, tag := range tagsFromDefinition(mdata) {
+			tagNames = append(tagNames, tag.Name)
+		}
+	}
+	return
+}
+
+func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) string {
+	if mdata, ok := metadata["swagger:summary"]; ok && len(mdata) > 0 {
+		return mdata[0]
+	}
+	return name
+}
+
+// extensionsFromDefinition returns the specification extensions defined with the
+// "swagger:extension:x-*" metadata.
+func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
+	extensions := make(map[string]interface{})
+	for key, value := range mdata {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if chunks[0] != "swagger" || chunks[1] != "extension" {
+			continue
+		}
+		if !strings.HasPrefix(chunks[2], "x-") {
+			continue
+		}
+		val := value[0]
+		ival := interface{}(val)
+		if err := json.Unmarshal([]byte(val), &ival); err != nil {
+			extensions[chunks[2]] = val
+			continue
+		}
+		extensions[chunks[2]] = ival
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+	return extensions
+}
+
+func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
+	if docs == nil {
+		return nil
+	}
+	return &ExternalDocs{
+		Description: docs.Description,
+		URL:         docs.URL,
+	}
+}
+
+func paramsFromDefinition(api *design.APIDefinition, params *design.AttributeDefinition, path string) ([]*Parameter, error) {
+	if params == nil {
+		return nil, nil
+	}
+	obj := params.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid parameters definition, not an object")
+	}
+	var res []*Parameter
+	wildcards := design.ExtractWildcards(path)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		in := "query"
+		required := params.IsRequired(n)
+		for _, w := range wildcards {
+			if n == w {
+				in = "path"
+				required = true
+				break
+			}
+		}
+		res = append(res, paramFor(api, at, n, in, required))
+		return nil
+	})
+	return res, nil
+}
+
+func paramsFromHeaders(api *design.APIDefinition, action *design.ActionDefinition) []*Parameter {
+	var params []*Parameter
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		params = append(params, paramFor(api, header, name, "header", required))
+		return nil
+	})
+	return params
+}
+
+func paramsFromCookies(api *design.APIDefinition, action *design.ActionDefinition) []*Parameter {
+	var params []*Parameter
+	action.IterateCookies(func(name string, required bool, cookie *design.AttributeDefinition) error {
+		params = append(params, paramFor(api, cookie, name, "cookie", required))
+		return nil
+	})
+	return params
+}
+
+func paramFor(api *design.APIDefinition, at *design.AttributeDefinition, name, in string, required bool) *Parameter {
+	schema := attributeSchema(api, at)
+	description := schema.Description
+	schema.Description = ""
+	return &Parameter{
+		Name:        name,
+		In:          in,
+		Description: description,
+		Required:    required,
+		Deprecated:  at.IsDeprecated(),
+		Schema:      schema,
+		Extensions:  extensionsFromDefinition(at.Metadata),
+	}
+}
+
+func headersFromDefinition(api *design.APIDefinition, headers *design.AttributeDefinition) (map[string]*Header, error) {
+	if headers == nil {
+		return nil, nil
+	}
+	obj := headers.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("invalid headers definition, not an object")
+	}
+	res := make(map[string]*Header)
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		schema := attributeSchema(api, at)
+		description := schema.Description
+		schema.Description = ""
+		res[n] = &Header{
+			Description: description,
+			Required:    headers.IsRequired(n),
+			Schema:      schema,
+		}
+		return nil
+	})
+	return res, nil
+}
+
+// responseFromDefinition returns the response described by r. The body of responses with a media
+// type may be encoded with the response media type identifier or with any of the media types the
+// API produces.
+func responseFromDefinition(api *design.APIDefinition, r *design.ResponseDefinition) (*Response, error) {
+	var content map[string]*MediaType
+	if r.MediaType != "" {
+		if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			schema := genschema.NewJSONSchema()
+			schema.Ref = genschema.MediaTypeRef(api, mt, view)
+			content = map[string]*MediaType{r.MediaType: {Schema: toOpenAPISchema(schema)}}
+			for _, p := range api.Produces {
+				for _, m := range p.MIMETypes {
+					content[m] = &MediaType{Schema: toOpenAPISchema(schema)}
+				}
+			}
+		}
+	}
+	headers, err := headersFromDefinition(api, r.Headers)
+	if err != nil {
+		return nil, err
+	}
+	description := r.Description
+	if description == "" {
+		description = r.Name
+	}
+	return &Response{
+		Description: description,
+		Headers:     headers,
+		Content:     content,
+		Extensions:  extensionsFromDefinition(r.Metadata),
+	}, nil
+}
+
+// requestBodyFromDefinition returns the request body of the given action. Multipart payloads use
+// the "multipart/form-data" media type, other payloads may use any of the media types the API
+// consumes.
+func requestBodyFromDefinition(api *design.APIDefinition, action *design.ActionDefinition) *RequestBody {
+	if action.Payload == nil {
+		return nil
+	}
+	content := make(map[string]*MediaType)
+	if action.PayloadMultipart {
+		schema := typeSchema(api, action.Payload.Type)
+		if obj := action.Payload.Type.ToObject(); obj != nil {
+			schema = toOpenAPISchema(genschema.AttributeSchema(api, &design.AttributeDefinition{
+				Type:       obj,
+				Validation: action.Payload.Validation,
+			}))
+		}
+		content["multipart/form-data"] = &MediaType{Schema: schema}
+	} else {
+		schema := typeSchema(api, action.Payload)
+		if action.Patch != nil {
+			content[action.Patch.Format] = &MediaType{Schema: schema}
+		} else {
+			for _, c := range api.Consumes {
+				for _, m := range c.MIMETypes {
+					content[m] = &MediaType{Schema: schema}
+				}
+			}
+		}
+		if len(content) == 0 {
+			content["application/json"] = &MediaType{Schema: schema}
+		}
+	}
+	return &RequestBody{
+		Description: action.Payload.Description,
+		Content:     content,
+		Required:    !action.PayloadOptional,
+	}
+}
+
+func buildPathFromFileServer(o *OpenAPI, api *design.APIDefinition, fs *design.FileServerDefinition) error {
+	wcs := design.ExtractWildcards(fs.RequestPath)
+	var params []*Parameter
+	if len(wcs) > 0 {
+		params = []*Parameter{{
+			In:          "path",
+			Name:        wcs[0],
+			Description: "Relative file path",
+			Required:    true,
+			Schema:      &genschema.JSONSchema{Type: genschema.JSONString},
+		}}
+	}
+
+	contentType := "application/octet-stream"
+	if !fs.IsDir() {
+		if ct := mime.TypeByExtension(filepath.Ext(fs.FilePath)); ct != "" {
+			contentType = ct
+		}
+	}
+	responses := map[string]*Response{
+		"200": {
+			Description: "File downloaded",
+			Content: map[string]*MediaType{
+				contentType: {Schema: &genschema.JSONSchema{Type: genschema.JSONString, Format: "binary"}},
+			},
+		},
+	}
+	if len(wcs) > 0 {
+		responses["404"] = &Response{
+			Description: "File not found",
+			Content: map[string]*MediaType{
+				design.ErrorMediaIdentifier: {Schema: typeSchema(api, design.ErrorMedia)},
+			},
+		}
+	}
+
+	operation := &Operation{
+		Description:  fs.Description,
+		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
+		ExternalDocs: docsFromDefinition(fs.Docs),
+		OperationID:  fmt.Sprintf("%s#%s", fs.Parent.Name, fs.RequestPath),
+		Parameters:   params,
+		Responses:    responses,
+	}
+	applySecurity(operation, fs.Security)
+
+	p := pathItem(o, fs.RequestPath)
+	p.Get = operation
+	p.Extensions = extensionsFromDefinition(fs.Metadata)
+	return nil
+}
+
+func buildPathFromDefinition(o *OpenAPI, api *design.APIDefinition, route *design.RouteDefinition) error {
+	action := route.Parent
+
+	tagNames := tagNamesFromDefinitions(action.Parent.Metadata, action.Metadata)
+	if len(tagNames) == 0 {
+		// By default tag with resource name
+		tagNames = []string{action.Parent.Name}
+	}
+	params, err := paramsFromDefinition(api, action.AllParams(), route.FullPath())
+	if err != nil {
+		return err
+	}
+	params = append(params, paramsFromHeaders(api, action)...)
+	params = append(params, paramsFromCookies(api, action)...)
+
+	responses := make(map[string]*Response, len(action.Responses))
+	for _, r := range action.Responses {
+		resp, err := responseFromDefinition(api, r)
+		if err != nil {
+			return err
+		}
+		if p := action.Pagination; p != nil && p.Envelope && r.Name == design.OK {
+			for _, mt := range resp.Content {
+				mt.Schema = toOpenAPISchema(genschema.PageEnvelopeSchema(p, mt.Schema))
+			}
+		}
+		responses[strconv.Itoa(r.Status)] = resp
+	}
+
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	for i, rt := range action.Routes {
+		if rt == route && i > 0 {
+			operationID = fmt.Sprintf("%s#%d", operationID, i)
+			break
+		}
+	}
+
+	var servers []*Server
+	if len(action.Schemes) > 0 && api.Host != "" {
+		for _, s := range action.Schemes {
+			servers = append(servers, &Server{URL: fmt.Sprintf("%s://%s", s, api.Host)})
+		}
+	}
+
+	operation := &Operation{
+		Tags:         tagNames,
+		Description:  action.Description,
+		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		ExternalDocs: docsFromDefinition(action.Docs),
+		OperationID:  operationID,
+		Parameters:   params,
+		RequestBody:  requestBodyFromDefinition(api, action),
+		Responses:    responses,
+		Servers:      servers,
+		Deprecated:   action.Deprecation() != nil,
+		Extensions:   extensionsFromDefinition(route.Metadata),
+	}
+	applySecurity(operation, action.Security)
+
+	p := pathItem(o, route.FullPath())
+	switch route.Verb {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "OPTIONS":
+		p.Options = operation
+	case "HEAD":
+		p.Head = operation
+	case "PATCH":
+		p.Patch = operation
+	case "TRACE":
+		p.Trace = operation
+	}
+	p.Extensions = extensionsFromDefinition(action.Metadata)
+	return nil
+}
+
+// pathItem returns the path item for the given goa path, creating it if needed.
+func pathItem(o *OpenAPI, path string) *PathItem {
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		path,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if key == "" {
+		key = "/"
+	}
+	p, ok := o.Paths[key]
+	if !ok {
+		p = new(PathItem)
+		o.Paths[key] = p
+	}
+	return p
+}
+
+// applySecurity sets the operation security requirements. Each alternative requirement is a
+// separate entry, the schemes of a requirement must all be satisfied.
+func applySecurity(operation *Operation, security *design.SecurityDefinition) {
+	if security == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return
+	}
+	for _, req := range security.Requirements() {
+		schemes := req.AllSchemes()
+		entry := make(map[string][]string, len(schemes))
+		for _, scheme := range schemes {
+			scopes := make([]string, 0)
+			if req.Scopes != nil && (len(schemes) == 1 || scheme.Kind == design.OAuth2SecurityKind || scheme.Kind == design.JWTSecurityKind) {
+				scopes = req.Scopes
+			}
+			entry[scheme.SchemeName] = scopes
+		}
+		operation.Security = append(operation.Security, entry)
+	}
+}
@@ -0,0 +1,326 @@
+package genopenapi_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_openapi"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var spec *genopenapi.OpenAPI
+	var newErr error
+
+	BeforeEach(func() {
+		spec = nil
+		newErr = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		spec, newErr = genopenapi.New(Design)
+	})
+
+	Context("with a valid API definition", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Title("title")
+				Description("description")
+				Version("1.0")
+				Metadata("swagger:tag:tag")
+				Metadata("swagger:tag:tag:desc", "Tag desc.")
+				Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
+				Host("goa.design")
+				Scheme("https", "http")
+				BasePath("/base")
+			})
+		})
+
+		It("sets the basic fields", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.OpenAPI).Should(Equal("3.1.0"))
+			Ω(spec.Info.Title).Should(Equal("title"))
+			Ω(spec.Info.Description).Should(Equal("description"))
+			Ω(spec.Info.Version).Should(Equal("1.0"))
+			Ω(spec.Info.Extensions).Should(HaveKeyWithValue("x-api", map[string]interface{}{"foo": "bar"}))
+			Ω(spec.Servers).Should(Equal([]*genopenapi.Server{
+				{URL: "https://goa.design"},
+				{URL: "http://goa.design"},
+			}))
+			Ω(spec.Tags).Should(Equal([]*genopenapi.Tag{{Name: "tag", Description: "Tag desc."}}))
+			Ω(spec.Paths).Should(BeEmpty())
+			Ω(spec.Components).Should(BeNil())
+		})
+
+		It("serializes the extensions", func() {
+			b, err := json.Marshal(spec)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"openapi":"3.1.0"`))
+			Ω(string(b)).Should(ContainSubstring(`"x-api":{"foo":"bar"}`))
+		})
+	})
+
+	Context("with resources", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Consumes("application/json")
+				Consumes("application/xml")
+				Produces("application/json")
+			})
+			BottleMedia := MediaType("application/vnd.goa.example.bottle", func() {
+				Description("A bottle of wine")
+				Attributes(func() {
+					Attribute("id", Integer, "ID of bottle")
+					Attribute("name", String, "Name of bottle")
+					Required("id")
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer, "Bottle ID")
+						Param("fields", String)
+					})
+					Headers(func() {
+						Header("X-Request-Id", String)
+					})
+					Cookies(func() {
+						Cookie("session", String)
+						Required("session")
+					})
+					Response(OK, func() {
+						Media(BottleMedia, "tiny")
+					})
+				})
+				Action("create", func() {
+					Metadata("swagger:summary", "create a bottle")
+					Routing(POST(""))
+					Payload(func() {
+						Member("name", String)
+						Member("country", String)
+						Member("postal_code", String)
+						Member("vintage", Integer, func() {
+							ExclusiveMinimum(1900)
+							Maximum(2100)
+						})
+						Required("name")
+						DependentRequired("postal_code", "country")
+					})
+					Response(Created, BottleMedia)
+				})
+				Action("list", func() {
+					Routing(GET(""))
+					Paginated(Offset, func() {
+						Envelope()
+					})
+					Response(OK, CollectionOf(BottleMedia))
+				})
+				Action("update", func() {
+					Routing(PATCH("/:id"))
+					JSONPatch(BottleMedia)
+					Response(NoContent)
+				})
+				Action("hidden", func() {
+					Metadata("swagger:generate", "false")
+					Routing(DELETE("/:id"))
+					Response(NoContent)
+				})
+				Files("/download/*filepath", "public/")
+				Files("/index.html", "public/index.html")
+			})
+		})
+
+		It("produces the paths", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(spec.Paths).Should(HaveLen(4))
+			Ω(spec.Paths).Should(HaveKey("/bottles/{id}"))
+			Ω(spec.Paths).Should(HaveKey("/bottles"))
+			Ω(spec.Paths["/bottles/{id}"].Delete).Should(BeNil())
+		})
+
+		It("sets the parameters", func() {
+			op := spec.Paths["/bottles/{id}"].Get
+			Ω(op.OperationID).Should(Equal("bottle#show"))
+			Ω(op.Tags).Should(Equal([]string{"bottle"}))
+			Ω(op.Parameters).Should(HaveLen(4))
+			Ω(op.Parameters[0].Name).Should(Equal("fields"))
+			Ω(op.Parameters[0].In).Should(Equal("query"))
+			Ω(op.Parameters[1].Name).Should(Equal("id"))
+			Ω(op.Parameters[1].In).Should(Equal("path"))
+			Ω(op.Parameters[1].Required).Should(BeTrue())
+			Ω(op.Parameters[1].Description).Should(Equal("Bottle ID"))
+			Ω(op.Parameters[1].Schema.Type).Should(BeEquivalentTo(genschema.JSONInteger))
+			Ω(op.Parameters[2].Name).Should(Equal("X-Request-Id"))
+			Ω(op.Parameters[2].In).Should(Equal("header"))
+			Ω(op.Parameters[3].Name).Should(Equal("session"))
+			Ω(op.Parameters[3].In).Should(Equal("cookie"))
+			Ω(op.Parameters[3].Required).Should(BeTrue())
+		})
+
+		It("maps views to component schemas", func() {
+			resp := spec.Paths["/bottles/{id}"].Get.Responses["200"]
+			Ω(resp.Content).Should(HaveKey("application/vnd.goa.example.bottle"))
+			ref := resp.Content["application/vnd.goa.example.bottle"].Schema.Ref
+			Ω(ref).Should(Equal("#/components/schemas/GoaExampleBottleTiny"))
+			Ω(spec.Components.Schemas).Should(HaveKey("GoaExampleBottleTiny"))
+			Ω(spec.Components.Schemas["GoaExampleBottleTiny"].Properties).Should(HaveKey("id"))
+			Ω(spec.Components.Schemas["GoaExampleBottleTiny"].Properties).ShouldNot(HaveKey("name"))
+		})
+
+		It("maps the produced media types to the response content", func() {
+			resp := spec.Paths["/bottles"].Post.Responses["201"]
+			Ω(resp.Content).Should(HaveLen(2))
+			Ω(resp.Content).Should(HaveKey("application/vnd.goa.example.bottle"))
+			Ω(resp.Content).Should(HaveKey("application/json"))
+			Ω(resp.Content["application/json"].Schema.Ref).Should(Equal("#/components/schemas/GoaExampleBottle"))
+		})
+
+		It("maps the consumed media types to the request body content", func() {
+			op := spec.Paths["/bottles"].Post
+			Ω(op.Summary).Should(Equal("create a bottle"))
+			Ω(op.RequestBody).ShouldNot(BeNil())
+			Ω(op.RequestBody.Required).Should(BeTrue())
+			Ω(op.RequestBody.Content).Should(HaveLen(2))
+			Ω(op.RequestBody.Content).Should(HaveKey("application/json"))
+			Ω(op.RequestBody.Content).Should(HaveKey("application/xml"))
+			Ω(op.RequestBody.Content["application/json"].Schema.Ref).Should(HavePrefix("#/components/schemas/"))
+		})
+
+		It("maps the dependent required properties to dependentRequired", func() {
+			ref := spec.Paths["/bottles"].Post.RequestBody.Content["application/json"].Schema.Ref
+			payload := spec.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+			Ω(payload).ShouldNot(BeNil())
+			Ω(payload.DependentRequired).Should(Equal(map[string][]string{"postal_code": {"country"}}))
+			Ω(payload.Dependencies).Should(BeNil())
+		})
+
+		It("uses the excluded values as exclusive bounds", func() {
+			ref := spec.Paths["/bottles"].Post.RequestBody.Content["application/json"].Schema.Ref
+			payload := spec.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+			Ω(payload).ShouldNot(BeNil())
+			vintage := payload.Properties["vintage"]
+			Ω(vintage).ShouldNot(BeNil())
+			Ω(vintage.ExclusiveMinimum).Should(Equal(1900.0))
+			Ω(vintage.Minimum).Should(BeNil())
+			Ω(*vintage.Maximum).Should(Equal(2100.0))
+			Ω(vintage.ExclusiveMaximum).Should(BeNil())
+			b, err := json.Marshal(vintage)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"exclusiveMinimum":1900`))
+			Ω(string(b)).ShouldNot(ContainSubstring(`"minimum"`))
+		})
+
+		It("uses the patch format for the request body of patch actions", func() {
+			op := spec.Paths["/bottles/{id}"].Patch
+			Ω(op).ShouldNot(BeNil())
+			Ω(op.RequestBody.Content).Should(HaveLen(1))
+			Ω(op.RequestBody.Content).Should(HaveKey("application/json-patch+json"))
+			Ω(spec.Components.Schemas).Should(HaveKey("JSONPatchOperation"))
+		})
+
+		It("wraps the pages of paginated actions in an envelope", func() {
+			op := spec.Paths["/bottles"].Get
+			Ω(op).ShouldNot(BeNil())
+			Ω(op.Parameters).Should(HaveLen(2))
+			Ω(op.Parameters[0].Name).Should(Equal("limit"))
+			Ω(op.Parameters[1].Name).Should(Equal("offset"))
+			content := op.Responses["200"].Content
+			Ω(content).Should(HaveLen(2))
+			for _, mt := range content {
+				Ω(mt.Schema.Properties).Should(HaveKey("items"))
+				Ω(mt.Schema.Properties).Should(HaveKey("total"))
+				Ω(mt.Schema.Required).Should(Equal([]string{"items", "offset", "limit"}))
+			}
+		})
+
+		It("maps files to binary responses", func() {
+			dir := spec.Paths["/download/{filepath}"].Get
+			Ω(dir.Parameters).Should(HaveLen(1))
+			Ω(dir.Responses["200"].Content).Should(HaveKey("application/octet-stream"))
+			Ω(dir.Responses["200"].Content["application/octet-stream"].Schema.Format).Should(Equal("binary"))
+			Ω(dir.Responses).Should(HaveKey("404"))
+			file := spec.Paths["/index.html"].Get
+			Ω(file.Responses["200"].Content).Should(HaveKey("text/html; charset=utf-8"))
+		})
+
+		It("does not produce JSON hyper-schema fields", func() {
+			b, err := json.Marshal(spec)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).ShouldNot(ContainSubstring("#/definitions/"))
+			Ω(string(b)).ShouldNot(ContainSubstring(`"$schema"`))
+		})
+	})
+
+	Context("with security", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				BasicAuthSecurity("password")
+				OAuth2Security("oauth2", func() {
+					AccessCodeFlow("/auth", "/token")
+					Scope("api:read", "Read access")
+				})
+				JWTSecurity("jwt", func() {
+					Header("Authorization")
+				})
+				APIKeySecurity("key", func() {
+					Query("key")
+				})
+				MTLSSecurity("mtls")
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Security("password", "oauth2", func() {
+						Scope("api:read")
+					})
+					Security("mtls")
+					Routing(GET("/"))
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("maps the security schemes", func() {
+			schemes := spec.Components.SecuritySchemes
+			Ω(schemes).Should(HaveLen(5))
+			Ω(schemes["password"].Type).Should(Equal("http"))
+			Ω(schemes["password"].Scheme).Should(Equal("basic"))
+			Ω(schemes["oauth2"].Type).Should(Equal("oauth2"))
+			Ω(schemes["oauth2"].Flows.AuthorizationCode).Should(Equal(&genopenapi.OAuthFlow{
+				AuthorizationURL: "/auth",
+				TokenURL:         "/token",
+				Scopes:           map[string]string{"api:read": "Read access"},
+			}))
+			Ω(schemes["jwt"].Type).Should(Equal("http"))
+			Ω(schemes["jwt"].Scheme).Should(Equal("bearer"))
+			Ω(schemes["jwt"].BearerFormat).Should(Equal("JWT"))
+			Ω(schemes["key"].Type).Should(Equal("apiKey"))
+			Ω(schemes["key"].In).Should(Equal("query"))
+			Ω(schemes["mtls"].Type).Should(Equal("mutualTLS"))
+		})
+
+		It("sets the alternative security requirements", func() {
+			Ω(spec.Paths["/"].Get.Security).Should(Equal([]map[string][]string{
+				{"password": {}, "oauth2": {"api:read"}},
+				{"mtls": {}},
+			}))
+		})
+	})
+})
@@ -0,0 +1,24 @@
+/*
+Package genproto provides a generator for the protocol buffers definitions of the API.
+The generator produces a ".proto" file that defines one message per user type, one message per
+media type view and one gRPC service per resource with one RPC per action. It also produces the Go
+functions that convert between the data structures generated by "goagen app" and the Go types
+generated by protoc from the ".proto" file so that the same controller code can serve both HTTP
+and gRPC requests. The messages can be encoded and decoded with the encoding/gogoprotobuf package.
+
+Field numbers are assigned in alphabetical order of the attribute names the first time the
+definitions are generated. The generator then reads the numbers back from the previously generated
+".proto" file so that existing fields keep their numbers, new fields are numbered after all the
+numbers used so far and the numbers of removed fields are reserved. Keep the generated file under
+version control to preserve the numbers. Use the "proto:field" metadata to assign numbers
+explicitly, generation fails if the metadata would change the number of an existing field:
+
+    Attribute("name", String, func() {
+        Metadata("proto:field", "2")
+    })
+
+Integer attributes are represented as int64 unless their format is "int32", "uint32" or "uint64".
+Number attributes are represented as double unless their format is "float32" (float) or
+"decimal" (string).
+*/
+package genproto
@@ -0,0 +1,243 @@
+package genproto
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/version"
+)
+
+//NewGenerator returns an initialized instance of a protocol buffers Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the protocol buffers generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated package
+	AppPkg   string                // Import path of the package generated with "goagen app"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, target, appPkg, ver string
+	)
+
+	set := flag.NewFlagSet("proto", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.StringVar(&target, "pkg", "pb", "")
+	set.StringVar(&appPkg, "app-pkg", "app", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Target: target, AppPkg: appPkg, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the protocol buffers definition file and the Go conversion functions.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Target == "" {
+		g.Target = "pb"
+	}
+	if g.AppPkg == "" {
+		g.AppPkg = "app"
+	}
+
+	pkgDir := filepath.Join(g.OutDir, g.Target)
+	protoFile := filepath.Join(pkgDir, PackageName(g.API)+".proto")
+	lock, err := readLock(protoFile)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := New(g.API, lock)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.RemoveAll(pkgDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(pkgDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, pkgDir)
+
+	f.GoPackage, err = codegen.PackagePath(pkgDir)
+	if err != nil {
+		return nil, err
+	}
+	f.GoPackage = fmt.Sprintf("%s;%s", filepath.ToSlash(f.GoPackage), g.Target)
+
+	if err = g.generateProto(protoFile, f); err != nil {
+		return nil, err
+	}
+	if err = g.generateGlue(filepath.Join(pkgDir, "convert.go"), f); err != nil {
+		return nil, err
+	}
+
+	return g.genfiles, nil
+}
+
+// readLock returns the field numbers recorded in the given previously generated definition file,
+// nil if the file does not exist.
+func readLock(protoFile string) (Lock, error) {
+	f, err := os.Open(protoFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	lock, err := ParseLock(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", protoFile, err)
+	}
+	return lock, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+func (g *Generator) generateProto(protoFile string, f *File) error {
+	file, err := codegen.SourceFileFor(protoFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, protoFile)
+
+	data := map[string]interface{}{
+		"Title":       fmt.Sprintf("%s: Protocol Buffers Definitions", g.API.Context()),
+		"ToolVersion": version.String(),
+		"File":        f,
+	}
+	funcs := template.FuncMap{"protoComment": protoComment}
+	return file.ExecuteTemplate("proto", protoT, funcs, data)
+}
+
+func (g *Generator) generateGlue(glueFile string, f *File) (err error) {
+	file, err := codegen.SourceFileFor(glueFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	g.genfiles = append(g.genfiles, glueFile)
+
+	imp := g.AppPkg
+	if _, err := codegen.PackageSourcePath(imp); err != nil {
+		imp, err = codegen.PackagePath(g.OutDir)
+		if err != nil {
+			return err
+		}
+		imp = path.Join(filepath.ToSlash(imp), g.AppPkg)
+	}
+	elems := strings.Split(imp, "/")
+	gl := &glue{appPkg: elems[len(elems)-1]}
+
+	title := fmt.Sprintf("%s: Protocol Buffers Conversions", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport(imp),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	for _, m := range f.Messages {
+		if !gl.convertible(m) {
+			continue
+		}
+		if _, err = file.Write([]byte(gl.toPBFunc(m) + "\n" + gl.fromPBFunc(m) + "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoComment returns the given text as a protocol buffers comment indented with the given
+// number of tabs.
+func protoComment(text string, tabs int) string {
+	if text == "" {
+		return ""
+	}
+	prefix := strings.Repeat("\t", tabs) + "// "
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(prefix+l, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+const protoT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+//
+// Command:
+{{ comment commandLine }}
+
+syntax = "proto3";
+
+package {{ .File.Package }};
+
+option go_package = "{{ .File.GoPackage }}";
+{{ if .File.Imports }}
+{{ range .File.Imports }}import "{{ . }}";
+{{ end }}{{ end }}{{ range .File.Messages }}
+{{ protoComment .Description 0 }}message {{ .Name }} {
+{{ range .Fields }}{{ protoComment .Description 1 }}	{{ if .Repeated }}repeated {{ else if .Optional }}optional {{ end }}{{ .Type }} {{ .Name }} = {{ .Number }};
+{{ end }}{{ if .Reserved }}	reserved {{ range $i, $n := .Reserved }}{{ if $i }}, {{ end }}{{ $n }}{{ end }};
+{{ end }}{{ range .Unsupported }}	// {{ . }} is not supported by protocol buffers.
+{{ end }}}
+{{ end }}{{ range .File.Services }}
+{{ protoComment .Description 0 }}service {{ .Name }}Service {
+{{ range .RPCs }}{{ protoComment .Description 1 }}	rpc {{ .Name }}({{ .Request }}) returns ({{ .Response }});
+{{ end }}}
+{{ end }}`
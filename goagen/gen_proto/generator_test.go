@@ -0,0 +1,46 @@
+package genproto_test
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/gen_proto"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genproto.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+		target string
+		appPkg string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		target: "grpc",
+		appPkg: "github.com/goadesign/goa-cellar/app",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+
+			generator = genproto.NewGenerator(
+				genproto.API(args.api),
+				genproto.OutDir(args.outDir),
+				genproto.Target(args.target),
+				genproto.AppPkg(args.appPkg),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Target).Should(Equal(args.target))
+			Ω(generator.AppPkg).Should(Equal(args.appPkg))
+		})
+	})
+})
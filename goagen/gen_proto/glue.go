@@ -0,0 +1,298 @@
+package genproto
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// glue generates the functions that convert between the data structures generated by
+// "goagen app" and the protocol buffers messages.
+type glue struct {
+	// appPkg is the name of the package generated by "goagen app".
+	appPkg string
+}
+
+// convertible returns true if the conversion functions can be generated for the given message.
+func (g *glue) convertible(m *Message) bool {
+	return m.Type != nil && !m.Inline
+}
+
+// supported returns true if values of the given attribute can be converted. Attributes defined
+// inline and types overridden with the "struct:field:type" metadata are not supported.
+func (g *glue) supported(at *design.AttributeDefinition) bool {
+	if _, ok := at.Metadata["struct:field:type"]; ok {
+		return false
+	}
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		return scalarType(at) != ""
+	case *design.Array:
+		return g.supported(actual.ElemType)
+	case *design.Hash:
+		return g.supported(actual.KeyType) && g.supported(actual.ElemType)
+	case design.Object, *design.Union:
+		return false
+	case *design.UserTypeDefinition:
+		if !actual.IsObject() {
+			return g.supported(actual.AttributeDefinition)
+		}
+	case *design.MediaTypeDefinition:
+		if !actual.IsObject() {
+			return g.supported(actual.AttributeDefinition)
+		}
+	}
+	return true
+}
+
+// toPBFunc returns the code of the function that converts the app data structure to the message.
+func (g *glue) toPBFunc(m *Message) string {
+	var buf bytes.Buffer
+	appType := g.appRef(&design.AttributeDefinition{Type: m.Type})
+	fmt.Fprintf(&buf, "// %sToPB converts the %s value to the %s protocol buffers message.\n", m.Name, appType, m.Name)
+	fmt.Fprintf(&buf, "func %sToPB(v %s) *%s {\n", m.Name, appType, m.Name)
+	buf.WriteString("\tif v == nil {\n\t\treturn nil\n\t}\n")
+	fmt.Fprintf(&buf, "\tres := &%s{}\n", m.Name)
+	if m.Collection {
+		buf.WriteString(g.toPB(&design.AttributeDefinition{Type: m.Type.ToArray()}, "v", "res.Items", 1))
+	} else {
+		parent := m.Type.(design.DataStructure).Definition()
+		for _, f := range m.Fields {
+			if !g.supported(f.Attribute) {
+				fmt.Fprintf(&buf, "\t// %s is not converted, its type is defined inline.\n", f.AttName)
+				continue
+			}
+			src := "v." + codegen.GoifyAtt(f.Attribute, f.AttName, true)
+			dst := "res." + f.GoName
+			if f.Attribute.Nullable {
+				// Null values are not set in the message.
+				fmt.Fprintf(&buf, "\tif %s.Set && !%s.Null {\n", src, src)
+				if f.Optional {
+					fmt.Fprintf(&buf, "\t\tvar val %s\n", g.pbRef(f.Attribute))
+					buf.WriteString(g.toPB(f.Attribute, src+".Value", "val", 2))
+					fmt.Fprintf(&buf, "\t\t%s = &val\n", dst)
+				} else {
+					buf.WriteString(g.toPB(f.Attribute, src+".Value", dst, 2))
+				}
+				buf.WriteString("\t}\n")
+				continue
+			}
+			if parent.IsPrimitivePointer(f.AttName) {
+				fmt.Fprintf(&buf, "\tif %s != nil {\n", src)
+				fmt.Fprintf(&buf, "\t\tvar val %s\n", g.pbRef(f.Attribute))
+				buf.WriteString(g.toPB(f.Attribute, "*"+src, "val", 2))
+				fmt.Fprintf(&buf, "\t\t%s = &val\n", dst)
+				buf.WriteString("\t}\n")
+				continue
+			}
+			buf.WriteString(g.toPB(f.Attribute, src, dst, 1))
+		}
+	}
+	buf.WriteString("\treturn res\n}\n")
+	return buf.String()
+}
+
+// fromPBFunc returns the code of the function that converts the message to the app data
+// structure.
+func (g *glue) fromPBFunc(m *Message) string {
+	var buf bytes.Buffer
+	appType := g.appRef(&design.AttributeDefinition{Type: m.Type})
+	fmt.Fprintf(&buf, "// %sFromPB converts the %s protocol buffers message to a %s value.\n", m.Name, m.Name, appType)
+	fmt.Fprintf(&buf, "func %sFromPB(v *%s) (%s, error) {\n", m.Name, m.Name, appType)
+	buf.WriteString("\tif v == nil {\n\t\treturn nil, nil\n\t}\n")
+	if m.Collection {
+		fmt.Fprintf(&buf, "\tvar res %s\n", appType)
+		buf.WriteString(g.fromPB(&design.AttributeDefinition{Type: m.Type}, "v.Items", "res", 1))
+	} else {
+		fmt.Fprintf(&buf, "\tres := &%s{}\n", appType[1:])
+		parent := m.Type.(design.DataStructure).Definition()
+		for _, f := range m.Fields {
+			if !g.supported(f.Attribute) {
+				fmt.Fprintf(&buf, "\t// %s is not converted, its type is defined inline.\n", f.AttName)
+				continue
+			}
+			src := "v." + f.GoName
+			dst := "res." + codegen.GoifyAtt(f.Attribute, f.AttName, true)
+			if f.Attribute.Nullable {
+				// Messages do not distinguish null values from absent ones.
+				value := src
+				if f.Optional {
+					fmt.Fprintf(&buf, "\tif %s != nil {\n", src)
+					value = "*" + src
+				} else {
+					fmt.Fprintf(&buf, "\tif len(%s) > 0 {\n", src)
+				}
+				buf.WriteString(g.fromPB(f.Attribute, value, dst+".Value", 2))
+				fmt.Fprintf(&buf, "\t\t%s.Set = true\n", dst)
+				buf.WriteString("\t}\n")
+				continue
+			}
+			if parent.IsPrimitivePointer(f.AttName) {
+				fmt.Fprintf(&buf, "\tif %s != nil {\n", src)
+				fmt.Fprintf(&buf, "\t\tvar val %s\n", g.appRef(f.Attribute))
+				buf.WriteString(g.fromPB(f.Attribute, "*"+src, "val", 2))
+				fmt.Fprintf(&buf, "\t\t%s = &val\n", dst)
+				buf.WriteString("\t}\n")
+				continue
+			}
+			buf.WriteString(g.fromPB(f.Attribute, src, dst, 1))
+		}
+	}
+	buf.WriteString("\treturn res, nil\n}\n")
+	return buf.String()
+}
+
+// toPB returns the code that assigns the conversion of the app value src to dst.
+func (g *glue) toPB(at *design.AttributeDefinition, src, dst string, depth int) string {
+	tabs := codegen.Tabs(depth)
+	switch {
+	case at.Type.IsPrimitive():
+		return fmt.Sprintf("%s%s = %s\n", tabs, dst, g.scalarToPB(at, src))
+	case at.Type.IsObject():
+		return fmt.Sprintf("%s%s = %sToPB(%s)\n", tabs, dst, codegen.GoTypeName(at.Type, nil, 0, false), src)
+	case at.Type.IsArray():
+		elem := at.Type.ToArray().ElemType
+		e, i := fmt.Sprintf("e%d", depth), fmt.Sprintf("i%d", depth)
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%sif %s != nil {\n", tabs, src)
+		fmt.Fprintf(&buf, "%s\t%s = make([]%s, len(%s))\n", tabs, dst, g.pbRef(elem), src)
+		fmt.Fprintf(&buf, "%s\tfor %s, %s := range %s {\n", tabs, i, e, src)
+		buf.WriteString(g.toPB(elem, e, fmt.Sprintf("%s[%s]", dst, i), depth+2))
+		fmt.Fprintf(&buf, "%s\t}\n%s}\n", tabs, tabs)
+		return buf.String()
+	default:
+		h := at.Type.ToHash()
+		k, v, val := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth), fmt.Sprintf("val%d", depth)
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%sif %s != nil {\n", tabs, src)
+		fmt.Fprintf(&buf, "%s\t%s = make(map[%s]%s, len(%s))\n", tabs, dst, g.pbRef(h.KeyType), g.pbRef(h.ElemType), src)
+		fmt.Fprintf(&buf, "%s\tfor %s, %s := range %s {\n", tabs, k, v, src)
+		fmt.Fprintf(&buf, "%s\t\tvar %s %s\n", tabs, val, g.pbRef(h.ElemType))
+		buf.WriteString(g.toPB(h.ElemType, v, val, depth+2))
+		fmt.Fprintf(&buf, "%s\t\t%s[%s] = %s\n", tabs, dst, g.scalarToPB(h.KeyType, k), val)
+		fmt.Fprintf(&buf, "%s\t}\n%s}\n", tabs, tabs)
+		return buf.String()
+	}
+}
+
+// fromPB returns the code that assigns the conversion of the protocol buffers value src to dst.
+// The code returns an error if the value cannot be converted.
+func (g *glue) fromPB(at *design.AttributeDefinition, src, dst string, depth int) string {
+	tabs := codegen.Tabs(depth)
+	switch {
+	case at.Type.IsPrimitive():
+		switch at.Type.Kind() {
+		case design.DateTimeKind:
+			return fmt.Sprintf("%s{\n%s\tt, err := time.Parse(time.RFC3339, %s)\n%s\tif err != nil {\n%s\t\treturn nil, err\n%s\t}\n%s\t%s = t\n%s}\n",
+				tabs, tabs, src, tabs, tabs, tabs, tabs, dst, tabs)
+		case design.UUIDKind:
+			return fmt.Sprintf("%s{\n%s\tu, err := uuid.FromString(%s)\n%s\tif err != nil {\n%s\t\treturn nil, err\n%s\t}\n%s\t%s = u\n%s}\n",
+				tabs, tabs, src, tabs, tabs, tabs, tabs, dst, tabs)
+		}
+		return fmt.Sprintf("%s%s = %s\n", tabs, dst, g.scalarFromPB(at, src))
+	case at.Type.IsObject():
+		name := codegen.GoTypeName(at.Type, nil, 0, false)
+		return fmt.Sprintf("%s{\n%s\tm, err := %sFromPB(%s)\n%s\tif err != nil {\n%s\t\treturn nil, err\n%s\t}\n%s\t%s = m\n%s}\n",
+			tabs, tabs, name, src, tabs, tabs, tabs, tabs, dst, tabs)
+	case at.Type.IsArray():
+		elem := at.Type.ToArray().ElemType
+		e, i := fmt.Sprintf("e%d", depth), fmt.Sprintf("i%d", depth)
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%sif %s != nil {\n", tabs, src)
+		fmt.Fprintf(&buf, "%s\t%s = make(%s, len(%s))\n", tabs, dst, g.appRef(at), src)
+		fmt.Fprintf(&buf, "%s\tfor %s, %s := range %s {\n", tabs, i, e, src)
+		buf.WriteString(g.fromPB(elem, e, fmt.Sprintf("%s[%s]", dst, i), depth+2))
+		fmt.Fprintf(&buf, "%s\t}\n%s}\n", tabs, tabs)
+		return buf.String()
+	default:
+		h := at.Type.ToHash()
+		k, v, val := fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth), fmt.Sprintf("val%d", depth)
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%sif %s != nil {\n", tabs, src)
+		fmt.Fprintf(&buf, "%s\t%s = make(%s, len(%s))\n", tabs, dst, g.appRef(at), src)
+		fmt.Fprintf(&buf, "%s\tfor %s, %s := range %s {\n", tabs, k, v, src)
+		fmt.Fprintf(&buf, "%s\t\tvar %s %s\n", tabs, val, g.appRef(h.ElemType))
+		buf.WriteString(g.fromPB(h.ElemType, v, val, depth+2))
+		fmt.Fprintf(&buf, "%s\t\t%s[%s] = %s\n", tabs, dst, g.scalarFromPB(h.KeyType, k), val)
+		fmt.Fprintf(&buf, "%s\t}\n%s}\n", tabs, tabs)
+		return buf.String()
+	}
+}
+
+// scalarToPB returns the expression converting the primitive app value src.
+func (g *glue) scalarToPB(at *design.AttributeDefinition, src string) string {
+	if at.NumericFormat() == "decimal" {
+		return src
+	}
+	switch at.Type.Kind() {
+	case design.IntegerKind, design.NumberKind:
+		return fmt.Sprintf("%s(%s)", g.pbRef(at), src)
+	case design.DateTimeKind:
+		return receiver(src) + ".Format(time.RFC3339)"
+	case design.UUIDKind:
+		return receiver(src) + ".String()"
+	}
+	return src
+}
+
+// receiver returns the given expression so that it can be used as a method receiver.
+func receiver(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// scalarFromPB returns the expression converting the primitive protocol buffers value src.
+func (g *glue) scalarFromPB(at *design.AttributeDefinition, src string) string {
+	if at.NumericFormat() == "decimal" {
+		return src
+	}
+	switch at.Type.Kind() {
+	case design.IntegerKind, design.NumberKind:
+		return fmt.Sprintf("%s(%s)", codegen.GoNativeTypeAtt(at), src)
+	}
+	return src
+}
+
+// pbRef returns the Go type of protocol buffers values corresponding to the given attribute.
+func (g *glue) pbRef(at *design.AttributeDefinition) string {
+	switch {
+	case at.Type.IsPrimitive():
+		switch typ := scalarType(at); typ {
+		case "double":
+			return "float64"
+		case "float":
+			return "float32"
+		default:
+			return typ
+		}
+	case at.Type.IsObject():
+		return "*" + codegen.GoTypeName(at.Type, nil, 0, false)
+	case at.Type.IsArray():
+		return "[]" + g.pbRef(at.Type.ToArray().ElemType)
+	default:
+		h := at.Type.ToHash()
+		return fmt.Sprintf("map[%s]%s", g.pbRef(h.KeyType), g.pbRef(h.ElemType))
+	}
+}
+
+// appRef returns the Go type of the app values corresponding to the given attribute.
+func (g *glue) appRef(at *design.AttributeDefinition) string {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		return codegen.GoNativeTypeAtt(at)
+	case *design.Array:
+		return "[]" + g.appRef(actual.ElemType)
+	case *design.Hash:
+		return fmt.Sprintf("map[%s]%s", g.appRef(actual.KeyType), g.appRef(actual.ElemType))
+	}
+	name := g.appPkg + "." + codegen.GoTypeName(at.Type, nil, 0, false)
+	if at.Type.IsObject() {
+		return "*" + name
+	}
+	return name
+}
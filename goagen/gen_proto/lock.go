@@ -0,0 +1,112 @@
+package genproto
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Lock records the field numbers assigned in a previously generated definition file indexed
+	// by message name. Generating the definitions with a lock keeps the numbers of the existing
+	// fields, numbers the new fields after all the numbers ever used by the message and
+	// reserves the numbers of the removed fields so that they are never reused.
+	Lock map[string]*MessageLock
+
+	// MessageLock records the field numbers of a message.
+	MessageLock struct {
+		// Fields maps the field names to their numbers.
+		Fields map[string]int
+		// Reserved lists the numbers of the removed fields.
+		Reserved []int
+	}
+)
+
+var (
+	// messageRegex matches the first line of a message definition.
+	messageRegex = regexp.MustCompile(`^message\s+(\w+)\s*{`)
+	// fieldRegex matches a message field definition.
+	fieldRegex = regexp.MustCompile(`^(?:repeated\s+|optional\s+)?\S.*\s(\w+)\s*=\s*(\d+)\s*;`)
+	// reservedRegex matches a reserved numbers statement.
+	reservedRegex = regexp.MustCompile(`^reserved\s+([\d\s,]+);`)
+)
+
+// ParseLock reads the field numbers of the messages defined in the given definition file
+// previously generated by goagen.
+func ParseLock(r io.Reader) (Lock, error) {
+	var (
+		lock    = make(Lock)
+		current *MessageLock
+		scanner = bufio.NewScanner(r)
+		line    int
+	)
+	for scanner.Scan() {
+		line++
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || strings.HasPrefix(l, "//") {
+			continue
+		}
+		if current == nil {
+			if m := messageRegex.FindStringSubmatch(l); m != nil {
+				current = &MessageLock{Fields: make(map[string]int)}
+				lock[m[1]] = current
+			}
+			continue
+		}
+		if l == "}" {
+			current = nil
+			continue
+		}
+		if m := reservedRegex.FindStringSubmatch(l); m != nil {
+			for _, n := range strings.Split(m[1], ",") {
+				num, err := strconv.Atoi(strings.TrimSpace(n))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid reserved number %q", line, n)
+				}
+				current.Reserved = append(current.Reserved, num)
+			}
+			continue
+		}
+		if m := fieldRegex.FindStringSubmatch(l); m != nil {
+			num, _ := strconv.Atoi(m[2])
+			current.Fields[m[1]] = num
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// max returns the greatest number recorded in the lock, 0 if there is none.
+func (l *MessageLock) max() int {
+	var max int
+	for _, n := range l.Fields {
+		if n > max {
+			max = n
+		}
+	}
+	for _, n := range l.Reserved {
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// reserved returns the numbers reserved by the lock and the numbers of the locked fields that
+// are not part of the given fields sorted in ascending order.
+func (l *MessageLock) reserved(fields map[string]bool) []int {
+	res := append([]int(nil), l.Reserved...)
+	for n, num := range l.Fields {
+		if !fields[n] {
+			res = append(res, num)
+		}
+	}
+	sort.Ints(res)
+	return res
+}
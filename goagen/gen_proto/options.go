@@ -0,0 +1,34 @@
+package genproto
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Target Name of generated package
+func Target(target string) Option {
+	return func(g *Generator) {
+		g.Target = target
+	}
+}
+
+//AppPkg Import path of the package generated with "goagen app", may be relative to OutDir
+func AppPkg(appPkg string) Option {
+	return func(g *Generator) {
+		g.AppPkg = appPkg
+	}
+}
@@ -0,0 +1,554 @@
+package genproto
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+const (
+	// FieldMetadata is the name of the metadata used to set the number of the protocol buffers
+	// field generated for an attribute, e.g.:
+	//
+	//    Attribute("name", String, func() {
+	//        Metadata("proto:field", "2")
+	//    })
+	//
+	FieldMetadata = "proto:field"
+
+	// emptyMessage is the message used by RPCs that do not return a media type.
+	emptyMessage = "google.protobuf.Empty"
+)
+
+type (
+	// File describes a protocol buffers definition file.
+	File struct {
+		// Package is the protocol buffers package name.
+		Package string
+		// GoPackage is the import path of the Go package generated by protoc.
+		GoPackage string
+		// Imports lists the imported definition files.
+		Imports []string
+		// Messages lists the messages sorted by name.
+		Messages []*Message
+		// Services lists the services, one per resource.
+		Services []*Service
+	}
+
+	// Message describes a protocol buffers message.
+	Message struct {
+		// Name is the message name, it is also the name of the Go type generated by
+		// "goagen app" for user types and media types.
+		Name string
+		// Description is the message comment.
+		Description string
+		// Fields lists the message fields sorted by number.
+		Fields []*Field
+		// Unsupported lists the attributes that cannot be represented in protocol buffers.
+		Unsupported []string
+		// Reserved lists the numbers of the fields removed since the lock was generated.
+		Reserved []int
+		// Type is the user type or projected media type the message is built from, nil for
+		// action request messages.
+		Type design.DataType
+		// Collection is true if Type is a media type collection, the message then contains
+		// a single "items" field.
+		Collection bool
+		// Inline is true if the message was built from an attribute defined inline.
+		Inline bool
+	}
+
+	// Field describes a protocol buffers message field.
+	Field struct {
+		// Name is the field name.
+		Name string
+		// GoName is the name of the struct field generated by protoc.
+		GoName string
+		// AttName is the name of the corresponding attribute.
+		AttName string
+		// Attribute is the corresponding attribute.
+		Attribute *design.AttributeDefinition
+		// Type is the field protocol buffers type, e.g. "int64" or "map<string, Bottle>".
+		Type string
+		// Number is the field number.
+		Number int
+		// Repeated is true if the field is a list.
+		Repeated bool
+		// Optional is true if the field is a scalar with explicit presence.
+		Optional bool
+		// Description is the field comment.
+		Description string
+	}
+
+	// Service describes a gRPC service.
+	Service struct {
+		// Name is the service name.
+		Name string
+		// Description is the service comment.
+		Description string
+		// RPCs lists the service methods.
+		RPCs []*RPC
+	}
+
+	// RPC describes a gRPC service method.
+	RPC struct {
+		// Name is the method name.
+		Name string
+		// Description is the method comment.
+		Description string
+		// Request is the name of the request message.
+		Request string
+		// Response is the name of the response message.
+		Response string
+	}
+
+	// builder computes the protocol buffers definitions.
+	builder struct {
+		file     *File
+		messages map[string]*Message
+		imports  map[string]bool
+		lock     Lock
+	}
+)
+
+// New computes the protocol buffers definitions for the given API: one message per user type,
+// one message per media type view and one service per resource with one RPC per action. lock
+// records the field numbers of the previously generated definitions if any, see Lock.
+func New(api *design.APIDefinition, lock Lock) (*File, error) {
+	b := &builder{
+		file:     &File{Package: PackageName(api)},
+		messages: make(map[string]*Message),
+		imports:  make(map[string]bool),
+		lock:     lock,
+	}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if !ut.IsObject() {
+			return nil
+		}
+		_, err := b.message(ut)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() || !(mt.IsObject() || mt.IsArray()) {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, links, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			if _, err := b.message(p); err != nil {
+				return err
+			}
+			if links != nil {
+				_, err = b.message(links)
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		svc := &Service{Name: codegen.Goify(res.Name, true), Description: res.Description}
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			rpc, err := b.rpc(api, a)
+			if err != nil {
+				return err
+			}
+			svc.RPCs = append(svc.RPCs, rpc)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		b.file.Services = append(b.file.Services, svc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(b.file.Messages, func(i, j int) bool {
+		return b.file.Messages[i].Name < b.file.Messages[j].Name
+	})
+	for imp := range b.imports {
+		b.file.Imports = append(b.file.Imports, imp)
+	}
+	sort.Strings(b.file.Imports)
+	return b.file, nil
+}
+
+// PackageName returns the protocol buffers package name for the given API.
+func PackageName(api *design.APIDefinition) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, codegen.SnakeCase(api.Name))
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "api" + name
+	}
+	return name
+}
+
+// rpc computes the RPC corresponding to the given action. The request message contains the
+// action parameters and a "payload" field if the action has a payload. The response message
+// corresponds to the view of the media type returned by the success response with the lowest
+// status code.
+func (b *builder) rpc(api *design.APIDefinition, a *design.ActionDefinition) (*RPC, error) {
+	reqName := codegen.Goify(a.Parent.Name, true) + codegen.Goify(a.Name, true) + "Request"
+	req := &design.AttributeDefinition{Type: design.Object{}}
+	if params := a.AllParams(); params != nil {
+		for n, p := range params.Type.ToObject() {
+			req.Type.ToObject()[n] = p
+		}
+		if params.Validation != nil {
+			req.Validation = params.Validation.Dup()
+		}
+	}
+	if a.Payload != nil {
+		req.Type.ToObject()["payload"] = &design.AttributeDefinition{
+			Type:     a.Payload,
+			Metadata: a.Payload.Metadata,
+		}
+		if !a.PayloadOptional {
+			if req.Validation == nil {
+				req.Validation = &dslengine.ValidationDefinition{}
+			}
+			req.Validation.Required = append(req.Validation.Required, "payload")
+		}
+	}
+	m := &Message{Name: reqName, Description: fmt.Sprintf("%s is the request message of the %s %s action.", reqName, a.Parent.Name, a.Name)}
+	b.messages[reqName] = m
+	b.file.Messages = append(b.file.Messages, m)
+	var err error
+	if m.Fields, m.Unsupported, m.Reserved, err = b.fields(reqName, req); err != nil {
+		return nil, err
+	}
+
+	resp := emptyMessage
+	responses := make([]*design.ResponseDefinition, 0, len(a.Responses))
+	for _, r := range a.Responses {
+		responses = append(responses, r)
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Status < responses[j].Status })
+	for _, r := range responses {
+		if r.Status < 200 || r.Status >= 300 || r.MediaType == "" {
+			continue
+		}
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil || mt.IsError() {
+			continue
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = b.message(p); err != nil {
+			return nil, err
+		}
+		break
+	}
+	if resp == emptyMessage {
+		b.imports["google/protobuf/empty.proto"] = true
+	}
+	return &RPC{
+		Name:        codegen.Goify(a.Name, true),
+		Description: a.Description,
+		Request:     reqName,
+		Response:    resp,
+	}, nil
+}
+
+// message returns the name of the message corresponding to the given user type or media type,
+// creating it if needed.
+func (b *builder) message(t design.DataType) (string, error) {
+	name := codegen.GoTypeName(t, nil, 0, false)
+	if _, ok := b.messages[name]; ok {
+		return name, nil
+	}
+	var att *design.AttributeDefinition
+	switch actual := t.(type) {
+	case *design.UserTypeDefinition:
+		att = actual.AttributeDefinition
+	case *design.MediaTypeDefinition:
+		att = actual.AttributeDefinition
+	default:
+		return "", fmt.Errorf("%s: not a user type", name)
+	}
+	m := &Message{Name: name, Description: att.Description, Type: t}
+	b.messages[name] = m
+	b.file.Messages = append(b.file.Messages, m)
+	if arr := att.Type.ToArray(); arr != nil {
+		typ, repeated, err := b.fieldType(arr.ElemType, name+"Item")
+		if err != nil {
+			return "", err
+		}
+		if typ == "" || repeated {
+			return "", fmt.Errorf("%s: unsupported collection element type", name)
+		}
+		m.Collection = true
+		m.Fields = []*Field{{
+			Name:      "items",
+			GoName:    "Items",
+			Attribute: arr.ElemType,
+			Type:      typ,
+			Number:    1,
+			Repeated:  true,
+		}}
+		return name, nil
+	}
+	var err error
+	m.Fields, m.Unsupported, m.Reserved, err = b.fields(name, att)
+	return name, err
+}
+
+// fields computes the fields of the message built from the given object attribute. Fields are
+// numbered using the "proto:field" metadata if present or the number recorded in the lock. The
+// other fields are numbered in alphabetical order starting after the greatest number recorded in
+// the lock so that numbers are never reused. fields also returns the numbers of the locked fields
+// that no longer exist. It fails if the number of a locked field would change.
+func (b *builder) fields(msg string, att *design.AttributeDefinition) ([]*Field, []string, []int, error) {
+	obj := att.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var (
+		fields      []*Field
+		unsupported []string
+		reserved    []int
+		used        = make(map[int]string)
+		next        = 1
+		lock        = b.lock[msg]
+	)
+	for _, n := range names {
+		at := obj[n]
+		typ, repeated, err := b.fieldType(at, msg+codegen.Goify(n, true))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if typ == "" {
+			unsupported = append(unsupported, n)
+			continue
+		}
+		num, err := fieldNumber(msg, n, at)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		name := fieldName(n)
+		if lock != nil {
+			if locked, ok := lock.Fields[name]; ok {
+				if num > 0 && num != locked {
+					return nil, nil, nil, fmt.Errorf("%s: the number of field %q would change from %d to %d", msg, name, locked, num)
+				}
+				num = locked
+			}
+		}
+		if num > 0 {
+			if other, ok := used[num]; ok {
+				return nil, nil, nil, fmt.Errorf("%s: fields %q and %q use the same number %d", msg, other, n, num)
+			}
+			used[num] = n
+		}
+		fields = append(fields, &Field{
+			Name:        name,
+			GoName:      goCamelCase(name),
+			AttName:     n,
+			Attribute:   at,
+			Type:        typ,
+			Number:      num,
+			Repeated:    repeated,
+			Optional:    at.Type.IsPrimitive() && (att.IsPrimitivePointer(n) || at.Nullable),
+			Description: at.Description,
+		})
+	}
+	if lock != nil {
+		current := make(map[string]bool, len(fields))
+		for _, f := range fields {
+			current[f.Name] = true
+		}
+		reserved = lock.reserved(current)
+		for _, num := range reserved {
+			if n, ok := used[num]; ok {
+				return nil, nil, nil, fmt.Errorf("%s: field %q uses the number %d of a removed field", msg, n, num)
+			}
+		}
+		next = lock.max() + 1
+	}
+	for _, f := range fields {
+		if f.Number > 0 {
+			continue
+		}
+		for used[next] != "" || next >= 19000 && next <= 19999 {
+			next++
+		}
+		f.Number = next
+		used[next] = f.AttName
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Number < fields[j].Number })
+	return fields, unsupported, reserved, nil
+}
+
+// fieldType returns the protocol buffers type of the field corresponding to the given attribute
+// and whether the field is repeated. It returns an empty type if the attribute type cannot be
+// represented. nested is the name of the message created for attributes defined inline.
+func (b *builder) fieldType(at *design.AttributeDefinition, nested string) (string, bool, error) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		return scalarType(at), false, nil
+	case *design.Array:
+		elem := actual.ElemType
+		if elem.Type.IsArray() || elem.Type.IsHash() {
+			return "", false, nil
+		}
+		typ, _, err := b.fieldType(elem, nested+"Item")
+		return typ, true, err
+	case *design.Hash:
+		key := ""
+		switch actual.KeyType.Type.Kind() {
+		case design.BooleanKind, design.IntegerKind, design.StringKind:
+			key = scalarType(actual.KeyType)
+		}
+		elem := actual.ElemType
+		if key == "" || elem.Type.IsArray() || elem.Type.IsHash() {
+			return "", false, nil
+		}
+		typ, _, err := b.fieldType(elem, nested+"Value")
+		if err != nil || typ == "" {
+			return "", false, err
+		}
+		return fmt.Sprintf("map<%s, %s>", key, typ), false, nil
+	case design.Object:
+		if _, ok := b.messages[nested]; ok {
+			return nested, false, nil
+		}
+		m := &Message{Name: nested, Description: at.Description, Inline: true}
+		b.messages[nested] = m
+		b.file.Messages = append(b.file.Messages, m)
+		var err error
+		m.Fields, m.Unsupported, m.Reserved, err = b.fields(nested, at)
+		return nested, false, err
+	case *design.UserTypeDefinition:
+		if !actual.IsObject() {
+			return b.fieldType(actual.AttributeDefinition, nested)
+		}
+		name, err := b.message(actual)
+		return name, false, err
+	case *design.MediaTypeDefinition:
+		if actual.IsError() {
+			return "", false, nil
+		}
+		if !actual.IsObject() {
+			return b.fieldType(actual.AttributeDefinition, nested)
+		}
+		name, err := b.message(actual)
+		return name, false, err
+	}
+	return "", false, nil
+}
+
+// scalarType returns the protocol buffers scalar type used to represent the given primitive
+// attribute. The "int32", "uint32" and "uint64" formats select the integer bitness and the
+// "float" format selects single precision numbers. It returns an empty string for Any and File.
+func scalarType(at *design.AttributeDefinition) string {
+	var format string
+	if at.Validation != nil {
+		format = at.Validation.Format
+	}
+	switch at.Type.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		switch format {
+		case "int32", "uint32":
+			return format
+		case "uint", "uint64":
+			return "uint64"
+		}
+		return "int64"
+	case design.NumberKind:
+		if format == "decimal" {
+			return "string"
+		}
+		if format == "float" || format == "float32" {
+			return "float"
+		}
+		return "double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		return "string"
+	}
+	return ""
+}
+
+// fieldNumber returns the field number set with the "proto:field" metadata, 0 if there is none.
+func fieldNumber(msg, name string, at *design.AttributeDefinition) (int, error) {
+	vals, ok := at.Metadata[FieldMetadata]
+	if !ok || len(vals) == 0 {
+		return 0, nil
+	}
+	num, err := strconv.Atoi(vals[0])
+	if err != nil || num < 1 || num > 536870911 || num >= 19000 && num <= 19999 {
+		return 0, fmt.Errorf("%s: invalid %s metadata value %q for field %q", msg, FieldMetadata, vals[0], name)
+	}
+	return num, nil
+}
+
+// fieldName returns the protocol buffers field name for the given attribute name.
+func fieldName(att string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, codegen.SnakeCase(att))
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "f_" + name
+	}
+	return name
+}
+
+// goCamelCase returns the name of the Go struct field generated by protoc for the given protocol
+// buffers field name.
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skip over '_' in "_{{lowercase}}".
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool { return 'a' <= c && c <= 'z' }
+
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
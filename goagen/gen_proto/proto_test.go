@@ -0,0 +1,365 @@
+package genproto_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_proto"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New", func() {
+	var file *genproto.File
+	var lock genproto.Lock
+	var newErr error
+
+	BeforeEach(func() {
+		file = nil
+		lock = nil
+		newErr = nil
+		dslengine.Reset()
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		file, newErr = genproto.New(Design, lock)
+	})
+
+	message := func(name string) *genproto.Message {
+		for _, m := range file.Messages {
+			if m.Name == name {
+				return m
+			}
+		}
+		return nil
+	}
+
+	Context("with a design", func() {
+		BeforeEach(func() {
+			API("cellar", func() {})
+			Type("Origin", func() {
+				Attribute("country", String)
+				Attribute("region", String, func() {
+					Metadata("proto:field", "5")
+				})
+				Attribute("extra", Any)
+				Required("country")
+			})
+			BottleMedia := MediaType("application/vnd.goa.example.bottle", func() {
+				Description("A bottle of wine")
+				Attributes(func() {
+					Attribute("id", Integer, "ID of bottle")
+					Attribute("name", String)
+					Attribute("tags", ArrayOf(String))
+					Attribute("ratings", HashOf(String, Number))
+					Attribute("origin", "Origin")
+					Required("id")
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+					Attribute("tags")
+					Attribute("ratings")
+					Attribute("origin")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottle", func() {
+				Description("The bottle resource")
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Response(OK, func() {
+						Media(BottleMedia, "tiny")
+					})
+					Response(NotFound)
+				})
+				Action("create", func() {
+					Routing(POST(""))
+					Payload("Origin")
+					Response(NoContent)
+				})
+				Action("list", func() {
+					Routing(GET(""))
+					Response(OK, CollectionOf(BottleMedia))
+				})
+			})
+		})
+
+		It("creates one message per type and view", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(file.Package).Should(Equal("cellar"))
+			Ω(message("Origin")).ShouldNot(BeNil())
+			Ω(message("GoaExampleBottle")).ShouldNot(BeNil())
+			Ω(message("GoaExampleBottleTiny")).ShouldNot(BeNil())
+			Ω(message("GoaExampleBottleCollection")).ShouldNot(BeNil())
+			Ω(message("GoaExampleBottleCollection").Collection).Should(BeTrue())
+			Ω(message("GoaExampleBottleTiny").Fields).Should(HaveLen(1))
+		})
+
+		It("numbers the fields", func() {
+			origin := message("Origin")
+			Ω(origin.Fields).Should(HaveLen(2))
+			Ω(origin.Fields[0].Name).Should(Equal("country"))
+			Ω(origin.Fields[0].Number).Should(Equal(1))
+			Ω(origin.Fields[0].Optional).Should(BeFalse())
+			Ω(origin.Fields[1].Name).Should(Equal("region"))
+			Ω(origin.Fields[1].Number).Should(Equal(5))
+			Ω(origin.Fields[1].Optional).Should(BeTrue())
+			Ω(origin.Unsupported).Should(Equal([]string{"extra"}))
+		})
+
+		It("maps the attribute types", func() {
+			bottle := message("GoaExampleBottle")
+			types := make(map[string]string)
+			for _, f := range bottle.Fields {
+				types[f.Name] = f.Type
+				if f.Name == "tags" {
+					Ω(f.Repeated).Should(BeTrue())
+				}
+			}
+			Ω(types).Should(Equal(map[string]string{
+				"id":      "int64",
+				"name":    "string",
+				"origin":  "Origin",
+				"ratings": "map<string, double>",
+				"tags":    "string",
+			}))
+		})
+
+		It("creates one service per resource", func() {
+			Ω(file.Services).Should(HaveLen(1))
+			svc := file.Services[0]
+			Ω(svc.Name).Should(Equal("Bottle"))
+			Ω(svc.RPCs).Should(HaveLen(3))
+			rpcs := make(map[string]*genproto.RPC)
+			for _, r := range svc.RPCs {
+				rpcs[r.Name] = r
+			}
+			Ω(rpcs["Show"].Request).Should(Equal("BottleShowRequest"))
+			Ω(rpcs["Show"].Response).Should(Equal("GoaExampleBottleTiny"))
+			Ω(rpcs["Create"].Response).Should(Equal("google.protobuf.Empty"))
+			Ω(rpcs["List"].Response).Should(Equal("GoaExampleBottleCollection"))
+			Ω(file.Imports).Should(Equal([]string{"google/protobuf/empty.proto"}))
+			req := message("BottleCreateRequest")
+			Ω(req.Fields).Should(HaveLen(1))
+			Ω(req.Fields[0].Name).Should(Equal("payload"))
+			Ω(req.Fields[0].Type).Should(Equal("CreateBottlePayload"))
+		})
+
+		Context("with an integer format", func() {
+			JustBeforeEach(func() {
+				id := Design.MediaTypes["application/vnd.goa.example.bottle"].Type.ToObject()["id"]
+				id.Validation = &dslengine.ValidationDefinition{Format: "int32"}
+				ProjectedMediaTypes = make(map[string]*MediaTypeDefinition)
+				file, newErr = genproto.New(Design, lock)
+			})
+
+			It("uses the format bitness", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(message("GoaExampleBottleTiny").Fields[0].Type).Should(Equal("int32"))
+			})
+		})
+
+		Context("with a decimal format", func() {
+			JustBeforeEach(func() {
+				id := Design.MediaTypes["application/vnd.goa.example.bottle"].Type.ToObject()["id"]
+				id.Type = Number
+				id.Validation = &dslengine.ValidationDefinition{Format: "decimal"}
+				ProjectedMediaTypes = make(map[string]*MediaTypeDefinition)
+				file, newErr = genproto.New(Design, lock)
+			})
+
+			It("uses strings", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(message("GoaExampleBottleTiny").Fields[0].Type).Should(Equal("string"))
+			})
+		})
+	})
+
+	Context("with a lock", func() {
+		BeforeEach(func() {
+			API("cellar", func() {})
+			lock = genproto.Lock{"Origin": {
+				Fields:   map[string]int{"country": 2, "region": 1, "old": 4},
+				Reserved: []int{3},
+			}}
+		})
+
+		Context("and new fields", func() {
+			BeforeEach(func() {
+				Type("Origin", func() {
+					Attribute("country", String)
+					Attribute("region", String)
+					Attribute("zip", String)
+				})
+			})
+
+			It("keeps the locked numbers and appends the new fields", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				origin := message("Origin")
+				Ω(origin.Fields).Should(HaveLen(3))
+				Ω(origin.Fields[0].Name).Should(Equal("region"))
+				Ω(origin.Fields[0].Number).Should(Equal(1))
+				Ω(origin.Fields[1].Name).Should(Equal("country"))
+				Ω(origin.Fields[1].Number).Should(Equal(2))
+				Ω(origin.Fields[2].Name).Should(Equal("zip"))
+				Ω(origin.Fields[2].Number).Should(Equal(5))
+				Ω(origin.Reserved).Should(Equal([]int{3, 4}))
+			})
+		})
+
+		Context("and metadata that changes a locked number", func() {
+			BeforeEach(func() {
+				Type("Origin", func() {
+					Attribute("country", String, func() {
+						Metadata("proto:field", "1")
+					})
+				})
+			})
+
+			It("returns an error", func() {
+				Ω(newErr).Should(HaveOccurred())
+				Ω(newErr.Error()).Should(ContainSubstring(`the number of field "country" would change from 2 to 1`))
+			})
+		})
+
+		Context("and metadata that reuses the number of a removed field", func() {
+			BeforeEach(func() {
+				Type("Origin", func() {
+					Attribute("country", String)
+					Attribute("zip", String, func() {
+						Metadata("proto:field", "4")
+					})
+				})
+			})
+
+			It("returns an error", func() {
+				Ω(newErr).Should(HaveOccurred())
+				Ω(newErr.Error()).Should(ContainSubstring("uses the number 4 of a removed field"))
+			})
+		})
+	})
+
+	Context("with duplicate field numbers", func() {
+		BeforeEach(func() {
+			API("cellar", func() {})
+			Type("Origin", func() {
+				Attribute("country", String, func() {
+					Metadata("proto:field", "1")
+				})
+				Attribute("region", String, func() {
+					Metadata("proto:field", "1")
+				})
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(newErr).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		dslengine.Reset()
+		API("cellar", func() {})
+		Type("Origin", func() {
+			Attribute("country", String)
+			Attribute("created_at", DateTime)
+			Attribute("ids", ArrayOf(UUID))
+			Required("country")
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genproto.Generate()
+	})
+
+	Context("with a previously generated definition file", func() {
+		BeforeEach(func() {
+			Ω(os.MkdirAll(filepath.Join(outDir, "pb"), 0755)).Should(Succeed())
+			prev := "message Origin {\n\tstring country = 3;\n\tstring old = 1;\n}\n"
+			Ω(ioutil.WriteFile(filepath.Join(outDir, "pb", "cellar.proto"), []byte(prev), 0644)).Should(Succeed())
+		})
+
+		It("keeps the field numbers", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "pb", "cellar.proto"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("message Origin {\n\tstring country = 3;\n\toptional string created_at = 4;\n\trepeated string ids = 5;\n\treserved 1;\n}"))
+		})
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the definitions and the conversion functions", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(3))
+
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "pb", "cellar.proto"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring(`syntax = "proto3";`))
+		Ω(string(content)).Should(ContainSubstring("message Origin {\n\tstring country = 1;\n\toptional string created_at = 2;\n\trepeated string ids = 3;\n}"))
+
+		content, err = ioutil.ReadFile(filepath.Join(outDir, "pb", "convert.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("package pb"))
+		Ω(string(content)).Should(ContainSubstring("func OriginToPB(v *app.Origin) *Origin {"))
+		Ω(string(content)).Should(ContainSubstring("func OriginFromPB(v *Origin) (*app.Origin, error) {"))
+		Ω(string(content)).Should(ContainSubstring("val = (*v.CreatedAt).Format(time.RFC3339)"))
+	})
+})
+
+var _ = Describe("ParseLock", func() {
+	It("reads the field numbers of the messages", func() {
+		lock, err := genproto.ParseLock(strings.NewReader(`syntax = "proto3";
+
+// Origin is a type.
+message Origin {
+	// country of origin
+	string country = 1;
+	optional string region = 5;
+	repeated string ids = 6;
+	map<string, double> ratings = 7;
+	reserved 2, 3;
+	// extra is not supported by protocol buffers.
+}
+
+service BottleService {
+	rpc Show(BottleShowRequest) returns (Bottle);
+}
+`))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(lock).Should(HaveLen(1))
+		Ω(lock["Origin"].Fields).Should(Equal(map[string]int{"country": 1, "region": 5, "ids": 6, "ratings": 7}))
+		Ω(lock["Origin"].Reserved).Should(Equal([]int{2, 3}))
+	})
+})
@@ -39,14 +39,41 @@ type (
 		Format               string        `json:"format,omitempty"`
 		Pattern              string        `json:"pattern,omitempty"`
 		Minimum              *float64      `json:"minimum,omitempty"`
+		ExclusiveMinimum     interface{}   `json:"exclusiveMinimum,omitempty"` // true or the excluded minimum in JSON Schema 2020-12
 		Maximum              *float64      `json:"maximum,omitempty"`
+		ExclusiveMaximum     interface{}   `json:"exclusiveMaximum,omitempty"` // true or the excluded maximum in JSON Schema 2020-12
+		MultipleOf           *float64      `json:"multipleOf,omitempty"`
 		MinLength            *int          `json:"minLength,omitempty"`
 		MaxLength            *int          `json:"maxLength,omitempty"`
+		UniqueItems          bool          `json:"uniqueItems,omitempty"`
+		MinProperties        *int          `json:"minProperties,omitempty"`
+		MaxProperties        *int          `json:"maxProperties,omitempty"`
 		Required             []string      `json:"required,omitempty"`
 		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
+		// Dependencies lists the properties required by each property when present.
+		Dependencies map[string][]string `json:"dependencies,omitempty"`
+		// DependentRequired replaces Dependencies in JSON Schema 2020-12 used by OpenAPI 3.1.
+		DependentRequired map[string][]string `json:"dependentRequired,omitempty"`
 
 		// Union
 		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+		OneOf []*JSONSchema `json:"oneOf,omitempty"`
+		AllOf []*JSONSchema `json:"allOf,omitempty"`
+		Not   *JSONSchema   `json:"not,omitempty"`
+
+		// Discriminator is an extension used by Swagger to represent polymorphism.
+		Discriminator string `json:"discriminator,omitempty"`
+		// XNullable is an extension used by Swagger to represent nullable values.
+		XNullable bool `json:"x-nullable,omitempty"`
+		// XDiscriminatorValue is an extension used by Swagger to set the value of the
+		// discriminator that selects a schema when it differs from the schema name.
+		XDiscriminatorValue string `json:"x-discriminator-value,omitempty"`
+		// XOneOf is an extension used by Swagger to list the alternatives of a union type
+		// that has no discriminator.
+		XOneOf []*JSONSchema `json:"x-oneOf,omitempty"`
+
+		// Nullable adds "null" to the type of the schema when encoded, see MarshalJSON.
+		Nullable bool `json:"-"`
 	}
 
 	// JSONType is the JSON type enum.
@@ -123,6 +150,19 @@ func (s *JSONSchema) JSON() ([]byte, error) {
 	return json.Marshal(s)
 }
 
+// MarshalJSON encodes the schema. The type of a nullable schema is encoded as an array that
+// contains the schema type and "null".
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	type schema JSONSchema
+	if !s.Nullable || s.Type == "" {
+		return json.Marshal(schema(s))
+	}
+	return json.Marshal(struct {
+		schema
+		Type []JSONType `json:"type"`
+	}{schema(s), []JSONType{s.Type, JSONNull}})
+}
+
 // APISchema produces the API JSON hyper schema.
 func APISchema(api *design.APIDefinition) *JSONSchema {
 	api.IterateResources(func(r *design.ResourceDefinition) error {
@@ -199,14 +239,18 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 				} else {
 					identifier = ""
 				}
+				respSchema := TypeSchema(api, mt)
+				if p := a.Pagination; p != nil && p.Envelope && resp.Name == design.OK {
+					respSchema = PageEnvelopeSchema(p, respSchema)
+				}
 				if targetSchema == nil {
-					targetSchema = TypeSchema(api, mt)
+					targetSchema = respSchema
 				} else if targetSchema.AnyOf == nil {
 					firstSchema := targetSchema
 					targetSchema = NewJSONSchema()
-					targetSchema.AnyOf = []*JSONSchema{firstSchema, TypeSchema(api, mt)}
+					targetSchema.AnyOf = []*JSONSchema{firstSchema, respSchema}
 				} else {
-					targetSchema.AnyOf = append(targetSchema.AnyOf, TypeSchema(api, mt))
+					targetSchema.AnyOf = append(targetSchema.AnyOf, respSchema)
 				}
 			}
 		}
@@ -277,6 +321,36 @@ func GenerateTypeDefinition(api *design.APIDefinition, ut *design.UserTypeDefini
 	buildAttributeSchema(api, s, ut.AttributeDefinition)
 }
 
+// PageEnvelopeSchema produces the JSON schema of the response body of paginated actions that wrap
+// the page of results described by items together with the pagination fields.
+func PageEnvelopeSchema(p *design.PaginationDefinition, items *JSONSchema) *JSONSchema {
+	s := NewJSONSchema()
+	s.Type = JSONObject
+	s.Properties["items"] = items
+	if p.Strategy == design.Cursor {
+		s.Properties["next_cursor"] = &JSONSchema{
+			Type:        JSONString,
+			Description: "Cursor of the next page, absent on the last page",
+		}
+		s.Required = []string{"items"}
+		return s
+	}
+	s.Properties["offset"] = &JSONSchema{
+		Type:        JSONInteger,
+		Description: "Position of the first result of the page in the collection",
+	}
+	s.Properties["limit"] = &JSONSchema{
+		Type:        JSONInteger,
+		Description: "Maximum number of results in the page",
+	}
+	s.Properties["total"] = &JSONSchema{
+		Type:        JSONInteger,
+		Description: "Total number of results in the collection, absent if unknown",
+	}
+	s.Required = []string{"items", "offset", "limit"}
+	return s
+}
+
 // TypeSchema produces the JSON schema corresponding to the given data type.
 func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	s := NewJSONSchema()
@@ -304,11 +378,31 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 		for n, at := range actual {
 			prop := NewJSONSchema()
 			buildAttributeSchema(api, prop, at)
+			if at.NumericFormat() == "int64" {
+				// int64 fields are encoded as strings, see design.IntegerFormats.
+				encodeAsString(prop)
+			}
 			s.Properties[n] = prop
 		}
 	case *design.Hash:
 		s.Type = JSONObject
 		s.AdditionalProperties = true
+	case *design.Union:
+		for _, n := range actual.VariantNames() {
+			variant := AttributeSchema(api, actual.Variants[n])
+			if actual.Discriminator != "" {
+				// The value of the discriminator attribute is the variant name.
+				prop := NewJSONSchema()
+				prop.Type = JSONString
+				prop.Enum = []interface{}{n}
+				disc := NewJSONSchema()
+				disc.Type = JSONObject
+				disc.Properties[actual.Discriminator] = prop
+				disc.Required = []string{actual.Discriminator}
+				variant = &JSONSchema{AllOf: []*JSONSchema{variant, disc}}
+			}
+			s.OneOf = append(s.OneOf, variant)
+		}
 	case *design.UserTypeDefinition:
 		s.Ref = TypeRef(api, actual)
 	case *design.MediaTypeDefinition:
@@ -318,6 +412,12 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	return s
 }
 
+// AttributeSchema produces the JSON schema corresponding to the given attribute including its
+// description, default value, example and validations.
+func AttributeSchema(api *design.APIDefinition, at *design.AttributeDefinition) *JSONSchema {
+	return buildAttributeSchema(api, NewJSONSchema(), at)
+}
+
 type mergeItems []struct {
 	a, b   interface{}
 	needed bool
@@ -338,6 +438,22 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 		{&s.Format, other.Format, s.Format == ""},
 		{&s.Pattern, other.Pattern, s.Pattern == ""},
 		{&s.AdditionalProperties, other.AdditionalProperties, s.AdditionalProperties == false},
+		{&s.ExclusiveMinimum, other.ExclusiveMinimum, s.ExclusiveMinimum == nil},
+		{&s.ExclusiveMaximum, other.ExclusiveMaximum, s.ExclusiveMaximum == nil},
+		{&s.MultipleOf, other.MultipleOf, s.MultipleOf == nil},
+		{&s.UniqueItems, other.UniqueItems, s.UniqueItems == false},
+		{&s.MinProperties, other.MinProperties, s.MinProperties == nil},
+		{&s.MaxProperties, other.MaxProperties, s.MaxProperties == nil},
+		{&s.OneOf, other.OneOf, s.OneOf == nil},
+		{&s.AllOf, other.AllOf, s.AllOf == nil},
+		{&s.Not, other.Not, s.Not == nil},
+		{&s.Dependencies, other.Dependencies, s.Dependencies == nil},
+		{&s.DependentRequired, other.DependentRequired, s.DependentRequired == nil},
+		{&s.Discriminator, other.Discriminator, s.Discriminator == ""},
+		{&s.XNullable, other.XNullable, s.XNullable == false},
+		{&s.XDiscriminatorValue, other.XDiscriminatorValue, s.XDiscriminatorValue == ""},
+		{&s.XOneOf, other.XOneOf, s.XOneOf == nil},
+		{&s.Nullable, other.Nullable, s.Nullable == false},
 		{
 			a: s.Minimum, b: other.Minimum,
 			needed: (s.Minimum == nil && s.Minimum != nil) ||
@@ -412,20 +528,42 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		Format:               s.Format,
 		Pattern:              s.Pattern,
 		Minimum:              s.Minimum,
+		ExclusiveMinimum:     s.ExclusiveMinimum,
 		Maximum:              s.Maximum,
+		ExclusiveMaximum:     s.ExclusiveMaximum,
+		MultipleOf:           s.MultipleOf,
 		MinLength:            s.MinLength,
 		MaxLength:            s.MaxLength,
+		UniqueItems:          s.UniqueItems,
+		MinProperties:        s.MinProperties,
+		MaxProperties:        s.MaxProperties,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
-	}
-	for n, p := range s.Properties {
-		js.Properties[n] = p.Dup()
+		OneOf:                s.OneOf,
+		AllOf:                s.AllOf,
+		Not:                  s.Not,
+		Dependencies:         s.Dependencies,
+		DependentRequired:    s.DependentRequired,
+		Discriminator:        s.Discriminator,
+		XNullable:            s.XNullable,
+		XDiscriminatorValue:  s.XDiscriminatorValue,
+		XOneOf:               s.XOneOf,
+		Nullable:             s.Nullable,
+	}
+	if s.Properties != nil {
+		js.Properties = make(map[string]*JSONSchema, len(s.Properties))
+		for n, p := range s.Properties {
+			js.Properties[n] = p.Dup()
+		}
 	}
 	if s.Items != nil {
 		js.Items = s.Items.Dup()
 	}
-	for n, d := range s.Definitions {
-		js.Definitions[n] = d.Dup()
+	if s.Definitions != nil {
+		js.Definitions = make(map[string]*JSONSchema, len(s.Definitions))
+		for n, d := range s.Definitions {
+			js.Definitions[n] = d.Dup()
+		}
 	}
 	return &js
 }
@@ -446,18 +584,28 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	s.DefaultValue = toStringMap(at.DefaultValue)
 	s.Description = at.Description
 	s.Example = at.GenerateExample(api.RandomGenerator(), nil)
+	s.Nullable = at.Nullable
 	val := at.Validation
 	if val == nil {
 		return s
 	}
 	s.Enum = val.Values
-	s.Format = val.Format
+	s.Format = JSONFormat(val.Format)
 	s.Pattern = val.Pattern
 	if val.Minimum != nil {
 		s.Minimum = val.Minimum
+		if val.ExclusiveMinimum {
+			s.ExclusiveMinimum = true
+		}
 	}
 	if val.Maximum != nil {
 		s.Maximum = val.Maximum
+		if val.ExclusiveMaximum {
+			s.ExclusiveMaximum = true
+		}
+	}
+	if val.MultipleOf != nil {
+		s.MultipleOf = val.MultipleOf
 	}
 	if val.MinLength != nil {
 		s.MinLength = val.MinLength
@@ -465,10 +613,62 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	if val.MaxLength != nil {
 		s.MaxLength = val.MaxLength
 	}
+	s.UniqueItems = val.UniqueItems
+	if val.MinProperties != nil {
+		s.MinProperties = val.MinProperties
+	}
+	if val.MaxProperties != nil {
+		s.MaxProperties = val.MaxProperties
+	}
 	s.Required = val.Required
+	s.Dependencies = val.DependentRequired
+	for _, names := range val.OneOfRequired {
+		s.AllOf = append(s.AllOf, &JSONSchema{OneOf: requiredSchemas(names)})
+	}
+	for _, names := range val.MutuallyExclusive {
+		var pairs []*JSONSchema
+		for i, n := range names {
+			for _, o := range names[i+1:] {
+				pairs = append(pairs, &JSONSchema{Required: []string{n, o}})
+			}
+		}
+		s.AllOf = append(s.AllOf, &JSONSchema{Not: &JSONSchema{AnyOf: pairs}})
+	}
+	if at.NumericFormat() == "decimal" {
+		encodeAsString(s)
+	}
 	return s
 }
 
+// requiredSchemas returns the schemas that each require one of the given properties.
+func requiredSchemas(names []string) []*JSONSchema {
+	res := make([]*JSONSchema, len(names))
+	for i, n := range names {
+		res[i] = &JSONSchema{Required: []string{n}}
+	}
+	return res
+}
+
+// JSONFormat returns the JSON schema format corresponding to the given validation format.
+// The "float32" numeric format maps to the OpenAPI "float" format.
+func JSONFormat(format string) string {
+	if format == "float32" {
+		return "float"
+	}
+	return format
+}
+
+// encodeAsString updates the schema of a numeric value encoded as a JSON string.
+func encodeAsString(s *JSONSchema) {
+	s.Type = JSONString
+	if s.DefaultValue != nil {
+		s.DefaultValue = toString(s.DefaultValue)
+	}
+	if s.Example != nil {
+		s.Example = toString(s.Example)
+	}
+}
+
 // toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
 func toStringMap(val interface{}) interface{} {
 	switch actual := val.(type) {
@@ -1,6 +1,8 @@
 package genschema_test
 
 import (
+	"encoding/json"
+
 	"github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -78,4 +80,139 @@ var _ = Describe("TypeSchema", func() {
 		})
 
 	})
+
+	Context("with numeric formats", func() {
+		BeforeEach(func() {
+			format := func(f string) *dslengine.ValidationDefinition {
+				return &dslengine.ValidationDefinition{Format: f}
+			}
+			typ = design.Object{
+				"id":     &design.AttributeDefinition{Type: design.Integer, Validation: format("int64"), Example: 42},
+				"count":  &design.AttributeDefinition{Type: design.Integer, Validation: format("int32")},
+				"ratio":  &design.AttributeDefinition{Type: design.Number, Validation: format("float32")},
+				"amount": &design.AttributeDefinition{Type: design.Number, Validation: format("decimal"), DefaultValue: 12.5},
+			}
+		})
+
+		It("sets the formats and encodes int64 and decimal values as strings", func() {
+			Ω(s.Properties["id"].Type).Should(BeEquivalentTo(genschema.JSONString))
+			Ω(s.Properties["id"].Format).Should(Equal("int64"))
+			Ω(s.Properties["id"].Example).Should(Equal("42"))
+			Ω(s.Properties["count"].Type).Should(BeEquivalentTo(genschema.JSONInteger))
+			Ω(s.Properties["count"].Format).Should(Equal("int32"))
+			Ω(s.Properties["ratio"].Format).Should(Equal("float"))
+			Ω(s.Properties["amount"].Type).Should(BeEquivalentTo(genschema.JSONString))
+			Ω(s.Properties["amount"].Format).Should(Equal("decimal"))
+			Ω(s.Properties["amount"].DefaultValue).Should(Equal("12.5"))
+		})
+	})
+
+	Context("with extended validations", func() {
+		BeforeEach(func() {
+			min, multiple, maxProps := 0.0, 0.5, 3
+			typ = design.Object{
+				"price": &design.AttributeDefinition{Type: design.Number, Validation: &dslengine.ValidationDefinition{
+					Minimum: &min, ExclusiveMinimum: true, MultipleOf: &multiple,
+				}},
+				"ids": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}},
+					Validation: &dslengine.ValidationDefinition{UniqueItems: true}},
+				"labels": &design.AttributeDefinition{Type: &design.Hash{
+					KeyType:  &design.AttributeDefinition{Type: design.String},
+					ElemType: &design.AttributeDefinition{Type: design.String},
+				}, Validation: &dslengine.ValidationDefinition{MaxProperties: &maxProps}},
+			}
+		})
+
+		It("sets the validations", func() {
+			price := s.Properties["price"]
+			Ω(*price.Minimum).Should(Equal(0.0))
+			Ω(price.ExclusiveMinimum).Should(BeTrue())
+			Ω(*price.MultipleOf).Should(Equal(0.5))
+			Ω(s.Properties["ids"].UniqueItems).Should(BeTrue())
+			Ω(*s.Properties["labels"].MaxProperties).Should(Equal(3))
+		})
+	})
+
+	Context("with cross-field validations", func() {
+		BeforeEach(func() {
+			typ = design.Object{
+				"email":       &design.AttributeDefinition{Type: design.String},
+				"phone":       &design.AttributeDefinition{Type: design.String},
+				"fax":         &design.AttributeDefinition{Type: design.String},
+				"country":     &design.AttributeDefinition{Type: design.String},
+				"postal_code": &design.AttributeDefinition{Type: design.String},
+			}
+		})
+
+		JustBeforeEach(func() {
+			att := &design.AttributeDefinition{Type: typ, Validation: &dslengine.ValidationDefinition{
+				OneOfRequired:     [][]string{{"email", "phone"}},
+				MutuallyExclusive: [][]string{{"fax", "phone"}},
+				DependentRequired: map[string][]string{"postal_code": {"country"}},
+			}}
+			s = genschema.AttributeSchema(design.Design, att)
+		})
+
+		It("sets the oneOf, not and dependencies keywords", func() {
+			Ω(s.Dependencies).Should(Equal(map[string][]string{"postal_code": {"country"}}))
+			Ω(s.AllOf).Should(HaveLen(2))
+			Ω(s.AllOf[0].OneOf).Should(HaveLen(2))
+			Ω(s.AllOf[0].OneOf[0].Required).Should(Equal([]string{"email"}))
+			Ω(s.AllOf[1].Not.AnyOf).Should(HaveLen(1))
+			Ω(s.AllOf[1].Not.AnyOf[0].Required).Should(Equal([]string{"fax", "phone"}))
+		})
+	})
+
+	Context("with a nullable attribute", func() {
+		BeforeEach(func() {
+			typ = design.Object{
+				"nickname": &design.AttributeDefinition{Type: design.String, Nullable: true},
+			}
+		})
+
+		JustBeforeEach(func() {
+			s = genschema.AttributeSchema(design.Design, &design.AttributeDefinition{Type: typ})
+		})
+
+		It("adds null to the attribute types", func() {
+			Ω(s.Properties["nickname"].Nullable).Should(BeTrue())
+			b, err := json.Marshal(s.Properties["nickname"])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"type":["string","null"]`))
+		})
+	})
+
+	Context("with a union", func() {
+		BeforeEach(func() {
+			typ = &design.Union{
+				Variants: design.Object{
+					"card":   &design.AttributeDefinition{Type: design.Object{"number": &design.AttributeDefinition{Type: design.String}}},
+					"wallet": &design.AttributeDefinition{Type: design.Object{"provider": &design.AttributeDefinition{Type: design.String}}},
+				},
+				Discriminator: "kind",
+			}
+		})
+
+		It("returns a oneOf JSON schema", func() {
+			Ω(s).ShouldNot(BeNil())
+			Ω(s.OneOf).Should(HaveLen(2))
+			card := s.OneOf[0]
+			Ω(card.AllOf).Should(HaveLen(2))
+			Ω(card.AllOf[0].Properties).Should(HaveKey("number"))
+			Ω(card.AllOf[1].Required).Should(Equal([]string{"kind"}))
+			Ω(card.AllOf[1].Properties["kind"].Enum).Should(Equal([]interface{}{"card"}))
+		})
+
+		Context("with no discriminator", func() {
+			BeforeEach(func() {
+				typ.(*design.Union).Discriminator = ""
+			})
+
+			It("uses the variant schemas", func() {
+				Ω(s.OneOf).Should(HaveLen(2))
+				Ω(s.OneOf[1].AllOf).Should(BeEmpty())
+				Ω(s.OneOf[1].Properties).Should(HaveKey("provider"))
+			})
+		})
+	})
 })
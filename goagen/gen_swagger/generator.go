@@ -12,6 +12,7 @@ import (
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_schema"
 	"github.com/goadesign/goa/goagen/utils"
 )
 
@@ -75,11 +76,6 @@ func (g *Generator) Generate() (_ []string, err error) {
 		}
 	}()
 
-	s, err := New(g.API)
-	if err != nil {
-		return nil, err
-	}
-
 	swaggerDir := filepath.Join(g.OutDir, "swagger")
 	os.RemoveAll(swaggerDir)
 	if err = os.MkdirAll(swaggerDir, 0755); err != nil {
@@ -87,34 +83,73 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 	g.genfiles = append(g.genfiles, swaggerDir)
 
+	if !g.API.IsVersioned() {
+		if err = g.generateSpec(g.API, swaggerDir); err != nil {
+			return nil, err
+		}
+		return g.genfiles, nil
+	}
+
+	// The top level spec describes the latest version, each version is also described in the
+	// directory named after it.
+	for _, v := range g.API.Versions {
+		err = g.API.WithVersion(v.Name, func(api *design.APIDefinition) error {
+			if v.Name == g.API.Version {
+				if err := g.generateSpec(api, swaggerDir); err != nil {
+					return err
+				}
+			}
+			dir := filepath.Join(swaggerDir, v.Name)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			g.genfiles = append(g.genfiles, dir)
+			return g.generateSpec(api, dir)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return g.genfiles, nil
+}
+
+// generateSpec writes the JSON and YAML Swagger specs of the given API definition to dir.
+func (g *Generator) generateSpec(api *design.APIDefinition, dir string) error {
+	genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	s, err := New(api)
+	if err != nil {
+		return err
+	}
+
 	// JSON
 	rawJSON, err := json.Marshal(s)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	swaggerFile := filepath.Join(swaggerDir, "swagger.json")
+	swaggerFile := filepath.Join(dir, "swagger.json")
 	if err := ioutil.WriteFile(swaggerFile, rawJSON, 0644); err != nil {
-		return nil, err
+		return err
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
 
 	// YAML
 	var yamlSource interface{}
 	if err = json.Unmarshal(rawJSON, &yamlSource); err != nil {
-		return nil, err
+		return err
 	}
 
 	rawYAML, err := yaml.Marshal(yamlSource)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	swaggerFile = filepath.Join(swaggerDir, "swagger.yaml")
+	swaggerFile = filepath.Join(dir, "swagger.yaml")
 	if err := ioutil.WriteFile(swaggerFile, rawYAML, 0644); err != nil {
-		return nil, err
+		return err
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
 
-	return g.genfiles, nil
+	return nil
 }
 
 // Cleanup removes all the files generated by this generator during the last invokation of Generate.
@@ -9,6 +9,7 @@ import (
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_schema"
 )
 
@@ -458,12 +459,104 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 			// sad but swagger doesn't support these
 			d.Media = nil
 			d.Links = nil
+			adaptSchema(d)
+			if ut, ok := api.Types[n]; ok && ut.IsUnion() {
+				for vn, vd := range variantSchemas(ut, d) {
+					if _, ok := genschema.Definitions[vn]; !ok {
+						s.Definitions[vn] = vd
+					}
+				}
+				d = unionSchema(ut.ToUnion(), d)
+			}
 			s.Definitions[n] = d
 		}
 	}
 	return s, nil
 }
 
+// adaptSchema removes the schemas describing the OneOfRequired, MutuallyExclusive and
+// DependentRequired validations from the given schema and its properties, Swagger does not support
+// "oneOf", "not" and "dependencies". It also describes nullable schemas with the "x-nullable"
+// extension as Swagger does not support the "null" type.
+func adaptSchema(s *genschema.JSONSchema) {
+	if s == nil {
+		return
+	}
+	s.XNullable, s.Nullable = s.Nullable, false
+	s.Dependencies = nil
+	var allOf []*genschema.JSONSchema
+	for _, sub := range s.AllOf {
+		if sub.Not == nil && len(sub.OneOf) == 0 {
+			allOf = append(allOf, sub)
+		}
+	}
+	s.AllOf = allOf
+	for _, p := range s.Properties {
+		adaptSchema(p)
+	}
+	adaptSchema(s.Items)
+}
+
+// unionSchema returns the Swagger definition of a union type given its JSON schema. Swagger does
+// not support "oneOf", the definition of a discriminated union describes an object whose
+// discriminator attribute is set to one of the variant names, the variants are described by the
+// definitions returned by variantSchemas. The definition of a union with no discriminator cannot
+// describe the variants in standard Swagger, it is an object that lists the variants in the
+// "x-oneOf" extension.
+func unionSchema(u *design.Union, s *genschema.JSONSchema) *genschema.JSONSchema {
+	res := genschema.NewJSONSchema()
+	res.Title = s.Title
+	res.Description = s.Description
+	res.Type = genschema.JSONObject
+	res.Example = s.Example
+	if u.Discriminator == "" {
+		for _, v := range s.OneOf {
+			v = v.Dup()
+			adaptSchema(v)
+			res.XOneOf = append(res.XOneOf, v)
+		}
+		return res
+	}
+	prop := genschema.NewJSONSchema()
+	prop.Type = genschema.JSONString
+	for _, n := range u.VariantNames() {
+		prop.Enum = append(prop.Enum, n)
+	}
+	res.Discriminator = u.Discriminator
+	res.Properties[u.Discriminator] = prop
+	res.Required = []string{u.Discriminator}
+	return res
+}
+
+// variantSchemas returns the Swagger definitions of the variants of a discriminated union type
+// indexed by definition name given the JSON schema of the union type. Each definition extends the
+// union definition with the variant attributes. Swagger uses the definition name as discriminator
+// value by default, the definitions set the "x-discriminator-value" extension to the variant name
+// since the definition names are prefixed with the union type name to avoid conflicts.
+func variantSchemas(ut *design.UserTypeDefinition, s *genschema.JSONSchema) map[string]*genschema.JSONSchema {
+	u := ut.ToUnion()
+	if u.Discriminator == "" {
+		return nil
+	}
+	res := make(map[string]*genschema.JSONSchema)
+	for i, n := range u.VariantNames() {
+		if i >= len(s.OneOf) || len(s.OneOf[i].AllOf) == 0 {
+			continue
+		}
+		base := genschema.NewJSONSchema()
+		base.Ref = "#/definitions/" + ut.TypeName
+		// The variant schema may be shared with other definitions.
+		v := s.OneOf[i].AllOf[0].Dup()
+		adaptSchema(v)
+		v.Description = fmt.Sprintf("%q variant of %s.", n, ut.TypeName)
+		vs := genschema.NewJSONSchema()
+		vs.AllOf = []*genschema.JSONSchema{base, v}
+		vs.XDiscriminatorValue = n
+		res[ut.TypeName+codegen.Goify(n, true)] = vs
+	}
+	return res
+}
+
 // mustGenerate returns true if the metadata indicates that a Swagger specification should be
 // generated, false otherwise.
 func mustGenerate(meta dslengine.MetadataDefinition) bool {
@@ -516,6 +609,10 @@ func securityDefsFromDefinition(schemes []*design.SecuritySchemeDefinition) map[
 
 	defs := make(map[string]*SecurityDefinition)
 	for _, scheme := range schemes {
+		if scheme.Kind == design.MTLSSecurityKind {
+			// Swagger 2.0 has no mutual TLS security scheme, see applySecurity.
+			continue
+		}
 		def := &SecurityDefinition{
 			Type:             scheme.Type,
 			Description:      scheme.Description,
@@ -537,8 +634,15 @@ func securityDefsFromDefinition(schemes []*design.SecuritySchemeDefinition) map[
 				def.Scopes = nil
 			}
 		}
+		if scheme.Kind == design.HTTPSigSecurityKind {
+			def.Description += "\n\n**HTTP message signature**: the Signature and Signature-Input headers " +
+				"sign the request method, path, query string and Content-Digest header."
+		}
 		defs[scheme.SchemeName] = def
 	}
+	if len(defs) == 0 {
+		return nil
+	}
 	return defs
 }
 
@@ -718,7 +822,7 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 		Default:     toStringMap(at.DefaultValue),
 		Description: at.Description,
 		Required:    required,
-		Type:        at.Type.Name(),
+		Type:        typeName(at),
 	}
 	if at.Type.IsArray() {
 		p.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
@@ -766,7 +870,7 @@ func toString(val interface{}) string {
 }
 
 func itemsFromDefinition(at *design.AttributeDefinition) *Items {
-	items := &Items{Type: at.Type.Name()}
+	items := &Items{Type: typeName(at)}
 	initValidations(at, items)
 	if at.Type.IsArray() {
 		items.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
@@ -835,7 +939,7 @@ func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Hea
 		header := &Header{
 			Default:     at.DefaultValue,
 			Description: at.Description,
-			Type:        at.Type.Name(),
+			Type:        typeName(at),
 		}
 		initValidations(at, header)
 		res[n] = header
@@ -926,6 +1030,9 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		if err != nil {
 			return err
 		}
+		if p := action.Pagination; p != nil && p.Envelope && r.Name == design.OK && resp.Schema != nil {
+			resp.Schema = genschema.PageEnvelopeSchema(p, resp.Schema)
+		}
 		responses[strconv.Itoa(r.Status)] = resp
 	}
 
@@ -975,10 +1082,13 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		Parameters:   params,
 		Responses:    responses,
 		Schemes:      schemes,
-		Deprecated:   false,
+		Deprecated:   action.Deprecation() != nil,
 		Extensions:   extensionsFromDefinition(route.Metadata),
 	}
 
+	if action.Patch != nil {
+		operation.Consumes = []string{action.Patch.Format}
+	}
 	computeProduces(operation, s, action)
 	applySecurity(operation, action.Security)
 
@@ -1065,22 +1175,70 @@ func computePaths(operation *Operation, s *Swagger, route *design.RouteDefinitio
 }
 
 func applySecurity(operation *Operation, security *design.SecurityDefinition) {
-	if security != nil && security.Scheme.Kind != design.NoSecurityKind {
-		if security.Scheme.Kind == design.JWTSecurityKind && len(security.Scopes) > 0 {
-			if operation.Description != "" {
-				operation.Description += "\n\n"
+	if security == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return
+	}
+	reqs := security.Requirements()
+	documented := make(map[string]bool)
+	var sec []map[string][]string
+	for _, req := range reqs {
+		schemes := req.AllSchemes()
+		entry := make(map[string][]string)
+		for _, scheme := range schemes {
+			if scheme.Kind == design.MTLSSecurityKind {
+				if !documented[scheme.SchemeName] {
+					applyMTLSSecurity(operation, scheme, len(reqs) == 1)
+					documented[scheme.SchemeName] = true
+				}
+				continue
+			}
+			if scheme.Kind == design.JWTSecurityKind && len(req.Scopes) > 0 {
+				if operation.Description != "" {
+					operation.Description += "\n\n"
+				}
+				operation.Description += fmt.Sprintf("Required security scopes:\n%s", scopesList(req.Scopes))
+			}
+			scopes := make([]string, 0)
+			// Only OAuth2 (and JWT) schemes use scopes when combined with other schemes.
+			if req.Scopes != nil && (len(schemes) == 1 || scheme.Kind == design.OAuth2SecurityKind || scheme.Kind == design.JWTSecurityKind) {
+				scopes = req.Scopes
 			}
-			operation.Description += fmt.Sprintf("Required security scopes:\n%s", scopesList(security.Scopes))
+			entry[scheme.SchemeName] = scopes
 		}
-		scopes := security.Scopes
-		if scopes == nil {
-			scopes = make([]string, 0)
+		// An alternative that only requires client certificates needs no credentials at the
+		// HTTP level. It is left out since an empty requirement means that the operation
+		// accepts anonymous requests in Swagger 2.0, the x-mutual-tls extension and the
+		// description document it instead.
+		if len(entry) > 0 {
+			sec = append(sec, entry)
 		}
-		sec := []map[string][]string{{security.Scheme.SchemeName: scopes}}
+	}
+	if len(sec) > 0 {
 		operation.Security = sec
 	}
 }
 
+// applyMTLSSecurity documents the use of the mutual TLS scheme in the operation description and
+// using an extension since Swagger 2.0 cannot express it. required indicates whether the client
+// certificate is required or is one of several alternatives.
+func applyMTLSSecurity(operation *Operation, scheme *design.SecuritySchemeDefinition, required bool) {
+	if operation.Description != "" {
+		operation.Description += "\n\n"
+	}
+	verb := "Requires"
+	if !required {
+		verb = "Accepts"
+	}
+	operation.Description += fmt.Sprintf("%s a client certificate (mutual TLS security scheme `%s`).", verb, scheme.SchemeName)
+	if scheme.Description != "" {
+		operation.Description += " " + scheme.Description
+	}
+	if operation.Extensions == nil {
+		operation.Extensions = make(map[string]interface{})
+	}
+	operation.Extensions["x-mutual-tls"] = scheme.SchemeName
+}
+
 func scopesList(scopes []string) string {
 	sort.Strings(scopes)
 
@@ -1101,6 +1259,15 @@ func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
 	}
 }
 
+// typeName returns the Swagger type of the given primitive or array attribute. Decimal values
+// are strings.
+func typeName(at *design.AttributeDefinition) string {
+	if at.NumericFormat() == "decimal" {
+		return string(genschema.JSONString)
+	}
+	return at.Type.Name()
+}
+
 func initEnumValidation(def interface{}, values []interface{}) {
 	switch actual := def.(type) {
 	case *Parameter:
@@ -1134,31 +1301,53 @@ func initPatternValidation(def interface{}, pattern string) {
 	}
 }
 
-func initMinimumValidation(def interface{}, min *float64) {
+func initMinimumValidation(def interface{}, min *float64, exclusive bool) {
 	switch actual := def.(type) {
 	case *Parameter:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	case *Header:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	case *Items:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	}
 }
 
-func initMaximumValidation(def interface{}, max *float64) {
+func initMaximumValidation(def interface{}, max *float64, exclusive bool) {
 	switch actual := def.(type) {
 	case *Parameter:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
 	case *Header:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
 	case *Items:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
+	}
+}
+
+func initMultipleOfValidation(def interface{}, multiple float64) {
+	switch actual := def.(type) {
+	case *Parameter:
+		actual.MultipleOf = multiple
+	case *Header:
+		actual.MultipleOf = multiple
+	case *Items:
+		actual.MultipleOf = multiple
+	}
+}
+
+func initUniqueItemsValidation(def interface{}) {
+	switch actual := def.(type) {
+	case *Parameter:
+		actual.UniqueItems = true
+	case *Header:
+		actual.UniqueItems = true
+	case *Items:
+		actual.UniqueItems = true
 	}
 }
 
@@ -1198,13 +1387,16 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 		return
 	}
 	initEnumValidation(def, val.Values)
-	initFormatValidation(def, val.Format)
+	initFormatValidation(def, genschema.JSONFormat(val.Format))
 	initPatternValidation(def, val.Pattern)
 	if val.Minimum != nil {
-		initMinimumValidation(def, val.Minimum)
+		initMinimumValidation(def, val.Minimum, val.ExclusiveMinimum)
 	}
 	if val.Maximum != nil {
-		initMaximumValidation(def, val.Maximum)
+		initMaximumValidation(def, val.Maximum, val.ExclusiveMaximum)
+	}
+	if val.MultipleOf != nil {
+		initMultipleOfValidation(def, *val.MultipleOf)
 	}
 	if val.MinLength != nil {
 		initMinLengthValidation(def, attr.Type.IsArray(), val.MinLength)
@@ -1212,4 +1404,7 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 	if val.MaxLength != nil {
 		initMaxLengthValidation(def, attr.Type.IsArray(), val.MaxLength)
 	}
+	if val.UniqueItems {
+		initUniqueItemsValidation(def)
+	}
 }
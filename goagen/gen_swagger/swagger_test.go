@@ -212,6 +212,59 @@ var _ = Describe("New", func() {
 			})
 
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+
+			Context("with numeric formats", func() {
+				BeforeEach(func() {
+					base := Design.DSLFunc
+					Design.DSLFunc = func() {
+						base()
+						Params(func() {
+							Param(intParam, Integer, func() {
+								Format("int32")
+							})
+							Param(numParam, Number, func() {
+								Format("decimal")
+							})
+						})
+					}
+				})
+
+				It("sets the parameter types and formats", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					Ω(swagger.Parameters[intParam].Type).Should(Equal("integer"))
+					Ω(swagger.Parameters[intParam].Format).Should(Equal("int32"))
+					Ω(swagger.Parameters[numParam].Type).Should(Equal("string"))
+					Ω(swagger.Parameters[numParam].Format).Should(Equal("decimal"))
+				})
+			})
+
+			Context("with extended validations", func() {
+				BeforeEach(func() {
+					base := Design.DSLFunc
+					Design.DSLFunc = func() {
+						base()
+						Params(func() {
+							Param(intParam, Integer, func() {
+								ExclusiveMinimum(0)
+								MultipleOf(10)
+							})
+							Param(queryParam, ArrayOf(String), func() {
+								UniqueItems()
+							})
+						})
+					}
+				})
+
+				It("sets the parameter validations", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					Ω(*swagger.Parameters[intParam].Minimum).Should(Equal(0.0))
+					Ω(swagger.Parameters[intParam].ExclusiveMinimum).Should(BeTrue())
+					Ω(swagger.Parameters[intParam].MultipleOf).Should(Equal(10.0))
+					Ω(swagger.Parameters[queryParam].UniqueItems).Should(BeTrue())
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
 		})
 
 		Context("with required payload", func() {
@@ -258,6 +311,259 @@ var _ = Describe("New", func() {
 
 		})
 
+		Context("with a union payload", func() {
+			BeforeEach(func() {
+				p := Type("PaymentMethod", func() {
+					OneOf(func() {
+						Attribute("card", func() {
+							Attribute("number", String)
+						})
+						Attribute("wallet", func() {
+							Attribute("provider", String)
+						})
+					}, Discriminator("kind"))
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PUT("/"),
+						)
+						Payload(p)
+					})
+				})
+			})
+
+			It("serializes into valid swagger JSON", func() {
+				validateSwaggerWithFragments(swagger, [][]byte{
+					[]byte(`"required":["kind"],"discriminator":"kind"`),
+					[]byte(`"PaymentMethodCard":{"allOf":[{"$ref":"#/definitions/PaymentMethod"}`),
+					[]byte(`"x-discriminator-value":"card"`),
+					[]byte(`"x-discriminator-value":"wallet"`),
+				})
+			})
+
+			It("does not modify the JSON schema of the union", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				for _, v := range genschema.Definitions["PaymentMethod"].OneOf {
+					Ω(v.AllOf[0].Description).Should(BeEmpty())
+				}
+			})
+		})
+
+		Context("with a union payload with no discriminator", func() {
+			BeforeEach(func() {
+				p := Type("Contact", func() {
+					OneOf(func() {
+						Attribute("email", func() {
+							Attribute("address", String)
+						})
+						Attribute("phone", func() {
+							Attribute("number", Integer)
+						})
+					})
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PUT("/"),
+						)
+						Payload(p)
+					})
+				})
+			})
+
+			It("lists the variants in the x-oneOf extension", func() {
+				validateSwaggerWithFragments(swagger, [][]byte{
+					[]byte(`"x-oneOf":[{"type":"object","properties":{"address":{"type":"string"}}},{"type":"object","properties":{"number":{"type":"integer","format":"int64"}}}]`),
+				})
+			})
+		})
+
+		Context("with a cross-field validated payload", func() {
+			BeforeEach(func() {
+				p := Type("ContactPayload", func() {
+					Attribute("email", String)
+					Attribute("phone", String)
+					Attribute("country", String)
+					Attribute("postal_code", String)
+					OneOfRequired("email", "phone")
+					DependentRequired("postal_code", "country")
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PUT("/"),
+						)
+						Payload(p)
+					})
+				})
+			})
+
+			It("drops the keywords Swagger does not support", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				def := swagger.Definitions["ContactPayload"]
+				Ω(def).ShouldNot(BeNil())
+				Ω(def.AllOf).Should(BeEmpty())
+				Ω(def.Dependencies).Should(BeEmpty())
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a nullable payload attribute", func() {
+			BeforeEach(func() {
+				p := Type("PatchPayload", func() {
+					Attribute("nickname", String, func() {
+						Nullable()
+					})
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PATCH("/"),
+						)
+						Payload(p)
+					})
+				})
+			})
+
+			It("uses the x-nullable extension", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				def := swagger.Definitions["PatchPayload"]
+				Ω(def).ShouldNot(BeNil())
+				Ω(def.Properties["nickname"].XNullable).Should(BeTrue())
+				Ω(def.Properties["nickname"].Nullable).Should(BeFalse())
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a merge patch action", func() {
+			BeforeEach(func() {
+				p := Type("Profile", func() {
+					Attribute("nickname", String)
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							PATCH("/"),
+						)
+						MergePatch(p)
+					})
+				})
+			})
+
+			It("consumes the merge patch content type", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Patch
+				Ω(op).ShouldNot(BeNil())
+				Ω(op.Consumes).Should(Equal([]string{"application/merge-patch+json"}))
+				Ω(op.Parameters).Should(HaveLen(1))
+				Ω(op.Parameters[0].In).Should(Equal("body"))
+				Ω(op.Parameters[0].Required).Should(BeTrue())
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a filterable and sortable collection", func() {
+			BeforeEach(func() {
+				bottle := MediaType("application/vnd.goa.bottle", func() {
+					Attributes(func() {
+						Attribute("name", String)
+					})
+					Filterable("name", Prefix)
+					Sortable("name")
+					View("default", func() {
+						Attribute("name")
+					})
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						Response(OK, CollectionOf(bottle))
+					})
+				})
+			})
+
+			It("documents the filter and sort params", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Get
+				Ω(op).ShouldNot(BeNil())
+				Ω(op.Parameters).Should(HaveLen(2))
+				Ω(op.Parameters[0].Name).Should(Equal("filter[name][prefix]"))
+				Ω(op.Parameters[0].In).Should(Equal("query"))
+				Ω(op.Parameters[1].Name).Should(Equal("sort"))
+				Ω(op.Parameters[1].Pattern).Should(Equal("^-?(name)(,-?(name))*$"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a cursor paginated action", func() {
+			BeforeEach(func() {
+				bottle := MediaType("application/vnd.goa.bottle", func() {
+					Attributes(func() {
+						Attribute("name", String)
+					})
+					View("default", func() {
+						Attribute("name")
+					})
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						Paginated(Cursor, func() {
+							MaxPageSize(50)
+						})
+						Response(OK, CollectionOf(bottle))
+					})
+				})
+			})
+
+			It("documents the pagination params and Link header", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Get
+				Ω(op).ShouldNot(BeNil())
+				Ω(op.Parameters).Should(HaveLen(2))
+				Ω(op.Parameters[0].Name).Should(Equal("cursor"))
+				Ω(op.Parameters[1].Name).Should(Equal("limit"))
+				Ω(*op.Parameters[1].Maximum).Should(Equal(float64(50)))
+				Ω(op.Responses["200"].Headers).Should(HaveKey("Link"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with a deprecated action", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(
+							GET("/"),
+						)
+						Deprecated(func() {
+							Sunset("2027-06-30")
+						})
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("flags the operation as deprecated", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/"].(*genswagger.Path).Get
+				Ω(op).ShouldNot(BeNil())
+				Ω(op.Deprecated).Should(BeTrue())
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with optional payload", func() {
 			BeforeEach(func() {
 				p := Type("OptionalPayload", func() {
@@ -684,5 +990,99 @@ var _ = Describe("New", func() {
 			})
 
 		})
+
+		Context("with mutual TLS security", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Description("Action")
+						Security("mtls")
+						Routing(GET("/"))
+						Response(NoContent)
+					})
+				})
+				base := Design.DSLFunc
+				Design.DSLFunc = func() {
+					base()
+					MTLSSecurity("mtls", func() {
+						Description("Client certificates.")
+					})
+				}
+			})
+
+			It("documents the scheme in the operation", func() {
+				Ω(swagger.SecurityDefinitions).Should(BeNil())
+				p := swagger.Paths["/"].(*genswagger.Path)
+				Ω(p.Get.Security).Should(BeNil())
+				Ω(p.Get.Description).Should(Equal("Action\n\nRequires a client certificate (mutual TLS security scheme `mtls`). Client certificates."))
+				Ω(p.Get.Extensions).Should(HaveKeyWithValue("x-mutual-tls", "mtls"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+
+			Context("as an alternative", func() {
+				BeforeEach(func() {
+					base := Design.DSLFunc
+					Design.DSLFunc = func() {
+						base()
+						APIKeySecurity("key", func() {
+							Header("X-Key")
+						})
+					}
+					Resource("alt", func() {
+						Action("act", func() {
+							Security("mtls")
+							Security("key")
+							Routing(GET("/alt"))
+							Response(NoContent)
+						})
+					})
+				})
+
+				It("documents the mutual TLS alternative without an anonymous requirement", func() {
+					p := swagger.Paths["/alt"].(*genswagger.Path)
+					Ω(p.Get.Security).Should(Equal([]map[string][]string{{"key": {}}}))
+					Ω(p.Get.Description).Should(ContainSubstring("Accepts a client certificate"))
+					Ω(p.Get.Extensions).Should(HaveKeyWithValue("x-mutual-tls", "mtls"))
+				})
+			})
+		})
+
+		Context("with composite security requirements", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Security("password", "oauth2", func() {
+							Scope("api:read")
+						})
+						Security("key")
+						Routing(GET("/"))
+						Response(NoContent)
+					})
+				})
+				base := Design.DSLFunc
+				Design.DSLFunc = func() {
+					base()
+					BasicAuthSecurity("password")
+					OAuth2Security("oauth2", func() {
+						ImplicitFlow("/auth")
+						Scope("api:read", "Read access")
+					})
+					APIKeySecurity("key", func() {
+						Header("X-Key")
+					})
+				}
+			})
+
+			It("sets the alternative security requirements", func() {
+				p := swagger.Paths["/"].(*genswagger.Path)
+				Ω(p.Get.Security).Should(Equal([]map[string][]string{
+					{"password": {}, "oauth2": {"api:read"}},
+					{"key": {}},
+				}))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
 	})
 })
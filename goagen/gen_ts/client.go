@@ -0,0 +1,269 @@
+package gents
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// tsAction describes a client method.
+	tsAction struct {
+		// Name is the name of the client method, e.g. "showBottle".
+		Name string
+		// Description is the JSDoc comment of the method.
+		Description string
+		// Method is the HTTP method.
+		Method string
+		// Path is the body of the template literal that computes the request path.
+		Path string
+		// Request is the name of the request interface.
+		Request string
+		// RequestOptional is true if all the request fields are optional.
+		RequestOptional bool
+		// Parts lists the request fields: path parameters, query parameters and headers.
+		Parts []*tsRequestPart
+		// Payload is the TypeScript type of the payload, empty if the action has none.
+		Payload string
+		// PayloadOptional is true if the payload may be omitted.
+		PayloadOptional bool
+		// Multipart is true if the payload is sent as multipart form data.
+		Multipart bool
+		// ContentType is the Content-Type of the payload if it is not sent as multipart form
+		// data.
+		ContentType string
+		// Response is the TypeScript type of the success response body.
+		Response string
+		// Security lists the alternative security requirements of the action, each
+		// requirement lists the names of the schemes that must all be satisfied.
+		Security [][]string
+	}
+
+	// tsRequestPart describes the path, query or headers field of a request interface.
+	tsRequestPart struct {
+		// Field is the name of the request field: "path", "query" or "headers".
+		Field string
+		// Name is the name of the interface describing the field.
+		Name string
+		// Description is the JSDoc comment of the interface.
+		Description string
+		// Optional is true if the field may be omitted.
+		Optional bool
+		// Attribute is the object attribute listing the parameters or headers.
+		Attribute *design.AttributeDefinition
+	}
+
+	// tsScheme describes a security scheme and the factory function that creates its Auth.
+	tsScheme struct {
+		// Name is the name of the scheme.
+		Name string
+		// Func is the name of the factory function, empty if the scheme has none.
+		Func string
+		// Kind is "basic", "header", "query", "bearer" or "" if there is no factory.
+		Kind string
+		// Key is the name of the header or query string parameter that holds the credentials.
+		Key string
+		// Token is true if the credentials are a token obtained from a TokenProvider.
+		Token bool
+		// Description is the JSDoc comment of the factory function.
+		Description string
+	}
+)
+
+// actions returns the client methods for the API actions sorted by name.
+func (ts *typeScript) actions(api *design.APIDefinition) ([]*tsAction, error) {
+	var actions []*tsAction
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || len(a.Routes) == 0 {
+				return nil
+			}
+			action, err := ts.action(api, a)
+			if err != nil {
+				return err
+			}
+			actions = append(actions, action)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Name < actions[j].Name })
+	return actions, nil
+}
+
+// action computes the client method for the given action, it uses the first action route.
+func (ts *typeScript) action(api *design.APIDefinition, a *design.ActionDefinition) (*tsAction, error) {
+	prefix := codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true)
+	route := a.Routes[0]
+	action := &tsAction{
+		Name:            codegen.Goify(a.Name, false) + codegen.Goify(a.Parent.Name, true),
+		Method:          route.Verb,
+		Path:            route.FullPath(),
+		Request:         prefix + "Request",
+		RequestOptional: true,
+		Response:        "void",
+	}
+	action.Description = fmt.Sprintf("%s calls the %s %s action: %s %s.", action.Name, a.Parent.Name, a.Name, route.Verb, route.FullPath())
+	if a.Description != "" {
+		action.Description += "\n" + a.Description
+	}
+
+	params := a.AllParams()
+	pathParams := &design.AttributeDefinition{Type: design.Object{}}
+	for _, p := range route.Params() {
+		att := params.Type.ToObject()[p]
+		if att == nil {
+			att = &design.AttributeDefinition{Type: design.String}
+		}
+		pathParams.Type.ToObject()[p] = att
+		pathParams.Validation = requireAttribute(pathParams.Validation, p)
+	}
+	queryParams := &design.AttributeDefinition{Type: design.Object{}}
+	if a.QueryParams != nil {
+		queryParams = a.QueryParams
+	}
+	headers := &design.AttributeDefinition{Type: design.Object{}}
+	if a.Headers != nil {
+		headers = a.Headers
+	}
+	for _, part := range []*tsRequestPart{
+		{Field: "path", Name: prefix + "Path", Attribute: pathParams},
+		{Field: "query", Name: prefix + "Query", Attribute: queryParams},
+		{Field: "headers", Name: prefix + "Headers", Attribute: headers},
+	} {
+		obj := part.Attribute.Type.ToObject()
+		if len(obj) == 0 {
+			continue
+		}
+		part.Optional = true
+		for n := range obj {
+			if part.Attribute.IsRequired(n) {
+				part.Optional = false
+				action.RequestOptional = false
+				break
+			}
+		}
+		part.Description = fmt.Sprintf("%s lists the %s of the %s %s action.", part.Name, partDescription[part.Field], a.Parent.Name, a.Name)
+		ts.collectEnums(part.Name, part.Attribute)
+		action.Parts = append(action.Parts, part)
+	}
+	action.Path = pathTemplate(action.Path)
+
+	if a.Payload != nil {
+		action.Payload = ts.typeRef(&design.AttributeDefinition{Type: a.Payload}, "models.")
+		action.PayloadOptional = a.PayloadOptional
+		action.Multipart = a.PayloadMultipart
+		action.ContentType = "application/json"
+		if a.Patch != nil {
+			action.ContentType = a.Patch.Format
+		}
+		if !a.PayloadOptional {
+			action.RequestOptional = false
+		}
+	}
+
+	responses := make([]*design.ResponseDefinition, 0, len(a.Responses))
+	for _, r := range a.Responses {
+		responses = append(responses, r)
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Status < responses[j].Status })
+	for _, r := range responses {
+		if r.Status < 200 || r.Status >= 300 || r.MediaType == "" {
+			continue
+		}
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil || mt.IsError() {
+			continue
+		}
+		view := r.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return nil, err
+		}
+		action.Response = ts.typeRef(&design.AttributeDefinition{Type: p}, "models.")
+		break
+	}
+
+	if a.Security != nil {
+		for _, req := range a.Security.Requirements() {
+			var names []string
+			for _, s := range req.AllSchemes() {
+				// Mutual TLS is configured on the connection, not on the request.
+				if s.Kind != design.MTLSSecurityKind {
+					names = append(names, s.SchemeName)
+				}
+			}
+			action.Security = append(action.Security, names)
+		}
+	}
+
+	return action, nil
+}
+
+// partDescription describes the content of the request fields.
+var partDescription = map[string]string{
+	"path":    "path parameters",
+	"query":   "query string parameters",
+	"headers": "request headers",
+}
+
+// schemes returns the security schemes of the API.
+func schemes(api *design.APIDefinition) []*tsScheme {
+	res := make([]*tsScheme, 0, len(api.SecuritySchemes))
+	for _, s := range api.SecuritySchemes {
+		scheme := &tsScheme{Name: s.SchemeName, Func: codegen.Goify(s.SchemeName, false) + "Auth"}
+		switch s.Kind {
+		case design.BasicAuthSecurityKind:
+			scheme.Kind = "basic"
+		case design.APIKeySecurityKind, design.JWTSecurityKind:
+			scheme.Kind, scheme.Key = s.In, s.Name
+			if scheme.Kind == "header" && strings.EqualFold(s.Name, "Authorization") {
+				scheme.Kind = "bearer"
+			}
+			scheme.Token = s.Kind == design.JWTSecurityKind
+		case design.OAuth2SecurityKind:
+			scheme.Kind, scheme.Key, scheme.Token = "bearer", "Authorization", true
+		default:
+			scheme.Func = ""
+		}
+		if scheme.Func != "" {
+			scheme.Description = fmt.Sprintf("%s returns the Auth for the %q %s security scheme.", scheme.Func, s.SchemeName, s.Type)
+			if s.Description != "" {
+				scheme.Description += "\n" + s.Description
+			}
+		}
+		res = append(res, scheme)
+	}
+	return res
+}
+
+// pathTemplate returns the body of the template literal that computes the given path, the path
+// parameters are read from the "req.path" object.
+func pathTemplate(path string) string {
+	path = strings.NewReplacer("`", "\\`", "$", "\\$").Replace(path)
+	return design.WildcardRegex.ReplaceAllStringFunc(path, func(wc string) string {
+		encode := "encodePath"
+		if wc[1] == '*' {
+			encode = "encodeWildcard"
+		}
+		return fmt.Sprintf("/${%s(req.path[%s])}", encode, literal(wc[2:]))
+	})
+}
+
+// requireAttribute adds name to the required attributes of the given validation.
+func requireAttribute(v *dslengine.ValidationDefinition, name string) *dslengine.ValidationDefinition {
+	if v == nil {
+		v = &dslengine.ValidationDefinition{}
+	}
+	v.Required = append(v.Required, name)
+	return v
+}
@@ -0,0 +1,20 @@
+/*
+Package gents provides a goa generator for a TypeScript client module.
+
+The generator produces three files under the "ts" directory:
+
+  - models.ts declares an interface for each user type and media type view and an enum for
+    each attribute with an Enum validation.
+  - client.ts declares a fetch based Client class with one method per action. Each method
+    accepts a typed request listing the path parameters, query string parameters, headers and
+    payload of the action.
+  - tsconfig.json configures the TypeScript compiler in strict mode.
+
+Requests are authorized with Auth implementations given to the client indexed by security
+scheme name. client.ts declares a factory function for each basic auth, API key, JWT and OAuth2
+security scheme, for example:
+
+    const client = new Client({ auth: { jwt: jwtAuth(() => session.token()) } });
+    const bottle = await client.showBottle({ path: { bottleID: 1 } });
+*/
+package gents
@@ -0,0 +1,13 @@
+package gents_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenTS(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenTS Suite")
+}
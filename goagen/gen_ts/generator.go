@@ -0,0 +1,425 @@
+package gents
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/version"
+)
+
+//NewGenerator returns an initialized instance of a TypeScript Client Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the TypeScript client generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Destination directory
+	Scheme   string                // Scheme used by the default client base URL
+	Host     string                // Host used by the default client base URL
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, ver  string
+		scheme, host string
+	)
+
+	set := flag.NewFlagSet("ts", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.String("design", "", "")
+	set.StringVar(&scheme, "scheme", "", "")
+	set.StringVar(&host, "host", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Scheme: scheme, Host: host, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the TypeScript models, client and compiler configuration.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Scheme == "" && len(g.API.Schemes) > 0 {
+		g.Scheme = g.API.Schemes[0]
+	}
+	if g.Scheme == "" {
+		g.Scheme = "http"
+	}
+	if g.Host == "" {
+		g.Host = g.API.Host
+	}
+
+	ts := newTypeScript()
+	models, err := ts.models(g.API)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := ts.actions(g.API)
+	if err != nil {
+		return nil, err
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "ts")
+	if err = os.RemoveAll(g.OutDir); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+
+	if err = g.generateModels(filepath.Join(g.OutDir, "models.ts"), ts, models); err != nil {
+		return nil, err
+	}
+	if err = g.generateClient(filepath.Join(g.OutDir, "client.ts"), ts, actions); err != nil {
+		return nil, err
+	}
+	if err = g.generateConfig(filepath.Join(g.OutDir, "tsconfig.json")); err != nil {
+		return nil, err
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+func (g *Generator) generateModels(modelsFile string, ts *typeScript, models []*tsModel) error {
+	file, err := codegen.SourceFileFor(modelsFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, modelsFile)
+
+	defs := make([]string, 0, len(ts.enums)+len(models))
+	for _, e := range ts.enums {
+		defs = append(defs, ts.enumDef(e))
+	}
+	for _, m := range models {
+		defs = append(defs, ts.modelDef(m))
+	}
+	data := map[string]interface{}{
+		"Title":       fmt.Sprintf("%s: TypeScript Models", g.API.Context()),
+		"ToolVersion": version.String(),
+		"Definitions": defs,
+	}
+	return file.ExecuteTemplate("models", modelsT, nil, data)
+}
+
+func (g *Generator) generateClient(clientFile string, ts *typeScript, actions []*tsAction) error {
+	file, err := codegen.SourceFileFor(clientFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, clientFile)
+
+	baseURL := ""
+	if g.Host != "" {
+		baseURL = fmt.Sprintf("%s://%s", g.Scheme, g.Host)
+	}
+	data := map[string]interface{}{
+		"Title":       fmt.Sprintf("%s: TypeScript Client", g.API.Context()),
+		"ToolVersion": version.String(),
+		"BaseURL":     baseURL,
+		"Schemes":     schemes(g.API),
+		"Actions":     actions,
+	}
+	funcs := template.FuncMap{
+		"doc":        docComment,
+		"literal":    literal,
+		"properties": ts.properties,
+		"join":       strings.Join,
+	}
+	return file.ExecuteTemplate("client", clientT, funcs, data)
+}
+
+func (g *Generator) generateConfig(configFile string) error {
+	file, err := codegen.SourceFileFor(configFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	g.genfiles = append(g.genfiles, configFile)
+
+	_, err = file.Write([]byte(configT))
+	return err
+}
+
+const modelsT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+//
+// Command:
+{{ comment commandLine }}
+{{ range .Definitions }}
+{{ . }}{{ else }}
+export {};
+{{ end }}`
+
+const clientT = `// Code generated by goagen {{ .ToolVersion }}, DO NOT EDIT.
+//
+// {{ .Title }}
+//
+// Command:
+{{ comment commandLine }}
+
+import * as models from "./models";
+
+/** RequestContext describes the request being built, it is given to the Auth implementations. */
+export interface RequestContext {
+  method: string;
+  url: URL;
+  headers: Headers;
+  body?: string | FormData;
+}
+
+/** Auth applies the credentials of a security scheme to a request. */
+export interface Auth {
+  apply(req: RequestContext): void | Promise<void>;
+}
+
+/** TokenProvider is a token or a function called for each request that returns a token. */
+export type TokenProvider = string | (() => string | Promise<string>);
+
+/** SecurityScheme lists the names of the API security schemes. */
+export type SecurityScheme = {{ if .Schemes }}{{ range $i, $s := .Schemes }}{{ if $i }} | {{ end }}{{ literal $s.Name }}{{ end }}{{ else }}never{{ end }};
+{{ range .Schemes }}{{ if .Func }}
+{{ doc .Description "" }}{{ if eq .Kind "basic" }}export function {{ .Func }}(username: string, password: string): Auth {
+  return {
+    apply(req: RequestContext): void {
+      req.headers.set("Authorization", "Basic " + btoa(username + ":" + password));
+    },
+  };
+}
+{{ else if .Token }}export function {{ .Func }}(token: TokenProvider): Auth {
+  return {
+    async apply(req: RequestContext): Promise<void> {
+      const value = typeof token === "string" ? token : await token();
+{{ if eq .Kind "bearer" }}      req.headers.set({{ literal .Key }}, "Bearer " + value);
+{{ else if eq .Kind "query" }}      req.url.searchParams.set({{ literal .Key }}, value);
+{{ else }}      req.headers.set({{ literal .Key }}, value);
+{{ end }}    },
+  };
+}
+{{ else }}export function {{ .Func }}(key: string): Auth {
+  return {
+    apply(req: RequestContext): void {
+{{ if eq .Kind "query" }}      req.url.searchParams.set({{ literal .Key }}, key);
+{{ else }}      req.headers.set({{ literal .Key }}, key);
+{{ end }}    },
+  };
+}
+{{ end }}{{ end }}{{ end }}
+/** ClientOptions configures the client. */
+export interface ClientOptions {
+  /** baseURL is the scheme and host of the API{{ if .BaseURL }}, defaults to {{ literal .BaseURL }}{{ end }}. */
+  baseURL?: string;
+  /** fetch is the function used to send requests, defaults to the global fetch. */
+  fetch?: typeof fetch;
+  /** auth maps the names of the security schemes to the credentials used to authorize requests. */
+  auth?: Partial<Record<SecurityScheme, Auth>>;
+  /** headers are added to all requests. */
+  headers?: Record<string, string>;
+}
+
+/** ClientError is thrown when the API responds with a status code that is not 2xx. */
+export class ClientError extends Error {
+  /** status is the response status code. */
+  readonly status: number;
+  /** body is the decoded response body. */
+  readonly body: unknown;
+
+  constructor(status: number, body: unknown) {
+    super("unexpected response status " + status);
+    this.name = "ClientError";
+    this.status = status;
+    this.body = body;
+  }
+}
+{{ range $a := .Actions }}{{ range .Parts }}
+{{ doc .Description "" }}export interface {{ .Name }} {
+{{ properties .Attribute "models." "  " }}}
+{{ end }}
+/** {{ .Request }} is the request of the {{ .Name }} method. */
+export interface {{ .Request }} {
+{{ range .Parts }}  {{ .Field }}{{ if .Optional }}?{{ end }}: {{ .Name }};
+{{ end }}{{ if .Payload }}  payload{{ if .PayloadOptional }}?{{ end }}: {{ .Payload }};
+{{ end }}}
+{{ end }}
+/** Client calls the API actions, it has one method per action. */
+export class Client {
+  private readonly baseURL: string;
+  private readonly fetchFn: typeof fetch;
+  private readonly auth: Partial<Record<SecurityScheme, Auth>>;
+  private readonly headers: Record<string, string>;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseURL = options.baseURL ?? {{ literal .BaseURL }};
+    this.fetchFn = options.fetch ?? ((input: RequestInfo | URL, init?: RequestInit) => fetch(input, init));
+    this.auth = options.auth ?? {};
+    this.headers = options.headers ?? {};
+  }
+{{ range .Actions }}
+{{ doc .Description "  " }}  async {{ .Name }}(req: {{ .Request }}{{ if .RequestOptional }} = {}{{ end }}): Promise<{{ .Response }}> {
+    const ctx = this.request({{ literal .Method }}, ` + "`{{ .Path }}`" + `);
+{{ range .Parts }}{{ if eq .Field "query" }}    appendQuery(ctx.url, req.query);
+{{ else if eq .Field "headers" }}    setHeaders(ctx.headers, req.headers);
+{{ end }}{{ end }}{{ if .Payload }}    if (req.payload !== undefined) {
+{{ if .Multipart }}      ctx.body = formData(req.payload);
+{{ else }}      ctx.headers.set("Content-Type", {{ literal .ContentType }});
+      ctx.body = JSON.stringify(req.payload);
+{{ end }}    }
+{{ end }}{{ if .Security }}    await this.authorize(ctx, [{{ range $i, $r := .Security }}{{ if $i }}, {{ end }}[{{ range $j, $s := $r }}{{ if $j }}, {{ end }}{{ literal $s }}{{ end }}]{{ end }}]);
+{{ end }}    return this.send<{{ .Response }}>(ctx);
+  }
+{{ end }}
+  private request(method: string, path: string): RequestContext {
+    const base = typeof location === "undefined" ? undefined : location.href;
+    return { method, url: new URL(this.baseURL + path, base), headers: new Headers(this.headers) };
+  }
+
+  private async authorize(req: RequestContext, requirements: SecurityScheme[][]): Promise<void> {
+    for (const schemes of requirements) {
+      const auths: Auth[] = [];
+      for (const scheme of schemes) {
+        const auth = this.auth[scheme];
+        if (auth !== undefined) {
+          auths.push(auth);
+        }
+      }
+      if (auths.length === schemes.length) {
+        for (const auth of auths) {
+          await auth.apply(req);
+        }
+        return;
+      }
+    }
+  }
+
+  private async send<T>(req: RequestContext): Promise<T> {
+    const resp = await this.fetchFn(req.url.toString(), { method: req.method, headers: req.headers, body: req.body });
+    const text = await resp.text();
+    let body: unknown = undefined;
+    if (text !== "") {
+      try {
+        body = JSON.parse(text);
+      } catch {
+        body = text;
+      }
+    }
+    if (!resp.ok) {
+      throw new ClientError(resp.status, body);
+    }
+    return body as T;
+  }
+}
+
+function encodePath(value: unknown): string {
+  return encodeURIComponent(String(value));
+}
+
+function encodeWildcard(value: unknown): string {
+  return encodeURI(String(value));
+}
+
+function appendQuery(url: URL, query: object | undefined): void {
+  if (query === undefined) {
+    return;
+  }
+  for (const [name, value] of Object.entries(query)) {
+    if (value === undefined || value === null) {
+      continue;
+    }
+    if (Array.isArray(value)) {
+      for (const v of value) {
+        url.searchParams.append(name, String(v));
+      }
+      continue;
+    }
+    url.searchParams.append(name, String(value));
+  }
+}
+
+function setHeaders(headers: Headers, values: object | undefined): void {
+  if (values === undefined) {
+    return;
+  }
+  for (const [name, value] of Object.entries(values)) {
+    if (value === undefined || value === null) {
+      continue;
+    }
+    headers.set(name, Array.isArray(value) ? value.map(String).join(", ") : String(value));
+  }
+}
+
+function formData(payload: object): FormData {
+  const form = new FormData();
+  for (const [name, value] of Object.entries(payload)) {
+    if (value === undefined || value === null) {
+      continue;
+    }
+    if (value instanceof Blob) {
+      form.append(name, value);
+    } else if (typeof value === "object") {
+      form.append(name, JSON.stringify(value));
+    } else {
+      form.append(name, String(value));
+    }
+  }
+  return form;
+}
+`
+
+const configT = `{
+  "compilerOptions": {
+    "target": "ES2020",
+    "lib": ["ES2020", "DOM"],
+    "module": "ES2020",
+    "moduleResolution": "node",
+    "strict": true,
+    "declaration": true,
+    "outDir": "dist"
+  },
+  "files": ["models.ts", "client.ts"]
+}
+`
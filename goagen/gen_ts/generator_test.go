@@ -0,0 +1,304 @@
+package gents_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_ts"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gents.Generator
+
+	var args = struct {
+		api    *APIDefinition
+		outDir string
+		scheme string
+		host   string
+	}{
+		api: &APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+		scheme: "https",
+		host:   "cellar.goa.design",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+
+			generator = gents.NewGenerator(
+				gents.API(args.api),
+				gents.OutDir(args.outDir),
+				gents.Scheme(args.scheme),
+				gents.Host(args.host),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Scheme).Should(Equal(args.scheme))
+			Ω(generator.Host).Should(Equal(args.host))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		dslengine.Reset()
+		API("cellar", func() {
+			Host("cellar.goa.design")
+			Scheme("https")
+			JWTSecurity("jwt", func() {
+				Header("Authorization")
+			})
+			APIKeySecurity("key", func() {
+				Query("key")
+			})
+		})
+		Type("Origin", func() {
+			Description("Origin of the wine")
+			Attribute("country", String, "Country of origin", func() {
+				Enum("france", "italy")
+			})
+			Attribute("vintage", Integer)
+			Attribute("tags", HashOf(String, ArrayOf(String)))
+			Attribute("price", Number, func() {
+				Format("decimal")
+			})
+			Attribute("stock", Integer, func() {
+				Format("int64")
+			})
+			Required("country")
+		})
+		Type("Method", func() {
+			OneOf(func() {
+				Attribute("card", func() {
+					Attribute("number", String)
+				})
+				Attribute("wallet", func() {
+					Attribute("provider", String)
+				})
+			}, Discriminator("kind"))
+		})
+		var WineStatus = Type("WineStatus", String, func() {
+			Description("Status of the wine")
+			Enum("open", "in-cellar")
+			Metadata("enum:names", "Opened", "Cellared")
+		})
+		var Bottle = MediaType("application/vnd.goa.example.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("name", String)
+				Attribute("origin", "Origin")
+				Required("id", "name")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+				Attribute("origin")
+			})
+			View("tiny", func() {
+				Attribute("id")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Security("jwt")
+			Action("show", func() {
+				Routing(GET("/:bottleID"))
+				Params(func() {
+					Param("bottleID", Integer)
+					Param("sort", String, func() {
+						Enum("asc", "desc")
+					})
+				})
+				Headers(func() {
+					Header("X-Request-Id")
+				})
+				Response(OK, Bottle)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Security("key")
+				Payload("Origin")
+				Response(Created)
+			})
+			Action("rate", func() {
+				Routing(PUT("/:bottleID/status"))
+				Params(func() {
+					Param("bottleID", Integer)
+					Param("status", WineStatus)
+				})
+				Response(NoContent)
+			})
+			Action("pay", func() {
+				Routing(POST("/pay"))
+				Payload("Method")
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = gents.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the models", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(4))
+
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "ts", "models.ts"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("export enum OriginCountry {\n  France = \"france\",\n  Italy = \"italy\",\n}"))
+		Ω(string(content)).Should(ContainSubstring("export enum ShowBottleQuerySort {\n  Asc = \"asc\",\n  Desc = \"desc\",\n}"))
+		Ω(string(content)).Should(ContainSubstring(`/** Origin of the wine */
+export interface Origin {
+  /** Country of origin */
+  country: OriginCountry;
+  price?: string;
+  stock?: string;
+  tags?: Record<string, string[]>;
+  vintage?: number;
+}`))
+		Ω(string(content)).Should(ContainSubstring("export interface GoaExampleBottle {\n  id: number;\n  name: string;\n  origin?: Origin;\n}"))
+		Ω(string(content)).Should(ContainSubstring("export interface GoaExampleBottleTiny {\n  id: number;\n}"))
+		Ω(string(content)).Should(ContainSubstring("/** Status of the wine */\nexport enum WineStatus {\n  Opened = \"open\",\n  Cellared = \"in-cellar\",\n}"))
+		Ω(string(content)).ShouldNot(ContainSubstring("export type WineStatus"))
+		Ω(string(content)).Should(ContainSubstring(`export type Method = ({ number?: string } & { kind: "card" }) | ({ provider?: string } & { kind: "wallet" });`))
+	})
+
+	It("generates the client", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "ts", "client.ts"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring(`export type SecurityScheme = "jwt" | "key";`))
+		Ω(string(content)).Should(ContainSubstring("export function jwtAuth(token: TokenProvider): Auth {"))
+		Ω(string(content)).Should(ContainSubstring(`req.headers.set("Authorization", "Bearer " + value);`))
+		Ω(string(content)).Should(ContainSubstring("export function keyAuth(key: string): Auth {"))
+		Ω(string(content)).Should(ContainSubstring(`req.url.searchParams.set("key", key);`))
+		Ω(string(content)).Should(ContainSubstring("export interface ShowBottlePath {\n  bottleID: number;\n}"))
+		Ω(string(content)).Should(ContainSubstring("export interface ShowBottleQuery {\n  sort?: models.ShowBottleQuerySort;\n}"))
+		Ω(string(content)).Should(ContainSubstring("export interface RateBottleQuery {\n  /** Status of the wine */\n  status?: models.WineStatus;\n}"))
+		Ω(string(content)).Should(ContainSubstring("export interface ShowBottleHeaders {\n  \"X-Request-Id\"?: string;\n}"))
+		Ω(string(content)).Should(ContainSubstring("export interface ShowBottleRequest {\n  path: ShowBottlePath;\n  query?: ShowBottleQuery;\n  headers?: ShowBottleHeaders;\n}"))
+		Ω(string(content)).Should(ContainSubstring("export interface CreateBottleRequest {\n  payload: models.CreateBottlePayload;\n}"))
+		Ω(string(content)).Should(ContainSubstring(`this.baseURL = options.baseURL ?? "https://cellar.goa.design";`))
+		Ω(string(content)).Should(ContainSubstring("async showBottle(req: ShowBottleRequest): Promise<models.GoaExampleBottle> {"))
+		Ω(string(content)).Should(ContainSubstring("const ctx = this.request(\"GET\", `/bottles/${encodePath(req.path[\"bottleID\"])}`);"))
+		Ω(string(content)).Should(ContainSubstring(`await this.authorize(ctx, [["jwt"]]);`))
+		Ω(string(content)).Should(ContainSubstring("async createBottle(req: CreateBottleRequest): Promise<void> {"))
+		Ω(string(content)).Should(ContainSubstring("ctx.body = JSON.stringify(req.payload);"))
+		Ω(string(content)).Should(ContainSubstring(`await this.authorize(ctx, [["key"]]);`))
+	})
+
+	It("generates a strict compiler configuration", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "ts", "tsconfig.json"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring(`"strict": true`))
+	})
+
+	It("generates code that compiles in strict mode", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		compile(filepath.Join(outDir, "ts"))
+	})
+})
+
+var _ = Describe("Generate with a minimal design", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		dslengine.Reset()
+		API("cellar", func() {})
+		Resource("bottle", func() {
+			Action("health", func() {
+				Routing(GET("/health"))
+				Response(NoContent)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		_, genErr = gents.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates a models module without models", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "ts", "models.ts"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(HaveSuffix("\nexport {};\n"))
+		compile(filepath.Join(outDir, "ts"))
+	})
+
+	Context("with number enums", func() {
+		BeforeEach(func() {
+			Type("Volume", func() {
+				Attribute("liters", Number, func() {
+					Enum(1e6, 1.5, -2)
+				})
+			})
+		})
+
+		It("generates valid member names", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "ts", "models.ts"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("export enum VolumeLiters {\n  V1000000 = 1000000,\n  V1_5 = 1.5,\n  VMinus2 = -2,\n}"))
+			compile(filepath.Join(outDir, "ts"))
+		})
+	})
+})
+
+// compile runs the TypeScript compiler in strict mode on the project in the given directory. It
+// skips the test if the compiler is not installed.
+func compile(dir string) {
+	tsc, err := exec.LookPath("tsc")
+	if err != nil {
+		Skip("tsc is not installed")
+	}
+	out, err := exec.Command(tsc, "--strict", "--noEmit", "-p", dir).CombinedOutput()
+	Ω(err).ShouldNot(HaveOccurred(), string(out))
+}
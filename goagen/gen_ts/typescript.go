@@ -0,0 +1,398 @@
+package gents
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// typeScript computes the TypeScript types corresponding to the design types.
+	typeScript struct {
+		// enums lists the enums in order of creation.
+		enums []*tsEnum
+		// enumNames indexes the enum names by attribute.
+		enumNames map[*design.AttributeDefinition]string
+		// enumTypes lists the enum user types.
+		enumTypes []*design.UserTypeDefinition
+		// names lists the names already in use.
+		names map[string]bool
+	}
+
+	// tsEnum describes a TypeScript enum generated for an Enum validation.
+	tsEnum struct {
+		Name        string
+		Description string
+		Members     []*tsEnumMember
+	}
+
+	// tsEnumMember describes a TypeScript enum member.
+	tsEnumMember struct {
+		Name  string
+		Value string
+	}
+
+	// tsModel describes a TypeScript interface or type alias generated for a user type or a
+	// media type view.
+	tsModel struct {
+		Name        string
+		Description string
+		Attribute   *design.AttributeDefinition
+	}
+)
+
+// identifierRegex matches valid TypeScript identifiers.
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// invalidIdentifierCharRegex matches the characters that are not valid in TypeScript identifiers.
+var invalidIdentifierCharRegex = regexp.MustCompile(`[^A-Za-z0-9_$]`)
+
+func newTypeScript() *typeScript {
+	return &typeScript{
+		enumNames: make(map[*design.AttributeDefinition]string),
+		names:     make(map[string]bool),
+	}
+}
+
+// models returns the models generated for the user types and the media type views sorted by
+// name.
+func (ts *typeScript) models(api *design.APIDefinition) ([]*tsModel, error) {
+	var models []*tsModel
+	add := func(t design.DataType) {
+		name := codegen.GoTypeName(t, nil, 0, false)
+		if ts.names[name] {
+			return
+		}
+		ts.names[name] = true
+		att := t.(design.DataStructure).Definition()
+		models = append(models, &tsModel{Name: name, Description: att.Description, Attribute: att})
+	}
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if ut.IsEnum() {
+			ts.typeEnum(ut)
+			return nil
+		}
+		add(ut)
+		return nil
+	})
+	err := api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, links, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			add(p)
+			if links != nil {
+				add(links)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Action payloads defined inline are not part of the API user types.
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				add(a.Payload)
+			}
+			return nil
+		})
+	})
+	// Collect the enums before sorting so that the user types name the enums they share with
+	// the payloads.
+	for _, m := range models {
+		ts.collectEnums(m.Name, m.Attribute)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+// collectEnums creates the enums for the attributes with Enum validations defined in the given
+// attribute. The enum names are prefixed with ctx.
+func (ts *typeScript) collectEnums(ctx string, at *design.AttributeDefinition) {
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		ts.enum(ctx, at)
+	case *design.Array:
+		ts.collectEnums(ctx+"Item", actual.ElemType)
+	case *design.Hash:
+		ts.collectEnums(ctx+"Value", actual.ElemType)
+	case design.Object:
+		for _, n := range sortedNames(actual) {
+			ts.collectEnums(ctx+codegen.Goify(n, true), actual[n])
+		}
+	case *design.Union:
+		for _, n := range actual.VariantNames() {
+			ts.collectEnums(ctx+codegen.Goify(n, true), actual.Variants[n])
+		}
+	case *design.UserTypeDefinition:
+		if actual.IsPrimitive() {
+			ts.enum(ctx, actual.AttributeDefinition)
+		}
+	}
+}
+
+// typeEnum creates the enum for the given enum user type. The enum is named after the type and
+// its members use the same names as the generated Go constants.
+func (ts *typeScript) typeEnum(ut *design.UserTypeDefinition) {
+	name := codegen.GoTypeName(ut, nil, 0, false)
+	ts.names[name] = true
+	e := &tsEnum{Name: name, Description: ut.Description}
+	for i, n := range codegen.EnumValueNames(ut) {
+		e.Members = append(e.Members, &tsEnumMember{Name: n, Value: literal(ut.Validation.Values[i])})
+	}
+	ts.enums = append(ts.enums, e)
+	ts.enumNames[ut.AttributeDefinition] = name
+	ts.enumTypes = append(ts.enumTypes, ut)
+}
+
+// enum creates the enum for the given primitive attribute if it defines an Enum validation. The
+// attributes that list the same values as an enum user type, such as the params that use the
+// type, reuse its enum.
+func (ts *typeScript) enum(name string, at *design.AttributeDefinition) {
+	if at.Validation == nil || len(at.Validation.Values) == 0 {
+		return
+	}
+	if _, ok := ts.enumNames[at]; ok {
+		return
+	}
+	for _, ut := range ts.enumTypes {
+		if ut.Type.Kind() == at.Type.Kind() && reflect.DeepEqual(ut.Validation.Values, at.Validation.Values) {
+			ts.enumNames[at] = ts.enumNames[ut.AttributeDefinition]
+			return
+		}
+	}
+	switch at.Type.Kind() {
+	case design.StringKind, design.IntegerKind, design.NumberKind:
+	default:
+		return
+	}
+	for ts.names[name] {
+		name += "Enum"
+	}
+	ts.names[name] = true
+	e := &tsEnum{Name: name}
+	used := make(map[string]bool)
+	for _, v := range at.Validation.Values {
+		member := enumMemberName(v)
+		base := member
+		for i := 2; used[member]; i++ {
+			member = fmt.Sprintf("%s%d", base, i)
+		}
+		used[member] = true
+		e.Members = append(e.Members, &tsEnumMember{Name: member, Value: literal(v)})
+	}
+	ts.enums = append(ts.enums, e)
+	ts.enumNames[at] = name
+}
+
+// enumMemberName returns the name of the enum member for the given value. Numbers are written
+// without exponent and the characters that are not valid in identifiers are replaced.
+func enumMemberName(v interface{}) string {
+	var member string
+	switch actual := v.(type) {
+	case string:
+		member = codegen.Goify(actual, true)
+	case float64:
+		member = "V" + strconv.FormatFloat(actual, 'f', -1, 64)
+	default:
+		member = fmt.Sprintf("V%v", v)
+	}
+	member = strings.NewReplacer("-", "Minus", ".", "_").Replace(member)
+	member = invalidIdentifierCharRegex.ReplaceAllString(member, "_")
+	if !identifierRegex.MatchString(member) {
+		member = "V" + member
+	}
+	return member
+}
+
+// fieldTypeRef returns the TypeScript type of the field n of the given object attribute. Decimal
+// and int64 fields are encoded as JSON strings and nullable fields may also be null.
+func (ts *typeScript) fieldTypeRef(at *design.AttributeDefinition, n, prefix string) string {
+	ref := ts.typeRef(at.Type.ToObject()[n], prefix)
+	if at.IsStringEncoded(n) {
+		ref = "string"
+	}
+	if at.Type.ToObject()[n].Nullable {
+		ref += " | null"
+	}
+	return ref
+}
+
+// typeRef returns the TypeScript type of the given attribute, prefix is prepended to the names
+// of the models and enums.
+func (ts *typeScript) typeRef(at *design.AttributeDefinition, prefix string) string {
+	if name, ok := ts.enumNames[at]; ok {
+		return prefix + name
+	}
+	switch actual := at.Type.(type) {
+	case design.Primitive:
+		switch actual.Kind() {
+		case design.BooleanKind:
+			return "boolean"
+		case design.IntegerKind, design.NumberKind:
+			if at.NumericFormat() == "decimal" {
+				return "string"
+			}
+			return "number"
+		case design.StringKind, design.DateTimeKind, design.UUIDKind:
+			return "string"
+		case design.FileKind:
+			return "Blob"
+		default:
+			return "unknown"
+		}
+	case *design.Array:
+		elem := ts.typeRef(actual.ElemType, prefix)
+		if strings.ContainsAny(elem, " <{|") {
+			return "Array<" + elem + ">"
+		}
+		return elem + "[]"
+	case *design.Hash:
+		key := "string"
+		if k := actual.KeyType.Type.Kind(); k == design.IntegerKind || k == design.NumberKind {
+			key = "number"
+		}
+		return fmt.Sprintf("Record<%s, %s>", key, ts.typeRef(actual.ElemType, prefix))
+	case design.Object:
+		return ts.objectLiteral(at, prefix)
+	case *design.Union:
+		return ts.unionType(actual, prefix)
+	case *design.MediaTypeDefinition:
+		if actual.IsError() {
+			return "unknown"
+		}
+	}
+	return prefix + codegen.GoTypeName(at.Type, nil, 0, false)
+}
+
+// objectLiteral returns the TypeScript object type literal for the given object attribute.
+func (ts *typeScript) objectLiteral(at *design.AttributeDefinition, prefix string) string {
+	obj := at.Type.ToObject()
+	if len(obj) == 0 {
+		return "Record<string, never>"
+	}
+	props := make([]string, 0, len(obj))
+	for _, n := range sortedNames(obj) {
+		props = append(props, fmt.Sprintf("%s%s: %s", propertyName(n), optional(at, n), ts.fieldTypeRef(at, n, prefix)))
+	}
+	return "{ " + strings.Join(props, "; ") + " }"
+}
+
+// unionType returns the TypeScript union type for the given union, the variants are intersected
+// with the object literal that sets the discriminator attribute if any.
+func (ts *typeScript) unionType(u *design.Union, prefix string) string {
+	variants := make([]string, 0, len(u.Variants))
+	for _, n := range u.VariantNames() {
+		variant := ts.typeRef(u.Variants[n], prefix)
+		if u.Discriminator != "" {
+			variant = fmt.Sprintf("(%s & { %s: %s })", variant, propertyName(u.Discriminator), literal(n))
+		}
+		variants = append(variants, variant)
+	}
+	return strings.Join(variants, " | ")
+}
+
+// properties returns the TypeScript interface members for the given object attribute indented
+// with the given string.
+func (ts *typeScript) properties(at *design.AttributeDefinition, prefix, indent string) string {
+	obj := at.Type.ToObject()
+	var buf strings.Builder
+	for _, n := range sortedNames(obj) {
+		buf.WriteString(docComment(obj[n].Description, indent))
+		fmt.Fprintf(&buf, "%s%s%s: %s;\n", indent, propertyName(n), optional(at, n), ts.fieldTypeRef(at, n, prefix))
+	}
+	return buf.String()
+}
+
+// modelDef returns the TypeScript definition of the given model.
+func (ts *typeScript) modelDef(m *tsModel) string {
+	var buf strings.Builder
+	buf.WriteString(docComment(m.Description, ""))
+	if m.Attribute.Type.IsObject() {
+		fmt.Fprintf(&buf, "export interface %s {\n%s}\n", m.Name, ts.properties(m.Attribute, "", "  "))
+		return buf.String()
+	}
+	fmt.Fprintf(&buf, "export type %s = %s;\n", m.Name, ts.typeRef(m.Attribute, ""))
+	return buf.String()
+}
+
+// enumDef returns the TypeScript definition of the given enum.
+func (ts *typeScript) enumDef(e *tsEnum) string {
+	var buf strings.Builder
+	buf.WriteString(docComment(e.Description, ""))
+	fmt.Fprintf(&buf, "export enum %s {\n", e.Name)
+	for _, m := range e.Members {
+		fmt.Fprintf(&buf, "  %s = %s,\n", m.Name, m.Value)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// optional returns "?" if the attribute with the given name is not required by its parent.
+func optional(parent *design.AttributeDefinition, name string) string {
+	if parent.IsRequired(name) {
+		return ""
+	}
+	return "?"
+}
+
+// propertyName returns the TypeScript property name for the given attribute name, quoted if it
+// is not a valid identifier.
+func propertyName(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	return literal(name)
+}
+
+// literal returns the TypeScript literal for the given value.
+func literal(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err) // bug
+	}
+	return string(b)
+}
+
+// docComment returns the given text formatted as a JSDoc comment indented with the given string.
+func docComment(text, indent string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	text = strings.Replace(text, "*/", "*\\/", -1)
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/** %s */\n", indent, lines[0])
+	}
+	var buf strings.Builder
+	buf.WriteString(indent + "/**\n")
+	for _, l := range lines {
+		buf.WriteString(strings.TrimRight(indent+" * "+l, " ") + "\n")
+	}
+	buf.WriteString(indent + " */\n")
+	return buf.String()
+}
+
+// sortedNames returns the names of the object attributes in alphabetical order.
+func sortedNames(obj design.Object) []string {
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
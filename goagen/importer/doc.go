@@ -0,0 +1,18 @@
+/*
+Package importer implements the "goagen import" command which converts Swagger 2.0 and OpenAPI 3
+documents into design packages.
+
+Import reads documents encoded in JSON or YAML and produces the source code of a design package
+that uses the apidsl DSL: the API definition, the security schemes, one resource per tag or path
+prefix with its actions, routes, parameters, headers, payloads and responses, and the types and
+media types with their validations. The definitions used by response bodies become media types,
+the other object definitions become types and the non object definitions are inlined where they
+are used. The definitions whose oneOf schemas list objects become union types defined with OneOf.
+The operation IDs, views, collections, union hierarchies and error media type of documents
+produced by "goagen swagger" and "goagen openapi" are recognized so that importing such a document
+yields the original design.
+
+The constructs that the DSL cannot represent, such as anyOf schemas, nullable attributes or
+cookie parameters, are reported with TODO comments in the generated code.
+*/
+package importer
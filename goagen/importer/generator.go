@@ -0,0 +1,42 @@
+package importer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// DesignFile is the name of the file written by Generate.
+const DesignFile = "design.go"
+
+// Generate imports the document at path and writes the design package to the directory named
+// after the package in outDir. It refuses to overwrite an existing design unless force is true.
+func Generate(path, outDir string, force bool, opts *Options) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Source == "" {
+		opts.Source = filepath.Base(path)
+	}
+	src, err := Import(b, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	dir := filepath.Join(outDir, opts.Package)
+	file := filepath.Join(dir, DesignFile)
+	if _, err := os.Stat(file); err == nil && !force {
+		return nil, fmt.Errorf("%s already exists, use --force to overwrite it", file)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(file, src, 0644); err != nil {
+		return nil, err
+	}
+	return []string{file}, nil
+}
@@ -0,0 +1,911 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"mime"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+const (
+	// GroupByTag groups the operations into resources named after their first tag.
+	GroupByTag = "tag"
+	// GroupByPath groups the operations into resources named after the first segment of their
+	// path.
+	GroupByPath = "path"
+)
+
+type (
+	// Options configures the importer.
+	Options struct {
+		// Package is the name of the generated design package.
+		Package string
+		// Group is GroupByTag or GroupByPath, GroupByTag falls back to grouping by path for
+		// operations that have no tag.
+		Group string
+		// Source is the name of the imported document mentioned in the package documentation.
+		Source string
+	}
+
+	// importer converts a Swagger 2.0 or OpenAPI 3 document into the source code of a design
+	// package.
+	importer struct {
+		doc       *document
+		opts      *Options
+		defs      map[string]*definition
+		names     names
+		schemes   map[string]string
+		resources []*resource
+		// reach lists the definitions referenced directly or indirectly by each definition.
+		reach map[*definition]map[*definition]bool
+		// current is the definition being written, used to detect reference cycles.
+		current *definition
+		// usesDesign is true if the generated code uses the design package.
+		usesDesign bool
+		buf        *bytes.Buffer
+	}
+
+	// definition is a schema definition of the document.
+	definition struct {
+		name       string
+		schema     *schema
+		kind       defKind
+		varName    string
+		identifier string
+		// base is the media type definition of projected and collection definitions.
+		base *definition
+		// view is the view of projected and collection definitions.
+		view string
+		// views lists the attributes of each view of a media type definition.
+		views map[string][]string
+	}
+
+	// defKind is the kind of definition.
+	defKind int
+
+	// union describes oneOf schemas imported with OneOf.
+	union struct {
+		variants      []*variant
+		discriminator string
+	}
+
+	// variant is a union variant.
+	variant struct {
+		name   string
+		schema *schema
+	}
+
+	// resource is a group of operations imported as a resource.
+	resource struct {
+		name     string
+		basePath string
+		actions  []*action
+		byName   map[string]*action
+	}
+
+	// action lists the operations imported as one action, one per route. The first operation
+	// defines the action.
+	action struct {
+		name   string
+		routes []*route
+	}
+
+	// route is an operation of the document.
+	route struct {
+		method string
+		path   string
+		index  int
+		op     *operation
+	}
+)
+
+const (
+	// typeDef is a definition imported as a Type.
+	typeDef defKind = iota
+	// mediaDef is a definition imported as a MediaType.
+	mediaDef
+	// aliasDef is a definition that is not an object, the DSL inlines it where it is used.
+	aliasDef
+	// projectedDef is a view of a media type produced by goagen.
+	projectedDef
+	// collectionDef is a collection media type produced by goagen.
+	collectionDef
+	// errorDef is the goa error media type.
+	errorDef
+)
+
+// goaOperationIDRegex matches the operation IDs produced by goagen: "resource#action" followed
+// by the index of the route for actions with multiple routes.
+var goaOperationIDRegex = regexp.MustCompile(`^([^#]+)#([^#]+)(?:#(\d+))?$`)
+
+// mediaTypeTitlePrefix prefixes the title of the media type definitions produced by goagen.
+const mediaTypeTitlePrefix = "Mediatype identifier: "
+
+// viewDescriptionRegex matches the suffix goagen appends to the description of media types.
+var viewDescriptionRegex = regexp.MustCompile(` \([^()]+ view\)$`)
+
+// Import converts the Swagger 2.0 or OpenAPI 3 document b, encoded in JSON or YAML, into the
+// source code of a design package. The constructs that the DSL cannot represent are reported
+// with TODO comments.
+func Import(b []byte, opts *Options) ([]byte, error) {
+	doc, err := parse(b)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Package == "" {
+		opts.Package = "design"
+	}
+	if opts.Group == "" {
+		opts.Group = GroupByTag
+	}
+	if opts.Group != GroupByTag && opts.Group != GroupByPath {
+		return nil, fmt.Errorf("invalid group %q, must be %q or %q", opts.Group, GroupByTag, GroupByPath)
+	}
+	i := &importer{
+		doc:     doc,
+		opts:    opts,
+		defs:    make(map[string]*definition),
+		names:   make(names),
+		schemes: make(map[string]string),
+		buf:     new(bytes.Buffer),
+	}
+	i.collectOperations()
+	i.collectDefinitions()
+	i.classify()
+	i.name()
+	i.computeReach()
+	src := i.write()
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format design: %s\n%s", err, src) // bug
+	}
+	return formatted, nil
+}
+
+// collectOperations groups the operations of the document into resources and actions.
+func (i *importer) collectOperations() {
+	byName := make(map[string]*resource)
+	paths := make([]string, 0, len(i.doc.Paths))
+	for p := range i.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		item := i.doc.Paths[p]
+		ops := item.operations()
+		for _, m := range methods {
+			op, ok := ops[m]
+			if !ok {
+				continue
+			}
+			op.Parameters = mergeParameters(item.Parameters, op.Parameters)
+			resName, actName, index, goa := i.operationNames(m, p, op)
+			res, ok := byName[resName]
+			if !ok {
+				res = &resource{name: resName, byName: make(map[string]*action)}
+				byName[resName] = res
+				i.resources = append(i.resources, res)
+			}
+			a := res.byName[actName]
+			if a == nil || !goa {
+				name := actName
+				for n := 2; res.byName[name] != nil; n++ {
+					name = fmt.Sprintf("%s_%d", actName, n)
+				}
+				a = &action{name: name}
+				res.byName[name] = a
+				res.actions = append(res.actions, a)
+			}
+			a.routes = append(a.routes, &route{method: m, path: p, index: index, op: op})
+		}
+	}
+	sort.Slice(i.resources, func(j, k int) bool { return i.resources[j].name < i.resources[k].name })
+	for _, res := range i.resources {
+		var paths []string
+		for _, a := range res.actions {
+			sort.SliceStable(a.routes, func(j, k int) bool { return a.routes[j].index < a.routes[k].index })
+			for _, r := range a.routes {
+				paths = append(paths, r.path)
+			}
+		}
+		res.basePath = commonPrefix(paths)
+	}
+}
+
+// operationNames returns the names of the resource and action of the operation, the index of
+// the route and whether the operation ID was produced by goagen.
+func (i *importer) operationNames(method, path string, op *operation) (string, string, int, bool) {
+	if m := goaOperationIDRegex.FindStringSubmatch(op.OperationID); m != nil {
+		index, _ := strconv.Atoi(m[3])
+		return m[1], m[2], index, true
+	}
+	var res string
+	if i.opts.Group == GroupByTag && len(op.Tags) > 0 {
+		res = snakeName(op.Tags[0])
+	}
+	if res == "" {
+		res = pathResource(path)
+	}
+	act := snakeName(op.OperationID)
+	if act == "" {
+		switch method {
+		case "GET":
+			act = "list"
+			if strings.HasSuffix(path, "}") {
+				act = "show"
+			}
+		case "POST":
+			act = "create"
+		case "PUT", "PATCH":
+			act = "update"
+		default:
+			act = strings.ToLower(method)
+		}
+	}
+	return res, act, 0, false
+}
+
+// collectDefinitions creates the definitions of the document and hoists the inline object
+// schemas that the DSL cannot define inline into new definitions.
+func (i *importer) collectDefinitions() {
+	for n, s := range i.doc.Definitions {
+		i.defs[n] = &definition{name: n, schema: s}
+	}
+	i.foldHierarchies()
+	for _, n := range sortedKeys(i.defs) {
+		s := i.defs[n].schema
+		if s.Items != nil && isInlineObject(s.Items) {
+			s.Items = i.define(n+"Item", s.Items)
+		}
+		i.hoist(s, n)
+	}
+	for _, res := range i.resources {
+		for _, a := range res.actions {
+			owner := codegen.Goify(a.name, true) + codegen.Goify(res.name, true)
+			op := a.routes[0].op
+			for _, p := range op.Parameters {
+				if p.In == "body" {
+					i.hoist(p.Schema, owner+"Payload")
+				} else {
+					i.hoist(p.Schema, owner+codegen.Goify(p.Name, true))
+				}
+			}
+			for _, code := range responseCodes(op.Responses) {
+				r := op.Responses[code]
+				s := r.Schema
+				if s == nil {
+					continue
+				}
+				name := owner + responseName(statusCode(code))
+				if isInlineObject(s) {
+					r.Schema = i.define(name, s)
+				} else if s.Ref == "" && s.Items != nil && isInlineObject(s.Items) {
+					s.Items = i.define(name, s.Items)
+				} else {
+					i.hoist(s, name)
+				}
+			}
+		}
+	}
+}
+
+// hoist replaces the inline object schemas used as array elements or hash values with
+// references to new definitions named after owner.
+func (i *importer) hoist(s *schema, owner string) {
+	if s == nil || s.Ref != "" {
+		return
+	}
+	if s.Items != nil {
+		if isInlineObject(s.Items) {
+			s.Items = i.define(owner+"Item", s.Items)
+		} else {
+			i.hoist(s.Items, owner+"Item")
+		}
+	}
+	if ap := s.AdditionalProperties; ap != nil && ap.Schema != nil {
+		if isInlineObject(ap.Schema) {
+			ap.Schema = i.define(owner+"Value", ap.Schema)
+		} else {
+			i.hoist(ap.Schema, owner+"Value")
+		}
+	}
+	for n, p := range s.Properties {
+		i.hoist(p, owner+codegen.Goify(n, true))
+	}
+	for _, sub := range s.AllOf {
+		i.hoist(sub, owner)
+	}
+	for _, sub := range s.OneOf {
+		i.hoist(sub, owner)
+	}
+}
+
+// define creates a definition for s and returns a reference to it.
+func (i *importer) define(name string, s *schema) *schema {
+	n := name
+	for k := 2; i.defs[n] != nil || i.doc.Definitions[n] != nil; k++ {
+		n = fmt.Sprintf("%s%d", name, k)
+	}
+	i.defs[n] = &definition{name: n, schema: s}
+	i.hoist(s, n)
+	return &schema{Ref: "#/definitions/" + n}
+}
+
+// classify computes the kind of each definition. Objects are imported as types unless they
+// are used in response bodies in which case they are imported as media types. The views and
+// collections of the media types produced by goagen are merged back into their media type.
+func (i *importer) classify() {
+	groups := make(map[string][]*definition)
+	var ids []string
+	for _, d := range i.sortedDefinitions() {
+		if !strings.HasPrefix(d.schema.Title, mediaTypeTitlePrefix) {
+			if len(i.flatten(d.schema).Properties) > 0 || i.union(d.schema) != nil {
+				d.kind = typeDef
+			} else {
+				d.kind = aliasDef
+			}
+			continue
+		}
+		id, params, err := mime.ParseMediaType(strings.TrimPrefix(d.schema.Title, mediaTypeTitlePrefix))
+		if err != nil {
+			d.kind = aliasDef
+			continue
+		}
+		d.view = params["view"]
+		if d.view == "" {
+			d.view = design.DefaultView
+		}
+		switch {
+		case id == design.ErrorMediaIdentifier:
+			d.kind = errorDef
+		case params["type"] == "collection":
+			d.kind = collectionDef
+		default:
+			d.kind = projectedDef
+			if _, ok := groups[id]; !ok {
+				ids = append(ids, id)
+			}
+			groups[id] = append(groups[id], d)
+		}
+	}
+	for _, id := range ids {
+		i.mergeViews(id, groups[id])
+	}
+	for _, d := range i.defs {
+		if d.kind != collectionDef {
+			continue
+		}
+		d.kind = aliasDef
+		if d.schema.Items == nil || d.schema.Items.Ref == "" {
+			continue
+		}
+		if elem := i.defs[refName(d.schema.Items.Ref)]; elem != nil {
+			switch elem.kind {
+			case mediaDef:
+				d.kind, d.base = collectionDef, elem
+			case projectedDef:
+				d.kind, d.base = collectionDef, elem.base
+			}
+		}
+	}
+	for _, res := range i.resources {
+		for _, a := range res.actions {
+			for _, r := range a.routes {
+				for _, resp := range r.op.Responses {
+					i.promote(resp.Schema, make(map[*definition]bool))
+				}
+			}
+		}
+	}
+}
+
+// mergeViews creates the media type definition with the given identifier from the definitions
+// of its views produced by goagen.
+func (i *importer) mergeViews(id string, views []*definition) {
+	var base *definition
+	for _, v := range views {
+		if v.view == design.DefaultView {
+			base = v
+			break
+		}
+	}
+	if base == nil {
+		name := strings.TrimSuffix(views[0].name, codegen.Goify(views[0].view, true))
+		if _, ok := i.defs[name]; ok || name == "" {
+			name = views[0].name + "Media"
+		}
+		base = &definition{name: name, schema: &schema{}}
+		i.defs[name] = base
+	}
+	schemas := make(map[*definition]*schema, len(views))
+	for _, v := range views {
+		schemas[v] = v.schema
+	}
+	merged := *base.schema
+	merged.Title = ""
+	merged.Description = viewDescriptionRegex.ReplaceAllString(merged.Description, "")
+	merged.Properties = make(map[string]*schema)
+	merged.Required = nil
+	base.kind = mediaDef
+	base.identifier = id
+	base.schema = &merged
+	base.views = make(map[string][]string)
+	if base.view == design.DefaultView {
+		// Merge the default view first so that its attributes and required attributes come
+		// first.
+		ordered := []*definition{base}
+		for _, v := range views {
+			if v != base {
+				ordered = append(ordered, v)
+			}
+		}
+		views = ordered
+	}
+	for _, v := range views {
+		var attrs []string
+		for n, p := range schemas[v].Properties {
+			if _, ok := merged.Properties[n]; !ok {
+				merged.Properties[n] = p
+			}
+			attrs = append(attrs, n)
+		}
+		sort.Strings(attrs)
+		base.views[v.view] = attrs
+		for _, r := range schemas[v].Required {
+			if !contains(merged.Required, r) {
+				merged.Required = append(merged.Required, r)
+			}
+		}
+		if v != base {
+			v.base = base
+		}
+	}
+	if _, ok := base.views[design.DefaultView]; !ok {
+		base.views[design.DefaultView] = sortedKeys(merged.Properties)
+	}
+}
+
+// promote turns the types used by the response body s into media types.
+func (i *importer) promote(s *schema, seen map[*definition]bool) {
+	if s == nil {
+		return
+	}
+	if s.Ref == "" {
+		if s.Items != nil {
+			i.promote(s.Items, seen)
+		}
+		return
+	}
+	d := i.defs[refName(s.Ref)]
+	if d == nil || seen[d] {
+		return
+	}
+	seen[d] = true
+	switch d.kind {
+	case typeDef:
+		d.kind = mediaDef
+		d.identifier = "application/vnd." + codegen.KebabCase(d.name) + "+json"
+		d.views = map[string][]string{design.DefaultView: sortedKeys(i.flatten(d.schema).Properties)}
+	case aliasDef:
+		i.promote(d.schema, seen)
+	}
+}
+
+// name computes the names of the variables holding the types, media types and security
+// schemes.
+func (i *importer) name() {
+	defs := i.sortedDefinitions()
+	for _, kind := range []defKind{typeDef, mediaDef} {
+		suffix := "Type"
+		if kind == mediaDef {
+			suffix = "Media"
+		}
+		for _, d := range defs {
+			if d.kind == kind {
+				d.varName = i.names.unique(d.name, suffix)
+			}
+		}
+	}
+	for _, n := range sortedKeys(i.doc.SecurityDefinitions) {
+		switch i.doc.SecurityDefinitions[n].Type {
+		case "basic", "apiKey", "bearer", "oauth2":
+			i.schemes[n] = i.names.unique(n, "Security")
+		}
+	}
+}
+
+// computeReach computes the definitions referenced directly or indirectly by each type and
+// media type definition.
+func (i *importer) computeReach() {
+	deps := make(map[*definition]map[*definition]bool)
+	for _, d := range i.defs {
+		if d.kind == typeDef || d.kind == mediaDef {
+			deps[d] = make(map[*definition]bool)
+			i.references(d.schema, deps[d], make(map[*definition]bool))
+		}
+	}
+	i.reach = make(map[*definition]map[*definition]bool)
+	for d := range deps {
+		reach := make(map[*definition]bool)
+		stack := []*definition{d}
+		for len(stack) > 0 {
+			cur := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for dep := range deps[cur] {
+				if !reach[dep] {
+					reach[dep] = true
+					stack = append(stack, dep)
+				}
+			}
+		}
+		i.reach[d] = reach
+	}
+}
+
+// references collects the type and media type definitions referenced by s.
+func (i *importer) references(s *schema, refs, seen map[*definition]bool) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		d := i.defs[refName(s.Ref)]
+		if d == nil {
+			return
+		}
+		switch d.kind {
+		case typeDef, mediaDef:
+			refs[d] = true
+		case projectedDef, collectionDef:
+			refs[d.base] = true
+		case aliasDef:
+			if !seen[d] {
+				seen[d] = true
+				i.references(d.schema, refs, seen)
+			}
+		}
+		return
+	}
+	i.references(s.Items, refs, seen)
+	if s.AdditionalProperties != nil {
+		i.references(s.AdditionalProperties.Schema, refs, seen)
+	}
+	for _, p := range s.Properties {
+		i.references(p, refs, seen)
+	}
+	for _, l := range [][]*schema{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, sub := range l {
+			i.references(sub, refs, seen)
+		}
+	}
+}
+
+// flatten merges the allOf schemas of s into s.
+func (i *importer) flatten(s *schema) *schema {
+	if len(s.AllOf) == 0 {
+		return s
+	}
+	if len(s.AllOf) == 1 && s.AllOf[0].Ref != "" && len(s.Properties) == 0 {
+		return &schema{Ref: s.AllOf[0].Ref, Description: s.Description}
+	}
+	merged := *s
+	merged.AllOf = nil
+	merged.Properties = make(map[string]*schema)
+	for n, p := range s.Properties {
+		merged.Properties[n] = p
+	}
+	merged.Required = append([]string(nil), s.Required...)
+	for _, part := range s.AllOf {
+		if part.Ref != "" {
+			d := i.defs[refName(part.Ref)]
+			if d == nil {
+				continue
+			}
+			part = d.schema
+		}
+		part = i.flatten(part)
+		merged.merged = append(merged.merged, part)
+		for n, p := range part.Properties {
+			if _, ok := merged.Properties[n]; !ok {
+				merged.Properties[n] = p
+			}
+		}
+		for _, r := range part.Required {
+			if !contains(merged.Required, r) {
+				merged.Required = append(merged.Required, r)
+			}
+		}
+	}
+	if len(merged.Type) == 0 {
+		merged.Type = typeList{"object"}
+	}
+	return &merged
+}
+
+// foldHierarchies rewrites the Swagger 2.0 polymorphic hierarchies produced by goagen for union
+// types into oneOf schemas: the definition that sets the discriminator becomes the union and the
+// definitions that extend it with allOf become its variants.
+func (i *importer) foldHierarchies() {
+	names := sortedKeys(i.defs)
+	for _, base := range names {
+		b := i.defs[base]
+		if b == nil {
+			continue
+		}
+		disc, ok := b.schema.Discriminator.(string)
+		if !ok || len(b.schema.Properties) != 1 || b.schema.Properties[disc] == nil {
+			continue
+		}
+		var variants []*schema
+		var folded []string
+		for _, n := range names {
+			d := i.defs[n]
+			if d == nil || len(d.schema.AllOf) != 2 || len(d.schema.Properties) > 0 {
+				continue
+			}
+			for k, part := range d.schema.AllOf {
+				if part.Ref == "" || refName(part.Ref) != base {
+					continue
+				}
+				name := n
+				for _, v := range b.schema.Properties[disc].Enum {
+					if value, ok := v.(string); ok && base+codegen.Goify(value, true) == n {
+						name = value
+					}
+				}
+				v := d.schema.AllOf[1-k]
+				if v.Description == fmt.Sprintf("%q variant of %s.", name, base) {
+					v.Description = ""
+				}
+				tag := &schema{
+					Type:       typeList{"object"},
+					Properties: map[string]*schema{disc: {Type: typeList{"string"}, Enum: []interface{}{name}}},
+					Required:   []string{disc},
+				}
+				variants = append(variants, &schema{AllOf: []*schema{v, tag}})
+				folded = append(folded, n)
+				break
+			}
+		}
+		if len(variants) == 0 {
+			continue
+		}
+		b.schema.OneOf = variants
+		b.schema.Properties = nil
+		b.schema.Required = nil
+		b.schema.Discriminator = nil
+		for _, n := range folded {
+			delete(i.defs, n)
+		}
+	}
+}
+
+// union returns the union described by the oneOf schemas of s or nil if the DSL cannot represent
+// them. The variants must be objects. The variant names are the values of the discriminator
+// attribute set by the variants as produced by goagen or listed in the OpenAPI discriminator
+// mapping and default to the names of the referenced definitions.
+func (i *importer) union(s *schema) *union {
+	if s.Ref != "" || len(s.OneOf) == 0 || len(s.Properties) > 0 || len(s.AllOf) > 0 || len(s.AnyOf) > 0 {
+		return nil
+	}
+	res := &union{}
+	var mapping map[string]interface{}
+	if d, ok := s.Discriminator.(map[string]interface{}); ok {
+		res.discriminator, _ = d["propertyName"].(string)
+		mapping, _ = d["mapping"].(map[string]interface{})
+	}
+	explicit := res.discriminator != ""
+	names := make(map[string]bool)
+	for k, sub := range s.OneOf {
+		v := &variant{schema: sub}
+		if prop, value, base := discriminatorValue(sub); prop != "" {
+			if res.discriminator == "" {
+				if k > 0 {
+					return nil
+				}
+				res.discriminator = prop
+			}
+			if prop != res.discriminator {
+				return nil
+			}
+			v.name, v.schema = value, base
+		} else if res.discriminator != "" && !explicit {
+			return nil
+		}
+		if v.name == "" && sub.Ref != "" {
+			for _, n := range sortedKeys(mapping) {
+				if mapping[n] == sub.Ref {
+					v.name = n
+					break
+				}
+			}
+		}
+		if v.name == "" {
+			switch {
+			case sub.Ref != "" && res.discriminator != "":
+				v.name = refName(sub.Ref)
+			case sub.Ref != "":
+				v.name = snakeName(refName(sub.Ref))
+			case res.discriminator == "":
+				v.name = fmt.Sprintf("variant%d", k+1)
+			default:
+				return nil
+			}
+		}
+		if names[v.name] || !i.isObject(v.schema) {
+			return nil
+		}
+		names[v.name] = true
+		res.variants = append(res.variants, v)
+	}
+	return res
+}
+
+// discriminatorValue returns the name and value of the discriminator attribute set by the union
+// variant s as produced by goagen: the allOf of the variant schema and of an object whose only
+// required attribute has a single enum value. It returns empty strings otherwise.
+func discriminatorValue(s *schema) (string, string, *schema) {
+	if s.Ref != "" || len(s.AllOf) != 2 || len(s.Properties) > 0 {
+		return "", "", nil
+	}
+	for k, part := range s.AllOf {
+		if part.Ref != "" || len(part.Properties) != 1 || len(part.Required) != 1 {
+			continue
+		}
+		prop := part.Required[0]
+		p, ok := part.Properties[prop]
+		if !ok || len(p.Enum) != 1 {
+			continue
+		}
+		if value, ok := p.Enum[0].(string); ok {
+			return prop, value, s.AllOf[1-k]
+		}
+	}
+	return "", "", nil
+}
+
+// isObject returns true if s describes an object with properties or references the definition
+// of one.
+func (i *importer) isObject(s *schema) bool {
+	if s.Ref != "" {
+		d := i.defs[refName(s.Ref)]
+		return d != nil && len(i.flatten(d.schema).Properties) > 0
+	}
+	return len(i.flatten(s).Properties) > 0
+}
+
+// sortedDefinitions returns the definitions sorted by name.
+func (i *importer) sortedDefinitions() []*definition {
+	defs := make([]*definition, 0, len(i.defs))
+	for _, n := range sortedKeys(i.defs) {
+		defs = append(defs, i.defs[n])
+	}
+	return defs
+}
+
+// mergeParameters returns the operation parameters completed with the path parameters they do
+// not override.
+func mergeParameters(pathParams, opParams []*parameter) []*parameter {
+	res := append([]*parameter(nil), opParams...)
+	for _, p := range pathParams {
+		found := false
+		for _, o := range opParams {
+			if o.Name == p.Name && o.In == p.In {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// isInlineObject returns true if s defines an object with properties inline.
+func isInlineObject(s *schema) bool {
+	return s.Ref == "" && (len(s.Properties) > 0 || len(s.AllOf) > 0)
+}
+
+// pathResource returns the name of the resource derived from the first segment of path.
+func pathResource(path string) string {
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" && !strings.Contains(seg, "{") {
+			if name := snakeName(seg); name != "" {
+				return name
+			}
+		}
+	}
+	return "root"
+}
+
+// commonPrefix returns the longest sequence of path segments shared by all paths that contains
+// no parameter.
+func commonPrefix(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	prefix := strings.Split(strings.Trim(paths[0], "/"), "/")
+	for _, p := range paths[1:] {
+		segs := strings.Split(strings.Trim(p, "/"), "/")
+		n := 0
+		for n < len(prefix) && n < len(segs) && prefix[n] == segs[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	for n, seg := range prefix {
+		if seg == "" || strings.Contains(seg, "{") {
+			prefix = prefix[:n]
+			break
+		}
+	}
+	if len(prefix) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(prefix, "/")
+}
+
+// responseCodes returns the keys of the responses sorted by status code, the non numeric keys
+// come last.
+func responseCodes(responses map[string]*response) []string {
+	codes := sortedKeys(responses)
+	sort.SliceStable(codes, func(j, k int) bool {
+		cj, ck := statusCode(codes[j]), statusCode(codes[k])
+		if cj == 0 || ck == 0 {
+			return cj != 0
+		}
+		return cj < ck
+	})
+	return codes
+}
+
+// sortedKeys returns the keys of the map m sorted alphabetically, m must have string keys.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch actual := m.(type) {
+	case map[string]*schema:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*definition:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*response:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*securityScheme:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*parameter:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string][]string:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,701 @@
+package importer_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	"github.com/goadesign/goa/goagen/gen_swagger"
+	"github.com/goadesign/goa/goagen/importer"
+	"github.com/goadesign/goa/goagen/meta"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Import", func() {
+	var spec string
+	var opts *importer.Options
+	var src string
+	var importErr error
+
+	BeforeEach(func() {
+		spec = ""
+		opts = &importer.Options{Source: "spec"}
+	})
+
+	JustBeforeEach(func() {
+		b, err := importer.Import([]byte(spec), opts)
+		src, importErr = string(b), err
+	})
+
+	Context("with a Swagger 2.0 document", func() {
+		BeforeEach(func() {
+			spec = swaggerSpec
+		})
+
+		It("generates the API definition", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring("package design\n"))
+			Ω(src).Should(ContainSubstring(`var _ = API("petstore", func() {
+	Title("Petstore")
+	Description("Pets")
+	Version("1.0.0")
+	Host("petstore.example.com")
+	Scheme("https")
+	BasePath("/v1")
+	Consumes("application/json")
+	Produces("application/json")
+})`))
+		})
+
+		It("generates the security schemes", func() {
+			Ω(src).Should(ContainSubstring(`var Oauth = OAuth2Security("oauth", func() {
+	AccessCodeFlow("https://example.com/auth", "https://example.com/token")
+	Scope("pets:read", "Read pets")
+	Scope("pets:write", "Write pets")
+})`))
+		})
+
+		It("groups the operations by tag", func() {
+			Ω(src).Should(ContainSubstring(`var _ = Resource("pets", func() {
+	Description("Pet operations")
+	BasePath("/pets")
+	Action("list_pets", func() {
+		Metadata("swagger:summary", "List all pets")
+		Routing(GET(""))
+		Security(Oauth, func() {
+			Scope("pets:read")
+		})`))
+			Ω(src).Should(ContainSubstring(`	Action("create_pet", func() {
+		Routing(POST(""))
+		Security(Oauth, func() {
+			Scope("pets:write")
+		})
+		Payload(NewPet)
+		Response(Created)
+	})`))
+		})
+
+		It("generates the routes and responses", func() {
+			Ω(src).Should(ContainSubstring(`		Routing(GET("/:petId"))
+		Params(func() {
+			Param("petId", String)
+		})
+		Response(OK, func() {
+			Description("Expected response to a valid request")
+			Media(Pet)
+		})`))
+			Ω(src).Should(ContainSubstring(`Media(CollectionOf(Pet))`))
+		})
+
+		It("generates types and media types with validations", func() {
+			Ω(src).Should(ContainSubstring(`var NewPet = Type("NewPet", func() {
+	Attribute("name", String, func() {
+		MinLength(1)
+	})
+	Attribute("tag", String, func() {
+		Enum("cat", "dog")
+	})`))
+			Ω(src).Should(ContainSubstring(`var Pet = MediaType("application/vnd.pet+json", func() {
+	Attributes(func() {
+		Attribute("id", Integer)
+		Attribute("name", String)
+		Attribute("owner", "Owner")
+		Required("id", "name")
+	})
+	View("default", func() {`))
+		})
+
+		It("breaks reference cycles with type names", func() {
+			Ω(src).Should(ContainSubstring(`Attribute("pets", ArrayOf("application/vnd.pet+json"))`))
+		})
+
+		It("lists the unsupported constructs as TODO comments", func() {
+			Ω(src).Should(ContainSubstring(`// TODO: "default" response is not supported.`))
+		})
+
+		It("keeps the extended validations", func() {
+			Ω(src).Should(ContainSubstring(`MultipleOf(0.5)`))
+		})
+
+		It("keeps the numeric formats", func() {
+			Ω(src).Should(ContainSubstring(`Attribute("code", Integer, func() {
+			Format("int32")
+		})`))
+			Ω(src).ShouldNot(ContainSubstring(`Format("int64")`))
+		})
+	})
+
+	Context("with an OpenAPI 3 document in YAML", func() {
+		BeforeEach(func() {
+			spec = openAPISpec
+		})
+
+		It("reads the servers and security schemes", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`	Host("api.example.com")
+	Scheme("https")
+	BasePath("/v2")
+	Security(JWT)
+})`))
+			Ω(src).Should(ContainSubstring(`var JWT = JWTSecurity("jwt", func() {
+	Header("Authorization")
+})`))
+		})
+
+		It("merges the path parameters and reads the request bodies", func() {
+			Ω(src).Should(ContainSubstring(`	Action("update_account", func() {
+		Routing(PUT("/:id"))
+		// TODO: cookie parameter "session" is not supported.
+		Params(func() {
+			Param("id", UUID)
+		})
+		OptionalPayload(func() {
+			Attribute("nickname", String)
+		})`))
+		})
+
+		It("hoists inline response bodies into media types", func() {
+			Ω(src).Should(ContainSubstring(`Response(OK, CollectionOf(ListUsersUsersOK))`))
+			Ω(src).Should(ContainSubstring(`var ListUsersUsersOK = MediaType(`))
+		})
+
+		It("declares the deprecated actions with Deprecated", func() {
+			Ω(src).Should(ContainSubstring(`Deprecated()`))
+			Ω(src).ShouldNot(ContainSubstring(`// TODO: the action is deprecated.`))
+		})
+
+		It("lists the unsupported constructs as TODO comments", func() {
+			Ω(src).Should(ContainSubstring(`// TODO: callbacks "onEvent" are not supported.`))
+			Ω(src).Should(ContainSubstring(`// TODO: oneOf is not supported, the attribute accepts any value.`))
+		})
+
+		It("defines nullable attributes with Nullable", func() {
+			Ω(src).Should(ContainSubstring("Attribute(\"nickname\", String, func() {\n\t\t\tNullable()"))
+		})
+
+		It("keeps the exclusive bounds", func() {
+			Ω(src).Should(ContainSubstring(`ExclusiveMinimum(0)`))
+		})
+
+		Context("grouping by path", func() {
+			BeforeEach(func() {
+				opts.Group = importer.GroupByPath
+				opts.Package = "api"
+			})
+
+			It("names the resources after the path segments", func() {
+				Ω(importErr).ShouldNot(HaveOccurred())
+				Ω(src).Should(ContainSubstring("package api\n"))
+				Ω(src).Should(ContainSubstring(`var _ = Resource("accounts", func() {`))
+				Ω(src).Should(ContainSubstring(`var _ = Resource("users", func() {`))
+			})
+		})
+	})
+
+	Context("with an OpenAPI 3 oneOf discriminator", func() {
+		BeforeEach(func() {
+			spec = unionSpec
+		})
+
+		It("generates a union type", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`var Pet = Type("Pet", func() {
+	OneOf(func() {
+		Attribute("Cat", Cat)
+		Attribute("doggo", Dog)
+	}, Discriminator("petType"))
+})`))
+		})
+	})
+
+	Context("with OpenAPI 3.1 cross-field validations", func() {
+		BeforeEach(func() {
+			spec = crossFieldSpec
+		})
+
+		It("generates the cross-field validations", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`	Attribute("postal_code", String)
+	OneOfRequired("email", "phone")
+	MutuallyExclusive("fax", "phone")
+	DependentRequired("postal_code", "country")
+})`))
+			Ω(src).ShouldNot(ContainSubstring("// TODO:"))
+		})
+	})
+
+	Context("with an invalid group", func() {
+		BeforeEach(func() {
+			spec = swaggerSpec
+			opts.Group = "foo"
+		})
+
+		It("fails", func() {
+			Ω(importErr).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a document that is not Swagger or OpenAPI", func() {
+		BeforeEach(func() {
+			spec = `{"asyncapi": "2.0.0"}`
+		})
+
+		It("fails", func() {
+			Ω(importErr).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var dir, spec string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "import")
+		Ω(err).ShouldNot(HaveOccurred())
+		spec = filepath.Join(dir, "swagger.json")
+		Ω(ioutil.WriteFile(spec, []byte(swaggerSpec), 0644)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("writes the design package", func() {
+		files, err := importer.Generate(spec, dir, false, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(files).Should(Equal([]string{filepath.Join(dir, "design", importer.DesignFile)}))
+		b, err := ioutil.ReadFile(files[0])
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(ContainSubstring("imported from swagger.json with goagen import"))
+	})
+
+	It("does not overwrite an existing design unless forced", func() {
+		_, err := importer.Generate(spec, dir, false, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = importer.Generate(spec, dir, false, nil)
+		Ω(err).Should(HaveOccurred())
+		_, err = importer.Generate(spec, dir, true, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("round trip through gen_swagger", func() {
+	var workspace *codegen.Workspace
+	var original, generated interface{}
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		var err error
+		workspace, err = codegen.NewWorkspace("import")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		API("cellar", func() {
+			Title("The cellar")
+			Version("1.0")
+			Host("cellar.example.com")
+			Scheme("https")
+			BasePath("/api")
+			Consumes("application/json")
+			Produces("application/json")
+		})
+		basic := BasicAuthSecurity("basic")
+		key := APIKeySecurity("key", func() { Header("X-Key") })
+		payload := Type("BottlePayload", func() {
+			Attribute("name", String, func() {
+				MinLength(2)
+				MaxLength(20)
+			})
+			Attribute("vintage", Integer, func() {
+				Minimum(1900)
+				Maximum(2030)
+			})
+			Attribute("color", String, func() {
+				Enum("red", "white")
+				Default("red")
+			})
+			Attribute("tags", ArrayOf(String), func() {
+				MaxLength(5)
+				UniqueItems()
+			})
+			Attribute("meta", HashOf(String, Integer), func() { MaxProperties(10) })
+			Attribute("price", Number, func() {
+				ExclusiveMinimum(0)
+				MultipleOf(0.01)
+			})
+			Attribute("created", DateTime)
+			Attribute("id", UUID)
+			Required("name", "vintage")
+		})
+		method := Type("PaymentMethod", func() {
+			OneOf(func() {
+				Attribute("card", func() {
+					Attribute("number", String)
+					Required("number")
+				})
+				Attribute("wallet", func() {
+					Attribute("provider", String)
+				})
+			}, Discriminator("kind"))
+		})
+		bottle := MediaType("application/vnd.goa.example.bottle+json", func() {
+			Description("A bottle")
+			TypeName("Bottle")
+			Reference(payload)
+			Attributes(func() {
+				Attribute("id", Integer, "ID")
+				Attribute("name")
+				Attribute("vintage")
+				Attribute("href", String)
+				Required("id", "name")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+				Attribute("vintage")
+			})
+			View("tiny", func() {
+				Attribute("id")
+				Attribute("href")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			DefaultMedia(bottle)
+			Security(basic)
+			Action("list", func() {
+				Routing(GET(""))
+				Description("List bottles")
+				Params(func() {
+					Param("years", ArrayOf(Integer))
+					Param("limit", Integer, func() { Minimum(1) })
+				})
+				Response(OK, func() { Media(CollectionOf(bottle), "tiny") })
+			})
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() { Param("id", Integer) })
+				Response(OK)
+				Response(NotFound)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Security(key)
+				Payload(payload)
+				Response(Created, func() {
+					Headers(func() { Header("Location", String) })
+				})
+				Response(BadRequest, ErrorMedia)
+			})
+			Action("update", func() {
+				Routing(PATCH("/:id"), PUT("/:id"))
+				NoSecurity()
+				Params(func() { Param("id", Integer) })
+				Payload(func() {
+					Member("name")
+					Required("name")
+				})
+				Response(NoContent)
+			})
+			Action("pay", func() {
+				Routing(POST("/:id/payments"))
+				Params(func() { Param("id", Integer) })
+				Payload(method)
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).Should(Succeed())
+		swagger, err := genswagger.New(Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(swagger)
+		Ω(err).ShouldNot(HaveOccurred())
+		original = normalize(b)
+
+		src, err := importer.Import(b, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("imported/design")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ioutil.WriteFile(filepath.Join(pkg.Abs(), importer.DesignFile), src, 0644)).Should(Succeed())
+
+		out := filepath.Join(workspace.Path, "out")
+		gen, err := meta.NewGenerator(
+			"genswagger.Generate",
+			[]*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa/goagen/gen_swagger")},
+			map[string]string{"design": "imported/design", "out": out},
+			nil,
+		)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = gen.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err = ioutil.ReadFile(filepath.Join(out, "swagger", "swagger.json"))
+		Ω(err).ShouldNot(HaveOccurred())
+		generated = normalize(b)
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("regenerates the same document", func() {
+		Ω(generated).Should(Equal(original))
+	})
+})
+
+// normalize decodes the Swagger document b and removes the parts that do not survive a round
+// trip: the randomly generated examples and the root responses that goagen only fills for the
+// standard responses.
+func normalize(b []byte) interface{} {
+	var doc map[string]interface{}
+	Ω(json.Unmarshal(b, &doc)).Should(Succeed())
+	delete(doc, "responses")
+	return stripExamples(doc)
+}
+
+// stripExamples removes the "example" keys of v recursively.
+func stripExamples(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[string]interface{}:
+		delete(actual, "example")
+		for k, e := range actual {
+			actual[k] = stripExamples(e)
+		}
+	case []interface{}:
+		for i, e := range actual {
+			actual[i] = stripExamples(e)
+		}
+	}
+	return v
+}
+
+const swaggerSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "Petstore", "version": "1.0.0", "description": "Pets"},
+  "host": "petstore.example.com",
+  "basePath": "/v1",
+  "schemes": ["https"],
+  "consumes": ["application/json"],
+  "produces": ["application/json"],
+  "securityDefinitions": {
+    "oauth": {
+      "type": "oauth2", "flow": "accessCode",
+      "authorizationUrl": "https://example.com/auth", "tokenUrl": "https://example.com/token",
+      "scopes": {"pets:read": "Read pets", "pets:write": "Write pets"}
+    }
+  },
+  "tags": [{"name": "pets", "description": "Pet operations"}],
+  "paths": {
+    "/pets": {
+      "get": {
+        "tags": ["pets"], "operationId": "listPets", "summary": "List all pets",
+        "security": [{"oauth": ["pets:read"]}],
+        "parameters": [{"name": "limit", "in": "query", "type": "integer", "format": "int32", "maximum": 100}],
+        "responses": {
+          "200": {"description": "A list of pets", "schema": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}},
+          "default": {"description": "Unexpected error", "schema": {"$ref": "#/definitions/Error"}}
+        }
+      },
+      "post": {
+        "tags": ["pets"], "operationId": "createPet",
+        "security": [{"oauth": ["pets:write"]}],
+        "parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/NewPet"}}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/pets/{petId}": {
+      "get": {
+        "tags": ["pets"], "operationId": "showPetById",
+        "parameters": [{"name": "petId", "in": "path", "required": true, "type": "string"}],
+        "responses": {
+          "200": {"description": "Expected response to a valid request", "schema": {"$ref": "#/definitions/Pet"}},
+          "404": {"description": "Not found"}
+        }
+      }
+    }
+  },
+  "definitions": {
+    "NewPet": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string", "minLength": 1},
+        "tag": {"type": "string", "enum": ["cat", "dog"]},
+        "weight": {"type": "number", "multipleOf": 0.5}
+      }
+    },
+    "Pet": {
+      "type": "object",
+      "required": ["id", "name"],
+      "properties": {
+        "id": {"type": "integer", "format": "int64"},
+        "name": {"type": "string"},
+        "owner": {"$ref": "#/definitions/Owner"}
+      }
+    },
+    "Owner": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "pets": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}
+      }
+    },
+    "Error": {
+      "type": "object",
+      "required": ["code", "message"],
+      "properties": {"code": {"type": "integer", "format": "int32"}, "message": {"type": "string"}}
+    }
+  }
+}
+`
+
+const openAPISpec = `openapi: 3.0.3
+info:
+  title: Accounts
+  version: "2.0"
+servers:
+  - url: https://api.example.com/v2
+components:
+  securitySchemes:
+    jwt:
+      type: http
+      scheme: bearer
+  schemas:
+    Account:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+          format: uuid
+        balance:
+          type: number
+          exclusiveMinimum: 0
+        nickname:
+          type: string
+          nullable: true
+        owner:
+          oneOf:
+            - $ref: '#/components/schemas/Person'
+            - $ref: '#/components/schemas/Company'
+    Person:
+      type: object
+      properties:
+        name: {type: string}
+    Company:
+      type: object
+      properties:
+        name: {type: string}
+security:
+  - jwt: []
+paths:
+  /accounts/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: string, format: uuid}
+    get:
+      operationId: getAccount
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Account'}
+    put:
+      operationId: updateAccount
+      parameters:
+        - name: session
+          in: cookie
+          schema: {type: string}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                nickname: {type: string}
+      responses:
+        '204':
+          description: Updated
+  /users:
+    get:
+      operationId: listUsers
+      deprecated: true
+      callbacks:
+        onEvent: {}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    login: {type: string}
+`
+
+const unionSpec = `openapi: 3.0.3
+info:
+  title: Pets
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: "#/components/schemas/Cat"
+        - $ref: "#/components/schemas/Dog"
+      discriminator:
+        propertyName: petType
+        mapping:
+          doggo: "#/components/schemas/Dog"
+    Cat:
+      type: object
+      properties:
+        name:
+          type: string
+    Dog:
+      type: object
+      properties:
+        bark:
+          type: boolean
+`
+
+const crossFieldSpec = `openapi: 3.1.0
+info:
+  title: Contacts
+  version: "1.0"
+paths: {}
+components:
+  schemas:
+    Contact:
+      type: object
+      properties:
+        email:
+          type: string
+        phone:
+          type: string
+        fax:
+          type: string
+        country:
+          type: string
+        postal_code:
+          type: string
+      oneOf:
+        - required: [email]
+        - required: [phone]
+      not:
+        required: [fax, phone]
+      dependentRequired:
+        postal_code: [country]
+`
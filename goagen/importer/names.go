@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// dslNames lists the exported identifiers of the design and apidsl packages. The generated
+// design package dot imports both so its variables may not use these names.
+var dslNames = map[string]bool{
+	"API": true, "APIDefinition": true, "APIKeySecurity": true, "APIKeySecurityKind": true,
+	"Accepted": true, "AccessCodeFlow": true, "Action": true, "ActionDefinition": true,
+	"ActionIterator": true, "AddedIn": true, "Any": true, "AnyKind": true, "ApplicationFlow": true,
+	"Array": true, "ArrayKind": true, "ArrayOf": true, "ArrayVal": true, "Attribute": true,
+	"AttributeDefinition": true, "AttributeIterator": true, "Attributes": true, "BadGateway": true,
+	"BadRequest": true, "BasePath": true, "BasicAuthSecurity": true, "BasicAuthSecurityKind": true,
+	"Boolean": true, "BooleanKind": true, "ByFilePath": true, "CONNECT": true, "CORSDefinition": true,
+	"CanBeNullable": true, "CanonicalActionName": true, "CanonicalIdentifier": true,
+	"CollectionOf": true, "Conflict": true, "Consumes": true, "Contact": true,
+	"ContactDefinition": true, "ContainerDefinition": true, "ContentType": true, "Continue": true,
+	"Cookie": true, "Cookies": true, "Created": true, "Credentials": true, "Cursor": true,
+	"DELETE": true, "DataStructure": true, "DataType": true, "DateTime": true, "DateTimeKind": true,
+	"Default": true, "DefaultDecoders": true, "DefaultEncoders": true, "DefaultMedia": true,
+	"DefaultPageSize": true, "DefaultView": true, "DependentRequired": true, "Deprecated": true,
+	"DeprecationDefinition": true, "Description": true, "Design": true, "Discriminator": true,
+	"Docs": true, "DocsDefinition": true, "Dup": true, "DupAtt": true, "Email": true,
+	"EncodingDefinition": true, "Enum": true, "Envelope": true, "Eq": true, "ErrorMedia": true,
+	"ErrorMediaIdentifier": true, "Example": true, "ExclusiveMaximum": true, "ExclusiveMinimum": true,
+	"ExpectationFailed": true, "Expose": true, "ExtractWildcards": true, "File": true,
+	"FileKind": true, "FileServerDefinition": true, "FileServerIterator": true, "Files": true,
+	"FilterDefinition": true, "FilterOperator": true, "Filterable": true, "Forbidden": true,
+	"Format": true, "Found": true, "Function": true, "GET": true, "GatewayTimeout": true,
+	"GeneratedMediaTypes": true, "GobContentTypes": true, "Gone": true, "Gt": true, "Gte": true,
+	"HEAD": true, "HTTPSigSecurity": true, "HTTPSigSecurityKind": true,
+	"HTTPVersionNotSupported": true, "HasFile": true, "HasKnownEncoder": true, "Hash": true,
+	"HashKind": true, "HashOf": true, "HashVal": true, "Header": true, "HeaderIterator": true,
+	"HeaderVersioning": true, "Headers": true, "Host": true, "ImplicitFlow": true, "Integer": true,
+	"IntegerFormats": true, "IntegerKind": true, "InternalServerError": true, "JSONContentTypes": true,
+	"JSONPatch": true, "JSONPatchContentType": true, "JSONPatchOperation": true, "JWTSecurity": true,
+	"JWTSecurityKind": true, "Kind": true, "KnownEncoderFunctions": true, "KnownEncoders": true,
+	"LengthRequired": true, "License": true, "LicenseDefinition": true, "LifecycleDefinition": true,
+	"Link": true, "LinkDefinition": true, "Links": true, "Lt": true, "Lte": true, "MTLSSecurity": true,
+	"MTLSSecurityKind": true, "MaxAge": true, "MaxLength": true, "MaxPageSize": true,
+	"MaxProperties": true, "Maximum": true, "Media": true, "MediaType": true,
+	"MediaTypeDefinition": true, "MediaTypeIterator": true, "MediaTypeKind": true,
+	"MediaTypeRoot": true, "MediaTypeVersioning": true, "Member": true, "MergePatch": true,
+	"MergePatchContentType": true, "Metadata": true, "MethodNotAllowed": true, "Methods": true,
+	"MinLength": true, "MinProperties": true, "Minimum": true, "MovedPermanently": true,
+	"MultipartForm": true, "MultipleChoices": true, "MultipleOf": true, "MutuallyExclusive": true,
+	"Name": true, "Ne": true, "NewAPIDefinition": true, "NewMediaTypeDefinition": true,
+	"NewRandomGenerator": true, "NewResourceDefinition": true, "NewUserTypeDefinition": true,
+	"NoContent": true, "NoExample": true, "NoSecurity": true, "NoSecurityKind": true,
+	"NonAuthoritativeInfo": true, "NotAcceptable": true, "NotFound": true, "NotImplemented": true,
+	"NotModified": true, "Nullable": true, "Number": true, "NumberFormats": true, "NumberKind": true,
+	"OAuth2Security": true, "OAuth2SecurityKind": true, "OK": true, "OPTIONS": true, "Object": true,
+	"ObjectKind": true, "Offset": true, "OneOf": true, "OneOfRequired": true, "OptionalPayload": true,
+	"Origin": true, "PATCH": true, "POST": true, "PUT": true, "Package": true, "Paginated": true,
+	"PaginationDefinition": true, "PaginationStrategy": true, "Param": true, "Params": true,
+	"Parent": true, "PartialContent": true, "PasswordFlow": true, "PatchDefinition": true,
+	"PathVersioning": true, "Pattern": true, "Payload": true, "PaymentRequired": true,
+	"PreconditionFailed": true, "Prefix": true, "Primitive": true, "PrivateNetwork": true,
+	"Produces": true, "ProjectedMediaTypes": true, "ProxyAuthRequired": true, "Query": true,
+	"RandomGenerator": true, "Reference": true, "RemovedIn": true, "RequestEntityTooLarge": true,
+	"RequestTimeout": true, "RequestURITooLong": true, "RequestedRangeNotSatisfiable": true,
+	"Required": true, "ResetContent": true, "Resource": true, "ResourceDefinition": true,
+	"ResourceIterator": true, "Response": true, "ResponseDefinition": true, "ResponseIterator": true,
+	"ResponseTemplate": true, "ResponseTemplateDefinition": true, "RouteDefinition": true,
+	"Routing": true, "Scheme": true, "Scope": true, "Security": true, "SecurityDefinition": true,
+	"SecuritySchemeDefinition": true, "SecuritySchemeKind": true, "SeeOther": true,
+	"ServiceUnavailable": true, "Sortable": true, "SparseFieldsets": true, "Status": true,
+	"String": true, "StringKind": true, "Sunset": true, "SupportedValidationFormats": true,
+	"SwitchingProtocols": true, "TRACE": true, "Teapot": true, "TemporaryRedirect": true,
+	"TermsOfService": true, "Title": true, "TokenURL": true, "Trait": true, "Type": true,
+	"TypeName": true, "URL": true, "UUID": true, "UUIDKind": true, "Unauthorized": true, "Union": true,
+	"UnionKind": true, "UnionOption": true, "UniqueItems": true, "UnprocessableEntity": true,
+	"UnsupportedMediaType": true, "UseProxy": true, "UseTrait": true, "UserTypeDefinition": true,
+	"UserTypeIterator": true, "UserTypeKind": true, "UserTypes": true, "ValidateFunc": true,
+	"Version": true, "VersionDefinition": true, "VersionedBy": true, "VersioningScheme": true,
+	"View": true, "ViewDefinition": true, "ViewIterator": true, "WildcardRegex": true,
+	"XMLContentTypes": true,
+}
+
+// nonIdentRegex matches the sequences of characters that may not appear in an identifier.
+var nonIdentRegex = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// names keeps track of the variable names used by the generated code.
+type names map[string]bool
+
+// unique returns a variable name derived from name that does not clash with the names already
+// used nor with the DSL. suffix is appended to the name in case of clash before resorting to a
+// number.
+func (n names) unique(name, suffix string) string {
+	v := codegen.Goify(nonIdentRegex.ReplaceAllString(name, "_"), true)
+	if v == "" || v[0] >= '0' && v[0] <= '9' {
+		v = suffix + v
+	}
+	if n[v] || dslNames[v] {
+		v += suffix
+	}
+	base := v
+	for i := 2; n[v] || dslNames[v]; i++ {
+		v = fmt.Sprintf("%s%d", base, i)
+	}
+	n[v] = true
+	return v
+}
+
+// snakeName returns the snake_case version of name suitable for resource and action names.
+func snakeName(name string) string {
+	name = nonIdentRegex.ReplaceAllString(codegen.SnakeCase(name), "_")
+	return strings.Trim(name, "_")
+}
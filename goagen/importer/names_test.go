@@ -0,0 +1,33 @@
+package importer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("dslNames", func() {
+	It("lists the exported identifiers of the dot-imported packages", func() {
+		for _, dir := range []string{"../../design", "../../design/apidsl"} {
+			fset := token.NewFileSet()
+			pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+				return !strings.HasSuffix(fi.Name(), "_test.go")
+			}, 0)
+			Ω(err).ShouldNot(HaveOccurred())
+			for _, pkg := range pkgs {
+				for _, f := range pkg.Files {
+					for n := range f.Scope.Objects {
+						if ast.IsExported(n) {
+							Ω(dslNames).Should(HaveKey(n), "%s.%s", pkg.Name, n)
+						}
+					}
+				}
+			}
+		}
+	})
+})
@@ -0,0 +1,506 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// document is the union of the Swagger 2.0 and OpenAPI 3 document fields read by the
+	// importer.
+	document struct {
+		Swagger             string                     `json:"swagger"`
+		OpenAPI             string                     `json:"openapi"`
+		Info                *info                      `json:"info"`
+		Host                string                     `json:"host"`
+		BasePath            string                     `json:"basePath"`
+		Schemes             []string                   `json:"schemes"`
+		Consumes            []string                   `json:"consumes"`
+		Produces            []string                   `json:"produces"`
+		Servers             []*server                  `json:"servers"`
+		Paths               map[string]*pathItem       `json:"paths"`
+		Definitions         map[string]*schema         `json:"definitions"`
+		Parameters          map[string]*parameter      `json:"parameters"`
+		Responses           map[string]*response       `json:"responses"`
+		SecurityDefinitions map[string]*securityScheme `json:"securityDefinitions"`
+		Components          *components                `json:"components"`
+		Security            []map[string][]string      `json:"security"`
+		Tags                []*tag                     `json:"tags"`
+		ExternalDocs        *externalDocs              `json:"externalDocs"`
+	}
+
+	info struct {
+		Title          string   `json:"title"`
+		Description    string   `json:"description"`
+		TermsOfService string   `json:"termsOfService"`
+		Version        string   `json:"version"`
+		Contact        *contact `json:"contact"`
+		License        *license `json:"license"`
+	}
+
+	contact struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		URL   string `json:"url"`
+	}
+
+	license struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+
+	externalDocs struct {
+		Description string `json:"description"`
+		URL         string `json:"url"`
+	}
+
+	server struct {
+		URL string `json:"url"`
+	}
+
+	tag struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	components struct {
+		Schemas         map[string]*schema         `json:"schemas"`
+		Parameters      map[string]*parameter      `json:"parameters"`
+		Responses       map[string]*response       `json:"responses"`
+		RequestBodies   map[string]*requestBody    `json:"requestBodies"`
+		Headers         map[string]*parameter      `json:"headers"`
+		SecuritySchemes map[string]*securityScheme `json:"securitySchemes"`
+	}
+
+	pathItem struct {
+		Ref        string       `json:"$ref"`
+		Get        *operation   `json:"get"`
+		Put        *operation   `json:"put"`
+		Post       *operation   `json:"post"`
+		Delete     *operation   `json:"delete"`
+		Options    *operation   `json:"options"`
+		Head       *operation   `json:"head"`
+		Patch      *operation   `json:"patch"`
+		Trace      *operation   `json:"trace"`
+		Parameters []*parameter `json:"parameters"`
+	}
+
+	operation struct {
+		Tags        []string               `json:"tags"`
+		Summary     string                 `json:"summary"`
+		Description string                 `json:"description"`
+		OperationID string                 `json:"operationId"`
+		Consumes    []string               `json:"consumes"`
+		Produces    []string               `json:"produces"`
+		Parameters  []*parameter           `json:"parameters"`
+		RequestBody *requestBody           `json:"requestBody"`
+		Responses   map[string]*response   `json:"responses"`
+		Deprecated  bool                   `json:"deprecated"`
+		Security    *[]map[string][]string `json:"security"`
+		Callbacks   map[string]interface{} `json:"callbacks"`
+	}
+
+	// parameter describes both Swagger 2.0 parameters, whose type is given inline, and
+	// OpenAPI 3 parameters and headers, whose type is given by a schema.
+	parameter struct {
+		schema
+		Name     string  `json:"name"`
+		In       string  `json:"in"`
+		Required bool    `json:"required"`
+		Schema   *schema `json:"schema"`
+	}
+
+	requestBody struct {
+		Ref         string                `json:"$ref"`
+		Description string                `json:"description"`
+		Required    bool                  `json:"required"`
+		Content     map[string]*mediaItem `json:"content"`
+	}
+
+	response struct {
+		Ref         string                `json:"$ref"`
+		Description string                `json:"description"`
+		Schema      *schema               `json:"schema"`
+		Headers     map[string]*parameter `json:"headers"`
+		Content     map[string]*mediaItem `json:"content"`
+	}
+
+	mediaItem struct {
+		Schema *schema `json:"schema"`
+	}
+
+	securityScheme struct {
+		Type             string            `json:"type"`
+		Description      string            `json:"description"`
+		Name             string            `json:"name"`
+		In               string            `json:"in"`
+		Scheme           string            `json:"scheme"`
+		Flow             string            `json:"flow"`
+		AuthorizationURL string            `json:"authorizationUrl"`
+		TokenURL         string            `json:"tokenUrl"`
+		Scopes           map[string]string `json:"scopes"`
+		Flows            map[string]*flow  `json:"flows"`
+	}
+
+	flow struct {
+		AuthorizationURL string            `json:"authorizationUrl"`
+		TokenURL         string            `json:"tokenUrl"`
+		Scopes           map[string]string `json:"scopes"`
+	}
+
+	// schema is a JSON schema as used by Swagger 2.0 and OpenAPI 3.0 and 3.1.
+	schema struct {
+		Ref                  string             `json:"$ref"`
+		Type                 typeList           `json:"type"`
+		Format               string             `json:"format"`
+		Title                string             `json:"title"`
+		Description          string             `json:"description"`
+		Items                *schema            `json:"items"`
+		Properties           map[string]*schema `json:"properties"`
+		AdditionalProperties *additional        `json:"additionalProperties"`
+		Required             []string           `json:"required"`
+		Enum                 []interface{}      `json:"enum"`
+		Default              interface{}        `json:"default"`
+		Example              interface{}        `json:"example"`
+		Pattern              string             `json:"pattern"`
+		Minimum              *float64           `json:"minimum"`
+		Maximum              *float64           `json:"maximum"`
+		ExclusiveMinimum     interface{}        `json:"exclusiveMinimum"`
+		ExclusiveMaximum     interface{}        `json:"exclusiveMaximum"`
+		MultipleOf           *float64           `json:"multipleOf"`
+		MinLength            *int               `json:"minLength"`
+		MaxLength            *int               `json:"maxLength"`
+		MinItems             *int               `json:"minItems"`
+		MaxItems             *int               `json:"maxItems"`
+		UniqueItems          bool               `json:"uniqueItems"`
+		MinProperties        *int               `json:"minProperties"`
+		MaxProperties        *int               `json:"maxProperties"`
+		AllOf                []*schema          `json:"allOf"`
+		AnyOf                []*schema          `json:"anyOf"`
+		OneOf                []*schema          `json:"oneOf"`
+		Not                  *schema            `json:"not"`
+		Nullable             bool               `json:"nullable"`
+		Discriminator        interface{}        `json:"discriminator"`
+		Const                interface{}        `json:"const"`
+		ReadOnly             bool               `json:"readOnly"`
+		WriteOnly            bool               `json:"writeOnly"`
+		CollectionFormat     string             `json:"collectionFormat"`
+
+		// DependentRequired lists the properties required by each property when present
+		// (OpenAPI 3.1).
+		DependentRequired map[string][]string `json:"dependentRequired"`
+		// XNullable is the Swagger extension that marks nullable values.
+		XNullable bool `json:"x-nullable"`
+
+		// merged lists the allOf schemas merged into the schema by flatten.
+		merged []*schema
+	}
+
+	// typeList is the value of the schema "type" field which OpenAPI 3.1 allows to be a list.
+	typeList []string
+
+	// additional is the value of the "additionalProperties" field, either a boolean or a
+	// schema.
+	additional struct {
+		Allowed bool
+		Schema  *schema
+	}
+)
+
+// UnmarshalJSON accepts both a single type name and a list of type names.
+func (t *typeList) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*t = typeList{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(b, &names); err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// UnmarshalJSON accepts both a boolean and a schema.
+func (a *additional) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(b, &a.Schema)
+}
+
+// parse reads a Swagger 2.0 or OpenAPI 3 document encoded in JSON or YAML.
+func parse(b []byte) (*document, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] != '{' {
+		var raw interface{}
+		if err := yaml.Unmarshal(b, &raw); err != nil {
+			return nil, fmt.Errorf("invalid document: %s", err)
+		}
+		js, err := json.Marshal(jsonValue(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid document: %s", err)
+		}
+		b = js
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid document: %s", err)
+	}
+	switch {
+	case strings.HasPrefix(doc.Swagger, "2."):
+	case strings.HasPrefix(doc.OpenAPI, "3."):
+		doc.fromOpenAPI()
+	case doc.Swagger != "":
+		return nil, fmt.Errorf("unsupported Swagger version %q", doc.Swagger)
+	case doc.OpenAPI != "":
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", doc.OpenAPI)
+	default:
+		return nil, fmt.Errorf("not a Swagger or OpenAPI document: missing version")
+	}
+	doc.resolve()
+	return &doc, nil
+}
+
+// jsonValue converts the maps produced by the YAML decoder to maps with string keys.
+func jsonValue(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(actual))
+		for k, e := range actual {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range actual {
+			actual[i] = jsonValue(e)
+		}
+	}
+	return v
+}
+
+// fromOpenAPI moves the OpenAPI 3 fields that have a Swagger 2.0 equivalent to their Swagger
+// 2.0 counterpart so that the rest of the importer only deals with the latter.
+func (d *document) fromOpenAPI() {
+	if len(d.Servers) > 0 {
+		if u, err := url.Parse(d.Servers[0].URL); err == nil {
+			d.Host = u.Host
+			d.BasePath = strings.TrimSuffix(u.Path, "/")
+			if u.Scheme != "" {
+				d.Schemes = []string{u.Scheme}
+			}
+		}
+		for _, s := range d.Servers[1:] {
+			if u, err := url.Parse(s.URL); err == nil && u.Host == d.Host && u.Scheme != "" && !contains(d.Schemes, u.Scheme) {
+				d.Schemes = append(d.Schemes, u.Scheme)
+			}
+		}
+	}
+	if c := d.Components; c != nil {
+		d.Definitions = c.Schemas
+		d.Parameters = c.Parameters
+		d.Responses = c.Responses
+		d.SecurityDefinitions = make(map[string]*securityScheme, len(c.SecuritySchemes))
+		for n, s := range c.SecuritySchemes {
+			d.SecurityDefinitions[n] = s.fromOpenAPI()
+		}
+	}
+	for _, item := range d.Paths {
+		for _, op := range item.operations() {
+			if rb := d.requestBody(op.RequestBody); rb != nil {
+				if mime, item := mediaFor(rb.Content); item != nil {
+					op.Consumes = []string{mime}
+					op.Parameters = append(op.Parameters, &parameter{
+						Name:     "payload",
+						In:       "body",
+						Required: rb.Required,
+						Schema:   item.Schema,
+						schema:   schema{Description: rb.Description},
+					})
+				}
+			}
+			for _, resp := range op.Responses {
+				if resp.Ref == "" {
+					resp.fromOpenAPI()
+				}
+			}
+		}
+	}
+	for _, resp := range d.Responses {
+		resp.fromOpenAPI()
+	}
+}
+
+// fromOpenAPI converts a OpenAPI 3 security scheme to its Swagger 2.0 equivalent. The schemes
+// that have no equivalent keep their OpenAPI 3 type.
+func (s *securityScheme) fromOpenAPI() *securityScheme {
+	switch {
+	case s.Type == "http" && strings.EqualFold(s.Scheme, "basic"):
+		s.Type = "basic"
+	case s.Type == "http" && strings.EqualFold(s.Scheme, "bearer"):
+		s.Type = "bearer"
+	case s.Type == "oauth2":
+		for _, name := range []string{"authorizationCode", "implicit", "password", "clientCredentials"} {
+			if f, ok := s.Flows[name]; ok {
+				s.Flow = map[string]string{
+					"authorizationCode": "accessCode",
+					"implicit":          "implicit",
+					"password":          "password",
+					"clientCredentials": "application",
+				}[name]
+				s.AuthorizationURL = f.AuthorizationURL
+				s.TokenURL = f.TokenURL
+				s.Scopes = f.Scopes
+				break
+			}
+		}
+	}
+	return s
+}
+
+// fromOpenAPI sets the schema of the response from its content.
+func (r *response) fromOpenAPI() {
+	if _, item := mediaFor(r.Content); item != nil {
+		r.Schema = item.Schema
+	}
+}
+
+// resolve replaces the references to parameters, responses and request bodies with the
+// definitions they reference and moves the type fields of Swagger 2.0 parameters and headers to
+// their schema.
+func (d *document) resolve() {
+	for _, item := range d.Paths {
+		for i, p := range item.Parameters {
+			item.Parameters[i] = d.parameter(p)
+		}
+		for _, op := range item.operations() {
+			for i, p := range op.Parameters {
+				op.Parameters[i] = d.parameter(p)
+			}
+			for code, resp := range op.Responses {
+				op.Responses[code] = d.response(resp)
+			}
+		}
+	}
+}
+
+// parameter returns the parameter referenced by p if any, p otherwise.
+func (d *document) parameter(p *parameter) *parameter {
+	if p.Ref != "" {
+		if ref, ok := d.Parameters[refName(p.Ref)]; ok {
+			p = ref
+		}
+	}
+	if p.Schema == nil && p.In != "body" {
+		s := p.schema
+		p.Schema = &s
+	}
+	return p
+}
+
+// response returns the response referenced by r if any, r otherwise.
+func (d *document) response(r *response) *response {
+	if r.Ref != "" {
+		if ref, ok := d.Responses[refName(r.Ref)]; ok {
+			r = ref
+		}
+	}
+	for n, h := range r.Headers {
+		if h.Ref != "" && d.Components != nil {
+			if ref, ok := d.Components.Headers[refName(h.Ref)]; ok {
+				h = ref
+			}
+		}
+		if h.Schema == nil {
+			s := h.schema
+			h.Schema = &s
+		}
+		if h.Schema.Description == "" {
+			h.Schema.Description = h.Description
+		}
+		r.Headers[n] = h
+	}
+	return r
+}
+
+// requestBody returns the request body referenced by rb if any, rb otherwise.
+func (d *document) requestBody(rb *requestBody) *requestBody {
+	if rb != nil && rb.Ref != "" && d.Components != nil {
+		if ref, ok := d.Components.RequestBodies[refName(rb.Ref)]; ok {
+			return ref
+		}
+	}
+	return rb
+}
+
+// methods lists the HTTP methods in the order they are imported.
+var methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE"}
+
+// operations returns the operations of the path indexed by HTTP method.
+func (p *pathItem) operations() map[string]*operation {
+	ops := make(map[string]*operation)
+	for m, op := range map[string]*operation{
+		"GET": p.Get, "HEAD": p.Head, "POST": p.Post, "PUT": p.Put, "PATCH": p.Patch,
+		"DELETE": p.Delete, "OPTIONS": p.Options, "TRACE": p.Trace,
+	} {
+		if op != nil {
+			ops[m] = op
+		}
+	}
+	return ops
+}
+
+// mediaFor returns the content item used to import the request or response body, JSON content
+// types are preferred.
+func mediaFor(content map[string]*mediaItem) (string, *mediaItem) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	mimes := make([]string, 0, len(content))
+	for m := range content {
+		mimes = append(mimes, m)
+	}
+	sort.Strings(mimes)
+	for _, m := range mimes {
+		if strings.Contains(m, "json") {
+			return m, content[m]
+		}
+	}
+	return mimes[0], content[mimes[0]]
+}
+
+// refName returns the name of the definition referenced by ref.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// statusCode returns the HTTP status code of a response key, 0 if the key is not a status code
+// (e.g. "default").
+func statusCode(key string) int {
+	code, err := strconv.Atoi(key)
+	if err != nil || code < 100 || code > 599 {
+		return 0
+	}
+	return code
+}
+
+// contains returns true if vals contains v.
+func contains(vals []string, v string) bool {
+	for _, val := range vals {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1122 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+)
+
+// pathParamRegex matches the path parameters of the document paths.
+var pathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+// responseNames lists the names of the built-in responses indexed by status code.
+var responseNames = make(map[int]string)
+
+func init() {
+	for name, r := range design.NewAPIDefinition().DefaultResponses {
+		responseNames[r.Status] = name
+	}
+}
+
+// write returns the source code of the design package.
+func (i *importer) write() []byte {
+	body := i.capture(func() {
+		i.writeAPI()
+		i.writeSecuritySchemes()
+		for _, res := range i.resources {
+			i.writeResource(res)
+		}
+		for _, d := range i.sortedDefinitions() {
+			switch d.kind {
+			case typeDef:
+				i.writeType(d)
+			case mediaDef:
+				i.writeMediaType(d)
+			}
+		}
+	})
+	var buf bytes.Buffer
+	source := ""
+	if i.opts.Source != "" {
+		source = " from " + i.opts.Source
+	}
+	fmt.Fprintf(&buf, "// Package %s contains the API design imported%s with goagen import.\n", i.opts.Package, source)
+	fmt.Fprintf(&buf, "// The TODO comments list the constructs that could not be imported.\n")
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n", i.opts.Package)
+	if i.usesDesign {
+		fmt.Fprintf(&buf, "\t. \"github.com/goadesign/goa/design\"\n")
+	}
+	fmt.Fprintf(&buf, "\t. \"github.com/goadesign/goa/design/apidsl\"\n)\n\n")
+	buf.WriteString(body)
+	return buf.Bytes()
+}
+
+// writeAPI writes the API definition.
+func (i *importer) writeAPI() {
+	inf := i.doc.Info
+	if inf == nil {
+		inf = &info{}
+	}
+	name := snakeName(inf.Title)
+	if name == "" {
+		name = "api"
+	}
+	i.line("var _ = API(%s, func() {", quote(name))
+	i.call("Title", inf.Title)
+	i.call("Description", inf.Description)
+	i.call("Version", inf.Version)
+	i.call("TermsOfService", inf.TermsOfService)
+	if c := inf.Contact; c != nil {
+		i.line("Contact(func() {")
+		i.call("Name", c.Name)
+		i.call("Email", c.Email)
+		i.call("URL", c.URL)
+		i.line("})")
+	}
+	if l := inf.License; l != nil {
+		i.line("License(func() {")
+		i.call("Name", l.Name)
+		i.call("URL", l.URL)
+		i.line("})")
+	}
+	if d := i.doc.ExternalDocs; d != nil {
+		i.line("Docs(func() {")
+		i.call("Description", d.Description)
+		i.call("URL", d.URL)
+		i.line("})")
+	}
+	i.call("Host", i.doc.Host)
+	if len(i.doc.Schemes) > 0 {
+		i.line("Scheme(%s)", quoteAll(i.doc.Schemes))
+	}
+	i.call("BasePath", i.doc.BasePath)
+	i.writeEncodings("Consumes", i.doc.Consumes)
+	i.writeEncodings("Produces", i.doc.Produces)
+	i.writeSecurity(i.doc.Security)
+	i.line("})\n")
+}
+
+// writeEncodings writes the Consumes or Produces DSL for the given MIME types.
+func (i *importer) writeEncodings(fn string, mimeTypes []string) {
+	var known []string
+	for _, m := range mimeTypes {
+		if design.HasKnownEncoder(m) {
+			known = append(known, m)
+		} else {
+			i.todo("%s: MIME type %q has no known encoder, use %s with Package to declare one.", strings.ToLower(fn), m, fn)
+		}
+	}
+	if len(known) > 0 {
+		i.line("%s(%s)", fn, quoteAll(known))
+	}
+}
+
+// writeSecuritySchemes writes the security scheme definitions.
+func (i *importer) writeSecuritySchemes() {
+	for _, n := range sortedKeys(i.doc.SecurityDefinitions) {
+		s := i.doc.SecurityDefinitions[n]
+		v, ok := i.schemes[n]
+		if !ok {
+			i.todo("security scheme %q of type %q is not supported.", n, s.Type)
+			i.line("")
+			continue
+		}
+		var fn string
+		switch s.Type {
+		case "basic":
+			fn = "BasicAuthSecurity"
+		case "apiKey":
+			fn = "APIKeySecurity"
+		case "bearer":
+			fn = "JWTSecurity"
+		case "oauth2":
+			fn = "OAuth2Security"
+		}
+		body := i.capture(func() { i.securitySchemeBody(s) })
+		if body == "" {
+			i.line("var %s = %s(%s)\n", v, fn, quote(n))
+			continue
+		}
+		i.line("var %s = %s(%s, func() {", v, fn, quote(n))
+		i.buf.WriteString(body)
+		i.line("})\n")
+	}
+}
+
+// securitySchemeBody writes the DSL defining the security scheme s.
+func (i *importer) securitySchemeBody(s *securityScheme) {
+	i.call("Description", s.Description)
+	switch s.Type {
+	case "apiKey":
+		switch s.In {
+		case "header":
+			i.call("Header", s.Name)
+		case "query":
+			i.call("Query", s.Name)
+		default:
+			i.todo("API key in %q is not supported.", s.In)
+		}
+	case "bearer":
+		i.call("Header", "Authorization")
+	case "oauth2":
+		switch s.Flow {
+		case "accessCode":
+			i.line("AccessCodeFlow(%s, %s)", quote(s.AuthorizationURL), quote(s.TokenURL))
+		case "implicit":
+			i.call("ImplicitFlow", s.AuthorizationURL)
+		case "password":
+			i.call("PasswordFlow", s.TokenURL)
+		case "application":
+			i.call("ApplicationFlow", s.TokenURL)
+		default:
+			i.todo("OAuth2 flow %q is not supported.", s.Flow)
+		}
+		for _, scope := range sortedKeys(s.Scopes) {
+			if desc := s.Scopes[scope]; desc != "" {
+				i.line("Scope(%s, %s)", quote(scope), quote(desc))
+			} else {
+				i.call("Scope", scope)
+			}
+		}
+	}
+}
+
+// writeSecurity writes the Security DSL for the given security requirements.
+func (i *importer) writeSecurity(reqs []map[string][]string) {
+	for _, req := range reqs {
+		if len(req) == 0 {
+			i.todo("anonymous access is allowed as an alternative to the security requirements.")
+			continue
+		}
+		var vars, scopes []string
+		for _, n := range sortedKeys(req) {
+			v, ok := i.schemes[n]
+			if !ok {
+				i.todo("security requirement %q references an unsupported security scheme.", n)
+				vars = nil
+				break
+			}
+			vars = append(vars, v)
+			for _, s := range req[n] {
+				if !contains(scopes, s) {
+					scopes = append(scopes, s)
+				}
+			}
+		}
+		if len(vars) == 0 {
+			continue
+		}
+		if len(scopes) == 0 {
+			i.line("Security(%s)", strings.Join(vars, ", "))
+			continue
+		}
+		i.line("Security(%s, func() {", strings.Join(vars, ", "))
+		for _, s := range scopes {
+			i.call("Scope", s)
+		}
+		i.line("})")
+	}
+}
+
+// writeResource writes the definition of the resource and its actions.
+func (i *importer) writeResource(res *resource) {
+	i.line("var _ = Resource(%s, func() {", quote(res.name))
+	for _, t := range i.doc.Tags {
+		if snakeName(t.Name) == res.name || t.Name == res.name {
+			i.call("Description", t.Description)
+			break
+		}
+	}
+	i.call("BasePath", res.basePath)
+	security := res.security()
+	if security != nil {
+		i.writeSecurity(*security)
+	}
+	for _, a := range res.actions {
+		i.writeAction(res, a, security == nil)
+	}
+	i.line("})\n")
+}
+
+// security returns the security requirements shared by all the actions of the resource, nil if
+// the actions have different requirements.
+func (res *resource) security() *[]map[string][]string {
+	var shared *[]map[string][]string
+	for _, a := range res.actions {
+		sec := a.routes[0].op.Security
+		if sec == nil || len(*sec) == 0 {
+			return nil
+		}
+		if shared == nil {
+			shared = sec
+			continue
+		}
+		b1, _ := json.Marshal(*shared)
+		b2, _ := json.Marshal(*sec)
+		if !bytes.Equal(b1, b2) {
+			return nil
+		}
+	}
+	return shared
+}
+
+// writeAction writes the definition of the action.
+func (i *importer) writeAction(res *resource, a *action, withSecurity bool) {
+	op := a.routes[0].op
+	i.line("Action(%s, func() {", quote(a.name))
+	i.call("Description", op.Description)
+	if op.Summary != "" && op.Summary != a.name+" "+res.name {
+		i.line("Metadata(\"swagger:summary\", %s)", quote(op.Summary))
+	}
+	if op.Deprecated {
+		i.line("Deprecated()")
+	}
+	routes := make([]string, len(a.routes))
+	for k, r := range a.routes {
+		path := strings.TrimPrefix(r.path, res.basePath)
+		path = pathParamRegex.ReplaceAllString(path, ":$1")
+		routes[k] = fmt.Sprintf("%s(%s)", r.method, quote(path))
+	}
+	i.line("Routing(%s)", strings.Join(routes, ", "))
+	if withSecurity && op.Security != nil {
+		if len(*op.Security) == 0 {
+			if len(i.doc.Security) > 0 {
+				i.line("NoSecurity()")
+			}
+		} else {
+			i.writeSecurity(*op.Security)
+		}
+	}
+	i.writeParams(op)
+	i.writePayload(op)
+	for _, code := range responseCodes(op.Responses) {
+		i.writeResponse(code, op.Responses[code])
+	}
+	if len(op.Callbacks) > 0 {
+		i.todo("callbacks %s are not supported.", quoteAll(sortedCallbacks(op.Callbacks)))
+	}
+	i.line("})")
+}
+
+// writeParams writes the Params and Headers DSL of the operation.
+func (i *importer) writeParams(op *operation) {
+	var params, headers []*parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path", "query":
+			params = append(params, p)
+		case "header":
+			switch strings.ToLower(p.Name) {
+			case "accept", "content-type", "authorization":
+			default:
+				headers = append(headers, p)
+			}
+		case "body", "formData":
+		default:
+			i.todo("%s parameter %q is not supported.", p.In, p.Name)
+		}
+	}
+	for _, block := range []struct {
+		fn, attr string
+		params   []*parameter
+	}{{"Params", "Param", params}, {"Headers", "Header", headers}} {
+		if len(block.params) == 0 {
+			continue
+		}
+		i.line("%s(func() {", block.fn)
+		var required []string
+		for _, p := range block.params {
+			if s, _ := i.typeOf(p.Schema); s == "" {
+				i.todo("%s %q of type object is not supported.", strings.ToLower(block.attr), p.Name)
+				continue
+			}
+			desc := p.Description
+			if desc == "" {
+				desc = p.Schema.Description
+			}
+			i.attribute(block.attr, p.Name, desc, p.Schema)
+			if p.Required && p.In != "path" {
+				required = append(required, p.Name)
+			}
+		}
+		if len(required) > 0 {
+			i.line("Required(%s)", quoteAll(required))
+		}
+		i.line("})")
+	}
+}
+
+// writePayload writes the Payload DSL of the operation.
+func (i *importer) writePayload(op *operation) {
+	var (
+		body     *parameter
+		form     []*parameter
+		required bool
+	)
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "body":
+			body = p
+			required = p.Required
+		case "formData":
+			form = append(form, p)
+			required = required || p.Required
+		}
+	}
+	fn := "OptionalPayload"
+	if required {
+		fn = "Payload"
+	}
+	switch {
+	case body != nil:
+		s := body.Schema
+		if s == nil {
+			s = &schema{}
+		}
+		expr, _ := i.typeOf(s)
+		if expr != "" {
+			i.line("%s(%s)", fn, expr)
+		} else {
+			i.line("%s(func() {", fn)
+			i.validations(i.flatten(s))
+			i.line("})")
+		}
+	case len(form) > 0:
+		i.line("%s(func() {", fn)
+		var req []string
+		for _, p := range form {
+			i.attribute("Attribute", p.Name, p.Description, p.Schema)
+			if p.Required {
+				req = append(req, p.Name)
+			}
+		}
+		if len(req) > 0 {
+			i.line("Required(%s)", quoteAll(req))
+		}
+		i.line("})")
+	default:
+		return
+	}
+	if len(form) > 0 || len(op.Consumes) > 0 && strings.HasPrefix(op.Consumes[0], "multipart/form-data") {
+		i.line("MultipartForm()")
+	}
+}
+
+// writeResponse writes the Response DSL for the response with the given status code.
+func (i *importer) writeResponse(code string, r *response) {
+	status := statusCode(code)
+	if status == 0 {
+		i.todo("%q response is not supported.", code)
+		return
+	}
+	i.usesDesign = true
+	name := responseName(status)
+	_, standard := responseNames[status]
+	if !standard {
+		name = quote(name)
+	}
+	media, view := i.responseMedia(r.Schema)
+	desc := r.Description
+	if desc == http.StatusText(status) {
+		desc = ""
+	}
+	if standard && desc == "" && len(r.Headers) == 0 && view == design.DefaultView {
+		if media == "" {
+			i.line("Response(%s)", name)
+		} else {
+			i.line("Response(%s, %s)", name, media)
+		}
+		return
+	}
+	i.line("Response(%s, func() {", name)
+	if !standard {
+		i.line("Status(%d)", status)
+	}
+	i.call("Description", desc)
+	if media != "" {
+		if view == design.DefaultView {
+			i.line("Media(%s)", media)
+		} else {
+			i.line("Media(%s, %s)", media, quote(view))
+		}
+	}
+	if len(r.Headers) > 0 {
+		i.line("Headers(func() {")
+		for _, n := range sortedKeys(r.Headers) {
+			h := r.Headers[n]
+			i.attribute("Header", n, h.Schema.Description, h.Schema)
+		}
+		i.line("})")
+	}
+	i.line("})")
+}
+
+// responseMedia returns the media type expression and view of a response body schema.
+func (i *importer) responseMedia(s *schema) (string, string) {
+	if s == nil {
+		return "", design.DefaultView
+	}
+	if s.Ref != "" {
+		d := i.defs[refName(s.Ref)]
+		if d == nil {
+			i.todo("response body references unknown definition %q.", s.Ref)
+			return "", design.DefaultView
+		}
+		switch d.kind {
+		case mediaDef:
+			return i.ref(d), design.DefaultView
+		case projectedDef:
+			return i.ref(d.base), d.view
+		case collectionDef:
+			return fmt.Sprintf("CollectionOf(%s)", i.ref(d.base)), d.view
+		case errorDef:
+			return "ErrorMedia", design.DefaultView
+		case aliasDef:
+			return i.responseMedia(d.schema)
+		}
+	}
+	if i.kind(s) == "array" && s.Items != nil && s.Items.Ref != "" {
+		if elem, view := i.responseMedia(s.Items); elem != "" && !strings.HasPrefix(elem, "CollectionOf") {
+			return fmt.Sprintf("CollectionOf(%s)", elem), view
+		}
+	}
+	i.todo("response body of type %q is not supported, the DSL requires a media type.", i.kind(s))
+	return "", design.DefaultView
+}
+
+// writeType writes the Type definition of d.
+func (i *importer) writeType(d *definition) {
+	i.current = d
+	defer func() { i.current = nil }()
+	s := i.flatten(d.schema)
+	i.line("var %s = Type(%s, func() {", d.varName, quote(d.name))
+	i.call("Description", s.Description)
+	if u := i.union(s); u != nil {
+		i.writeUnion(u)
+	} else {
+		i.validations(s)
+	}
+	i.line("})\n")
+}
+
+// writeUnion writes the OneOf DSL defining the variants of u.
+func (i *importer) writeUnion(u *union) {
+	i.line("OneOf(func() {")
+	for _, v := range u.variants {
+		i.attribute("Attribute", v.name, "", v.schema)
+	}
+	if u.discriminator == "" {
+		i.line("})")
+		return
+	}
+	i.line("}, Discriminator(%s))", quote(u.discriminator))
+}
+
+// writeMediaType writes the MediaType definition of d.
+func (i *importer) writeMediaType(d *definition) {
+	i.current = d
+	defer func() { i.current = nil }()
+	s := i.flatten(d.schema)
+	i.line("var %s = MediaType(%s, func() {", d.varName, quote(d.identifier))
+	i.call("Description", viewDescriptionRegex.ReplaceAllString(s.Description, ""))
+	if mediaTypeName(d.identifier) != d.name {
+		i.call("TypeName", d.name)
+	}
+	i.line("Attributes(func() {")
+	i.validations(s)
+	i.line("})")
+	views := make([]string, 0, len(d.views))
+	for v := range d.views {
+		if v != design.DefaultView {
+			views = append(views, v)
+		}
+	}
+	sort.Strings(views)
+	for _, v := range append([]string{design.DefaultView}, views...) {
+		i.line("View(%s, func() {", quote(v))
+		for _, a := range d.views[v] {
+			i.call("Attribute", a)
+		}
+		i.line("})")
+	}
+	i.line("})\n")
+}
+
+// attribute writes the DSL defining an attribute. fn is the DSL function: Attribute, Param or
+// Header.
+func (i *importer) attribute(fn, name, desc string, s *schema) {
+	if s == nil {
+		s = &schema{}
+	}
+	if s.Ref != "" && i.defs[refName(s.Ref)] == nil {
+		i.todo("%s %q references unknown definition %q.", strings.ToLower(fn), name, s.Ref)
+	}
+	expr, resolved := i.typeOf(s)
+	if desc == "" && s.Ref == "" {
+		desc = resolved.Description
+	}
+	args := []string{quote(name)}
+	body := i.capture(func() {
+		if expr == "" {
+			i.call("Description", desc)
+		}
+		if fn == "Attribute" && i.nullable(resolved) {
+			i.line("Nullable()")
+			nn := *resolved
+			nn.Nullable, nn.XNullable, nn.Type = false, false, nonNull(nn.Type)
+			resolved = &nn
+		}
+		i.validations(resolved)
+	})
+	if expr != "" {
+		args = append(args, expr)
+		if desc != "" {
+			args = append(args, quote(desc))
+		}
+	}
+	if body == "" {
+		i.line("%s(%s)", fn, strings.Join(args, ", "))
+		return
+	}
+	i.line("%s(%s, func() {", fn, strings.Join(args, ", "))
+	i.buf.WriteString(body)
+	i.line("})")
+}
+
+// typeOf returns the expression of the data type of the attribute described by s and the schema
+// holding its validations. The expression is empty for objects defined inline.
+func (i *importer) typeOf(s *schema) (string, *schema) {
+	s = i.flatten(s)
+	if s.Ref != "" {
+		d := i.defs[refName(s.Ref)]
+		if d == nil {
+			i.usesDesign = true
+			return "Any", s
+		}
+		switch d.kind {
+		case typeDef, mediaDef:
+			return i.ref(d), s
+		case projectedDef:
+			return i.ref(d.base), s
+		case collectionDef:
+			return fmt.Sprintf("CollectionOf(%s)", i.ref(d.base)), s
+		case errorDef:
+			i.usesDesign = true
+			return "ErrorMedia", s
+		case aliasDef:
+			alias := *d.schema
+			if s.Description != "" {
+				alias.Description = s.Description
+			}
+			return i.typeOf(&alias)
+		}
+		return "Any", s
+	}
+	i.usesDesign = true
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		return "Any", s
+	}
+	switch i.kind(s) {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "DateTime", s
+		case "uuid":
+			return "UUID", s
+		case "binary":
+			return "File", s
+		}
+		return "String", s
+	case "integer":
+		return "Integer", s
+	case "number":
+		return "Number", s
+	case "boolean":
+		return "Boolean", s
+	case "file":
+		return "File", s
+	case "array":
+		if s.Items == nil {
+			return "ArrayOf(Any)", s
+		}
+		return fmt.Sprintf("ArrayOf(%s)", i.elemType(s.Items)), s
+	case "object":
+		if len(s.Properties) > 0 {
+			return "", s
+		}
+		if ap := s.AdditionalProperties; ap != nil && ap.Schema != nil {
+			return fmt.Sprintf("HashOf(String, %s)", i.elemType(ap.Schema)), s
+		}
+		return "HashOf(String, Any)", s
+	}
+	return "Any", s
+}
+
+// elemType returns the expression of the data type of an array element or hash value
+// including its validations.
+func (i *importer) elemType(s *schema) string {
+	expr, resolved := i.typeOf(s)
+	if expr == "" {
+		// Hoisted objects are references, this is an object without properties.
+		return "Any"
+	}
+	body := i.capture(func() { i.validations(resolved) })
+	if body == "" {
+		return expr
+	}
+	return fmt.Sprintf("%s, func() {\n%s}", expr, body)
+}
+
+// ref returns the expression referencing the type or media type definition d. The expression
+// uses the name of the type when d is part of a reference cycle with the definition being
+// written, Go does not allow initialization cycles.
+func (i *importer) ref(d *definition) string {
+	if c := i.current; c != nil && (c == d || i.reach[d][c]) {
+		if d.kind == mediaDef {
+			return quote(d.identifier)
+		}
+		return quote(d.name)
+	}
+	return d.varName
+}
+
+// validations writes the validations, default value, example and child attributes of the
+// schema s. Unsupported constructs produce TODO comments.
+func (i *importer) validations(s *schema) {
+	if s.Ref != "" {
+		return
+	}
+	kind := i.kind(s)
+	if _, ok := requiredGroup(s.OneOf); len(s.OneOf) > 0 && !ok {
+		i.todo("oneOf is not supported, the attribute accepts any value.")
+	}
+	if len(s.AnyOf) > 0 {
+		i.todo("anyOf is not supported, the attribute accepts any value.")
+	}
+	if _, ok := exclusiveGroup(s.Not); s.Not != nil && !ok {
+		i.todo("not is not supported.")
+	}
+	if s.Discriminator != nil {
+		i.todo("discriminator is not supported.")
+	}
+	if s.Nullable || s.XNullable || contains(s.Type, "null") {
+		i.todo("the attribute is nullable.")
+	}
+	if s.ReadOnly {
+		i.todo("the attribute is read-only.")
+	}
+	if s.WriteOnly {
+		i.todo("the attribute is write-only.")
+	}
+	if len(s.Enum) > 0 {
+		i.literalCall("Enum", "enum", s, s.Enum...)
+	}
+	if s.Const != nil {
+		i.literalCall("Enum", "const", s, s.Const)
+	}
+	switch {
+	case s.Format == "":
+	case kind == "string":
+		switch s.Format {
+		case "date-time", "uuid", "binary":
+		default:
+			if contains(apidsl.SupportedValidationFormats, s.Format) {
+				i.call("Format", s.Format)
+			} else {
+				i.todo("format %q is not supported.", s.Format)
+			}
+		}
+	case kind == "integer":
+		switch s.Format {
+		case "int64":
+			// goa documents all integers as int64, only string encoded values use the format.
+			if stringEncoded(s) {
+				i.call("Format", s.Format)
+			}
+		case "int32", "uint", "uint32", "uint64":
+			i.call("Format", s.Format)
+		default:
+			i.todo("format %q is not supported.", s.Format)
+		}
+	case kind == "number":
+		switch s.Format {
+		case "double":
+		case "float", "float32":
+			i.call("Format", "float32")
+		case "decimal":
+			i.call("Format", s.Format)
+		default:
+			i.todo("format %q is not supported.", s.Format)
+		}
+	default:
+		i.todo("format %q is not supported.", s.Format)
+	}
+	if s.Pattern != "" {
+		i.call("Pattern", s.Pattern)
+	}
+	if kind == "integer" || kind == "number" {
+		if v, ok := exclusiveBound(s.ExclusiveMinimum, s.Minimum); ok {
+			i.line("ExclusiveMinimum(%s)", v)
+		} else if s.Minimum != nil {
+			i.line("Minimum(%s)", number(*s.Minimum))
+		}
+		if v, ok := exclusiveBound(s.ExclusiveMaximum, s.Maximum); ok {
+			i.line("ExclusiveMaximum(%s)", v)
+		} else if s.Maximum != nil {
+			i.line("Maximum(%s)", number(*s.Maximum))
+		}
+		if s.MultipleOf != nil {
+			if kind == "integer" && *s.MultipleOf != math.Trunc(*s.MultipleOf) {
+				i.todo("multipleOf %s is not supported.", number(*s.MultipleOf))
+			} else {
+				i.line("MultipleOf(%s)", number(*s.MultipleOf))
+			}
+		}
+	}
+	minLength, maxLength := s.MinLength, s.MaxLength
+	if kind == "array" {
+		if s.MinItems != nil {
+			minLength = s.MinItems
+		}
+		if s.MaxItems != nil {
+			maxLength = s.MaxItems
+		}
+	}
+	if kind == "string" || kind == "array" {
+		if minLength != nil {
+			i.line("MinLength(%d)", *minLength)
+		}
+		if maxLength != nil {
+			i.line("MaxLength(%d)", *maxLength)
+		}
+	}
+	if kind == "array" && s.UniqueItems {
+		i.line("UniqueItems()")
+	}
+	if kind == "object" && len(s.Properties) == 0 {
+		if s.MinProperties != nil {
+			i.line("MinProperties(%d)", *s.MinProperties)
+		}
+		if s.MaxProperties != nil {
+			i.line("MaxProperties(%d)", *s.MaxProperties)
+		}
+	} else {
+		if s.MinProperties != nil {
+			i.todo("minProperties %d is not supported.", *s.MinProperties)
+		}
+		if s.MaxProperties != nil {
+			i.todo("maxProperties %d is not supported.", *s.MaxProperties)
+		}
+	}
+	if s.Default != nil {
+		i.literalCall("Default", "default value", s, s.Default)
+	}
+	if s.Example != nil {
+		switch kind {
+		case "string", "integer", "number", "boolean":
+			if lit, ok := i.literal(s.Example, s); ok {
+				i.line("Example(%s)", lit)
+			}
+		}
+	}
+	if len(s.Properties) == 0 {
+		return
+	}
+	if ap := s.AdditionalProperties; ap != nil && (ap.Schema != nil || ap.Allowed) {
+		i.todo("additional properties are not supported.")
+	}
+	for _, n := range sortedKeys(s.Properties) {
+		i.attribute("Attribute", n, "", s.Properties[n])
+	}
+	if len(s.Required) > 0 {
+		i.line("Required(%s)", quoteAll(s.Required))
+	}
+	i.crossFieldRules(s)
+}
+
+// crossFieldRules writes the OneOfRequired, MutuallyExclusive and DependentRequired validations
+// described by the oneOf, not and dependentRequired fields of s and of the allOf schemas merged
+// into s.
+func (i *importer) crossFieldRules(s *schema) {
+	if names, ok := requiredGroup(s.OneOf); ok {
+		i.line("OneOfRequired(%s)", quoteAll(names))
+	}
+	if names, ok := exclusiveGroup(s.Not); ok {
+		i.line("MutuallyExclusive(%s)", quoteAll(names))
+	}
+	for _, n := range sortedKeys(s.DependentRequired) {
+		i.line("DependentRequired(%q, %s)", n, quoteAll(s.DependentRequired[n]))
+	}
+	for _, part := range s.merged {
+		i.crossFieldRules(part)
+	}
+}
+
+// requiredGroup returns the property names required by the given schemas if each schema only
+// requires one property. This is how oneOf describes a OneOfRequired validation.
+func requiredGroup(schemas []*schema) ([]string, bool) {
+	if len(schemas) < 2 {
+		return nil, false
+	}
+	names := make([]string, len(schemas))
+	for k, sub := range schemas {
+		if !requiresOnly(sub, 1) {
+			return nil, false
+		}
+		names[k] = sub.Required[0]
+	}
+	return names, true
+}
+
+// exclusiveGroup returns the property names of the not schema s if it describes a
+// MutuallyExclusive validation: s requires two properties or is the anyOf of schemas that each
+// require two properties.
+func exclusiveGroup(s *schema) ([]string, bool) {
+	if s == nil {
+		return nil, false
+	}
+	if requiresOnly(s, 2) {
+		return s.Required, true
+	}
+	if len(s.AnyOf) == 0 || s.Ref != "" || len(s.Properties) > 0 || len(s.Required) > 0 {
+		return nil, false
+	}
+	var names []string
+	for _, sub := range s.AnyOf {
+		if !requiresOnly(sub, 2) {
+			return nil, false
+		}
+		for _, n := range sub.Required {
+			if !contains(names, n) {
+				names = append(names, n)
+			}
+		}
+	}
+	return names, true
+}
+
+// requiresOnly returns true if the schema s only requires n properties.
+func requiresOnly(s *schema, n int) bool {
+	return s != nil && s.Ref == "" && len(s.Type) == 0 && len(s.Properties) == 0 &&
+		len(s.Required) == n && len(s.AllOf) == 0 && len(s.AnyOf) == 0 && len(s.OneOf) == 0 && s.Not == nil
+}
+
+// literalCall writes a call to the DSL function fn with the Go literals of the given values.
+// It writes a TODO comment if a value cannot be represented.
+func (i *importer) literalCall(fn, what string, s *schema, vals ...interface{}) {
+	lits := make([]string, len(vals))
+	for k, v := range vals {
+		lit, ok := i.literal(v, s)
+		if !ok {
+			b, _ := json.Marshal(vals)
+			i.todo("%s %s is not supported.", what, b)
+			return
+		}
+		lits[k] = lit
+	}
+	i.line("%s(%s)", fn, strings.Join(lits, ", "))
+}
+
+// literal returns the Go literal of the value v of an attribute described by s.
+func (i *importer) literal(v interface{}, s *schema) (string, bool) {
+	_, s = i.typeOf(s)
+	kind := i.kind(s)
+	switch actual := v.(type) {
+	case string:
+		if kind == "string" || kind == "" {
+			return quote(actual), true
+		}
+		if _, err := strconv.ParseFloat(actual, 64); err == nil && stringEncoded(s) {
+			return i.literal(json.Number(actual), &schema{Type: []string{kind}})
+		}
+	case json.Number:
+		if kind == "integer" {
+			if _, err := actual.Int64(); err == nil {
+				return actual.String(), true
+			}
+		} else if kind == "number" || kind == "" {
+			return actual.String(), true
+		}
+	case bool:
+		if kind == "boolean" || kind == "" {
+			return strconv.FormatBool(actual), true
+		}
+	case []interface{}:
+		if kind == "array" && s.Items != nil {
+			elems := make([]string, len(actual))
+			for k, e := range actual {
+				lit, ok := i.literal(e, s.Items)
+				if !ok {
+					return "", false
+				}
+				elems[k] = lit
+			}
+			return fmt.Sprintf("[]interface{}{%s}", strings.Join(elems, ", ")), true
+		}
+	}
+	return "", false
+}
+
+// kind returns the JSON type of the values described by s, the empty string if s accepts any
+// value.
+func (i *importer) kind(s *schema) string {
+	if stringEncoded(s) {
+		if s.Format == "int64" {
+			return "integer"
+		}
+		return "number"
+	}
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	if len(s.Properties) > 0 || s.AdditionalProperties != nil {
+		return "object"
+	}
+	if s.Items != nil {
+		return "array"
+	}
+	return ""
+}
+
+// nullable returns true if the nullable attribute described by s can be defined with the Nullable
+// DSL, that is if it is a primitive or an array of primitives without default value.
+func (i *importer) nullable(s *schema) bool {
+	if !(s.Nullable || s.XNullable || contains(s.Type, "null")) || s.Default != nil {
+		return false
+	}
+	primitive := func(k string) bool {
+		return k == "string" || k == "integer" || k == "number" || k == "boolean"
+	}
+	switch k := i.kind(s); {
+	case primitive(k):
+		return true
+	case k == "array":
+		return s.Items != nil && s.Items.Ref == "" && primitive(i.kind(s.Items))
+	}
+	return false
+}
+
+// nonNull returns the given type list without "null".
+func nonNull(types typeList) typeList {
+	var res typeList
+	for _, t := range types {
+		if t != "null" {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// stringEncoded returns true if s describes numbers encoded as JSON strings, that is int64 object
+// fields and decimal values.
+func stringEncoded(s *schema) bool {
+	return contains(s.Type, "string") && (s.Format == "int64" || s.Format == "decimal")
+}
+
+// call writes a call to the DSL function fn with the quoted string argument arg unless arg is
+// empty.
+func (i *importer) call(fn, arg string) {
+	if arg != "" {
+		i.line("%s(%s)", fn, quote(arg))
+	}
+}
+
+// todo writes a TODO comment.
+func (i *importer) todo(format string, args ...interface{}) {
+	i.line("// TODO: "+format, args...)
+}
+
+// line writes a line of code, the code is indented by gofmt.
+func (i *importer) line(format string, args ...interface{}) {
+	fmt.Fprintf(i.buf, format+"\n", args...)
+}
+
+// capture returns the code written by fn.
+func (i *importer) capture(fn func()) string {
+	orig := i.buf
+	i.buf = new(bytes.Buffer)
+	defer func() { i.buf = orig }()
+	fn()
+	return i.buf.String()
+}
+
+// responseName returns the name of the built-in response with the given status code if any, a
+// name derived from the status code otherwise.
+func responseName(status int) string {
+	if name, ok := responseNames[status]; ok {
+		return name
+	}
+	return fmt.Sprintf("Status%d", status)
+}
+
+// mediaTypeName computes the type name the MediaType DSL derives from the identifier.
+func mediaTypeName(identifier string) string {
+	last := identifier[strings.LastIndex(identifier, "/")+1:]
+	if idx := strings.Index(last, "+"); idx > 0 {
+		last = last[:idx]
+	}
+	elems := strings.Split(strings.TrimPrefix(last, "vnd."), ".")
+	for k, e := range elems {
+		elems[k] = strings.Title(e)
+	}
+	return strings.Join(elems, "")
+}
+
+// exclusiveBound returns the Go literal of the exclusive bound described by the value of an
+// exclusiveMinimum or exclusiveMaximum field: a boolean modifying bound in OpenAPI 3.0 and Swagger
+// or the bound itself in OpenAPI 3.1. It returns false if the bound is not exclusive.
+func exclusiveBound(v interface{}, bound *float64) (string, bool) {
+	switch actual := v.(type) {
+	case bool:
+		if actual && bound != nil {
+			return number(*bound), true
+		}
+	case json.Number:
+		return actual.String(), true
+	}
+	return "", false
+}
+
+// number returns the Go literal of the number f.
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// quote returns the Go literal of the string s, a raw string literal is used for multi-line
+// strings.
+func quote(s string) string {
+	if strings.Contains(s, "\n") && !strings.Contains(s, "`") && !strings.Contains(s, "\r") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// quoteAll returns the comma separated Go literals of the strings.
+func quoteAll(vals []string) string {
+	quoted := make([]string, len(vals))
+	for k, v := range vals {
+		quoted[k] = quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// sortedCallbacks returns the sorted names of the callbacks.
+func sortedCallbacks(callbacks map[string]interface{}) []string {
+	names := make([]string, 0, len(callbacks))
+	for n := range callbacks {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,34 @@
+/*
+Package ir provides the intermediate representation of the code produced by the goagen generators.
+
+The "app", "client", "main" and "mock" generators describe each file they produce with a File listing the
+templates, called sections, that render the file content. Once all the files are described the
+generators call Render which runs the hooks registered for the generator and renders the files.
+The controller files produced by the "main" and "controller" generators run the hooks registered
+for Controller.
+
+Hooks make it possible for third-party packages to modify the generated code without forking the
+generators. A hook may add, remove or modify files, sections and imports. For example the
+following hook adds a log statement at the beginning of each Mount<Resource>Controller function
+generated by "goagen app":
+
+    func init() {
+        ir.RegisterHook(ir.App, func(files []*ir.File) ([]*ir.File, error) {
+            for _, f := range files {
+                for _, s := range f.Section("mount") {
+                    s.Source = strings.Replace(s.Source, "\tinitService(service)\n",
+                        "\tinitService(service)\n\tservice.LogInfo(\"mount\", \"plugin\", \"audit\")\n", 1)
+                }
+            }
+            return files, nil
+        })
+    }
+
+Packages that register hooks are compiled into the generator with the goagen "--hook" flag:
+
+    goagen app -d github.com/goadesign/goa-cellar/design --hook github.com/me/audit
+
+Plugins run with "goagen gen" may also register hooks before invoking one of the generators
+directly.
+*/
+package ir
@@ -0,0 +1,149 @@
+package ir
+
+import (
+	"path/filepath"
+	"text/template"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+)
+
+type (
+	// Writer is implemented by File, which records the sections of a file, and by
+	// codegen.SourceFile, which renders them right away.
+	Writer interface {
+		WriteHeader(title, pack string, imports []*codegen.ImportSpec) error
+		ExecuteTemplate(name, source string, funcMap template.FuncMap, data interface{}) error
+	}
+
+	// File describes a generated file as a list of sections rendered in order.
+	File struct {
+		// Path is the path to the generated file.
+		Path string
+		// Sections lists the file sections in rendering order. The first section is
+		// usually the header section created by WriteHeader.
+		Sections []*Section
+	}
+
+	// Section is a part of a generated file rendered by executing a template.
+	Section struct {
+		// Name identifies the kind of section, e.g. "header" or "mount". Several sections
+		// of the same file may share the same name.
+		Name string
+		// Source is the template source.
+		Source string
+		// FuncMap lists the functions used by the template in addition to
+		// codegen.DefaultFuncMap.
+		FuncMap template.FuncMap
+		// Data is the template input.
+		Data interface{}
+	}
+
+	// Header is the input of the "header" section template.
+	Header struct {
+		// Title is the title written in the "Code generated" comment, there is no comment
+		// if empty.
+		Title string
+		// ToolVersion is the version of goagen.
+		ToolVersion string
+		// Pkg is the name of the Go package.
+		Pkg string
+		// Imports lists the file imports.
+		Imports []*codegen.ImportSpec
+	}
+)
+
+// HeaderSection is the name of the header sections.
+const HeaderSection = "header"
+
+// NewFile returns a file with the given path and no section.
+func NewFile(path string) *File {
+	return &File{Path: path}
+}
+
+// WriteHeader appends a header section to the file. It has the same signature as
+// codegen.SourceFile.WriteHeader so that File may be used wherever a generator used to write the
+// header of a source file.
+func (f *File) WriteHeader(title, pack string, imports []*codegen.ImportSpec) error {
+	f.Sections = append(f.Sections, &Section{
+		Name:   HeaderSection,
+		Source: codegen.HeaderT,
+		Data: &Header{
+			Title:       title,
+			ToolVersion: version.String(),
+			Pkg:         pack,
+			Imports:     imports,
+		},
+	})
+	return nil
+}
+
+// ExecuteTemplate appends a section to the file. It has the same signature as
+// codegen.SourceFile.ExecuteTemplate but the template is only executed when the file is rendered.
+func (f *File) ExecuteTemplate(name, source string, funcMap template.FuncMap, data interface{}) error {
+	f.Sections = append(f.Sections, &Section{Name: name, Source: source, FuncMap: funcMap, Data: data})
+	return nil
+}
+
+// Write appends a section that renders the given code verbatim. It implements io.Writer.
+func (f *File) Write(b []byte) (int, error) {
+	f.Sections = append(f.Sections, &Section{Name: "code", Source: "{{ . }}", Data: string(b)})
+	return len(b), nil
+}
+
+// Section returns the sections with the given name in rendering order.
+func (f *File) Section(name string) []*Section {
+	var sections []*Section
+	for _, s := range f.Sections {
+		if s.Name == name {
+			sections = append(sections, s)
+		}
+	}
+	return sections
+}
+
+// InsertAfter inserts the given sections right after the section s. The sections are appended to
+// the file if s is not one of its sections.
+func (f *File) InsertAfter(s *Section, sections ...*Section) {
+	for i, sec := range f.Sections {
+		if sec == s {
+			res := make([]*Section, 0, len(f.Sections)+len(sections))
+			res = append(res, f.Sections[:i+1]...)
+			res = append(res, sections...)
+			f.Sections = append(res, f.Sections[i+1:]...)
+			return
+		}
+	}
+	f.Sections = append(f.Sections, sections...)
+}
+
+// AddImport adds the given imports to the header sections of the file. Imports that are not used
+// by the rendered code are removed when Go source files are formatted.
+func (f *File) AddImport(imports ...*codegen.ImportSpec) {
+	for _, s := range f.Section(HeaderSection) {
+		if h, ok := s.Data.(*Header); ok {
+			h.Imports = append(h.Imports, imports...)
+		}
+	}
+}
+
+// Render executes the section templates and writes the result to the file. Go source files are
+// formatted and their unused imports removed.
+func (f *File) Render() (err error) {
+	file, err := codegen.SourceFileFor(f.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil && filepath.Ext(f.Path) == ".go" {
+			err = file.FormatCode()
+		}
+	}()
+	for _, s := range f.Sections {
+		if err = file.ExecuteTemplate(s.Name, s.Source, s.FuncMap, s.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
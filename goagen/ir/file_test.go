@@ -0,0 +1,86 @@
+package ir_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/ir"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File", func() {
+	var file *ir.File
+
+	BeforeEach(func() {
+		file = ir.NewFile("foo.go")
+		file.WriteHeader("Foo", "foo", []*codegen.ImportSpec{codegen.SimpleImport("fmt")})
+		file.ExecuteTemplate("decl", "var {{ . }} = 1\n", nil, "a")
+		file.ExecuteTemplate("decl", "var {{ . }} = 2\n", nil, "b")
+	})
+
+	Describe("Section", func() {
+		It("returns the sections with the given name", func() {
+			Ω(file.Section(ir.HeaderSection)).Should(HaveLen(1))
+			decls := file.Section("decl")
+			Ω(decls).Should(HaveLen(2))
+			Ω(decls[0].Data).Should(Equal("a"))
+			Ω(decls[1].Data).Should(Equal("b"))
+			Ω(file.Section("unknown")).Should(BeEmpty())
+		})
+	})
+
+	Describe("InsertAfter", func() {
+		It("inserts the sections after the given section", func() {
+			s := &ir.Section{Name: "extra"}
+			file.InsertAfter(file.Section("decl")[0], s)
+			Ω(file.Sections).Should(HaveLen(4))
+			Ω(file.Sections[2]).Should(Equal(s))
+		})
+
+		It("appends the sections if the given section is not in the file", func() {
+			s := &ir.Section{Name: "extra"}
+			file.InsertAfter(&ir.Section{}, s)
+			Ω(file.Sections).Should(HaveLen(4))
+			Ω(file.Sections[3]).Should(Equal(s))
+		})
+	})
+
+	Describe("AddImport", func() {
+		It("adds the imports to the header", func() {
+			file.AddImport(codegen.SimpleImport("strings"))
+			h := file.Section(ir.HeaderSection)[0].Data.(*ir.Header)
+			Ω(h.Imports).Should(HaveLen(2))
+			Ω(h.Imports[1].Path).Should(Equal("strings"))
+		})
+	})
+
+	Describe("Render", func() {
+		var workspace *codegen.Workspace
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			workspace, err = codegen.NewWorkspace("test")
+			Ω(err).ShouldNot(HaveOccurred())
+			dir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+			Ω(err).ShouldNot(HaveOccurred())
+			file.Path = filepath.Join(dir, "foo.go")
+			file.Write([]byte("func f() string { return fmt.Sprint(a+b) }\n"))
+		})
+
+		AfterEach(func() {
+			workspace.Delete()
+		})
+
+		It("renders the sections in order", func() {
+			Ω(file.Render()).Should(Succeed())
+			content, err := ioutil.ReadFile(file.Path)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("// Foo\n"))
+			Ω(string(content)).Should(ContainSubstring("package foo\n"))
+			Ω(string(content)).Should(ContainSubstring("import \"fmt\"\n\nvar a = 1\nvar b = 2\n\nfunc f() string { return fmt.Sprint(a + b) }\n"))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package ir
+
+// Hook is called by a generator with the files it is about to render. It returns the files to
+// render, hooks may modify the given files and add or remove files.
+type Hook func(files []*File) ([]*File, error)
+
+// Names of the generators that run hooks.
+const (
+	// App is the name of the "goagen app" generator.
+	App = "app"
+	// Client is the name of the "goagen client" generator.
+	Client = "client"
+	// Main is the name of the "goagen main" generator.
+	Main = "main"
+	// Controller is the name of the "goagen controller" generator.
+	Controller = "controller"
+	// Mock is the name of the "goagen mock" generator.
+	Mock = "mock"
+)
+
+// hooks indexes the registered hooks by generator name.
+var hooks = make(map[string][]Hook)
+
+// RegisterHook registers a hook for the generator with the given name. Hooks run in registration
+// order, they are typically registered in package init functions.
+func RegisterHook(generator string, hook Hook) {
+	hooks[generator] = append(hooks[generator], hook)
+}
+
+// ResetHooks removes all the registered hooks.
+func ResetHooks() {
+	hooks = make(map[string][]Hook)
+}
+
+// Render runs the hooks registered for the generator with the given name then renders the files.
+// It returns the paths of the files it attempted to render so that generators may clean them up
+// on error.
+func Render(generator string, files []*File) ([]string, error) {
+	var err error
+	for _, h := range hooks[generator] {
+		if files, err = h(files); err != nil {
+			return nil, err
+		}
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+		if err := f.Render(); err != nil {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,87 @@
+package ir_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/ir"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Render", func() {
+	var workspace *codegen.Workspace
+	var dir string
+	var files []*ir.File
+	var paths []string
+	var renderErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		dir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		f := ir.NewFile(filepath.Join(dir, "foo.txt"))
+		f.ExecuteTemplate("greeting", "hello {{ . }}\n", nil, "world")
+		files = []*ir.File{f}
+	})
+
+	JustBeforeEach(func() {
+		paths, renderErr = ir.Render(ir.App, files)
+	})
+
+	AfterEach(func() {
+		ir.ResetHooks()
+		workspace.Delete()
+	})
+
+	It("renders the files", func() {
+		Ω(renderErr).ShouldNot(HaveOccurred())
+		Ω(paths).Should(Equal([]string{filepath.Join(dir, "foo.txt")}))
+		content, err := ioutil.ReadFile(paths[0])
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(Equal("hello world\n"))
+	})
+
+	Context("with registered hooks", func() {
+		BeforeEach(func() {
+			ir.RegisterHook(ir.App, func(files []*ir.File) ([]*ir.File, error) {
+				files[0].Section("greeting")[0].Data = "goa"
+				return files, nil
+			})
+			ir.RegisterHook(ir.App, func(files []*ir.File) ([]*ir.File, error) {
+				s := files[0].Section("greeting")[0]
+				s.Data = s.Data.(string) + " and hooks"
+				return files, nil
+			})
+			ir.RegisterHook(ir.Client, func(files []*ir.File) ([]*ir.File, error) {
+				return nil, errors.New("client hook")
+			})
+		})
+
+		It("runs the generator hooks in registration order", func() {
+			Ω(renderErr).ShouldNot(HaveOccurred())
+			content, err := ioutil.ReadFile(paths[0])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal("hello goa and hooks\n"))
+		})
+	})
+
+	Context("with a failing hook", func() {
+		BeforeEach(func() {
+			ir.RegisterHook(ir.App, func(files []*ir.File) ([]*ir.File, error) {
+				return nil, errors.New("boom")
+			})
+		})
+
+		It("returns the error and does not render", func() {
+			Ω(renderErr).Should(MatchError("boom"))
+			_, err := os.Stat(filepath.Join(dir, "foo.txt"))
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,23 @@
+/*
+Package lint implements the "goagen lint" command which checks a design against a set of style
+rules.
+
+The dslengine validations only catch designs that are structurally invalid. The lint rules check
+conventions on top of that such as naming, documentation and validation of the inputs. Each rule
+reports issues with a severity: "info", "warning" or "error". The configuration file given with
+--config may change the severity of any rule or disable it with "off":
+
+	rules:
+	  plural-resource-names: off
+	  action-description: error
+
+Issues are reported with the file and line of the DSL that defines the offending resource,
+action, type or attribute as recorded by dslengine.RecordLocation.
+
+Custom rules are registered with Register in the init function of a Go package. The packages
+given with --rules are compiled in the linter the same way goagen compiles generator plugins so
+that their rules run along the built-in rules and may be configured the same way.
+
+The command exits with a non-zero status if any issue has the "error" severity.
+*/
+package lint
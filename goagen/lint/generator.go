@@ -0,0 +1,59 @@
+package lint
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// IssuesFile is the name of the file written by Generate.
+const IssuesFile = "lint.json"
+
+// Generate is the generator entry point called by the meta generator. It runs the rules against
+// the design and writes the issues to the output directory as JSON.
+func Generate() (files []string, err error) {
+	var outDir, ver, config string
+
+	set := flag.NewFlagSet("lint", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&config, "config", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+	if design.Design == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	var cfg *Config
+	if config != "" {
+		if cfg, err = LoadConfig(config); err != nil {
+			return nil, err
+		}
+	}
+	issues, err := Run(design.Design, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if issues == nil {
+		issues = []*Issue{}
+	}
+	b, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(outDir, IssuesFile)
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return nil, err
+	}
+	return []string{path}, nil
+}
@@ -0,0 +1,216 @@
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+type (
+	// Severity is the severity of the issues reported by a rule.
+	Severity int
+
+	// Rule is a design lint rule.
+	Rule struct {
+		// Name is the rule name used in configuration files, e.g. "action-description".
+		Name string
+		// Description describes what the rule checks.
+		Description string
+		// Severity is the default severity of the issues reported by the rule.
+		Severity Severity
+		// Check checks the design and reports issues with the given reporter.
+		Check func(api *design.APIDefinition, r *Reporter)
+	}
+
+	// Issue is a rule violation.
+	Issue struct {
+		// Rule is the name of the rule that reported the issue.
+		Rule string `json:"rule"`
+		// Severity is the severity of the issue.
+		Severity Severity `json:"severity"`
+		// File is the path to the design file that contains the offending definition if known.
+		File string `json:"file,omitempty"`
+		// Line is the line number in File.
+		Line int `json:"line,omitempty"`
+		// Message describes the issue.
+		Message string `json:"message"`
+	}
+
+	// Reporter records the issues found by a rule.
+	Reporter struct {
+		rule     *Rule
+		severity Severity
+		parents  map[dslengine.Definition]dslengine.Definition
+		issues   []*Issue
+	}
+
+	// Config configures the rules. It is read from YAML files of the form:
+	//
+	//	rules:
+	//	  plural-resource-names: off
+	//	  action-description: error
+	Config struct {
+		// Rules indexes the severity of the rules by rule name. The severity is one of
+		// "off", "info", "warning" or "error", rules not listed use their default
+		// severity.
+		Rules map[string]string `yaml:"rules"`
+	}
+)
+
+const (
+	// Off disables a rule.
+	Off Severity = iota
+	// Info is the severity of informational issues.
+	Info
+	// Warning is the severity of issues that should be fixed.
+	Warning
+	// Error is the severity of issues that must be fixed, goagen lint exits with a non-zero
+	// status if any is reported.
+	Error
+)
+
+// rules indexes the registered rules by name.
+var rules = make(map[string]*Rule)
+
+// severities lists the severity names indexed by severity.
+var severities = []string{"off", "info", "warning", "error"}
+
+// Register registers a rule. Packages that define custom rules call Register in their init
+// function, goagen lint compiles the packages given with --rules in the linter so that their
+// rules run with the built-in rules. Register panics if a rule with the same name is already
+// registered.
+func Register(r *Rule) {
+	if _, ok := rules[r.Name]; ok {
+		panic(fmt.Sprintf("lint rule %q registered twice", r.Name)) // bug
+	}
+	rules[r.Name] = r
+}
+
+// Rules returns the registered rules sorted by name.
+func Rules() []*Rule {
+	res := make([]*Rule, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// LoadConfig reads the configuration file at the given path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid lint configuration %s: %s", path, err)
+	}
+	return &c, nil
+}
+
+// Run runs the registered rules enabled by the configuration against the given API and
+// returns the issues sorted by location. cfg may be nil in which case all the rules run with
+// their default severity.
+func Run(api *design.APIDefinition, cfg *Config) ([]*Issue, error) {
+	sevs := make(map[string]Severity, len(rules))
+	for n, r := range rules {
+		sevs[n] = r.Severity
+	}
+	if cfg != nil {
+		for n, s := range cfg.Rules {
+			if _, ok := rules[n]; !ok {
+				return nil, fmt.Errorf("unknown lint rule %q", n)
+			}
+			sev, err := ParseSeverity(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid severity for lint rule %q: %s", n, err)
+			}
+			sevs[n] = sev
+		}
+	}
+
+	parents := parentIndex(api)
+	var issues []*Issue
+	for _, r := range Rules() {
+		if sevs[r.Name] == Off {
+			continue
+		}
+		rep := &Reporter{rule: r, severity: sevs[r.Name], parents: parents}
+		r.Check(api, rep)
+		issues = append(issues, rep.issues...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Rule < issues[j].Rule
+	})
+	return issues, nil
+}
+
+// Report records an issue with the given definition. The issue location is the location of the
+// DSL that created the definition or, if the DSL did not record one, the location of the
+// closest enclosing definition that has one.
+func (r *Reporter) Report(def dslengine.Definition, format string, args ...interface{}) {
+	issue := &Issue{Rule: r.rule.Name, Severity: r.severity, Message: fmt.Sprintf(format, args...)}
+	for d := def; d != nil; d = r.parents[d] {
+		if loc := dslengine.LocationOf(d); loc != nil {
+			issue.File, issue.Line = loc.File, loc.Line
+			break
+		}
+	}
+	r.issues = append(r.issues, issue)
+}
+
+// ParseSeverity returns the severity with the given name.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severities {
+		if strings.EqualFold(name, n) {
+			return Severity(i), nil
+		}
+	}
+	return Off, fmt.Errorf("unknown severity %q, must be one of %s", name, strings.Join(severities, ", "))
+}
+
+// String returns the severity name.
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severities) {
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+	return severities[s]
+}
+
+// MarshalText returns the severity name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses the severity name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	sev, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sev
+	return nil
+}
+
+// String returns the issue formatted as "file:line: severity: message [rule]" so that editors
+// may link to the file.
+func (i *Issue) String() string {
+	msg := fmt.Sprintf("%s: %s [%s]", i.Severity, i.Message, i.Rule)
+	if i.File == "" {
+		return msg
+	}
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, msg)
+}
@@ -0,0 +1,148 @@
+package lint_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/lint"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// issuesOf returns the issues reported by the given rule.
+func issuesOf(issues []*lint.Issue, rule string) []*lint.Issue {
+	var res []*lint.Issue
+	for _, i := range issues {
+		if i.Rule == rule {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+var _ = Describe("Run", func() {
+	var cfg *lint.Config
+	var issues []*lint.Issue
+	var runErr error
+
+	BeforeEach(func() {
+		cfg = nil
+		dslengine.Reset()
+		API("test", func() {})
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		issues, runErr = lint.Run(Design, cfg)
+	})
+
+	It("reports the issues with the location of the DSL", func() {
+		Ω(runErr).ShouldNot(HaveOccurred())
+		plural := issuesOf(issues, "plural-resource-names")
+		Ω(plural).Should(HaveLen(1))
+		Ω(plural[0].Severity).Should(Equal(lint.Warning))
+		Ω(plural[0].File).Should(Equal("lint_test.go"))
+		Ω(plural[0].Line).Should(Equal(36))
+		Ω(plural[0].Message).Should(Equal(`resource name "bottle" is not plural`))
+		desc := issuesOf(issues, "action-description")
+		Ω(desc).Should(HaveLen(1))
+		Ω(desc[0].Line).Should(Equal(37))
+	})
+
+	Context("with a configuration", func() {
+		BeforeEach(func() {
+			cfg = &lint.Config{Rules: map[string]string{
+				"plural-resource-names": "off",
+				"action-description":    "error",
+			}}
+		})
+
+		It("applies the configured severities", func() {
+			Ω(runErr).ShouldNot(HaveOccurred())
+			Ω(issuesOf(issues, "plural-resource-names")).Should(BeEmpty())
+			desc := issuesOf(issues, "action-description")
+			Ω(desc).Should(HaveLen(1))
+			Ω(desc[0].Severity).Should(Equal(lint.Error))
+			Ω(lint.Errors(issues)).Should(Equal(desc))
+		})
+	})
+
+	Context("with an unknown rule", func() {
+		BeforeEach(func() {
+			cfg = &lint.Config{Rules: map[string]string{"unknown": "error"}}
+		})
+
+		It("fails", func() {
+			Ω(runErr).Should(MatchError(`unknown lint rule "unknown"`))
+		})
+	})
+
+	Context("with an invalid severity", func() {
+		BeforeEach(func() {
+			cfg = &lint.Config{Rules: map[string]string{"action-description": "fatal"}}
+		})
+
+		It("fails", func() {
+			Ω(runErr).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a custom rule", func() {
+		BeforeEach(func() {
+			cfg = &lint.Config{Rules: map[string]string{"test-custom": "info"}}
+		})
+
+		It("runs the rule", func() {
+			custom := issuesOf(issues, "test-custom")
+			Ω(custom).Should(HaveLen(1))
+			Ω(custom[0].Severity).Should(Equal(lint.Info))
+			Ω(custom[0].Message).Should(Equal(`API "test" checked`))
+		})
+	})
+})
+
+var _ = Describe("LoadConfig", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "lint")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("reads the rule severities", func() {
+		path := filepath.Join(dir, "lint.yaml")
+		content := "rules:\n  plural-resource-names: off\n  action-description: error\n"
+		Ω(ioutil.WriteFile(path, []byte(content), 0644)).ShouldNot(HaveOccurred())
+		cfg, err := lint.LoadConfig(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cfg.Rules).Should(Equal(map[string]string{
+			"plural-resource-names": "off",
+			"action-description":    "error",
+		}))
+	})
+})
+
+func init() {
+	lint.Register(&lint.Rule{
+		Name:     "test-custom",
+		Severity: lint.Off,
+		Check: func(api *APIDefinition, r *lint.Reporter) {
+			r.Report(api, "%s checked", api.Context())
+		},
+	})
+}
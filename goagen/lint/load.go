@@ -0,0 +1,82 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/meta"
+)
+
+// Lint compiles a linter for the design package with the given import path and returns the
+// issues it reports. config is the path to the configuration file, empty to run the rules with
+// their default severity. rulePkgs lists the import paths of the packages that register custom
+// rules.
+func Lint(designPkg, config string, rulePkgs []string) ([]*Issue, error) {
+	out, err := ioutil.TempDir("", "goagen-lint")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(out)
+
+	flags := map[string]string{"design": designPkg, "out": out}
+	if config != "" {
+		// The linter does not necessarily run in the current directory.
+		if flags["config"], err = filepath.Abs(config); err != nil {
+			return nil, err
+		}
+	}
+	imports := []*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa/goagen/lint")}
+	for _, p := range rulePkgs {
+		imports = append(imports, codegen.NewImport("_", p))
+	}
+	gen, err := meta.NewGenerator("lint.Generate", imports, flags, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gen.Generate(); err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filepath.Join(out, IssuesFile))
+	if err != nil {
+		return nil, err
+	}
+	var issues []*Issue
+	if err := json.Unmarshal(b, &issues); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// Write writes the issues to w, one per line, followed by a summary.
+func Write(w io.Writer, issues []*Issue) error {
+	counts := make(map[Severity]int)
+	for _, i := range issues {
+		counts[i.Severity]++
+		if _, err := fmt.Fprintln(w, i.String()); err != nil {
+			return err
+		}
+	}
+	if len(issues) == 0 {
+		_, err := fmt.Fprintln(w, "no issue")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d issue(s): %d error(s), %d warning(s), %d info\n",
+		len(issues), counts[Error], counts[Warning], counts[Info])
+	return err
+}
+
+// Errors returns the issues whose severity is Error.
+func Errors(issues []*Issue) []*Issue {
+	var res []*Issue
+	for _, i := range issues {
+		if i.Severity == Error {
+			res = append(res, i)
+		}
+	}
+	return res
+}
@@ -0,0 +1,303 @@
+package lint
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// snakeCaseRegex matches snake_case names.
+var snakeCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// irregularPlurals lists common plural nouns that do not end with "s".
+var irregularPlurals = map[string]bool{
+	"people": true, "children": true, "men": true, "women": true, "data": true, "media": true,
+	"feet": true, "teeth": true, "mice": true, "geese": true, "criteria": true, "indices": true,
+}
+
+func init() {
+	Register(&Rule{
+		Name:        "plural-resource-names",
+		Description: "resource names are plural nouns",
+		Severity:    Warning,
+		Check:       checkPluralResourceNames,
+	})
+	Register(&Rule{
+		Name:        "snake-case-attributes",
+		Description: "attribute and parameter names are snake_case",
+		Severity:    Warning,
+		Check:       checkSnakeCaseAttributes,
+	})
+	Register(&Rule{
+		Name:        "action-description",
+		Description: "every action has a description",
+		Severity:    Warning,
+		Check:       checkActionDescription,
+	})
+	Register(&Rule{
+		Name:        "client-error-responses",
+		Description: "every action declares at least one 4xx response",
+		Severity:    Warning,
+		Check:       checkClientErrorResponses,
+	})
+	Register(&Rule{
+		Name:        "bounded-arrays",
+		Description: "arrays sent by clients have a MaxLength validation",
+		Severity:    Warning,
+		Check:       checkBoundedArrays,
+	})
+	Register(&Rule{
+		Name:        "consistent-examples",
+		Description: "examples satisfy the validations of their attribute",
+		Severity:    Error,
+		Check:       checkConsistentExamples,
+	})
+}
+
+func checkPluralResourceNames(api *design.APIDefinition, r *Reporter) {
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		if !isPlural(res.Name) {
+			r.Report(res, "resource name %q is not plural", res.Name)
+		}
+		return nil
+	})
+}
+
+func checkSnakeCaseAttributes(api *design.APIDefinition, r *Reporter) {
+	WalkAttributes(api, func(owner dslengine.Definition, section, name string, att *design.AttributeDefinition) {
+		if name == "" || section == HeadersSection {
+			return
+		}
+		if !snakeCaseRegex.MatchString(name) {
+			r.Report(att, "%s name %q of %s is not snake_case", attributeKind(section), name, owner.Context())
+		}
+	})
+}
+
+func checkActionDescription(api *design.APIDefinition, r *Reporter) {
+	eachAction(api, func(a *design.ActionDefinition) {
+		if strings.TrimSpace(a.Description) == "" {
+			r.Report(a, "%s has no description", a.Context())
+		}
+	})
+}
+
+func checkClientErrorResponses(api *design.APIDefinition, r *Reporter) {
+	eachAction(api, func(a *design.ActionDefinition) {
+		for _, resp := range a.Responses {
+			if resp.Status >= 400 && resp.Status < 500 {
+				return
+			}
+		}
+		r.Report(a, "%s declares no 4xx response", a.Context())
+	})
+}
+
+func checkBoundedArrays(api *design.APIDefinition, r *Reporter) {
+	WalkAttributes(api, func(owner dslengine.Definition, section, name string, att *design.AttributeDefinition) {
+		if section == MediaTypeSection || !att.Type.IsArray() {
+			return
+		}
+		if att.Validation == nil || att.Validation.MaxLength == nil {
+			r.Report(att, "array %s of %s has no MaxLength validation", describe(section, name), owner.Context())
+		}
+	})
+}
+
+func checkConsistentExamples(api *design.APIDefinition, r *Reporter) {
+	WalkAttributes(api, func(owner dslengine.Definition, section, name string, att *design.AttributeDefinition) {
+		// NoExample sets the example to "-".
+		if att.Example == nil || att.Example == "-" || att.Validation == nil {
+			return
+		}
+		if msg := checkExample(att.Example, att.Validation); msg != "" {
+			r.Report(att, "example of %s of %s %s", describe(section, name), owner.Context(), msg)
+		}
+	})
+}
+
+// checkExample returns a message describing the validation the example fails, the empty string
+// if the example is valid.
+func checkExample(ex interface{}, v *dslengine.ValidationDefinition) string {
+	if len(v.Values) > 0 {
+		found := false
+		for _, val := range v.Values {
+			if fmt.Sprint(val) == fmt.Sprint(ex) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("%#v is not one of the enum values", ex)
+		}
+	}
+	if f, ok := toFloat(ex); ok {
+		if v.Minimum != nil && (f < *v.Minimum || v.ExclusiveMinimum && f == *v.Minimum) {
+			return fmt.Sprintf("%v is lower than the minimum %v", ex, *v.Minimum)
+		}
+		if v.Maximum != nil && (f > *v.Maximum || v.ExclusiveMaximum && f == *v.Maximum) {
+			return fmt.Sprintf("%v is greater than the maximum %v", ex, *v.Maximum)
+		}
+		if m := v.MultipleOf; m != nil {
+			if q := f / *m; math.Abs(q-math.Round(q)) > 1e-9 {
+				return fmt.Sprintf("%v is not a multiple of %v", ex, *m)
+			}
+		}
+	}
+	length := -1
+	if s, ok := ex.(string); ok {
+		length = utf8.RuneCountInString(s)
+		if v.Pattern != "" {
+			if re, err := regexp.Compile(v.Pattern); err == nil && !re.MatchString(s) {
+				return fmt.Sprintf("%q does not match the pattern %q", s, v.Pattern)
+			}
+		}
+	} else if val := reflect.ValueOf(ex); val.Kind() == reflect.Slice || val.Kind() == reflect.Map {
+		length = val.Len()
+		if val.Kind() == reflect.Map {
+			if v.MinProperties != nil && length < *v.MinProperties {
+				return fmt.Sprintf("has fewer properties than the minimum %d", *v.MinProperties)
+			}
+			if v.MaxProperties != nil && length > *v.MaxProperties {
+				return fmt.Sprintf("has more properties than the maximum %d", *v.MaxProperties)
+			}
+			if m, ok := ex.(map[string]interface{}); ok && v.HasCrossFieldRules() {
+				if msg := checkCrossFieldRules(m, v); msg != "" {
+					return msg
+				}
+			}
+		} else if v.UniqueItems {
+			for i := 0; i < length; i++ {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(val.Index(i).Interface(), val.Index(j).Interface()) {
+						return fmt.Sprintf("has duplicate elements %#v", val.Index(i).Interface())
+					}
+				}
+			}
+		}
+	}
+	if length >= 0 {
+		if v.MinLength != nil && length < *v.MinLength {
+			return fmt.Sprintf("is shorter than the minimum length %d", *v.MinLength)
+		}
+		if v.MaxLength != nil && length > *v.MaxLength {
+			return fmt.Sprintf("is longer than the maximum length %d", *v.MaxLength)
+		}
+	}
+	return ""
+}
+
+// checkCrossFieldRules returns a message describing the OneOfRequired, MutuallyExclusive or
+// DependentRequired validation the object example fails, the empty string if the example is valid.
+func checkCrossFieldRules(ex map[string]interface{}, v *dslengine.ValidationDefinition) string {
+	count := func(names []string) int {
+		c := 0
+		for _, n := range names {
+			if _, ok := ex[n]; ok {
+				c++
+			}
+		}
+		return c
+	}
+	for _, names := range v.OneOfRequired {
+		if count(names) != 1 {
+			return fmt.Sprintf("must set exactly one of %s", strings.Join(names, ", "))
+		}
+	}
+	for _, names := range v.MutuallyExclusive {
+		if count(names) > 1 {
+			return fmt.Sprintf("sets more than one of the mutually exclusive %s", strings.Join(names, ", "))
+		}
+	}
+	names := make([]string, 0, len(v.DependentRequired))
+	for n := range v.DependentRequired {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		deps := v.DependentRequired[n]
+		if _, ok := ex[n]; ok && count(deps) != len(deps) {
+			return fmt.Sprintf("sets %s but not all of %s", n, strings.Join(deps, ", "))
+		}
+	}
+	return ""
+}
+
+// eachAction calls fn for each action of the API.
+func eachAction(api *design.APIDefinition, fn func(a *design.ActionDefinition)) {
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			fn(a)
+			return nil
+		})
+	})
+}
+
+// isPlural returns true if the given name looks like a plural noun. Only the last word of
+// snake_case and camelCase names is considered.
+func isPlural(name string) bool {
+	word := name
+	if i := strings.LastIndexAny(word, "_-"); i >= 0 {
+		word = word[i+1:]
+	}
+	for i := len(word) - 1; i > 0; i-- {
+		if word[i] >= 'A' && word[i] <= 'Z' {
+			word = word[i:]
+			break
+		}
+	}
+	word = strings.ToLower(word)
+	if irregularPlurals[word] {
+		return true
+	}
+	return strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss")
+}
+
+// attributeKind returns the kind of attributes the section defines.
+func attributeKind(section string) string {
+	switch section {
+	case ParamsSection:
+		return "parameter"
+	case HeadersSection:
+		return "header"
+	}
+	return "attribute"
+}
+
+// describe returns a description of the attribute with the given name.
+func describe(section, name string) string {
+	if name == "" {
+		return "element"
+	}
+	return fmt.Sprintf("%s %q", attributeKind(section), name)
+}
+
+// toFloat converts numeric values to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
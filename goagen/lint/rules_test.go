@@ -0,0 +1,120 @@
+package lint_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/lint"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Built-in rules", func() {
+	var issues []*lint.Issue
+
+	messages := func(rule string) []string {
+		var res []string
+		for _, i := range issuesOf(issues, rule) {
+			res = append(res, i.Message)
+		}
+		return res
+	}
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("test", func() {})
+		BottlePayload := Type("BottlePayload", func() {
+			Attribute("name", String, func() {
+				MinLength(3)
+				Example("ab")
+			})
+			Attribute("vintageYear", Integer, func() {
+				Minimum(1900)
+				MultipleOf(7)
+				Example(1950)
+			})
+			Attribute("color", String, func() {
+				Enum("red", "white")
+				Example("rose")
+			})
+			Attribute("tags", ArrayOf(String))
+			Attribute("ratings", ArrayOf(Integer), func() {
+				MaxLength(10)
+			})
+		})
+		Bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("reviews", ArrayOf(String))
+			})
+			View("default", func() {
+				Attribute("reviews")
+			})
+		})
+		Resource("bottles", func() {
+			Action("create", func() {
+				Description("Create a bottle")
+				Routing(POST(""))
+				Payload(BottlePayload)
+				Response(Created)
+				Response(BadRequest)
+			})
+			Action("show", func() {
+				Routing(GET("/:bottleID"))
+				Params(func() {
+					Param("bottleID", Integer)
+				})
+				Response(OK, Bottle)
+			})
+		})
+		Resource("account", func() {
+			Action("list", func() {
+				Description("List the accounts")
+				Routing(GET(""))
+				Response(NoContent)
+				Response(NotFound)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		var err error
+		issues, err = lint.Run(Design, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("checks the resource names", func() {
+		Ω(messages("plural-resource-names")).Should(Equal([]string{`resource name "account" is not plural`}))
+	})
+
+	It("checks the attribute names", func() {
+		Ω(messages("snake-case-attributes")).Should(ConsistOf(
+			`attribute name "vintageYear" of type "BottlePayload" is not snake_case`,
+			`parameter name "bottleID" of resource "bottles" action "show" is not snake_case`,
+		))
+	})
+
+	It("checks the action descriptions", func() {
+		Ω(messages("action-description")).Should(Equal([]string{`resource "bottles" action "show" has no description`}))
+	})
+
+	It("checks the 4xx responses", func() {
+		Ω(messages("client-error-responses")).Should(Equal([]string{`resource "bottles" action "show" declares no 4xx response`}))
+	})
+
+	It("checks the arrays sent by clients", func() {
+		Ω(messages("bounded-arrays")).Should(Equal([]string{`array attribute "tags" of type "BottlePayload" has no MaxLength validation`}))
+	})
+
+	It("checks the examples", func() {
+		Ω(messages("consistent-examples")).Should(ConsistOf(
+			`example of attribute "color" of type "BottlePayload" "rose" is not one of the enum values`,
+			`example of attribute "name" of type "BottlePayload" is shorter than the minimum length 3`,
+			`example of attribute "vintageYear" of type "BottlePayload" 1950 is not a multiple of 7`,
+		))
+		for _, i := range issuesOf(issues, "consistent-examples") {
+			Ω(i.Severity).Should(Equal(lint.Error))
+			Ω(i.File).Should(Equal("rules_test.go"))
+		}
+	})
+})
@@ -0,0 +1,139 @@
+package lint
+
+import (
+	"sort"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Sections of the design that contain attributes, see WalkAttributes.
+const (
+	// TypeSection is the section of the user type attributes.
+	TypeSection = "type"
+	// MediaTypeSection is the section of the media type attributes.
+	MediaTypeSection = "media type"
+	// PayloadSection is the section of the attributes of the payloads defined inline.
+	PayloadSection = "payload"
+	// ParamsSection is the section of the path and query string parameters.
+	ParamsSection = "params"
+	// HeadersSection is the section of the request headers.
+	HeadersSection = "headers"
+)
+
+// AttributeWalker is the function called by WalkAttributes for each attribute. owner is the
+// definition that contains the attribute, section is the kind of attributes the owner defines
+// and name is the attribute name. name is empty for the elements of arrays and hashes.
+type AttributeWalker func(owner dslengine.Definition, section, name string, att *design.AttributeDefinition)
+
+// WalkAttributes calls fn for each attribute of the design: the attributes of the user types,
+// media types, inline payloads, params and headers. The attributes of the user types and media
+// types are only visited once, when walking the type itself. The built-in error media type is
+// not walked.
+func WalkAttributes(api *design.APIDefinition, fn AttributeWalker) {
+	walk(api, func(owner, _ dslengine.Definition, section, name string, att *design.AttributeDefinition) {
+		fn(owner, section, name, att)
+	})
+}
+
+// walker is the function called by walk for each attribute with the definition that directly
+// encloses the attribute: the owner or the parent attribute.
+type walker func(owner, parent dslengine.Definition, section, name string, att *design.AttributeDefinition)
+
+// walk calls fn for each attribute of the design.
+func walk(api *design.APIDefinition, fn walker) {
+	walkChildren(api, api, ParamsSection, api.Params, fn)
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		walkChildren(ut, ut, TypeSection, ut.AttributeDefinition, fn)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.Identifier != design.ErrorMediaIdentifier {
+			walkChildren(mt, mt, MediaTypeSection, mt.AttributeDefinition, fn)
+		}
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		walkChildren(r, r, ParamsSection, r.Params, fn)
+		walkChildren(r, r, HeadersSection, r.Headers, fn)
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			walkChildren(a, a, ParamsSection, a.Params, fn)
+			walkChildren(a, a, HeadersSection, a.Headers, fn)
+			if p := a.Payload; p != nil {
+				if _, ok := api.Types[p.TypeName]; !ok {
+					walkChildren(a, a, PayloadSection, p.AttributeDefinition, fn)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// walkChildren calls fn for the children of the given attribute recursively. It does not
+// descend into user types and media types.
+func walkChildren(owner, parent dslengine.Definition, section string, att *design.AttributeDefinition, fn walker) {
+	if att == nil || att.Type == nil {
+		return
+	}
+	switch t := att.Type.(type) {
+	case design.Object:
+		names := make([]string, 0, len(t))
+		for n := range t {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			fn(owner, parent, section, n, t[n])
+			walkChildren(owner, t[n], section, t[n], fn)
+		}
+	case *design.Array:
+		fn(owner, parent, section, "", t.ElemType)
+		walkChildren(owner, t.ElemType, section, t.ElemType, fn)
+	case *design.Hash:
+		for _, child := range []*design.AttributeDefinition{t.KeyType, t.ElemType} {
+			fn(owner, parent, section, "", child)
+			walkChildren(owner, child, section, child, fn)
+		}
+	case *design.Union:
+		for _, n := range t.VariantNames() {
+			fn(owner, parent, section, n, t.Variants[n])
+			walkChildren(owner, t.Variants[n], section, t.Variants[n], fn)
+		}
+	}
+}
+
+// parentIndex maps the definitions of the design to their enclosing definition so that issues
+// may be located using the closest definition whose location the DSL recorded.
+func parentIndex(api *design.APIDefinition) map[dslengine.Definition]dslengine.Definition {
+	parents := make(map[dslengine.Definition]dslengine.Definition)
+	add := func(def, parent dslengine.Definition) {
+		if _, ok := parents[def]; !ok {
+			parents[def] = parent
+		}
+	}
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		add(ut, api)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		add(mt, api)
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		add(r, api)
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			add(a, r)
+			if a.Payload != nil {
+				add(a.Payload, a)
+			}
+			for _, resp := range a.Responses {
+				add(resp, a)
+			}
+			return nil
+		})
+	})
+	walk(api, func(_, parent dslengine.Definition, _, _ string, att *design.AttributeDefinition) {
+		add(att, parent)
+	})
+	return parents
+}
@@ -9,8 +9,12 @@ import (
 	"time"
 
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/diff"
+	"github.com/goadesign/goa/goagen/importer"
+	"github.com/goadesign/goa/goagen/lint"
 	"github.com/goadesign/goa/goagen/meta"
 	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/goagen/watch"
 	"github.com/goadesign/goa/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -48,6 +52,8 @@ package and tool and the Swagger specification for the API.
 	rootCmd.PersistentFlags().StringP("out", "o", ".", "output directory")
 	rootCmd.PersistentFlags().StringVarP(&designPkg, "design", "d", "", "design package import path")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug mode, does not cleanup temporary files.")
+	rootCmd.PersistentFlags().String("model", "", "path to a JSON design model exported with 'goagen model', used instead of the design package")
+	rootCmd.PersistentFlags().StringSlice("hook", nil, "`import path` of a package that registers generator hooks, may be repeated")
 
 	// versionCmd implements the "version" command
 	versionCmd := &cobra.Command{
@@ -110,6 +116,41 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(swaggerCmd)
 
+	// openapiCmd implements the "openapi" command.
+	openapiCmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Generate OpenAPI 3.1 specification",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genopenapi", c) },
+	}
+	rootCmd.AddCommand(openapiCmd)
+
+	// mockCmd implements the "mock" command.
+	var mockAppPkg, seed string
+	mockCmd := &cobra.Command{
+		Use:   "mock",
+		Short: "Generate a mock server sending example responses",
+		Long: `The mock command generates a server in the "mock" directory that implements every action of
+the API with example responses. The server mounts the controllers of the package generated by
+"goagen app" so that requests are validated against the design. Requests select the response sent
+by an action with the X-Mock-Response header set to the response name or status code, actions
+send their first 2xx response by default.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genmock", c) },
+	}
+	mockCmd.Flags().StringVar(&mockAppPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	mockCmd.Flags().StringVar(&seed, "seed", "", "seed of the example generator, defaults to the API name")
+	rootCmd.AddCommand(mockCmd)
+
+	// protoCmd implements the "proto" command.
+	var protoPkg, protoAppPkg string
+	protoCmd := &cobra.Command{
+		Use:   "proto",
+		Short: "Generate Protocol Buffers definitions and conversion functions",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genproto", c) },
+	}
+	protoCmd.Flags().StringVar(&protoPkg, "pkg", "pb", "Name of generated Go package")
+	protoCmd.Flags().StringVar(&protoAppPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	rootCmd.AddCommand(protoCmd)
+
 	// jsCmd implements the "js" command.
 	var (
 		timeout      = time.Duration(20) * time.Second
@@ -127,6 +168,17 @@ package and tool and the Swagger specification for the API.
 	jsCmd.Flags().BoolVar(&noexample, "noexample", false, `Skip generation of example HTML and controller`)
 	rootCmd.AddCommand(jsCmd)
 
+	// tsCmd implements the "ts" command.
+	var tsScheme, tsHost string
+	tsCmd := &cobra.Command{
+		Use:   "ts",
+		Short: "Generate TypeScript client and models",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gents", c) },
+	}
+	tsCmd.Flags().StringVar(&tsScheme, "scheme", "", `the URL scheme of the default client base URL, defaults to the scheme defined in the API design if any.`)
+	tsCmd.Flags().StringVar(&tsHost, "host", "", `the API hostname of the default client base URL, defaults to the hostname defined in the API design if any`)
+	rootCmd.AddCommand(tsCmd)
+
 	// schemaCmd implements the "schema" command.
 	schemaCmd := &cobra.Command{
 		Use:   "schema",
@@ -183,6 +235,111 @@ package and tool and the Swagger specification for the API.
 	bootCmd.Flags().AddFlagSet(swaggerCmd.Flags())
 	rootCmd.AddCommand(bootCmd)
 
+	// watchCmd implements the "watch" command.
+	var (
+		watchGens []string
+		interval  time.Duration
+	)
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Regenerate code each time the design changes",
+		Long: `The watch command runs the given generators then watches the design package and
+re-runs the generators affected by each change. Only the files whose content changed are written.
+The design is compiled once per change to export its model, the generators load the model and are
+only compiled again when their own packages change. Changes that leave the model intact such as
+comments do not regenerate anything.
+
+The "main" and "controller" scaffolding generators cannot be watched.`,
+		Run: func(c *cobra.Command, _ []string) { err = runWatch(c, watchGens, interval) },
+	}
+	watchCmd.Flags().StringSliceVar(&watchGens, "gen", []string{"app", "client", "swagger"}, "name of a generator to run, may be repeated")
+	watchCmd.Flags().DurationVar(&interval, "interval", watch.DefaultInterval, "polling interval")
+	watchCmd.Flags().AddFlagSet(appCmd.Flags())
+	watchCmd.Flags().AddFlagSet(clientCmd.Flags())
+	watchCmd.Flags().AddFlagSet(swaggerCmd.Flags())
+	rootCmd.AddCommand(watchCmd)
+
+	// modelCmd implements the "model" command.
+	modelCmd := &cobra.Command{
+		Use:   "model",
+		Short: "Export the design as a JSON model",
+		Long: `The model command writes the JSON representation of the evaluated design to model.json: the
+resources, actions, types, media types, views, links, security schemes, metadata and validations.
+Generators load the model given with --model instead of compiling the design package, e.g.:
+
+	goagen model -d github.com/acme/api/design
+	goagen app --model model.json`,
+		Run: func(c *cobra.Command, _ []string) {
+			files, err = generate("model", "github.com/goadesign/goa/goagen/model", c, nil)
+		},
+	}
+	rootCmd.AddCommand(modelCmd)
+
+	// diffCmd implements the "diff" command.
+	var base, head, format string
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Report the changes between two versions of a design",
+		Long: `The diff command evaluates two versions of the design package and reports the added, removed
+and changed resources, actions, parameters, types and validations. A version is either a git
+revision of the repository containing the design package or a directory containing the design
+package. The working tree is compared when --head is not given.
+
+The command exits with a non-zero status if some changes break existing clients.`,
+		Run: func(c *cobra.Command, _ []string) { err = runDiff(c, base, head, format) },
+	}
+	diffCmd.Flags().StringVar(&base, "base", "", "git revision or directory of the base version of the design package")
+	diffCmd.Flags().StringVar(&head, "head", "", "git revision or directory of the new version of the design package, defaults to the working tree")
+	diffCmd.Flags().StringVar(&format, "format", "text", "output format: text, json or markdown")
+	rootCmd.AddCommand(diffCmd)
+
+	// lintCmd implements the "lint" command.
+	var (
+		lintConfig string
+		rulePkgs   []string
+	)
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the design against style rules",
+		Long: `The lint command runs the built-in and custom style rules against the design and reports the
+issues with the location of the offending DSL. The rules are enabled, disabled and given
+severities with a YAML configuration file:
+
+	rules:
+	  plural-resource-names: off
+	  action-description: error
+
+The command exits with a non-zero status if any issue has the "error" severity.`,
+		Run: func(c *cobra.Command, _ []string) { err = runLint(c, lintConfig, rulePkgs) },
+	}
+	lintCmd.Flags().StringVar(&lintConfig, "config", "", "path to the lint configuration `file`")
+	lintCmd.Flags().StringSliceVar(&rulePkgs, "rules", nil, "`import path` of a package that registers lint rules, may be repeated")
+	rootCmd.AddCommand(lintCmd)
+
+	// importCmd implements the "import" command.
+	var (
+		specPath, designName, group string
+		overwrite                   bool
+	)
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Generate a design package from a Swagger or OpenAPI document",
+		Long: `The import command reads a Swagger 2.0 or OpenAPI 3 document in JSON or YAML and writes the
+equivalent design package to the output directory. Operations are grouped into resources by
+tag or by path prefix. The constructs that the DSL cannot describe are listed as TODO comments
+in the generated code, e.g.:
+
+	goagen import --spec swagger.yaml --group path`,
+		Run: func(c *cobra.Command, _ []string) {
+			files, err = runImport(c, specPath, overwrite, &importer.Options{Package: designName, Group: group})
+		},
+	}
+	importCmd.Flags().StringVar(&specPath, "spec", "", "path to the Swagger or OpenAPI `file`")
+	importCmd.Flags().StringVar(&designName, "pkg", "design", "name of the generated design `package`")
+	importCmd.Flags().StringVar(&group, "group", importer.GroupByTag, "group operations into resources by \"tag\" or \"path\"")
+	importCmd.Flags().BoolVar(&overwrite, "force", false, "overwrite existing files")
+	rootCmd.AddCommand(importCmd)
+
 	// controllerCmd implements the "controller" command.
 	var (
 		res, appPkg string
@@ -272,9 +429,20 @@ func runGen(c *cobra.Command, args []string) ([]string, error) {
 }
 
 func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]string, error) {
+	gen, err := newMetaGenerator(pkgName, pkgPath, c, args)
+	if err != nil {
+		return nil, err
+	}
+	return gen.Generate()
+}
+
+// commandFlags lists the flags used by goagen that are not forwarded to the generators.
+var commandFlags = map[string]bool{"pkg-path": true, "hook": true, "gen": true, "interval": true, "base": true, "head": true, "format": true}
+
+func newMetaGenerator(pkgName, pkgPath string, c *cobra.Command, args []string) (*meta.Generator, error) {
 	m := make(map[string]string)
 	c.Flags().Visit(func(f *pflag.Flag) {
-		if f.Name != "pkg-path" {
+		if !commandFlags[f.Name] {
 			m[f.Name] = f.Value.String()
 		}
 	})
@@ -288,16 +456,123 @@ func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]strin
 		return nil, err
 	}
 
-	gen, err := meta.NewGenerator(
+	// The hook packages register their hooks when the generator tool initializes.
+	imports := []*codegen.ImportSpec{codegen.SimpleImport(pkgPath)}
+	hooks, err := c.Flags().GetStringSlice("hook")
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hooks {
+		imports = append(imports, codegen.NewImport("_", h))
+	}
+
+	return meta.NewGenerator(
 		pkgName+".Generate",
-		[]*codegen.ImportSpec{codegen.SimpleImport(pkgPath)},
+		imports,
 		m,
 		args,
 	)
+}
+
+func runWatch(c *cobra.Command, names []string, interval time.Duration) error {
+	hooks, err := c.Flags().GetStringSlice("hook")
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return gen.Generate()
+	w := &watch.Watcher{
+		DesignPkg: c.Flag("design").Value.String(),
+		Interval:  interval,
+		Output:    os.Stdout,
+	}
+	if w.DesignPkg == "" {
+		return fmt.Errorf("missing design package flag")
+	}
+	modelPkg := "github.com/goadesign/goa/goagen/model"
+	flags := map[string]string{"design": w.DesignPkg}
+	if w.Model, err = meta.NewGenerator("model.Generate", []*codegen.ImportSpec{codegen.SimpleImport(modelPkg)}, flags, nil); err != nil {
+		return err
+	}
+	for _, n := range names {
+		if n == "main" || n == "controller" {
+			return fmt.Errorf("the %s generator produces scaffolding and cannot be watched", n)
+		}
+		pkgPath := fmt.Sprintf("github.com/goadesign/goa/goagen/gen_%s", n)
+		pkgSrcPath, err := codegen.PackageSourcePath(pkgPath)
+		if err != nil {
+			return fmt.Errorf("unknown generator %q: %s", n, err)
+		}
+		pkgName, err := codegen.PackageName(pkgSrcPath)
+		if err != nil {
+			return fmt.Errorf("invalid package import path: %s", err)
+		}
+		gen, err := newMetaGenerator(pkgName, pkgPath, c, nil)
+		if err != nil {
+			return err
+		}
+		pkgs := []string{pkgPath}
+		if n == "app" || n == "client" || n == "mock" {
+			// Only the generators that render their files with goagen/ir run hooks.
+			pkgs = append(pkgs, hooks...)
+		}
+		w.Generators = append(w.Generators, &watch.Generator{Name: n, Meta: gen, Packages: pkgs})
+		w.OutDir = gen.OutDir
+	}
+
+	stop := make(chan struct{})
+	go utils.Catch(nil, func() { close(stop) })
+	fmt.Printf("watching %s, press Ctrl-C to stop\n", w.DesignPkg)
+	return w.Run(stop)
+}
+
+func runLint(c *cobra.Command, config string, rulePkgs []string) error {
+	designPkg := c.Flag("design").Value.String()
+	if designPkg == "" {
+		return fmt.Errorf("missing design package flag")
+	}
+	issues, err := lint.Lint(designPkg, config, rulePkgs)
+	if err != nil {
+		return err
+	}
+	if err := lint.Write(os.Stdout, issues); err != nil {
+		return err
+	}
+	if n := len(lint.Errors(issues)); n > 0 {
+		return fmt.Errorf("%d lint error(s)", n)
+	}
+	return nil
+}
+
+func runImport(c *cobra.Command, spec string, force bool, opts *importer.Options) ([]string, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("missing spec flag")
+	}
+	return importer.Generate(spec, c.Flag("out").Value.String(), force, opts)
+}
+
+func runDiff(c *cobra.Command, base, head, format string) error {
+	designPkg := c.Flag("design").Value.String()
+	if designPkg == "" {
+		return fmt.Errorf("missing design package flag")
+	}
+	if base == "" {
+		return fmt.Errorf("missing base flag")
+	}
+	old, err := diff.Load(designPkg, base)
+	if err != nil {
+		return err
+	}
+	nw, err := diff.Load(designPkg, head)
+	if err != nil {
+		return err
+	}
+	changes := diff.Compare(old, nw)
+	if err := diff.Write(os.Stdout, format, changes); err != nil {
+		return err
+	}
+	if n := len(diff.Breaking(changes)); n > 0 {
+		return fmt.Errorf("%d breaking change(s)", n)
+	}
+	return nil
 }
 
 type (
@@ -351,13 +626,13 @@ func runCommands(root *cobra.Command) {
 // for import path to any Go package.
 func flagJSON(fl *pflag.Flag) *flag {
 	f := &flag{Long: fl.Name, Short: fl.Shorthand, Description: fl.Usage}
-	f.Required = fl.Name == "pkg-path" || fl.Name == "design"
+	f.Required = fl.Name == "pkg-path" || fl.Name == "design" || fl.Name == "base"
 	switch fl.Name {
 	case "out":
 		f.Argument = "$DIR"
 	case "design":
 		f.Argument = "$DESIGN_PKG"
-	case "pkg-path":
+	case "pkg-path", "hook", "rules":
 		f.Argument = "$PKG"
 	}
 	return f
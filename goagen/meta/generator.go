@@ -1,11 +1,14 @@
 package meta
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -43,6 +46,19 @@ type Generator struct {
 	// DesignPkgPath is the Go import path to the design package.
 	DesignPkgPath string
 
+	// ModelPath is the path to a JSON design model exported with "goagen model". The
+	// generator loads the model instead of compiling the design package if set.
+	ModelPath string
+
+	// ToolCache is the directory where the compiled generator is kept so that it may be
+	// reused by later runs. The generator is compiled on each run if empty.
+	ToolCache string
+
+	// ToolKey identifies the content of the packages compiled into the generator, e.g. the
+	// digest of the design package source files. Generate reuses the generator compiled
+	// with the same key, it only applies if ToolCache is set.
+	ToolKey string
+
 	debug bool
 }
 
@@ -50,8 +66,8 @@ type Generator struct {
 // given its factory method and command line flags.
 func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[string]string, customflags []string) (*Generator, error) {
 	var (
-		outDir, designPkgPath string
-		debug                 bool
+		outDir, designPkgPath, modelPath string
+		debug                            bool
 	)
 
 	if o, ok := flags["out"]; ok {
@@ -60,6 +76,13 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 	if d, ok := flags["design"]; ok {
 		designPkgPath = d
 	}
+	if p, ok := flags["model"]; ok && p != "" {
+		var err error
+		modelPath, err = filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if d, ok := flags["debug"]; ok {
 		var err error
 		debug, err = strconv.ParseBool(d)
@@ -75,6 +98,7 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 		CustomFlags:   customflags,
 		OutDir:        outDir,
 		DesignPkgPath: designPkgPath,
+		ModelPath:     modelPath,
 		debug:         debug,
 	}, nil
 }
@@ -88,7 +112,7 @@ func (m *Generator) Generate() ([]string, error) {
 	if m.OutDir == "" {
 		return nil, fmt.Errorf("missing output directory flag")
 	}
-	if m.DesignPkgPath == "" {
+	if m.DesignPkgPath == "" && m.ModelPath == "" {
 		return nil, fmt.Errorf("missing design package flag")
 	}
 
@@ -97,6 +121,14 @@ func (m *Generator) Generate() ([]string, error) {
 		return nil, err
 	}
 
+	// Reuse the generator compiled from the same sources if any.
+	cached := m.cachedTool()
+	if cached != "" {
+		if _, err := os.Stat(cached); err == nil {
+			return m.spawn(cached)
+		}
+	}
+
 	// Create temporary workspace used for generation
 	wd, err := os.Getwd()
 	if err != nil {
@@ -118,13 +150,16 @@ func (m *Generator) Generate() ([]string, error) {
 		fmt.Printf("** Code generator source dir: %s\n", tmpDir)
 	}
 
-	pkgSourcePath, err := codegen.PackageSourcePath(m.DesignPkgPath)
-	if err != nil {
-		return nil, fmt.Errorf("invalid design package import path: %s", err)
-	}
-	pkgName, err := codegen.PackageName(pkgSourcePath)
-	if err != nil {
-		return nil, err
+	pkgName := "design"
+	if m.ModelPath == "" {
+		pkgSourcePath, err := codegen.PackageSourcePath(m.DesignPkgPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid design package import path: %s", err)
+		}
+		pkgName, err = codegen.PackageName(pkgSourcePath)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Generate tool source code.
@@ -143,9 +178,51 @@ func (m *Generator) Generate() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	if cached != "" {
+		if err := copyTool(genbin, cached); err != nil {
+			return nil, err
+		}
+	}
 	return m.spawn(genbin)
 }
 
+// cachedTool returns the path to the compiled generator in the tool cache, the empty string if
+// the generator is not cached. The path depends on the key and on everything that makes it into
+// the generator source code.
+func (m *Generator) cachedTool() string {
+	if m.ToolCache == "" || m.ToolKey == "" {
+		return ""
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n", m.ToolKey, m.Genfunc, m.DesignPkgPath, m.ModelPath)
+	for _, imp := range m.Imports {
+		fmt.Fprintf(h, "%s %s\n", imp.Name, imp.Path)
+	}
+	// Keep the name of the generator, it appears in the header of the generated files.
+	name := "goagen"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(m.ToolCache, hex.EncodeToString(h.Sum(nil))[:16], name)
+}
+
+// copyTool copies the compiled generator to the tool cache. It writes to a temporary file first
+// so that a concurrent run never spawns a partially written generator.
+func copyTool(genbin, cached string) error {
+	b, err := ioutil.ReadFile(genbin)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+		return err
+	}
+	tmp := cached + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cached)
+}
+
 func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 	file, err := pkg.CreateSourceFile("main.go")
 	if err != nil {
@@ -156,10 +233,20 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("github.com/goadesign/goa/dslengine"),
-		codegen.NewImport("_", filepath.ToSlash(m.DesignPkgPath)),
 	)
+	source := mainTmpl
+	if m.ModelPath != "" {
+		for _, path := range []string{"github.com/goadesign/goa/design", "github.com/goadesign/goa/goagen/model"} {
+			if !hasImport(imports, path) {
+				imports = append(imports, codegen.SimpleImport(path))
+			}
+		}
+		source = modelMainTmpl
+	} else {
+		imports = append(imports, codegen.NewImport("_", filepath.ToSlash(m.DesignPkgPath)))
+	}
 	file.WriteHeader("Code Generator", "main", imports)
-	tmpl, err := template.New("generator").Parse(mainTmpl)
+	tmpl, err := template.New("generator").Parse(source)
 	if err != nil {
 		panic(err) // bug
 	}
@@ -170,6 +257,7 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 	context := map[string]string{
 		"Genfunc":       m.Genfunc,
 		"DesignPackage": m.DesignPkgPath,
+		"ModelPath":     m.ModelPath,
 		"PkgName":       pkgName,
 	}
 	if err := tmpl.Execute(file, context); err != nil {
@@ -177,12 +265,23 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 	}
 }
 
+// hasImport returns true if imports contains a non-aliased import of the package with the given
+// path.
+func hasImport(imports []*codegen.ImportSpec, path string) bool {
+	for _, imp := range imports {
+		if imp.Path == path && imp.Name == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // spawn runs the compiled generator using the arguments initialized by Kingpin
 // when parsing the command line.
 func (m *Generator) spawn(genbin string) ([]string, error) {
 	var args []string
 	for k, v := range m.Flags {
-		if k == "debug" {
+		if k == "debug" || k == "model" {
 			continue
 		}
 		args = append(args, fmt.Sprintf("--%s=%s", k, v))
@@ -216,3 +315,20 @@ func main() {
 	// We're done
 	fmt.Println(strings.Join(files, "\n"))
 }`
+
+const modelMainTmpl = `
+func main() {
+	// Load the design model, the DSL has already run when the model was exported.
+	api, err := model.Load({{ printf "%q" .ModelPath }})
+	dslengine.FailOnError(err)
+	design.Design = api
+	if design.ProjectedMediaTypes == nil {
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+	}
+
+	files, err := {{.Genfunc}}()
+	dslengine.FailOnError(err)
+
+	// We're done
+	fmt.Println(strings.Join(files, "\n"))
+}`
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -225,6 +226,38 @@ var _ = Describe("Run", func() {
 					Ω(compileError).ShouldNot(HaveOccurred())
 				})
 			})
+
+			Context("with a tool cache", func() {
+				var cache string
+
+				BeforeEach(func() {
+					var err error
+					cache, err = ioutil.TempDir("", "goagen-cache")
+					Ω(err).ShouldNot(HaveOccurred())
+				})
+
+				JustBeforeEach(func() {
+					m.ToolCache = cache
+					m.ToolKey = "design-digest"
+					_, compileError = m.Generate()
+				})
+
+				AfterEach(func() {
+					os.RemoveAll(cache)
+				})
+
+				It("reuses the generator compiled with the same key", func() {
+					Ω(compileError).ShouldNot(HaveOccurred())
+					genPackage, err := genWorkspace.NewPackage("gen")
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(ioutil.WriteFile(filepath.Join(genPackage.Abs(), "gen.go"), []byte(invalidSource), 0644)).Should(Succeed())
+					_, err = m.Generate()
+					Ω(err).ShouldNot(HaveOccurred())
+					m.ToolKey = "other-digest"
+					_, err = m.Generate()
+					Ω(err).Should(HaveOccurred())
+				})
+			})
 		})
 
 		Context("with code that returns generated file paths", func() {
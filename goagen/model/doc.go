@@ -0,0 +1,17 @@
+/*
+Package model implements the JSON representation of evaluated API designs used by the "goagen
+model" command and the --model flag of the other commands.
+
+Export builds the representation of a design: the API properties, resources, actions, routes,
+responses, user types, media types with their views and links, security schemes and
+requirements, metadata and validations. User types and media types are referenced by name so
+that the representation of recursive types is finite. Traits and response templates are DSL
+functions, only the names of the traits are exported and the definitions already include their
+effect.
+
+Import, Read and Load rebuild a design.APIDefinition from the representation. The definition is
+the result of running the DSL: generators may use it directly without compiling the design
+package. The representation is versioned, Import rejects models produced with a different
+Version.
+*/
+package model
@@ -0,0 +1,462 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goadesign/goa/design"
+)
+
+// kindNames lists the names of the type kinds used in the JSON representation.
+var kindNames = map[design.Kind]string{
+	design.BooleanKind:   "boolean",
+	design.IntegerKind:   "integer",
+	design.NumberKind:    "number",
+	design.StringKind:    "string",
+	design.DateTimeKind:  "datetime",
+	design.UUIDKind:      "uuid",
+	design.AnyKind:       "any",
+	design.FileKind:      "file",
+	design.ArrayKind:     "array",
+	design.ObjectKind:    "object",
+	design.HashKind:      "hash",
+	design.UnionKind:     "union",
+	design.UserTypeKind:  "user_type",
+	design.MediaTypeKind: "media_type",
+}
+
+// securityKindNames lists the names of the security scheme kinds used in the JSON representation.
+var securityKindNames = map[design.SecuritySchemeKind]string{
+	design.OAuth2SecurityKind:    "oauth2",
+	design.BasicAuthSecurityKind: "basic",
+	design.APIKeySecurityKind:    "api_key",
+	design.JWTSecurityKind:       "jwt",
+	design.MTLSSecurityKind:      "mtls",
+	design.HTTPSigSecurityKind:   "httpsig",
+}
+
+// exporter builds the JSON representation of a design.
+type exporter struct {
+	// api is the design being exported.
+	api *design.APIDefinition
+	// mediaTypes indexes the canonical identifiers of the registered media types.
+	mediaTypes map[*design.MediaTypeDefinition]string
+	// inline records the unregistered types being exported to stop at recursive references.
+	inline map[design.DataType]bool
+}
+
+// Export returns the JSON representation of the given evaluated design.
+func Export(api *design.APIDefinition) *Design {
+	e := &exporter{
+		api:        api,
+		mediaTypes: make(map[*design.MediaTypeDefinition]string, len(api.MediaTypes)),
+		inline:     make(map[design.DataType]bool),
+	}
+	for id, mt := range api.MediaTypes {
+		e.mediaTypes[mt] = id
+	}
+	return &Design{Version: Version, API: e.definition()}
+}
+
+func (e *exporter) definition() *API {
+	a := e.api
+	res := &API{
+		Name:           a.Name,
+		Title:          a.Title,
+		Description:    a.Description,
+		Version:        a.Version,
+		VersionScheme:  string(a.VersionScheme),
+		VersionKey:     a.VersionKey,
+		Host:           a.Host,
+		Schemes:        a.Schemes,
+		BasePath:       a.BasePath,
+		Params:         e.attribute(a.Params),
+		Consumes:       exportEncodings(a.Consumes),
+		Produces:       exportEncodings(a.Produces),
+		Origins:        exportOrigins(a.Origins),
+		TermsOfService: a.TermsOfService,
+		Responses:      e.responses(a.Responses),
+		Metadata:       a.Metadata,
+		Security:       exportSecurity(a.Security),
+		NoExamples:     a.NoExamples,
+	}
+	if c := a.Contact; c != nil {
+		res.Contact = &Contact{Name: c.Name, Email: c.Email, URL: c.URL}
+	}
+	if l := a.License; l != nil {
+		res.License = &License{Name: l.Name, URL: l.URL}
+	}
+	res.Docs = exportDocs(a.Docs)
+	for _, v := range a.Versions {
+		res.Versions = append(res.Versions, &APIVersion{
+			Name:        v.Name,
+			Description: v.Description,
+			Deprecation: exportDeprecation(v.Deprecation),
+		})
+	}
+	if len(a.Resources) > 0 {
+		res.Resources = make(map[string]*Resource, len(a.Resources))
+		for n, r := range a.Resources {
+			res.Resources[n] = e.resource(r)
+		}
+	}
+	if len(a.Types) > 0 {
+		res.Types = make(map[string]*UserType, len(a.Types))
+		for n, ut := range a.Types {
+			res.Types[n] = &UserType{Attribute: e.attribute(ut.AttributeDefinition)}
+		}
+	}
+	if len(a.MediaTypes) > 0 {
+		res.MediaTypes = make(map[string]*MediaType, len(a.MediaTypes))
+		for id, mt := range a.MediaTypes {
+			res.MediaTypes[id] = e.mediaType(mt)
+		}
+	}
+	for n := range a.Traits {
+		res.Traits = append(res.Traits, n)
+	}
+	sort.Strings(res.Traits)
+	for _, s := range a.SecuritySchemes {
+		res.SecuritySchemes = append(res.SecuritySchemes, &SecurityScheme{
+			Kind:             securityKindNames[s.Kind],
+			SchemeName:       s.SchemeName,
+			Type:             s.Type,
+			Description:      s.Description,
+			In:               s.In,
+			Name:             s.Name,
+			Scopes:           s.Scopes,
+			Flow:             s.Flow,
+			TokenURL:         s.TokenURL,
+			AuthorizationURL: s.AuthorizationURL,
+			Metadata:         s.Metadata,
+		})
+	}
+	return res
+}
+
+func (e *exporter) resource(r *design.ResourceDefinition) *Resource {
+	res := &Resource{
+		Description:         r.Description,
+		Schemes:             r.Schemes,
+		BasePath:            r.BasePath,
+		Params:              e.attribute(r.Params),
+		ParentName:          r.ParentName,
+		MediaType:           r.MediaType,
+		DefaultViewName:     r.DefaultViewName,
+		CanonicalActionName: r.CanonicalActionName,
+		Responses:           e.responses(r.Responses),
+		Headers:             e.attribute(r.Headers),
+		Origins:             exportOrigins(r.Origins),
+		Metadata:            r.Metadata,
+		Security:            exportSecurity(r.Security),
+		Lifecycle:           exportLifecycle(r.Lifecycle),
+	}
+	if len(r.Actions) > 0 {
+		res.Actions = make(map[string]*Action, len(r.Actions))
+		for n, a := range r.Actions {
+			res.Actions[n] = e.action(a)
+		}
+	}
+	for _, fs := range r.FileServers {
+		res.FileServers = append(res.FileServers, &FileServer{
+			Description: fs.Description,
+			Docs:        exportDocs(fs.Docs),
+			FilePath:    fs.FilePath,
+			RequestPath: fs.RequestPath,
+			Metadata:    fs.Metadata,
+			Security:    exportSecurity(fs.Security),
+		})
+	}
+	return res
+}
+
+func (e *exporter) action(a *design.ActionDefinition) *Action {
+	res := &Action{
+		Description:      a.Description,
+		Docs:             exportDocs(a.Docs),
+		Schemes:          a.Schemes,
+		Responses:        e.responses(a.Responses),
+		Params:           e.attribute(a.Params),
+		QueryParams:      e.attribute(a.QueryParams),
+		PayloadOptional:  a.PayloadOptional,
+		PayloadMultipart: a.PayloadMultipart,
+		Headers:          e.attribute(a.Headers),
+		Cookies:          e.attribute(a.Cookies),
+		Metadata:         a.Metadata,
+		Security:         exportSecurity(a.Security),
+		Lifecycle:        exportLifecycle(a.Lifecycle),
+	}
+	if a.Payload != nil {
+		res.Payload = e.dataType(a.Payload)
+	}
+	if a.Patch != nil {
+		res.Patch = &Patch{Format: a.Patch.Format, Target: e.dataType(a.Patch.Target)}
+	}
+	if p := a.Pagination; p != nil {
+		res.Pagination = &Pagination{
+			Strategy:        string(p.Strategy),
+			MaxPageSize:     p.MaxPageSize,
+			DefaultPageSize: p.DefaultPageSize,
+			Envelope:        p.Envelope,
+		}
+	}
+	for _, r := range a.Routes {
+		res.Routes = append(res.Routes, &Route{Verb: r.Verb, Path: r.Path, Metadata: r.Metadata})
+	}
+	return res
+}
+
+func (e *exporter) responses(resps map[string]*design.ResponseDefinition) map[string]*Response {
+	if len(resps) == 0 {
+		return nil
+	}
+	res := make(map[string]*Response, len(resps))
+	for n, r := range resps {
+		res[n] = &Response{
+			Status:      r.Status,
+			Description: r.Description,
+			Type:        e.dataType(r.Type),
+			MediaType:   r.MediaType,
+			ViewName:    r.ViewName,
+			Headers:     e.attribute(r.Headers),
+			Metadata:    r.Metadata,
+			Standard:    r.Standard,
+		}
+	}
+	return res
+}
+
+func (e *exporter) mediaType(mt *design.MediaTypeDefinition) *MediaType {
+	res := &MediaType{
+		Attribute:       e.attribute(mt.AttributeDefinition),
+		TypeName:        mt.TypeName,
+		Identifier:      mt.Identifier,
+		ContentType:     mt.ContentType,
+		SortFields:      mt.SortFields,
+		SparseFieldsets: mt.SparseFieldsets,
+	}
+	for _, f := range mt.Filters {
+		filter := &Filter{Attribute: f.Attribute}
+		for _, op := range f.Operators {
+			filter.Operators = append(filter.Operators, string(op))
+		}
+		res.Filters = append(res.Filters, filter)
+	}
+	if len(mt.Links) > 0 {
+		res.Links = make(map[string]*Link, len(mt.Links))
+		for n, l := range mt.Links {
+			res.Links[n] = &Link{View: l.View, URITemplate: l.URITemplate}
+		}
+	}
+	if len(mt.Views) > 0 {
+		res.Views = make(map[string]*Attribute, len(mt.Views))
+		for n, v := range mt.Views {
+			res.Views[n] = e.attribute(v.AttributeDefinition)
+		}
+	}
+	if mt.Resource != nil {
+		res.Resource = mt.Resource.Name
+	}
+	return res
+}
+
+func (e *exporter) attribute(att *design.AttributeDefinition) *Attribute {
+	if att == nil {
+		return nil
+	}
+	res := &Attribute{
+		Type:        e.dataType(att.Type),
+		Reference:   e.dataType(att.Reference),
+		Description: att.Description,
+		Metadata:    att.Metadata,
+		Default:     exportValue(att.DefaultValue),
+		Example:     exportValue(att.Example),
+		View:        att.View,
+		Nullable:    att.Nullable,
+		Lifecycle:   exportLifecycle(att.Lifecycle),
+	}
+	if v := att.Validation; v != nil {
+		res.Validation = &Validation{
+			Format:            v.Format,
+			Pattern:           v.Pattern,
+			Minimum:           v.Minimum,
+			Maximum:           v.Maximum,
+			ExclusiveMinimum:  v.ExclusiveMinimum,
+			ExclusiveMaximum:  v.ExclusiveMaximum,
+			MultipleOf:        v.MultipleOf,
+			MinLength:         v.MinLength,
+			MaxLength:         v.MaxLength,
+			UniqueItems:       v.UniqueItems,
+			MinProperties:     v.MinProperties,
+			MaxProperties:     v.MaxProperties,
+			Required:          v.Required,
+			OneOfRequired:     v.OneOfRequired,
+			MutuallyExclusive: v.MutuallyExclusive,
+			DependentRequired: v.DependentRequired,
+		}
+		for _, val := range v.Values {
+			res.Validation.Values = append(res.Validation.Values, exportValue(val))
+		}
+		for _, f := range v.Funcs {
+			res.Validation.Funcs = append(res.Validation.Funcs, &ValidationFunc{
+				PackagePath: f.PackagePath,
+				Name:        f.Name,
+				Attributes:  f.Attributes,
+			})
+		}
+	}
+	for n, ok := range att.NonZeroAttributes {
+		if ok {
+			res.NonZeroAttributes = append(res.NonZeroAttributes, n)
+		}
+	}
+	sort.Strings(res.NonZeroAttributes)
+	return res
+}
+
+func (e *exporter) dataType(dt design.DataType) *Type {
+	if dt == nil {
+		return nil
+	}
+	res := &Type{Kind: kindNames[dt.Kind()]}
+	switch t := dt.(type) {
+	case design.Primitive:
+	case *design.Array:
+		res.Elem = e.attribute(t.ElemType)
+	case *design.Hash:
+		res.Key = e.attribute(t.KeyType)
+		res.Elem = e.attribute(t.ElemType)
+	case design.Object:
+		res.Attributes = make(map[string]*Attribute, len(t))
+		for n, att := range t {
+			res.Attributes[n] = e.attribute(att)
+		}
+	case *design.Union:
+		res.Variants = make(map[string]*Attribute, len(t.Variants))
+		for n, att := range t.Variants {
+			res.Variants[n] = e.attribute(att)
+		}
+		res.Discriminator = t.Discriminator
+	case *design.MediaTypeDefinition:
+		if id, ok := e.mediaTypes[t]; ok {
+			res.Name = id
+			break
+		}
+		res.Name = design.CanonicalIdentifier(t.Identifier)
+		if !e.inline[t] {
+			e.inline[t] = true
+			res.MediaType = e.mediaType(t)
+			delete(e.inline, t)
+		}
+	case *design.UserTypeDefinition:
+		res.Name = t.TypeName
+		if e.api.Types[t.TypeName] == t || e.inline[t] {
+			break
+		}
+		e.inline[t] = true
+		res.UserType = &UserType{Attribute: e.attribute(t.AttributeDefinition)}
+		delete(e.inline, t)
+	default:
+		panic(fmt.Sprintf("unknown data type %T", dt)) // bug
+	}
+	return res
+}
+
+func exportEncodings(encs []*design.EncodingDefinition) []*Encoding {
+	var res []*Encoding
+	for _, enc := range encs {
+		res = append(res, &Encoding{
+			MIMETypes:   enc.MIMETypes,
+			PackagePath: enc.PackagePath,
+			Function:    enc.Function,
+			Encoder:     enc.Encoder,
+		})
+	}
+	return res
+}
+
+func exportOrigins(origins map[string]*design.CORSDefinition) map[string]*CORS {
+	if len(origins) == 0 {
+		return nil
+	}
+	res := make(map[string]*CORS, len(origins))
+	for o, c := range origins {
+		res[o] = &CORS{
+			Headers:        c.Headers,
+			Methods:        c.Methods,
+			Exposed:        c.Exposed,
+			MaxAge:         c.MaxAge,
+			Credentials:    c.Credentials,
+			Regexp:         c.Regexp,
+			PrivateNetwork: c.PrivateNetwork,
+		}
+	}
+	return res
+}
+
+func exportDocs(d *design.DocsDefinition) *Docs {
+	if d == nil {
+		return nil
+	}
+	return &Docs{Description: d.Description, URL: d.URL}
+}
+
+func exportLifecycle(l *design.LifecycleDefinition) *Lifecycle {
+	if l == nil {
+		return nil
+	}
+	return &Lifecycle{AddedIn: l.AddedIn, RemovedIn: l.RemovedIn, Deprecation: exportDeprecation(l.Deprecation)}
+}
+
+func exportDeprecation(d *design.DeprecationDefinition) *Deprecation {
+	if d == nil {
+		return nil
+	}
+	return &Deprecation{Since: d.Since, Sunset: d.Sunset}
+}
+
+func exportSecurity(s *design.SecurityDefinition) *Security {
+	if s == nil {
+		return nil
+	}
+	res := &Security{Scopes: s.Scopes}
+	if s.Scheme != nil {
+		if s.Scheme.Kind == design.NoSecurityKind {
+			res.NoSecurity = true
+		} else {
+			res.Scheme = s.Scheme.SchemeName
+		}
+	}
+	for _, scheme := range s.AdditionalSchemes {
+		res.AdditionalSchemes = append(res.AdditionalSchemes, scheme.SchemeName)
+	}
+	for _, alt := range s.Alternatives {
+		res.Alternatives = append(res.Alternatives, exportSecurity(alt))
+	}
+	return res
+}
+
+// exportValue converts the hash values of default, example and enum values to JSON objects.
+func exportValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			res[fmt.Sprint(k)] = exportValue(elem)
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			res[k] = exportValue(elem)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, elem := range v {
+			res[i] = exportValue(elem)
+		}
+		return res
+	}
+	return val
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// ModelFile is the name of the file written by Generate.
+const ModelFile = "model.json"
+
+// Generate is the generator entry point called by the meta generator. It writes the JSON
+// representation of the design to the output directory.
+func Generate() (files []string, err error) {
+	var outDir, ver string
+
+	set := flag.NewFlagSet("model", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+	if design.Design == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	b, err := json.MarshalIndent(Export(design.Design), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(outDir, ModelFile)
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return []string{path}, nil
+}
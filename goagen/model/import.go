@@ -0,0 +1,650 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// importer rebuilds a design from its JSON representation.
+type importer struct {
+	// api is the design being built.
+	api *design.APIDefinition
+	// schemes indexes the security schemes by name.
+	schemes map[string]*design.SecuritySchemeDefinition
+	// inline indexes the unregistered user types and media types being built by name so
+	// that recursive references resolve to them.
+	inline map[string]design.DataType
+	// values lists the functions that convert the default, example and enum values once all
+	// the types are built.
+	values []func()
+}
+
+// Load reads the JSON representation of a design from the file at the given path and builds the
+// corresponding API definition.
+func Load(path string) (*design.APIDefinition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	api, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid design model %s: %s", path, err)
+	}
+	return api, nil
+}
+
+// Read decodes the JSON representation of a design read from r and builds the corresponding API
+// definition.
+func Read(r io.Reader) (*design.APIDefinition, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Check the version first: a newer representation may not decode into Design and the
+	// fields this version does not know about would be silently ignored.
+	var v struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(v.Version); err != nil {
+		return nil, err
+	}
+	var d Design
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, err
+	}
+	return Import(&d)
+}
+
+// checkVersion returns an error if the representation version is not Version.
+func checkVersion(v int) error {
+	if v > Version {
+		return fmt.Errorf("model version %d was exported by a newer goagen, this goagen supports version %d", v, Version)
+	}
+	if v != Version {
+		return fmt.Errorf("unsupported model version %d, expected %d", v, Version)
+	}
+	return nil
+}
+
+// Import builds the API definition described by the given JSON representation. The definition
+// is the evaluated design: the DSL does not need to run and the traits and response templates
+// cannot be applied anymore.
+func Import(d *Design) (api *design.APIDefinition, err error) {
+	if err := checkVersion(d.Version); err != nil {
+		return nil, err
+	}
+	if d.API == nil {
+		return nil, fmt.Errorf("missing API definition")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(*importError); ok {
+				err = e.err
+				return
+			}
+			panic(r)
+		}
+	}()
+	i := &importer{
+		api:     design.NewAPIDefinition(),
+		schemes: make(map[string]*design.SecuritySchemeDefinition),
+		inline:  make(map[string]design.DataType),
+	}
+	i.definition(d.API)
+	for _, convert := range i.values {
+		convert()
+	}
+	return i.api, nil
+}
+
+// importError is the type of the panics used to abort Import.
+type importError struct {
+	err error
+}
+
+// fail aborts the import with the given error.
+func fail(format string, args ...interface{}) {
+	panic(&importError{err: fmt.Errorf(format, args...)})
+}
+
+func (i *importer) definition(m *API) {
+	a := i.api
+	a.Name = m.Name
+	a.Title = m.Title
+	a.Description = m.Description
+	a.Version = m.Version
+	a.VersionScheme = design.VersioningScheme(m.VersionScheme)
+	a.VersionKey = m.VersionKey
+	for _, v := range m.Versions {
+		vd := &design.VersionDefinition{Parent: a, Name: v.Name, Description: v.Description}
+		vd.Deprecation = importDeprecation(v.Deprecation, vd)
+		a.Versions = append(a.Versions, vd)
+	}
+	a.Host = m.Host
+	a.Schemes = m.Schemes
+	a.BasePath = m.BasePath
+	a.Consumes = importEncodings(m.Consumes)
+	a.Produces = importEncodings(m.Produces)
+	a.Origins = importOrigins(m.Origins, a)
+	a.TermsOfService = m.TermsOfService
+	a.Metadata = m.Metadata
+	a.NoExamples = m.NoExamples
+	if c := m.Contact; c != nil {
+		a.Contact = &design.ContactDefinition{Name: c.Name, Email: c.Email, URL: c.URL}
+	}
+	if l := m.License; l != nil {
+		a.License = &design.LicenseDefinition{Name: l.Name, URL: l.URL}
+	}
+	a.Docs = importDocs(m.Docs)
+	if len(m.Traits) > 0 {
+		a.Traits = make(map[string]*dslengine.TraitDefinition, len(m.Traits))
+		for _, n := range m.Traits {
+			a.Traits[n] = &dslengine.TraitDefinition{Name: n}
+		}
+	}
+	for _, s := range m.SecuritySchemes {
+		scheme := &design.SecuritySchemeDefinition{
+			Kind:             securityKind(s.Kind),
+			SchemeName:       s.SchemeName,
+			Type:             s.Type,
+			Description:      s.Description,
+			In:               s.In,
+			Name:             s.Name,
+			Scopes:           s.Scopes,
+			Flow:             s.Flow,
+			TokenURL:         s.TokenURL,
+			AuthorizationURL: s.AuthorizationURL,
+			Metadata:         s.Metadata,
+		}
+		a.SecuritySchemes = append(a.SecuritySchemes, scheme)
+		i.schemes[s.SchemeName] = scheme
+	}
+	a.Security = i.security(m.Security)
+
+	// Create the user types and media types first so that the types that reference them
+	// resolve to the same definitions.
+	if len(m.Types) > 0 {
+		a.Types = make(map[string]*design.UserTypeDefinition, len(m.Types))
+		for n := range m.Types {
+			a.Types[n] = &design.UserTypeDefinition{TypeName: n}
+		}
+	}
+	if len(m.MediaTypes) > 0 {
+		a.MediaTypes = make(map[string]*design.MediaTypeDefinition, len(m.MediaTypes))
+		for id, mt := range m.MediaTypes {
+			if id == design.CanonicalIdentifier(design.ErrorMediaIdentifier) {
+				a.MediaTypes[id] = design.ErrorMedia
+				continue
+			}
+			a.MediaTypes[id] = &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{TypeName: mt.TypeName},
+			}
+		}
+	}
+	for n, ut := range m.Types {
+		a.Types[n].AttributeDefinition = i.attribute(ut.Attribute)
+	}
+	for id, mt := range m.MediaTypes {
+		if a.MediaTypes[id] != design.ErrorMedia {
+			i.mediaType(a.MediaTypes[id], mt)
+		}
+	}
+
+	a.Params = i.attribute(m.Params)
+	a.Responses = i.responses(m.Responses, nil)
+	if len(m.Resources) > 0 {
+		a.Resources = make(map[string]*design.ResourceDefinition, len(m.Resources))
+		for n, r := range m.Resources {
+			a.Resources[n] = i.resource(n, r)
+		}
+	}
+	for id, mt := range m.MediaTypes {
+		if mt.Resource == "" {
+			continue
+		}
+		r, ok := a.Resources[mt.Resource]
+		if !ok {
+			fail("unknown resource %q of media type %q", mt.Resource, id)
+		}
+		a.MediaTypes[id].Resource = r
+	}
+}
+
+func (i *importer) resource(name string, m *Resource) *design.ResourceDefinition {
+	r := &design.ResourceDefinition{
+		Name:                name,
+		Schemes:             m.Schemes,
+		BasePath:            m.BasePath,
+		Params:              i.attribute(m.Params),
+		ParentName:          m.ParentName,
+		Description:         m.Description,
+		MediaType:           m.MediaType,
+		DefaultViewName:     m.DefaultViewName,
+		CanonicalActionName: m.CanonicalActionName,
+		Headers:             i.attribute(m.Headers),
+		Metadata:            m.Metadata,
+		Security:            i.security(m.Security),
+	}
+	r.Lifecycle = importLifecycle(m.Lifecycle, r)
+	r.Responses = i.responses(m.Responses, r)
+	r.Origins = importOrigins(m.Origins, r)
+	if len(m.Actions) > 0 {
+		r.Actions = make(map[string]*design.ActionDefinition, len(m.Actions))
+		for n, a := range m.Actions {
+			r.Actions[n] = i.action(n, a, r)
+		}
+	}
+	for _, fs := range m.FileServers {
+		r.FileServers = append(r.FileServers, &design.FileServerDefinition{
+			Parent:      r,
+			Description: fs.Description,
+			Docs:        importDocs(fs.Docs),
+			FilePath:    fs.FilePath,
+			RequestPath: fs.RequestPath,
+			Metadata:    fs.Metadata,
+			Security:    i.security(fs.Security),
+		})
+	}
+	return r
+}
+
+func (i *importer) action(name string, m *Action, parent *design.ResourceDefinition) *design.ActionDefinition {
+	a := &design.ActionDefinition{
+		Name:             name,
+		Description:      m.Description,
+		Docs:             importDocs(m.Docs),
+		Parent:           parent,
+		Schemes:          m.Schemes,
+		Params:           i.attribute(m.Params),
+		QueryParams:      i.attribute(m.QueryParams),
+		PayloadOptional:  m.PayloadOptional,
+		PayloadMultipart: m.PayloadMultipart,
+		Headers:          i.attribute(m.Headers),
+		Cookies:          i.attribute(m.Cookies),
+		Metadata:         m.Metadata,
+		Security:         i.security(m.Security),
+	}
+	a.Lifecycle = importLifecycle(m.Lifecycle, a)
+	a.Responses = i.responses(m.Responses, a)
+	for _, r := range m.Routes {
+		a.Routes = append(a.Routes, &design.RouteDefinition{Verb: r.Verb, Path: r.Path, Parent: a, Metadata: r.Metadata})
+	}
+	if m.Payload != nil {
+		ut, ok := i.dataType(m.Payload).(*design.UserTypeDefinition)
+		if !ok {
+			fail("payload of action %q of resource %q is not a user type", name, parent.Name)
+		}
+		a.Payload = ut
+	}
+	if m.Patch != nil {
+		a.Patch = &design.PatchDefinition{Format: m.Patch.Format, Target: i.dataType(m.Patch.Target)}
+	}
+	if p := m.Pagination; p != nil {
+		a.Pagination = &design.PaginationDefinition{
+			Parent:          a,
+			Strategy:        design.PaginationStrategy(p.Strategy),
+			MaxPageSize:     p.MaxPageSize,
+			DefaultPageSize: p.DefaultPageSize,
+			Envelope:        p.Envelope,
+		}
+	}
+	return a
+}
+
+func (i *importer) responses(m map[string]*Response, parent dslengine.Definition) map[string]*design.ResponseDefinition {
+	if len(m) == 0 {
+		return nil
+	}
+	res := make(map[string]*design.ResponseDefinition, len(m))
+	for n, r := range m {
+		res[n] = &design.ResponseDefinition{
+			Name:        n,
+			Status:      r.Status,
+			Description: r.Description,
+			Type:        i.dataType(r.Type),
+			MediaType:   r.MediaType,
+			ViewName:    r.ViewName,
+			Headers:     i.attribute(r.Headers),
+			Parent:      parent,
+			Metadata:    r.Metadata,
+			Standard:    r.Standard,
+		}
+	}
+	return res
+}
+
+// mediaType initializes mt with the definition m.
+func (i *importer) mediaType(mt *design.MediaTypeDefinition, m *MediaType) {
+	mt.TypeName = m.TypeName
+	mt.Identifier = m.Identifier
+	mt.ContentType = m.ContentType
+	mt.AttributeDefinition = i.attribute(m.Attribute)
+	mt.SortFields = m.SortFields
+	mt.SparseFieldsets = m.SparseFieldsets
+	for _, f := range m.Filters {
+		filter := &design.FilterDefinition{Attribute: f.Attribute}
+		for _, op := range f.Operators {
+			filter.Operators = append(filter.Operators, design.FilterOperator(op))
+		}
+		mt.Filters = append(mt.Filters, filter)
+	}
+	if len(m.Links) > 0 {
+		mt.Links = make(map[string]*design.LinkDefinition, len(m.Links))
+		for n, l := range m.Links {
+			mt.Links[n] = &design.LinkDefinition{Name: n, View: l.View, URITemplate: l.URITemplate, Parent: mt}
+		}
+	}
+	if len(m.Views) > 0 {
+		mt.Views = make(map[string]*design.ViewDefinition, len(m.Views))
+		for n, v := range m.Views {
+			mt.Views[n] = &design.ViewDefinition{AttributeDefinition: i.attribute(v), Name: n, Parent: mt}
+		}
+	}
+}
+
+func (i *importer) attribute(m *Attribute) *design.AttributeDefinition {
+	if m == nil {
+		return nil
+	}
+	att := &design.AttributeDefinition{
+		Type:        i.dataType(m.Type),
+		Reference:   i.dataType(m.Reference),
+		Description: m.Description,
+		Metadata:    m.Metadata,
+		View:        m.View,
+		Nullable:    m.Nullable,
+	}
+	att.Lifecycle = importLifecycle(m.Lifecycle, att)
+	if v := m.Validation; v != nil {
+		att.Validation = &dslengine.ValidationDefinition{
+			Format:            v.Format,
+			Pattern:           v.Pattern,
+			Minimum:           v.Minimum,
+			Maximum:           v.Maximum,
+			ExclusiveMinimum:  v.ExclusiveMinimum,
+			ExclusiveMaximum:  v.ExclusiveMaximum,
+			MultipleOf:        v.MultipleOf,
+			MinLength:         v.MinLength,
+			MaxLength:         v.MaxLength,
+			UniqueItems:       v.UniqueItems,
+			MinProperties:     v.MinProperties,
+			MaxProperties:     v.MaxProperties,
+			Required:          v.Required,
+			OneOfRequired:     v.OneOfRequired,
+			MutuallyExclusive: v.MutuallyExclusive,
+			DependentRequired: v.DependentRequired,
+		}
+		for _, f := range v.Funcs {
+			att.Validation.Funcs = append(att.Validation.Funcs, &dslengine.ValidationFuncDefinition{
+				PackagePath: f.PackagePath,
+				Name:        f.Name,
+				Attributes:  f.Attributes,
+			})
+		}
+	}
+	i.values = append(i.values, func() {
+		att.DefaultValue = importValue(att.Type, m.Default)
+		att.Example = importValue(att.Type, m.Example)
+		if v := m.Validation; v != nil {
+			for _, val := range v.Values {
+				att.Validation.Values = append(att.Validation.Values, importValue(att.Type, val))
+			}
+		}
+	})
+	if len(m.NonZeroAttributes) > 0 {
+		att.NonZeroAttributes = make(map[string]bool, len(m.NonZeroAttributes))
+		for _, n := range m.NonZeroAttributes {
+			att.NonZeroAttributes[n] = true
+		}
+	}
+	return att
+}
+
+func (i *importer) dataType(m *Type) design.DataType {
+	if m == nil {
+		return nil
+	}
+	switch m.Kind {
+	case "boolean":
+		return design.Boolean
+	case "integer":
+		return design.Integer
+	case "number":
+		return design.Number
+	case "string":
+		return design.String
+	case "datetime":
+		return design.DateTime
+	case "uuid":
+		return design.UUID
+	case "any":
+		return design.Any
+	case "file":
+		return design.File
+	case "array":
+		return &design.Array{ElemType: i.attribute(m.Elem)}
+	case "hash":
+		return &design.Hash{KeyType: i.attribute(m.Key), ElemType: i.attribute(m.Elem)}
+	case "object":
+		obj := make(design.Object, len(m.Attributes))
+		for n, att := range m.Attributes {
+			obj[n] = i.attribute(att)
+		}
+		return obj
+	case "union":
+		variants := make(design.Object, len(m.Variants))
+		for n, att := range m.Variants {
+			variants[n] = i.attribute(att)
+		}
+		return &design.Union{Variants: variants, Discriminator: m.Discriminator}
+	case "user_type":
+		key := "user_type:" + m.Name
+		if m.UserType != nil {
+			ut := &design.UserTypeDefinition{TypeName: m.Name}
+			i.inline[key] = ut
+			ut.AttributeDefinition = i.attribute(m.UserType.Attribute)
+			delete(i.inline, key)
+			return ut
+		}
+		if ut, ok := i.api.Types[m.Name]; ok {
+			return ut
+		}
+		if ut, ok := i.inline[key]; ok {
+			return ut
+		}
+		fail("unknown user type %q", m.Name)
+	case "media_type":
+		key := "media_type:" + m.Name
+		if m.MediaType != nil {
+			mt := &design.MediaTypeDefinition{UserTypeDefinition: &design.UserTypeDefinition{}}
+			i.inline[key] = mt
+			i.mediaType(mt, m.MediaType)
+			delete(i.inline, key)
+			return mt
+		}
+		if mt, ok := i.api.MediaTypes[m.Name]; ok {
+			return mt
+		}
+		if mt, ok := i.inline[key]; ok {
+			return mt
+		}
+		if m.Name == design.CanonicalIdentifier(design.ErrorMediaIdentifier) {
+			return design.ErrorMedia
+		}
+		fail("unknown media type %q", m.Name)
+	}
+	fail("unknown type kind %q", m.Kind)
+	return nil
+}
+
+func (i *importer) security(m *Security) *design.SecurityDefinition {
+	if m == nil {
+		return nil
+	}
+	s := &design.SecurityDefinition{Scopes: m.Scopes}
+	if m.NoSecurity {
+		s.Scheme = &design.SecuritySchemeDefinition{Kind: design.NoSecurityKind}
+	} else {
+		s.Scheme = i.scheme(m.Scheme)
+	}
+	for _, n := range m.AdditionalSchemes {
+		s.AdditionalSchemes = append(s.AdditionalSchemes, i.scheme(n))
+	}
+	for _, alt := range m.Alternatives {
+		s.Alternatives = append(s.Alternatives, i.security(alt))
+	}
+	return s
+}
+
+func (i *importer) scheme(name string) *design.SecuritySchemeDefinition {
+	s, ok := i.schemes[name]
+	if !ok {
+		fail("unknown security scheme %q", name)
+	}
+	return s
+}
+
+func importEncodings(m []*Encoding) []*design.EncodingDefinition {
+	var res []*design.EncodingDefinition
+	for _, enc := range m {
+		res = append(res, &design.EncodingDefinition{
+			MIMETypes:   enc.MIMETypes,
+			PackagePath: enc.PackagePath,
+			Function:    enc.Function,
+			Encoder:     enc.Encoder,
+		})
+	}
+	return res
+}
+
+func importOrigins(m map[string]*CORS, parent dslengine.Definition) map[string]*design.CORSDefinition {
+	if len(m) == 0 {
+		return nil
+	}
+	res := make(map[string]*design.CORSDefinition, len(m))
+	for o, c := range m {
+		res[o] = &design.CORSDefinition{
+			Parent:         parent,
+			Origin:         o,
+			Headers:        c.Headers,
+			Methods:        c.Methods,
+			Exposed:        c.Exposed,
+			MaxAge:         c.MaxAge,
+			Credentials:    c.Credentials,
+			Regexp:         c.Regexp,
+			PrivateNetwork: c.PrivateNetwork,
+		}
+	}
+	return res
+}
+
+func importLifecycle(m *Lifecycle, parent dslengine.Definition) *design.LifecycleDefinition {
+	if m == nil {
+		return nil
+	}
+	return &design.LifecycleDefinition{
+		AddedIn:     m.AddedIn,
+		RemovedIn:   m.RemovedIn,
+		Deprecation: importDeprecation(m.Deprecation, parent),
+	}
+}
+
+func importDeprecation(m *Deprecation, parent dslengine.Definition) *design.DeprecationDefinition {
+	if m == nil {
+		return nil
+	}
+	return &design.DeprecationDefinition{Parent: parent, Since: m.Since, Sunset: m.Sunset}
+}
+
+func importDocs(m *Docs) *design.DocsDefinition {
+	if m == nil {
+		return nil
+	}
+	return &design.DocsDefinition{Description: m.Description, URL: m.URL}
+}
+
+// securityKind returns the security scheme kind with the given name.
+func securityKind(name string) design.SecuritySchemeKind {
+	for k, n := range securityKindNames {
+		if n == name {
+			return k
+		}
+	}
+	fail("unknown security scheme kind %q", name)
+	return 0
+}
+
+// importValue converts the JSON decoded default, example or enum value val to the Go value the
+// DSL produces for the given type: integers are int, hashes are map[interface{}]interface{} and
+// objects are map[string]interface{}.
+func importValue(dt design.DataType, val interface{}) interface{} {
+	if dt == nil || val == nil {
+		return val
+	}
+	switch {
+	case dt.Kind() == design.IntegerKind:
+		if f, ok := val.(float64); ok && f == float64(int(f)) {
+			return int(f)
+		}
+	case dt.IsArray():
+		if vals, ok := val.([]interface{}); ok {
+			res := make([]interface{}, len(vals))
+			for i, v := range vals {
+				res[i] = importValue(dt.ToArray().ElemType.Type, v)
+			}
+			return res
+		}
+	case dt.IsHash():
+		if vals, ok := val.(map[string]interface{}); ok {
+			h := dt.ToHash()
+			res := make(map[interface{}]interface{}, len(vals))
+			for k, v := range vals {
+				res[importKey(h.KeyType.Type, k)] = importValue(h.ElemType.Type, v)
+			}
+			return res
+		}
+	case dt.IsObject():
+		if vals, ok := val.(map[string]interface{}); ok {
+			obj := dt.ToObject()
+			res := make(map[string]interface{}, len(vals))
+			for k, v := range vals {
+				if att, ok := obj[k]; ok {
+					v = importValue(att.Type, v)
+				}
+				res[k] = v
+			}
+			return res
+		}
+	}
+	return val
+}
+
+// importKey converts the JSON object key k to a key of a hash whose keys have the given type.
+func importKey(dt design.DataType, k string) interface{} {
+	switch dt.Kind() {
+	case design.IntegerKind:
+		if i, err := strconv.Atoi(k); err == nil {
+			return i
+		}
+	case design.NumberKind:
+		if f, err := strconv.ParseFloat(k, 64); err == nil {
+			return f
+		}
+	case design.BooleanKind:
+		if b, err := strconv.ParseBool(k); err == nil {
+			return b
+		}
+	}
+	return k
+}
@@ -0,0 +1,497 @@
+package model
+
+import "github.com/goadesign/goa/dslengine"
+
+// Version is the version of the JSON representation produced by Export. Import rejects models
+// with a different version. The version is incremented whenever a change to the representation
+// prevents older loaders from reading the new models correctly.
+//
+// Version 2 adds union types, the cross-field and numeric validations, nullable attributes,
+// patch payloads, pagination, filters and sort fields, API versions and action cookies.
+const Version = 2
+
+type (
+	// Design is the JSON representation of an evaluated API design.
+	Design struct {
+		// Version is the version of the representation, see Version.
+		Version int `json:"version"`
+		// API is the API definition.
+		API *API `json:"api"`
+	}
+
+	// API is the representation of design.APIDefinition.
+	API struct {
+		// Name is the API name.
+		Name string `json:"name"`
+		// Title is the API title.
+		Title string `json:"title,omitempty"`
+		// Description is the API description.
+		Description string `json:"description,omitempty"`
+		// Version is the version of the API described by the design.
+		Version string `json:"version,omitempty"`
+		// Versions lists the versions declared by the design in order.
+		Versions []*APIVersion `json:"versions,omitempty"`
+		// VersionScheme defines how requests select the API version.
+		VersionScheme string `json:"version_scheme,omitempty"`
+		// VersionKey is the name of the header or media type parameter that selects the
+		// API version.
+		VersionKey string `json:"version_key,omitempty"`
+		// Host is the default API hostname.
+		Host string `json:"host,omitempty"`
+		// Schemes lists the supported URL schemes.
+		Schemes []string `json:"schemes,omitempty"`
+		// BasePath is the common base path to all the API endpoints.
+		BasePath string `json:"base_path,omitempty"`
+		// Params defines the path parameters common to all the API endpoints.
+		Params *Attribute `json:"params,omitempty"`
+		// Consumes lists the decoders used by the API controllers.
+		Consumes []*Encoding `json:"consumes,omitempty"`
+		// Produces lists the encoders used by the API controllers.
+		Produces []*Encoding `json:"produces,omitempty"`
+		// Origins indexes the CORS policies by origin.
+		Origins map[string]*CORS `json:"origins,omitempty"`
+		// TermsOfService describes or links to the API terms of service.
+		TermsOfService string `json:"terms_of_service,omitempty"`
+		// Contact is the API contact information.
+		Contact *Contact `json:"contact,omitempty"`
+		// License is the API license.
+		License *License `json:"license,omitempty"`
+		// Docs points to the API external documentation.
+		Docs *Docs `json:"docs,omitempty"`
+		// Resources indexes the resources by name.
+		Resources map[string]*Resource `json:"resources,omitempty"`
+		// Types indexes the user types by name.
+		Types map[string]*UserType `json:"types,omitempty"`
+		// MediaTypes indexes the media types by canonical identifier.
+		MediaTypes map[string]*MediaType `json:"media_types,omitempty"`
+		// Traits lists the names of the traits. Traits are DSL functions applied while the
+		// design is evaluated, their effect is part of the definitions that use them.
+		Traits []string `json:"traits,omitempty"`
+		// Responses indexes the responses available to all the actions by name.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// Metadata is the API metadata.
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		// SecuritySchemes lists the security schemes.
+		SecuritySchemes []*SecurityScheme `json:"security_schemes,omitempty"`
+		// Security is the default security requirement.
+		Security *Security `json:"security,omitempty"`
+		// NoExamples is true if example generation is disabled.
+		NoExamples bool `json:"no_examples,omitempty"`
+	}
+
+	// Contact is the representation of design.ContactDefinition.
+	Contact struct {
+		// Name of the contact person or organization.
+		Name string `json:"name,omitempty"`
+		// Email address of the contact.
+		Email string `json:"email,omitempty"`
+		// URL pointing to the contact information.
+		URL string `json:"url,omitempty"`
+	}
+
+	// License is the representation of design.LicenseDefinition.
+	License struct {
+		// Name of the license.
+		Name string `json:"name,omitempty"`
+		// URL to the license.
+		URL string `json:"url,omitempty"`
+	}
+
+	// Docs is the representation of design.DocsDefinition.
+	Docs struct {
+		// Description of the documentation.
+		Description string `json:"description,omitempty"`
+		// URL to the documentation.
+		URL string `json:"url,omitempty"`
+	}
+
+	// Encoding is the representation of design.EncodingDefinition.
+	Encoding struct {
+		// MIMETypes lists the MIME types handled by the encoder or decoder.
+		MIMETypes []string `json:"mime_types"`
+		// PackagePath is the import path of the package implementing the encoder or decoder.
+		PackagePath string `json:"package_path,omitempty"`
+		// Function is the name of the factory function.
+		Function string `json:"function,omitempty"`
+		// Encoder is true for encoders, false for decoders.
+		Encoder bool `json:"encoder,omitempty"`
+	}
+
+	// CORS is the representation of design.CORSDefinition.
+	CORS struct {
+		// Headers lists the authorized headers.
+		Headers []string `json:"headers,omitempty"`
+		// Methods lists the authorized HTTP methods.
+		Methods []string `json:"methods,omitempty"`
+		// Exposed lists the headers exposed to clients.
+		Exposed []string `json:"exposed,omitempty"`
+		// MaxAge is how long to cache preflight request responses in seconds.
+		MaxAge uint `json:"max_age,omitempty"`
+		// Credentials sets the Access-Control-Allow-Credentials header.
+		Credentials bool `json:"credentials,omitempty"`
+		// Regexp is true if the origin is a regular expression.
+		Regexp bool `json:"regexp,omitempty"`
+		// PrivateNetwork allows private network access preflight requests.
+		PrivateNetwork bool `json:"private_network,omitempty"`
+	}
+
+	// Resource is the representation of design.ResourceDefinition.
+	Resource struct {
+		// Description of the resource.
+		Description string `json:"description,omitempty"`
+		// Schemes lists the supported URL schemes.
+		Schemes []string `json:"schemes,omitempty"`
+		// BasePath is the common URL prefix to all the resource actions.
+		BasePath string `json:"base_path,omitempty"`
+		// Params defines the parameters common to all the resource actions.
+		Params *Attribute `json:"params,omitempty"`
+		// ParentName is the name of the parent resource if any.
+		ParentName string `json:"parent,omitempty"`
+		// MediaType is the identifier of the default media type.
+		MediaType string `json:"media_type,omitempty"`
+		// DefaultViewName is the name of the view of the default media type.
+		DefaultViewName string `json:"default_view,omitempty"`
+		// Actions indexes the actions by name.
+		Actions map[string]*Action `json:"actions,omitempty"`
+		// FileServers lists the static asset endpoints.
+		FileServers []*FileServer `json:"file_servers,omitempty"`
+		// CanonicalActionName is the name of the action with the canonical resource path.
+		CanonicalActionName string `json:"canonical_action,omitempty"`
+		// Responses indexes the responses common to all the actions by name.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// Headers defines the request headers common to all the actions.
+		Headers *Attribute `json:"headers,omitempty"`
+		// Origins indexes the CORS policies by origin.
+		Origins map[string]*CORS `json:"origins,omitempty"`
+		// Metadata is the resource metadata.
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		// Security is the security requirement of the actions that do not define one.
+		Security *Security `json:"security,omitempty"`
+		// Lifecycle lists the API versions that include the resource if any.
+		Lifecycle *Lifecycle `json:"lifecycle,omitempty"`
+	}
+
+	// APIVersion is the representation of design.VersionDefinition.
+	APIVersion struct {
+		// Name of the version.
+		Name string `json:"name"`
+		// Description of the version.
+		Description string `json:"description,omitempty"`
+		// Deprecation describes the deprecation of the version if any.
+		Deprecation *Deprecation `json:"deprecation,omitempty"`
+	}
+
+	// Lifecycle is the representation of design.LifecycleDefinition.
+	Lifecycle struct {
+		// AddedIn is the name of the first version that includes the definition.
+		AddedIn string `json:"added_in,omitempty"`
+		// RemovedIn is the name of the first version that no longer includes the definition.
+		RemovedIn string `json:"removed_in,omitempty"`
+		// Deprecation describes the deprecation of the definition if any.
+		Deprecation *Deprecation `json:"deprecation,omitempty"`
+	}
+
+	// Deprecation is the representation of design.DeprecationDefinition.
+	Deprecation struct {
+		// Since is the name of the first version that deprecates the definition.
+		Since string `json:"since,omitempty"`
+		// Sunset is the date after which the definition is expected to be removed.
+		Sunset string `json:"sunset,omitempty"`
+	}
+
+	// Action is the representation of design.ActionDefinition.
+	Action struct {
+		// Description of the action.
+		Description string `json:"description,omitempty"`
+		// Docs points to the action external documentation.
+		Docs *Docs `json:"docs,omitempty"`
+		// Schemes lists the action specific URL schemes.
+		Schemes []string `json:"schemes,omitempty"`
+		// Routes lists the action routes.
+		Routes []*Route `json:"routes,omitempty"`
+		// Responses indexes the responses by name.
+		Responses map[string]*Response `json:"responses,omitempty"`
+		// Params defines the path and query string parameters.
+		Params *Attribute `json:"params,omitempty"`
+		// QueryParams defines the query string parameters.
+		QueryParams *Attribute `json:"query_params,omitempty"`
+		// Payload is the request payload type if any, a user type.
+		Payload *Type `json:"payload,omitempty"`
+		// PayloadOptional is true if the payload may be omitted.
+		PayloadOptional bool `json:"payload_optional,omitempty"`
+		// PayloadMultipart is true if the payload is multipart.
+		PayloadMultipart bool `json:"payload_multipart,omitempty"`
+		// Patch describes the partial update sent in the request body if any.
+		Patch *Patch `json:"patch,omitempty"`
+		// Pagination describes how the results are split into pages if any.
+		Pagination *Pagination `json:"pagination,omitempty"`
+		// Headers defines the request headers.
+		Headers *Attribute `json:"headers,omitempty"`
+		// Cookies defines the request cookies.
+		Cookies *Attribute `json:"cookies,omitempty"`
+		// Metadata is the action metadata.
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		// Security is the action security requirement.
+		Security *Security `json:"security,omitempty"`
+		// Lifecycle lists the API versions that include the action if any.
+		Lifecycle *Lifecycle `json:"lifecycle,omitempty"`
+	}
+
+	// Patch is the representation of design.PatchDefinition.
+	Patch struct {
+		// Format is the Content-Type of the patch document.
+		Format string `json:"format"`
+		// Target is the type of the values the patch applies to.
+		Target *Type `json:"target"`
+	}
+
+	// Pagination is the representation of design.PaginationDefinition.
+	Pagination struct {
+		// Strategy is the pagination strategy, "cursor" or "offset".
+		Strategy string `json:"strategy"`
+		// MaxPageSize is the maximum number of results in a page if any.
+		MaxPageSize int `json:"max_page_size,omitempty"`
+		// DefaultPageSize is the number of results in a page if the request does not say.
+		DefaultPageSize int `json:"default_page_size"`
+		// Envelope is true if the pages are wrapped in an envelope.
+		Envelope bool `json:"envelope,omitempty"`
+	}
+
+	// Route is the representation of design.RouteDefinition.
+	Route struct {
+		// Verb is the HTTP method.
+		Verb string `json:"verb"`
+		// Path is the route path relative to the resource base path.
+		Path string `json:"path"`
+		// Metadata is the route metadata.
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// FileServer is the representation of design.FileServerDefinition.
+	FileServer struct {
+		// Description of the endpoint.
+		Description string `json:"description,omitempty"`
+		// Docs points to the endpoint external documentation.
+		Docs *Docs `json:"docs,omitempty"`
+		// FilePath is the path to the static assets.
+		FilePath string `json:"file_path"`
+		// RequestPath is the HTTP path that serves the assets.
+		RequestPath string `json:"request_path"`
+		// Metadata is the endpoint metadata.
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		// Security is the endpoint security requirement.
+		Security *Security `json:"security,omitempty"`
+	}
+
+	// Response is the representation of design.ResponseDefinition.
+	Response struct {
+		// Status is the HTTP status code.
+		Status int `json:"status"`
+		// Description of the response.
+		Description string `json:"description,omitempty"`
+		// Type is the response body type if any.
+		Type *Type `json:"type,omitempty"`
+		// MediaType is the identifier of the response media type if any.
+		MediaType string `json:"media_type,omitempty"`
+		// ViewName is the name of the view used to render the media type.
+		ViewName string `json:"view,omitempty"`
+		// Headers defines the response headers.
+		Headers *Attribute `json:"headers,omitempty"`
+		// Metadata is the response metadata.
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		// Standard is true if the response is one of the goa built-in responses.
+		Standard bool `json:"standard,omitempty"`
+	}
+
+	// UserType is the representation of design.UserTypeDefinition.
+	UserType struct {
+		// Attribute is the type attribute.
+		*Attribute
+	}
+
+	// MediaType is the representation of design.MediaTypeDefinition.
+	MediaType struct {
+		// Attribute is the type attribute.
+		*Attribute
+		// TypeName is the name of the media type Go data structure.
+		TypeName string `json:"type_name"`
+		// Identifier is the media type identifier.
+		Identifier string `json:"identifier"`
+		// ContentType is the value of the Content-Type response header.
+		ContentType string `json:"content_type,omitempty"`
+		// Links indexes the links by name.
+		Links map[string]*Link `json:"links,omitempty"`
+		// Views indexes the views by name.
+		Views map[string]*Attribute `json:"views,omitempty"`
+		// Resource is the name of the resource the media type is the canonical
+		// representation of if any.
+		Resource string `json:"resource,omitempty"`
+		// Filters lists the attributes collections can be filtered by.
+		Filters []*Filter `json:"filters,omitempty"`
+		// SortFields lists the attributes collections can be sorted by.
+		SortFields []string `json:"sort_fields,omitempty"`
+		// SparseFieldsets is true if clients may select the rendered attributes.
+		SparseFieldsets bool `json:"sparse_fieldsets,omitempty"`
+	}
+
+	// Filter is the representation of design.FilterDefinition.
+	Filter struct {
+		// Attribute is the name of the filtered attribute.
+		Attribute string `json:"attribute"`
+		// Operators lists the supported comparisons.
+		Operators []string `json:"operators"`
+	}
+
+	// Link is the representation of design.LinkDefinition.
+	Link struct {
+		// View is the view used to render the link.
+		View string `json:"view,omitempty"`
+		// URITemplate is the RFC6570 URI template of the link href.
+		URITemplate string `json:"uri_template,omitempty"`
+	}
+
+	// Attribute is the representation of design.AttributeDefinition.
+	Attribute struct {
+		// Type is the attribute type.
+		Type *Type `json:"type,omitempty"`
+		// Reference is the type the attribute properties are inherited from if any.
+		Reference *Type `json:"reference,omitempty"`
+		// Description of the attribute.
+		Description string `json:"description,omitempty"`
+		// Validation lists the attribute validations.
+		Validation *Validation `json:"validation,omitempty"`
+		// Metadata is the attribute metadata.
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+		// Default is the default value.
+		Default interface{} `json:"default,omitempty"`
+		// Example is the example value.
+		Example interface{} `json:"example,omitempty"`
+		// View is the view used to render the attribute.
+		View string `json:"view,omitempty"`
+		// NonZeroAttributes lists the names of the child attributes that cannot be zero
+		// sorted alphabetically.
+		NonZeroAttributes []string `json:"non_zero,omitempty"`
+		// Nullable is true if the attribute may be set to null.
+		Nullable bool `json:"nullable,omitempty"`
+		// Lifecycle lists the API versions that include the attribute if any.
+		Lifecycle *Lifecycle `json:"lifecycle,omitempty"`
+	}
+
+	// Type is the representation of design.DataType. User types and media types registered
+	// with the API are represented by their name or identifier, the other user types and media
+	// types are defined inline.
+	Type struct {
+		// Kind is the type kind, one of "boolean", "integer", "number", "string",
+		// "datetime", "uuid", "any", "file", "array", "object", "hash", "union",
+		// "user_type" or "media_type".
+		Kind string `json:"kind"`
+		// Name is the name of user types and the canonical identifier of media types.
+		Name string `json:"name,omitempty"`
+		// Elem is the element attribute of arrays and hashes.
+		Elem *Attribute `json:"elem,omitempty"`
+		// Key is the key attribute of hashes.
+		Key *Attribute `json:"key,omitempty"`
+		// Attributes indexes the attributes of objects by name.
+		Attributes map[string]*Attribute `json:"attributes,omitempty"`
+		// Variants indexes the variants of unions by name.
+		Variants map[string]*Attribute `json:"variants,omitempty"`
+		// Discriminator is the name of the discriminator attribute of unions.
+		Discriminator string `json:"discriminator,omitempty"`
+		// UserType defines user types that are not registered with the API.
+		UserType *UserType `json:"user_type,omitempty"`
+		// MediaType defines media types that are not registered with the API.
+		MediaType *MediaType `json:"media_type,omitempty"`
+	}
+
+	// Validation is the representation of dslengine.ValidationDefinition.
+	Validation struct {
+		// Values lists the enum values.
+		Values []interface{} `json:"enum,omitempty"`
+		// Format is the name of the format validation.
+		Format string `json:"format,omitempty"`
+		// Pattern is the regular expression values must match.
+		Pattern string `json:"pattern,omitempty"`
+		// Minimum is the minimum value.
+		Minimum *float64 `json:"minimum,omitempty"`
+		// Maximum is the maximum value.
+		Maximum *float64 `json:"maximum,omitempty"`
+		// ExclusiveMinimum is true if the minimum value is excluded.
+		ExclusiveMinimum bool `json:"exclusive_minimum,omitempty"`
+		// ExclusiveMaximum is true if the maximum value is excluded.
+		ExclusiveMaximum bool `json:"exclusive_maximum,omitempty"`
+		// MultipleOf is the number values must be a multiple of.
+		MultipleOf *float64 `json:"multiple_of,omitempty"`
+		// MinLength is the minimum length.
+		MinLength *int `json:"min_length,omitempty"`
+		// MaxLength is the maximum length.
+		MaxLength *int `json:"max_length,omitempty"`
+		// UniqueItems is true if the array elements must be unique.
+		UniqueItems bool `json:"unique_items,omitempty"`
+		// MinProperties is the minimum number of hash keys.
+		MinProperties *int `json:"min_properties,omitempty"`
+		// MaxProperties is the maximum number of hash keys.
+		MaxProperties *int `json:"max_properties,omitempty"`
+		// Required lists the names of the required attributes.
+		Required []string `json:"required,omitempty"`
+		// OneOfRequired lists groups of attributes of which exactly one must be set.
+		OneOfRequired [][]string `json:"one_of_required,omitempty"`
+		// MutuallyExclusive lists groups of attributes of which at most one may be set.
+		MutuallyExclusive [][]string `json:"mutually_exclusive,omitempty"`
+		// DependentRequired lists the attributes required by each attribute when set.
+		DependentRequired map[string][]string `json:"dependent_required,omitempty"`
+		// Funcs lists the user functions called by the generated validation code.
+		Funcs []*ValidationFunc `json:"funcs,omitempty"`
+	}
+
+	// ValidationFunc is the representation of dslengine.ValidationFuncDefinition.
+	ValidationFunc struct {
+		// PackagePath is the import path of the package that defines the function.
+		PackagePath string `json:"package"`
+		// Name is the name of the function.
+		Name string `json:"name"`
+		// Attributes lists the names of the attributes given to the function.
+		Attributes []string `json:"attributes"`
+	}
+
+	// SecurityScheme is the representation of design.SecuritySchemeDefinition.
+	SecurityScheme struct {
+		// Kind is the scheme kind, one of "oauth2", "basic", "api_key", "jwt", "mtls" or
+		// "httpsig".
+		Kind string `json:"kind"`
+		// SchemeName is the name of the scheme referenced by the security requirements.
+		SchemeName string `json:"scheme"`
+		// Type is the Swagger type of the scheme.
+		Type string `json:"type"`
+		// Description of the scheme.
+		Description string `json:"description,omitempty"`
+		// In is the location of API keys, "header" or "query".
+		In string `json:"in,omitempty"`
+		// Name is the name of the header or query parameter.
+		Name string `json:"name,omitempty"`
+		// Scopes indexes the scope descriptions by scope.
+		Scopes map[string]string `json:"scopes,omitempty"`
+		// Flow is the OAuth2 flow.
+		Flow string `json:"flow,omitempty"`
+		// TokenURL is the URL used to retrieve tokens.
+		TokenURL string `json:"token_url,omitempty"`
+		// AuthorizationURL is the URL used to retrieve authorization codes.
+		AuthorizationURL string `json:"authorization_url,omitempty"`
+		// Metadata is the scheme metadata.
+		Metadata dslengine.MetadataDefinition `json:"metadata,omitempty"`
+	}
+
+	// Security is the representation of design.SecurityDefinition.
+	Security struct {
+		// Scheme is the name of the security scheme, empty if NoSecurity is true.
+		Scheme string `json:"scheme,omitempty"`
+		// AdditionalSchemes lists the names of the schemes that must be satisfied together
+		// with Scheme.
+		AdditionalSchemes []string `json:"additional_schemes,omitempty"`
+		// Scopes lists the required scopes.
+		Scopes []string `json:"scopes,omitempty"`
+		// Alternatives lists the alternative requirements in order of preference.
+		Alternatives []*Security `json:"alternatives,omitempty"`
+		// NoSecurity is true if the requirement disables security.
+		NoSecurity bool `json:"no_security,omitempty"`
+	}
+)
@@ -0,0 +1,336 @@
+package model_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Export and Import", func() {
+	var exported []byte
+	var api *APIDefinition
+	var importErr error
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("cellar", func() {
+			Title("Cellar API")
+			Host("localhost:8080")
+			Scheme("http")
+			Metadata("team", "wine")
+			Trait("Authenticated", func() {
+				Metadata("authenticated")
+			})
+			Origin("http://swagger.goa.design", func() {
+				Methods("GET", "POST")
+				MaxAge(600)
+			})
+			Version("v1", func() {
+				Deprecated(func() {
+					Sunset("2027-06-30")
+				})
+			})
+			Version("v2")
+			VersionedBy(HeaderVersioning)
+		})
+		JWTSecurity("jwt", func() {
+			Header("Authorization")
+			Scope("api:read", "Read access")
+		})
+		BottlePayload := Type("BottlePayload", func() {
+			Attribute("name", String, func() {
+				MinLength(2)
+				Example("Number 8")
+			})
+			Attribute("vintage", Integer, func() {
+				Minimum(1900)
+				ExclusiveMaximum(2100)
+				Default(2010)
+			})
+			Attribute("ratings", HashOf(Integer, String), func() {
+				MinProperties(1)
+				Default(map[interface{}]interface{}{1: "poor", 5: "great"})
+			})
+			Attribute("color", String, func() {
+				Enum("red", "white")
+			})
+			Attribute("region", String)
+			Attribute("label", String, func() {
+				Nullable()
+			})
+			Required("name")
+			DependentRequired("region", "color")
+			ValidateFunc("example.com/validators", "ValidateBottle", "name", "vintage")
+		})
+		Type("PaymentMethod", func() {
+			OneOf(func() {
+				Attribute("card", func() {
+					Attribute("number", String)
+				})
+				Attribute("wallet", func() {
+					Attribute("provider", String)
+				})
+			}, Discriminator("kind"))
+		})
+		AccountMedia := MediaType("application/vnd.goa.example.account", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("href", String)
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("href")
+			})
+			View("link", func() {
+				Attribute("href")
+			})
+		})
+		BottleMedia := MediaType("application/vnd.goa.example.bottle", func() {
+			Reference(BottlePayload)
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("href", String)
+				Attribute("name")
+				Attribute("vintage")
+				Attribute("account", AccountMedia)
+			})
+			Links(func() {
+				Link("account")
+			})
+			Filterable("vintage", Gte, Lte)
+			Sortable("name")
+			SparseFieldsets()
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+				Attribute("links")
+			})
+			View("tiny", func() {
+				Attribute("id")
+			})
+		})
+		Resource("bottles", func() {
+			BasePath("/bottles")
+			DefaultMedia(BottleMedia)
+			Action("show", func() {
+				Description("Show a bottle")
+				UseTrait("Authenticated")
+				Security("jwt", func() {
+					Scope("api:read")
+				})
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer)
+				})
+				Response(OK)
+				Response(NotFound)
+			})
+			Action("list", func() {
+				Routing(GET(""))
+				Paginated(Offset, func() {
+					MaxPageSize(50)
+					Envelope()
+				})
+				Response(OK, CollectionOf(BottleMedia))
+			})
+			Action("create", func() {
+				NoSecurity()
+				Routing(POST(""))
+				Payload(BottlePayload)
+				Response(Created)
+				Response(BadRequest, ErrorMedia)
+			})
+			Action("rate", func() {
+				AddedIn("v2")
+				Deprecated("v2")
+				Routing(PUT("/:id/rating"))
+				Payload(func() {
+					Member("rating", Integer)
+					Required("rating")
+				})
+				Response(NoContent)
+			})
+			Action("update", func() {
+				Routing(PATCH("/:id"))
+				JSONPatch(BottleMedia)
+				Response(OK)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+
+		var err error
+		exported, err = json.Marshal(model.Export(Design))
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		api, importErr = model.Read(bytes.NewReader(exported))
+	})
+
+	It("round trips", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(model.Export(api))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(MatchJSON(exported))
+	})
+
+	It("resolves the user types and media types to the registered definitions", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		create := api.Resources["bottles"].Actions["create"]
+		Ω(create.Payload).Should(BeIdenticalTo(api.Types["BottlePayload"]))
+		bottle := api.MediaTypes["application/vnd.goa.example.bottle"]
+		account := bottle.Type.ToObject()["account"].Type
+		Ω(account).Should(BeIdenticalTo(api.MediaTypes["application/vnd.goa.example.account"]))
+		list := api.Resources["bottles"].Actions["list"]
+		bottles := list.Responses["OK"].Type.(*MediaTypeDefinition)
+		Ω(bottles).Should(BeIdenticalTo(api.MediaTypes[CanonicalIdentifier(bottles.Identifier)]))
+		Ω(bottles.Type.ToArray().ElemType.Type).Should(BeIdenticalTo(bottle))
+		Ω(create.Responses["BadRequest"].Type).Should(BeIdenticalTo(ErrorMedia))
+	})
+
+	It("defines inline payloads", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		rate := api.Resources["bottles"].Actions["rate"]
+		Ω(rate.Payload.TypeName).Should(Equal("RateBottlesPayload"))
+		Ω(rate.Payload.Type.ToObject()).Should(HaveKey("rating"))
+		Ω(rate.Payload.Validation.Required).Should(Equal([]string{"rating"}))
+	})
+
+	It("restores the patch targets", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		update := api.Resources["bottles"].Actions["update"]
+		Ω(update.Patch).ShouldNot(BeNil())
+		Ω(update.Patch.Format).Should(Equal(JSONPatchContentType))
+		Ω(update.Patch.Target).Should(BeIdenticalTo(api.MediaTypes["application/vnd.goa.example.bottle"]))
+		Ω(update.Payload.Type.ToArray().ElemType.Type).Should(BeIdenticalTo(api.Types["JSONPatchOperation"]))
+	})
+
+	It("restores the pagination", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		list := api.Resources["bottles"].Actions["list"]
+		Ω(list.Pagination).ShouldNot(BeNil())
+		Ω(list.Pagination.Parent).Should(BeIdenticalTo(list))
+		Ω(list.Pagination.Strategy).Should(Equal(Offset))
+		Ω(list.Pagination.MaxPageSize).Should(Equal(50))
+		Ω(list.Pagination.DefaultPageSize).Should(Equal(20))
+		Ω(list.Pagination.Envelope).Should(BeTrue())
+		Ω(list.Params.Type.ToObject()).Should(HaveKey("offset"))
+	})
+
+	It("restores the filters and sort fields", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		bottle := api.MediaTypes["application/vnd.goa.example.bottle"]
+		Ω(bottle.Filters).Should(HaveLen(1))
+		Ω(bottle.Filters[0].Attribute).Should(Equal("vintage"))
+		Ω(bottle.Filters[0].Operators).Should(Equal([]FilterOperator{Gte, Lte}))
+		Ω(bottle.SortFields).Should(Equal([]string{"name"}))
+		Ω(bottle.SparseFieldsets).Should(BeTrue())
+		Ω(api.Resources["bottles"].Actions["list"].Params.Type.ToObject()).Should(HaveKey("filter[vintage][gte]"))
+	})
+
+	It("restores the versions and lifecycles", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		Ω(api.Versions).Should(HaveLen(2))
+		Ω(api.Version).Should(Equal("v2"))
+		Ω(api.VersionScheme).Should(Equal(HeaderVersioning))
+		Ω(api.VersionKey).Should(Equal("X-API-Version"))
+		Ω(api.Versions[0].Parent).Should(Equal(api))
+		Ω(api.Versions[0].Deprecation.Sunset).Should(Equal("2027-06-30"))
+		rate := api.Resources["bottles"].Actions["rate"]
+		Ω(rate.Lifecycle.AddedIn).Should(Equal("v2"))
+		Ω(rate.Lifecycle.Deprecation.Since).Should(Equal("v2"))
+		Ω(rate.Lifecycle.Deprecation.Parent).Should(Equal(rate))
+	})
+
+	It("sets the parents", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		res := api.Resources["bottles"]
+		show := res.Actions["show"]
+		Ω(show.Parent).Should(BeIdenticalTo(res))
+		Ω(show.Routes[0].Parent).Should(BeIdenticalTo(show))
+		Ω(show.Responses["OK"].Parent).Should(BeIdenticalTo(show))
+		bottle := api.MediaTypes["application/vnd.goa.example.bottle"]
+		Ω(bottle.Views["tiny"].Parent).Should(BeIdenticalTo(bottle))
+		Ω(bottle.Links["account"].Parent).Should(BeIdenticalTo(bottle))
+		Ω(api.Origins["http://swagger.goa.design"].Parent).Should(BeIdenticalTo(api))
+	})
+
+	It("restores the Go values of defaults and examples", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		obj := api.Types["BottlePayload"].Type.ToObject()
+		Ω(obj["vintage"].DefaultValue).Should(Equal(2010))
+		Ω(obj["ratings"].DefaultValue).Should(Equal(map[interface{}]interface{}{1: "poor", 5: "great"}))
+		Ω(obj["name"].Example).Should(Equal("Number 8"))
+		Ω(obj["color"].Validation.Values).Should(Equal([]interface{}{"red", "white"}))
+		Ω(obj["label"].Nullable).Should(BeTrue())
+		Ω(obj["name"].Nullable).Should(BeFalse())
+	})
+
+	It("restores the cross-field validations", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		val := api.Types["BottlePayload"].Validation
+		Ω(val.DependentRequired).Should(Equal(map[string][]string{"region": {"color"}}))
+		Ω(val.Funcs).Should(HaveLen(1))
+		Ω(*val.Funcs[0]).Should(Equal(dslengine.ValidationFuncDefinition{
+			PackagePath: "example.com/validators",
+			Name:        "ValidateBottle",
+			Attributes:  []string{"name", "vintage"},
+		}))
+	})
+
+	It("restores the union types", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		u := api.Types["PaymentMethod"].ToUnion()
+		Ω(u).ShouldNot(BeNil())
+		Ω(u.Discriminator).Should(Equal("kind"))
+		Ω(u.VariantNames()).Should(Equal([]string{"card", "wallet"}))
+		Ω(u.Variants["card"].Type.ToObject()).Should(HaveKey("number"))
+	})
+
+	It("restores the security requirements", func() {
+		Ω(importErr).ShouldNot(HaveOccurred())
+		Ω(api.SecuritySchemes).Should(HaveLen(1))
+		show := api.Resources["bottles"].Actions["show"]
+		Ω(show.Security.Scheme).Should(BeIdenticalTo(api.SecuritySchemes[0]))
+		Ω(show.Security.Scopes).Should(Equal([]string{"api:read"}))
+		create := api.Resources["bottles"].Actions["create"]
+		Ω(create.Security).Should(BeNil())
+		Ω(api.Traits).Should(HaveKey("Authenticated"))
+	})
+
+	Context("with an unsupported version", func() {
+		BeforeEach(func() {
+			exported = []byte(`{"version":0,"api":{"name":"cellar"}}`)
+		})
+
+		It("fails", func() {
+			Ω(importErr).Should(MatchError("unsupported model version 0, expected 2"))
+		})
+	})
+
+	Context("with a newer version", func() {
+		BeforeEach(func() {
+			exported = []byte(`{"version":3,"api":{"name":"cellar","types":["unknown representation"]}}`)
+		})
+
+		It("fails before decoding the representation", func() {
+			Ω(importErr).Should(MatchError("model version 3 was exported by a newer goagen, this goagen supports version 2"))
+		})
+	})
+
+	Context("with an unknown type", func() {
+		BeforeEach(func() {
+			exported = []byte(`{"version":2,"api":{"name":"cellar","types":{"Foo":{"type":{"kind":"user_type","name":"Bar"}}}}}`)
+		})
+
+		It("fails", func() {
+			Ω(importErr).Should(MatchError(`unknown user type "Bar"`))
+		})
+	})
+})
@@ -0,0 +1,26 @@
+/*
+Package watch implements the "goagen watch" command which regenerates the code whenever the design
+changes.
+
+The watcher polls the Go source files of the design package and of the packages each generator
+depends on (the generator package itself, the hook packages registered with --hook). When the
+files of a package change the watcher waits for them to settle then re-runs the generators that
+depend on the package: a change to the design re-runs all the generators while a change to a
+hook package only re-runs the generators that render files through the goagen/ir package.
+
+When the design package changes the watcher compiles it once to export its JSON model (see the
+goagen/model package) and compares the model with the previous export: a change that does not
+affect the evaluated design, e.g. a comment or a refactoring of the DSL, regenerates nothing.
+The generators then load the model instead of compiling the design. Their compiled tools are
+cached and keyed on the digests of the packages they depend on while the model exporter is keyed
+on the digest of the design package so that only the exporter is compiled again on each change
+and reverting the design reuses a previous build.
+
+The generators run in a staging GOPATH that mirrors the output directory. Once they complete the
+watcher only writes the files whose content changed and removes the files the generators no
+longer produce so that editors and the go build cache are not invalidated needlessly.
+
+Errors reported by the DSL engine and the compiler are printed with the file and line they refer
+to, the watcher keeps running so that the next change to the design may fix them.
+*/
+package watch
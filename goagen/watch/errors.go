@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Error is an error reported while compiling or running a generator.
+	Error struct {
+		// File is the path to the file that caused the error if known.
+		File string
+		// Line is the line number in File, zero if unknown.
+		Line int
+		// Message is the error message.
+		Message string
+	}
+
+	// GenerationError is returned by Watcher.Generate when a generator fails.
+	GenerationError struct {
+		// Generator is the name of the failing generator.
+		Generator string
+		// Errors lists the errors reported by the generator.
+		Errors []*Error
+	}
+)
+
+var (
+	// dslErrorRegex matches the errors reported by the DSL engine: "[file:line] message".
+	dslErrorRegex = regexp.MustCompile(`^\[(.+):(\d+)\] (.*)$`)
+
+	// goErrorRegex matches the errors reported by the Go compiler: "file:line:column: message".
+	goErrorRegex = regexp.MustCompile(`^(.+\.go):(\d+)(?::\d+)?: (.*)$`)
+)
+
+// ParseErrors extracts the errors from the output of a failed generator. Lines that do not
+// refer to a file are returned as errors with no file except for the exit status and compiler
+// package headers.
+func ParseErrors(output string) []*Error {
+	var errs []*Error
+	for _, l := range strings.Split(output, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "exit status ") || strings.HasPrefix(l, "# ") {
+			continue
+		}
+		m := dslErrorRegex.FindStringSubmatch(l)
+		if m == nil {
+			m = goErrorRegex.FindStringSubmatch(l)
+		}
+		if m == nil {
+			errs = append(errs, &Error{Message: l})
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		errs = append(errs, &Error{File: m[1], Line: line, Message: m[3]})
+	}
+	return errs
+}
+
+// Error returns the error formatted as "file:line: message" so that editors may link to the file.
+func (e *Error) Error() string {
+	if e.File == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// Error lists the generator errors, one per line.
+func (e *GenerationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	if len(msgs) == 0 {
+		return fmt.Sprintf("%s generator failed", e.Generator)
+	}
+	return strings.Join(msgs, "\n")
+}
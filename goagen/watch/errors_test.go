@@ -0,0 +1,49 @@
+package watch
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseErrors", func() {
+	var output string
+	var errs []*Error
+
+	JustBeforeEach(func() {
+		errs = ParseErrors(output)
+	})
+
+	Context("with DSL errors", func() {
+		BeforeEach(func() {
+			output = "exit status 1\n[/go/src/app/design/design.go:12] invalid type\n[/go/src/app/design/design.go:20] missing name\n"
+		})
+
+		It("returns the file and line of the errors", func() {
+			Ω(errs).Should(HaveLen(2))
+			Ω(errs[0]).Should(Equal(&Error{File: "/go/src/app/design/design.go", Line: 12, Message: "invalid type"}))
+			Ω(errs[1].Error()).Should(Equal("/go/src/app/design/design.go:20: missing name"))
+		})
+	})
+
+	Context("with compiler errors", func() {
+		BeforeEach(func() {
+			output = "# app/design\ndesign/design.go:7:2: undefined: Foo\n"
+		})
+
+		It("returns the file and line of the errors", func() {
+			Ω(errs).Should(HaveLen(1))
+			Ω(errs[0]).Should(Equal(&Error{File: "design/design.go", Line: 7, Message: "undefined: Foo"}))
+		})
+	})
+
+	Context("with an error that does not refer to a file", func() {
+		BeforeEach(func() {
+			output = "GOPATH not set"
+		})
+
+		It("returns the message", func() {
+			Ω(errs).Should(HaveLen(1))
+			Ω(errs[0].Error()).Should(Equal("GOPATH not set"))
+		})
+	})
+})
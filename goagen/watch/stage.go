@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// checksums maps file paths to the SHA-256 checksum of their content.
+type checksums map[string][sha256.Size]byte
+
+// mirror copies the regular files of src to dst so that the generators running in dst find the
+// files they expect, e.g. the client tool main which is only generated once. The directories
+// the generators never write to, such as vendor, are linked rather than copied. mirror returns
+// the checksums of the copied files indexed by path relative to dst.
+func mirror(src, dst string) (checksums, error) {
+	copied := make(checksums)
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return copied, os.MkdirAll(dst, 0755)
+	}
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			if path == src {
+				return os.MkdirAll(target, 0755)
+			}
+			if strings.HasPrefix(info.Name(), "goagen") {
+				// Temporary directory created by the meta generator.
+				return filepath.SkipDir
+			}
+			if ignoredDir(info.Name()) || info.Name() == "node_modules" {
+				if err := os.Symlink(path, target); err != nil {
+					return err
+				}
+				return filepath.SkipDir
+			}
+			return os.Mkdir(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			copied[rel] = sha256.Sum256(b)
+			return ioutil.WriteFile(target, b, info.Mode().Perm())
+		}
+		return nil
+	})
+	return copied, err
+}
+
+// sync writes to out the files of staging that the generators created or modified and removes
+// from out the mirrored files that the generators removed. Files that were modified in out
+// while the generators ran are left untouched unless the generators modified them too.
+func sync(staging, out string, mirrored checksums) (*Result, error) {
+	res := &Result{}
+	seen := make(map[string]bool)
+	err := filepath.Walk(staging, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(staging, path)
+		if err != nil {
+			return err
+		}
+		seen[rel] = true
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if sum, ok := mirrored[rel]; ok && sum == sha256.Sum256(b) {
+			return nil
+		}
+		target := filepath.Join(out, rel)
+		if existing, err := ioutil.ReadFile(target); err == nil && bytes.Equal(existing, b) {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, b, info.Mode().Perm()); err != nil {
+			return err
+		}
+		res.Updated = append(res.Updated, target)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for rel := range mirrored {
+		if !seen[rel] {
+			removed = append(removed, rel)
+		}
+	}
+	sort.Strings(removed)
+	for _, rel := range removed {
+		target := filepath.Join(out, rel)
+		b, err := ioutil.ReadFile(target)
+		if err != nil || sha256.Sum256(b) != mirrored[rel] {
+			continue
+		}
+		if err := os.Remove(target); err != nil {
+			return nil, err
+		}
+		res.Removed = append(res.Removed, target)
+		// Remove the directories the generators removed.
+		for dir := filepath.Dir(rel); dir != "."; dir = filepath.Dir(dir) {
+			if _, err := os.Stat(filepath.Join(staging, dir)); err == nil {
+				break
+			}
+			if os.Remove(filepath.Join(out, dir)) != nil {
+				break
+			}
+		}
+	}
+	return res, nil
+}
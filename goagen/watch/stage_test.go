@@ -0,0 +1,91 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("mirror and sync", func() {
+	var dir, out, staging string
+	var mirrored checksums
+	var res *Result
+
+	write := func(path, content string) {
+		Ω(os.MkdirAll(filepath.Dir(path), 0755)).Should(Succeed())
+		Ω(ioutil.WriteFile(path, []byte(content), 0644)).Should(Succeed())
+	}
+
+	read := func(path string) string {
+		b, err := ioutil.ReadFile(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "watch")
+		Ω(err).ShouldNot(HaveOccurred())
+		out = filepath.Join(dir, "out")
+		staging = filepath.Join(dir, "staging")
+		write(filepath.Join(out, "app", "contexts.go"), "contexts")
+		write(filepath.Join(out, "app", "test", "bottle.go"), "test")
+		write(filepath.Join(out, "main.go"), "main")
+		write(filepath.Join(out, "vendor", "lib", "lib.go"), "lib")
+		mirrored, err = mirror(out, staging)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("copies the files and links the vendor directory", func() {
+		Ω(mirrored).Should(HaveLen(3))
+		Ω(read(filepath.Join(staging, "app", "contexts.go"))).Should(Equal("contexts"))
+		fi, err := os.Lstat(filepath.Join(staging, "vendor"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(fi.Mode() & os.ModeSymlink).ShouldNot(BeZero())
+	})
+
+	Context("once the generators ran", func() {
+		BeforeEach(func() {
+			Ω(os.RemoveAll(filepath.Join(staging, "app"))).Should(Succeed())
+			write(filepath.Join(staging, "app", "contexts.go"), "contexts")
+			write(filepath.Join(staging, "app", "controllers.go"), "controllers")
+			write(filepath.Join(staging, "main.go"), "main")
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			res, err = sync(staging, out, mirrored)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("only writes the new and modified files", func() {
+			Ω(res.Updated).Should(Equal([]string{filepath.Join(out, "app", "controllers.go")}))
+			Ω(read(filepath.Join(out, "app", "controllers.go"))).Should(Equal("controllers"))
+		})
+
+		It("removes the files and directories the generators removed", func() {
+			Ω(res.Removed).Should(Equal([]string{filepath.Join(out, "app", "test", "bottle.go")}))
+			_, err := os.Stat(filepath.Join(out, "app", "test"))
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+			Ω(read(filepath.Join(out, "vendor", "lib", "lib.go"))).Should(Equal("lib"))
+		})
+
+		Context("with a file modified while the generators ran", func() {
+			BeforeEach(func() {
+				write(filepath.Join(out, "main.go"), "edited")
+			})
+
+			It("keeps the modification", func() {
+				Ω(res.Updated).ShouldNot(ContainElement(filepath.Join(out, "main.go")))
+				Ω(read(filepath.Join(out, "main.go"))).Should(Equal("edited"))
+			})
+		})
+	})
+})
@@ -0,0 +1,389 @@
+package watch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/meta"
+	"github.com/goadesign/goa/goagen/model"
+)
+
+type (
+	// Watcher runs generators each time the design or the generator packages change.
+	Watcher struct {
+		// DesignPkg is the import path of the design package.
+		DesignPkg string
+		// OutDir is the output directory of the generators.
+		OutDir string
+		// Generators lists the generators run by the watcher in order.
+		Generators []*Generator
+		// Interval is the polling interval.
+		Interval time.Duration
+		// Output receives the generation reports.
+		Output io.Writer
+		// Model exports the JSON model of the design, see the goagen/model package. If set
+		// Run compiles the design package once per change to export the model, skips the
+		// generation if the model did not change and runs the generators from the model.
+		Model *meta.Generator
+
+		// tools is the directory where the compiled generators and the model are kept.
+		tools string
+		// built lists the digests of the packages the last generation ran with.
+		built digests
+		// model is the checksum of the last exported model, empty if the export failed.
+		model string
+		// stale is true if the last generation failed.
+		stale bool
+	}
+
+	// Generator describes a generator run by the watcher.
+	Generator struct {
+		// Name identifies the generator in the reports, e.g. "app".
+		Name string
+		// Meta compiles and runs the generator. The watcher overrides its output
+		// directory to run it in the staging area.
+		Meta *meta.Generator
+		// Packages lists the import paths of the packages the generator depends on in
+		// addition to the design package, e.g. the generator package or hook packages.
+		Packages []string
+	}
+
+	// Result lists the files written and removed by a generation.
+	Result struct {
+		// Updated lists the paths of the files whose content changed.
+		Updated []string
+		// Removed lists the paths of the files the generators no longer produce.
+		Removed []string
+	}
+
+	// digests maps package import paths to the digest of their Go source files.
+	digests map[string]string
+)
+
+// DefaultInterval is the default polling interval.
+const DefaultInterval = 500 * time.Millisecond
+
+// Run generates the code then polls the packages and regenerates the code affected by each change
+// until stop is closed. Generation errors are reported to Output and do not stop the watcher, Run
+// only returns an error if the packages cannot be read.
+func (w *Watcher) Run(stop <-chan struct{}) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	tools, err := ioutil.TempDir("", "goagen-watch-tools")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tools)
+	w.tools = tools
+	built, err := w.digests()
+	if err != nil {
+		return err
+	}
+	w.build(built, digests{}.changed(built))
+	last := built
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+		cur, err := w.digests()
+		if err != nil {
+			return err
+		}
+		if !cur.equal(last) {
+			// Wait for the files to settle, editors may save in several steps.
+			last = cur
+			continue
+		}
+		if cur.equal(built) {
+			continue
+		}
+		changed := built.changed(cur)
+		built = cur
+		w.build(cur, changed)
+	}
+}
+
+// build exports the model if the design changed then runs the generators affected by the
+// changed packages.
+func (w *Watcher) build(cur digests, changed []string) {
+	w.built = cur
+	if w.Model != nil && contains(changed, w.DesignPkg) {
+		prev := w.model
+		if err := w.export(); err != nil {
+			fmt.Fprintf(w.Output, "[%s] model: export failed\n%s\n", time.Now().Format("15:04:05"), err)
+			return
+		}
+		if w.model == prev && !w.stale {
+			// The change does not affect the evaluated design, e.g. a comment.
+			changed = remove(changed, w.DesignPkg)
+			if len(w.affected(changed)) == 0 {
+				fmt.Fprintf(w.Output, "[%s] model: unchanged\n", time.Now().Format("15:04:05"))
+				return
+			}
+		}
+	}
+	w.run(w.affected(changed))
+}
+
+// export compiles the design package and exports its model to the tools directory. The model
+// generator is cached using the digest of the design package so that reverting the design does
+// not compile it again.
+func (w *Watcher) export() error {
+	w.model = ""
+	m := *w.Model
+	m.OutDir = w.tools
+	m.Flags = make(map[string]string, len(w.Model.Flags))
+	for k, v := range w.Model.Flags {
+		m.Flags[k] = v
+	}
+	m.Flags["out"] = w.tools
+	m.ToolCache = w.tools
+	m.ToolKey = w.built[w.DesignPkg]
+	if _, err := m.Generate(); err != nil {
+		return &GenerationError{Generator: "model", Errors: ParseErrors(err.Error())}
+	}
+	b, err := ioutil.ReadFile(w.modelPath())
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(b)
+	w.model = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// modelPath returns the path to the exported model.
+func (w *Watcher) modelPath() string {
+	return filepath.Join(w.tools, model.ModelFile)
+}
+
+// Generate runs the given generators in a staging area that mirrors the output directory then
+// writes the files whose content changed and removes the files that are not generated anymore.
+func (w *Watcher) Generate(gens []*Generator) (*Result, error) {
+	outDir, err := filepath.Abs(w.OutDir)
+	if err != nil {
+		return nil, err
+	}
+	outPkg, err := codegen.PackagePath(outDir)
+	if err != nil {
+		return nil, err
+	}
+	staging, err := ioutil.TempDir("", "goagen-watch")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+	stagingOut := filepath.Join(staging, "src", filepath.FromSlash(outPkg))
+	mirrored, err := mirror(outDir, stagingOut)
+	if err != nil {
+		return nil, err
+	}
+
+	// Put the staging area first in GOPATH so that the generators compute the same import
+	// paths as when they run in the output directory.
+	gopath := os.Getenv("GOPATH")
+	os.Setenv("GOPATH", staging+string(os.PathListSeparator)+gopath)
+	defer os.Setenv("GOPATH", gopath)
+	for _, g := range gens {
+		m := *g.Meta
+		m.OutDir = stagingOut
+		m.Flags = make(map[string]string, len(g.Meta.Flags))
+		for k, v := range g.Meta.Flags {
+			m.Flags[k] = v
+		}
+		m.Flags["out"] = stagingOut
+		if w.model != "" {
+			// The generator loads the model at runtime so that it only needs to be
+			// compiled again when the packages it depends on change.
+			m.ModelPath = w.modelPath()
+			m.ToolCache = w.tools
+			m.ToolKey = w.key(g)
+		}
+		if _, err := m.Generate(); err != nil {
+			msg := strings.Replace(err.Error(), stagingOut, outDir, -1)
+			return nil, &GenerationError{Generator: g.Name, Errors: ParseErrors(msg)}
+		}
+	}
+
+	return sync(stagingOut, outDir, mirrored)
+}
+
+// run runs the given generators and reports the outcome.
+func (w *Watcher) run(gens []*Generator) {
+	if len(gens) == 0 {
+		return
+	}
+	names := make([]string, len(gens))
+	for i, g := range gens {
+		names[i] = g.Name
+	}
+	start := time.Now()
+	res, err := w.Generate(gens)
+	w.stale = err != nil
+	prefix := fmt.Sprintf("[%s] %s:", start.Format("15:04:05"), strings.Join(names, ", "))
+	if err != nil {
+		fmt.Fprintf(w.Output, "%s generation failed\n%s\n", prefix, err)
+		return
+	}
+	elapsed := time.Since(start).Round(10 * time.Millisecond)
+	if len(res.Updated) == 0 && len(res.Removed) == 0 {
+		fmt.Fprintf(w.Output, "%s up to date (%s)\n", prefix, elapsed)
+		return
+	}
+	fmt.Fprintf(w.Output, "%s %d file(s) updated, %d removed (%s)\n", prefix, len(res.Updated), len(res.Removed), elapsed)
+	for _, f := range res.Updated {
+		fmt.Fprintf(w.Output, "  updated %s\n", w.rel(f))
+	}
+	for _, f := range res.Removed {
+		fmt.Fprintf(w.Output, "  removed %s\n", w.rel(f))
+	}
+}
+
+// affected returns the generators that depend on one of the given packages.
+func (w *Watcher) affected(pkgs []string) []*Generator {
+	changed := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		changed[p] = true
+	}
+	if changed[w.DesignPkg] {
+		return w.Generators
+	}
+	var gens []*Generator
+	for _, g := range w.Generators {
+		for _, p := range g.Packages {
+			if changed[p] {
+				gens = append(gens, g)
+				break
+			}
+		}
+	}
+	return gens
+}
+
+// digests computes the digests of the design package and of the generator packages.
+func (w *Watcher) digests() (digests, error) {
+	pkgs := []string{w.DesignPkg}
+	for _, g := range w.Generators {
+		pkgs = append(pkgs, g.Packages...)
+	}
+	res := make(digests, len(pkgs))
+	for _, p := range pkgs {
+		if _, ok := res[p]; ok {
+			continue
+		}
+		dir, err := codegen.PackageSourcePath(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid package import path %q: %s", p, err)
+		}
+		if res[p], err = digest(dir); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// key returns the key of the compiled generator: the digests of the packages it depends on.
+func (w *Watcher) key(g *Generator) string {
+	keys := make([]string, len(g.Packages))
+	for i, p := range g.Packages {
+		keys[i] = p + "@" + w.built[p]
+	}
+	return strings.Join(keys, " ")
+}
+
+// rel returns the path of the given file relative to the output directory if possible.
+func (w *Watcher) rel(path string) string {
+	if r, err := filepath.Rel(w.OutDir, path); err == nil {
+		return r
+	}
+	return path
+}
+
+// equal returns true if d and other have the same digests.
+func (d digests) equal(other digests) bool {
+	return len(d.changed(other)) == 0 && len(d) == len(other)
+}
+
+// changed returns the sorted import paths of the packages whose digest differ in other.
+func (d digests) changed(other digests) []string {
+	var pkgs []string
+	for p, sum := range other {
+		if d[p] != sum {
+			pkgs = append(pkgs, p)
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// digest computes the digest of the Go source files in dir and its sub-directories. It skips the
+// directories ignored by the go tool and the temporary directories created by goagen.
+func digest(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path != dir && (ignoredDir(name) || strings.HasPrefix(name, "goagen")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s %d\n", path, len(b))
+		h.Write(b)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ignoredDir returns true if the go tool ignores the directory with the given name.
+func ignoredDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor"
+}
+
+// contains returns true if pkgs contains pkg.
+func contains(pkgs []string, pkg string) bool {
+	for _, p := range pkgs {
+		if p == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// remove returns pkgs without pkg.
+func remove(pkgs []string, pkg string) []string {
+	var res []string
+	for _, p := range pkgs {
+		if p != pkg {
+			res = append(res, p)
+		}
+	}
+	return res
+}
@@ -0,0 +1,53 @@
+package watch
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Watcher", func() {
+	var app, client, swagger *Generator
+	var w *Watcher
+
+	BeforeEach(func() {
+		app = &Generator{Name: "app", Packages: []string{"gen_app", "hooks"}}
+		client = &Generator{Name: "client", Packages: []string{"gen_client", "hooks"}}
+		swagger = &Generator{Name: "swagger", Packages: []string{"gen_swagger"}}
+		w = &Watcher{DesignPkg: "design", Generators: []*Generator{app, client, swagger}}
+	})
+
+	Describe("affected", func() {
+		It("returns all the generators when the design changes", func() {
+			Ω(w.affected([]string{"design"})).Should(Equal([]*Generator{app, client, swagger}))
+		})
+
+		It("returns the generators that depend on the changed packages", func() {
+			Ω(w.affected([]string{"hooks"})).Should(Equal([]*Generator{app, client}))
+			Ω(w.affected([]string{"gen_swagger"})).Should(Equal([]*Generator{swagger}))
+		})
+
+		It("returns no generator if no dependency changed", func() {
+			Ω(w.affected([]string{"other"})).Should(BeEmpty())
+		})
+	})
+
+	Describe("key", func() {
+		It("changes when a package the generator depends on changes", func() {
+			w.built = digests{"design": "a", "gen_app": "b", "hooks": "c"}
+			key := w.key(app)
+			w.built["design"] = "d"
+			Ω(w.key(app)).Should(Equal(key))
+			w.built["hooks"] = "e"
+			Ω(w.key(app)).ShouldNot(Equal(key))
+		})
+	})
+
+	Describe("digests", func() {
+		It("lists the changed packages", func() {
+			d := digests{"design": "a", "hooks": "b"}
+			Ω(d.changed(digests{"design": "a", "hooks": "c"})).Should(Equal([]string{"hooks"}))
+			Ω(d.equal(digests{"design": "a", "hooks": "b"})).Should(BeTrue())
+			Ω(d.equal(digests{"design": "a"})).Should(BeFalse())
+		})
+	})
+})
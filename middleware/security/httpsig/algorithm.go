@@ -0,0 +1,88 @@
+package httpsig
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+// Algorithm computes and verifies signatures.
+type Algorithm interface {
+	// Name returns the algorithm name as used in the "alg" signature parameter.
+	Name() string
+	// Sign returns the signature of the given signature base.
+	Sign(base []byte) ([]byte, error)
+	// Verify returns nil if sig is a valid signature of base.
+	Verify(base, sig []byte) error
+}
+
+// ErrInvalidSignature is the error returned by the algorithms Verify method when the signature
+// does not match.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+type (
+	hmacSHA256 struct {
+		secret []byte
+	}
+
+	ed25519Alg struct {
+		priv ed25519.PrivateKey
+		pub  ed25519.PublicKey
+	}
+)
+
+// HMACSHA256 returns the "hmac-sha256" algorithm using the given shared secret.
+func HMACSHA256(secret []byte) Algorithm {
+	return &hmacSHA256{secret: secret}
+}
+
+// Ed25519 returns the "ed25519" algorithm using the given private key to sign and its public key
+// to verify.
+func Ed25519(priv ed25519.PrivateKey) Algorithm {
+	return &ed25519Alg{priv: priv, pub: priv.Public().(ed25519.PublicKey)}
+}
+
+// Ed25519Public returns the "ed25519" algorithm that can only verify signatures using the given
+// public key.
+func Ed25519Public(pub ed25519.PublicKey) Algorithm {
+	return &ed25519Alg{pub: pub}
+}
+
+// Name returns "hmac-sha256".
+func (a *hmacSHA256) Name() string { return "hmac-sha256" }
+
+// Sign computes the HMAC of base.
+func (a *hmacSHA256) Sign(base []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write(base)
+	return mac.Sum(nil), nil
+}
+
+// Verify compares the HMAC of base with sig in constant time.
+func (a *hmacSHA256) Verify(base, sig []byte) error {
+	expected, _ := a.Sign(base)
+	if !hmac.Equal(expected, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Name returns "ed25519".
+func (a *ed25519Alg) Name() string { return "ed25519" }
+
+// Sign signs base with the private key.
+func (a *ed25519Alg) Sign(base []byte) ([]byte, error) {
+	if a.priv == nil {
+		return nil, errors.New("ed25519: no private key")
+	}
+	return ed25519.Sign(a.priv, base), nil
+}
+
+// Verify checks sig using the public key.
+func (a *ed25519Alg) Verify(base, sig []byte) error {
+	if !ed25519.Verify(a.pub, base, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
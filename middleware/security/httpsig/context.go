@@ -0,0 +1,24 @@
+package httpsig
+
+import "context"
+
+type contextKey int
+
+const (
+	keyIDKey contextKey = iota + 1
+	digestKey
+)
+
+// WithKeyID creates a child context containing the identifier of the key used to sign the request.
+func WithKeyID(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, keyIDKey, keyID)
+}
+
+// ContextKeyID retrieves the identifier of the key used to sign the request from the context. It
+// returns an empty string if the request signature was not verified.
+func ContextKeyID(ctx context.Context) string {
+	if k, ok := ctx.Value(keyIDKey).(string); ok {
+		return k
+	}
+	return ""
+}
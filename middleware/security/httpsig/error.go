@@ -0,0 +1,7 @@
+package httpsig
+
+import "github.com/goadesign/goa"
+
+// ErrHTTPSigError is the error returned by this middleware when the request signature is missing or
+// invalid.
+var ErrHTTPSigError = goa.NewErrorClass("httpsig_security_error", 401)
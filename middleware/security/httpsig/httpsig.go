@@ -0,0 +1,183 @@
+package httpsig
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// DefaultMaxSkew is the default maximum difference allowed between the signature creation time and
+// the server clock.
+const DefaultMaxSkew = 5 * time.Minute
+
+type (
+	// KeyResolver returns the algorithm and key used to verify signatures created with the key
+	// identified by keyID.
+	KeyResolver func(keyID string) (Algorithm, error)
+
+	// Config configures the verification middleware.
+	Config struct {
+		// Keys resolves the key identifiers listed in the signatures.
+		Keys KeyResolver
+		// Required lists the components that signatures must cover, defaults to
+		// DefaultComponents.
+		Required []string
+		// MaxSkew is the maximum difference allowed between the signature creation time and
+		// the server clock, defaults to DefaultMaxSkew.
+		MaxSkew time.Duration
+		// Nonces records the signature nonces to detect replays, defaults to an in-memory
+		// cache. Signatures without nonce are rejected.
+		Nonces NonceCache
+	}
+)
+
+// New returns a middleware to be used with the HTTPSigSecurity DSL definitions of goa. It verifies
+// HTTP message signatures (https://datatracker.ietf.org/doc/html/draft-ietf-httpbis-message-signatures)
+// created for example by the client package HTTPSigSigner.
+//
+// The steps taken by the middleware are:
+//
+//     1. Parse the Signature-Input and Signature headers
+//     2. Check that the signature covers the required components and was created within the
+//        allowed clock skew
+//     3. Verify the Content-Digest header against the request body
+//     4. Resolve the key and verify the signature
+//     5. Reject nonces that were already seen
+//
+// The identifier of the key used to sign the request is stored in the request context and can be
+// retrieved with ContextKeyID.
+//
+// The code generated by goagen preserves the body of the requests sent to actions secured with
+// HTTP message signatures so that the middleware can verify the body digest after the payload is
+// decoded. Handlers served outside of the generated controllers may be wrapped with DigestBody to
+// compute the digest before the body is consumed.
+//
+//    keys := func(keyID string) (httpsig.Algorithm, error) {
+//        return httpsig.HMACSHA256(secrets[keyID]), nil
+//    }
+//    app.UseHTTPSigMiddleware(service, httpsig.New(&httpsig.Config{Keys: keys}, nil, app.NewHTTPSigSecurity()))
+//
+func New(config *Config, validationFunc goa.Middleware, scheme *goa.HTTPSigSecurity) goa.Middleware {
+	required := config.Required
+	if required == nil {
+		required = DefaultComponents
+	}
+	maxSkew := config.MaxSkew
+	if maxSkew == 0 {
+		maxSkew = DefaultMaxSkew
+	}
+	nonces := config.Nonces
+	if nonces == nil {
+		nonces = NewMemoryNonceCache()
+	}
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			p, sig, err := ParseRequest(req)
+			if err != nil {
+				return ErrHTTPSigError(err)
+			}
+			for _, r := range required {
+				if !covers(p, r) {
+					return ErrHTTPSigError("signature does not cover required component", "component", r)
+				}
+			}
+			now := time.Now()
+			if p.Created.IsZero() {
+				return ErrHTTPSigError("missing signature creation time")
+			}
+			if d := now.Sub(p.Created); d > maxSkew || d < -maxSkew {
+				return ErrHTTPSigError("signature creation time outside of allowed clock skew")
+			}
+			if !p.Expires.IsZero() && now.After(p.Expires) {
+				return ErrHTTPSigError("signature expired")
+			}
+			if p.Nonce == "" {
+				return ErrHTTPSigError("missing signature nonce")
+			}
+			if covers(p, "content-digest") {
+				digest, err := requestDigest(req)
+				if err != nil {
+					return ErrHTTPSigError(err)
+				}
+				if !equalDigest(digest, req.Header.Get(ContentDigestHeader)) {
+					return ErrHTTPSigError("content digest mismatch")
+				}
+			}
+			if config.Keys == nil {
+				return ErrHTTPSigError("no key resolver configured")
+			}
+			alg, err := config.Keys(p.KeyID)
+			if err != nil || alg == nil {
+				return ErrHTTPSigError("unknown key", "keyid", p.KeyID)
+			}
+			if p.Alg != "" && p.Alg != alg.Name() {
+				return ErrHTTPSigError("signature algorithm mismatch", "alg", p.Alg)
+			}
+			base, err := SignatureBase(req, p)
+			if err != nil {
+				return ErrHTTPSigError(err)
+			}
+			if err := alg.Verify(base, sig); err != nil {
+				return ErrHTTPSigError(err, "keyid", p.KeyID)
+			}
+			if nonces.Seen(p.KeyID, p.Nonce, now.Add(2*maxSkew)) {
+				return ErrHTTPSigError("signature replayed", "keyid", p.KeyID)
+			}
+
+			ctx = WithKeyID(ctx, p.KeyID)
+			if validationFunc != nil {
+				nextHandler = validationFunc(nextHandler)
+			}
+			return nextHandler(ctx, rw, req)
+		}
+	}
+}
+
+// DigestBody returns a handler that reads the request body, records its digest in the request
+// context for the verification middleware and restores the body before calling h.
+func DigestBody(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Body != nil {
+			body, err := ioutil.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				http.Error(rw, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			req = req.WithContext(context.WithValue(req.Context(), digestKey, ContentDigest(body)))
+		}
+		h.ServeHTTP(rw, req)
+	})
+}
+
+// requestDigest returns the digest recorded by DigestBody or computes it from the request body.
+func requestDigest(req *http.Request) (string, error) {
+	if d, ok := req.Context().Value(digestKey).(string); ok {
+		return d, nil
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return "", err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return ContentDigest(body), nil
+}
+
+// covers returns true if the signature parameters list the given component.
+func covers(p *Params, component string) bool {
+	for _, c := range p.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package httpsig_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
+	"github.com/goadesign/goa/middleware/security/httpsig"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var secret = []byte("shared-secret")
+	var config *httpsig.Config
+	var signer *client.HTTPSigSigner
+	var request *http.Request
+	var handler goa.Handler
+	var dispatchResult error
+	var fetchedKeyID string
+	var fetchedBody string
+
+	BeforeEach(func() {
+		fetchedKeyID = ""
+		fetchedBody = ""
+		config = &httpsig.Config{
+			Keys: func(keyID string) (httpsig.Algorithm, error) {
+				if keyID != "partner" {
+					return nil, errors.New("unknown key")
+				}
+				return httpsig.HMACSHA256(secret), nil
+			},
+		}
+		signer = &client.HTTPSigSigner{KeyID: "partner", Algorithm: httpsig.HMACSHA256(secret)}
+		request, _ = http.NewRequest("POST", "http://example.com/bottles?sort=name", strings.NewReader(`{"name":"bottle"}`))
+		handler = func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			fetchedKeyID = httpsig.ContextKeyID(ctx)
+			body, _ := ioutil.ReadAll(r.Body)
+			fetchedBody = string(body)
+			return nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		middleware := httpsig.New(config, nil, &goa.HTTPSigSecurity{})
+		dispatchResult = middleware(handler)(context.Background(), httptest.NewRecorder(), request)
+	})
+
+	Context("with an unsigned request", func() {
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(fetchedKeyID).Should(BeEmpty())
+		})
+	})
+
+	Context("with a request signed by the client signer", func() {
+		BeforeEach(func() {
+			Ω(signer.Sign(request)).ShouldNot(HaveOccurred())
+		})
+
+		It("accepts the request", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(fetchedKeyID).Should(Equal("partner"))
+			Ω(fetchedBody).Should(Equal(`{"name":"bottle"}`))
+		})
+
+		Context("and a tampered path", func() {
+			BeforeEach(func() {
+				request.URL.Path = "/admin"
+			})
+
+			It("rejects the request", func() {
+				Ω(dispatchResult).Should(HaveOccurred())
+			})
+		})
+
+		Context("and a tampered body", func() {
+			BeforeEach(func() {
+				request.Body = ioutil.NopCloser(strings.NewReader(`{"name":"other"}`))
+			})
+
+			It("rejects the request", func() {
+				Ω(dispatchResult).Should(HaveOccurred())
+				Ω(dispatchResult.Error()).Should(ContainSubstring("content digest mismatch"))
+			})
+		})
+
+		Context("and an unknown key", func() {
+			BeforeEach(func() {
+				signer.KeyID = "unknown"
+				Ω(signer.Sign(request)).ShouldNot(HaveOccurred())
+			})
+
+			It("rejects the request", func() {
+				Ω(dispatchResult).Should(HaveOccurred())
+			})
+		})
+
+		Context("and a requirement on an uncovered component", func() {
+			BeforeEach(func() {
+				config.Required = []string{"@method", "content-type"}
+			})
+
+			It("rejects the request", func() {
+				Ω(dispatchResult).Should(HaveOccurred())
+				Ω(dispatchResult.Error()).Should(ContainSubstring("content-type"))
+			})
+		})
+	})
+
+	Context("with a replayed request", func() {
+		BeforeEach(func() {
+			Ω(signer.Sign(request)).ShouldNot(HaveOccurred())
+			config.Nonces = httpsig.NewMemoryNonceCache()
+			replay, _ := http.NewRequest("POST", "http://example.com/bottles?sort=name", strings.NewReader(`{"name":"bottle"}`))
+			replay.Header = request.Header
+			err := httpsig.New(config, nil, &goa.HTTPSigSecurity{})(handler)(context.Background(), httptest.NewRecorder(), replay)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.Error()).Should(ContainSubstring("replayed"))
+		})
+	})
+
+	Context("with a signature created outside of the allowed clock skew", func() {
+		BeforeEach(func() {
+			request.Header.Set(httpsig.ContentDigestHeader, httpsig.ContentDigest([]byte(`{"name":"bottle"}`)))
+			params := &httpsig.Params{
+				Components: httpsig.DefaultComponents,
+				Created:    time.Now().Add(-time.Hour),
+				KeyID:      "partner",
+				Nonce:      "abc",
+			}
+			Ω(httpsig.SignRequest(request, httpsig.HMACSHA256(secret), params)).ShouldNot(HaveOccurred())
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.Error()).Should(ContainSubstring("clock skew"))
+		})
+	})
+
+	Context("with a covered header with surrounding whitespace", func() {
+		BeforeEach(func() {
+			request.Header["X-Tenant"] = []string{"  acme ", " cellar"}
+			request.Header.Set(httpsig.ContentDigestHeader, httpsig.ContentDigest([]byte(`{"name":"bottle"}`)))
+			params := &httpsig.Params{
+				Components: append([]string{"x-tenant"}, httpsig.DefaultComponents...),
+				Created:    time.Now(),
+				KeyID:      "partner",
+				Nonce:      "abc",
+			}
+			Ω(httpsig.SignRequest(request, httpsig.HMACSHA256(secret), params)).ShouldNot(HaveOccurred())
+		})
+
+		It("verifies the signature without modifying the request headers", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(request.Header["X-Tenant"]).Should(Equal([]string{"  acme ", " cellar"}))
+		})
+	})
+
+	Context("with an ed25519 signature", func() {
+		BeforeEach(func() {
+			pub, priv, err := ed25519.GenerateKey(rand.Reader)
+			Ω(err).ShouldNot(HaveOccurred())
+			config.Keys = func(keyID string) (httpsig.Algorithm, error) {
+				return httpsig.Ed25519Public(pub), nil
+			}
+			signer = &client.HTTPSigSigner{KeyID: "partner", Algorithm: httpsig.Ed25519(priv)}
+			Ω(signer.Sign(request)).ShouldNot(HaveOccurred())
+		})
+
+		It("accepts the request", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(fetchedKeyID).Should(Equal("partner"))
+		})
+	})
+})
+
+var _ = Describe("DigestBody", func() {
+	It("records the digest of the body consumed before the middleware runs", func() {
+		secret := []byte("shared-secret")
+		config := &httpsig.Config{
+			Keys: func(string) (httpsig.Algorithm, error) { return httpsig.HMACSHA256(secret), nil },
+		}
+		var verifyErr error
+		mw := httpsig.New(config, nil, &goa.HTTPSigSecurity{})
+		h := httpsig.DigestBody(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			ioutil.ReadAll(req.Body) // simulate payload decoding
+			verifyErr = mw(func(context.Context, http.ResponseWriter, *http.Request) error {
+				return nil
+			})(context.Background(), rw, req)
+		}))
+
+		body := []byte(`{"name":"bottle"}`)
+		req, _ := http.NewRequest("PUT", "http://example.com/bottles/1", bytes.NewReader(body))
+		signer := &client.HTTPSigSigner{KeyID: "partner", Algorithm: httpsig.HMACSHA256(secret)}
+		Ω(signer.Sign(req)).ShouldNot(HaveOccurred())
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		Ω(verifyErr).ShouldNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,49 @@
+package httpsig
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// NonceCache records the nonces of verified signatures to detect replays.
+	NonceCache interface {
+		// Seen records the nonce for the given key until expiry and returns true if it was
+		// already recorded.
+		Seen(keyID, nonce string, expiry time.Time) bool
+	}
+
+	// memoryNonceCache is a NonceCache that keeps the nonces in memory.
+	memoryNonceCache struct {
+		sync.Mutex
+		nonces    map[string]time.Time
+		lastPurge time.Time
+	}
+)
+
+// NewMemoryNonceCache returns a NonceCache that keeps the nonces in memory. It is only suitable for
+// services running a single instance.
+func NewMemoryNonceCache() NonceCache {
+	return &memoryNonceCache{nonces: make(map[string]time.Time)}
+}
+
+// Seen implements NonceCache.
+func (c *memoryNonceCache) Seen(keyID, nonce string, expiry time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	if now.Sub(c.lastPurge) > time.Minute {
+		for k, exp := range c.nonces {
+			if now.After(exp) {
+				delete(c.nonces, k)
+			}
+		}
+		c.lastPurge = now
+	}
+	key := keyID + "\x00" + nonce
+	if exp, ok := c.nonces[key]; ok && now.Before(exp) {
+		return true
+	}
+	c.nonces[key] = expiry
+	return false
+}
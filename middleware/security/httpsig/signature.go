@@ -0,0 +1,260 @@
+package httpsig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureInputHeader is the name of the header that lists the signature parameters.
+	SignatureInputHeader = "Signature-Input"
+	// SignatureHeader is the name of the header that contains the signature.
+	SignatureHeader = "Signature"
+	// ContentDigestHeader is the name of the header that contains the request body digest.
+	ContentDigestHeader = "Content-Digest"
+	// DefaultLabel is the label used to identify the signature created by SignRequest.
+	DefaultLabel = "sig1"
+)
+
+// DefaultComponents lists the components covered by signatures by default: the request method,
+// path and query string as well as the body digest.
+var DefaultComponents = []string{"@method", "@path", "@query", "content-digest"}
+
+// Params contains the signature parameters, see
+// https://datatracker.ietf.org/doc/html/draft-ietf-httpbis-message-signatures
+type Params struct {
+	// Label identifies the signature in the Signature-Input and Signature headers.
+	Label string
+	// Components lists the covered components: derived components such as "@method" or
+	// "@path" and lowercase header field names.
+	Components []string
+	// Created is the signature creation time.
+	Created time.Time
+	// Expires is the optional signature expiration time.
+	Expires time.Time
+	// KeyID identifies the key used to create the signature.
+	KeyID string
+	// Alg is the name of the signature algorithm, e.g. "hmac-sha256".
+	Alg string
+	// Nonce is a random value used to detect replays.
+	Nonce string
+}
+
+// ContentDigest returns the value of the Content-Digest header for the given body.
+func ContentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+// SignRequest signs the request with the given algorithm and sets the Signature-Input and Signature
+// headers. The request must already contain the headers listed in the covered components, including
+// the Content-Digest header if covered.
+func SignRequest(req *http.Request, alg Algorithm, p *Params) error {
+	if p.Label == "" {
+		p.Label = DefaultLabel
+	}
+	if p.Alg == "" {
+		p.Alg = alg.Name()
+	}
+	base, err := SignatureBase(req, p)
+	if err != nil {
+		return err
+	}
+	sig, err := alg.Sign(base)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(SignatureInputHeader, p.Label+"="+p.String())
+	req.Header.Set(SignatureHeader, p.Label+"=:"+base64.StdEncoding.EncodeToString(sig)+":")
+	return nil
+}
+
+// SignatureBase computes the signature base for the request, that is the data that is signed.
+func SignatureBase(req *http.Request, p *Params) ([]byte, error) {
+	var lines []string
+	for _, c := range p.Components {
+		val, err := componentValue(req, c)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, fmt.Sprintf("%q: %s", c, val))
+	}
+	lines = append(lines, fmt.Sprintf("%q: %s", "@signature-params", p.String()))
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// String serializes the parameters as they appear in the Signature-Input header (without the
+// label).
+func (p *Params) String() string {
+	comps := make([]string, len(p.Components))
+	for i, c := range p.Components {
+		comps[i] = strconv.Quote(c)
+	}
+	s := "(" + strings.Join(comps, " ") + ")"
+	if !p.Created.IsZero() {
+		s += ";created=" + strconv.FormatInt(p.Created.Unix(), 10)
+	}
+	if !p.Expires.IsZero() {
+		s += ";expires=" + strconv.FormatInt(p.Expires.Unix(), 10)
+	}
+	if p.KeyID != "" {
+		s += ";keyid=" + strconv.Quote(p.KeyID)
+	}
+	if p.Alg != "" {
+		s += ";alg=" + strconv.Quote(p.Alg)
+	}
+	if p.Nonce != "" {
+		s += ";nonce=" + strconv.Quote(p.Nonce)
+	}
+	return s
+}
+
+// ParseRequest parses the Signature-Input and Signature headers of the request. It returns the
+// parameters and the signature of the first signature listed in Signature-Input.
+func ParseRequest(req *http.Request) (*Params, []byte, error) {
+	input := req.Header.Get(SignatureInputHeader)
+	if input == "" {
+		return nil, nil, fmt.Errorf("missing %s header", SignatureInputHeader)
+	}
+	p, err := parseSignatureInput(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := parseSignature(req.Header.Get(SignatureHeader), p.Label)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, sig, nil
+}
+
+// parseSignatureInput parses the first member of a Signature-Input header value, e.g.
+// `sig1=("@method" "@path");created=1618884473;keyid="key"`.
+func parseSignatureInput(h string) (*Params, error) {
+	idx := strings.Index(h, "=")
+	if idx <= 0 {
+		return nil, errors.New("invalid signature input")
+	}
+	p := &Params{Label: strings.TrimSpace(h[:idx])}
+	rest := strings.TrimSpace(h[idx+1:])
+	if !strings.HasPrefix(rest, "(") {
+		return nil, errors.New("invalid signature input, expected inner list")
+	}
+	end := strings.Index(rest, ")")
+	if end < 0 {
+		return nil, errors.New("invalid signature input, unterminated inner list")
+	}
+	for _, item := range strings.Fields(rest[1:end]) {
+		c, err := strconv.Unquote(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature input component %s", item)
+		}
+		p.Components = append(p.Components, c)
+	}
+	rest = rest[end+1:]
+	if comma := strings.Index(rest, ","); comma >= 0 {
+		rest = rest[:comma] // ignore additional signatures
+	}
+	for _, param := range strings.Split(rest, ";") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid signature parameter %s", param)
+		}
+		key, val := kv[0], kv[1]
+		switch key {
+		case "created", "expires":
+			secs, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid signature parameter %s", param)
+			}
+			if key == "created" {
+				p.Created = time.Unix(secs, 0)
+			} else {
+				p.Expires = time.Unix(secs, 0)
+			}
+		case "keyid", "alg", "nonce":
+			s, err := strconv.Unquote(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid signature parameter %s", param)
+			}
+			switch key {
+			case "keyid":
+				p.KeyID = s
+			case "alg":
+				p.Alg = s
+			default:
+				p.Nonce = s
+			}
+		}
+	}
+	return p, nil
+}
+
+// parseSignature extracts the signature with the given label from a Signature header value, e.g.
+// `sig1=:base64:`.
+func parseSignature(h, label string) ([]byte, error) {
+	if h == "" {
+		return nil, fmt.Errorf("missing %s header", SignatureHeader)
+	}
+	for _, member := range strings.Split(h, ",") {
+		kv := strings.SplitN(strings.TrimSpace(member), "=", 2)
+		if len(kv) != 2 || kv[0] != label {
+			continue
+		}
+		val := kv[1]
+		if len(val) < 2 || val[0] != ':' || val[len(val)-1] != ':' {
+			return nil, errors.New("invalid signature encoding")
+		}
+		return base64.StdEncoding.DecodeString(val[1 : len(val)-1])
+	}
+	return nil, fmt.Errorf("no signature with label %q", label)
+}
+
+// componentValue returns the value of the given covered component.
+func componentValue(req *http.Request, c string) (string, error) {
+	switch c {
+	case "@method":
+		return strings.ToUpper(req.Method), nil
+	case "@authority":
+		host := req.Host
+		if host == "" {
+			host = req.URL.Host
+		}
+		return strings.ToLower(host), nil
+	case "@path":
+		p := req.URL.EscapedPath()
+		if p == "" {
+			p = "/"
+		}
+		return p, nil
+	case "@query":
+		return "?" + req.URL.RawQuery, nil
+	}
+	if strings.HasPrefix(c, "@") {
+		return "", fmt.Errorf("unsupported derived component %q", c)
+	}
+	vals := req.Header[http.CanonicalHeaderKey(c)]
+	if len(vals) == 0 {
+		return "", fmt.Errorf("missing covered header %q", c)
+	}
+	trimmed := make([]string, len(vals))
+	for i, v := range vals {
+		trimmed[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(trimmed, ", "), nil
+}
+
+// equalDigest compares two Content-Digest values in constant time.
+func equalDigest(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
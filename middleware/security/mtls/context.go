@@ -0,0 +1,42 @@
+package mtls
+
+import (
+	"context"
+	"crypto/x509"
+)
+
+type contextKey int
+
+const (
+	identityKey contextKey = iota + 1
+)
+
+// Identity describes the client authenticated by the middleware.
+type Identity struct {
+	// Certificate is the client (leaf) certificate.
+	Certificate *x509.Certificate
+	// Chain is the verified certificate chain, starting with Certificate.
+	Chain []*x509.Certificate
+	// Subject is the distinguished name of the client certificate subject.
+	Subject string
+	// SANs lists the DNS, email address, IP address and URI subject alternative names of the
+	// client certificate.
+	SANs []string
+	// SPIFFEID is the first "spiffe" URI SAN of the client certificate if any.
+	SPIFFEID string
+}
+
+// WithIdentity creates a child context containing the given client identity.
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey, id)
+}
+
+// ContextIdentity retrieves the client identity from a `context` that went through our security
+// middleware.
+func ContextIdentity(ctx context.Context) *Identity {
+	id, ok := ctx.Value(identityKey).(*Identity)
+	if !ok {
+		return nil
+	}
+	return id
+}
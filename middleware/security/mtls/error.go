@@ -0,0 +1,7 @@
+package mtls
+
+import "github.com/goadesign/goa"
+
+// ErrMTLSError is the error returned by this middleware when the client certificate is missing or
+// not authorized.
+var ErrMTLSError = goa.NewErrorClass("mtls_security_error", 401)
@@ -0,0 +1,137 @@
+package mtls
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+// Policy lists the client identities allowed by the middleware. A client is authorized if the leaf
+// certificate of its verified chain matches any of the entries. An empty policy authorizes any
+// client that presents a certificate verified by the server TLS configuration.
+type Policy struct {
+	// Subjects lists the allowed certificate subjects. An entry matches either the subject
+	// common name or the complete distinguished name as formatted by pkix.Name.String, e.g.
+	// "CN=billing,O=Acme".
+	Subjects []string
+	// SANs lists the allowed DNS, email address, IP address or URI subject alternative names.
+	SANs []string
+	// SPIFFEIDs lists the allowed SPIFFE IDs, e.g. "spiffe://example.org/billing". An entry
+	// ending with "/*" matches any ID with the given prefix, e.g. "spiffe://example.org/*"
+	// matches all the workloads of the "example.org" trust domain.
+	SPIFFEIDs []string
+}
+
+// New returns a middleware to be used with the MTLSSecurity DSL definitions of goa. The server must
+// be configured to request and verify client certificates (see the ClientAuth field of
+// crypto/tls.Config), the middleware only inspects the chains verified during the TLS handshake.
+//
+// The steps taken by the middleware are:
+//
+//     1. Retrieve the leaf certificate of the first verified peer certificate chain
+//     2. Validate the certificate subject, subject alternative names and SPIFFE ID against the
+//        given policy
+//
+// The resulting identity is stored in the request context and can be retrieved with
+// ContextIdentity.
+//
+// You can define an optional function to do additional validations once the identity is
+// established. Example:
+//
+//	validationHandler, _ := goa.NewMiddleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+//		id := mtls.ContextIdentity(ctx)
+//		if id.Certificate.Subject.OrganizationalUnit[0] != "payments" {
+//			return mtls.ErrMTLSError("you are not part of the payments team")
+//		}
+//		return nil
+//	})
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//    policy := &mtls.Policy{SPIFFEIDs: []string{"spiffe://example.org/*"}}
+//    app.UseMTLSMiddleware(service, mtls.New(policy, validationHandler))
+//
+func New(policy *Policy, validationFunc goa.Middleware) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.TLS == nil {
+				return ErrMTLSError("request was not made over TLS")
+			}
+			if len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+				return ErrMTLSError("missing or unverified client certificate")
+			}
+			chain := req.TLS.VerifiedChains[0]
+			id := NewIdentity(chain)
+			if !policy.Allows(id) {
+				return ErrMTLSError("client certificate not authorized", "subject", id.Subject, "spiffe_id", id.SPIFFEID)
+			}
+
+			ctx = WithIdentity(ctx, id)
+			if validationFunc != nil {
+				nextHandler = validationFunc(nextHandler)
+			}
+			return nextHandler(ctx, rw, req)
+		}
+	}
+}
+
+// Allows returns true if the given identity is authorized by the policy.
+func (p *Policy) Allows(id *Identity) bool {
+	if p == nil || len(p.Subjects)+len(p.SANs)+len(p.SPIFFEIDs) == 0 {
+		return true
+	}
+	cert := id.Certificate
+	for _, s := range p.Subjects {
+		if s == cert.Subject.CommonName || s == id.Subject {
+			return true
+		}
+	}
+	for _, s := range p.SANs {
+		for _, san := range id.SANs {
+			if s == san {
+				return true
+			}
+		}
+	}
+	if id.SPIFFEID != "" {
+		for _, s := range p.SPIFFEIDs {
+			if strings.HasSuffix(s, "/*") {
+				if strings.HasPrefix(id.SPIFFEID, s[:len(s)-1]) {
+					return true
+				}
+				continue
+			}
+			if s == id.SPIFFEID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewIdentity builds the identity of the client that presented the given verified chain. The first
+// certificate of the chain is the client (leaf) certificate.
+func NewIdentity(chain []*x509.Certificate) *Identity {
+	cert := chain[0]
+	id := &Identity{
+		Certificate: cert,
+		Chain:       chain,
+		Subject:     cert.Subject.String(),
+	}
+	id.SANs = append(id.SANs, cert.DNSNames...)
+	id.SANs = append(id.SANs, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		id.SANs = append(id.SANs, ip.String())
+	}
+	for _, u := range cert.URIs {
+		id.SANs = append(id.SANs, u.String())
+		if u.Scheme == "spiffe" && id.SPIFFEID == "" {
+			id.SPIFFEID = u.String()
+		}
+	}
+	return id
+}
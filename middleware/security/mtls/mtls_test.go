@@ -0,0 +1,145 @@
+package mtls_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/mtls"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var policy *mtls.Policy
+	var request *http.Request
+	var handler goa.Handler
+	var dispatchResult error
+	var fetchedIdentity *mtls.Identity
+
+	BeforeEach(func() {
+		policy = nil
+		fetchedIdentity = nil
+		request, _ = http.NewRequest("GET", "https://example.com/", nil)
+		handler = func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			fetchedIdentity = mtls.ContextIdentity(ctx)
+			return nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		middleware := mtls.New(policy, nil)
+		dispatchResult = middleware(handler)(context.Background(), httptest.NewRecorder(), request)
+	})
+
+	Context("with a plain text request", func() {
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(fetchedIdentity).Should(BeNil())
+		})
+	})
+
+	Context("with no verified client certificate", func() {
+		BeforeEach(func() {
+			request.TLS = &tls.ConnectionState{}
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(fetchedIdentity).Should(BeNil())
+		})
+	})
+
+	Context("with a verified client certificate", func() {
+		BeforeEach(func() {
+			cert := newCertificate("billing", "billing.internal", "spiffe://example.org/billing")
+			request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		})
+
+		It("accepts the request when the policy is empty", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(fetchedIdentity).ShouldNot(BeNil())
+			Ω(fetchedIdentity.Certificate.Subject.CommonName).Should(Equal("billing"))
+			Ω(fetchedIdentity.SPIFFEID).Should(Equal("spiffe://example.org/billing"))
+			Ω(fetchedIdentity.SANs).Should(ConsistOf("billing.internal", "spiffe://example.org/billing"))
+		})
+
+		Context("and a policy matching the subject", func() {
+			BeforeEach(func() {
+				policy = &mtls.Policy{Subjects: []string{"billing"}}
+			})
+
+			It("accepts the request", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+				Ω(fetchedIdentity).ShouldNot(BeNil())
+			})
+		})
+
+		Context("and a policy matching a SAN", func() {
+			BeforeEach(func() {
+				policy = &mtls.Policy{SANs: []string{"billing.internal"}}
+			})
+
+			It("accepts the request", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("and a policy matching the SPIFFE trust domain", func() {
+			BeforeEach(func() {
+				policy = &mtls.Policy{SPIFFEIDs: []string{"spiffe://example.org/*"}}
+			})
+
+			It("accepts the request", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("and a policy that does not match", func() {
+			BeforeEach(func() {
+				policy = &mtls.Policy{
+					Subjects:  []string{"shipping"},
+					SANs:      []string{"shipping.internal"},
+					SPIFFEIDs: []string{"spiffe://example.org/shipping", "spiffe://other.org/*"},
+				}
+			})
+
+			It("rejects the request", func() {
+				Ω(dispatchResult).Should(HaveOccurred())
+				Ω(fetchedIdentity).Should(BeNil())
+			})
+		})
+	})
+})
+
+// newCertificate creates a self-signed certificate with the given common name, DNS name and URI
+// SANs.
+func newCertificate(cn, dns, uri string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ω(err).ShouldNot(HaveOccurred())
+	u, err := url.Parse(uri)
+	Ω(err).ShouldNot(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{dns},
+		URIs:         []*url.URL{u},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	Ω(err).ShouldNot(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Ω(err).ShouldNot(HaveOccurred())
+	return cert
+}
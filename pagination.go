@@ -0,0 +1,56 @@
+package goa
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CursorPageLink returns the value of a RFC 8288 Link header that points to the page of results
+// identified by the cursor next. The link reuses the query string of the request URL u so that
+// the next page is requested with the same parameters.
+func CursorPageLink(u *url.URL, next string) string {
+	return pageLink(u, "cursor", next, "next")
+}
+
+// OffsetPageLinks returns the value of a RFC 8288 Link header that points to the first, previous,
+// next and last pages of results relative to the page requested with the URL u. offset and limit
+// are the values of the page parameters, count the number of results in the page and total the
+// number of results in the collection or a negative value if unknown. The "last" link is only
+// included if total is known, without it the "next" link is included if the page is full.
+func OffsetPageLinks(u *url.URL, offset, limit, count, total int) string {
+	if limit <= 0 {
+		limit = 1
+	}
+	links := []string{pageLink(u, "offset", "0", "first")}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(u, "offset", strconv.Itoa(prev), "prev"))
+	}
+	next := offset + count
+	if total >= 0 && next < total || total < 0 && count > 0 && count >= limit {
+		links = append(links, pageLink(u, "offset", strconv.Itoa(next), "next"))
+	}
+	if total >= 0 {
+		last := 0
+		if total > 0 {
+			last = (total - 1) / limit * limit
+		}
+		links = append(links, pageLink(u, "offset", strconv.Itoa(last), "last"))
+	}
+	return strings.Join(links, ", ")
+}
+
+// pageLink returns a link with the given relation type to the URL u where the query string
+// parameter param is set to val.
+func pageLink(u *url.URL, param, val, rel string) string {
+	target := *u
+	values := target.Query()
+	values.Set(param, val)
+	target.RawQuery = values.Encode()
+	return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+}
@@ -0,0 +1,70 @@
+package goa_test
+
+import (
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CursorPageLink", func() {
+	It("links to the next page with the same query string", func() {
+		u, _ := url.Parse("/bottles?limit=10&cursor=a")
+		Ω(goa.CursorPageLink(u, "b")).Should(Equal(`</bottles?cursor=b&limit=10>; rel="next"`))
+	})
+})
+
+var _ = Describe("OffsetPageLinks", func() {
+	var u *url.URL
+	var offset, count, total int
+	var links string
+
+	BeforeEach(func() {
+		u, _ = url.Parse("/bottles?limit=10")
+		offset, count, total = 10, 10, 25
+	})
+
+	JustBeforeEach(func() {
+		links = goa.OffsetPageLinks(u, offset, 10, count, total)
+	})
+
+	It("links to the neighboring pages", func() {
+		Ω(links).Should(Equal(`</bottles?limit=10&offset=0>; rel="first", ` +
+			`</bottles?limit=10&offset=0>; rel="prev", ` +
+			`</bottles?limit=10&offset=20>; rel="next", ` +
+			`</bottles?limit=10&offset=20>; rel="last"`))
+	})
+
+	Context("on the last page", func() {
+		BeforeEach(func() {
+			offset, count = 20, 5
+		})
+
+		It("does not link to a next page", func() {
+			Ω(links).ShouldNot(ContainSubstring(`rel="next"`))
+			Ω(links).Should(ContainSubstring(`</bottles?limit=10&offset=20>; rel="last"`))
+		})
+	})
+
+	Context("with an unknown total", func() {
+		BeforeEach(func() {
+			offset, total = 0, -1
+		})
+
+		It("links to the next page of a full page", func() {
+			Ω(links).Should(Equal(`</bottles?limit=10&offset=0>; rel="first", ` +
+				`</bottles?limit=10&offset=10>; rel="next"`))
+		})
+
+		Context("and a partial page", func() {
+			BeforeEach(func() {
+				count = 3
+			})
+
+			It("does not link to a next page", func() {
+				Ω(links).Should(Equal(`</bottles?limit=10&offset=0>; rel="first"`))
+			})
+		})
+	})
+})
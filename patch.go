@@ -0,0 +1,451 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatchOperation is a single operation of a JSON patch document as described in RFC 6902.
+type PatchOperation struct {
+	// Op is the name of the operation: "add", "remove", "replace", "move", "copy" or "test".
+	Op string `json:"op"`
+	// Path is the JSON pointer to the location the operation applies to.
+	Path string `json:"path"`
+	// From is the JSON pointer to the source location of "move" and "copy" operations.
+	From string `json:"from,omitempty"`
+	// Value is the value used by the "add", "replace" and "test" operations.
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON encodes the operation. The value is always encoded for the "add", "replace" and
+// "test" operations even if null and omitted for the other operations.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	type operation PatchOperation
+	switch op.Op {
+	case "add", "replace", "test":
+		return json.Marshal(operation(op))
+	}
+	return json.Marshal(struct {
+		operation
+		Value interface{} `json:"value,omitempty"`
+	}{operation: operation(op)})
+}
+
+// ApplyMergePatch applies the JSON merge patch (RFC 7396) patch to the value pointed to by v. patch
+// may be any value whose JSON encoding is a merge patch document such as the payload of an action
+// defined with the MergePatch DSL.
+func ApplyMergePatch(patch, v interface{}) error {
+	p, err := jsonValue(patch)
+	if err != nil {
+		return err
+	}
+	doc, err := jsonValue(v)
+	if err != nil {
+		return err
+	}
+	return setJSONValue(v, mergePatch(doc, p))
+}
+
+// DiffMergePatch computes the JSON merge patch (RFC 7396) that turns orig into updated and decodes
+// it into the value pointed to by patch.
+func DiffMergePatch(orig, updated, patch interface{}) error {
+	o, err := jsonValue(orig)
+	if err != nil {
+		return err
+	}
+	u, err := jsonValue(updated)
+	if err != nil {
+		return err
+	}
+	return setJSONValue(patch, mergeDiff(o, u))
+}
+
+// ApplyJSONPatch applies the JSON patch (RFC 6902) patch to the value pointed to by v. patch may be
+// any value whose JSON encoding is a JSON patch document such as the payload of an action defined
+// with the JSONPatch DSL. The operations are applied in order, v is left untouched if any fails.
+func ApplyJSONPatch(patch, v interface{}) error {
+	var ops []*PatchOperation
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&ops); err != nil {
+		return err
+	}
+	doc, err := jsonValue(v)
+	if err != nil {
+		return err
+	}
+	for i, op := range ops {
+		if doc, err = op.apply(doc); err != nil {
+			return InvalidPatchError(i, op.Op, op.Path, err.Error())
+		}
+	}
+	return setJSONValue(v, doc)
+}
+
+// DiffJSONPatch computes the JSON patch (RFC 6902) that turns orig into updated and decodes it
+// into the value pointed to by patch. The patch adds, removes and replaces object members, arrays
+// that differ are replaced as a whole.
+func DiffJSONPatch(orig, updated, patch interface{}) error {
+	o, err := jsonValue(orig)
+	if err != nil {
+		return err
+	}
+	u, err := jsonValue(updated)
+	if err != nil {
+		return err
+	}
+	ops := jsonDiff("", o, u, []*PatchOperation{})
+	return setJSONValue(patch, ops)
+}
+
+// apply applies the operation to doc and returns the resulting document.
+func (op *PatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add":
+		return addValue(doc, path, op.Value)
+	case "remove":
+		return removeValue(doc, path)
+	case "replace":
+		return replaceValue(doc, path, op.Value)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			if v, err = jsonValue(v); err != nil {
+				return nil, err
+			}
+		} else {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, fmt.Errorf("cannot move %s into one of its children", op.From)
+			}
+			if doc, err = removeValue(doc, from); err != nil {
+				return nil, err
+			}
+		}
+		return addValue(doc, path, v)
+	case "test":
+		v, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		expected, err := jsonValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(v, expected) {
+			return nil, fmt.Errorf("value is %s", jsonString(v))
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// mergePatch applies the merge patch p to doc as described in RFC 7396 section 2.
+func mergePatch(doc, p interface{}) interface{} {
+	pm, ok := p.(map[string]interface{})
+	if !ok {
+		return p
+	}
+	dm, ok := doc.(map[string]interface{})
+	if !ok {
+		dm = make(map[string]interface{})
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(dm, k)
+			continue
+		}
+		dm[k] = mergePatch(dm[k], v)
+	}
+	return dm
+}
+
+// mergeDiff returns the merge patch that turns o into u.
+func mergeDiff(o, u interface{}) interface{} {
+	om, ok := o.(map[string]interface{})
+	if !ok {
+		return u
+	}
+	um, ok := u.(map[string]interface{})
+	if !ok {
+		return u
+	}
+	res := make(map[string]interface{})
+	for k := range om {
+		if _, ok := um[k]; !ok {
+			res[k] = nil
+		}
+	}
+	for k, uv := range um {
+		ov, ok := om[k]
+		if !ok {
+			res[k] = uv
+		} else if !reflect.DeepEqual(ov, uv) {
+			res[k] = mergeDiff(ov, uv)
+		}
+	}
+	return res
+}
+
+// jsonDiff appends the operations that turn o into u to ops and returns the result.
+func jsonDiff(path string, o, u interface{}, ops []*PatchOperation) []*PatchOperation {
+	if reflect.DeepEqual(o, u) {
+		return ops
+	}
+	om, ok := o.(map[string]interface{})
+	um, ok2 := u.(map[string]interface{})
+	if !ok || !ok2 {
+		return append(ops, &PatchOperation{Op: "replace", Path: path, Value: u})
+	}
+	keys := make([]string, 0, len(om)+len(um))
+	for k := range om {
+		keys = append(keys, k)
+	}
+	for k := range um {
+		if _, ok := om[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := path + "/" + escapePointer(k)
+		ov, inO := om[k]
+		uv, inU := um[k]
+		switch {
+		case !inU:
+			ops = append(ops, &PatchOperation{Op: "remove", Path: p})
+		case !inO:
+			ops = append(ops, &PatchOperation{Op: "add", Path: p, Value: uv})
+		default:
+			ops = jsonDiff(p, ov, uv, ops)
+		}
+	}
+	return ops
+}
+
+// getValue returns the value located at path in doc.
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for _, tok := range path {
+		switch d := doc.(type) {
+		case map[string]interface{}:
+			v, ok := d[tok]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", tok)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(tok, len(d)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = d[i]
+		default:
+			return nil, fmt.Errorf("cannot lookup %q in %s", tok, jsonString(doc))
+		}
+	}
+	return doc, nil
+}
+
+// addValue adds v at path in doc and returns the resulting document.
+func addValue(doc interface{}, path []string, v interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	return updateParent(doc, path, func(parent interface{}, tok string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[tok] = v
+			return p, nil
+		case []interface{}:
+			if tok == "-" {
+				return append(p, v), nil
+			}
+			i, err := arrayIndex(tok, len(p))
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = v
+			return p, nil
+		default:
+			return nil, fmt.Errorf("cannot add %q to %s", tok, jsonString(parent))
+		}
+	})
+}
+
+// removeValue removes the value at path in doc and returns the resulting document.
+func removeValue(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	return updateParent(doc, path, func(parent interface{}, tok string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[tok]; !ok {
+				return nil, fmt.Errorf("member %q not found", tok)
+			}
+			delete(p, tok)
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(tok, len(p)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:i], p[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove %q from %s", tok, jsonString(parent))
+		}
+	})
+}
+
+// replaceValue replaces the value at path in doc with v and returns the resulting document.
+func replaceValue(doc interface{}, path []string, v interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	return updateParent(doc, path, func(parent interface{}, tok string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[tok]; !ok {
+				return nil, fmt.Errorf("member %q not found", tok)
+			}
+			p[tok] = v
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(tok, len(p)-1)
+			if err != nil {
+				return nil, err
+			}
+			p[i] = v
+			return p, nil
+		default:
+			return nil, fmt.Errorf("cannot replace %q in %s", tok, jsonString(parent))
+		}
+	})
+}
+
+// updateParent calls fn with the value containing the location referenced by path and the last
+// token of path and replaces the value with the result.
+func updateParent(doc interface{}, path []string, fn func(interface{}, string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		child, ok := d[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", path[0])
+		}
+		v, err := updateParent(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		d[path[0]] = v
+		return d, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(d)-1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := updateParent(d[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = v
+		return d, nil
+	default:
+		return nil, fmt.Errorf("cannot lookup %q in %s", path[0], jsonString(doc))
+	}
+}
+
+// parsePointer returns the reference tokens of the JSON pointer (RFC 6901) p.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", p)
+	}
+	toks := strings.Split(p[1:], "/")
+	for i, t := range toks {
+		toks[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+	return toks, nil
+}
+
+// escapePointer escapes the JSON pointer reference token t.
+func escapePointer(t string) string {
+	return strings.Replace(strings.Replace(t, "~", "~0", -1), "/", "~1", -1)
+}
+
+// arrayIndex parses the array index tok and checks that it is lower or equal to max.
+func arrayIndex(tok string, max int) (int, error) {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (tok != "0" && tok[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of bounds", i)
+	}
+	return i, nil
+}
+
+// jsonValue returns the generic representation of the JSON encoding of v.
+func jsonValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// setJSONValue decodes the JSON encoding of val into the value pointed to by v after resetting it
+// so that the members missing from val are cleared.
+func setJSONValue(v, val interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("cannot decode into non-pointer %T", v)
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	return json.Unmarshal(b, v)
+}
+
+// jsonString returns the JSON encoding of v for use in error messages.
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,149 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type patchBottle struct {
+	Name    string   `json:"name"`
+	Vintage *int     `json:"vintage,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+var _ = Describe("ApplyMergePatch", func() {
+	var patch map[string]interface{}
+	var bottle *patchBottle
+	var err error
+
+	BeforeEach(func() {
+		vintage := 2000
+		bottle = &patchBottle{Name: "old", Vintage: &vintage, Tags: []string{"a"}}
+		patch = map[string]interface{}{"name": "new", "vintage": nil}
+	})
+
+	JustBeforeEach(func() {
+		err = goa.ApplyMergePatch(patch, bottle)
+	})
+
+	It("replaces and removes members", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(bottle.Name).Should(Equal("new"))
+		Ω(bottle.Vintage).Should(BeNil())
+		Ω(bottle.Tags).Should(Equal([]string{"a"}))
+	})
+
+	Context("with a patch that does not match the target type", func() {
+		BeforeEach(func() {
+			patch = map[string]interface{}{"name": 42}
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("DiffMergePatch", func() {
+	It("computes a patch that transforms the original value", func() {
+		vintage := 2000
+		orig := &patchBottle{Name: "old", Vintage: &vintage, Tags: []string{"a"}}
+		updated := &patchBottle{Name: "new", Tags: []string{"a"}}
+		var patch map[string]interface{}
+		Ω(goa.DiffMergePatch(orig, updated, &patch)).Should(Succeed())
+		Ω(patch).Should(Equal(map[string]interface{}{"name": "new", "vintage": nil}))
+		Ω(goa.ApplyMergePatch(patch, orig)).Should(Succeed())
+		Ω(orig).Should(Equal(updated))
+	})
+})
+
+var _ = Describe("PatchOperation", func() {
+	It("encodes null values of add, replace and test operations", func() {
+		b, err := json.Marshal(&goa.PatchOperation{Op: "replace", Path: "/vintage"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"op":"replace","path":"/vintage","value":null}`))
+	})
+
+	It("omits the value of the other operations", func() {
+		b, err := json.Marshal([]goa.PatchOperation{{Op: "remove", Path: "/vintage"}})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`[{"op":"remove","path":"/vintage"}]`))
+	})
+})
+
+var _ = Describe("ApplyJSONPatch", func() {
+	var ops []*goa.PatchOperation
+	var bottle *patchBottle
+	var err error
+
+	BeforeEach(func() {
+		bottle = &patchBottle{Name: "old", Tags: []string{"a", "b"}}
+		ops = nil
+	})
+
+	JustBeforeEach(func() {
+		err = goa.ApplyJSONPatch(ops, bottle)
+	})
+
+	Context("with add, remove, move and copy operations", func() {
+		BeforeEach(func() {
+			ops = []*goa.PatchOperation{
+				{Op: "test", Path: "/name", Value: "old"},
+				{Op: "add", Path: "/tags/-", Value: "c"},
+				{Op: "remove", Path: "/tags/0"},
+				{Op: "add", Path: "/vintage", Value: 1990},
+				{Op: "copy", From: "/tags/0", Path: "/name"},
+				{Op: "move", From: "/tags/1", Path: "/tags/0"},
+			}
+		})
+
+		It("applies the operations in order", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(bottle.Name).Should(Equal("b"))
+			Ω(bottle.Vintage).ShouldNot(BeNil())
+			Ω(*bottle.Vintage).Should(Equal(1990))
+			Ω(bottle.Tags).Should(Equal([]string{"c", "b"}))
+		})
+	})
+
+	Context("with a failing test operation", func() {
+		BeforeEach(func() {
+			ops = []*goa.PatchOperation{
+				{Op: "replace", Path: "/name", Value: "new"},
+				{Op: "test", Path: "/name", Value: "other"},
+			}
+		})
+
+		It("returns an error and leaves the value unchanged", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`patch operation #1 (test "/name") failed`))
+			Ω(bottle.Name).Should(Equal("old"))
+		})
+	})
+
+	Context("with a path that does not exist", func() {
+		BeforeEach(func() {
+			ops = []*goa.PatchOperation{{Op: "remove", Path: "/vintage"}}
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("DiffJSONPatch", func() {
+	It("computes operations that transform the original value", func() {
+		vintage := 2000
+		orig := &patchBottle{Name: "old", Vintage: &vintage, Tags: []string{"a"}}
+		updated := &patchBottle{Name: "new", Tags: []string{"a", "b"}}
+		var ops []*goa.PatchOperation
+		Ω(goa.DiffJSONPatch(orig, updated, &ops)).Should(Succeed())
+		Ω(ops).Should(HaveLen(3))
+		Ω(goa.ApplyJSONPatch(ops, orig)).Should(Succeed())
+		Ω(orig).Should(Equal(updated))
+	})
+})
@@ -75,3 +75,19 @@ type JWTSecurity struct {
 	// Scopes defines a list of scopes for the security scheme, along with their description.
 	Scopes map[string]string
 }
+
+// MTLSSecurity represents the mutual TLS security scheme. Clients authenticate by presenting a
+// certificate that the server verifies during the TLS handshake, the identity is then read from the
+// verified peer certificate chain.
+type MTLSSecurity struct {
+	// Description of the security scheme
+	Description string
+}
+
+// HTTPSigSecurity represents the HTTP message signatures security scheme. Clients sign the request
+// method, path, body digest and selected headers with a shared secret or a private key and send
+// the result in the Signature and Signature-Input headers.
+type HTTPSigSecurity struct {
+	// Description of the security scheme
+	Description string
+}
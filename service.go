@@ -1,10 +1,13 @@
 package goa
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
@@ -40,8 +43,9 @@ type (
 		// Response body encoder
 		Encoder *HTTPEncoder
 
-		middleware []Middleware       // Middleware chain
-		cancel     context.CancelFunc // Service context cancel signal trigger
+		middleware    []Middleware             // Middleware chain
+		cancel        context.CancelFunc       // Service context cancel signal trigger
+		versionRoutes map[string]*versionRoute // Routes served by multiple API versions
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -267,6 +271,20 @@ func (service *Service) EncodeResponse(ctx context.Context, v interface{}) error
 	return service.Encoder.Encode(v, ContextResponse(ctx), accept)
 }
 
+// EncodesJSON returns true if the response to the request is encoded in JSON. defaultType is the
+// content type of the default encoder used when no encoder is registered for the request Accept
+// header.
+func (service *Service) EncodesJSON(req *http.Request, defaultType string) bool {
+	contentType := service.Encoder.ContentType(req.Header.Get("Accept"))
+	if contentType == "*/*" {
+		contentType = defaultType
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
 // ServeFiles replies to the request with the contents of the named file or directory. See
 // FileHandler for details.
 func (ctrl *Controller) ServeFiles(path, filename string) error {
@@ -348,6 +366,23 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 	}
 }
 
+// PreserveBody returns an unmarshaler that runs unm and then restores the request body so that the
+// middlewares can read it again, for example to verify its digest. goagen uses it for the actions
+// secured with HTTP message signatures.
+func PreserveBody(unm Unmarshaler) Unmarshaler {
+	return func(ctx context.Context, service *Service, req *http.Request) error {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		err = unm(ctx, service, req)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return err
+	}
+}
+
 // FileHandler returns a handler that serves files under the given filename for the given route path.
 // The logic for what to do when the filename points to a file vs. a directory is the same as the
 // standard http package ServeFile function. The path may end with a wildcard that matches the rest
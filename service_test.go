@@ -497,3 +497,20 @@ func (t *TestResponseWriter) Write(b []byte) (int, error) {
 func (t *TestResponseWriter) WriteHeader(s int) {
 	t.Status = s
 }
+
+var _ = Describe("PreserveBody", func() {
+	It("restores the body read by the unmarshaler", func() {
+		req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(`{"name":"bottle"}`))
+		var decoded []byte
+		unm := goa.PreserveBody(func(ctx context.Context, service *goa.Service, req *http.Request) error {
+			var err error
+			decoded, err = ioutil.ReadAll(req.Body)
+			return err
+		})
+		Ω(unm(context.Background(), nil, req)).ShouldNot(HaveOccurred())
+		Ω(string(decoded)).Should(Equal(`{"name":"bottle"}`))
+		body, err := ioutil.ReadAll(req.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(body)).Should(Equal(`{"name":"bottle"}`))
+	})
+})
@@ -0,0 +1,144 @@
+package goa
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SortKey is an attribute to sort a collection by as listed in the "sort" query string parameter.
+type SortKey struct {
+	// Field is the name of the attribute.
+	Field string
+	// Desc is true if the collection is sorted in descending order of the attribute values.
+	Desc bool
+}
+
+// ParseSort parses the value of a "sort" query string parameter, a comma separated list of
+// attribute names each optionally prefixed with "-" for descending order, e.g. "-vintage,name".
+func ParseSort(s string) []SortKey {
+	var keys []SortKey
+	for _, f := range ParseFields(s) {
+		if strings.HasPrefix(f, "-") {
+			keys = append(keys, SortKey{Field: f[1:], Desc: true})
+		} else {
+			keys = append(keys, SortKey{Field: f})
+		}
+	}
+	return keys
+}
+
+// ParseFields parses the value of a "fields" query string parameter, a comma separated list of
+// attribute names.
+func ParseFields(s string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// String returns the representation of the key in the "sort" query string parameter.
+func (k SortKey) String() string {
+	if k.Desc {
+		return "-" + k.Field
+	}
+	return k.Field
+}
+
+// SelectFields returns a projection of v that only includes the given fields. v is a struct, a
+// pointer to a struct or a slice of these, typically a media type or a collection media type. The
+// projection replaces each struct with a map indexed by the JSON names of the selected fields,
+// fields omitted when empty are left out of the map if empty. The projection may thus only be
+// encoded in JSON, see Service.EncodesJSON. SelectFields returns v if fields is empty.
+func SelectFields(v interface{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		return v
+	}
+	keep := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		keep[f] = true
+	}
+	return selectFields(reflect.ValueOf(v), keep)
+}
+
+// selectFields projects v recursively.
+func selectFields(v reflect.Value, keep map[string]bool) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		res := make([]interface{}, v.Len())
+		for i := range res {
+			res[i] = selectFields(v.Index(i), keep)
+		}
+		return res
+	case reflect.Struct:
+		res := make(map[string]interface{})
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			name, omitEmpty := jsonField(f)
+			if !keep[name] {
+				continue
+			}
+			fv := v.Field(i)
+			if omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			res[name] = fv.Interface()
+		}
+		return res
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// jsonField returns the JSON name of the struct field and whether it is omitted when empty.
+func jsonField(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			return name, true
+		}
+	}
+	return name, false
+}
+
+// isEmptyValue returns true if v is the empty value of its type as defined by the "omitempty"
+// option of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
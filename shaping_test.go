@@ -0,0 +1,77 @@
+package goa_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseSort", func() {
+	It("parses the sort keys", func() {
+		keys := goa.ParseSort("-vintage, name,")
+		Ω(keys).Should(Equal([]goa.SortKey{{Field: "vintage", Desc: true}, {Field: "name"}}))
+		Ω(keys[0].String()).Should(Equal("-vintage"))
+		Ω(keys[1].String()).Should(Equal("name"))
+	})
+
+	It("returns nil for an empty value", func() {
+		Ω(goa.ParseSort("")).Should(BeNil())
+	})
+})
+
+var _ = Describe("SelectFields", func() {
+	type bottle struct {
+		ID      int     `json:"id"`
+		Name    string  `json:"name"`
+		Vintage *int    `json:"vintage,omitempty"`
+		Color   *string `json:"color,omitempty"`
+		secret  string
+	}
+
+	var vintage = 2010
+	var b = &bottle{ID: 1, Name: "red", Vintage: &vintage, secret: "x"}
+
+	It("only includes the selected fields", func() {
+		selected := goa.SelectFields(b, goa.ParseFields("id,vintage,color"))
+		Ω(selected).Should(Equal(map[string]interface{}{"id": 1, "vintage": &vintage}))
+	})
+
+	It("projects the elements of collections", func() {
+		selected := goa.SelectFields([]*bottle{b, nil}, []string{"name"})
+		Ω(selected).Should(Equal([]interface{}{map[string]interface{}{"name": "red"}, nil}))
+	})
+
+	It("returns the value as is without fields", func() {
+		Ω(goa.SelectFields(b, nil)).Should(BeIdenticalTo(b))
+	})
+})
+
+var _ = Describe("EncodesJSON", func() {
+	var service *goa.Service
+	var req *http.Request
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "application/json", "application/vnd.bottle+json")
+		service.Encoder.Register(goa.NewXMLEncoder, "application/xml")
+		req, _ = http.NewRequest("GET", "/bottles", nil)
+	})
+
+	It("returns true for the JSON encoders", func() {
+		req.Header.Set("Accept", "application/vnd.bottle+json")
+		Ω(service.EncodesJSON(req, "application/xml")).Should(BeTrue())
+	})
+
+	It("returns false for the other encoders", func() {
+		req.Header.Set("Accept", "application/xml")
+		Ω(service.EncodesJSON(req, "application/json")).Should(BeFalse())
+	})
+
+	It("uses the default encoder content type if no encoder matches", func() {
+		req.Header.Set("Accept", "text/plain")
+		Ω(service.EncodesJSON(req, "application/json")).Should(BeTrue())
+		Ω(service.EncodesJSON(req, "application/xml")).Should(BeFalse())
+	})
+})
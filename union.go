@@ -0,0 +1,100 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MarshalUnion returns the JSON encoding of v, the value of the union variant with the given name.
+// The encoding of v must be a JSON object, MarshalUnion adds the discriminator attribute set to
+// the variant name to the object if discriminator is not empty.
+func MarshalUnion(v interface{}, discriminator, name string) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || discriminator == "" {
+		return b, err
+	}
+	if len(b) < 2 || b[0] != '{' {
+		return nil, fmt.Errorf("union variant %q must be encoded as a JSON object", name)
+	}
+	k, _ := json.Marshal(discriminator)
+	n, _ := json.Marshal(name)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(n)
+	if len(bytes.TrimSpace(b[1:len(b)-1])) > 0 {
+		buf.WriteByte(',')
+	}
+	buf.Write(b[1:])
+	return buf.Bytes(), nil
+}
+
+// UnmarshalUnion decodes the JSON encoding of a union value into one of the given variant values
+// indexed by variant name and returns the name of the variant. If discriminator is not empty the
+// value of the discriminator attribute selects the variant. Otherwise the data must decode into
+// exactly one variant: it may not contain attributes unknown to the variant and the decoded value
+// must validate if the variant implements a Validate method.
+func UnmarshalUnion(data []byte, discriminator string, variants map[string]interface{}) (string, error) {
+	names := make([]string, 0, len(variants))
+	for n := range variants {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	if discriminator != "" {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return "", err
+		}
+		raw, ok := obj[discriminator]
+		if !ok {
+			return "", fmt.Errorf("missing discriminator attribute %q", discriminator)
+		}
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return "", fmt.Errorf("discriminator attribute %q must be a string", discriminator)
+		}
+		v, ok := variants[name]
+		if !ok {
+			return "", fmt.Errorf("invalid value %q for discriminator attribute %q, must be one of %s",
+				name, discriminator, quoteNames(names))
+		}
+		return name, json.Unmarshal(data, v)
+	}
+
+	var matches []string
+	for _, n := range names {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(variants[n]); err != nil {
+			continue
+		}
+		if v, ok := variants[n].(interface {
+			Validate() error
+		}); ok && v.Validate() != nil {
+			continue
+		}
+		matches = append(matches, n)
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("value does not match any of the variants %s", quoteNames(names))
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("value matches more than one of the variants %s", quoteNames(matches))
+	}
+}
+
+// quoteNames returns the comma separated list of the quoted names.
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%q", n)
+	}
+	return strings.Join(quoted, ", ")
+}
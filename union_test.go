@@ -0,0 +1,160 @@
+package goa_test
+
+import (
+	"errors"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type card struct {
+	Number *string `json:"number,omitempty"`
+}
+
+func (c *card) Validate() error {
+	if c.Number == nil {
+		return errors.New("missing number")
+	}
+	return nil
+}
+
+type wallet struct {
+	Provider *string `json:"provider,omitempty"`
+}
+
+var _ = Describe("MarshalUnion", func() {
+	var v interface{}
+	var discriminator string
+	var b []byte
+	var err error
+
+	BeforeEach(func() {
+		number := "4242"
+		v = &card{Number: &number}
+		discriminator = "kind"
+	})
+
+	JustBeforeEach(func() {
+		b, err = goa.MarshalUnion(v, discriminator, "card")
+	})
+
+	It("adds the discriminator", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"kind":"card","number":"4242"}`))
+	})
+
+	Context("with an empty object", func() {
+		BeforeEach(func() {
+			v = &card{}
+		})
+
+		It("adds the discriminator", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(Equal(`{"kind":"card"}`))
+		})
+	})
+
+	Context("with no discriminator", func() {
+		BeforeEach(func() {
+			discriminator = ""
+		})
+
+		It("encodes the variant", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(Equal(`{"number":"4242"}`))
+		})
+	})
+
+	Context("with a value that is not an object", func() {
+		BeforeEach(func() {
+			v = "foo"
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("UnmarshalUnion", func() {
+	var data string
+	var discriminator string
+	var variants map[string]interface{}
+	var name string
+	var err error
+
+	BeforeEach(func() {
+		discriminator = "kind"
+		variants = map[string]interface{}{"card": &card{}, "wallet": &wallet{}}
+	})
+
+	JustBeforeEach(func() {
+		name, err = goa.UnmarshalUnion([]byte(data), discriminator, variants)
+	})
+
+	Context("with a discriminator", func() {
+		BeforeEach(func() {
+			data = `{"kind":"card","number":"4242"}`
+		})
+
+		It("decodes the selected variant", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(name).Should(Equal("card"))
+			Ω(*variants["card"].(*card).Number).Should(Equal("4242"))
+		})
+	})
+
+	Context("with a missing discriminator", func() {
+		BeforeEach(func() {
+			data = `{"number":"4242"}`
+		})
+
+		It("fails", func() {
+			Ω(err).Should(MatchError(`missing discriminator attribute "kind"`))
+		})
+	})
+
+	Context("with an unknown variant", func() {
+		BeforeEach(func() {
+			data = `{"kind":"cash"}`
+		})
+
+		It("fails", func() {
+			Ω(err).Should(MatchError(`invalid value "cash" for discriminator attribute "kind", must be one of "card", "wallet"`))
+		})
+	})
+
+	Context("with no discriminator", func() {
+		BeforeEach(func() {
+			discriminator = ""
+			data = `{"provider":"acme"}`
+		})
+
+		It("decodes the matching variant", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(name).Should(Equal("wallet"))
+			Ω(*variants["wallet"].(*wallet).Provider).Should(Equal("acme"))
+		})
+
+		Context("and a value that matches several variants", func() {
+			BeforeEach(func() {
+				variants["other"] = &wallet{}
+			})
+
+			It("fails", func() {
+				Ω(err).Should(MatchError(`value matches more than one of the variants "other", "wallet"`))
+			})
+		})
+
+		Context("and a value that matches no variant", func() {
+			BeforeEach(func() {
+				data = `{"iban":"FR76"}`
+			})
+
+			It("fails", func() {
+				Ω(err).Should(MatchError(`value does not match any of the variants "card", "wallet"`))
+			})
+		})
+	})
+})
@@ -2,10 +2,13 @@ package goa
 
 import (
 	"fmt"
+	"math/big"
 	"net"
 	"net/mail"
 	"net/url"
+	"reflect"
 	"regexp"
+	"strconv"
 	"sync"
 	"time"
 
@@ -51,6 +54,9 @@ const (
 
 	// FormatRFC1123 defines RFC1123 date time values.
 	FormatRFC1123 = "rfc1123"
+
+	// FormatDecimal defines arbitrary precision decimal number values.
+	FormatDecimal = "decimal"
 )
 
 var (
@@ -59,6 +65,9 @@ var (
 
 	// Simple regular expression for IPv4 values, more rigorous checking is done via net.ParseIP
 	ipv4Regex = regexp.MustCompile(`^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`)
+
+	// Regular expression used to validate decimal values
+	decimalRegex = regexp.MustCompile(`^[-+]?[0-9]+(?:\.[0-9]+)?$`)
 )
 
 // ValidateFormat validates a string against a standard format.
@@ -76,6 +85,7 @@ var (
 //     - "cidr": RFC4632 and RFC4291 CIDR notation IP address value
 //     - "regexp": Regular expression syntax accepted by RE2
 //     - "rfc1123": RFC1123 date time value
+//     - "decimal": decimal number value such as "12.50"
 func ValidateFormat(f Format, val string) error {
 	var err error
 	switch f {
@@ -115,6 +125,10 @@ func ValidateFormat(f Format, val string) error {
 		_, err = regexp.Compile(val)
 	case FormatRFC1123:
 		_, err = time.Parse(time.RFC1123, val)
+	case FormatDecimal:
+		if !decimalRegex.MatchString(val) {
+			err = fmt.Errorf("\"%s\" is not a decimal number", val)
+		}
 	default:
 		return fmt.Errorf("unknown format %#v", f)
 	}
@@ -125,6 +139,67 @@ func ValidateFormat(f Format, val string) error {
 	return nil
 }
 
+// CompareDecimal compares the decimal value val with bound. It returns -1 if val is lower than
+// bound, 0 if they are equal and 1 if val is greater. Values that are not valid decimal numbers
+// compare lower than any bound.
+func CompareDecimal(val string, bound float64) int {
+	v, ok := new(big.Rat).SetString(val)
+	if !ok {
+		return -1
+	}
+	// Use the shortest decimal representation of bound so that "0.1" equals 0.1.
+	b, _ := new(big.Rat).SetString(strconv.FormatFloat(bound, 'g', -1, 64))
+	return v.Cmp(b)
+}
+
+// ValidateMultipleOf returns true if val is a multiple of divisor. Both values are compared using
+// their shortest decimal representation so that 0.3 is a multiple of 0.1.
+func ValidateMultipleOf(val, divisor float64) bool {
+	return ValidateDecimalMultipleOf(strconv.FormatFloat(val, 'g', -1, 64), divisor)
+}
+
+// ValidateDecimalMultipleOf returns true if the decimal value val is a multiple of divisor. Values
+// that are not valid decimal numbers are never multiples.
+func ValidateDecimalMultipleOf(val string, divisor float64) bool {
+	v, ok := new(big.Rat).SetString(val)
+	if !ok {
+		return false
+	}
+	d, _ := new(big.Rat).SetString(strconv.FormatFloat(divisor, 'g', -1, 64))
+	if d.Sign() == 0 {
+		return false
+	}
+	return v.Quo(v, d).IsInt()
+}
+
+// ValidateUniqueItems checks that the elements of the slice val are all different. It returns
+// the first duplicated element and false if not.
+func ValidateUniqueItems(val interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, true
+	}
+	if et := v.Type().Elem(); et.Comparable() && et.Kind() != reflect.Interface && et.Kind() != reflect.Ptr {
+		seen := make(map[interface{}]struct{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			e := v.Index(i).Interface()
+			if _, ok := seen[e]; ok {
+				return e, false
+			}
+			seen[e] = struct{}{}
+		}
+		return nil, true
+	}
+	for i := 0; i < v.Len(); i++ {
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(v.Index(i).Interface(), v.Index(j).Interface()) {
+				return v.Index(i).Interface(), false
+			}
+		}
+	}
+	return nil, true
+}
+
 // knownPatterns records the compiled patterns.
 // TBD: refactor all this so that the generated code initializes the map on start to get rid of the
 // need for a RW mutex.
@@ -147,3 +222,15 @@ func ValidatePattern(p string, val string) bool {
 	}
 	return r.MatchString(val)
 }
+
+// SetAttributes returns the names of the attributes whose corresponding set value is true. It is
+// used by the generated code to validate the OneOfRequired and MutuallyExclusive validations.
+func SetAttributes(names []string, set ...bool) []string {
+	var res []string
+	for i, n := range names {
+		if i < len(set) && set[i] {
+			res = append(res, n)
+		}
+	}
+	return res
+}
@@ -369,4 +369,83 @@ var _ = Describe("ValidateFormat", func() {
 			})
 		})
 	})
+
+	Context("Decimal", func() {
+		BeforeEach(func() {
+			f = goa.FormatDecimal
+		})
+
+		Context("with an invalid value", func() {
+			BeforeEach(func() {
+				val = "12.5e3"
+			})
+
+			It("does not validate", func() {
+				Ω(valErr).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a valid value", func() {
+			BeforeEach(func() {
+				val = "-1234.50"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+})
+
+var _ = Describe("CompareDecimal", func() {
+	It("compares decimal values with bounds", func() {
+		Ω(goa.CompareDecimal("12.50", 12.5)).Should(Equal(0))
+		Ω(goa.CompareDecimal("0.10", 0.1)).Should(Equal(0))
+		Ω(goa.CompareDecimal("0.10", 0.2)).Should(Equal(-1))
+		Ω(goa.CompareDecimal("100000000000000000000.01", 1e20)).Should(Equal(1))
+	})
+
+	It("compares invalid values lower than bounds", func() {
+		Ω(goa.CompareDecimal("foo", -1)).Should(Equal(-1))
+	})
+})
+
+var _ = Describe("ValidateMultipleOf", func() {
+	It("checks float values", func() {
+		Ω(goa.ValidateMultipleOf(0.3, 0.1)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(10, 2.5)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(0.35, 0.1)).Should(BeFalse())
+	})
+
+	It("checks decimal values", func() {
+		Ω(goa.ValidateDecimalMultipleOf("12.50", 0.01)).Should(BeTrue())
+		Ω(goa.ValidateDecimalMultipleOf("12.505", 0.01)).Should(BeFalse())
+		Ω(goa.ValidateDecimalMultipleOf("foo", 0.01)).Should(BeFalse())
+	})
+})
+
+var _ = Describe("ValidateUniqueItems", func() {
+	It("accepts unique elements", func() {
+		_, ok := goa.ValidateUniqueItems([]int{1, 2, 3})
+		Ω(ok).Should(BeTrue())
+	})
+
+	It("returns the first duplicated element", func() {
+		dup, ok := goa.ValidateUniqueItems([]string{"a", "b", "a"})
+		Ω(ok).Should(BeFalse())
+		Ω(dup).Should(Equal("a"))
+	})
+
+	It("compares non comparable elements deeply", func() {
+		dup, ok := goa.ValidateUniqueItems([]interface{}{[]int{1}, map[string]int{"a": 1}, []int{1}})
+		Ω(ok).Should(BeFalse())
+		Ω(dup).Should(Equal([]int{1}))
+	})
+})
+
+var _ = Describe("SetAttributes", func() {
+	It("returns the names of the set attributes", func() {
+		Ω(goa.SetAttributes([]string{"a", "b", "c"}, true, false, true)).Should(Equal([]string{"a", "c"}))
+		Ω(goa.SetAttributes([]string{"a", "b"}, false, false)).Should(BeEmpty())
+	})
 })
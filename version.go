@@ -0,0 +1,94 @@
+package goa
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type (
+	// VersionSelector returns the version of the API requested by req. The generated code
+	// uses a selector to dispatch the requests of the APIs that serve multiple versions at the
+	// same paths.
+	VersionSelector func(req *http.Request) string
+
+	// versionRoute holds the handlers of a route served by multiple API versions.
+	versionRoute struct {
+		selector VersionSelector
+		handlers map[string]MuxHandler
+	}
+)
+
+// HeaderVersion returns a version selector that reads the version from the request header with
+// the given name, def if the request does not set the header.
+func HeaderVersion(name, def string) VersionSelector {
+	return func(req *http.Request) string {
+		if v := req.Header.Get(name); v != "" {
+			return v
+		}
+		return def
+	}
+}
+
+// MediaTypeVersion returns a version selector that reads the version from the parameter with the
+// given name of the media types listed in the Accept request header, e.g.
+// "application/vnd.bottle+json; version=v2". It returns def if no media type sets the parameter.
+func MediaTypeVersion(param, def string) VersionSelector {
+	return func(req *http.Request) string {
+		for _, accept := range req.Header["Accept"] {
+			for _, mt := range strings.Split(accept, ",") {
+				_, params, err := mime.ParseMediaType(strings.TrimSpace(mt))
+				if err != nil {
+					continue
+				}
+				if v := params[param]; v != "" {
+					return v
+				}
+			}
+		}
+		return def
+	}
+}
+
+// HandleVersion registers the handler of the given version of the API for the requests with the
+// given method and path. Handlers of different versions may be registered for the same method
+// and path, the requests are then dispatched to the handler of the version returned by the
+// selector. Requests for a version with no handler are rejected with a bad request error.
+func (service *Service) HandleVersion(selector VersionSelector, version, method, path string, handler MuxHandler) {
+	key := method + " " + path
+	if route, ok := service.versionRoutes[key]; ok {
+		route.handlers[version] = handler
+		return
+	}
+	route := &versionRoute{
+		selector: selector,
+		handlers: map[string]MuxHandler{version: handler},
+	}
+	if service.versionRoutes == nil {
+		service.versionRoutes = make(map[string]*versionRoute)
+	}
+	service.versionRoutes[key] = route
+	service.Mux.Handle(method, path, func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		v := route.selector(req)
+		if h, ok := route.handlers[v]; ok {
+			h(rw, req, params)
+			return
+		}
+		ctx := NewContext(service.Context, rw, req, params)
+		service.Send(ctx, 400, ErrBadRequest("unsupported API version %#v", v))
+	})
+}
+
+// DeprecatedHandler returns a handler that adds the Deprecation header and, if sunset is not
+// empty, the RFC 8594 Sunset header with the given HTTP date to the responses of h.
+func DeprecatedHandler(h Handler, sunset string) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		rw.Header().Set("Deprecation", "true")
+		if sunset != "" {
+			rw.Header().Set("Sunset", sunset)
+		}
+		return h(ctx, rw, req)
+	}
+}
@@ -0,0 +1,115 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HeaderVersion", func() {
+	selector := goa.HeaderVersion("X-API-Version", "v2")
+
+	It("reads the version from the header", func() {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Version", "v1")
+		Ω(selector(req)).Should(Equal("v1"))
+	})
+
+	It("defaults to the given version", func() {
+		req, _ := http.NewRequest("GET", "/", nil)
+		Ω(selector(req)).Should(Equal("v2"))
+	})
+})
+
+var _ = Describe("MediaTypeVersion", func() {
+	selector := goa.MediaTypeVersion("version", "v2")
+
+	It("reads the version from the accepted media types", func() {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "text/plain, application/vnd.bottle+json; version=v1")
+		Ω(selector(req)).Should(Equal("v1"))
+	})
+
+	It("defaults to the given version", func() {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "application/json")
+		Ω(selector(req)).Should(Equal("v2"))
+	})
+})
+
+var _ = Describe("HandleVersion", func() {
+	var service *goa.Service
+	var rw *httptest.ResponseRecorder
+	var req *http.Request
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		selector := goa.HeaderVersion("X-API-Version", "v2")
+		for _, v := range []string{"v1", "v2"} {
+			version := v
+			service.HandleVersion(selector, version, "GET", "/bottles", func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+				rw.Write([]byte(version))
+			})
+		}
+		rw = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/bottles", nil)
+	})
+
+	It("dispatches the requests to the handler of the selected version", func() {
+		req.Header.Set("X-API-Version", "v1")
+		service.Mux.ServeHTTP(rw, req)
+		Ω(rw.Body.String()).Should(Equal("v1"))
+	})
+
+	It("dispatches the requests to the default version", func() {
+		service.Mux.ServeHTTP(rw, req)
+		Ω(rw.Body.String()).Should(Equal("v2"))
+	})
+
+	It("rejects the requests for unsupported versions", func() {
+		req.Header.Set("X-API-Version", "v3")
+		service.Mux.ServeHTTP(rw, req)
+		Ω(rw.Code).Should(Equal(400))
+		Ω(rw.Body.String()).Should(ContainSubstring("unsupported API version"))
+	})
+})
+
+var _ = Describe("DeprecatedHandler", func() {
+	var sunset string
+	var rw *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		sunset = ""
+		rw = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		h := goa.DeprecatedHandler(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.WriteHeader(204)
+			return nil
+		}, sunset)
+		req, _ := http.NewRequest("GET", "/", nil)
+		Ω(h(context.Background(), rw, req)).Should(Succeed())
+	})
+
+	It("sets the Deprecation header", func() {
+		Ω(rw.Header().Get("Deprecation")).Should(Equal("true"))
+		Ω(rw.Header()).ShouldNot(HaveKey("Sunset"))
+	})
+
+	Context("with a sunset date", func() {
+		BeforeEach(func() {
+			sunset = "Wed, 30 Jun 2027 00:00:00 GMT"
+		})
+
+		It("sets the Sunset header", func() {
+			Ω(rw.Header().Get("Sunset")).Should(Equal(sunset))
+		})
+	})
+})